@@ -82,23 +82,45 @@ func run() error {
 	storeClient := storev1.NewStoreServiceClient(storeConn)
 	staffClient := storev1.NewStaffServiceClient(storeConn)
 	customerClient := customerv1.NewCustomerServiceClient(customerConn)
+	segmentClient := customerv1.NewSegmentServiceClient(customerConn)
 	filmClient := filmv1.NewFilmServiceClient(filmConn)
 	actorClient := filmv1.NewActorServiceClient(filmConn)
 	categoryClient := filmv1.NewCategoryServiceClient(filmConn)
 	languageClient := filmv1.NewLanguageServiceClient(filmConn)
+	reviewClient := filmv1.NewReviewServiceClient(filmConn)
+	wishlistClient := filmv1.NewWishlistServiceClient(filmConn)
+	recommendationClient := filmv1.NewRecommendationServiceClient(filmConn)
+	filmAssetClient := filmv1.NewFilmAssetServiceClient(filmConn)
 	rentalClient := rentalv1.NewRentalServiceClient(rentalConn)
 	inventoryClient := rentalv1.NewInventoryServiceClient(rentalConn)
 	paymentClient := paymentv1.NewPaymentServiceClient(paymentConn)
+	promotionClient := paymentv1.NewPromotionServiceClient(paymentConn)
+	pricingClient := paymentv1.NewPricingServiceClient(paymentConn)
+	storedValueClient := paymentv1.NewStoredValueServiceClient(paymentConn)
+	loyaltyClient := paymentv1.NewLoyaltyServiceClient(paymentConn)
+	subscriptionClient := paymentv1.NewSubscriptionServiceClient(paymentConn)
+	taxClient := paymentv1.NewTaxServiceClient(paymentConn)
 
 	// 6. Create handlers.
 	authHandler := handler.NewAuthHandler(staffClient, jwtManager, refreshStore)
 	storeHandler := handler.NewStoreHandler(storeClient)
 	staffHandler := handler.NewStaffHandler(staffClient)
-	customerHandler := handler.NewCustomerHandler(customerClient)
+	customerHandler := handler.NewCustomerHandler(customerClient, refreshStore)
 	filmHandler := handler.NewFilmHandler(filmClient, actorClient, categoryClient, languageClient)
 	inventoryHandler := handler.NewInventoryHandler(inventoryClient)
 	rentalHandler := handler.NewRentalHandler(rentalClient)
 	paymentHandler := handler.NewPaymentHandler(paymentClient)
+	promotionHandler := handler.NewPromotionHandler(promotionClient, pricingClient)
+	storedValueHandler := handler.NewStoredValueHandler(storedValueClient)
+	loyaltyHandler := handler.NewLoyaltyHandler(loyaltyClient)
+	subscriptionHandler := handler.NewSubscriptionHandler(subscriptionClient)
+	taxHandler := handler.NewTaxHandler(taxClient)
+	reviewHandler := handler.NewReviewHandler(reviewClient)
+	recommendationHandler := handler.NewRecommendationHandler(recommendationClient)
+	filmAssetHandler := handler.NewFilmAssetHandler(filmAssetClient)
+	wishlistHandler := handler.NewWishlistHandler(wishlistClient)
+	segmentHandler := handler.NewSegmentHandler(segmentClient, customerClient)
+	duplicateHandler := handler.NewCustomerDuplicateHandler(customerClient, refreshStore)
 
 	// 7. Create router.
 	mux := router.NewRouter(
@@ -110,6 +132,17 @@ func run() error {
 		inventoryHandler,
 		rentalHandler,
 		paymentHandler,
+		promotionHandler,
+		storedValueHandler,
+		loyaltyHandler,
+		subscriptionHandler,
+		taxHandler,
+		reviewHandler,
+		recommendationHandler,
+		filmAssetHandler,
+		wishlistHandler,
+		segmentHandler,
+		duplicateHandler,
 		authMw,
 	)
 
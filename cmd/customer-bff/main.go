@@ -22,6 +22,7 @@ import (
 	"github.com/enkaigaku/dvd-rental/pkg/auth"
 	"github.com/enkaigaku/dvd-rental/pkg/grpcutil"
 	"github.com/enkaigaku/dvd-rental/pkg/middleware"
+	"github.com/enkaigaku/dvd-rental/pkg/notify"
 )
 
 func main() {
@@ -57,8 +58,15 @@ func run() error {
 		return fmt.Errorf("create jwt manager: %w", err)
 	}
 	refreshStore := auth.NewRefreshTokenStore(redisClient, cfg.RefreshTokenDuration)
+	resetStore := auth.NewPasswordResetStore(redisClient, cfg.PasswordResetTTL)
+	verifyStore := auth.NewEmailVerificationStore(redisClient, cfg.EmailVerifyTTL)
 	authMw := middleware.NewAuthMiddleware(jwtManager)
 
+	notifier, err := notify.NewFileNotifier(cfg.MailDir, cfg.MailFrom)
+	if err != nil {
+		return fmt.Errorf("create notifier: %w", err)
+	}
+
 	// 4. Create gRPC connections.
 	customerConn := grpcutil.MustDial(grpcutil.DefaultClientConfig(cfg.CustomerServiceAddr))
 	defer customerConn.Close()
@@ -76,21 +84,36 @@ func run() error {
 
 	// 5. Create gRPC clients.
 	customerClient := customerv1.NewCustomerServiceClient(customerConn)
+	cityClient := customerv1.NewCityServiceClient(customerConn)
 	filmClient := filmv1.NewFilmServiceClient(filmConn)
 	actorClient := filmv1.NewActorServiceClient(filmConn)
 	categoryClient := filmv1.NewCategoryServiceClient(filmConn)
+	reviewClient := filmv1.NewReviewServiceClient(filmConn)
+	wishlistClient := filmv1.NewWishlistServiceClient(filmConn)
+	recommendationClient := filmv1.NewRecommendationServiceClient(filmConn)
+	filmAssetClient := filmv1.NewFilmAssetServiceClient(filmConn)
 	rentalClient := rentalv1.NewRentalServiceClient(rentalConn)
 	paymentClient := paymentv1.NewPaymentServiceClient(paymentConn)
+	storedValueClient := paymentv1.NewStoredValueServiceClient(paymentConn)
+	loyaltyClient := paymentv1.NewLoyaltyServiceClient(paymentConn)
+	subscriptionClient := paymentv1.NewSubscriptionServiceClient(paymentConn)
 
 	// 6. Create handlers.
-	authHandler := handler.NewAuthHandler(customerClient, jwtManager, refreshStore)
+	authHandler := handler.NewAuthHandler(customerClient, jwtManager, refreshStore, resetStore, verifyStore, notifier, cfg.PasswordResetURL, cfg.EmailVerifyURL)
 	filmHandler := handler.NewFilmHandler(filmClient, actorClient, categoryClient)
 	rentalHandler := handler.NewRentalHandler(rentalClient)
-	paymentHandler := handler.NewPaymentHandler(paymentClient)
-	profileHandler := handler.NewProfileHandler(customerClient)
+	paymentHandler := handler.NewPaymentHandler(paymentClient, storedValueClient)
+	profileHandler := handler.NewProfileHandler(customerClient, verifyStore, notifier, cfg.EmailVerifyURL)
+	loyaltyHandler := handler.NewLoyaltyHandler(loyaltyClient)
+	subscriptionHandler := handler.NewSubscriptionHandler(subscriptionClient)
+	reviewHandler := handler.NewReviewHandler(reviewClient)
+	recommendationHandler := handler.NewRecommendationHandler(recommendationClient)
+	filmAssetHandler := handler.NewFilmAssetHandler(filmAssetClient)
+	cityHandler := handler.NewCityHandler(cityClient)
+	wishlistHandler := handler.NewWishlistHandler(wishlistClient)
 
 	// 7. Create router.
-	mux := router.NewRouter(authHandler, filmHandler, rentalHandler, paymentHandler, profileHandler, authMw)
+	mux := router.NewRouter(authHandler, filmHandler, rentalHandler, paymentHandler, profileHandler, loyaltyHandler, subscriptionHandler, reviewHandler, recommendationHandler, filmAssetHandler, cityHandler, wishlistHandler, authMw)
 
 	// 8. Create HTTP server.
 	srv := &http.Server{
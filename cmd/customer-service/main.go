@@ -52,18 +52,22 @@ func run() error {
 	addressRepo := repository.NewAddressRepository(pool)
 	cityRepo := repository.NewCityRepository(pool)
 	countryRepo := repository.NewCountryRepository(pool)
+	segmentRepo := repository.NewSegmentRepository(pool)
+	duplicateRepo := repository.NewDuplicateRepository(pool)
 
 	// Services
-	customerSvc := service.NewCustomerService(customerRepo, addressRepo, cityRepo, countryRepo)
+	customerSvc := service.NewCustomerService(customerRepo, addressRepo, cityRepo, countryRepo, duplicateRepo)
 	addressSvc := service.NewAddressService(addressRepo, cityRepo)
 	citySvc := service.NewCityService(cityRepo)
 	countrySvc := service.NewCountryService(countryRepo)
+	segmentSvc := service.NewSegmentService(segmentRepo)
 
 	// Handlers
 	customerHandler := handler.NewCustomerHandler(customerSvc)
 	addressHandler := handler.NewAddressHandler(addressSvc)
 	cityHandler := handler.NewCityHandler(citySvc)
 	countryHandler := handler.NewCountryHandler(countrySvc)
+	segmentHandler := handler.NewSegmentHandler(segmentSvc)
 
 	// gRPC server
 	grpcServer := grpc.NewServer()
@@ -71,6 +75,7 @@ func run() error {
 	customerv1.RegisterAddressServiceServer(grpcServer, addressHandler)
 	customerv1.RegisterCityServiceServer(grpcServer, cityHandler)
 	customerv1.RegisterCountryServiceServer(grpcServer, countryHandler)
+	customerv1.RegisterSegmentServiceServer(grpcServer, segmentHandler)
 
 	// Health check
 	healthServer := health.NewServer()
@@ -79,6 +84,7 @@ func run() error {
 	healthServer.SetServingStatus("customer.v1.AddressService", healthpb.HealthCheckResponse_SERVING)
 	healthServer.SetServingStatus("customer.v1.CityService", healthpb.HealthCheckResponse_SERVING)
 	healthServer.SetServingStatus("customer.v1.CountryService", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus("customer.v1.SegmentService", healthpb.HealthCheckResponse_SERVING)
 
 	// Reflection for development tooling
 	reflection.Register(grpcServer)
@@ -99,6 +105,7 @@ func run() error {
 		healthServer.SetServingStatus("customer.v1.AddressService", healthpb.HealthCheckResponse_NOT_SERVING)
 		healthServer.SetServingStatus("customer.v1.CityService", healthpb.HealthCheckResponse_NOT_SERVING)
 		healthServer.SetServingStatus("customer.v1.CountryService", healthpb.HealthCheckResponse_NOT_SERVING)
+		healthServer.SetServingStatus("customer.v1.SegmentService", healthpb.HealthCheckResponse_NOT_SERVING)
 		grpcServer.GracefulStop()
 	}()
 
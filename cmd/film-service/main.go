@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"google.golang.org/grpc"
@@ -15,10 +16,14 @@ import (
 	"google.golang.org/grpc/reflection"
 
 	filmv1 "github.com/enkaigaku/dvd-rental/gen/proto/film/v1"
+	rentalv1 "github.com/enkaigaku/dvd-rental/gen/proto/rental/v1"
 	"github.com/enkaigaku/dvd-rental/internal/film/config"
 	"github.com/enkaigaku/dvd-rental/internal/film/handler"
+	"github.com/enkaigaku/dvd-rental/internal/film/media"
+	"github.com/enkaigaku/dvd-rental/internal/film/rentals"
 	"github.com/enkaigaku/dvd-rental/internal/film/repository"
 	"github.com/enkaigaku/dvd-rental/internal/film/service"
+	"github.com/enkaigaku/dvd-rental/pkg/grpcutil"
 )
 
 func main() {
@@ -52,18 +57,43 @@ func run() error {
 	actorRepo := repository.NewActorRepository(pool)
 	categoryRepo := repository.NewCategoryRepository(pool)
 	languageRepo := repository.NewLanguageRepository(pool)
+	reviewRepo := repository.NewReviewRepository(pool)
+	wishlistRepo := repository.NewWishlistRepository(pool)
+	recommendationRepo := repository.NewRecommendationRepository(pool)
+	popularityRepo := repository.NewPopularityRepository(pool)
+	catalogRepo := repository.NewCatalogRepository(pool)
+	assetRepo := repository.NewFilmAssetRepository(pool)
+
+	// Film image storage
+	blobStore, err := media.NewLocalStore(cfg.AssetDir)
+	if err != nil {
+		return err
+	}
+
+	// Rental service client
+	rentalConn := grpcutil.MustDial(grpcutil.DefaultClientConfig(cfg.RentalServiceAddr))
+	defer rentalConn.Close()
+	rentalChecker := rentals.NewChecker(rentalv1.NewRentalServiceClient(rentalConn))
 
 	// Services
-	filmSvc := service.NewFilmService(filmRepo, actorRepo, categoryRepo, languageRepo)
+	filmSvc := service.NewFilmService(filmRepo, actorRepo, categoryRepo, languageRepo, reviewRepo, popularityRepo, catalogRepo, assetRepo)
 	actorSvc := service.NewActorService(actorRepo)
 	categorySvc := service.NewCategoryService(categoryRepo)
 	languageSvc := service.NewLanguageService(languageRepo)
+	reviewSvc := service.NewReviewService(reviewRepo, filmRepo, rentalChecker)
+	wishlistSvc := service.NewWishlistService(wishlistRepo, filmRepo)
+	recommendationSvc := service.NewRecommendationService(recommendationRepo, filmRepo, assetRepo)
+	assetSvc := service.NewFilmAssetService(assetRepo, filmRepo, blobStore)
 
 	// Handlers
 	filmHandler := handler.NewFilmHandler(filmSvc)
 	actorHandler := handler.NewActorHandler(actorSvc)
 	categoryHandler := handler.NewCategoryHandler(categorySvc)
 	languageHandler := handler.NewLanguageHandler(languageSvc)
+	reviewHandler := handler.NewReviewHandler(reviewSvc)
+	wishlistHandler := handler.NewWishlistHandler(wishlistSvc)
+	recommendationHandler := handler.NewRecommendationHandler(recommendationSvc)
+	assetHandler := handler.NewFilmAssetHandler(assetSvc)
 
 	// gRPC server
 	grpcServer := grpc.NewServer()
@@ -71,6 +101,10 @@ func run() error {
 	filmv1.RegisterActorServiceServer(grpcServer, actorHandler)
 	filmv1.RegisterCategoryServiceServer(grpcServer, categoryHandler)
 	filmv1.RegisterLanguageServiceServer(grpcServer, languageHandler)
+	filmv1.RegisterReviewServiceServer(grpcServer, reviewHandler)
+	filmv1.RegisterWishlistServiceServer(grpcServer, wishlistHandler)
+	filmv1.RegisterRecommendationServiceServer(grpcServer, recommendationHandler)
+	filmv1.RegisterFilmAssetServiceServer(grpcServer, assetHandler)
 
 	// Health check
 	healthServer := health.NewServer()
@@ -79,6 +113,10 @@ func run() error {
 	healthServer.SetServingStatus("film.v1.ActorService", healthpb.HealthCheckResponse_SERVING)
 	healthServer.SetServingStatus("film.v1.CategoryService", healthpb.HealthCheckResponse_SERVING)
 	healthServer.SetServingStatus("film.v1.LanguageService", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus("film.v1.ReviewService", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus("film.v1.WishlistService", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus("film.v1.RecommendationService", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus("film.v1.FilmAssetService", healthpb.HealthCheckResponse_SERVING)
 
 	// Reflection for development tooling
 	reflection.Register(grpcServer)
@@ -88,6 +126,16 @@ func run() error {
 		return err
 	}
 
+	// Film similarity refresh
+	if cfg.SimilarityRefreshInterval > 0 {
+		go runSimilarityRefresh(ctx, recommendationSvc, cfg.SimilarityRefreshInterval)
+	}
+
+	// Film popularity refresh
+	if cfg.PopularityRefreshInterval > 0 {
+		go runPopularityRefresh(ctx, filmSvc, cfg.PopularityRefreshInterval)
+	}
+
 	// Graceful shutdown
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
@@ -99,9 +147,54 @@ func run() error {
 		healthServer.SetServingStatus("film.v1.ActorService", healthpb.HealthCheckResponse_NOT_SERVING)
 		healthServer.SetServingStatus("film.v1.CategoryService", healthpb.HealthCheckResponse_NOT_SERVING)
 		healthServer.SetServingStatus("film.v1.LanguageService", healthpb.HealthCheckResponse_NOT_SERVING)
+		healthServer.SetServingStatus("film.v1.ReviewService", healthpb.HealthCheckResponse_NOT_SERVING)
+		healthServer.SetServingStatus("film.v1.WishlistService", healthpb.HealthCheckResponse_NOT_SERVING)
+		healthServer.SetServingStatus("film.v1.RecommendationService", healthpb.HealthCheckResponse_NOT_SERVING)
+		healthServer.SetServingStatus("film.v1.FilmAssetService", healthpb.HealthCheckResponse_NOT_SERVING)
+		cancel()
 		grpcServer.GracefulStop()
 	}()
 
 	log.Printf("film-service listening on :%s", cfg.GRPCPort)
 	return grpcServer.Serve(lis)
 }
+
+// runSimilarityRefresh recomputes film similarities at startup and then
+// every interval until ctx is canceled.
+func runSimilarityRefresh(ctx context.Context, svc *service.RecommendationService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		pairs, err := svc.RefreshSimilarities(ctx)
+		if err != nil {
+			log.Printf("similarity refresh: %v", err)
+		} else {
+			log.Printf("similarity refresh: stored %d similar film pairs", pairs)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runPopularityRefresh recomputes the trending and popular lists' rental
+// counts every interval until ctx is canceled.
+func runPopularityRefresh(ctx context.Context, svc *service.FilmService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := svc.RefreshPopularity(ctx); err != nil {
+				log.Printf("popularity refresh: %v", err)
+			}
+		}
+	}
+}
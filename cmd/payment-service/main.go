@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"google.golang.org/grpc"
@@ -16,7 +17,9 @@ import (
 
 	paymentv1 "github.com/enkaigaku/dvd-rental/gen/proto/payment/v1"
 	"github.com/enkaigaku/dvd-rental/internal/payment/config"
+	"github.com/enkaigaku/dvd-rental/internal/payment/gateway"
 	"github.com/enkaigaku/dvd-rental/internal/payment/handler"
+	"github.com/enkaigaku/dvd-rental/internal/payment/model"
 	"github.com/enkaigaku/dvd-rental/internal/payment/repository"
 	"github.com/enkaigaku/dvd-rental/internal/payment/service"
 )
@@ -47,23 +50,55 @@ func run() error {
 	}
 	log.Println("connected to database")
 
-	// Repository
+	// Repositories
 	paymentRepo := repository.NewPaymentRepository(pool)
-
-	// Service
-	paymentSvc := service.NewPaymentService(paymentRepo)
-
-	// Handler
+	promotionRepo := repository.NewPromotionRepository(pool)
+	storedValueRepo := repository.NewStoredValueRepository(pool)
+	loyaltyRepo := repository.NewLoyaltyRepository(pool)
+	subscriptionRepo := repository.NewSubscriptionRepository(pool)
+	taxRepo := repository.NewTaxRepository(pool)
+
+	// Payment gateway
+	paymentGateway := gateway.NewOfflineGateway()
+
+	// Services
+	taxSvc := service.NewTaxService(taxRepo)
+	pricingSvc := service.NewPricingService(promotionRepo, subscriptionRepo, taxSvc)
+	storedValueSvc := service.NewStoredValueService(storedValueRepo)
+	loyaltySvc := service.NewLoyaltyService(loyaltyRepo)
+	paymentSvc := service.NewPaymentService(paymentRepo, pricingSvc, storedValueSvc, loyaltySvc, taxSvc)
+	promotionSvc := service.NewPromotionService(promotionRepo)
+	subscriptionSvc := service.NewSubscriptionService(subscriptionRepo, paymentGateway)
+
+	// Handlers
 	paymentHandler := handler.NewPaymentHandler(paymentSvc)
+	promotionHandler := handler.NewPromotionHandler(promotionSvc)
+	pricingHandler := handler.NewPricingHandler(pricingSvc)
+	storedValueHandler := handler.NewStoredValueHandler(storedValueSvc)
+	loyaltyHandler := handler.NewLoyaltyHandler(loyaltySvc)
+	subscriptionHandler := handler.NewSubscriptionHandler(subscriptionSvc)
+	taxHandler := handler.NewTaxHandler(taxSvc)
 
 	// gRPC server
 	grpcServer := grpc.NewServer()
 	paymentv1.RegisterPaymentServiceServer(grpcServer, paymentHandler)
+	paymentv1.RegisterPromotionServiceServer(grpcServer, promotionHandler)
+	paymentv1.RegisterPricingServiceServer(grpcServer, pricingHandler)
+	paymentv1.RegisterStoredValueServiceServer(grpcServer, storedValueHandler)
+	paymentv1.RegisterLoyaltyServiceServer(grpcServer, loyaltyHandler)
+	paymentv1.RegisterSubscriptionServiceServer(grpcServer, subscriptionHandler)
+	paymentv1.RegisterTaxServiceServer(grpcServer, taxHandler)
 
 	// Health check
 	healthServer := health.NewServer()
 	healthpb.RegisterHealthServer(grpcServer, healthServer)
 	healthServer.SetServingStatus("payment.v1.PaymentService", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus("payment.v1.PromotionService", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus("payment.v1.PricingService", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus("payment.v1.StoredValueService", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus("payment.v1.LoyaltyService", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus("payment.v1.SubscriptionService", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus("payment.v1.TaxService", healthpb.HealthCheckResponse_SERVING)
 
 	// Reflection for development tooling
 	reflection.Register(grpcServer)
@@ -73,6 +108,11 @@ func run() error {
 		return err
 	}
 
+	// Recurring subscription billing
+	if cfg.BillingInterval > 0 {
+		go runBilling(ctx, subscriptionSvc, cfg.BillingInterval)
+	}
+
 	// Graceful shutdown
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
@@ -81,9 +121,38 @@ func run() error {
 		sig := <-sigCh
 		log.Printf("received signal %v, shutting down gracefully...", sig)
 		healthServer.SetServingStatus("payment.v1.PaymentService", healthpb.HealthCheckResponse_NOT_SERVING)
+		healthServer.SetServingStatus("payment.v1.PromotionService", healthpb.HealthCheckResponse_NOT_SERVING)
+		healthServer.SetServingStatus("payment.v1.PricingService", healthpb.HealthCheckResponse_NOT_SERVING)
+		healthServer.SetServingStatus("payment.v1.StoredValueService", healthpb.HealthCheckResponse_NOT_SERVING)
+		healthServer.SetServingStatus("payment.v1.LoyaltyService", healthpb.HealthCheckResponse_NOT_SERVING)
+		healthServer.SetServingStatus("payment.v1.SubscriptionService", healthpb.HealthCheckResponse_NOT_SERVING)
+		healthServer.SetServingStatus("payment.v1.TaxService", healthpb.HealthCheckResponse_NOT_SERVING)
+		cancel()
 		grpcServer.GracefulStop()
 	}()
 
 	log.Printf("payment-service listening on :%s", cfg.GRPCPort)
 	return grpcServer.Serve(lis)
 }
+
+// runBilling bills due subscriptions every interval until ctx is canceled.
+func runBilling(ctx context.Context, svc *service.SubscriptionService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			run, err := svc.RunBilling(ctx, time.Now())
+			if err != nil {
+				log.Printf("subscription billing: %v", err)
+			}
+			if run != (model.BillingRun{}) {
+				log.Printf("subscription billing: renewed %d, failed %d, paused %d, canceled %d",
+					run.Renewed, run.Failed, run.Paused, run.Canceled)
+			}
+		}
+	}
+}
@@ -2,45 +2,78 @@ package handler
 
 import (
 	"context"
+	"fmt"
+	"log"
+	"math"
 	"net/http"
 	"time"
 
 	customerv1 "github.com/enkaigaku/dvd-rental/gen/proto/customer/v1"
+	"github.com/enkaigaku/dvd-rental/pkg/auth"
+	"github.com/enkaigaku/dvd-rental/pkg/middleware"
 )
 
 // CustomerHandler handles customer management endpoints.
 type CustomerHandler struct {
 	customerClient customerv1.CustomerServiceClient
+	refreshStore   *auth.RefreshTokenStore
 }
 
-// NewCustomerHandler creates a new CustomerHandler.
-func NewCustomerHandler(customerClient customerv1.CustomerServiceClient) *CustomerHandler {
-	return &CustomerHandler{customerClient: customerClient}
+// NewCustomerHandler creates a new CustomerHandler. refreshStore is the
+// store shared with the customer BFF, used to sign out anonymized customers.
+func NewCustomerHandler(customerClient customerv1.CustomerServiceClient, refreshStore *auth.RefreshTokenStore) *CustomerHandler {
+	return &CustomerHandler{
+		customerClient: customerClient,
+		refreshStore:   refreshStore,
+	}
 }
 
 // --- JSON models ---
 
 type customerResponse struct {
-	CustomerID int32  `json:"customer_id"`
-	StoreID    int32  `json:"store_id"`
-	FirstName  string `json:"first_name"`
-	LastName   string `json:"last_name"`
-	Email      string `json:"email"`
-	AddressID  int32  `json:"address_id"`
-	Active     bool   `json:"active"`
-	CreateDate string `json:"create_date"`
-	LastUpdate string `json:"last_update"`
+	CustomerID      int32  `json:"customer_id"`
+	StoreID         int32  `json:"store_id"`
+	FirstName       string `json:"first_name"`
+	LastName        string `json:"last_name"`
+	Email           string `json:"email"`
+	EmailVerified   bool   `json:"email_verified"`
+	EmailVerifiedAt string `json:"email_verified_at,omitempty"`
+	PendingEmail    string `json:"pending_email,omitempty"`
+	AddressID       int32  `json:"address_id"`
+	Active          bool   `json:"active"`
+	Anonymized      bool   `json:"anonymized"`
+	AnonymizedAt    string `json:"anonymized_at,omitempty"`
+	CreateDate      string `json:"create_date"`
+	LastUpdate      string `json:"last_update"`
 }
 
 type customerDetailResponse struct {
 	customerResponse
-	Address    string `json:"address"`
-	Address2   string `json:"address2"`
-	District   string `json:"district"`
-	City       string `json:"city"`
-	Country    string `json:"country"`
-	PostalCode string `json:"postal_code"`
-	Phone      string `json:"phone"`
+	Address    string                   `json:"address"`
+	Address2   string                   `json:"address2"`
+	District   string                   `json:"district"`
+	City       string                   `json:"city"`
+	Country    string                   `json:"country"`
+	PostalCode string                   `json:"postal_code"`
+	Phone      string                   `json:"phone"`
+	Summary    *customerSummaryResponse `json:"summary,omitempty"`
+}
+
+type customerSummaryResponse struct {
+	FirstRentalDate     string                      `json:"first_rental_date,omitempty"`
+	LastRentalDate      string                      `json:"last_rental_date,omitempty"`
+	TotalRentals        int32                       `json:"total_rentals"`
+	OpenRentals         int32                       `json:"open_rentals"`
+	OverdueRentals      int32                       `json:"overdue_rentals"`
+	LifetimeSpend       string                      `json:"lifetime_spend"`
+	FavouriteCategories []favouriteCategoryResponse `json:"favourite_categories"`
+	AverageDaysKept     float64                     `json:"average_days_kept"`
+}
+
+type favouriteCategoryResponse struct {
+	CategoryID int32  `json:"category_id"`
+	Name       string `json:"name"`
+	Rentals    int32  `json:"rentals"`
 }
 
 type customerListResponse struct {
@@ -48,6 +81,125 @@ type customerListResponse struct {
 	TotalCount int32              `json:"total_count"`
 }
 
+type customerExportResponse struct {
+	ExportedAt    string                     `json:"exported_at"`
+	Customer      customerDetailResponse     `json:"customer"`
+	Rentals       []customerRentalRecord     `json:"rentals"`
+	Payments      []customerPaymentRecord    `json:"payments"`
+	Reviews       []customerReviewRecord     `json:"reviews"`
+	Wishlist      []customerWishlistRecord   `json:"wishlist"`
+	Loyalty       customerLoyaltyExport      `json:"loyalty"`
+	StoredValue   customerStoredValueExport  `json:"stored_value"`
+	Subscriptions customerSubscriptionExport `json:"subscriptions"`
+}
+
+type customerRentalRecord struct {
+	RentalID   int32  `json:"rental_id"`
+	RentalDate string `json:"rental_date"`
+	ReturnDate string `json:"return_date,omitempty"`
+	StoreID    int32  `json:"store_id"`
+	FilmID     int32  `json:"film_id"`
+	FilmTitle  string `json:"film_title"`
+}
+
+type customerPaymentRecord struct {
+	PaymentID   int32  `json:"payment_id"`
+	RentalID    int32  `json:"rental_id"`
+	Amount      string `json:"amount"`
+	NetAmount   string `json:"net_amount"`
+	TaxAmount   string `json:"tax_amount"`
+	ChargeType  string `json:"charge_type"`
+	PaymentDate string `json:"payment_date"`
+}
+
+type customerReviewRecord struct {
+	ReviewID  int32  `json:"review_id"`
+	FilmID    int32  `json:"film_id"`
+	FilmTitle string `json:"film_title"`
+	Rating    int32  `json:"rating"`
+	Body      string `json:"body"`
+	Status    string `json:"status"`
+	CreatedAt string `json:"created_at"`
+}
+
+type customerWishlistRecord struct {
+	FilmID    int32  `json:"film_id"`
+	FilmTitle string `json:"film_title"`
+	AddedAt   string `json:"added_at"`
+}
+
+type customerLoyaltyExport struct {
+	Account      *customerLoyaltyAccountRecord      `json:"account"` // null without a loyalty account
+	Transactions []customerLoyaltyTransactionRecord `json:"transactions"`
+}
+
+type customerLoyaltyAccountRecord struct {
+	Tier           string `json:"tier"`
+	PointsBalance  int32  `json:"points_balance"`
+	LifetimePoints int32  `json:"lifetime_points"`
+}
+
+type customerLoyaltyTransactionRecord struct {
+	TransactionID int32  `json:"transaction_id"`
+	Kind          string `json:"kind"`
+	Points        int32  `json:"points"`
+	BalanceAfter  int32  `json:"balance_after"`
+	PaymentID     int32  `json:"payment_id,omitempty"`
+	RentalID      int32  `json:"rental_id,omitempty"`
+	Note          string `json:"note,omitempty"`
+	CreatedAt     string `json:"created_at"`
+}
+
+type customerStoredValueExport struct {
+	Accounts []customerStoredValueAccountRecord `json:"accounts"`
+	Entries  []customerStoredValueEntryRecord   `json:"entries"`
+}
+
+type customerStoredValueAccountRecord struct {
+	AccountID  int32  `json:"account_id"`
+	Kind       string `json:"kind"`
+	Code       string `json:"code"`
+	Balance    string `json:"balance"`
+	Active     bool   `json:"active"`
+	CreateDate string `json:"create_date"`
+}
+
+type customerStoredValueEntryRecord struct {
+	EntryID      int32  `json:"entry_id"`
+	AccountID    int32  `json:"account_id"`
+	Kind         string `json:"kind"`
+	Amount       string `json:"amount"`
+	BalanceAfter string `json:"balance_after"`
+	PaymentID    int32  `json:"payment_id,omitempty"`
+	Note         string `json:"note,omitempty"`
+	CreatedAt    string `json:"created_at"`
+}
+
+type customerSubscriptionExport struct {
+	Subscriptions []customerSubscriptionRecord        `json:"subscriptions"`
+	Payments      []customerSubscriptionPaymentRecord `json:"payments"`
+}
+
+type customerSubscriptionRecord struct {
+	SubscriptionID     int32  `json:"subscription_id"`
+	PlanName           string `json:"plan_name"`
+	Status             string `json:"status"`
+	CurrentPeriodStart string `json:"current_period_start"`
+	CurrentPeriodEnd   string `json:"current_period_end"`
+	CreateDate         string `json:"create_date"`
+	CanceledAt         string `json:"canceled_at,omitempty"`
+}
+
+type customerSubscriptionPaymentRecord struct {
+	SubscriptionPaymentID int32  `json:"subscription_payment_id"`
+	SubscriptionID        int32  `json:"subscription_id"`
+	Amount                string `json:"amount"`
+	PeriodStart           string `json:"period_start"`
+	PeriodEnd             string `json:"period_end"`
+	Status                string `json:"status"`
+	CreatedAt             string `json:"created_at"`
+}
+
 type createCustomerRequest struct {
 	StoreID   int32  `json:"store_id"`
 	FirstName string `json:"first_name"`
@@ -67,17 +219,27 @@ type updateCustomerRequest struct {
 }
 
 func customerToResponse(c *customerv1.Customer) customerResponse {
-	return customerResponse{
-		CustomerID: c.GetCustomerId(),
-		StoreID:    c.GetStoreId(),
-		FirstName:  c.GetFirstName(),
-		LastName:   c.GetLastName(),
-		Email:      c.GetEmail(),
-		AddressID:  c.GetAddressId(),
-		Active:     c.GetActive(),
-		CreateDate: c.GetCreateDate().AsTime().Format("2006-01-02"),
-		LastUpdate: c.GetLastUpdate().AsTime().Format(time.RFC3339),
+	resp := customerResponse{
+		CustomerID:    c.GetCustomerId(),
+		StoreID:       c.GetStoreId(),
+		FirstName:     c.GetFirstName(),
+		LastName:      c.GetLastName(),
+		Email:         c.GetEmail(),
+		EmailVerified: c.GetEmailVerifiedAt() != nil,
+		PendingEmail:  c.GetPendingEmail(),
+		AddressID:     c.GetAddressId(),
+		Active:        c.GetActive(),
+		Anonymized:    c.GetAnonymizedAt() != nil,
+		CreateDate:    c.GetCreateDate().AsTime().Format("2006-01-02"),
+		LastUpdate:    c.GetLastUpdate().AsTime().Format(time.RFC3339),
+	}
+	if c.GetEmailVerifiedAt() != nil {
+		resp.EmailVerifiedAt = c.GetEmailVerifiedAt().AsTime().Format(time.RFC3339)
+	}
+	if c.GetAnonymizedAt() != nil {
+		resp.AnonymizedAt = c.GetAnonymizedAt().AsTime().Format(time.RFC3339)
 	}
+	return resp
 }
 
 func customerDetailToResponse(d *customerv1.CustomerDetail) customerDetailResponse {
@@ -93,7 +255,175 @@ func customerDetailToResponse(d *customerv1.CustomerDetail) customerDetailRespon
 	}
 }
 
-// ListCustomers returns a paginated list of customers.
+func customerSummaryToResponse(s *customerv1.CustomerSummary) customerSummaryResponse {
+	categories := make([]favouriteCategoryResponse, len(s.GetFavouriteCategories()))
+	for i, c := range s.GetFavouriteCategories() {
+		categories[i] = favouriteCategoryResponse{
+			CategoryID: c.GetCategoryId(),
+			Name:       c.GetName(),
+			Rentals:    c.GetRentals(),
+		}
+	}
+	resp := customerSummaryResponse{
+		TotalRentals:        s.GetTotalRentals(),
+		OpenRentals:         s.GetOpenRentals(),
+		OverdueRentals:      s.GetOverdueRentals(),
+		LifetimeSpend:       s.GetLifetimeSpend(),
+		FavouriteCategories: categories,
+		AverageDaysKept:     math.Round(s.GetAverageDaysKept()*10) / 10,
+	}
+	if s.GetFirstRentalDate() != nil {
+		resp.FirstRentalDate = s.GetFirstRentalDate().AsTime().Format(time.RFC3339)
+		resp.LastRentalDate = s.GetLastRentalDate().AsTime().Format(time.RFC3339)
+	}
+	return resp
+}
+
+func customerExportToResponse(e *customerv1.CustomerDataExport) customerExportResponse {
+	rentals := make([]customerRentalRecord, len(e.GetRentals()))
+	for i, r := range e.GetRentals() {
+		rentals[i] = customerRentalRecord{
+			RentalID:   r.GetRentalId(),
+			RentalDate: r.GetRentalDate().AsTime().Format(time.RFC3339),
+			StoreID:    r.GetStoreId(),
+			FilmID:     r.GetFilmId(),
+			FilmTitle:  r.GetFilmTitle(),
+		}
+		if r.GetReturnDate() != nil {
+			rentals[i].ReturnDate = r.GetReturnDate().AsTime().Format(time.RFC3339)
+		}
+	}
+
+	payments := make([]customerPaymentRecord, len(e.GetPayments()))
+	for i, p := range e.GetPayments() {
+		payments[i] = customerPaymentRecord{
+			PaymentID:   p.GetPaymentId(),
+			RentalID:    p.GetRentalId(),
+			Amount:      p.GetAmount(),
+			NetAmount:   p.GetNetAmount(),
+			TaxAmount:   p.GetTaxAmount(),
+			ChargeType:  p.GetChargeType(),
+			PaymentDate: p.GetPaymentDate().AsTime().Format(time.RFC3339),
+		}
+	}
+
+	reviews := make([]customerReviewRecord, len(e.GetReviews()))
+	for i, rv := range e.GetReviews() {
+		reviews[i] = customerReviewRecord{
+			ReviewID:  rv.GetReviewId(),
+			FilmID:    rv.GetFilmId(),
+			FilmTitle: rv.GetFilmTitle(),
+			Rating:    rv.GetRating(),
+			Body:      rv.GetBody(),
+			Status:    rv.GetStatus(),
+			CreatedAt: rv.GetCreatedAt().AsTime().Format(time.RFC3339),
+		}
+	}
+
+	wishlist := make([]customerWishlistRecord, len(e.GetWishlist()))
+	for i, w := range e.GetWishlist() {
+		wishlist[i] = customerWishlistRecord{
+			FilmID:    w.GetFilmId(),
+			FilmTitle: w.GetFilmTitle(),
+			AddedAt:   w.GetAddedAt().AsTime().Format(time.RFC3339),
+		}
+	}
+
+	loyalty := customerLoyaltyExport{
+		Transactions: make([]customerLoyaltyTransactionRecord, len(e.GetLoyaltyTransactions())),
+	}
+	if a := e.GetLoyalty(); a != nil {
+		loyalty.Account = &customerLoyaltyAccountRecord{
+			Tier:           a.GetTier(),
+			PointsBalance:  a.GetPointsBalance(),
+			LifetimePoints: a.GetLifetimePoints(),
+		}
+	}
+	for i, t := range e.GetLoyaltyTransactions() {
+		loyalty.Transactions[i] = customerLoyaltyTransactionRecord{
+			TransactionID: t.GetTransactionId(),
+			Kind:          t.GetKind(),
+			Points:        t.GetPoints(),
+			BalanceAfter:  t.GetBalanceAfter(),
+			PaymentID:     t.GetPaymentId(),
+			RentalID:      t.GetRentalId(),
+			Note:          t.GetNote(),
+			CreatedAt:     t.GetCreatedAt().AsTime().Format(time.RFC3339),
+		}
+	}
+
+	storedValue := customerStoredValueExport{
+		Accounts: make([]customerStoredValueAccountRecord, len(e.GetStoredValue())),
+		Entries:  make([]customerStoredValueEntryRecord, len(e.GetStoredValueEntries())),
+	}
+	for i, a := range e.GetStoredValue() {
+		storedValue.Accounts[i] = customerStoredValueAccountRecord{
+			AccountID:  a.GetAccountId(),
+			Kind:       a.GetKind(),
+			Code:       a.GetCode(),
+			Balance:    a.GetBalance(),
+			Active:     a.GetActive(),
+			CreateDate: a.GetCreateDate().AsTime().Format(time.RFC3339),
+		}
+	}
+	for i, en := range e.GetStoredValueEntries() {
+		storedValue.Entries[i] = customerStoredValueEntryRecord{
+			EntryID:      en.GetEntryId(),
+			AccountID:    en.GetAccountId(),
+			Kind:         en.GetKind(),
+			Amount:       en.GetAmount(),
+			BalanceAfter: en.GetBalanceAfter(),
+			PaymentID:    en.GetPaymentId(),
+			Note:         en.GetNote(),
+			CreatedAt:    en.GetCreatedAt().AsTime().Format(time.RFC3339),
+		}
+	}
+
+	subscriptions := customerSubscriptionExport{
+		Subscriptions: make([]customerSubscriptionRecord, len(e.GetSubscriptions())),
+		Payments:      make([]customerSubscriptionPaymentRecord, len(e.GetSubscriptionPayments())),
+	}
+	for i, s := range e.GetSubscriptions() {
+		subscriptions.Subscriptions[i] = customerSubscriptionRecord{
+			SubscriptionID:     s.GetSubscriptionId(),
+			PlanName:           s.GetPlanName(),
+			Status:             s.GetStatus(),
+			CurrentPeriodStart: s.GetCurrentPeriodStart().AsTime().Format(time.RFC3339),
+			CurrentPeriodEnd:   s.GetCurrentPeriodEnd().AsTime().Format(time.RFC3339),
+			CreateDate:         s.GetCreateDate().AsTime().Format(time.RFC3339),
+		}
+		if s.GetCanceledAt() != nil {
+			subscriptions.Subscriptions[i].CanceledAt = s.GetCanceledAt().AsTime().Format(time.RFC3339)
+		}
+	}
+	for i, p := range e.GetSubscriptionPayments() {
+		subscriptions.Payments[i] = customerSubscriptionPaymentRecord{
+			SubscriptionPaymentID: p.GetSubscriptionPaymentId(),
+			SubscriptionID:        p.GetSubscriptionId(),
+			Amount:                p.GetAmount(),
+			PeriodStart:           p.GetPeriodStart().AsTime().Format(time.RFC3339),
+			PeriodEnd:             p.GetPeriodEnd().AsTime().Format(time.RFC3339),
+			Status:                p.GetStatus(),
+			CreatedAt:             p.GetCreatedAt().AsTime().Format(time.RFC3339),
+		}
+	}
+
+	return customerExportResponse{
+		ExportedAt:    e.GetExportedAt().AsTime().Format(time.RFC3339),
+		Customer:      customerDetailToResponse(e.GetCustomer()),
+		Rentals:       rentals,
+		Payments:      payments,
+		Reviews:       reviews,
+		Wishlist:      wishlist,
+		Loyalty:       loyalty,
+		StoredValue:   storedValue,
+		Subscriptions: subscriptions,
+	}
+}
+
+// ListCustomers returns a paginated list of customers, optionally of one
+// store. With q (a name, email or phone prefix) or status ("active" or
+// "inactive") it searches customers instead, best matches first.
 func (h *CustomerHandler) ListCustomers(w http.ResponseWriter, r *http.Request) {
 	pageSize, page := parsePagination(r)
 	storeID := parseQueryInt32(r, "store_id")
@@ -101,10 +431,21 @@ func (h *CustomerHandler) ListCustomers(w http.ResponseWriter, r *http.Request)
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
+	q := r.URL.Query().Get("q")
+	status := r.URL.Query().Get("status")
+
 	var resp *customerv1.ListCustomersResponse
 	var err error
 
-	if storeID > 0 {
+	if q != "" || status != "" {
+		resp, err = h.customerClient.SearchCustomers(ctx, &customerv1.SearchCustomersRequest{
+			Query:    q,
+			StoreId:  storeID,
+			Status:   status,
+			PageSize: pageSize,
+			Page:     page,
+		})
+	} else if storeID > 0 {
 		resp, err = h.customerClient.ListCustomersByStore(ctx, &customerv1.ListCustomersByStoreRequest{
 			StoreId:  storeID,
 			PageSize: pageSize,
@@ -132,7 +473,9 @@ func (h *CustomerHandler) ListCustomers(w http.ResponseWriter, r *http.Request)
 	})
 }
 
-// GetCustomer returns a single customer with full details.
+// GetCustomer returns a single customer with full details and a summary
+// of their rental activity and spend. The customer is still returned,
+// without the summary, if the summary cannot be computed.
 func (h *CustomerHandler) GetCustomer(w http.ResponseWriter, r *http.Request) {
 	customerID, err := parseIntParam(r, "id")
 	if err != nil {
@@ -151,7 +494,18 @@ func (h *CustomerHandler) GetCustomer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, customerDetailToResponse(detail))
+	resp := customerDetailToResponse(detail)
+	summary, err := h.customerClient.GetCustomerSummary(ctx, &customerv1.GetCustomerSummaryRequest{
+		CustomerId: customerID,
+	})
+	if err != nil {
+		log.Printf("get summary of customer %d: %v", customerID, err)
+	} else {
+		s := customerSummaryToResponse(summary)
+		resp.Summary = &s
+	}
+
+	writeJSON(w, http.StatusOK, resp)
 }
 
 // CreateCustomer creates a new customer.
@@ -215,7 +569,9 @@ func (h *CustomerHandler) UpdateCustomer(w http.ResponseWriter, r *http.Request)
 	writeJSON(w, http.StatusOK, customerToResponse(customer))
 }
 
-// DeleteCustomer deletes a customer by ID.
+// DeleteCustomer erases a customer's personal data. The customer record is
+// anonymized rather than removed, so their rentals and payments are kept for
+// accounting, and the customer is signed out everywhere.
 func (h *CustomerHandler) DeleteCustomer(w http.ResponseWriter, r *http.Request) {
 	customerID, err := parseIntParam(r, "id")
 	if err != nil {
@@ -226,7 +582,7 @@ func (h *CustomerHandler) DeleteCustomer(w http.ResponseWriter, r *http.Request)
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
-	_, err = h.customerClient.DeleteCustomer(ctx, &customerv1.DeleteCustomerRequest{
+	_, err = h.customerClient.AnonymizeCustomer(ctx, &customerv1.AnonymizeCustomerRequest{
 		CustomerId: customerID,
 	})
 	if err != nil {
@@ -234,5 +590,61 @@ func (h *CustomerHandler) DeleteCustomer(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if err := h.refreshStore.DeleteAllForUser(ctx, customerID, auth.RoleCustomer); err != nil {
+		log.Printf("revoke refresh tokens of anonymized customer %d: %v", customerID, err)
+	}
+
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// ExportCustomerData downloads everything held about a customer: profile,
+// address, rentals, payments, reviews, wishlist, loyalty points, stored value
+// and subscriptions. format=zip returns a ZIP archive with one JSON file
+// each; the default, format=json, returns a single JSON file.
+func (h *CustomerHandler) ExportCustomerData(w http.ResponseWriter, r *http.Request) {
+	customerID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid customer id")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "zip" {
+		writeError(w, http.StatusBadRequest, "format must be json or zip")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	export, err := h.customerClient.ExportCustomerData(ctx, &customerv1.ExportCustomerDataRequest{
+		CustomerId: customerID,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	resp := customerExportToResponse(export)
+	name := fmt.Sprintf("customer-%d-export", customerID)
+	if format == "zip" {
+		err = middleware.WriteZIPDownload(w, name+".zip", []middleware.DownloadFile{
+			{Name: "customer.json", Data: resp.Customer},
+			{Name: "rentals.json", Data: resp.Rentals},
+			{Name: "payments.json", Data: resp.Payments},
+			{Name: "reviews.json", Data: resp.Reviews},
+			{Name: "wishlist.json", Data: resp.Wishlist},
+			{Name: "loyalty.json", Data: resp.Loyalty},
+			{Name: "stored-value.json", Data: resp.StoredValue},
+			{Name: "subscriptions.json", Data: resp.Subscriptions},
+		})
+	} else {
+		err = middleware.WriteJSONDownload(w, name+".json", resp)
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to build export")
+	}
+}
@@ -0,0 +1,252 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	customerv1 "github.com/enkaigaku/dvd-rental/gen/proto/customer/v1"
+	"github.com/enkaigaku/dvd-rental/pkg/auth"
+	"github.com/enkaigaku/dvd-rental/pkg/middleware"
+)
+
+// duplicateScanTimeout bounds a duplicate scan, which compares every pair
+// of customers. It stays below the server's write timeout.
+const duplicateScanTimeout = 12 * time.Second
+
+// CustomerDuplicateHandler handles the duplicate customer review queue and
+// customer merges.
+type CustomerDuplicateHandler struct {
+	customerClient customerv1.CustomerServiceClient
+	refreshStore   *auth.RefreshTokenStore
+}
+
+// NewCustomerDuplicateHandler creates a new CustomerDuplicateHandler.
+// refreshStore is the store shared with the customer BFF, used to sign out
+// merged customers.
+func NewCustomerDuplicateHandler(customerClient customerv1.CustomerServiceClient, refreshStore *auth.RefreshTokenStore) *CustomerDuplicateHandler {
+	return &CustomerDuplicateHandler{
+		customerClient: customerClient,
+		refreshStore:   refreshStore,
+	}
+}
+
+// --- JSON models ---
+
+type customerDuplicateResponse struct {
+	DuplicateID   int32                  `json:"duplicate_id"`
+	Customer      customerDetailResponse `json:"customer"`
+	OtherCustomer customerDetailResponse `json:"other_customer"`
+	Score         float64                `json:"score"`
+	NameScore     float64                `json:"name_score"`
+	AddressScore  float64                `json:"address_score"`
+	PhoneScore    float64                `json:"phone_score"`
+	Status        string                 `json:"status"`
+	DetectedAt    string                 `json:"detected_at"`
+	ReviewedAt    string                 `json:"reviewed_at,omitempty"`
+	ReviewedBy    int32                  `json:"reviewed_by,omitempty"`
+}
+
+type customerDuplicateListResponse struct {
+	Duplicates []customerDuplicateResponse `json:"duplicates"`
+	TotalCount int32                       `json:"total_count"`
+}
+
+type scanDuplicatesResponse struct {
+	PendingCount int32 `json:"pending_count"`
+}
+
+type mergeCustomerRequest struct {
+	MergedCustomerID int32 `json:"merged_customer_id"`
+}
+
+type customerMergeResponse struct {
+	MergeID               int32  `json:"merge_id"`
+	SurvivingCustomerID   int32  `json:"surviving_customer_id"`
+	MergedCustomerID      int32  `json:"merged_customer_id"`
+	RentalsMoved          int32  `json:"rentals_moved"`
+	PaymentsMoved         int32  `json:"payments_moved"`
+	SubscriptionsMoved    int32  `json:"subscriptions_moved"`
+	SubscriptionsCanceled int32  `json:"subscriptions_canceled"`
+	GiftCardsMoved        int32  `json:"gift_cards_moved"`
+	StoreCreditMoved      string `json:"store_credit_moved"`
+	LoyaltyPointsMoved    int32  `json:"loyalty_points_moved"`
+	ReviewsMoved          int32  `json:"reviews_moved"`
+	ReviewsDropped        int32  `json:"reviews_dropped"`
+	WishlistItemsMoved    int32  `json:"wishlist_items_moved"`
+	PromotionUsesMoved    int32  `json:"promotion_uses_moved"`
+	MergedBy              int32  `json:"merged_by"`
+	MergedAt              string `json:"merged_at"`
+}
+
+func customerDuplicateToResponse(d *customerv1.CustomerDuplicate) customerDuplicateResponse {
+	score := func(s float64) float64 { return math.Round(s*100) / 100 }
+	resp := customerDuplicateResponse{
+		DuplicateID:   d.GetDuplicateId(),
+		Customer:      customerDetailToResponse(d.GetCustomer()),
+		OtherCustomer: customerDetailToResponse(d.GetOtherCustomer()),
+		Score:         score(d.GetScore()),
+		NameScore:     score(d.GetNameScore()),
+		AddressScore:  score(d.GetAddressScore()),
+		PhoneScore:    score(d.GetPhoneScore()),
+		Status:        d.GetStatus(),
+		DetectedAt:    d.GetDetectedAt().AsTime().Format(time.RFC3339),
+		ReviewedBy:    d.GetReviewedBy(),
+	}
+	if d.GetReviewedAt() != nil {
+		resp.ReviewedAt = d.GetReviewedAt().AsTime().Format(time.RFC3339)
+	}
+	return resp
+}
+
+// ListDuplicates returns the duplicate review queue, likeliest duplicates
+// first, with a summary of each customer's rentals and spend to help pick
+// the one to keep. ?status= lists dismissed or merged pairs instead of
+// pending ones. Pairs are still returned, without summaries, if those
+// cannot be computed.
+func (h *CustomerDuplicateHandler) ListDuplicates(w http.ResponseWriter, r *http.Request) {
+	pageSize, page := parsePagination(r)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.customerClient.ListCustomerDuplicates(ctx, &customerv1.ListCustomerDuplicatesRequest{
+		Status:   r.URL.Query().Get("status"),
+		PageSize: pageSize,
+		Page:     page,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	var ids []int32
+	for _, d := range resp.GetDuplicates() {
+		ids = append(ids, d.GetCustomer().GetCustomer().GetCustomerId(), d.GetOtherCustomer().GetCustomer().GetCustomerId())
+	}
+	summaries := make(map[int32]customerSummaryResponse)
+	if len(ids) > 0 {
+		summaryResp, err := h.customerClient.ListCustomerSummaries(ctx, &customerv1.ListCustomerSummariesRequest{
+			CustomerIds: ids,
+		})
+		if err != nil {
+			log.Printf("get summaries of duplicate customers: %v", err)
+		}
+		for _, s := range summaryResp.GetSummaries() {
+			summaries[s.GetCustomerId()] = customerSummaryToResponse(s)
+		}
+	}
+
+	duplicates := make([]customerDuplicateResponse, len(resp.GetDuplicates()))
+	for i, d := range resp.GetDuplicates() {
+		duplicates[i] = customerDuplicateToResponse(d)
+		if s, ok := summaries[duplicates[i].Customer.CustomerID]; ok {
+			duplicates[i].Customer.Summary = &s
+		}
+		if s, ok := summaries[duplicates[i].OtherCustomer.CustomerID]; ok {
+			duplicates[i].OtherCustomer.Summary = &s
+		}
+	}
+
+	writeJSON(w, http.StatusOK, customerDuplicateListResponse{
+		Duplicates: duplicates,
+		TotalCount: resp.GetTotalCount(),
+	})
+}
+
+// ScanDuplicates looks for likely duplicate customers by name, address and
+// phone similarity and refreshes the pending review queue.
+func (h *CustomerDuplicateHandler) ScanDuplicates(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), duplicateScanTimeout)
+	defer cancel()
+
+	resp, err := h.customerClient.ScanDuplicateCustomers(ctx, &customerv1.ScanDuplicateCustomersRequest{})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, scanDuplicatesResponse{PendingCount: resp.GetPendingCount()})
+}
+
+// DismissDuplicate takes a pair off the review queue as two different
+// people. The signed-in staff member is recorded as the reviewer.
+func (h *CustomerDuplicateHandler) DismissDuplicate(w http.ResponseWriter, r *http.Request) {
+	duplicateID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid duplicate id")
+		return
+	}
+	claims := middleware.GetClaims(r.Context())
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	duplicate, err := h.customerClient.DismissCustomerDuplicate(ctx, &customerv1.DismissCustomerDuplicateRequest{
+		DuplicateId: duplicateID,
+		StaffId:     claims.UserID,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, customerDuplicateToResponse(duplicate))
+}
+
+// MergeCustomer merges a duplicate customer into the customer in the path:
+// the duplicate's rentals and payments move over, the duplicate is
+// deactivated and signed out, and the signed-in staff member is recorded
+// as having merged them.
+func (h *CustomerDuplicateHandler) MergeCustomer(w http.ResponseWriter, r *http.Request) {
+	customerID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid customer id")
+		return
+	}
+
+	var req mergeCustomerRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+	claims := middleware.GetClaims(r.Context())
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	merge, err := h.customerClient.MergeCustomers(ctx, &customerv1.MergeCustomersRequest{
+		SurvivingCustomerId: customerID,
+		MergedCustomerId:    req.MergedCustomerID,
+		StaffId:             claims.UserID,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	if err := h.refreshStore.DeleteAllForUser(ctx, merge.GetMergedCustomerId(), auth.RoleCustomer); err != nil {
+		log.Printf("revoke refresh tokens of merged customer %d: %v", merge.GetMergedCustomerId(), err)
+	}
+
+	writeJSON(w, http.StatusOK, customerMergeResponse{
+		MergeID:               merge.GetMergeId(),
+		SurvivingCustomerID:   merge.GetSurvivingCustomerId(),
+		MergedCustomerID:      merge.GetMergedCustomerId(),
+		RentalsMoved:          merge.GetRentalsMoved(),
+		PaymentsMoved:         merge.GetPaymentsMoved(),
+		SubscriptionsMoved:    merge.GetSubscriptionsMoved(),
+		SubscriptionsCanceled: merge.GetSubscriptionsCanceled(),
+		GiftCardsMoved:        merge.GetGiftCardsMoved(),
+		StoreCreditMoved:      merge.GetStoreCreditMoved(),
+		LoyaltyPointsMoved:    merge.GetLoyaltyPointsMoved(),
+		ReviewsMoved:          merge.GetReviewsMoved(),
+		ReviewsDropped:        merge.GetReviewsDropped(),
+		WishlistItemsMoved:    merge.GetWishlistItemsMoved(),
+		PromotionUsesMoved:    merge.GetPromotionUsesMoved(),
+		MergedBy:              merge.GetMergedBy(),
+		MergedAt:              merge.GetMergedAt().AsTime().Format(time.RFC3339),
+	})
+}
@@ -2,7 +2,10 @@ package handler
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	filmv1 "github.com/enkaigaku/dvd-rental/gen/proto/film/v1"
@@ -34,32 +37,50 @@ func NewFilmHandler(
 // --- JSON models ---
 
 type filmResponse struct {
-	FilmID             int32    `json:"film_id"`
-	Title              string   `json:"title"`
-	Description        string   `json:"description"`
-	ReleaseYear        int32    `json:"release_year"`
-	LanguageID         int32    `json:"language_id"`
-	OriginalLanguageID int32    `json:"original_language_id,omitempty"`
-	RentalDuration     int32    `json:"rental_duration"`
-	RentalRate         string   `json:"rental_rate"`
-	Length             int32    `json:"length"`
-	ReplacementCost    string   `json:"replacement_cost"`
-	Rating             string   `json:"rating"`
-	SpecialFeatures    []string `json:"special_features"`
-	LastUpdate         string   `json:"last_update"`
+	FilmID             int32              `json:"film_id"`
+	Title              string             `json:"title"`
+	Description        string             `json:"description"`
+	ReleaseYear        int32              `json:"release_year"`
+	LanguageID         int32              `json:"language_id"`
+	OriginalLanguageID int32              `json:"original_language_id,omitempty"`
+	RentalDuration     int32              `json:"rental_duration"`
+	RentalRate         string             `json:"rental_rate"`
+	Length             int32              `json:"length"`
+	ReplacementCost    string             `json:"replacement_cost"`
+	Rating             string             `json:"rating"`
+	SpecialFeatures    []string           `json:"special_features"`
+	LastUpdate         string             `json:"last_update"`
+	ArchivedAt         string             `json:"archived_at,omitempty"`
+	Poster             *filmAssetResponse `json:"poster,omitempty"`
 }
 
 type filmDetailResponse struct {
 	filmResponse
-	LanguageName         string             `json:"language_name"`
-	OriginalLanguageName string             `json:"original_language_name,omitempty"`
-	Actors               []actorResponse    `json:"actors"`
-	Categories           []categoryResponse `json:"categories"`
+	LanguageName         string              `json:"language_name"`
+	OriginalLanguageName string              `json:"original_language_name,omitempty"`
+	Actors               []actorResponse     `json:"actors"`
+	Categories           []categoryResponse  `json:"categories"`
+	AverageRating        string              `json:"average_rating,omitempty"`
+	ReviewCount          int32               `json:"review_count"`
+	Stills               []filmAssetResponse `json:"stills"`
 }
 
 type filmListResponse struct {
-	Films      []filmResponse `json:"films"`
-	TotalCount int32          `json:"total_count"`
+	Films      []filmResponse      `json:"films"`
+	TotalCount int32               `json:"total_count"`
+	Facets     *filmFacetsResponse `json:"facets,omitempty"`
+}
+
+type facetCountResponse struct {
+	ID    int32  `json:"id,omitempty"`
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+type filmFacetsResponse struct {
+	Ratings    []facetCountResponse `json:"ratings"`
+	Categories []facetCountResponse `json:"categories"`
+	Languages  []facetCountResponse `json:"languages"`
 }
 
 type actorResponse struct {
@@ -67,6 +88,7 @@ type actorResponse struct {
 	FirstName  string `json:"first_name"`
 	LastName   string `json:"last_name"`
 	LastUpdate string `json:"last_update"`
+	ArchivedAt string `json:"archived_at,omitempty"`
 }
 
 type actorListResponse struct {
@@ -119,6 +141,14 @@ type createActorRequest struct {
 
 type updateActorRequest = createActorRequest
 
+type categoryRequest struct {
+	Name string `json:"name"`
+}
+
+type languageRequest struct {
+	Name string `json:"name"`
+}
+
 type filmActorRequest struct {
 	ActorID int32 `json:"actor_id"`
 }
@@ -128,7 +158,7 @@ type filmCategoryRequest struct {
 }
 
 func filmToResponse(f *filmv1.Film) filmResponse {
-	return filmResponse{
+	resp := filmResponse{
 		FilmID:             f.GetFilmId(),
 		Title:              f.GetTitle(),
 		Description:        f.GetDescription(),
@@ -142,16 +172,25 @@ func filmToResponse(f *filmv1.Film) filmResponse {
 		Rating:             f.GetRating(),
 		SpecialFeatures:    f.GetSpecialFeatures(),
 		LastUpdate:         f.GetLastUpdate().AsTime().Format(time.RFC3339),
+		Poster:             filmAssetToResponseOrNil(f.GetPoster()),
 	}
+	if f.GetArchivedAt() != nil {
+		resp.ArchivedAt = f.GetArchivedAt().AsTime().Format(time.RFC3339)
+	}
+	return resp
 }
 
 func actorToResponse(a *filmv1.Actor) actorResponse {
-	return actorResponse{
+	resp := actorResponse{
 		ActorID:    a.GetActorId(),
 		FirstName:  a.GetFirstName(),
 		LastName:   a.GetLastName(),
 		LastUpdate: a.GetLastUpdate().AsTime().Format(time.RFC3339),
 	}
+	if a.GetArchivedAt() != nil {
+		resp.ArchivedAt = a.GetArchivedAt().AsTime().Format(time.RFC3339)
+	}
+	return resp
 }
 
 func categoryToResponse(c *filmv1.Category) categoryResponse {
@@ -172,44 +211,60 @@ func languageToResponse(l *filmv1.Language) languageResponse {
 
 // --- Film endpoints ---
 
-// ListFilms returns a paginated list of films.
+func facetCountsToResponse(counts []*filmv1.FacetCount) []facetCountResponse {
+	resp := make([]facetCountResponse, len(counts))
+	for i, c := range counts {
+		resp[i] = facetCountResponse{
+			ID:    c.GetId(),
+			Value: c.GetValue(),
+			Count: c.GetCount(),
+		}
+	}
+	return resp
+}
+
+// ListFilms returns a paginated list of films. With ?q= it runs a title and
+// description search; otherwise the filters rating, category_id, actor_id, language_id,
+// min_year, max_year, min_length, max_length, min_rate, max_rate and feature
+// can be combined, sorted with sort= and order=desc, and facets=true adds
+// counts per rating, category and language.
 func (h *FilmHandler) ListFilms(w http.ResponseWriter, r *http.Request) {
 	pageSize, page := parsePagination(r)
-	categoryID := parseQueryInt32(r, "category_id")
-	actorID := parseQueryInt32(r, "actor_id")
 	query := r.URL.Query().Get("q")
 
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
-	var resp *filmv1.ListFilmsResponse
-	var err error
-
-	switch {
-	case query != "":
-		resp, err = h.filmClient.SearchFilms(ctx, &filmv1.SearchFilmsRequest{
+	if query != "" {
+		resp, err := h.filmClient.SearchFilms(ctx, &filmv1.SearchFilmsRequest{
 			Query:    query,
 			PageSize: pageSize,
 			Page:     page,
 		})
-	case categoryID > 0:
-		resp, err = h.filmClient.ListFilmsByCategory(ctx, &filmv1.ListFilmsByCategoryRequest{
-			CategoryId: categoryID,
-			PageSize:   pageSize,
-			Page:       page,
-		})
-	case actorID > 0:
-		resp, err = h.filmClient.ListFilmsByActor(ctx, &filmv1.ListFilmsByActorRequest{
-			ActorId:  actorID,
-			PageSize: pageSize,
-			Page:     page,
-		})
-	default:
-		resp, err = h.filmClient.ListFilms(ctx, &filmv1.ListFilmsRequest{
-			PageSize: pageSize,
-			Page:     page,
+		if err != nil {
+			handleGRPCError(w, err)
+			return
+		}
+		films := make([]filmResponse, len(resp.GetHits()))
+		for i, hit := range resp.GetHits() {
+			films[i] = filmToResponse(hit.GetFilm())
+		}
+		writeJSON(w, http.StatusOK, filmListResponse{
+			Films:      films,
+			TotalCount: resp.GetTotalCount(),
 		})
+		return
+	}
+
+	req, err := parseFilmBrowseQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
 	}
+	req.PageSize = pageSize
+	req.Page = page
+	req.IncludeArchived = true
+	resp, err := h.filmClient.ListFilms(ctx, req)
 	if err != nil {
 		handleGRPCError(w, err)
 		return
@@ -220,10 +275,78 @@ func (h *FilmHandler) ListFilms(w http.ResponseWriter, r *http.Request) {
 		films[i] = filmToResponse(f)
 	}
 
-	writeJSON(w, http.StatusOK, filmListResponse{
+	out := filmListResponse{
 		Films:      films,
 		TotalCount: resp.GetTotalCount(),
-	})
+	}
+	if f := resp.GetFacets(); f != nil {
+		out.Facets = &filmFacetsResponse{
+			Ratings:    facetCountsToResponse(f.GetRatings()),
+			Categories: facetCountsToResponse(f.GetCategories()),
+			Languages:  facetCountsToResponse(f.GetLanguages()),
+		}
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// parseFilmBrowseQuery reads the film browsing filters and sort order from
+// the query string. Repeatable parameters also accept comma-separated values.
+func parseFilmBrowseQuery(r *http.Request) (*filmv1.ListFilmsRequest, error) {
+	q := r.URL.Query()
+	req := &filmv1.ListFilmsRequest{
+		Ratings:         splitQueryValues(q["rating"]),
+		MinRentalRate:   q.Get("min_rate"),
+		MaxRentalRate:   q.Get("max_rate"),
+		SpecialFeatures: splitQueryValues(q["feature"]),
+		SortBy:          q.Get("sort"),
+		IncludeFacets:   parseQueryBool(r, "facets"),
+	}
+
+	switch q.Get("order") {
+	case "", "asc":
+	case "desc":
+		req.Descending = true
+	default:
+		return nil, fmt.Errorf("invalid order: must be asc or desc")
+	}
+
+	ints := []struct {
+		name string
+		dst  *int32
+	}{
+		{"category_id", &req.CategoryId},
+		{"actor_id", &req.ActorId},
+		{"language_id", &req.LanguageId},
+		{"min_year", &req.MinReleaseYear},
+		{"max_year", &req.MaxReleaseYear},
+		{"min_length", &req.MinLength},
+		{"max_length", &req.MaxLength},
+	}
+	for _, p := range ints {
+		s := q.Get(p.name)
+		if s == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: must be a number", p.name)
+		}
+		*p.dst = int32(n)
+	}
+	return req, nil
+}
+
+// splitQueryValues flattens repeated and comma-separated query values.
+func splitQueryValues(values []string) []string {
+	var out []string
+	for _, v := range values {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+	}
+	return out
 }
 
 // GetFilm returns a single film with full details.
@@ -238,7 +361,8 @@ func (h *FilmHandler) GetFilm(w http.ResponseWriter, r *http.Request) {
 	defer cancel()
 
 	detail, err := h.filmClient.GetFilm(ctx, &filmv1.GetFilmRequest{
-		FilmId: filmID,
+		FilmId:          filmID,
+		IncludeArchived: true,
 	})
 	if err != nil {
 		handleGRPCError(w, err)
@@ -255,12 +379,20 @@ func (h *FilmHandler) GetFilm(w http.ResponseWriter, r *http.Request) {
 		categories[i] = categoryToResponse(c)
 	}
 
+	stills := make([]filmAssetResponse, len(detail.GetStills()))
+	for i, a := range detail.GetStills() {
+		stills[i] = filmAssetToResponse(a)
+	}
+
 	writeJSON(w, http.StatusOK, filmDetailResponse{
 		filmResponse:         filmToResponse(detail.GetFilm()),
 		LanguageName:         detail.GetLanguageName(),
 		OriginalLanguageName: detail.GetOriginalLanguageName(),
 		Actors:               actors,
 		Categories:           categories,
+		AverageRating:        detail.GetAverageRating(),
+		ReviewCount:          detail.GetReviewCount(),
+		Stills:               stills,
 	})
 }
 
@@ -335,7 +467,7 @@ func (h *FilmHandler) UpdateFilm(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, filmToResponse(film))
 }
 
-// DeleteFilm deletes a film by ID.
+// DeleteFilm archives a film by ID.
 func (h *FilmHandler) DeleteFilm(w http.ResponseWriter, r *http.Request) {
 	filmID, err := parseIntParam(r, "id")
 	if err != nil {
@@ -357,6 +489,28 @@ func (h *FilmHandler) DeleteFilm(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// RestoreFilm restores an archived film.
+func (h *FilmHandler) RestoreFilm(w http.ResponseWriter, r *http.Request) {
+	filmID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid film id")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	film, err := h.filmClient.RestoreFilm(ctx, &filmv1.RestoreFilmRequest{
+		FilmId: filmID,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, filmToResponse(film))
+}
+
 // AddActorToFilm adds an actor to a film.
 func (h *FilmHandler) AddActorToFilm(w http.ResponseWriter, r *http.Request) {
 	filmID, err := parseIntParam(r, "id")
@@ -475,17 +629,32 @@ func (h *FilmHandler) RemoveCategoryFromFilm(w http.ResponseWriter, r *http.Requ
 
 // --- Actor endpoints ---
 
-// ListActors returns a paginated list of actors.
+// ListActors returns a paginated list of actors, archived ones included.
+// With ?q= it searches actor names instead.
 func (h *FilmHandler) ListActors(w http.ResponseWriter, r *http.Request) {
 	pageSize, page := parsePagination(r)
+	query := r.URL.Query().Get("q")
 
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
-	resp, err := h.actorClient.ListActors(ctx, &filmv1.ListActorsRequest{
-		PageSize: pageSize,
-		Page:     page,
-	})
+	var resp *filmv1.ListActorsResponse
+	var err error
+
+	if query != "" {
+		resp, err = h.actorClient.SearchActors(ctx, &filmv1.SearchActorsRequest{
+			Query:           query,
+			PageSize:        pageSize,
+			Page:            page,
+			IncludeArchived: true,
+		})
+	} else {
+		resp, err = h.actorClient.ListActors(ctx, &filmv1.ListActorsRequest{
+			PageSize:        pageSize,
+			Page:            page,
+			IncludeArchived: true,
+		})
+	}
 	if err != nil {
 		handleGRPCError(w, err)
 		return
@@ -577,7 +746,7 @@ func (h *FilmHandler) UpdateActor(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, actorToResponse(actor))
 }
 
-// DeleteActor deletes an actor by ID.
+// DeleteActor archives an actor by ID.
 func (h *FilmHandler) DeleteActor(w http.ResponseWriter, r *http.Request) {
 	actorID, err := parseIntParam(r, "id")
 	if err != nil {
@@ -599,7 +768,29 @@ func (h *FilmHandler) DeleteActor(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// --- Category endpoints (read-only) ---
+// RestoreActor restores an archived actor.
+func (h *FilmHandler) RestoreActor(w http.ResponseWriter, r *http.Request) {
+	actorID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid actor id")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	actor, err := h.actorClient.RestoreActor(ctx, &filmv1.RestoreActorRequest{
+		ActorId: actorID,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, actorToResponse(actor))
+}
+
+// --- Category endpoints ---
 
 // ListCategories returns all categories.
 func (h *FilmHandler) ListCategories(w http.ResponseWriter, r *http.Request) {
@@ -628,7 +819,104 @@ func (h *FilmHandler) ListCategories(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// --- Language endpoints (read-only) ---
+// GetCategory returns a single category.
+func (h *FilmHandler) GetCategory(w http.ResponseWriter, r *http.Request) {
+	categoryID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid category id")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	category, err := h.categoryClient.GetCategory(ctx, &filmv1.GetCategoryRequest{
+		CategoryId: categoryID,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, categoryToResponse(category))
+}
+
+// CreateCategory creates a new category.
+func (h *FilmHandler) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	var req categoryRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	category, err := h.categoryClient.CreateCategory(ctx, &filmv1.CreateCategoryRequest{
+		Name: req.Name,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, categoryToResponse(category))
+}
+
+// UpdateCategory renames a category.
+func (h *FilmHandler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	categoryID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid category id")
+		return
+	}
+
+	var req categoryRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	category, err := h.categoryClient.UpdateCategory(ctx, &filmv1.UpdateCategoryRequest{
+		CategoryId: categoryID,
+		Name:       req.Name,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, categoryToResponse(category))
+}
+
+// DeleteCategory deletes a category. It is refused while films are in it, unless
+// the reassign_to query parameter names a category to move them to first.
+func (h *FilmHandler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	categoryID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid category id")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	_, err = h.categoryClient.DeleteCategory(ctx, &filmv1.DeleteCategoryRequest{
+		CategoryId: categoryID,
+		ReassignTo: parseQueryInt32(r, "reassign_to"),
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// --- Language endpoints ---
 
 // ListLanguages returns all languages.
 func (h *FilmHandler) ListLanguages(w http.ResponseWriter, r *http.Request) {
@@ -656,3 +944,100 @@ func (h *FilmHandler) ListLanguages(w http.ResponseWriter, r *http.Request) {
 		TotalCount: resp.GetTotalCount(),
 	})
 }
+
+// GetLanguage returns a single language.
+func (h *FilmHandler) GetLanguage(w http.ResponseWriter, r *http.Request) {
+	languageID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid language id")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	language, err := h.languageClient.GetLanguage(ctx, &filmv1.GetLanguageRequest{
+		LanguageId: languageID,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, languageToResponse(language))
+}
+
+// CreateLanguage creates a new language.
+func (h *FilmHandler) CreateLanguage(w http.ResponseWriter, r *http.Request) {
+	var req languageRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	language, err := h.languageClient.CreateLanguage(ctx, &filmv1.CreateLanguageRequest{
+		Name: req.Name,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, languageToResponse(language))
+}
+
+// UpdateLanguage renames a language.
+func (h *FilmHandler) UpdateLanguage(w http.ResponseWriter, r *http.Request) {
+	languageID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid language id")
+		return
+	}
+
+	var req languageRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	language, err := h.languageClient.UpdateLanguage(ctx, &filmv1.UpdateLanguageRequest{
+		LanguageId: languageID,
+		Name:       req.Name,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, languageToResponse(language))
+}
+
+// DeleteLanguage deletes a language. It is refused while films use it, unless
+// the reassign_to query parameter names a language to switch them to first.
+func (h *FilmHandler) DeleteLanguage(w http.ResponseWriter, r *http.Request) {
+	languageID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid language id")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	_, err = h.languageClient.DeleteLanguage(ctx, &filmv1.DeleteLanguageRequest{
+		LanguageId: languageID,
+		ReassignTo: parseQueryInt32(r, "reassign_to"),
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
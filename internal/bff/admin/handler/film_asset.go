@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	filmv1 "github.com/enkaigaku/dvd-rental/gen/proto/film/v1"
+)
+
+const (
+	// maxAssetUploadBytes is the largest image accepted, the film service's
+	// limit.
+	maxAssetUploadBytes = 10 << 20
+	// assetChunkSize is the size of the pieces images are streamed to the
+	// film service in.
+	assetChunkSize = 64 << 10
+	// assetUploadTimeout bounds an upload, which includes rendering the
+	// smaller sizes of the image.
+	assetUploadTimeout = 10 * time.Second
+)
+
+// FilmAssetHandler handles film poster and still endpoints.
+type FilmAssetHandler struct {
+	assetClient filmv1.FilmAssetServiceClient
+}
+
+// NewFilmAssetHandler creates a new FilmAssetHandler.
+func NewFilmAssetHandler(assetClient filmv1.FilmAssetServiceClient) *FilmAssetHandler {
+	return &FilmAssetHandler{assetClient: assetClient}
+}
+
+// --- JSON models ---
+
+type filmAssetResponse struct {
+	AssetID     int32    `json:"asset_id"`
+	FilmID      int32    `json:"film_id"`
+	Kind        string   `json:"kind"`
+	ContentType string   `json:"content_type"`
+	Width       int32    `json:"width"`
+	Height      int32    `json:"height"`
+	Sizes       []string `json:"sizes"`
+	CreatedAt   string   `json:"created_at"`
+}
+
+type filmAssetListResponse struct {
+	Assets []filmAssetResponse `json:"assets"`
+}
+
+// --- Converters ---
+
+func filmAssetToResponse(a *filmv1.FilmAsset) filmAssetResponse {
+	return filmAssetResponse{
+		AssetID:     a.GetAssetId(),
+		FilmID:      a.GetFilmId(),
+		Kind:        a.GetKind(),
+		ContentType: a.GetContentType(),
+		Width:       a.GetWidth(),
+		Height:      a.GetHeight(),
+		Sizes:       a.GetSizes(),
+		CreatedAt:   a.GetCreatedAt().AsTime().Format(time.RFC3339),
+	}
+}
+
+func filmAssetToResponseOrNil(a *filmv1.FilmAsset) *filmAssetResponse {
+	if a == nil {
+		return nil
+	}
+	resp := filmAssetToResponse(a)
+	return &resp
+}
+
+// ListFilmAssets returns a film's poster and stills.
+func (h *FilmAssetHandler) ListFilmAssets(w http.ResponseWriter, r *http.Request) {
+	filmID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid film id")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.assetClient.ListFilmAssets(ctx, &filmv1.ListFilmAssetsRequest{FilmId: filmID})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	assets := make([]filmAssetResponse, len(resp.GetAssets()))
+	for i, a := range resp.GetAssets() {
+		assets[i] = filmAssetToResponse(a)
+	}
+
+	writeJSON(w, http.StatusOK, filmAssetListResponse{Assets: assets})
+}
+
+// UploadFilmAsset uploads a film's poster or a still from a multipart form
+// with a kind field ("poster" or "still") and the JPEG or PNG image in an
+// image field. A new poster replaces the film's previous one.
+func (h *FilmAssetHandler) UploadFilmAsset(w http.ResponseWriter, r *http.Request) {
+	filmID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid film id")
+		return
+	}
+
+	// Leave room for the rest of the form around the image.
+	r.Body = http.MaxBytesReader(w, r.Body, maxAssetUploadBytes+1<<20)
+	if err := r.ParseMultipartForm(maxAssetUploadBytes); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, "image too large")
+			return
+		}
+		writeError(w, http.StatusBadRequest, "invalid multipart form: "+err.Error())
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("image")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "image file is required")
+		return
+	}
+	defer file.Close()
+	if header.Size > maxAssetUploadBytes {
+		writeError(w, http.StatusRequestEntityTooLarge, "image too large")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), assetUploadTimeout)
+	defer cancel()
+
+	stream, err := h.assetClient.UploadFilmAsset(ctx)
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	req := &filmv1.UploadFilmAssetRequest{FilmId: filmID, Kind: r.FormValue("kind")}
+	buf := make([]byte, assetChunkSize)
+	for {
+		n, readErr := io.ReadFull(file, buf)
+		if n > 0 || req.FilmId != 0 {
+			req.Chunk = buf[:n]
+			if err := stream.Send(req); err != nil {
+				// The service ended the stream; CloseAndRecv returns its error.
+				break
+			}
+			req = &filmv1.UploadFilmAssetRequest{}
+		}
+		if errors.Is(readErr, io.EOF) || errors.Is(readErr, io.ErrUnexpectedEOF) {
+			break
+		}
+		if readErr != nil {
+			writeError(w, http.StatusBadRequest, "read image: "+readErr.Error())
+			return
+		}
+	}
+
+	asset, err := stream.CloseAndRecv()
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, filmAssetToResponse(asset))
+}
+
+// DeleteFilmAsset deletes one of a film's assets.
+func (h *FilmAssetHandler) DeleteFilmAsset(w http.ResponseWriter, r *http.Request) {
+	filmID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid film id")
+		return
+	}
+	assetID, err := parseIntParam(r, "assetId")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid asset id")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	_, err = h.assetClient.DeleteFilmAsset(ctx, &filmv1.DeleteFilmAssetRequest{
+		FilmId:  filmID,
+		AssetId: assetID,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
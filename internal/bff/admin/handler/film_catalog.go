@@ -0,0 +1,486 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	filmv1 "github.com/enkaigaku/dvd-rental/gen/proto/film/v1"
+)
+
+// catalogTransferTimeout bounds a bulk import or export, which can take
+// far longer than the server's default read and write timeouts.
+const catalogTransferTimeout = 10 * time.Minute
+
+// csvListSeparator separates the values of list columns (special_features,
+// actors, categories) within a CSV cell.
+const csvListSeparator = "|"
+
+// csvColumns are the columns of the bulk CSV format, in export order.
+// film_id is exported only and ignored on import.
+var csvColumns = []string{
+	"film_id", "title", "description", "release_year", "language",
+	"original_language", "rental_duration", "rental_rate", "length",
+	"replacement_cost", "rating", "special_features", "actors", "categories",
+}
+
+// --- JSON models ---
+
+// filmRecordJSON is a film in the bulk JSON Lines format.
+type filmRecordJSON struct {
+	FilmID           int32    `json:"film_id,omitempty"`
+	Title            string   `json:"title"`
+	Description      string   `json:"description,omitempty"`
+	ReleaseYear      int32    `json:"release_year,omitempty"`
+	Language         string   `json:"language"`
+	OriginalLanguage string   `json:"original_language,omitempty"`
+	RentalDuration   int32    `json:"rental_duration,omitempty"`
+	RentalRate       string   `json:"rental_rate,omitempty"`
+	Length           int32    `json:"length,omitempty"`
+	ReplacementCost  string   `json:"replacement_cost,omitempty"`
+	Rating           string   `json:"rating,omitempty"`
+	SpecialFeatures  []string `json:"special_features,omitempty"`
+	Actors           []string `json:"actors,omitempty"`
+	Categories       []string `json:"categories,omitempty"`
+}
+
+type importRowErrorResponse struct {
+	Row     int32  `json:"row"`
+	Title   string `json:"title,omitempty"`
+	Message string `json:"message"`
+}
+
+type importFilmsResponse struct {
+	DryRun        bool                     `json:"dry_run"`
+	Rows          int32                    `json:"rows"`
+	Imported      int32                    `json:"imported"`
+	Failed        int32                    `json:"failed"`
+	ActorsCreated int32                    `json:"actors_created"`
+	Errors        []importRowErrorResponse `json:"errors"`
+}
+
+func filmRecordToJSON(r *filmv1.FilmRecord) filmRecordJSON {
+	return filmRecordJSON{
+		FilmID:           r.GetFilmId(),
+		Title:            r.GetTitle(),
+		Description:      r.GetDescription(),
+		ReleaseYear:      r.GetReleaseYear(),
+		Language:         r.GetLanguage(),
+		OriginalLanguage: r.GetOriginalLanguage(),
+		RentalDuration:   r.GetRentalDuration(),
+		RentalRate:       r.GetRentalRate(),
+		Length:           r.GetLength(),
+		ReplacementCost:  r.GetReplacementCost(),
+		Rating:           r.GetRating(),
+		SpecialFeatures:  r.GetSpecialFeatures(),
+		Actors:           r.GetActors(),
+		Categories:       r.GetCategories(),
+	}
+}
+
+func filmRecordFromJSON(r filmRecordJSON) *filmv1.FilmRecord {
+	return &filmv1.FilmRecord{
+		Title:            r.Title,
+		Description:      r.Description,
+		ReleaseYear:      r.ReleaseYear,
+		Language:         r.Language,
+		OriginalLanguage: r.OriginalLanguage,
+		RentalDuration:   r.RentalDuration,
+		RentalRate:       r.RentalRate,
+		Length:           r.Length,
+		ReplacementCost:  r.ReplacementCost,
+		Rating:           r.Rating,
+		SpecialFeatures:  r.SpecialFeatures,
+		Actors:           r.Actors,
+		Categories:       r.Categories,
+	}
+}
+
+// --- Formats ---
+
+// rowError is a malformed row in an import file. It is reported against
+// the row and the import carries on; any other read error aborts it.
+type rowError struct {
+	err error
+}
+
+func (e rowError) Error() string { return e.err.Error() }
+
+// filmRecordReader reads films from an import file one row at a time,
+// returning io.EOF after the last.
+type filmRecordReader interface {
+	Read() (*filmv1.FilmRecord, error)
+}
+
+// filmRecordWriter writes films to an export file.
+type filmRecordWriter interface {
+	Write(*filmv1.FilmRecord) error
+	Flush() error
+}
+
+// catalogFormat returns the bulk file format requested by the "format"
+// query parameter, or else by the request's Content-Type: "csv" (the
+// default) or "jsonl".
+func catalogFormat(r *http.Request) (string, error) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		switch strings.TrimSpace(strings.Split(r.Header.Get("Content-Type"), ";")[0]) {
+		case "application/x-ndjson", "application/jsonl":
+			format = "jsonl"
+		default:
+			format = "csv"
+		}
+	}
+	if format != "csv" && format != "jsonl" {
+		return "", fmt.Errorf("format must be csv or jsonl")
+	}
+	return format, nil
+}
+
+type csvFilmReader struct {
+	r       *csv.Reader
+	columns map[string]int
+}
+
+// newCSVFilmReader reads the header row, which names the columns present;
+// title and language are required. Every row must have a field per column.
+func newCSVFilmReader(body io.Reader) (*csvFilmReader, error) {
+	r := csv.NewReader(body)
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read CSV header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(csvColumns, name) {
+			return nil, fmt.Errorf("unknown CSV column %q", name)
+		}
+		columns[name] = i
+	}
+	for _, name := range []string{"title", "language"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("CSV column %q is required", name)
+		}
+	}
+	return &csvFilmReader{r: r, columns: columns}, nil
+}
+
+func (c *csvFilmReader) Read() (*filmv1.FilmRecord, error) {
+	fields, err := c.r.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, rowError{err: parseErr.Err}
+		}
+		return nil, err
+	}
+
+	get := func(name string) string {
+		if i, ok := c.columns[name]; ok {
+			return strings.TrimSpace(fields[i])
+		}
+		return ""
+	}
+	list := func(name string) []string {
+		var values []string
+		for _, v := range strings.Split(get(name), csvListSeparator) {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		return values
+	}
+	number := func(name string) (int32, error) {
+		s := get(name)
+		if s == "" {
+			return 0, nil
+		}
+		n, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			return 0, rowError{err: fmt.Errorf("invalid %s %q", name, s)}
+		}
+		return int32(n), nil
+	}
+
+	rec := &filmv1.FilmRecord{
+		Title:            get("title"),
+		Description:      get("description"),
+		Language:         get("language"),
+		OriginalLanguage: get("original_language"),
+		RentalRate:       get("rental_rate"),
+		ReplacementCost:  get("replacement_cost"),
+		Rating:           get("rating"),
+		SpecialFeatures:  list("special_features"),
+		Actors:           list("actors"),
+		Categories:       list("categories"),
+	}
+	if rec.ReleaseYear, err = number("release_year"); err != nil {
+		return nil, err
+	}
+	if rec.RentalDuration, err = number("rental_duration"); err != nil {
+		return nil, err
+	}
+	if rec.Length, err = number("length"); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+type csvFilmWriter struct {
+	w *csv.Writer
+}
+
+func newCSVFilmWriter(w io.Writer) (*csvFilmWriter, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvColumns); err != nil {
+		return nil, err
+	}
+	return &csvFilmWriter{w: cw}, nil
+}
+
+func (c *csvFilmWriter) Write(r *filmv1.FilmRecord) error {
+	number := func(n int32) string {
+		if n == 0 {
+			return ""
+		}
+		return strconv.Itoa(int(n))
+	}
+	return c.w.Write([]string{
+		strconv.Itoa(int(r.GetFilmId())),
+		r.GetTitle(),
+		r.GetDescription(),
+		number(r.GetReleaseYear()),
+		r.GetLanguage(),
+		r.GetOriginalLanguage(),
+		number(r.GetRentalDuration()),
+		r.GetRentalRate(),
+		number(r.GetLength()),
+		r.GetReplacementCost(),
+		r.GetRating(),
+		strings.Join(r.GetSpecialFeatures(), csvListSeparator),
+		strings.Join(r.GetActors(), csvListSeparator),
+		strings.Join(r.GetCategories(), csvListSeparator),
+	})
+}
+
+func (c *csvFilmWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type jsonlFilmReader struct {
+	s *bufio.Scanner
+}
+
+func newJSONLFilmReader(body io.Reader) *jsonlFilmReader {
+	s := bufio.NewScanner(body)
+	s.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	return &jsonlFilmReader{s: s}
+}
+
+func (j *jsonlFilmReader) Read() (*filmv1.FilmRecord, error) {
+	for j.s.Scan() {
+		line := bytes.TrimSpace(j.s.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var rec filmRecordJSON
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return nil, rowError{err: fmt.Errorf("invalid JSON: %w", err)}
+		}
+		return filmRecordFromJSON(rec), nil
+	}
+	if err := j.s.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+type jsonlFilmWriter struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func newJSONLFilmWriter(w io.Writer) *jsonlFilmWriter {
+	bw := bufio.NewWriter(w)
+	return &jsonlFilmWriter{w: bw, enc: json.NewEncoder(bw)}
+}
+
+func (j *jsonlFilmWriter) Write(r *filmv1.FilmRecord) error {
+	return j.enc.Encode(filmRecordToJSON(r))
+}
+
+func (j *jsonlFilmWriter) Flush() error {
+	return j.w.Flush()
+}
+
+// --- Handlers ---
+
+// ImportFilms bulk imports films from a CSV or JSON Lines request body,
+// streaming them to the film service. Each row is imported on its own and
+// rejected rows, malformed or invalid, are listed in the report by their
+// row number (the first row after any CSV header is 1). With dry_run=true
+// every row is checked but nothing is written.
+func (h *FilmHandler) ImportFilms(w http.ResponseWriter, r *http.Request) {
+	format, err := catalogFormat(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	dryRun := parseQueryBool(r, "dry_run")
+
+	rc := http.NewResponseController(w)
+	rc.SetReadDeadline(time.Now().Add(catalogTransferTimeout))
+	rc.SetWriteDeadline(time.Now().Add(catalogTransferTimeout))
+
+	var reader filmRecordReader
+	if format == "csv" {
+		csvReader, err := newCSVFilmReader(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		reader = csvReader
+	} else {
+		reader = newJSONLFilmReader(r.Body)
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), catalogTransferTimeout)
+	defer cancel()
+
+	stream, err := h.filmClient.ImportFilms(ctx)
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	var (
+		sentRows  []int32 // input row number of each film sent, by stream position
+		rowErrors []importRowErrorResponse
+		row       int32
+	)
+	for {
+		rec, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		row++
+		var malformed rowError
+		if errors.As(err, &malformed) {
+			rowErrors = append(rowErrors, importRowErrorResponse{Row: row, Message: malformed.Error()})
+			continue
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("read row %d: %v", row, err))
+			return
+		}
+
+		if err := stream.Send(&filmv1.ImportFilmsRequest{DryRun: dryRun, Film: rec}); err != nil {
+			// The service ended the stream; CloseAndRecv returns its error.
+			break
+		}
+		sentRows = append(sentRows, row)
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	for _, e := range resp.GetErrors() {
+		inputRow := e.GetRow()
+		if i := int(inputRow) - 1; i >= 0 && i < len(sentRows) {
+			inputRow = sentRows[i]
+		}
+		rowErrors = append(rowErrors, importRowErrorResponse{
+			Row:     inputRow,
+			Title:   e.GetTitle(),
+			Message: e.GetMessage(),
+		})
+	}
+	slices.SortFunc(rowErrors, func(a, b importRowErrorResponse) int { return int(a.Row - b.Row) })
+
+	writeJSON(w, http.StatusOK, importFilmsResponse{
+		DryRun:        dryRun,
+		Rows:          row,
+		Imported:      resp.GetImported(),
+		Failed:        int32(len(rowErrors)),
+		ActorsCreated: resp.GetActorsCreated(),
+		Errors:        rowErrors,
+	})
+}
+
+// ExportFilms streams the whole film catalog as CSV or JSON Lines, in the
+// format ImportFilms reads.
+func (h *FilmHandler) ExportFilms(w http.ResponseWriter, r *http.Request) {
+	format, err := catalogFormat(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Now().Add(catalogTransferTimeout))
+
+	ctx, cancel := context.WithTimeout(r.Context(), catalogTransferTimeout)
+	defer cancel()
+
+	stream, err := h.filmClient.ExportFilms(ctx, &filmv1.ExportFilmsRequest{})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	// Read the first film before writing anything, so an error from the
+	// film service can still be reported with an error status.
+	first, err := stream.Recv()
+	if err != nil && !errors.Is(err, io.EOF) {
+		handleGRPCError(w, err)
+		return
+	}
+
+	var writer filmRecordWriter
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="films.csv"`)
+		if writer, err = newCSVFilmWriter(w); err != nil {
+			return
+		}
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", `attachment; filename="films.jsonl"`)
+		writer = newJSONLFilmWriter(w)
+	}
+
+	// Once the response has started a failure can only cut it short.
+	// Aborting the handler drops the connection instead of ending the
+	// response cleanly, so the client cannot mistake a partial export for
+	// the whole catalog.
+	for rec := first; rec != nil; rec, err = stream.Recv() {
+		if err := writer.Write(rec); err != nil {
+			log.Printf("export films: write: %v", err)
+			panic(http.ErrAbortHandler)
+		}
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		log.Printf("export films: %v", err)
+		panic(http.ErrAbortHandler)
+	}
+	if err := writer.Flush(); err != nil {
+		log.Printf("export films: write: %v", err)
+		panic(http.ErrAbortHandler)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -109,6 +110,11 @@ func parseQueryBool(r *http.Request, name string) bool {
 	return s == "true" || s == "1"
 }
 
+// parseQueryDate parses a required YYYY-MM-DD query parameter as midnight UTC.
+func parseQueryDate(r *http.Request, name string) (time.Time, error) {
+	return time.Parse(time.DateOnly, r.URL.Query().Get(name))
+}
+
 // decodeJSON decodes JSON request body into the given target.
 func decodeJSON(r *http.Request, target any) error {
 	return json.NewDecoder(r.Body).Decode(target)
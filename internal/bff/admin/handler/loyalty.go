@@ -0,0 +1,237 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	paymentv1 "github.com/enkaigaku/dvd-rental/gen/proto/payment/v1"
+)
+
+// LoyaltyHandler handles loyalty program and rewards report endpoints.
+type LoyaltyHandler struct {
+	loyaltyClient paymentv1.LoyaltyServiceClient
+}
+
+// NewLoyaltyHandler creates a new LoyaltyHandler.
+func NewLoyaltyHandler(loyaltyClient paymentv1.LoyaltyServiceClient) *LoyaltyHandler {
+	return &LoyaltyHandler{loyaltyClient: loyaltyClient}
+}
+
+// --- JSON models ---
+
+type loyaltyAccountResponse struct {
+	CustomerID       int32  `json:"customer_id"`
+	Tier             string `json:"tier"`
+	PointsBalance    int32  `json:"points_balance"`
+	LifetimePoints   int32  `json:"lifetime_points"`
+	PointsMultiplier string `json:"points_multiplier"`
+	FreeRentalPoints int32  `json:"free_rental_points"`
+	TierCalculatedAt string `json:"tier_calculated_at,omitempty"`
+}
+
+type loyaltyTransactionResponse struct {
+	TransactionID int32  `json:"transaction_id"`
+	Kind          string `json:"kind"`
+	Points        int32  `json:"points"`
+	BalanceAfter  int32  `json:"balance_after"`
+	PaymentID     int32  `json:"payment_id,omitempty"`
+	RentalID      int32  `json:"rental_id,omitempty"`
+	Note          string `json:"note,omitempty"`
+	CreatedAt     string `json:"created_at"`
+}
+
+type loyaltyTransactionListResponse struct {
+	Transactions []loyaltyTransactionResponse `json:"transactions"`
+	TotalCount   int32                        `json:"total_count"`
+}
+
+type loyaltyTierResponse struct {
+	Tier                string `json:"tier"`
+	Rank                int32  `json:"rank"`
+	MinMonthlyPurchases int32  `json:"min_monthly_purchases"`
+	MinDollarAmount     string `json:"min_dollar_amount"`
+	PointsMultiplier    string `json:"points_multiplier"`
+}
+
+type loyaltyTierListResponse struct {
+	Tiers []loyaltyTierResponse `json:"tiers"`
+}
+
+type loyaltyTierCountResponse struct {
+	Tier      string `json:"tier"`
+	Customers int64  `json:"customers"`
+}
+
+type recalculateTiersResponse struct {
+	Tiers []loyaltyTierCountResponse `json:"tiers"`
+}
+
+type rewardsCustomerResponse struct {
+	CustomerID int32  `json:"customer_id"`
+	StoreID    int32  `json:"store_id"`
+	FirstName  string `json:"first_name"`
+	LastName   string `json:"last_name"`
+	Email      string `json:"email"`
+}
+
+type rewardsReportResponse struct {
+	Customers []rewardsCustomerResponse `json:"customers"`
+}
+
+func loyaltyAccountToResponse(a *paymentv1.LoyaltyAccount) loyaltyAccountResponse {
+	resp := loyaltyAccountResponse{
+		CustomerID:       a.GetCustomerId(),
+		Tier:             a.GetTier(),
+		PointsBalance:    a.GetPointsBalance(),
+		LifetimePoints:   a.GetLifetimePoints(),
+		PointsMultiplier: a.GetPointsMultiplier(),
+		FreeRentalPoints: a.GetFreeRentalPoints(),
+	}
+	if a.GetTierCalculatedAt() != nil {
+		resp.TierCalculatedAt = a.GetTierCalculatedAt().AsTime().Format(time.RFC3339)
+	}
+	return resp
+}
+
+func loyaltyTransactionToResponse(t *paymentv1.LoyaltyTransaction) loyaltyTransactionResponse {
+	return loyaltyTransactionResponse{
+		TransactionID: t.GetTransactionId(),
+		Kind:          t.GetKind(),
+		Points:        t.GetPoints(),
+		BalanceAfter:  t.GetBalanceAfter(),
+		PaymentID:     t.GetPaymentId(),
+		RentalID:      t.GetRentalId(),
+		Note:          t.GetNote(),
+		CreatedAt:     t.GetCreatedAt().AsTime().Format(time.RFC3339),
+	}
+}
+
+// GetCustomerLoyalty returns a customer's points and tier.
+func (h *LoyaltyHandler) GetCustomerLoyalty(w http.ResponseWriter, r *http.Request) {
+	customerID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid customer id")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	account, err := h.loyaltyClient.GetLoyaltyAccount(ctx, &paymentv1.GetLoyaltyAccountRequest{
+		CustomerId: customerID,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, loyaltyAccountToResponse(account))
+}
+
+// ListCustomerLoyaltyTransactions returns a customer's points history.
+func (h *LoyaltyHandler) ListCustomerLoyaltyTransactions(w http.ResponseWriter, r *http.Request) {
+	customerID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid customer id")
+		return
+	}
+	pageSize, page := parsePagination(r)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.loyaltyClient.ListLoyaltyTransactions(ctx, &paymentv1.ListLoyaltyTransactionsRequest{
+		CustomerId: customerID,
+		PageSize:   pageSize,
+		Page:       page,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	transactions := make([]loyaltyTransactionResponse, len(resp.GetTransactions()))
+	for i, t := range resp.GetTransactions() {
+		transactions[i] = loyaltyTransactionToResponse(t)
+	}
+
+	writeJSON(w, http.StatusOK, loyaltyTransactionListResponse{
+		Transactions: transactions,
+		TotalCount:   resp.GetTotalCount(),
+	})
+}
+
+// ListTiers returns the loyalty tiers and their thresholds.
+func (h *LoyaltyHandler) ListTiers(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.loyaltyClient.ListLoyaltyTiers(ctx, &paymentv1.ListLoyaltyTiersRequest{})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	tiers := make([]loyaltyTierResponse, len(resp.GetTiers()))
+	for i, t := range resp.GetTiers() {
+		tiers[i] = loyaltyTierResponse{
+			Tier:                t.GetTier(),
+			Rank:                t.GetRank(),
+			MinMonthlyPurchases: t.GetMinMonthlyPurchases(),
+			MinDollarAmount:     t.GetMinDollarAmount(),
+			PointsMultiplier:    t.GetPointsMultiplier(),
+		}
+	}
+
+	writeJSON(w, http.StatusOK, loyaltyTierListResponse{Tiers: tiers})
+}
+
+// RecalculateTiers reassigns every customer's tier from rewards_report and
+// returns the number of customers in each tier.
+func (h *LoyaltyHandler) RecalculateTiers(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.loyaltyClient.RecalculateLoyaltyTiers(ctx, &paymentv1.RecalculateLoyaltyTiersRequest{})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	tiers := make([]loyaltyTierCountResponse, len(resp.GetTiers()))
+	for i, t := range resp.GetTiers() {
+		tiers[i] = loyaltyTierCountResponse{Tier: t.GetTier(), Customers: t.GetCustomers()}
+	}
+
+	writeJSON(w, http.StatusOK, recalculateTiersResponse{Tiers: tiers})
+}
+
+// RunRewardsReport runs Pagila's rewards_report with the
+// min_monthly_purchases and min_dollar_amount query parameters.
+func (h *LoyaltyHandler) RunRewardsReport(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.loyaltyClient.RunRewardsReport(ctx, &paymentv1.RunRewardsReportRequest{
+		MinMonthlyPurchases: parseQueryInt32(r, "min_monthly_purchases"),
+		MinDollarAmount:     r.URL.Query().Get("min_dollar_amount"),
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	customers := make([]rewardsCustomerResponse, len(resp.GetCustomers()))
+	for i, c := range resp.GetCustomers() {
+		customers[i] = rewardsCustomerResponse{
+			CustomerID: c.GetCustomerId(),
+			StoreID:    c.GetStoreId(),
+			FirstName:  c.GetFirstName(),
+			LastName:   c.GetLastName(),
+			Email:      c.GetEmail(),
+		}
+	}
+
+	writeJSON(w, http.StatusOK, rewardsReportResponse{Customers: customers})
+}
@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"time"
 
+	"google.golang.org/protobuf/types/known/timestamppb"
+
 	paymentv1 "github.com/enkaigaku/dvd-rental/gen/proto/payment/v1"
 )
 
@@ -25,8 +27,11 @@ type paymentResponse struct {
 	CustomerID  int32  `json:"customer_id"`
 	StaffID     int32  `json:"staff_id"`
 	RentalID    int32  `json:"rental_id"`
-	Amount      string `json:"amount"`
+	Amount      string `json:"amount"` // net_amount + tax_amount
 	PaymentDate string `json:"payment_date"`
+	ChargeType  string `json:"charge_type"`
+	NetAmount   string `json:"net_amount"`
+	TaxAmount   string `json:"tax_amount"`
 }
 
 type paymentDetailResponse struct {
@@ -36,16 +41,53 @@ type paymentDetailResponse struct {
 	RentalDate   string `json:"rental_date,omitempty"`
 }
 
+type taxLineResponse struct {
+	TaxRateID int32  `json:"tax_rate_id"`
+	Name      string `json:"name"`
+	Rate      string `json:"rate"`
+	Amount    string `json:"amount"`
+}
+
+type receiptResponse struct {
+	paymentDetailResponse
+	StoreID      int32             `json:"store_id"`
+	StoreAddress string            `json:"store_address"`
+	StoreCity    string            `json:"store_city"`
+	StoreCountry string            `json:"store_country"`
+	FilmTitle    string            `json:"film_title"`
+	Taxes        []taxLineResponse `json:"taxes"`
+}
+
+type revenueRowResponse struct {
+	StoreID      int32  `json:"store_id"`
+	ChargeType   string `json:"charge_type"`
+	PaymentCount int64  `json:"payment_count"`
+	NetAmount    string `json:"net_amount"`
+	TaxAmount    string `json:"tax_amount"`
+	GrossAmount  string `json:"gross_amount"`
+}
+
+type revenueReportResponse struct {
+	StartDate string               `json:"start_date"`
+	EndDate   string               `json:"end_date"`
+	Rows      []revenueRowResponse `json:"rows"`
+}
+
 type paymentListResponse struct {
 	Payments   []paymentResponse `json:"payments"`
 	TotalCount int32             `json:"total_count"`
 }
 
 type createPaymentRequest struct {
-	CustomerID int32  `json:"customer_id"`
-	StaffID    int32  `json:"staff_id"`
-	RentalID   int32  `json:"rental_id"`
-	Amount     string `json:"amount"`
+	CustomerID    int32  `json:"customer_id"`
+	StaffID       int32  `json:"staff_id"`
+	RentalID      int32  `json:"rental_id"`
+	Amount        string `json:"amount"`         // net of tax; rentals are priced by the pricing engine
+	PromoCode     string `json:"promo_code"`     // only when amount is empty
+	GiftCardCode  string `json:"gift_card_code"` // pay from a gift card or store credit
+	RedeemPoints  bool   `json:"redeem_points"`  // free rental paid with loyalty points
+	ChargeType    string `json:"charge_type"`    // "rental" (default), "late_fee" or "replacement"
+	PriceOverride bool   `json:"price_override"` // charge amount for a rental instead of the quote
 }
 
 func paymentToResponse(p *paymentv1.Payment) paymentResponse {
@@ -56,6 +98,9 @@ func paymentToResponse(p *paymentv1.Payment) paymentResponse {
 		RentalID:    p.GetRentalId(),
 		Amount:      p.GetAmount(),
 		PaymentDate: p.GetPaymentDate().AsTime().Format(time.RFC3339),
+		ChargeType:  p.GetChargeType(),
+		NetAmount:   p.GetNetAmount(),
+		TaxAmount:   p.GetTaxAmount(),
 	}
 }
 
@@ -71,6 +116,27 @@ func paymentDetailToResponse(d *paymentv1.PaymentDetail) paymentDetailResponse {
 	return resp
 }
 
+func receiptToResponse(rc *paymentv1.Receipt) receiptResponse {
+	taxes := make([]taxLineResponse, len(rc.GetTaxes()))
+	for i, t := range rc.GetTaxes() {
+		taxes[i] = taxLineResponse{
+			TaxRateID: t.GetTaxRateId(),
+			Name:      t.GetName(),
+			Rate:      t.GetRate(),
+			Amount:    t.GetAmount(),
+		}
+	}
+	return receiptResponse{
+		paymentDetailResponse: paymentDetailToResponse(rc.GetDetail()),
+		StoreID:               rc.GetStoreId(),
+		StoreAddress:          rc.GetStoreAddress(),
+		StoreCity:             rc.GetStoreCity(),
+		StoreCountry:          rc.GetStoreCountry(),
+		FilmTitle:             rc.GetFilmTitle(),
+		Taxes:                 taxes,
+	}
+}
+
 // ListPayments returns a paginated list of payments.
 func (h *PaymentHandler) ListPayments(w http.ResponseWriter, r *http.Request) {
 	pageSize, page := parsePagination(r)
@@ -147,7 +213,75 @@ func (h *PaymentHandler) GetPayment(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, paymentDetailToResponse(detail))
 }
 
-// CreatePayment creates a new payment.
+// GetPaymentReceipt returns a payment with its store, film and tax breakdown.
+func (h *PaymentHandler) GetPaymentReceipt(w http.ResponseWriter, r *http.Request) {
+	paymentID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid payment id")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	receipt, err := h.paymentClient.GetPaymentReceipt(ctx, &paymentv1.GetPaymentReceiptRequest{
+		PaymentId: paymentID,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, receiptToResponse(receipt))
+}
+
+// GetRevenueReport returns net, tax and gross revenue per store and charge
+// type for payments from start_date up to (not including) end_date.
+func (h *PaymentHandler) GetRevenueReport(w http.ResponseWriter, r *http.Request) {
+	startDate, err := parseQueryDate(r, "start_date")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "start_date must be a YYYY-MM-DD date")
+		return
+	}
+	endDate, err := parseQueryDate(r, "end_date")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "end_date must be a YYYY-MM-DD date")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.paymentClient.GetRevenueReport(ctx, &paymentv1.GetRevenueReportRequest{
+		StartDate: timestamppb.New(startDate),
+		EndDate:   timestamppb.New(endDate),
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	rows := make([]revenueRowResponse, len(resp.GetRows()))
+	for i, row := range resp.GetRows() {
+		rows[i] = revenueRowResponse{
+			StoreID:      row.GetStoreId(),
+			ChargeType:   row.GetChargeType(),
+			PaymentCount: row.GetPaymentCount(),
+			NetAmount:    row.GetNetAmount(),
+			TaxAmount:    row.GetTaxAmount(),
+			GrossAmount:  row.GetGrossAmount(),
+		}
+	}
+
+	writeJSON(w, http.StatusOK, revenueReportResponse{
+		StartDate: startDate.Format(time.DateOnly),
+		EndDate:   endDate.Format(time.DateOnly),
+		Rows:      rows,
+	})
+}
+
+// CreatePayment creates a new payment. Without an amount the rental charge
+// is computed by the pricing engine. Sales tax is added to the amount.
 func (h *PaymentHandler) CreatePayment(w http.ResponseWriter, r *http.Request) {
 	var req createPaymentRequest
 	if err := decodeJSON(r, &req); err != nil {
@@ -159,10 +293,15 @@ func (h *PaymentHandler) CreatePayment(w http.ResponseWriter, r *http.Request) {
 	defer cancel()
 
 	payment, err := h.paymentClient.CreatePayment(ctx, &paymentv1.CreatePaymentRequest{
-		CustomerId: req.CustomerID,
-		StaffId:    req.StaffID,
-		RentalId:   req.RentalID,
-		Amount:     req.Amount,
+		CustomerId:    req.CustomerID,
+		StaffId:       req.StaffID,
+		RentalId:      req.RentalID,
+		Amount:        req.Amount,
+		PromoCode:     req.PromoCode,
+		GiftCardCode:  req.GiftCardCode,
+		RedeemPoints:  req.RedeemPoints,
+		ChargeType:    req.ChargeType,
+		PriceOverride: req.PriceOverride,
 	})
 	if err != nil {
 		handleGRPCError(w, err)
@@ -0,0 +1,310 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	paymentv1 "github.com/enkaigaku/dvd-rental/gen/proto/payment/v1"
+)
+
+// PromotionHandler handles promotion management and price quote endpoints.
+type PromotionHandler struct {
+	promotionClient paymentv1.PromotionServiceClient
+	pricingClient   paymentv1.PricingServiceClient
+}
+
+// NewPromotionHandler creates a new PromotionHandler.
+func NewPromotionHandler(
+	promotionClient paymentv1.PromotionServiceClient,
+	pricingClient paymentv1.PricingServiceClient,
+) *PromotionHandler {
+	return &PromotionHandler{
+		promotionClient: promotionClient,
+		pricingClient:   pricingClient,
+	}
+}
+
+// --- JSON models ---
+
+type promotionResponse struct {
+	PromotionID        int32  `json:"promotion_id"`
+	Name               string `json:"name"`
+	Code               string `json:"code,omitempty"`
+	Kind               string `json:"kind"`
+	Value              string `json:"value"`
+	BuyQuantity        int32  `json:"buy_quantity,omitempty"`
+	PayQuantity        int32  `json:"pay_quantity,omitempty"`
+	CategoryID         int32  `json:"category_id,omitempty"`
+	StoreID            int32  `json:"store_id,omitempty"`
+	StartsAt           string `json:"starts_at,omitempty"`
+	EndsAt             string `json:"ends_at,omitempty"`
+	MaxUsesPerCustomer int32  `json:"max_uses_per_customer"`
+	Active             bool   `json:"active"`
+	LastUpdate         string `json:"last_update"`
+}
+
+type promotionListResponse struct {
+	Promotions []promotionResponse `json:"promotions"`
+	TotalCount int32               `json:"total_count"`
+}
+
+type promotionRequest struct {
+	Name               string     `json:"name"`
+	Code               string     `json:"code"`
+	Kind               string     `json:"kind"`
+	Value              string     `json:"value"`
+	BuyQuantity        int32      `json:"buy_quantity"`
+	PayQuantity        int32      `json:"pay_quantity"`
+	CategoryID         int32      `json:"category_id"`
+	StoreID            int32      `json:"store_id"`
+	StartsAt           *time.Time `json:"starts_at"`
+	EndsAt             *time.Time `json:"ends_at"`
+	MaxUsesPerCustomer int32      `json:"max_uses_per_customer"`
+	Active             *bool      `json:"active"`
+}
+
+type appliedRuleResponse struct {
+	PromotionID int32  `json:"promotion_id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Discount    string `json:"discount"`
+}
+
+type priceQuoteResponse struct {
+	FilmID         int32                 `json:"film_id"`
+	StoreID        int32                 `json:"store_id"`
+	CustomerID     int32                 `json:"customer_id"`
+	BaseAmount     string                `json:"base_amount"`
+	Discount       string                `json:"discount"`
+	Amount         string                `json:"amount"`
+	AppliedRules   []appliedRuleResponse `json:"applied_rules"`
+	SubscriptionID int32                 `json:"subscription_id,omitempty"`
+	TaxAmount      string                `json:"tax_amount"`
+	TotalAmount    string                `json:"total_amount"`
+}
+
+func promotionToResponse(p *paymentv1.Promotion) promotionResponse {
+	resp := promotionResponse{
+		PromotionID:        p.GetPromotionId(),
+		Name:               p.GetName(),
+		Code:               p.GetCode(),
+		Kind:               p.GetKind(),
+		Value:              p.GetValue(),
+		BuyQuantity:        p.GetBuyQuantity(),
+		PayQuantity:        p.GetPayQuantity(),
+		CategoryID:         p.GetCategoryId(),
+		StoreID:            p.GetStoreId(),
+		MaxUsesPerCustomer: p.GetMaxUsesPerCustomer(),
+		Active:             p.GetActive(),
+		LastUpdate:         p.GetLastUpdate().AsTime().Format(time.RFC3339),
+	}
+	if p.GetStartsAt() != nil {
+		resp.StartsAt = p.GetStartsAt().AsTime().Format(time.RFC3339)
+	}
+	if p.GetEndsAt() != nil {
+		resp.EndsAt = p.GetEndsAt().AsTime().Format(time.RFC3339)
+	}
+	return resp
+}
+
+func priceQuoteToResponse(q *paymentv1.PriceQuote) priceQuoteResponse {
+	rules := make([]appliedRuleResponse, len(q.GetAppliedRules()))
+	for i, r := range q.GetAppliedRules() {
+		rules[i] = appliedRuleResponse{
+			PromotionID: r.GetPromotionId(),
+			Name:        r.GetName(),
+			Description: r.GetDescription(),
+			Discount:    r.GetDiscount(),
+		}
+	}
+	return priceQuoteResponse{
+		FilmID:         q.GetFilmId(),
+		StoreID:        q.GetStoreId(),
+		CustomerID:     q.GetCustomerId(),
+		BaseAmount:     q.GetBaseAmount(),
+		Discount:       q.GetDiscount(),
+		Amount:         q.GetAmount(),
+		AppliedRules:   rules,
+		SubscriptionID: q.GetSubscriptionId(),
+		TaxAmount:      q.GetTaxAmount(),
+		TotalAmount:    q.GetTotalAmount(),
+	}
+}
+
+// optionalTimestamp converts an optional JSON time to a proto Timestamp.
+func optionalTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+// ListPromotions returns a paginated list of promotions.
+func (h *PromotionHandler) ListPromotions(w http.ResponseWriter, r *http.Request) {
+	pageSize, page := parsePagination(r)
+	activeOnly := parseQueryBool(r, "active_only")
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.promotionClient.ListPromotions(ctx, &paymentv1.ListPromotionsRequest{
+		PageSize:   pageSize,
+		Page:       page,
+		ActiveOnly: activeOnly,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	promotions := make([]promotionResponse, len(resp.GetPromotions()))
+	for i, p := range resp.GetPromotions() {
+		promotions[i] = promotionToResponse(p)
+	}
+
+	writeJSON(w, http.StatusOK, promotionListResponse{
+		Promotions: promotions,
+		TotalCount: resp.GetTotalCount(),
+	})
+}
+
+// GetPromotion returns a single promotion.
+func (h *PromotionHandler) GetPromotion(w http.ResponseWriter, r *http.Request) {
+	promotionID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid promotion id")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	promotion, err := h.promotionClient.GetPromotion(ctx, &paymentv1.GetPromotionRequest{
+		PromotionId: promotionID,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, promotionToResponse(promotion))
+}
+
+// CreatePromotion creates a new promotion. Promotions are active unless
+// "active": false is given.
+func (h *PromotionHandler) CreatePromotion(w http.ResponseWriter, r *http.Request) {
+	var req promotionRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	promotion, err := h.promotionClient.CreatePromotion(ctx, &paymentv1.CreatePromotionRequest{
+		Name:               req.Name,
+		Code:               req.Code,
+		Kind:               req.Kind,
+		Value:              req.Value,
+		BuyQuantity:        req.BuyQuantity,
+		PayQuantity:        req.PayQuantity,
+		CategoryId:         req.CategoryID,
+		StoreId:            req.StoreID,
+		StartsAt:           optionalTimestamp(req.StartsAt),
+		EndsAt:             optionalTimestamp(req.EndsAt),
+		MaxUsesPerCustomer: req.MaxUsesPerCustomer,
+		Active:             req.Active == nil || *req.Active,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, promotionToResponse(promotion))
+}
+
+// UpdatePromotion replaces an existing promotion.
+func (h *PromotionHandler) UpdatePromotion(w http.ResponseWriter, r *http.Request) {
+	promotionID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid promotion id")
+		return
+	}
+
+	var req promotionRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	promotion, err := h.promotionClient.UpdatePromotion(ctx, &paymentv1.UpdatePromotionRequest{
+		PromotionId:        promotionID,
+		Name:               req.Name,
+		Code:               req.Code,
+		Kind:               req.Kind,
+		Value:              req.Value,
+		BuyQuantity:        req.BuyQuantity,
+		PayQuantity:        req.PayQuantity,
+		CategoryId:         req.CategoryID,
+		StoreId:            req.StoreID,
+		StartsAt:           optionalTimestamp(req.StartsAt),
+		EndsAt:             optionalTimestamp(req.EndsAt),
+		MaxUsesPerCustomer: req.MaxUsesPerCustomer,
+		Active:             req.Active == nil || *req.Active,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, promotionToResponse(promotion))
+}
+
+// DeletePromotion deletes a promotion by ID.
+func (h *PromotionHandler) DeletePromotion(w http.ResponseWriter, r *http.Request) {
+	promotionID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid promotion id")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	_, err = h.promotionClient.DeletePromotion(ctx, &paymentv1.DeletePromotionRequest{
+		PromotionId: promotionID,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// QuoteRentalPrice prices a rental of film_id at store_id for customer_id,
+// optionally applying promo_code, and explains which rules applied.
+func (h *PromotionHandler) QuoteRentalPrice(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	quote, err := h.pricingClient.QuoteRentalPrice(ctx, &paymentv1.QuoteRentalPriceRequest{
+		FilmId:     parseQueryInt32(r, "film_id"),
+		StoreId:    parseQueryInt32(r, "store_id"),
+		CustomerId: parseQueryInt32(r, "customer_id"),
+		PromoCode:  r.URL.Query().Get("promo_code"),
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, priceQuoteToResponse(quote))
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	filmv1 "github.com/enkaigaku/dvd-rental/gen/proto/film/v1"
+)
+
+// RecommendationHandler handles film similarity endpoints.
+type RecommendationHandler struct {
+	recommendationClient filmv1.RecommendationServiceClient
+}
+
+// NewRecommendationHandler creates a new RecommendationHandler.
+func NewRecommendationHandler(recommendationClient filmv1.RecommendationServiceClient) *RecommendationHandler {
+	return &RecommendationHandler{recommendationClient: recommendationClient}
+}
+
+// --- JSON models ---
+
+type similarFilmResponse struct {
+	filmResponse
+	Score float64 `json:"score"`
+}
+
+type similarFilmListResponse struct {
+	Films []similarFilmResponse `json:"films"`
+}
+
+type refreshSimilaritiesResponse struct {
+	Pairs int64 `json:"pairs"`
+}
+
+// ListSimilarFilms returns the films customers who rented a film also rented.
+func (h *RecommendationHandler) ListSimilarFilms(w http.ResponseWriter, r *http.Request) {
+	filmID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid film id")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.recommendationClient.ListSimilarFilms(ctx, &filmv1.ListSimilarFilmsRequest{
+		FilmId: filmID,
+		Limit:  parseQueryInt32(r, "limit"),
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	films := make([]similarFilmResponse, len(resp.GetRecommendations()))
+	for i, rec := range resp.GetRecommendations() {
+		films[i] = similarFilmResponse{
+			filmResponse: filmToResponse(rec.GetFilm()),
+			Score:        rec.GetScore(),
+		}
+	}
+	writeJSON(w, http.StatusOK, similarFilmListResponse{Films: films})
+}
+
+// RefreshSimilarities recomputes film similarities now instead of waiting
+// for the scheduled refresh.
+func (h *RecommendationHandler) RefreshSimilarities(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	resp, err := h.recommendationClient.RefreshSimilarities(ctx, &emptypb.Empty{})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, refreshSimilaritiesResponse{Pairs: resp.GetPairs()})
+}
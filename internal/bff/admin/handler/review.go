@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	filmv1 "github.com/enkaigaku/dvd-rental/gen/proto/film/v1"
+)
+
+// ReviewHandler handles film review moderation endpoints.
+type ReviewHandler struct {
+	reviewClient filmv1.ReviewServiceClient
+}
+
+// NewReviewHandler creates a new ReviewHandler.
+func NewReviewHandler(reviewClient filmv1.ReviewServiceClient) *ReviewHandler {
+	return &ReviewHandler{reviewClient: reviewClient}
+}
+
+// --- JSON models ---
+
+type reviewResponse struct {
+	ReviewID       int32  `json:"review_id"`
+	FilmID         int32  `json:"film_id"`
+	FilmTitle      string `json:"film_title"`
+	CustomerID     int32  `json:"customer_id"`
+	CustomerName   string `json:"customer_name"`
+	Rating         int32  `json:"rating"`
+	Body           string `json:"body"`
+	Status         string `json:"status"`
+	ModerationNote string `json:"moderation_note,omitempty"`
+	CreatedAt      string `json:"created_at"`
+	LastUpdate     string `json:"last_update"`
+}
+
+type reviewListResponse struct {
+	Reviews    []reviewResponse `json:"reviews"`
+	TotalCount int32            `json:"total_count"`
+}
+
+type moderateReviewRequest struct {
+	Note string `json:"note"`
+}
+
+func reviewToResponse(r *filmv1.Review) reviewResponse {
+	return reviewResponse{
+		ReviewID:       r.GetReviewId(),
+		FilmID:         r.GetFilmId(),
+		FilmTitle:      r.GetFilmTitle(),
+		CustomerID:     r.GetCustomerId(),
+		CustomerName:   r.GetCustomerName(),
+		Rating:         r.GetRating(),
+		Body:           r.GetBody(),
+		Status:         r.GetStatus(),
+		ModerationNote: r.GetModerationNote(),
+		CreatedAt:      r.GetCreatedAt().AsTime().Format(time.RFC3339),
+		LastUpdate:     r.GetLastUpdate().AsTime().Format(time.RFC3339),
+	}
+}
+
+// ListReviews returns the moderation queue, oldest first. ?status= lists
+// approved or rejected reviews instead of pending ones.
+func (h *ReviewHandler) ListReviews(w http.ResponseWriter, r *http.Request) {
+	pageSize, page := parsePagination(r)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.reviewClient.ListReviewsByStatus(ctx, &filmv1.ListReviewsByStatusRequest{
+		Status:   r.URL.Query().Get("status"),
+		PageSize: pageSize,
+		Page:     page,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, reviewsToListResponse(resp))
+}
+
+// ListFilmReviews returns the approved reviews of a film.
+func (h *ReviewHandler) ListFilmReviews(w http.ResponseWriter, r *http.Request) {
+	filmID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid film id")
+		return
+	}
+	pageSize, page := parsePagination(r)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.reviewClient.ListFilmReviews(ctx, &filmv1.ListFilmReviewsRequest{
+		FilmId:   filmID,
+		PageSize: pageSize,
+		Page:     page,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, reviewsToListResponse(resp))
+}
+
+// GetReview returns a single review.
+func (h *ReviewHandler) GetReview(w http.ResponseWriter, r *http.Request) {
+	reviewID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid review id")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	review, err := h.reviewClient.GetReview(ctx, &filmv1.GetReviewRequest{
+		ReviewId: reviewID,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, reviewToResponse(review))
+}
+
+// ApproveReview publishes a review and counts it in the film's rating.
+func (h *ReviewHandler) ApproveReview(w http.ResponseWriter, r *http.Request) {
+	h.moderateReview(w, r, "approved")
+}
+
+// RejectReview hides a review. The optional note tells the customer why.
+func (h *ReviewHandler) RejectReview(w http.ResponseWriter, r *http.Request) {
+	h.moderateReview(w, r, "rejected")
+}
+
+func (h *ReviewHandler) moderateReview(w http.ResponseWriter, r *http.Request, status string) {
+	reviewID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid review id")
+		return
+	}
+
+	// The body is optional.
+	var req moderateReviewRequest
+	if err := decodeJSON(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	review, err := h.reviewClient.ModerateReview(ctx, &filmv1.ModerateReviewRequest{
+		ReviewId: reviewID,
+		Status:   status,
+		Note:     req.Note,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, reviewToResponse(review))
+}
+
+// DeleteReview deletes a review.
+func (h *ReviewHandler) DeleteReview(w http.ResponseWriter, r *http.Request) {
+	reviewID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid review id")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	_, err = h.reviewClient.DeleteReview(ctx, &filmv1.DeleteReviewRequest{
+		ReviewId: reviewID,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func reviewsToListResponse(resp *filmv1.ListReviewsResponse) reviewListResponse {
+	reviews := make([]reviewResponse, len(resp.GetReviews()))
+	for i, rv := range resp.GetReviews() {
+		reviews[i] = reviewToResponse(rv)
+	}
+	return reviewListResponse{
+		Reviews:    reviews,
+		TotalCount: resp.GetTotalCount(),
+	}
+}
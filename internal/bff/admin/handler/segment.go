@@ -0,0 +1,496 @@
+package handler
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	customerv1 "github.com/enkaigaku/dvd-rental/gen/proto/customer/v1"
+)
+
+const (
+	// segmentEvaluateTimeout bounds calls that run a segment rule against
+	// every customer: preview, create, update and evaluate. It stays below
+	// the server's write timeout.
+	segmentEvaluateTimeout = 12 * time.Second
+	// segmentExportTimeout bounds a whole member CSV export.
+	segmentExportTimeout = 5 * time.Minute
+	// segmentExportPageSize is how many members an export fetches at a
+	// time; it is the most ListCustomerSummaries is sent per call.
+	segmentExportPageSize = 100
+)
+
+// segmentCSVColumns are the columns of the segment member export.
+var segmentCSVColumns = []string{
+	"customer_id", "first_name", "last_name", "email", "store_id", "active",
+	"total_rentals", "last_rental_date", "lifetime_spend", "favourite_categories",
+}
+
+// SegmentHandler handles customer segment endpoints, used to target
+// marketing campaigns.
+type SegmentHandler struct {
+	segmentClient  customerv1.SegmentServiceClient
+	customerClient customerv1.CustomerServiceClient
+}
+
+// NewSegmentHandler creates a new SegmentHandler. customerClient is used
+// to add activity summaries to segment members.
+func NewSegmentHandler(segmentClient customerv1.SegmentServiceClient, customerClient customerv1.CustomerServiceClient) *SegmentHandler {
+	return &SegmentHandler{
+		segmentClient:  segmentClient,
+		customerClient: customerClient,
+	}
+}
+
+// --- JSON models ---
+
+// segmentRuleJSON is a node of a segment rule tree: a group
+// ({"op": "all", "rules": [...]}) or a condition
+// ({"field": "lifetime_spend", "op": "gte", "values": [100]}).
+type segmentRuleJSON struct {
+	Op       string            `json:"op"`
+	Rules    []segmentRuleJSON `json:"rules,omitempty"`
+	Field    string            `json:"field,omitempty"`
+	Category string            `json:"category,omitempty"`
+	Values   []float64         `json:"values,omitempty"`
+}
+
+type segmentResponse struct {
+	SegmentID   int32           `json:"segment_id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Rule        segmentRuleJSON `json:"rule"`
+	MemberCount int32           `json:"member_count"`
+	EvaluatedAt string          `json:"evaluated_at,omitempty"`
+	CreatedAt   string          `json:"created_at"`
+	LastUpdate  string          `json:"last_update"`
+}
+
+type segmentListResponse struct {
+	Segments   []segmentResponse `json:"segments"`
+	TotalCount int32             `json:"total_count"`
+}
+
+type segmentRequest struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Rule        *segmentRuleJSON `json:"rule"`
+}
+
+type previewSegmentRequest struct {
+	Rule *segmentRuleJSON `json:"rule"`
+}
+
+type previewSegmentResponse struct {
+	MemberCount int32                       `json:"member_count"`
+	Stores      []segmentStoreCountResponse `json:"stores"`
+}
+
+type segmentStoreCountResponse struct {
+	StoreID     int32 `json:"store_id"`
+	MemberCount int32 `json:"member_count"`
+}
+
+type segmentMemberResponse struct {
+	customerResponse
+	Summary *customerSummaryResponse `json:"summary,omitempty"`
+}
+
+type segmentMemberListResponse struct {
+	Customers  []segmentMemberResponse `json:"customers"`
+	TotalCount int32                   `json:"total_count"`
+}
+
+func segmentToResponse(s *customerv1.Segment) segmentResponse {
+	resp := segmentResponse{
+		SegmentID:   s.GetSegmentId(),
+		Name:        s.GetName(),
+		Description: s.GetDescription(),
+		Rule:        segmentRuleToJSON(s.GetRule()),
+		MemberCount: s.GetMemberCount(),
+		CreatedAt:   s.GetCreatedAt().AsTime().Format(time.RFC3339),
+		LastUpdate:  s.GetLastUpdate().AsTime().Format(time.RFC3339),
+	}
+	if s.GetEvaluatedAt() != nil {
+		resp.EvaluatedAt = s.GetEvaluatedAt().AsTime().Format(time.RFC3339)
+	}
+	return resp
+}
+
+func segmentRuleToJSON(r *customerv1.SegmentRule) segmentRuleJSON {
+	var rules []segmentRuleJSON
+	for _, child := range r.GetRules() {
+		rules = append(rules, segmentRuleToJSON(child))
+	}
+	return segmentRuleJSON{
+		Op:       r.GetOp(),
+		Rules:    rules,
+		Field:    r.GetField(),
+		Category: r.GetCategory(),
+		Values:   r.GetValues(),
+	}
+}
+
+func segmentRuleToProto(r *segmentRuleJSON) *customerv1.SegmentRule {
+	if r == nil {
+		return nil
+	}
+	rules := make([]*customerv1.SegmentRule, len(r.Rules))
+	for i := range r.Rules {
+		rules[i] = segmentRuleToProto(&r.Rules[i])
+	}
+	return &customerv1.SegmentRule{
+		Op:       r.Op,
+		Rules:    rules,
+		Field:    r.Field,
+		Category: r.Category,
+		Values:   r.Values,
+	}
+}
+
+// ListSegments returns a paginated list of segments ordered by name.
+func (h *SegmentHandler) ListSegments(w http.ResponseWriter, r *http.Request) {
+	pageSize, page := parsePagination(r)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.segmentClient.ListSegments(ctx, &customerv1.ListSegmentsRequest{
+		PageSize: pageSize,
+		Page:     page,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	segments := make([]segmentResponse, len(resp.GetSegments()))
+	for i, s := range resp.GetSegments() {
+		segments[i] = segmentToResponse(s)
+	}
+
+	writeJSON(w, http.StatusOK, segmentListResponse{
+		Segments:   segments,
+		TotalCount: resp.GetTotalCount(),
+	})
+}
+
+// GetSegment returns a single segment.
+func (h *SegmentHandler) GetSegment(w http.ResponseWriter, r *http.Request) {
+	segmentID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid segment id")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	segment, err := h.segmentClient.GetSegment(ctx, &customerv1.GetSegmentRequest{
+		SegmentId: segmentID,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, segmentToResponse(segment))
+}
+
+// CreateSegment creates a segment and evaluates its members.
+func (h *SegmentHandler) CreateSegment(w http.ResponseWriter, r *http.Request) {
+	var req segmentRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), segmentEvaluateTimeout)
+	defer cancel()
+
+	segment, err := h.segmentClient.CreateSegment(ctx, &customerv1.CreateSegmentRequest{
+		Name:        req.Name,
+		Description: req.Description,
+		Rule:        segmentRuleToProto(req.Rule),
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, segmentToResponse(segment))
+}
+
+// UpdateSegment replaces a segment's name, description and rule, and
+// re-evaluates its members.
+func (h *SegmentHandler) UpdateSegment(w http.ResponseWriter, r *http.Request) {
+	segmentID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid segment id")
+		return
+	}
+
+	var req segmentRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), segmentEvaluateTimeout)
+	defer cancel()
+
+	segment, err := h.segmentClient.UpdateSegment(ctx, &customerv1.UpdateSegmentRequest{
+		SegmentId:   segmentID,
+		Name:        req.Name,
+		Description: req.Description,
+		Rule:        segmentRuleToProto(req.Rule),
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, segmentToResponse(segment))
+}
+
+// DeleteSegment deletes a segment.
+func (h *SegmentHandler) DeleteSegment(w http.ResponseWriter, r *http.Request) {
+	segmentID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid segment id")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	if _, err := h.segmentClient.DeleteSegment(ctx, &customerv1.DeleteSegmentRequest{
+		SegmentId: segmentID,
+	}); err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PreviewSegment counts the customers a rule matches, in total and per
+// store, without saving a segment.
+func (h *SegmentHandler) PreviewSegment(w http.ResponseWriter, r *http.Request) {
+	var req previewSegmentRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), segmentEvaluateTimeout)
+	defer cancel()
+
+	resp, err := h.segmentClient.PreviewSegment(ctx, &customerv1.PreviewSegmentRequest{
+		Rule: segmentRuleToProto(req.Rule),
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	stores := make([]segmentStoreCountResponse, len(resp.GetStores()))
+	for i, s := range resp.GetStores() {
+		stores[i] = segmentStoreCountResponse{
+			StoreID:     s.GetStoreId(),
+			MemberCount: s.GetMemberCount(),
+		}
+	}
+
+	writeJSON(w, http.StatusOK, previewSegmentResponse{
+		MemberCount: resp.GetMemberCount(),
+		Stores:      stores,
+	})
+}
+
+// EvaluateSegment re-runs a segment's rule against current data and
+// replaces its members.
+func (h *SegmentHandler) EvaluateSegment(w http.ResponseWriter, r *http.Request) {
+	segmentID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid segment id")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), segmentEvaluateTimeout)
+	defer cancel()
+
+	segment, err := h.segmentClient.EvaluateSegment(ctx, &customerv1.EvaluateSegmentRequest{
+		SegmentId: segmentID,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, segmentToResponse(segment))
+}
+
+// ListSegmentMembers returns a page of a segment's members as of its last
+// evaluation, each with a summary of their rental activity and spend.
+// Members are still returned, without summaries, if those cannot be
+// computed.
+func (h *SegmentHandler) ListSegmentMembers(w http.ResponseWriter, r *http.Request) {
+	segmentID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid segment id")
+		return
+	}
+	pageSize, page := parsePagination(r)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.segmentClient.ListSegmentMembers(ctx, &customerv1.ListSegmentMembersRequest{
+		SegmentId: segmentID,
+		PageSize:  pageSize,
+		Page:      page,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	summaries, err := h.memberSummaries(ctx, resp.GetCustomers())
+	if err != nil {
+		log.Printf("get summaries of segment %d members: %v", segmentID, err)
+	}
+
+	customers := make([]segmentMemberResponse, len(resp.GetCustomers()))
+	for i, c := range resp.GetCustomers() {
+		customers[i] = segmentMemberResponse{customerResponse: customerToResponse(c)}
+		if s, ok := summaries[c.GetCustomerId()]; ok {
+			summary := customerSummaryToResponse(s)
+			customers[i].Summary = &summary
+		}
+	}
+
+	writeJSON(w, http.StatusOK, segmentMemberListResponse{
+		Customers:  customers,
+		TotalCount: resp.GetTotalCount(),
+	})
+}
+
+// ExportSegmentMembers downloads all of a segment's members as CSV, with
+// their rental activity and spend, for loading into a campaign tool.
+func (h *SegmentHandler) ExportSegmentMembers(w http.ResponseWriter, r *http.Request) {
+	segmentID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid segment id")
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Now().Add(segmentExportTimeout))
+
+	ctx, cancel := context.WithTimeout(r.Context(), segmentExportTimeout)
+	defer cancel()
+
+	// Fetch the first page before writing anything, so an unknown segment
+	// or a failing service can still be reported with an error status.
+	members, summaries, total, err := h.exportPage(ctx, segmentID, 1)
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="segment-%d-members.csv"`, segmentID))
+	cw := csv.NewWriter(w)
+	if err := cw.Write(segmentCSVColumns); err != nil {
+		return
+	}
+
+	// Once the response has started a failure can only cut it short.
+	for page := int32(1); ; {
+		for _, c := range members {
+			if err := cw.Write(segmentMemberCSVRecord(c, summaries[c.GetCustomerId()])); err != nil {
+				return
+			}
+		}
+		if int(page)*segmentExportPageSize >= int(total) || len(members) == 0 {
+			break
+		}
+		page++
+		if members, summaries, _, err = h.exportPage(ctx, segmentID, page); err != nil {
+			log.Printf("export segment %d members: %v", segmentID, err)
+			return
+		}
+	}
+	cw.Flush()
+}
+
+// exportPage fetches one page of a segment's members with their
+// summaries, and the segment's total member count.
+func (h *SegmentHandler) exportPage(ctx context.Context, segmentID, page int32) ([]*customerv1.Customer, map[int32]*customerv1.CustomerSummary, int32, error) {
+	resp, err := h.segmentClient.ListSegmentMembers(ctx, &customerv1.ListSegmentMembersRequest{
+		SegmentId: segmentID,
+		PageSize:  segmentExportPageSize,
+		Page:      page,
+	})
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	summaries, err := h.memberSummaries(ctx, resp.GetCustomers())
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return resp.GetCustomers(), summaries, resp.GetTotalCount(), nil
+}
+
+// memberSummaries returns the summaries of customers by customer ID.
+func (h *SegmentHandler) memberSummaries(ctx context.Context, customers []*customerv1.Customer) (map[int32]*customerv1.CustomerSummary, error) {
+	if len(customers) == 0 {
+		return nil, nil
+	}
+	ids := make([]int32, len(customers))
+	for i, c := range customers {
+		ids[i] = c.GetCustomerId()
+	}
+
+	resp, err := h.customerClient.ListCustomerSummaries(ctx, &customerv1.ListCustomerSummariesRequest{
+		CustomerIds: ids,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make(map[int32]*customerv1.CustomerSummary, len(resp.GetSummaries()))
+	for _, s := range resp.GetSummaries() {
+		summaries[s.GetCustomerId()] = s
+	}
+	return summaries, nil
+}
+
+func segmentMemberCSVRecord(c *customerv1.Customer, s *customerv1.CustomerSummary) []string {
+	var categories []string
+	for _, cat := range s.GetFavouriteCategories() {
+		categories = append(categories, cat.GetName())
+	}
+	lastRental := ""
+	if s.GetLastRentalDate() != nil {
+		lastRental = s.GetLastRentalDate().AsTime().Format(time.RFC3339)
+	}
+	return []string{
+		strconv.Itoa(int(c.GetCustomerId())),
+		c.GetFirstName(),
+		c.GetLastName(),
+		c.GetEmail(),
+		strconv.Itoa(int(c.GetStoreId())),
+		strconv.FormatBool(c.GetActive()),
+		strconv.Itoa(int(s.GetTotalRentals())),
+		lastRental,
+		s.GetLifetimeSpend(),
+		strings.Join(categories, csvListSeparator),
+	}
+}
@@ -0,0 +1,293 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	paymentv1 "github.com/enkaigaku/dvd-rental/gen/proto/payment/v1"
+	"github.com/enkaigaku/dvd-rental/pkg/middleware"
+)
+
+// StoredValueHandler handles gift card and store credit endpoints.
+type StoredValueHandler struct {
+	storedValueClient paymentv1.StoredValueServiceClient
+}
+
+// NewStoredValueHandler creates a new StoredValueHandler.
+func NewStoredValueHandler(storedValueClient paymentv1.StoredValueServiceClient) *StoredValueHandler {
+	return &StoredValueHandler{storedValueClient: storedValueClient}
+}
+
+// --- JSON models ---
+
+type storedValueAccountResponse struct {
+	AccountID  int32  `json:"account_id"`
+	Kind       string `json:"kind"`
+	Code       string `json:"code"`
+	CustomerID int32  `json:"customer_id,omitempty"`
+	Balance    string `json:"balance"`
+	Active     bool   `json:"active"`
+	CreateDate string `json:"create_date"`
+	LastUpdate string `json:"last_update"`
+}
+
+type storedValueAccountListResponse struct {
+	Accounts []storedValueAccountResponse `json:"accounts"`
+}
+
+type storedValueEntryResponse struct {
+	EntryID       int32  `json:"entry_id"`
+	TransactionID int32  `json:"transaction_id"`
+	Kind          string `json:"kind"`
+	Amount        string `json:"amount"`
+	BalanceAfter  string `json:"balance_after"`
+	PaymentID     int32  `json:"payment_id,omitempty"`
+	Note          string `json:"note,omitempty"`
+	CreatedAt     string `json:"created_at"`
+}
+
+type storedValueEntryListResponse struct {
+	Entries    []storedValueEntryResponse `json:"entries"`
+	TotalCount int32                      `json:"total_count"`
+}
+
+type transferResponse struct {
+	From storedValueAccountResponse `json:"from"`
+	To   storedValueAccountResponse `json:"to"`
+}
+
+type issueGiftCardRequest struct {
+	Amount     string `json:"amount"`
+	CustomerID int32  `json:"customer_id"` // optional
+	Note       string `json:"note"`
+}
+
+type issueStoreCreditRequest struct {
+	Amount string `json:"amount"`
+	Note   string `json:"note"`
+}
+
+type redeemRequest struct {
+	Amount string `json:"amount"`
+	Note   string `json:"note"`
+}
+
+type transferRequest struct {
+	ToCode string `json:"to_code"`
+	Amount string `json:"amount"`
+	Note   string `json:"note"`
+}
+
+func storedValueAccountToResponse(a *paymentv1.StoredValueAccount) storedValueAccountResponse {
+	return storedValueAccountResponse{
+		AccountID:  a.GetAccountId(),
+		Kind:       a.GetKind(),
+		Code:       a.GetCode(),
+		CustomerID: a.GetCustomerId(),
+		Balance:    a.GetBalance(),
+		Active:     a.GetActive(),
+		CreateDate: a.GetCreateDate().AsTime().Format(time.RFC3339),
+		LastUpdate: a.GetLastUpdate().AsTime().Format(time.RFC3339),
+	}
+}
+
+func storedValueEntryToResponse(e *paymentv1.StoredValueEntry) storedValueEntryResponse {
+	return storedValueEntryResponse{
+		EntryID:       e.GetEntryId(),
+		TransactionID: e.GetTransactionId(),
+		Kind:          e.GetKind(),
+		Amount:        e.GetAmount(),
+		BalanceAfter:  e.GetBalanceAfter(),
+		PaymentID:     e.GetPaymentId(),
+		Note:          e.GetNote(),
+		CreatedAt:     e.GetCreatedAt().AsTime().Format(time.RFC3339),
+	}
+}
+
+// IssueGiftCard issues a new gift card. The signed-in staff member is
+// recorded as the issuer.
+func (h *StoredValueHandler) IssueGiftCard(w http.ResponseWriter, r *http.Request) {
+	var req issueGiftCardRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+	claims := middleware.GetClaims(r.Context())
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	account, err := h.storedValueClient.IssueGiftCard(ctx, &paymentv1.IssueGiftCardRequest{
+		Amount:     req.Amount,
+		CustomerId: req.CustomerID,
+		StaffId:    claims.UserID,
+		Note:       req.Note,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, storedValueAccountToResponse(account))
+}
+
+// GetGiftCard returns a gift card or store credit account by code.
+func (h *StoredValueHandler) GetGiftCard(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	account, err := h.storedValueClient.GetStoredValueAccount(ctx, &paymentv1.GetStoredValueAccountRequest{
+		Code: r.PathValue("code"),
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, storedValueAccountToResponse(account))
+}
+
+// ListGiftCardEntries returns the ledger entries of an account, newest first.
+func (h *StoredValueHandler) ListGiftCardEntries(w http.ResponseWriter, r *http.Request) {
+	pageSize, page := parsePagination(r)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.storedValueClient.ListStoredValueEntries(ctx, &paymentv1.ListStoredValueEntriesRequest{
+		Code:     r.PathValue("code"),
+		PageSize: pageSize,
+		Page:     page,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	entries := make([]storedValueEntryResponse, len(resp.GetEntries()))
+	for i, e := range resp.GetEntries() {
+		entries[i] = storedValueEntryToResponse(e)
+	}
+
+	writeJSON(w, http.StatusOK, storedValueEntryListResponse{
+		Entries:    entries,
+		TotalCount: resp.GetTotalCount(),
+	})
+}
+
+// RedeemGiftCard spends part of a balance outside of a rental payment.
+func (h *StoredValueHandler) RedeemGiftCard(w http.ResponseWriter, r *http.Request) {
+	var req redeemRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+	claims := middleware.GetClaims(r.Context())
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	account, err := h.storedValueClient.RedeemStoredValue(ctx, &paymentv1.RedeemStoredValueRequest{
+		Code:    r.PathValue("code"),
+		Amount:  req.Amount,
+		StaffId: claims.UserID,
+		Note:    req.Note,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, storedValueAccountToResponse(account))
+}
+
+// TransferGiftCard moves balance from one account to another.
+func (h *StoredValueHandler) TransferGiftCard(w http.ResponseWriter, r *http.Request) {
+	var req transferRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+	claims := middleware.GetClaims(r.Context())
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.storedValueClient.TransferStoredValue(ctx, &paymentv1.TransferStoredValueRequest{
+		FromCode: r.PathValue("code"),
+		ToCode:   req.ToCode,
+		Amount:   req.Amount,
+		StaffId:  claims.UserID,
+		Note:     req.Note,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, transferResponse{
+		From: storedValueAccountToResponse(resp.GetFrom()),
+		To:   storedValueAccountToResponse(resp.GetTo()),
+	})
+}
+
+// IssueStoreCredit adds store credit to a customer, opening their store
+// credit account on first use.
+func (h *StoredValueHandler) IssueStoreCredit(w http.ResponseWriter, r *http.Request) {
+	customerID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid customer id")
+		return
+	}
+
+	var req issueStoreCreditRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+	claims := middleware.GetClaims(r.Context())
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	account, err := h.storedValueClient.IssueStoreCredit(ctx, &paymentv1.IssueStoreCreditRequest{
+		CustomerId: customerID,
+		Amount:     req.Amount,
+		StaffId:    claims.UserID,
+		Note:       req.Note,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, storedValueAccountToResponse(account))
+}
+
+// ListCustomerStoredValue returns a customer's gift cards and store credit.
+func (h *StoredValueHandler) ListCustomerStoredValue(w http.ResponseWriter, r *http.Request) {
+	customerID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid customer id")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.storedValueClient.ListStoredValueAccountsByCustomer(ctx, &paymentv1.ListStoredValueAccountsByCustomerRequest{
+		CustomerId: customerID,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	accounts := make([]storedValueAccountResponse, len(resp.GetAccounts()))
+	for i, a := range resp.GetAccounts() {
+		accounts[i] = storedValueAccountToResponse(a)
+	}
+
+	writeJSON(w, http.StatusOK, storedValueAccountListResponse{Accounts: accounts})
+}
@@ -0,0 +1,457 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	paymentv1 "github.com/enkaigaku/dvd-rental/gen/proto/payment/v1"
+)
+
+// SubscriptionHandler handles membership plan and subscription endpoints.
+type SubscriptionHandler struct {
+	subscriptionClient paymentv1.SubscriptionServiceClient
+}
+
+// NewSubscriptionHandler creates a new SubscriptionHandler.
+func NewSubscriptionHandler(subscriptionClient paymentv1.SubscriptionServiceClient) *SubscriptionHandler {
+	return &SubscriptionHandler{subscriptionClient: subscriptionClient}
+}
+
+// --- JSON models ---
+
+type subscriptionPlanResponse struct {
+	PlanID               int32  `json:"plan_id"`
+	Name                 string `json:"name"`
+	Description          string `json:"description"`
+	Price                string `json:"price"`
+	IntervalMonths       int32  `json:"interval_months"`
+	MaxConcurrentRentals int32  `json:"max_concurrent_rentals"`
+	MaxRentalsPerPeriod  int32  `json:"max_rentals_per_period"`
+	Active               bool   `json:"active"`
+	LastUpdate           string `json:"last_update"`
+}
+
+type subscriptionPlanListResponse struct {
+	Plans []subscriptionPlanResponse `json:"plans"`
+}
+
+type subscriptionPlanRequest struct {
+	Name                 string `json:"name"`
+	Description          string `json:"description"`
+	Price                string `json:"price"`
+	IntervalMonths       int32  `json:"interval_months"`
+	MaxConcurrentRentals int32  `json:"max_concurrent_rentals"`
+	MaxRentalsPerPeriod  int32  `json:"max_rentals_per_period"`
+	Active               *bool  `json:"active"`
+}
+
+type subscriptionResponse struct {
+	SubscriptionID     int32  `json:"subscription_id"`
+	CustomerID         int32  `json:"customer_id"`
+	PlanID             int32  `json:"plan_id"`
+	PlanName           string `json:"plan_name"`
+	Status             string `json:"status"`
+	CurrentPeriodStart string `json:"current_period_start"`
+	CurrentPeriodEnd   string `json:"current_period_end"`
+	CancelAtPeriodEnd  bool   `json:"cancel_at_period_end"`
+	PauseAtPeriodEnd   bool   `json:"pause_at_period_end"`
+	FailedAttempts     int32  `json:"failed_attempts"`
+	NextBillingAt      string `json:"next_billing_at,omitempty"`
+	CanceledAt         string `json:"canceled_at,omitempty"`
+	CreateDate         string `json:"create_date"`
+}
+
+type subscriptionListResponse struct {
+	Subscriptions []subscriptionResponse `json:"subscriptions"`
+	TotalCount    int32                  `json:"total_count"`
+}
+
+type startSubscriptionRequest struct {
+	CustomerID int32 `json:"customer_id"`
+	PlanID     int32 `json:"plan_id"`
+}
+
+type cancelSubscriptionRequest struct {
+	Immediately bool `json:"immediately"`
+}
+
+type subscriptionPaymentResponse struct {
+	SubscriptionPaymentID int32  `json:"subscription_payment_id"`
+	Amount                string `json:"amount"`
+	PeriodStart           string `json:"period_start"`
+	PeriodEnd             string `json:"period_end"`
+	Status                string `json:"status"`
+	GatewayReference      string `json:"gateway_reference,omitempty"`
+	FailureReason         string `json:"failure_reason,omitempty"`
+	CreatedAt             string `json:"created_at"`
+}
+
+type subscriptionPaymentListResponse struct {
+	Payments   []subscriptionPaymentResponse `json:"payments"`
+	TotalCount int32                         `json:"total_count"`
+}
+
+type billingRunResponse struct {
+	Renewed  int32 `json:"renewed"`
+	Failed   int32 `json:"failed"`
+	Paused   int32 `json:"paused"`
+	Canceled int32 `json:"canceled"`
+}
+
+func subscriptionPlanToResponse(p *paymentv1.SubscriptionPlan) subscriptionPlanResponse {
+	return subscriptionPlanResponse{
+		PlanID:               p.GetPlanId(),
+		Name:                 p.GetName(),
+		Description:          p.GetDescription(),
+		Price:                p.GetPrice(),
+		IntervalMonths:       p.GetIntervalMonths(),
+		MaxConcurrentRentals: p.GetMaxConcurrentRentals(),
+		MaxRentalsPerPeriod:  p.GetMaxRentalsPerPeriod(),
+		Active:               p.GetActive(),
+		LastUpdate:           p.GetLastUpdate().AsTime().Format(time.RFC3339),
+	}
+}
+
+func subscriptionToResponse(s *paymentv1.Subscription) subscriptionResponse {
+	resp := subscriptionResponse{
+		SubscriptionID:     s.GetSubscriptionId(),
+		CustomerID:         s.GetCustomerId(),
+		PlanID:             s.GetPlanId(),
+		PlanName:           s.GetPlanName(),
+		Status:             s.GetStatus(),
+		CurrentPeriodStart: s.GetCurrentPeriodStart().AsTime().Format(time.RFC3339),
+		CurrentPeriodEnd:   s.GetCurrentPeriodEnd().AsTime().Format(time.RFC3339),
+		CancelAtPeriodEnd:  s.GetCancelAtPeriodEnd(),
+		PauseAtPeriodEnd:   s.GetPauseAtPeriodEnd(),
+		FailedAttempts:     s.GetFailedAttempts(),
+		CreateDate:         s.GetCreateDate().AsTime().Format(time.RFC3339),
+	}
+	if s.GetNextBillingAt() != nil {
+		resp.NextBillingAt = s.GetNextBillingAt().AsTime().Format(time.RFC3339)
+	}
+	if s.GetCanceledAt() != nil {
+		resp.CanceledAt = s.GetCanceledAt().AsTime().Format(time.RFC3339)
+	}
+	return resp
+}
+
+// ListPlans returns all subscription plans, or only the plans open to new
+// subscribers with ?active_only=true.
+func (h *SubscriptionHandler) ListPlans(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.subscriptionClient.ListSubscriptionPlans(ctx, &paymentv1.ListSubscriptionPlansRequest{
+		ActiveOnly: parseQueryBool(r, "active_only"),
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	plans := make([]subscriptionPlanResponse, len(resp.GetPlans()))
+	for i, p := range resp.GetPlans() {
+		plans[i] = subscriptionPlanToResponse(p)
+	}
+
+	writeJSON(w, http.StatusOK, subscriptionPlanListResponse{Plans: plans})
+}
+
+// GetPlan returns a subscription plan by ID.
+func (h *SubscriptionHandler) GetPlan(w http.ResponseWriter, r *http.Request) {
+	planID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid plan id")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	plan, err := h.subscriptionClient.GetSubscriptionPlan(ctx, &paymentv1.GetSubscriptionPlanRequest{
+		PlanId: planID,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, subscriptionPlanToResponse(plan))
+}
+
+// CreatePlan creates a new subscription plan. Plans are active unless
+// "active": false is given.
+func (h *SubscriptionHandler) CreatePlan(w http.ResponseWriter, r *http.Request) {
+	var req subscriptionPlanRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	plan, err := h.subscriptionClient.CreateSubscriptionPlan(ctx, &paymentv1.CreateSubscriptionPlanRequest{
+		Name:                 req.Name,
+		Description:          req.Description,
+		Price:                req.Price,
+		IntervalMonths:       req.IntervalMonths,
+		MaxConcurrentRentals: req.MaxConcurrentRentals,
+		MaxRentalsPerPeriod:  req.MaxRentalsPerPeriod,
+		Active:               req.Active == nil || *req.Active,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, subscriptionPlanToResponse(plan))
+}
+
+// UpdatePlan replaces an existing subscription plan. Deactivated plans keep
+// their subscribers but cannot be started.
+func (h *SubscriptionHandler) UpdatePlan(w http.ResponseWriter, r *http.Request) {
+	planID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid plan id")
+		return
+	}
+
+	var req subscriptionPlanRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	plan, err := h.subscriptionClient.UpdateSubscriptionPlan(ctx, &paymentv1.UpdateSubscriptionPlanRequest{
+		PlanId:               planID,
+		Name:                 req.Name,
+		Description:          req.Description,
+		Price:                req.Price,
+		IntervalMonths:       req.IntervalMonths,
+		MaxConcurrentRentals: req.MaxConcurrentRentals,
+		MaxRentalsPerPeriod:  req.MaxRentalsPerPeriod,
+		Active:               req.Active == nil || *req.Active,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, subscriptionPlanToResponse(plan))
+}
+
+// ListSubscriptions returns subscriptions, optionally filtered by the
+// customer_id and status query parameters.
+func (h *SubscriptionHandler) ListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	pageSize, page := parsePagination(r)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.subscriptionClient.ListSubscriptions(ctx, &paymentv1.ListSubscriptionsRequest{
+		PageSize:   pageSize,
+		Page:       page,
+		CustomerId: parseQueryInt32(r, "customer_id"),
+		Status:     r.URL.Query().Get("status"),
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	subs := make([]subscriptionResponse, len(resp.GetSubscriptions()))
+	for i, s := range resp.GetSubscriptions() {
+		subs[i] = subscriptionToResponse(s)
+	}
+
+	writeJSON(w, http.StatusOK, subscriptionListResponse{
+		Subscriptions: subs,
+		TotalCount:    resp.GetTotalCount(),
+	})
+}
+
+// GetSubscription returns a subscription by ID.
+func (h *SubscriptionHandler) GetSubscription(w http.ResponseWriter, r *http.Request) {
+	subscriptionID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid subscription id")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	sub, err := h.subscriptionClient.GetSubscription(ctx, &paymentv1.GetSubscriptionRequest{
+		SubscriptionId: subscriptionID,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, subscriptionToResponse(sub))
+}
+
+// StartSubscription subscribes a customer to a plan, charging the first period.
+func (h *SubscriptionHandler) StartSubscription(w http.ResponseWriter, r *http.Request) {
+	var req startSubscriptionRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	sub, err := h.subscriptionClient.StartSubscription(ctx, &paymentv1.StartSubscriptionRequest{
+		CustomerId: req.CustomerID,
+		PlanId:     req.PlanID,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, subscriptionToResponse(sub))
+}
+
+// CancelSubscription cancels a subscription at the end of the paid period,
+// or at once with {"immediately": true}. The body is optional.
+func (h *SubscriptionHandler) CancelSubscription(w http.ResponseWriter, r *http.Request) {
+	subscriptionID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid subscription id")
+		return
+	}
+
+	var req cancelSubscriptionRequest
+	if r.ContentLength != 0 {
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	sub, err := h.subscriptionClient.CancelSubscription(ctx, &paymentv1.CancelSubscriptionRequest{
+		SubscriptionId: subscriptionID,
+		Immediately:    req.Immediately,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, subscriptionToResponse(sub))
+}
+
+// PauseSubscription stops billing a subscription at the end of the paid period.
+func (h *SubscriptionHandler) PauseSubscription(w http.ResponseWriter, r *http.Request) {
+	h.changeSubscription(w, r, func(ctx context.Context, id int32) (*paymentv1.Subscription, error) {
+		return h.subscriptionClient.PauseSubscription(ctx, &paymentv1.PauseSubscriptionRequest{SubscriptionId: id})
+	})
+}
+
+// ResumeSubscription undoes a scheduled pause or cancellation, or restarts a
+// paused subscription with a new charge.
+func (h *SubscriptionHandler) ResumeSubscription(w http.ResponseWriter, r *http.Request) {
+	h.changeSubscription(w, r, func(ctx context.Context, id int32) (*paymentv1.Subscription, error) {
+		return h.subscriptionClient.ResumeSubscription(ctx, &paymentv1.ResumeSubscriptionRequest{SubscriptionId: id})
+	})
+}
+
+// RenewSubscription retries the charge for a past due subscription.
+func (h *SubscriptionHandler) RenewSubscription(w http.ResponseWriter, r *http.Request) {
+	h.changeSubscription(w, r, func(ctx context.Context, id int32) (*paymentv1.Subscription, error) {
+		return h.subscriptionClient.RenewSubscription(ctx, &paymentv1.RenewSubscriptionRequest{SubscriptionId: id})
+	})
+}
+
+// ListSubscriptionPayments returns the charge attempts for a subscription.
+func (h *SubscriptionHandler) ListSubscriptionPayments(w http.ResponseWriter, r *http.Request) {
+	subscriptionID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid subscription id")
+		return
+	}
+	pageSize, page := parsePagination(r)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.subscriptionClient.ListSubscriptionPayments(ctx, &paymentv1.ListSubscriptionPaymentsRequest{
+		SubscriptionId: subscriptionID,
+		PageSize:       pageSize,
+		Page:           page,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	payments := make([]subscriptionPaymentResponse, len(resp.GetPayments()))
+	for i, p := range resp.GetPayments() {
+		payments[i] = subscriptionPaymentResponse{
+			SubscriptionPaymentID: p.GetSubscriptionPaymentId(),
+			Amount:                p.GetAmount(),
+			PeriodStart:           p.GetPeriodStart().AsTime().Format(time.RFC3339),
+			PeriodEnd:             p.GetPeriodEnd().AsTime().Format(time.RFC3339),
+			Status:                p.GetStatus(),
+			GatewayReference:      p.GetGatewayReference(),
+			FailureReason:         p.GetFailureReason(),
+			CreatedAt:             p.GetCreatedAt().AsTime().Format(time.RFC3339),
+		}
+	}
+
+	writeJSON(w, http.StatusOK, subscriptionPaymentListResponse{
+		Payments:   payments,
+		TotalCount: resp.GetTotalCount(),
+	})
+}
+
+// RunBilling bills every subscription that is due now, without waiting for
+// the payment service's billing job.
+func (h *SubscriptionHandler) RunBilling(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.subscriptionClient.RunSubscriptionBilling(ctx, &paymentv1.RunSubscriptionBillingRequest{})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, billingRunResponse{
+		Renewed:  resp.GetRenewed(),
+		Failed:   resp.GetFailed(),
+		Paused:   resp.GetPaused(),
+		Canceled: resp.GetCanceled(),
+	})
+}
+
+// changeSubscription applies a state change to the subscription in the path.
+func (h *SubscriptionHandler) changeSubscription(w http.ResponseWriter, r *http.Request, change func(ctx context.Context, id int32) (*paymentv1.Subscription, error)) {
+	subscriptionID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid subscription id")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	sub, err := change(ctx, subscriptionID)
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, subscriptionToResponse(sub))
+}
@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	paymentv1 "github.com/enkaigaku/dvd-rental/gen/proto/payment/v1"
+)
+
+// TaxHandler handles sales tax rate endpoints.
+type TaxHandler struct {
+	taxClient paymentv1.TaxServiceClient
+}
+
+// NewTaxHandler creates a new TaxHandler.
+func NewTaxHandler(taxClient paymentv1.TaxServiceClient) *TaxHandler {
+	return &TaxHandler{taxClient: taxClient}
+}
+
+// --- JSON models ---
+
+type taxRateResponse struct {
+	TaxRateID  int32  `json:"tax_rate_id"`
+	Name       string `json:"name"`
+	CountryID  int32  `json:"country_id"`
+	Country    string `json:"country"`
+	CityID     int32  `json:"city_id,omitempty"`
+	City       string `json:"city,omitempty"`
+	ChargeType string `json:"charge_type,omitempty"`
+	Rate       string `json:"rate"`
+	Active     bool   `json:"active"`
+	LastUpdate string `json:"last_update"`
+}
+
+type taxRateListResponse struct {
+	TaxRates []taxRateResponse `json:"tax_rates"`
+}
+
+type taxRateRequest struct {
+	Name       string `json:"name"`
+	CountryID  int32  `json:"country_id"`
+	CityID     int32  `json:"city_id"`     // 0 = the whole country
+	ChargeType string `json:"charge_type"` // empty = every charge type
+	Rate       string `json:"rate"`        // percentage, e.g. "8.25"
+	Active     *bool  `json:"active"`
+}
+
+func taxRateToResponse(t *paymentv1.TaxRate) taxRateResponse {
+	return taxRateResponse{
+		TaxRateID:  t.GetTaxRateId(),
+		Name:       t.GetName(),
+		CountryID:  t.GetCountryId(),
+		Country:    t.GetCountry(),
+		CityID:     t.GetCityId(),
+		City:       t.GetCity(),
+		ChargeType: t.GetChargeType(),
+		Rate:       t.GetRate(),
+		Active:     t.GetActive(),
+		LastUpdate: t.GetLastUpdate().AsTime().Format(time.RFC3339),
+	}
+}
+
+// ListTaxRates returns all tax rates, or those of one country with ?country_id=.
+func (h *TaxHandler) ListTaxRates(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.taxClient.ListTaxRates(ctx, &paymentv1.ListTaxRatesRequest{
+		CountryId: parseQueryInt32(r, "country_id"),
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	rates := make([]taxRateResponse, len(resp.GetTaxRates()))
+	for i, t := range resp.GetTaxRates() {
+		rates[i] = taxRateToResponse(t)
+	}
+
+	writeJSON(w, http.StatusOK, taxRateListResponse{TaxRates: rates})
+}
+
+// GetTaxRate returns a tax rate by ID.
+func (h *TaxHandler) GetTaxRate(w http.ResponseWriter, r *http.Request) {
+	taxRateID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid tax rate id")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	rate, err := h.taxClient.GetTaxRate(ctx, &paymentv1.GetTaxRateRequest{
+		TaxRateId: taxRateID,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, taxRateToResponse(rate))
+}
+
+// CreateTaxRate creates a new tax rate. Rates are active unless
+// "active": false is given.
+func (h *TaxHandler) CreateTaxRate(w http.ResponseWriter, r *http.Request) {
+	var req taxRateRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	rate, err := h.taxClient.CreateTaxRate(ctx, &paymentv1.CreateTaxRateRequest{
+		Name:       req.Name,
+		CountryId:  req.CountryID,
+		CityId:     req.CityID,
+		ChargeType: req.ChargeType,
+		Rate:       req.Rate,
+		Active:     req.Active == nil || *req.Active,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, taxRateToResponse(rate))
+}
+
+// UpdateTaxRate replaces an existing tax rate. Payments already taken keep
+// the tax they were charged.
+func (h *TaxHandler) UpdateTaxRate(w http.ResponseWriter, r *http.Request) {
+	taxRateID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid tax rate id")
+		return
+	}
+
+	var req taxRateRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	rate, err := h.taxClient.UpdateTaxRate(ctx, &paymentv1.UpdateTaxRateRequest{
+		TaxRateId:  taxRateID,
+		Name:       req.Name,
+		CountryId:  req.CountryID,
+		CityId:     req.CityID,
+		ChargeType: req.ChargeType,
+		Rate:       req.Rate,
+		Active:     req.Active == nil || *req.Active,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, taxRateToResponse(rate))
+}
+
+// DeleteTaxRate deletes a tax rate that has never been charged.
+func (h *TaxHandler) DeleteTaxRate(w http.ResponseWriter, r *http.Request) {
+	taxRateID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid tax rate id")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	_, err = h.taxClient.DeleteTaxRate(ctx, &paymentv1.DeleteTaxRateRequest{
+		TaxRateId: taxRateID,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	filmv1 "github.com/enkaigaku/dvd-rental/gen/proto/film/v1"
+)
+
+// WishlistHandler handles viewing customers' wishlists.
+type WishlistHandler struct {
+	wishlistClient filmv1.WishlistServiceClient
+}
+
+// NewWishlistHandler creates a new WishlistHandler.
+func NewWishlistHandler(wishlistClient filmv1.WishlistServiceClient) *WishlistHandler {
+	return &WishlistHandler{wishlistClient: wishlistClient}
+}
+
+// --- JSON models ---
+
+type wishlistItemResponse struct {
+	filmResponse
+	AddedAt         string `json:"added_at"`
+	StoreID         int32  `json:"store_id"`
+	Copies          int32  `json:"copies"`
+	AvailableCopies int32  `json:"available_copies"`
+}
+
+type wishlistResponse struct {
+	Films      []wishlistItemResponse `json:"films"`
+	TotalCount int32                  `json:"total_count"`
+}
+
+// ListCustomerWishlist returns a customer's wishlist, most recently added
+// first, with each film's availability at the customer's home store.
+func (h *WishlistHandler) ListCustomerWishlist(w http.ResponseWriter, r *http.Request) {
+	customerID, err := parseIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid customer id")
+		return
+	}
+	pageSize, page := parsePagination(r)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.wishlistClient.ListWishlist(ctx, &filmv1.ListWishlistRequest{
+		CustomerId: customerID,
+		PageSize:   pageSize,
+		Page:       page,
+	})
+	if err != nil {
+		handleGRPCError(w, err)
+		return
+	}
+
+	films := make([]wishlistItemResponse, len(resp.GetItems()))
+	for i, item := range resp.GetItems() {
+		films[i] = wishlistItemResponse{
+			filmResponse:    filmToResponse(item.GetFilm()),
+			AddedAt:         item.GetAddedAt().AsTime().Format(time.RFC3339),
+			StoreID:         item.GetStoreId(),
+			Copies:          item.GetCopies(),
+			AvailableCopies: item.GetAvailableCopies(),
+		}
+	}
+
+	writeJSON(w, http.StatusOK, wishlistResponse{
+		Films:      films,
+		TotalCount: resp.GetTotalCount(),
+	})
+}
@@ -17,6 +17,17 @@ func NewRouter(
 	inventoryH *handler.InventoryHandler,
 	rentalH *handler.RentalHandler,
 	paymentH *handler.PaymentHandler,
+	promotionH *handler.PromotionHandler,
+	storedValueH *handler.StoredValueHandler,
+	loyaltyH *handler.LoyaltyHandler,
+	subscriptionH *handler.SubscriptionHandler,
+	taxH *handler.TaxHandler,
+	reviewH *handler.ReviewHandler,
+	recommendationH *handler.RecommendationHandler,
+	filmAssetH *handler.FilmAssetHandler,
+	wishlistH *handler.WishlistHandler,
+	segmentH *handler.SegmentHandler,
+	duplicateH *handler.CustomerDuplicateHandler,
 	authMw *middleware.AuthMiddleware,
 ) http.Handler {
 	mux := http.NewServeMux()
@@ -47,13 +58,35 @@ func NewRouter(
 	mux.Handle("POST /api/v1/customers", authMw.Require(http.HandlerFunc(customerH.CreateCustomer)))
 	mux.Handle("PUT /api/v1/customers/{id}", authMw.Require(http.HandlerFunc(customerH.UpdateCustomer)))
 	mux.Handle("DELETE /api/v1/customers/{id}", authMw.Require(http.HandlerFunc(customerH.DeleteCustomer)))
+	mux.Handle("GET /api/v1/customers/{id}/export", authMw.Require(http.HandlerFunc(customerH.ExportCustomerData)))
+	mux.Handle("GET /api/v1/customers/{id}/wishlist", authMw.Require(http.HandlerFunc(wishlistH.ListCustomerWishlist)))
+	mux.Handle("POST /api/v1/customers/{id}/merge", authMw.Require(http.HandlerFunc(duplicateH.MergeCustomer)))
+
+	// --- Protected: Duplicate Customer Review ---
+	mux.Handle("GET /api/v1/customer-duplicates", authMw.Require(http.HandlerFunc(duplicateH.ListDuplicates)))
+	mux.Handle("POST /api/v1/customer-duplicates/scan", authMw.Require(http.HandlerFunc(duplicateH.ScanDuplicates)))
+	mux.Handle("POST /api/v1/customer-duplicates/{id}/dismiss", authMw.Require(http.HandlerFunc(duplicateH.DismissDuplicate)))
+
+	// --- Protected: Customer Segments ---
+	mux.Handle("GET /api/v1/segments", authMw.Require(http.HandlerFunc(segmentH.ListSegments)))
+	mux.Handle("GET /api/v1/segments/{id}", authMw.Require(http.HandlerFunc(segmentH.GetSegment)))
+	mux.Handle("POST /api/v1/segments", authMw.Require(http.HandlerFunc(segmentH.CreateSegment)))
+	mux.Handle("PUT /api/v1/segments/{id}", authMw.Require(http.HandlerFunc(segmentH.UpdateSegment)))
+	mux.Handle("DELETE /api/v1/segments/{id}", authMw.Require(http.HandlerFunc(segmentH.DeleteSegment)))
+	mux.Handle("POST /api/v1/segments/preview", authMw.Require(http.HandlerFunc(segmentH.PreviewSegment)))
+	mux.Handle("POST /api/v1/segments/{id}/evaluate", authMw.Require(http.HandlerFunc(segmentH.EvaluateSegment)))
+	mux.Handle("GET /api/v1/segments/{id}/members", authMw.Require(http.HandlerFunc(segmentH.ListSegmentMembers)))
+	mux.Handle("GET /api/v1/segments/{id}/export", authMw.Require(http.HandlerFunc(segmentH.ExportSegmentMembers)))
 
 	// --- Protected: Films ---
 	mux.Handle("GET /api/v1/films", authMw.Require(http.HandlerFunc(filmH.ListFilms)))
+	mux.Handle("GET /api/v1/films/export", authMw.Require(http.HandlerFunc(filmH.ExportFilms)))
+	mux.Handle("POST /api/v1/films/import", authMw.Require(http.HandlerFunc(filmH.ImportFilms)))
 	mux.Handle("GET /api/v1/films/{id}", authMw.Require(http.HandlerFunc(filmH.GetFilm)))
 	mux.Handle("POST /api/v1/films", authMw.Require(http.HandlerFunc(filmH.CreateFilm)))
 	mux.Handle("PUT /api/v1/films/{id}", authMw.Require(http.HandlerFunc(filmH.UpdateFilm)))
 	mux.Handle("DELETE /api/v1/films/{id}", authMw.Require(http.HandlerFunc(filmH.DeleteFilm)))
+	mux.Handle("POST /api/v1/films/{id}/restore", authMw.Require(http.HandlerFunc(filmH.RestoreFilm)))
 	mux.Handle("POST /api/v1/films/{id}/actors", authMw.Require(http.HandlerFunc(filmH.AddActorToFilm)))
 	mux.Handle("DELETE /api/v1/films/{id}/actors/{actorId}", authMw.Require(http.HandlerFunc(filmH.RemoveActorFromFilm)))
 	mux.Handle("POST /api/v1/films/{id}/categories", authMw.Require(http.HandlerFunc(filmH.AddCategoryToFilm)))
@@ -65,12 +98,21 @@ func NewRouter(
 	mux.Handle("POST /api/v1/actors", authMw.Require(http.HandlerFunc(filmH.CreateActor)))
 	mux.Handle("PUT /api/v1/actors/{id}", authMw.Require(http.HandlerFunc(filmH.UpdateActor)))
 	mux.Handle("DELETE /api/v1/actors/{id}", authMw.Require(http.HandlerFunc(filmH.DeleteActor)))
+	mux.Handle("POST /api/v1/actors/{id}/restore", authMw.Require(http.HandlerFunc(filmH.RestoreActor)))
 
-	// --- Protected: Categories (read-only) ---
+	// --- Protected: Categories ---
 	mux.Handle("GET /api/v1/categories", authMw.Require(http.HandlerFunc(filmH.ListCategories)))
+	mux.Handle("GET /api/v1/categories/{id}", authMw.Require(http.HandlerFunc(filmH.GetCategory)))
+	mux.Handle("POST /api/v1/categories", authMw.Require(http.HandlerFunc(filmH.CreateCategory)))
+	mux.Handle("PUT /api/v1/categories/{id}", authMw.Require(http.HandlerFunc(filmH.UpdateCategory)))
+	mux.Handle("DELETE /api/v1/categories/{id}", authMw.Require(http.HandlerFunc(filmH.DeleteCategory)))
 
-	// --- Protected: Languages (read-only) ---
+	// --- Protected: Languages ---
 	mux.Handle("GET /api/v1/languages", authMw.Require(http.HandlerFunc(filmH.ListLanguages)))
+	mux.Handle("GET /api/v1/languages/{id}", authMw.Require(http.HandlerFunc(filmH.GetLanguage)))
+	mux.Handle("POST /api/v1/languages", authMw.Require(http.HandlerFunc(filmH.CreateLanguage)))
+	mux.Handle("PUT /api/v1/languages/{id}", authMw.Require(http.HandlerFunc(filmH.UpdateLanguage)))
+	mux.Handle("DELETE /api/v1/languages/{id}", authMw.Require(http.HandlerFunc(filmH.DeleteLanguage)))
 
 	// --- Protected: Inventory ---
 	mux.Handle("GET /api/v1/inventory", authMw.Require(http.HandlerFunc(inventoryH.ListInventory)))
@@ -90,8 +132,73 @@ func NewRouter(
 	// --- Protected: Payments ---
 	mux.Handle("GET /api/v1/payments", authMw.Require(http.HandlerFunc(paymentH.ListPayments)))
 	mux.Handle("GET /api/v1/payments/{id}", authMw.Require(http.HandlerFunc(paymentH.GetPayment)))
+	mux.Handle("GET /api/v1/payments/{id}/receipt", authMw.Require(http.HandlerFunc(paymentH.GetPaymentReceipt)))
 	mux.Handle("POST /api/v1/payments", authMw.Require(http.HandlerFunc(paymentH.CreatePayment)))
 	mux.Handle("DELETE /api/v1/payments/{id}", authMw.Require(http.HandlerFunc(paymentH.DeletePayment)))
+	mux.Handle("GET /api/v1/reports/revenue", authMw.Require(http.HandlerFunc(paymentH.GetRevenueReport)))
+
+	// --- Protected: Promotions & pricing ---
+	mux.Handle("GET /api/v1/promotions", authMw.Require(http.HandlerFunc(promotionH.ListPromotions)))
+	mux.Handle("GET /api/v1/promotions/{id}", authMw.Require(http.HandlerFunc(promotionH.GetPromotion)))
+	mux.Handle("POST /api/v1/promotions", authMw.Require(http.HandlerFunc(promotionH.CreatePromotion)))
+	mux.Handle("PUT /api/v1/promotions/{id}", authMw.Require(http.HandlerFunc(promotionH.UpdatePromotion)))
+	mux.Handle("DELETE /api/v1/promotions/{id}", authMw.Require(http.HandlerFunc(promotionH.DeletePromotion)))
+	mux.Handle("GET /api/v1/pricing/quote", authMw.Require(http.HandlerFunc(promotionH.QuoteRentalPrice)))
+
+	// --- Protected: Gift cards & store credit ---
+	mux.Handle("POST /api/v1/gift-cards", authMw.Require(http.HandlerFunc(storedValueH.IssueGiftCard)))
+	mux.Handle("GET /api/v1/gift-cards/{code}", authMw.Require(http.HandlerFunc(storedValueH.GetGiftCard)))
+	mux.Handle("GET /api/v1/gift-cards/{code}/entries", authMw.Require(http.HandlerFunc(storedValueH.ListGiftCardEntries)))
+	mux.Handle("POST /api/v1/gift-cards/{code}/redeem", authMw.Require(http.HandlerFunc(storedValueH.RedeemGiftCard)))
+	mux.Handle("POST /api/v1/gift-cards/{code}/transfer", authMw.Require(http.HandlerFunc(storedValueH.TransferGiftCard)))
+	mux.Handle("GET /api/v1/customers/{id}/stored-value", authMw.Require(http.HandlerFunc(storedValueH.ListCustomerStoredValue)))
+	mux.Handle("POST /api/v1/customers/{id}/store-credit", authMw.Require(http.HandlerFunc(storedValueH.IssueStoreCredit)))
+
+	// --- Protected: Loyalty ---
+	mux.Handle("GET /api/v1/loyalty/tiers", authMw.Require(http.HandlerFunc(loyaltyH.ListTiers)))
+	mux.Handle("POST /api/v1/loyalty/tiers/recalculate", authMw.Require(http.HandlerFunc(loyaltyH.RecalculateTiers)))
+	mux.Handle("GET /api/v1/loyalty/rewards-report", authMw.Require(http.HandlerFunc(loyaltyH.RunRewardsReport)))
+	mux.Handle("GET /api/v1/customers/{id}/loyalty", authMw.Require(http.HandlerFunc(loyaltyH.GetCustomerLoyalty)))
+	mux.Handle("GET /api/v1/customers/{id}/loyalty/transactions", authMw.Require(http.HandlerFunc(loyaltyH.ListCustomerLoyaltyTransactions)))
+
+	// --- Protected: Subscriptions ---
+	mux.Handle("GET /api/v1/subscription-plans", authMw.Require(http.HandlerFunc(subscriptionH.ListPlans)))
+	mux.Handle("GET /api/v1/subscription-plans/{id}", authMw.Require(http.HandlerFunc(subscriptionH.GetPlan)))
+	mux.Handle("POST /api/v1/subscription-plans", authMw.Require(http.HandlerFunc(subscriptionH.CreatePlan)))
+	mux.Handle("PUT /api/v1/subscription-plans/{id}", authMw.Require(http.HandlerFunc(subscriptionH.UpdatePlan)))
+	mux.Handle("GET /api/v1/subscriptions", authMw.Require(http.HandlerFunc(subscriptionH.ListSubscriptions)))
+	mux.Handle("POST /api/v1/subscriptions", authMw.Require(http.HandlerFunc(subscriptionH.StartSubscription)))
+	mux.Handle("POST /api/v1/subscriptions/billing/run", authMw.Require(http.HandlerFunc(subscriptionH.RunBilling)))
+	mux.Handle("GET /api/v1/subscriptions/{id}", authMw.Require(http.HandlerFunc(subscriptionH.GetSubscription)))
+	mux.Handle("POST /api/v1/subscriptions/{id}/cancel", authMw.Require(http.HandlerFunc(subscriptionH.CancelSubscription)))
+	mux.Handle("POST /api/v1/subscriptions/{id}/pause", authMw.Require(http.HandlerFunc(subscriptionH.PauseSubscription)))
+	mux.Handle("POST /api/v1/subscriptions/{id}/resume", authMw.Require(http.HandlerFunc(subscriptionH.ResumeSubscription)))
+	mux.Handle("POST /api/v1/subscriptions/{id}/renew", authMw.Require(http.HandlerFunc(subscriptionH.RenewSubscription)))
+	mux.Handle("GET /api/v1/subscriptions/{id}/payments", authMw.Require(http.HandlerFunc(subscriptionH.ListSubscriptionPayments)))
+
+	// --- Protected: Sales tax ---
+	mux.Handle("GET /api/v1/tax-rates", authMw.Require(http.HandlerFunc(taxH.ListTaxRates)))
+	mux.Handle("GET /api/v1/tax-rates/{id}", authMw.Require(http.HandlerFunc(taxH.GetTaxRate)))
+	mux.Handle("POST /api/v1/tax-rates", authMw.Require(http.HandlerFunc(taxH.CreateTaxRate)))
+	mux.Handle("PUT /api/v1/tax-rates/{id}", authMw.Require(http.HandlerFunc(taxH.UpdateTaxRate)))
+	mux.Handle("DELETE /api/v1/tax-rates/{id}", authMw.Require(http.HandlerFunc(taxH.DeleteTaxRate)))
+
+	// --- Protected: Review moderation ---
+	mux.Handle("GET /api/v1/reviews", authMw.Require(http.HandlerFunc(reviewH.ListReviews)))
+	mux.Handle("GET /api/v1/reviews/{id}", authMw.Require(http.HandlerFunc(reviewH.GetReview)))
+	mux.Handle("POST /api/v1/reviews/{id}/approve", authMw.Require(http.HandlerFunc(reviewH.ApproveReview)))
+	mux.Handle("POST /api/v1/reviews/{id}/reject", authMw.Require(http.HandlerFunc(reviewH.RejectReview)))
+	mux.Handle("DELETE /api/v1/reviews/{id}", authMw.Require(http.HandlerFunc(reviewH.DeleteReview)))
+	mux.Handle("GET /api/v1/films/{id}/reviews", authMw.Require(http.HandlerFunc(reviewH.ListFilmReviews)))
+
+	// --- Protected: Recommendations ---
+	mux.Handle("GET /api/v1/films/{id}/similar", authMw.Require(http.HandlerFunc(recommendationH.ListSimilarFilms)))
+	mux.Handle("POST /api/v1/recommendations/refresh", authMw.Require(http.HandlerFunc(recommendationH.RefreshSimilarities)))
+
+	// --- Protected: Film assets ---
+	mux.Handle("GET /api/v1/films/{id}/assets", authMw.Require(http.HandlerFunc(filmAssetH.ListFilmAssets)))
+	mux.Handle("POST /api/v1/films/{id}/assets", authMw.Require(http.HandlerFunc(filmAssetH.UploadFilmAsset)))
+	mux.Handle("DELETE /api/v1/films/{id}/assets/{assetId}", authMw.Require(http.HandlerFunc(filmAssetH.DeleteFilmAsset)))
 
 	// Apply middleware chain: Recovery → Logging → CORS → router.
 	return middleware.Recovery(
@@ -22,9 +22,24 @@ type Config struct {
 	AccessTokenDuration  time.Duration `envconfig:"JWT_ACCESS_DURATION" default:"15m"`
 	RefreshTokenDuration time.Duration `envconfig:"JWT_REFRESH_DURATION" default:"168h"`
 
-	// Redis URL for refresh token storage.
+	// Redis URL for refresh, password reset and email verification token
+	// storage.
 	RedisURL string `envconfig:"REDIS_URL" required:"true"`
 
+	// Password reset settings. Reset emails link to PasswordResetURL with
+	// the token added as ?token=.
+	PasswordResetTTL time.Duration `envconfig:"PASSWORD_RESET_TTL" default:"1h"`
+	PasswordResetURL string        `envconfig:"PASSWORD_RESET_URL" default:"http://localhost:3000/reset-password"`
+
+	// Email verification settings. Verification emails link to
+	// EmailVerifyURL with the token added as ?token=.
+	EmailVerifyTTL time.Duration `envconfig:"EMAIL_VERIFY_TTL" default:"24h"`
+	EmailVerifyURL string        `envconfig:"EMAIL_VERIFY_URL" default:"http://localhost:3000/verify-email"`
+
+	// Outgoing mail is written as .eml files to MailDir.
+	MailDir  string `envconfig:"MAIL_DIR" default:"data/mail"`
+	MailFrom string `envconfig:"MAIL_FROM" default:"DVD Rental <no-reply@dvdrental.local>"`
+
 	LogLevel string `envconfig:"LOG_LEVEL" default:"info"`
 }
 
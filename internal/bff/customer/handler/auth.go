@@ -2,12 +2,16 @@ package handler
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
 	"time"
 
 	customerv1 "github.com/enkaigaku/dvd-rental/gen/proto/customer/v1"
 	"github.com/enkaigaku/dvd-rental/pkg/auth"
 	"github.com/enkaigaku/dvd-rental/pkg/middleware"
+	"github.com/enkaigaku/dvd-rental/pkg/notify"
 )
 
 // AuthHandler handles authentication endpoints.
@@ -15,28 +19,75 @@ type AuthHandler struct {
 	customerClient customerv1.CustomerServiceClient
 	jwtManager     *auth.JWTManager
 	refreshStore   *auth.RefreshTokenStore
+	resetStore     *auth.PasswordResetStore
+	verifyStore    *auth.EmailVerificationStore
+	notifier       notify.Notifier
+	resetURL       string
+	verifier       *emailVerifier
 }
 
-// NewAuthHandler creates a new AuthHandler.
+// NewAuthHandler creates a new AuthHandler. Password reset and email
+// verification emails link to resetURL and verifyURL respectively, with the
+// token as the token query parameter.
 func NewAuthHandler(
 	customerClient customerv1.CustomerServiceClient,
 	jwtManager *auth.JWTManager,
 	refreshStore *auth.RefreshTokenStore,
+	resetStore *auth.PasswordResetStore,
+	verifyStore *auth.EmailVerificationStore,
+	notifier notify.Notifier,
+	resetURL string,
+	verifyURL string,
 ) *AuthHandler {
 	return &AuthHandler{
 		customerClient: customerClient,
 		jwtManager:     jwtManager,
 		refreshStore:   refreshStore,
+		resetStore:     resetStore,
+		verifyStore:    verifyStore,
+		notifier:       notifier,
+		resetURL:       resetURL,
+		verifier:       newEmailVerifier(verifyStore, notifier, verifyURL),
 	}
 }
 
+// minPasswordLength is the shortest password a customer can register with.
+const minPasswordLength = 8
+
 // --- JSON models ---
 
+type registerRequest struct {
+	FirstName  string `json:"first_name"`
+	LastName   string `json:"last_name"`
+	Email      string `json:"email"`
+	Password   string `json:"password"`
+	StoreID    int32  `json:"store_id"`
+	Address    string `json:"address"`
+	Address2   string `json:"address2"`
+	District   string `json:"district"`
+	CityID     int32  `json:"city_id"`
+	PostalCode string `json:"postal_code"`
+	Phone      string `json:"phone"`
+}
+
 type loginRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
 }
 
+type forgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type resetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+type verifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
 type refreshRequest struct {
 	RefreshToken string `json:"refresh_token"`
 }
@@ -51,6 +102,77 @@ type tokenResponse struct {
 	ExpiresIn    int64  `json:"expires_in"`
 }
 
+// Register signs up a new customer with their address and home store, and
+// returns a token pair so they are signed in straight away. A link to
+// verify their email is sent to them.
+func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
+	var req registerRequest
+	if err := readJSON(r, &req); err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+	if req.Email == "" || req.Password == "" {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "email and password are required")
+		return
+	}
+
+	// 1. Hash the password; the customer service only ever sees the hash.
+	hash, err := hashNewPassword(req.Password)
+	if err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	// 2. Create the address and customer.
+	customer, err := h.customerClient.RegisterCustomer(ctx, &customerv1.RegisterCustomerRequest{
+		StoreId:      req.StoreID,
+		FirstName:    req.FirstName,
+		LastName:     req.LastName,
+		Email:        req.Email,
+		PasswordHash: hash,
+		Address:      req.Address,
+		Address2:     req.Address2,
+		District:     req.District,
+		CityId:       req.CityID,
+		PostalCode:   req.PostalCode,
+		Phone:        req.Phone,
+	})
+	if err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	// 3. Generate token pair.
+	tokenPair, jti, err := h.jwtManager.GenerateTokenPair(customer.GetCustomerId(), auth.RoleCustomer, customer.GetEmail())
+	if err != nil {
+		middleware.WriteJSONError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to generate tokens")
+		return
+	}
+
+	// 4. Store refresh token in Redis.
+	if err := h.refreshStore.Store(ctx, jti, auth.RefreshTokenData{
+		UserID: customer.GetCustomerId(),
+		Role:   auth.RoleCustomer,
+	}); err != nil {
+		middleware.WriteJSONError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to store refresh token")
+		return
+	}
+
+	// 5. Send the verification email; the customer can ask for it again.
+	if err := h.verifier.send(ctx, customer.GetCustomerId(), customer.GetFirstName(), customer.GetEmail()); err != nil {
+		log.Printf("email verification for customer %d: %v", customer.GetCustomerId(), err)
+	}
+
+	middleware.WriteJSON(w, http.StatusCreated, tokenResponse{
+		AccessToken:  tokenPair.AccessToken,
+		RefreshToken: tokenPair.RefreshToken,
+		ExpiresIn:    tokenPair.ExpiresIn,
+	})
+}
+
 // Login authenticates a customer and returns a token pair.
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var req loginRequest
@@ -111,6 +233,164 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// ForgotPassword emails a password reset link to a customer. It responds
+// the same way whether or not the email belongs to an active customer, so
+// it cannot be used to find out who has an account.
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req forgotPasswordRequest
+	if err := readJSON(r, &req); err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+	if req.Email == "" {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "email is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	customer, err := h.customerClient.GetCustomerByEmail(ctx, &customerv1.GetCustomerByEmailRequest{
+		Email: req.Email,
+	})
+	if err == nil && customer.GetActive() {
+		if err := h.sendPasswordReset(ctx, customer); err != nil {
+			log.Printf("password reset for customer %d: %v", customer.GetCustomerId(), err)
+		}
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *AuthHandler) sendPasswordReset(ctx context.Context, customer *customerv1.Customer) error {
+	token, err := h.resetStore.Create(ctx, auth.PasswordResetData{
+		UserID: customer.GetCustomerId(),
+		Role:   auth.RoleCustomer,
+	})
+	if err != nil {
+		return err
+	}
+
+	link, err := linkWithToken(h.resetURL, token)
+	if err != nil {
+		return err
+	}
+
+	return h.notifier.Send(ctx, notify.Message{
+		To:      customer.GetEmail(),
+		Subject: "Reset your DVD Rental password",
+		Body: fmt.Sprintf("Hello %s,\n\n"+
+			"Someone asked to reset the password of your DVD Rental account. "+
+			"To choose a new password, open this link within %s:\n\n%s\n\n"+
+			"The link works once. If you did not ask for this, you can ignore this email.\n",
+			customer.GetFirstName(), h.resetStore.TTL(), link),
+	})
+}
+
+// ResetPassword sets a new password with a reset token from
+// ForgotPassword, invalidates any other reset links sent to the customer,
+// and signs them out of every session.
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req resetPasswordRequest
+	if err := readJSON(r, &req); err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+	if req.Token == "" || req.NewPassword == "" {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "token and new_password are required")
+		return
+	}
+
+	// 1. Hash the new password before using up the token.
+	hash, err := hashNewPassword(req.NewPassword)
+	if err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	// 2. Redeem the token.
+	data, err := h.resetStore.Consume(ctx, req.Token)
+	if err != nil {
+		if errors.Is(err, auth.ErrResetTokenInvalid) {
+			middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_TOKEN", "invalid or expired reset token")
+			return
+		}
+		middleware.WriteJSONError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to check reset token")
+		return
+	}
+	if data.Role != auth.RoleCustomer {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_TOKEN", "invalid or expired reset token")
+		return
+	}
+
+	// 3. Set the password.
+	if _, err := h.customerClient.UpdateCustomerPassword(ctx, &customerv1.UpdateCustomerPasswordRequest{
+		CustomerId:   data.UserID,
+		PasswordHash: hash,
+	}); err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	// 4. Invalidate any other reset links and revoke all refresh tokens.
+	if err := h.resetStore.DeleteAllForUser(ctx, data.UserID, auth.RoleCustomer); err != nil {
+		middleware.WriteJSONError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to revoke reset tokens")
+		return
+	}
+	if err := h.refreshStore.DeleteAllForUser(ctx, data.UserID, auth.RoleCustomer); err != nil {
+		middleware.WriteJSONError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to revoke refresh tokens")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// VerifyEmail confirms a customer's email with a token from a verification
+// email. For an email change, the new address becomes the customer's login.
+func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req verifyEmailRequest
+	if err := readJSON(r, &req); err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+	if req.Token == "" {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "token is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	data, err := h.verifyStore.Consume(ctx, req.Token)
+	if err != nil {
+		if errors.Is(err, auth.ErrVerificationTokenInvalid) {
+			middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_TOKEN", "invalid or expired verification token")
+			return
+		}
+		middleware.WriteJSONError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to check verification token")
+		return
+	}
+	if data.Role != auth.RoleCustomer {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_TOKEN", "invalid or expired verification token")
+		return
+	}
+
+	// Fails if the email change was cancelled or replaced since the token
+	// was sent.
+	if _, err := h.customerClient.VerifyCustomerEmail(ctx, &customerv1.VerifyCustomerEmailRequest{
+		CustomerId: data.UserID,
+		Email:      data.Email,
+	}); err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // Refresh generates a new token pair from a refresh token.
 func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 	var req refreshRequest
@@ -143,7 +423,8 @@ func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 	// 3. Delete the old refresh token (rotate).
 	_ = h.refreshStore.Delete(ctx, claims.ID)
 
-	// 4. Get customer email for the new access token.
+	// 4. Get customer email for the new access token. A customer disabled or
+	// erased since signing in gets no new tokens.
 	customerDetail, err := h.customerClient.GetCustomer(ctx, &customerv1.GetCustomerRequest{
 		CustomerId: tokenData.UserID,
 	})
@@ -151,6 +432,10 @@ func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 		grpcToHTTPError(w, err)
 		return
 	}
+	if customer := customerDetail.GetCustomer(); !customer.GetActive() || customer.GetAnonymizedAt() != nil {
+		middleware.WriteJSONError(w, http.StatusForbidden, "ACCOUNT_DISABLED", "account is disabled")
+		return
+	}
 
 	// 5. Generate new token pair.
 	tokenPair, jti, err := h.jwtManager.GenerateTokenPair(tokenData.UserID, tokenData.Role, customerDetail.GetCustomer().GetEmail())
@@ -200,3 +485,12 @@ func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 	_ = h.refreshStore.Delete(ctx, claims.ID)
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// hashNewPassword checks that a password is long enough to be set and
+// hashes it.
+func hashNewPassword(password string) (string, error) {
+	if len(password) < minPasswordLength {
+		return "", fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+	return auth.HashPassword(password)
+}
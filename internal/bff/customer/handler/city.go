@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	customerv1 "github.com/enkaigaku/dvd-rental/gen/proto/customer/v1"
+	"github.com/enkaigaku/dvd-rental/pkg/middleware"
+)
+
+// CityHandler handles city lookup endpoints (public), used to fill in the
+// city of an address.
+type CityHandler struct {
+	cityClient customerv1.CityServiceClient
+}
+
+// NewCityHandler creates a new CityHandler.
+func NewCityHandler(cityClient customerv1.CityServiceClient) *CityHandler {
+	return &CityHandler{cityClient: cityClient}
+}
+
+// --- JSON models ---
+
+type cityItem struct {
+	ID        int32  `json:"id"`
+	Name      string `json:"name"`
+	CountryID int32  `json:"country_id"`
+	Country   string `json:"country"`
+}
+
+type cityListResponse struct {
+	Cities     []cityItem `json:"cities"`
+	TotalCount int32      `json:"total_count"`
+	Page       int32      `json:"page"`
+	PageSize   int32      `json:"page_size"`
+}
+
+// ListCities lists cities, for autocomplete when the name query parameter
+// is given: cities whose name starts with it, ordered by name. country_id
+// narrows the list to one country.
+func (h *CityHandler) ListCities(w http.ResponseWriter, r *http.Request) {
+	countryID, err := parseQueryInt32(r, "country_id")
+	if err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+	pageSize, page := parsePagination(r)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.cityClient.ListCities(ctx, &customerv1.ListCitiesRequest{
+		Name:      r.URL.Query().Get("name"),
+		CountryId: countryID,
+		PageSize:  pageSize,
+		Page:      page,
+	})
+	if err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	cities := make([]cityItem, len(resp.GetCities()))
+	for i, c := range resp.GetCities() {
+		cities[i] = cityItem{
+			ID:        c.GetCityId(),
+			Name:      c.GetCity(),
+			CountryID: c.GetCountryId(),
+			Country:   c.GetCountry(),
+		}
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, cityListResponse{
+		Cities:     cities,
+		TotalCount: resp.GetTotalCount(),
+		Page:       page,
+		PageSize:   pageSize,
+	})
+}
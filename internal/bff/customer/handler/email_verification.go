@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/enkaigaku/dvd-rental/pkg/auth"
+	"github.com/enkaigaku/dvd-rental/pkg/notify"
+)
+
+// emailVerifier emails customers a link to confirm an email address.
+type emailVerifier struct {
+	store     *auth.EmailVerificationStore
+	notifier  notify.Notifier
+	verifyURL string
+}
+
+func newEmailVerifier(store *auth.EmailVerificationStore, notifier notify.Notifier, verifyURL string) *emailVerifier {
+	return &emailVerifier{
+		store:     store,
+		notifier:  notifier,
+		verifyURL: verifyURL,
+	}
+}
+
+// send issues a verification token for email and mails the link to it,
+// invalidating any link sent to the customer before.
+func (v *emailVerifier) send(ctx context.Context, customerID int32, firstName, email string) error {
+	token, err := v.store.Create(ctx, auth.EmailVerificationData{
+		UserID: customerID,
+		Role:   auth.RoleCustomer,
+		Email:  email,
+	})
+	if err != nil {
+		return err
+	}
+
+	link, err := linkWithToken(v.verifyURL, token)
+	if err != nil {
+		return err
+	}
+
+	return v.notifier.Send(ctx, notify.Message{
+		To:      email,
+		Subject: "Confirm your DVD Rental email address",
+		Body: fmt.Sprintf("Hello %s,\n\n"+
+			"Please confirm that this is the email address for your DVD Rental account "+
+			"by opening this link within %s:\n\n%s\n\n"+
+			"If you did not ask for this, you can ignore this email.\n",
+			firstName, v.store.TTL(), link),
+	})
+}
+
+// linkWithToken returns base with token set as its token query parameter.
+func linkWithToken(base, token string) (string, error) {
+	link, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("parse link url: %w", err)
+	}
+	q := link.Query()
+	q.Set("token", token)
+	link.RawQuery = q.Encode()
+	return link.String(), nil
+}
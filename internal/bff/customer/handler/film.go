@@ -2,7 +2,10 @@ package handler
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	filmv1 "github.com/enkaigaku/dvd-rental/gen/proto/film/v1"
@@ -32,12 +35,13 @@ func NewFilmHandler(
 // --- JSON models ---
 
 type filmListItem struct {
-	ID          int32  `json:"id"`
-	Title       string `json:"title"`
-	ReleaseYear int32  `json:"release_year,omitempty"`
-	RentalRate  string `json:"rental_rate"`
-	Length      int32  `json:"length,omitempty"`
-	Rating      string `json:"rating,omitempty"`
+	ID          int32      `json:"id"`
+	Title       string     `json:"title"`
+	ReleaseYear int32      `json:"release_year,omitempty"`
+	RentalRate  string     `json:"rental_rate"`
+	Length      int32      `json:"length,omitempty"`
+	Rating      string     `json:"rating,omitempty"`
+	Poster      *imageItem `json:"poster,omitempty"`
 }
 
 type filmDetailResponse struct {
@@ -55,6 +59,10 @@ type filmDetailResponse struct {
 	OriginalLanguage string         `json:"original_language,omitempty"`
 	Actors           []actorItem    `json:"actors,omitempty"`
 	Categories       []categoryItem `json:"categories,omitempty"`
+	AverageRating    string         `json:"average_rating,omitempty"`
+	ReviewCount      int32          `json:"review_count"`
+	Poster           *imageItem     `json:"poster,omitempty"`
+	Stills           []imageItem    `json:"stills,omitempty"`
 }
 
 type actorItem struct {
@@ -63,45 +71,172 @@ type actorItem struct {
 	LastName  string `json:"last_name"`
 }
 
+type actorDetailResponse struct {
+	ID          int32               `json:"id"`
+	FirstName   string              `json:"first_name"`
+	LastName    string              `json:"last_name"`
+	FilmCount   int32               `json:"film_count"`
+	RentalCount int64               `json:"rental_count"`
+	Filmography []actorCategoryItem `json:"filmography"`
+	CoStars     []coStarItem        `json:"co_stars"`
+}
+
+type actorCategoryItem struct {
+	CategoryID int32           `json:"category_id,omitempty"`
+	Category   string          `json:"category,omitempty"`
+	Films      []actorFilmItem `json:"films"`
+}
+
+type actorFilmItem struct {
+	ID          int32  `json:"id"`
+	Title       string `json:"title"`
+	ReleaseYear int32  `json:"release_year,omitempty"`
+	Rating      string `json:"rating,omitempty"`
+}
+
+type coStarItem struct {
+	actorItem
+	FilmsTogether int32 `json:"films_together"`
+}
+
 type categoryItem struct {
 	ID   int32  `json:"id"`
 	Name string `json:"name"`
 }
 
 type filmListResponse struct {
-	Films      []filmListItem `json:"films"`
-	TotalCount int32          `json:"total_count"`
-	Page       int32          `json:"page"`
-	PageSize   int32          `json:"page_size"`
+	Films      []filmListItem      `json:"films"`
+	TotalCount int32               `json:"total_count"`
+	Page       int32               `json:"page"`
+	PageSize   int32               `json:"page_size"`
+	Facets     *filmFacetsResponse `json:"facets,omitempty"`
+}
+
+type popularFilmItem struct {
+	filmListItem
+	Rentals int64 `json:"rentals"`
+}
+
+type popularFilmListResponse struct {
+	Films      []popularFilmItem `json:"films"`
+	TotalCount int32             `json:"total_count"`
+	Page       int32             `json:"page"`
+	PageSize   int32             `json:"page_size"`
+}
+
+type filmSearchItem struct {
+	filmListItem
+	TitleHighlight       string `json:"title_highlight"`
+	DescriptionHighlight string `json:"description_highlight,omitempty"`
+	Fuzzy                bool   `json:"fuzzy"`
+}
+
+type filmSearchResponse struct {
+	Films      []filmSearchItem `json:"films"`
+	TotalCount int32            `json:"total_count"`
+	Page       int32            `json:"page"`
+	PageSize   int32            `json:"page_size"`
+}
+
+type filmSuggestionItem struct {
+	ID        int32  `json:"id"`
+	Title     string `json:"title"`
+	Highlight string `json:"highlight"`
+}
+
+type suggestResponse struct {
+	Films  []filmSuggestionItem `json:"films"`
+	Actors []actorItem          `json:"actors"`
+}
+
+type facetItem struct {
+	ID    int32  `json:"id,omitempty"`
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+type filmFacetsResponse struct {
+	Ratings    []facetItem `json:"ratings"`
+	Categories []facetItem `json:"categories"`
+	Languages  []facetItem `json:"languages"`
 }
 
 // filmsToListItems converts proto films to JSON list items.
 func filmsToListItems(films []*filmv1.Film) []filmListItem {
 	items := make([]filmListItem, len(films))
 	for i, f := range films {
-		items[i] = filmListItem{
-			ID:          f.GetFilmId(),
-			Title:       f.GetTitle(),
-			ReleaseYear: f.GetReleaseYear(),
-			RentalRate:  f.GetRentalRate(),
-			Length:      f.GetLength(),
-			Rating:      f.GetRating(),
+		items[i] = filmToListItem(f)
+	}
+	return items
+}
+
+// filmToListItem converts a proto film to a JSON list item.
+func filmToListItem(f *filmv1.Film) filmListItem {
+	return filmListItem{
+		ID:          f.GetFilmId(),
+		Title:       f.GetTitle(),
+		ReleaseYear: f.GetReleaseYear(),
+		RentalRate:  f.GetRentalRate(),
+		Length:      f.GetLength(),
+		Rating:      f.GetRating(),
+		Poster:      filmAssetToImage(f.GetPoster()),
+	}
+}
+
+// actorsToItems converts proto actors to JSON items.
+func actorsToItems(actors []*filmv1.Actor) []actorItem {
+	items := make([]actorItem, len(actors))
+	for i, a := range actors {
+		items[i] = actorItem{
+			ID:        a.GetActorId(),
+			FirstName: a.GetFirstName(),
+			LastName:  a.GetLastName(),
 		}
 	}
 	return items
 }
 
-// ListFilms returns a paginated film list.
+// facetsToResponse converts proto facet counts to JSON.
+func facetsToResponse(f *filmv1.FilmFacets) *filmFacetsResponse {
+	if f == nil {
+		return nil
+	}
+	items := func(counts []*filmv1.FacetCount) []facetItem {
+		out := make([]facetItem, len(counts))
+		for i, c := range counts {
+			out[i] = facetItem{ID: c.GetId(), Value: c.GetValue(), Count: c.GetCount()}
+		}
+		return out
+	}
+	return &filmFacetsResponse{
+		Ratings:    items(f.GetRatings()),
+		Categories: items(f.GetCategories()),
+		Languages:  items(f.GetLanguages()),
+	}
+}
+
+// ListFilms returns a paginated film list. Filters can be combined:
+// rating (repeatable), category_id, actor_id, language_id, min_year,
+// max_year, min_length, max_length, min_rate, max_rate and feature
+// (repeatable, all must match). sort is title, release_year, rental_rate,
+// popularity or rating (average review rating), with order=desc to reverse
+// it. facets=true adds the counts per
+// rating, category and language for filter sidebars.
 func (h *FilmHandler) ListFilms(w http.ResponseWriter, r *http.Request) {
 	pageSize, page := parsePagination(r)
 
+	req, err := parseFilmBrowseQuery(r)
+	if err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+	req.PageSize = pageSize
+	req.Page = page
+
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
-	resp, err := h.filmClient.ListFilms(ctx, &filmv1.ListFilmsRequest{
-		PageSize: pageSize,
-		Page:     page,
-	})
+	resp, err := h.filmClient.ListFilms(ctx, req)
 	if err != nil {
 		grpcToHTTPError(w, err)
 		return
@@ -112,9 +247,70 @@ func (h *FilmHandler) ListFilms(w http.ResponseWriter, r *http.Request) {
 		TotalCount: resp.GetTotalCount(),
 		Page:       page,
 		PageSize:   pageSize,
+		Facets:     facetsToResponse(resp.GetFacets()),
 	})
 }
 
+// parseFilmBrowseQuery reads the film browsing filters and sort order from
+// the query string. Repeatable parameters also accept comma-separated values.
+func parseFilmBrowseQuery(r *http.Request) (*filmv1.ListFilmsRequest, error) {
+	q := r.URL.Query()
+	req := &filmv1.ListFilmsRequest{
+		Ratings:         splitQueryValues(q["rating"]),
+		MinRentalRate:   q.Get("min_rate"),
+		MaxRentalRate:   q.Get("max_rate"),
+		SpecialFeatures: splitQueryValues(q["feature"]),
+		SortBy:          q.Get("sort"),
+		IncludeFacets:   q.Get("facets") == "true",
+	}
+
+	switch q.Get("order") {
+	case "", "asc":
+	case "desc":
+		req.Descending = true
+	default:
+		return nil, fmt.Errorf("invalid order: must be asc or desc")
+	}
+
+	ints := []struct {
+		name string
+		dst  *int32
+	}{
+		{"category_id", &req.CategoryId},
+		{"actor_id", &req.ActorId},
+		{"language_id", &req.LanguageId},
+		{"min_year", &req.MinReleaseYear},
+		{"max_year", &req.MaxReleaseYear},
+		{"min_length", &req.MinLength},
+		{"max_length", &req.MaxLength},
+	}
+	for _, p := range ints {
+		s := q.Get(p.name)
+		if s == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: must be a number", p.name)
+		}
+		*p.dst = int32(n)
+	}
+	return req, nil
+}
+
+// splitQueryValues flattens repeated and comma-separated query values.
+func splitQueryValues(values []string) []string {
+	var out []string
+	for _, v := range values {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+	}
+	return out
+}
+
 // GetFilm returns detailed film information.
 func (h *FilmHandler) GetFilm(w http.ResponseWriter, r *http.Request) {
 	id, err := parseID(r, "id")
@@ -151,6 +347,11 @@ func (h *FilmHandler) GetFilm(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	stills := make([]imageItem, len(detail.GetStills()))
+	for i, a := range detail.GetStills() {
+		stills[i] = *filmAssetToImage(a)
+	}
+
 	middleware.WriteJSON(w, http.StatusOK, filmDetailResponse{
 		ID:               f.GetFilmId(),
 		Title:            f.GetTitle(),
@@ -166,10 +367,16 @@ func (h *FilmHandler) GetFilm(w http.ResponseWriter, r *http.Request) {
 		OriginalLanguage: detail.GetOriginalLanguageName(),
 		Actors:           actors,
 		Categories:       categories,
+		AverageRating:    detail.GetAverageRating(),
+		ReviewCount:      detail.GetReviewCount(),
+		Poster:           filmAssetToImage(f.GetPoster()),
+		Stills:           stills,
 	})
 }
 
-// SearchFilms searches films by query string.
+// SearchFilms searches film titles and descriptions, matching words by
+// prefix. When nothing matches, titles similar to q are returned and marked
+// fuzzy. Matched words are wrapped in <b></b> in the highlights.
 func (h *FilmHandler) SearchFilms(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query().Get("q")
 	if q == "" {
@@ -191,6 +398,167 @@ func (h *FilmHandler) SearchFilms(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	films := make([]filmSearchItem, len(resp.GetHits()))
+	for i, hit := range resp.GetHits() {
+		films[i] = filmSearchItem{
+			filmListItem:         filmToListItem(hit.GetFilm()),
+			TitleHighlight:       hit.GetTitleHighlight(),
+			DescriptionHighlight: hit.GetDescriptionHighlight(),
+			Fuzzy:                hit.GetFuzzy(),
+		}
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, filmSearchResponse{
+		Films:      films,
+		TotalCount: resp.GetTotalCount(),
+		Page:       page,
+		PageSize:   pageSize,
+	})
+}
+
+const (
+	defaultSuggestLimit = 10
+	maxSuggestLimit     = 20
+)
+
+// Suggest returns film titles and actors for a partially typed search, for
+// search-as-you-type. limit applies to films and actors separately.
+func (h *FilmHandler) Suggest(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query().Get("q")
+	if q == "" {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "query parameter 'q' is required")
+		return
+	}
+	limit := int32(defaultSuggestLimit)
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 32)
+		if err != nil || n <= 0 || n > maxSuggestLimit {
+			middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST",
+				fmt.Sprintf("limit must be between 1 and %d", maxSuggestLimit))
+			return
+		}
+		limit = int32(n)
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	films, err := h.filmClient.SuggestFilms(ctx, &filmv1.SuggestFilmsRequest{
+		Query: q,
+		Limit: limit,
+	})
+	if err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	actors, err := h.actorClient.SearchActors(ctx, &filmv1.SearchActorsRequest{
+		Query:    q,
+		PageSize: limit,
+		Page:     1,
+	})
+	if err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	out := suggestResponse{
+		Films:  make([]filmSuggestionItem, len(films.GetSuggestions())),
+		Actors: actorsToItems(actors.GetActors()),
+	}
+	for i, f := range films.GetSuggestions() {
+		out.Films[i] = filmSuggestionItem{
+			ID:        f.GetFilmId(),
+			Title:     f.GetTitle(),
+			Highlight: f.GetHighlight(),
+		}
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, out)
+}
+
+// ListTrendingFilms returns the films rented in the last 7 days, those
+// rented most above their usual rate first. An optional store_id limits it
+// to one store's rentals.
+func (h *FilmHandler) ListTrendingFilms(w http.ResponseWriter, r *http.Request) {
+	storeID, err := parseQueryInt32(r, "store_id")
+	if err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+	pageSize, page := parsePagination(r)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.filmClient.ListTrendingFilms(ctx, &filmv1.ListTrendingFilmsRequest{
+		StoreId:  storeID,
+		PageSize: pageSize,
+		Page:     page,
+	})
+	if err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, popularFilmsToResponse(resp, pageSize, page))
+}
+
+// ListPopularFilms returns the films most rented over the last window days
+// (7, 30 or 90; default 30), optionally at one store.
+func (h *FilmHandler) ListPopularFilms(w http.ResponseWriter, r *http.Request) {
+	storeID, err := parseQueryInt32(r, "store_id")
+	if err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+	window, err := parseQueryInt32(r, "window")
+	if err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+	pageSize, page := parsePagination(r)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.filmClient.ListPopularFilms(ctx, &filmv1.ListPopularFilmsRequest{
+		StoreId:    storeID,
+		WindowDays: window,
+		PageSize:   pageSize,
+		Page:       page,
+	})
+	if err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, popularFilmsToResponse(resp, pageSize, page))
+}
+
+// ListNewReleases returns films newest first, optionally only those one
+// store stocks.
+func (h *FilmHandler) ListNewReleases(w http.ResponseWriter, r *http.Request) {
+	storeID, err := parseQueryInt32(r, "store_id")
+	if err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+	pageSize, page := parsePagination(r)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.filmClient.ListNewReleases(ctx, &filmv1.ListNewReleasesRequest{
+		StoreId:  storeID,
+		PageSize: pageSize,
+		Page:     page,
+	})
+	if err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
 	middleware.WriteJSON(w, http.StatusOK, filmListResponse{
 		Films:      filmsToListItems(resp.GetFilms()),
 		TotalCount: resp.GetTotalCount(),
@@ -199,6 +567,22 @@ func (h *FilmHandler) SearchFilms(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func popularFilmsToResponse(resp *filmv1.ListPopularFilmsResponse, pageSize, page int32) popularFilmListResponse {
+	films := make([]popularFilmItem, len(resp.GetFilms()))
+	for i, f := range resp.GetFilms() {
+		films[i] = popularFilmItem{
+			filmListItem: filmToListItem(f.GetFilm()),
+			Rentals:      f.GetRentals(),
+		}
+	}
+	return popularFilmListResponse{
+		Films:      films,
+		TotalCount: resp.GetTotalCount(),
+		Page:       page,
+		PageSize:   pageSize,
+	}
+}
+
 // ListFilmsByCategory returns films in a specific category.
 func (h *FilmHandler) ListFilmsByCategory(w http.ResponseWriter, r *http.Request) {
 	categoryID, err := parseID(r, "id")
@@ -291,35 +675,108 @@ func (h *FilmHandler) ListCategories(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// ListActors returns a paginated actor list.
+// ListActors returns a paginated actor list. With ?q= it searches actor
+// names instead.
 func (h *FilmHandler) ListActors(w http.ResponseWriter, r *http.Request) {
 	pageSize, page := parsePagination(r)
+	q := r.URL.Query().Get("q")
 
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
-	resp, err := h.actorClient.ListActors(ctx, &filmv1.ListActorsRequest{
-		PageSize: pageSize,
-		Page:     page,
-	})
+	var resp *filmv1.ListActorsResponse
+	var err error
+
+	if q != "" {
+		resp, err = h.actorClient.SearchActors(ctx, &filmv1.SearchActorsRequest{
+			Query:    q,
+			PageSize: pageSize,
+			Page:     page,
+		})
+	} else {
+		resp, err = h.actorClient.ListActors(ctx, &filmv1.ListActorsRequest{
+			PageSize: pageSize,
+			Page:     page,
+		})
+	}
 	if err != nil {
 		grpcToHTTPError(w, err)
 		return
 	}
 
-	actors := make([]actorItem, len(resp.GetActors()))
-	for i, a := range resp.GetActors() {
-		actors[i] = actorItem{
-			ID:        a.GetActorId(),
-			FirstName: a.GetFirstName(),
-			LastName:  a.GetLastName(),
-		}
-	}
-
 	middleware.WriteJSON(w, http.StatusOK, map[string]interface{}{
-		"actors":      actors,
+		"actors":      actorsToItems(resp.GetActors()),
 		"total_count": resp.GetTotalCount(),
 		"page":        page,
 		"page_size":   pageSize,
 	})
 }
+
+// GetActor returns an actor's profile: their films grouped by category,
+// film and rental counts, and the actors they appear with most (co_stars
+// of them, default 10).
+func (h *FilmHandler) GetActor(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+	coStarLimit, err := parseQueryInt32(r, "co_stars")
+	if err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	detail, err := h.actorClient.GetActorDetail(ctx, &filmv1.GetActorDetailRequest{
+		ActorId:     id,
+		CoStarLimit: coStarLimit,
+	})
+	if err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	filmography := make([]actorCategoryItem, len(detail.GetFilmography()))
+	for i, c := range detail.GetFilmography() {
+		films := make([]actorFilmItem, len(c.GetFilms()))
+		for j, f := range c.GetFilms() {
+			films[j] = actorFilmItem{
+				ID:          f.GetFilmId(),
+				Title:       f.GetTitle(),
+				ReleaseYear: f.GetReleaseYear(),
+				Rating:      f.GetRating(),
+			}
+		}
+		filmography[i] = actorCategoryItem{
+			CategoryID: c.GetCategoryId(),
+			Category:   c.GetCategoryName(),
+			Films:      films,
+		}
+	}
+
+	coStars := make([]coStarItem, len(detail.GetCoStars()))
+	for i, c := range detail.GetCoStars() {
+		coStars[i] = coStarItem{
+			actorItem: actorItem{
+				ID:        c.GetActor().GetActorId(),
+				FirstName: c.GetActor().GetFirstName(),
+				LastName:  c.GetActor().GetLastName(),
+			},
+			FilmsTogether: c.GetFilmsTogether(),
+		}
+	}
+
+	a := detail.GetActor()
+	middleware.WriteJSON(w, http.StatusOK, actorDetailResponse{
+		ID:          a.GetActorId(),
+		FirstName:   a.GetFirstName(),
+		LastName:    a.GetLastName(),
+		FilmCount:   detail.GetFilmCount(),
+		RentalCount: detail.GetRentalCount(),
+		Filmography: filmography,
+		CoStars:     coStars,
+	})
+}
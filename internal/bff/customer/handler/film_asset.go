@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	filmv1 "github.com/enkaigaku/dvd-rental/gen/proto/film/v1"
+	"github.com/enkaigaku/dvd-rental/pkg/middleware"
+)
+
+// filmAssetCacheControl lets clients and proxies keep film images for a
+// year: an image never changes once uploaded, a new poster gets a new ID.
+const filmAssetCacheControl = "public, max-age=31536000, immutable"
+
+// FilmAssetHandler serves film posters and stills.
+type FilmAssetHandler struct {
+	assetClient filmv1.FilmAssetServiceClient
+}
+
+// NewFilmAssetHandler creates a new FilmAssetHandler.
+func NewFilmAssetHandler(assetClient filmv1.FilmAssetServiceClient) *FilmAssetHandler {
+	return &FilmAssetHandler{assetClient: assetClient}
+}
+
+// --- JSON models ---
+
+// imageItem is a film image with the URL of each size it is available in
+// (thumb, medium and large as stored, and original).
+type imageItem struct {
+	ID     int32             `json:"id"`
+	Width  int32             `json:"width"`
+	Height int32             `json:"height"`
+	URLs   map[string]string `json:"urls"`
+}
+
+// filmAssetToImage converts a proto film asset to a JSON image, or nil
+// when the film has none.
+func filmAssetToImage(a *filmv1.FilmAsset) *imageItem {
+	if a == nil {
+		return nil
+	}
+	urls := make(map[string]string, len(a.GetSizes()))
+	for _, size := range a.GetSizes() {
+		urls[size] = fmt.Sprintf("/api/v1/film-assets/%d/%s", a.GetAssetId(), size)
+	}
+	return &imageItem{
+		ID:     a.GetAssetId(),
+		Width:  a.GetWidth(),
+		Height: a.GetHeight(),
+		URLs:   urls,
+	}
+}
+
+// GetFilmAsset serves a film image in one size: thumb, medium, large or
+// original. Sizes not stored because the original is no wider are served
+// by the original.
+func (h *FilmAssetHandler) GetFilmAsset(w http.ResponseWriter, r *http.Request) {
+	assetID, err := parseID(r, "id")
+	if err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid asset id")
+		return
+	}
+	size := r.PathValue("size")
+
+	etag := fmt.Sprintf(`"%d-%s"`, assetID, size)
+	if r.Header.Get("If-None-Match") == etag {
+		w.Header().Set("Cache-Control", filmAssetCacheControl)
+		w.Header().Set("ETag", etag)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	stream, err := h.assetClient.GetFilmAssetContent(ctx, &filmv1.GetFilmAssetContentRequest{
+		AssetId: assetID,
+		Size:    size,
+	})
+	if err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	// The service reports a missing asset or invalid size on the first
+	// message, before anything is written.
+	first, err := stream.Recv()
+	if err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", first.GetContentType())
+	w.Header().Set("Cache-Control", filmAssetCacheControl)
+	w.Header().Set("ETag", etag)
+	w.WriteHeader(http.StatusOK)
+
+	for msg := first; ; {
+		if _, err := w.Write(msg.GetChunk()); err != nil {
+			return
+		}
+		// Past the headers, an error other than the end of the stream
+		// can only cut the image short.
+		if msg, err = stream.Recv(); err != nil {
+			return
+		}
+	}
+}
@@ -50,6 +50,20 @@ func parsePagination(r *http.Request) (pageSize, page int32) {
 	return pageSize, page
 }
 
+// parseQueryInt32 parses an optional int32 query parameter, returning 0
+// when it is absent.
+func parseQueryInt32(r *http.Request, name string) (int32, error) {
+	s := r.URL.Query().Get(name)
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: must be a number", name)
+	}
+	return int32(n), nil
+}
+
 // grpcToHTTPError maps a gRPC error to an HTTP JSON error response.
 func grpcToHTTPError(w http.ResponseWriter, err error) {
 	st, ok := status.FromError(err)
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	paymentv1 "github.com/enkaigaku/dvd-rental/gen/proto/payment/v1"
+	"github.com/enkaigaku/dvd-rental/pkg/middleware"
+)
+
+// LoyaltyHandler handles loyalty points endpoints (all require auth).
+type LoyaltyHandler struct {
+	loyaltyClient paymentv1.LoyaltyServiceClient
+}
+
+// NewLoyaltyHandler creates a new LoyaltyHandler.
+func NewLoyaltyHandler(loyaltyClient paymentv1.LoyaltyServiceClient) *LoyaltyHandler {
+	return &LoyaltyHandler{loyaltyClient: loyaltyClient}
+}
+
+// --- JSON models ---
+
+type loyaltyResponse struct {
+	Tier             string `json:"tier"`
+	Points           int32  `json:"points"`
+	LifetimePoints   int32  `json:"lifetime_points"`
+	PointsMultiplier string `json:"points_multiplier"`
+	FreeRentalPoints int32  `json:"free_rental_points"`
+	FreeRentals      int32  `json:"free_rentals_available"`
+}
+
+type loyaltyTransactionItem struct {
+	ID           int32  `json:"id"`
+	Kind         string `json:"kind"`
+	Points       int32  `json:"points"`
+	BalanceAfter int32  `json:"balance_after"`
+	RentalID     int32  `json:"rental_id,omitempty"`
+	Note         string `json:"note,omitempty"`
+	CreatedAt    string `json:"created_at"`
+}
+
+type loyaltyTransactionListResponse struct {
+	Transactions []loyaltyTransactionItem `json:"transactions"`
+	TotalCount   int32                    `json:"total_count"`
+	Page         int32                    `json:"page"`
+	PageSize     int32                    `json:"page_size"`
+}
+
+// GetLoyalty returns the authenticated customer's points and tier.
+func (h *LoyaltyHandler) GetLoyalty(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r.Context())
+	if claims == nil {
+		middleware.WriteJSONError(w, http.StatusUnauthorized, "UNAUTHORIZED", "authentication required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	account, err := h.loyaltyClient.GetLoyaltyAccount(ctx, &paymentv1.GetLoyaltyAccountRequest{
+		CustomerId: claims.UserID,
+	})
+	if err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	resp := loyaltyResponse{
+		Tier:             account.GetTier(),
+		Points:           account.GetPointsBalance(),
+		LifetimePoints:   account.GetLifetimePoints(),
+		PointsMultiplier: account.GetPointsMultiplier(),
+		FreeRentalPoints: account.GetFreeRentalPoints(),
+	}
+	if cost := account.GetFreeRentalPoints(); cost > 0 {
+		resp.FreeRentals = account.GetPointsBalance() / cost
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, resp)
+}
+
+// ListLoyaltyTransactions returns the authenticated customer's points history.
+func (h *LoyaltyHandler) ListLoyaltyTransactions(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r.Context())
+	if claims == nil {
+		middleware.WriteJSONError(w, http.StatusUnauthorized, "UNAUTHORIZED", "authentication required")
+		return
+	}
+
+	pageSize, page := parsePagination(r)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.loyaltyClient.ListLoyaltyTransactions(ctx, &paymentv1.ListLoyaltyTransactionsRequest{
+		CustomerId: claims.UserID,
+		PageSize:   pageSize,
+		Page:       page,
+	})
+	if err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	transactions := make([]loyaltyTransactionItem, len(resp.GetTransactions()))
+	for i, t := range resp.GetTransactions() {
+		transactions[i] = loyaltyTransactionItem{
+			ID:           t.GetTransactionId(),
+			Kind:         t.GetKind(),
+			Points:       t.GetPoints(),
+			BalanceAfter: t.GetBalanceAfter(),
+			RentalID:     t.GetRentalId(),
+			Note:         t.GetNote(),
+			CreatedAt:    timestampToString(t.GetCreatedAt()),
+		}
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, loyaltyTransactionListResponse{
+		Transactions: transactions,
+		TotalCount:   resp.GetTotalCount(),
+		Page:         page,
+		PageSize:     pageSize,
+	})
+}
@@ -9,14 +9,22 @@ import (
 	"github.com/enkaigaku/dvd-rental/pkg/middleware"
 )
 
-// PaymentHandler handles payment endpoints (all require auth).
+// PaymentHandler handles payment, gift card and store credit endpoints
+// (all require auth).
 type PaymentHandler struct {
-	paymentClient paymentv1.PaymentServiceClient
+	paymentClient     paymentv1.PaymentServiceClient
+	storedValueClient paymentv1.StoredValueServiceClient
 }
 
 // NewPaymentHandler creates a new PaymentHandler.
-func NewPaymentHandler(paymentClient paymentv1.PaymentServiceClient) *PaymentHandler {
-	return &PaymentHandler{paymentClient: paymentClient}
+func NewPaymentHandler(
+	paymentClient paymentv1.PaymentServiceClient,
+	storedValueClient paymentv1.StoredValueServiceClient,
+) *PaymentHandler {
+	return &PaymentHandler{
+		paymentClient:     paymentClient,
+		storedValueClient: storedValueClient,
+	}
 }
 
 // --- JSON models ---
@@ -24,10 +32,29 @@ func NewPaymentHandler(paymentClient paymentv1.PaymentServiceClient) *PaymentHan
 type paymentItem struct {
 	ID          int32  `json:"id"`
 	RentalID    int32  `json:"rental_id"`
+	ChargeType  string `json:"charge_type"`
+	NetAmount   string `json:"net_amount"`
+	TaxAmount   string `json:"tax_amount"`
 	Amount      string `json:"amount"`
 	PaymentDate string `json:"payment_date"`
 }
 
+type taxLineItem struct {
+	Name   string `json:"name"`
+	Rate   string `json:"rate"`
+	Amount string `json:"amount"`
+}
+
+type receiptResponse struct {
+	paymentItem
+	StoreAddress string        `json:"store_address"`
+	StoreCity    string        `json:"store_city"`
+	StoreCountry string        `json:"store_country"`
+	FilmTitle    string        `json:"film_title"`
+	RentalDate   string        `json:"rental_date,omitempty"`
+	Taxes        []taxLineItem `json:"taxes"`
+}
+
 type paymentListResponse struct {
 	Payments   []paymentItem `json:"payments"`
 	TotalCount int32         `json:"total_count"`
@@ -35,6 +62,45 @@ type paymentListResponse struct {
 	PageSize   int32         `json:"page_size"`
 }
 
+type createPaymentRequest struct {
+	RentalID     int32  `json:"rental_id"`
+	PromoCode    string `json:"promo_code"`
+	GiftCardCode string `json:"gift_card_code"`
+	RedeemPoints bool   `json:"redeem_points"`
+}
+
+type storedValueItem struct {
+	Kind    string `json:"kind"`
+	Code    string `json:"code"`
+	Balance string `json:"balance"`
+	Active  bool   `json:"active"`
+}
+
+type storedValueListResponse struct {
+	Accounts []storedValueItem `json:"accounts"`
+}
+
+func paymentToItem(p *paymentv1.Payment) paymentItem {
+	return paymentItem{
+		ID:          p.GetPaymentId(),
+		RentalID:    p.GetRentalId(),
+		ChargeType:  p.GetChargeType(),
+		NetAmount:   p.GetNetAmount(),
+		TaxAmount:   p.GetTaxAmount(),
+		Amount:      p.GetAmount(),
+		PaymentDate: timestampToString(p.GetPaymentDate()),
+	}
+}
+
+func storedValueToItem(a *paymentv1.StoredValueAccount) storedValueItem {
+	return storedValueItem{
+		Kind:    a.GetKind(),
+		Code:    a.GetCode(),
+		Balance: a.GetBalance(),
+		Active:  a.GetActive(),
+	}
+}
+
 // ListPayments returns the authenticated customer's payment history.
 func (h *PaymentHandler) ListPayments(w http.ResponseWriter, r *http.Request) {
 	claims := middleware.GetClaims(r.Context())
@@ -60,12 +126,7 @@ func (h *PaymentHandler) ListPayments(w http.ResponseWriter, r *http.Request) {
 
 	payments := make([]paymentItem, len(resp.GetPayments()))
 	for i, p := range resp.GetPayments() {
-		payments[i] = paymentItem{
-			ID:          p.GetPaymentId(),
-			RentalID:    p.GetRentalId(),
-			Amount:      p.GetAmount(),
-			PaymentDate: timestampToString(p.GetPaymentDate()),
-		}
+		payments[i] = paymentToItem(p)
 	}
 
 	middleware.WriteJSON(w, http.StatusOK, paymentListResponse{
@@ -75,3 +136,152 @@ func (h *PaymentHandler) ListPayments(w http.ResponseWriter, r *http.Request) {
 		PageSize:   pageSize,
 	})
 }
+
+// CreatePayment pays for one of the authenticated customer's rentals. The
+// amount is priced by the payment service, optionally applying a promo code,
+// and can be charged to a gift card or the customer's store credit, or the
+// rental can be taken free in exchange for loyalty points.
+func (h *PaymentHandler) CreatePayment(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r.Context())
+	if claims == nil {
+		middleware.WriteJSONError(w, http.StatusUnauthorized, "UNAUTHORIZED", "authentication required")
+		return
+	}
+
+	var req createPaymentRequest
+	if err := readJSON(r, &req); err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+	if req.RentalID == 0 {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "rental_id is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	// No staff member takes a self-service payment; it is recorded against
+	// the rental store's manager.
+	payment, err := h.paymentClient.CreatePayment(ctx, &paymentv1.CreatePaymentRequest{
+		CustomerId:   claims.UserID,
+		RentalId:     req.RentalID,
+		PromoCode:    req.PromoCode,
+		GiftCardCode: req.GiftCardCode,
+		RedeemPoints: req.RedeemPoints,
+		SelfService:  true,
+	})
+	if err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusCreated, paymentToItem(payment))
+}
+
+// GetReceipt returns the receipt for one of the authenticated customer's
+// payments, with the sales tax charged.
+func (h *PaymentHandler) GetReceipt(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r.Context())
+	if claims == nil {
+		middleware.WriteJSONError(w, http.StatusUnauthorized, "UNAUTHORIZED", "authentication required")
+		return
+	}
+
+	paymentID, err := parseID(r, "id")
+	if err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	receipt, err := h.paymentClient.GetPaymentReceipt(ctx, &paymentv1.GetPaymentReceiptRequest{
+		PaymentId: paymentID,
+	})
+	if err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	payment := receipt.GetDetail().GetPayment()
+	if payment.GetCustomerId() != claims.UserID {
+		middleware.WriteJSONError(w, http.StatusForbidden, "FORBIDDEN", "you can only access your own payments")
+		return
+	}
+
+	taxes := make([]taxLineItem, len(receipt.GetTaxes()))
+	for i, t := range receipt.GetTaxes() {
+		taxes[i] = taxLineItem{
+			Name:   t.GetName(),
+			Rate:   t.GetRate(),
+			Amount: t.GetAmount(),
+		}
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, receiptResponse{
+		paymentItem:  paymentToItem(payment),
+		StoreAddress: receipt.GetStoreAddress(),
+		StoreCity:    receipt.GetStoreCity(),
+		StoreCountry: receipt.GetStoreCountry(),
+		FilmTitle:    receipt.GetFilmTitle(),
+		RentalDate:   timestampToString(receipt.GetDetail().GetRentalDate()),
+		Taxes:        taxes,
+	})
+}
+
+// GetGiftCard returns the balance of a gift card. Store credit of other
+// customers is reported as not found.
+func (h *PaymentHandler) GetGiftCard(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r.Context())
+	if claims == nil {
+		middleware.WriteJSONError(w, http.StatusUnauthorized, "UNAUTHORIZED", "authentication required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	account, err := h.storedValueClient.GetStoredValueAccount(ctx, &paymentv1.GetStoredValueAccountRequest{
+		Code: r.PathValue("code"),
+	})
+	if err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	if account.GetKind() == "store_credit" && account.GetCustomerId() != claims.UserID {
+		middleware.WriteJSONError(w, http.StatusNotFound, "NOT_FOUND", "gift card not found")
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, storedValueToItem(account))
+}
+
+// ListStoredValue returns the authenticated customer's gift cards and store credit.
+func (h *PaymentHandler) ListStoredValue(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r.Context())
+	if claims == nil {
+		middleware.WriteJSONError(w, http.StatusUnauthorized, "UNAUTHORIZED", "authentication required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.storedValueClient.ListStoredValueAccountsByCustomer(ctx, &paymentv1.ListStoredValueAccountsByCustomerRequest{
+		CustomerId: claims.UserID,
+	})
+	if err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	accounts := make([]storedValueItem, len(resp.GetAccounts()))
+	for i, a := range resp.GetAccounts() {
+		accounts[i] = storedValueToItem(a)
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, storedValueListResponse{Accounts: accounts})
+}
@@ -2,39 +2,174 @@ package handler
 
 import (
 	"context"
+	"log"
 	"net/http"
 	"time"
 
 	customerv1 "github.com/enkaigaku/dvd-rental/gen/proto/customer/v1"
+	"github.com/enkaigaku/dvd-rental/pkg/auth"
 	"github.com/enkaigaku/dvd-rental/pkg/middleware"
+	"github.com/enkaigaku/dvd-rental/pkg/notify"
 )
 
 // ProfileHandler handles customer profile endpoints (all require auth).
 type ProfileHandler struct {
 	customerClient customerv1.CustomerServiceClient
+	verifier       *emailVerifier
 }
 
-// NewProfileHandler creates a new ProfileHandler.
-func NewProfileHandler(customerClient customerv1.CustomerServiceClient) *ProfileHandler {
-	return &ProfileHandler{customerClient: customerClient}
+// NewProfileHandler creates a new ProfileHandler. Email verification emails
+// link to verifyURL with the token as the token query parameter.
+func NewProfileHandler(
+	customerClient customerv1.CustomerServiceClient,
+	verifyStore *auth.EmailVerificationStore,
+	notifier notify.Notifier,
+	verifyURL string,
+) *ProfileHandler {
+	return &ProfileHandler{
+		customerClient: customerClient,
+		verifier:       newEmailVerifier(verifyStore, notifier, verifyURL),
+	}
 }
 
 // --- JSON models ---
 
 type profileResponse struct {
-	ID         int32  `json:"id"`
+	ID            int32  `json:"id"`
+	StoreID       int32  `json:"store_id"`
+	FirstName     string `json:"first_name"`
+	LastName      string `json:"last_name"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	PendingEmail  string `json:"pending_email,omitempty"`
+	Active        bool   `json:"active"`
+	CreateDate    string `json:"create_date"`
+	Address       string `json:"address,omitempty"`
+	Address2      string `json:"address2,omitempty"`
+	District      string `json:"district,omitempty"`
+	City          string `json:"city,omitempty"`
+	Country       string `json:"country,omitempty"`
+	PostalCode    string `json:"postal_code,omitempty"`
+	Phone         string `json:"phone,omitempty"`
+}
+
+type dataExportResponse struct {
+	ExportedAt    string             `json:"exported_at"`
+	Profile       profileResponse    `json:"profile"`
+	Rentals       []rentalRecord     `json:"rentals"`
+	Payments      []paymentRecord    `json:"payments"`
+	Reviews       []reviewRecord     `json:"reviews"`
+	Wishlist      []wishlistRecord   `json:"wishlist"`
+	Loyalty       loyaltyExport      `json:"loyalty"`
+	StoredValue   storedValueExport  `json:"stored_value"`
+	Subscriptions subscriptionExport `json:"subscriptions"`
+}
+
+type rentalRecord struct {
+	RentalID   int32  `json:"rental_id"`
+	RentalDate string `json:"rental_date"`
+	ReturnDate string `json:"return_date,omitempty"`
 	StoreID    int32  `json:"store_id"`
-	FirstName  string `json:"first_name"`
-	LastName   string `json:"last_name"`
-	Email      string `json:"email"`
+	FilmID     int32  `json:"film_id"`
+	FilmTitle  string `json:"film_title"`
+}
+
+type paymentRecord struct {
+	PaymentID   int32  `json:"payment_id"`
+	RentalID    int32  `json:"rental_id"`
+	Amount      string `json:"amount"`
+	NetAmount   string `json:"net_amount"`
+	TaxAmount   string `json:"tax_amount"`
+	ChargeType  string `json:"charge_type"`
+	PaymentDate string `json:"payment_date"`
+}
+
+type reviewRecord struct {
+	ReviewID  int32  `json:"review_id"`
+	FilmID    int32  `json:"film_id"`
+	FilmTitle string `json:"film_title"`
+	Rating    int32  `json:"rating"`
+	Body      string `json:"body"`
+	Status    string `json:"status"`
+	CreatedAt string `json:"created_at"`
+}
+
+type wishlistRecord struct {
+	FilmID    int32  `json:"film_id"`
+	FilmTitle string `json:"film_title"`
+	AddedAt   string `json:"added_at"`
+}
+
+type loyaltyExport struct {
+	Account      *loyaltyAccountRecord      `json:"account"` // null without a loyalty account
+	Transactions []loyaltyTransactionRecord `json:"transactions"`
+}
+
+type loyaltyAccountRecord struct {
+	Tier           string `json:"tier"`
+	PointsBalance  int32  `json:"points_balance"`
+	LifetimePoints int32  `json:"lifetime_points"`
+}
+
+type loyaltyTransactionRecord struct {
+	TransactionID int32  `json:"transaction_id"`
+	Kind          string `json:"kind"`
+	Points        int32  `json:"points"`
+	BalanceAfter  int32  `json:"balance_after"`
+	PaymentID     int32  `json:"payment_id,omitempty"`
+	RentalID      int32  `json:"rental_id,omitempty"`
+	Note          string `json:"note,omitempty"`
+	CreatedAt     string `json:"created_at"`
+}
+
+type storedValueExport struct {
+	Accounts []storedValueAccountRecord `json:"accounts"`
+	Entries  []storedValueEntryRecord   `json:"entries"`
+}
+
+type storedValueAccountRecord struct {
+	AccountID  int32  `json:"account_id"`
+	Kind       string `json:"kind"`
+	Code       string `json:"code"`
+	Balance    string `json:"balance"`
 	Active     bool   `json:"active"`
 	CreateDate string `json:"create_date"`
-	Address    string `json:"address,omitempty"`
-	District   string `json:"district,omitempty"`
-	City       string `json:"city,omitempty"`
-	Country    string `json:"country,omitempty"`
-	PostalCode string `json:"postal_code,omitempty"`
-	Phone      string `json:"phone,omitempty"`
+}
+
+type storedValueEntryRecord struct {
+	EntryID      int32  `json:"entry_id"`
+	AccountID    int32  `json:"account_id"`
+	Kind         string `json:"kind"`
+	Amount       string `json:"amount"`
+	BalanceAfter string `json:"balance_after"`
+	PaymentID    int32  `json:"payment_id,omitempty"`
+	Note         string `json:"note,omitempty"`
+	CreatedAt    string `json:"created_at"`
+}
+
+type subscriptionExport struct {
+	Subscriptions []subscriptionRecord        `json:"subscriptions"`
+	Payments      []subscriptionPaymentRecord `json:"payments"`
+}
+
+type subscriptionRecord struct {
+	SubscriptionID     int32  `json:"subscription_id"`
+	PlanName           string `json:"plan_name"`
+	Status             string `json:"status"`
+	CurrentPeriodStart string `json:"current_period_start"`
+	CurrentPeriodEnd   string `json:"current_period_end"`
+	CreateDate         string `json:"create_date"`
+	CanceledAt         string `json:"canceled_at,omitempty"`
+}
+
+type subscriptionPaymentRecord struct {
+	SubscriptionPaymentID int32  `json:"subscription_payment_id"`
+	SubscriptionID        int32  `json:"subscription_id"`
+	Amount                string `json:"amount"`
+	PeriodStart           string `json:"period_start"`
+	PeriodEnd             string `json:"period_end"`
+	Status                string `json:"status"`
+	CreatedAt             string `json:"created_at"`
 }
 
 type updateProfileRequest struct {
@@ -43,6 +178,20 @@ type updateProfileRequest struct {
 	Email     string `json:"email"`
 }
 
+type updateAddressRequest struct {
+	Address    string `json:"address"`
+	Address2   string `json:"address2"`
+	District   string `json:"district"`
+	CityID     int32  `json:"city_id"`
+	PostalCode string `json:"postal_code"`
+	Phone      string `json:"phone"`
+}
+
+type changePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
 // GetProfile returns the authenticated customer's profile.
 func (h *ProfileHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 	claims := middleware.GetClaims(r.Context())
@@ -62,26 +211,13 @@ func (h *ProfileHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	c := detail.GetCustomer()
-	middleware.WriteJSON(w, http.StatusOK, profileResponse{
-		ID:         c.GetCustomerId(),
-		StoreID:    c.GetStoreId(),
-		FirstName:  c.GetFirstName(),
-		LastName:   c.GetLastName(),
-		Email:      c.GetEmail(),
-		Active:     c.GetActive(),
-		CreateDate: timestampToString(c.GetCreateDate()),
-		Address:    detail.GetAddress(),
-		District:   detail.GetDistrict(),
-		City:       detail.GetCity(),
-		Country:    detail.GetCountry(),
-		PostalCode: detail.GetPostalCode(),
-		Phone:      detail.GetPhone(),
-	})
+	middleware.WriteJSON(w, http.StatusOK, detailToProfile(detail))
 }
 
 // UpdateProfile updates the authenticated customer's profile.
 // Uses read-modify-write since UpdateCustomerRequest requires all fields.
+// A new email does not take effect until it is verified: it is kept as the
+// pending email and a verification link is sent to it.
 func (h *ProfileHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 	claims := middleware.GetClaims(r.Context())
 	if claims == nil {
@@ -119,7 +255,7 @@ func (h *ProfileHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 		StoreId:    current.GetStoreId(),
 		FirstName:  req.FirstName,
 		LastName:   req.LastName,
-		Email:      req.Email,
+		Email:      current.GetEmail(),
 		AddressId:  current.GetAddressId(),
 		Active:     current.GetActive(),
 	})
@@ -128,12 +264,398 @@ func (h *ProfileHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Request the email change, or cancel a pending one if the current
+	// email was submitted.
+	pending := req.Email
+	if pending == current.GetEmail() {
+		pending = ""
+	}
+	if pending != current.GetPendingEmail() {
+		updated, err = h.customerClient.RequestCustomerEmailChange(ctx, &customerv1.RequestCustomerEmailChangeRequest{
+			CustomerId: claims.UserID,
+			Email:      req.Email,
+		})
+		if err != nil {
+			grpcToHTTPError(w, err)
+			return
+		}
+		if pending != "" {
+			if err := h.verifier.send(ctx, claims.UserID, updated.GetFirstName(), pending); err != nil {
+				log.Printf("email verification for customer %d: %v", claims.UserID, err)
+			}
+		}
+	}
+
 	middleware.WriteJSON(w, http.StatusOK, profileResponse{
-		ID:        updated.GetCustomerId(),
-		StoreID:   updated.GetStoreId(),
-		FirstName: updated.GetFirstName(),
-		LastName:  updated.GetLastName(),
-		Email:     updated.GetEmail(),
-		Active:    updated.GetActive(),
+		ID:            updated.GetCustomerId(),
+		StoreID:       updated.GetStoreId(),
+		FirstName:     updated.GetFirstName(),
+		LastName:      updated.GetLastName(),
+		Email:         updated.GetEmail(),
+		EmailVerified: updated.GetEmailVerifiedAt() != nil,
+		PendingEmail:  updated.GetPendingEmail(),
+		Active:        updated.GetActive(),
+	})
+}
+
+// ResendEmailVerification sends a new verification link for the
+// authenticated customer's pending email, or for their current email if it
+// has not been verified. Links sent before stop working.
+func (h *ProfileHandler) ResendEmailVerification(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r.Context())
+	if claims == nil {
+		middleware.WriteJSONError(w, http.StatusUnauthorized, "UNAUTHORIZED", "authentication required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	detail, err := h.customerClient.GetCustomer(ctx, &customerv1.GetCustomerRequest{
+		CustomerId: claims.UserID,
 	})
+	if err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	c := detail.GetCustomer()
+	email := c.GetPendingEmail()
+	if email == "" {
+		if c.GetEmailVerifiedAt() != nil {
+			middleware.WriteJSONError(w, http.StatusConflict, "ALREADY_VERIFIED", "email is already verified")
+			return
+		}
+		email = c.GetEmail()
+	}
+
+	if err := h.verifier.send(ctx, claims.UserID, c.GetFirstName(), email); err != nil {
+		middleware.WriteJSONError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to send verification email")
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// UpdateAddress sets the authenticated customer's address and phone in one
+// customer service call. The address is edited in place when only this
+// customer uses it; otherwise the customer is moved to a new address so
+// that whoever shares the old one is unaffected.
+func (h *ProfileHandler) UpdateAddress(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r.Context())
+	if claims == nil {
+		middleware.WriteJSONError(w, http.StatusUnauthorized, "UNAUTHORIZED", "authentication required")
+		return
+	}
+
+	var req updateAddressRequest
+	if err := readJSON(r, &req); err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+	if req.Address == "" || req.District == "" || req.CityID <= 0 || req.Phone == "" {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "address, district, city_id, and phone are required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	if _, err := h.customerClient.ChangeCustomerAddress(ctx, &customerv1.ChangeCustomerAddressRequest{
+		CustomerId: claims.UserID,
+		Address:    req.Address,
+		Address2:   req.Address2,
+		District:   req.District,
+		CityId:     req.CityID,
+		PostalCode: req.PostalCode,
+		Phone:      req.Phone,
+	}); err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	// Re-read for the city and country names.
+	detail, err := h.customerClient.GetCustomer(ctx, &customerv1.GetCustomerRequest{
+		CustomerId: claims.UserID,
+	})
+	if err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, detailToProfile(detail))
+}
+
+// ChangePassword sets the authenticated customer's password after checking
+// their current one.
+func (h *ProfileHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r.Context())
+	if claims == nil {
+		middleware.WriteJSONError(w, http.StatusUnauthorized, "UNAUTHORIZED", "authentication required")
+		return
+	}
+
+	var req changePasswordRequest
+	if err := readJSON(r, &req); err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+	if req.CurrentPassword == "" || req.NewPassword == "" {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "current_password and new_password are required")
+		return
+	}
+	hash, err := hashNewPassword(req.NewPassword)
+	if err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	// The password hash is only returned by email lookup, and the email in
+	// the token may be out of date.
+	detail, err := h.customerClient.GetCustomer(ctx, &customerv1.GetCustomerRequest{
+		CustomerId: claims.UserID,
+	})
+	if err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+	customer, err := h.customerClient.GetCustomerByEmail(ctx, &customerv1.GetCustomerByEmailRequest{
+		Email: detail.GetCustomer().GetEmail(),
+	})
+	if err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+	if err := auth.ComparePassword(customer.GetPasswordHash(), req.CurrentPassword); err != nil {
+		middleware.WriteJSONError(w, http.StatusForbidden, "INVALID_CREDENTIALS", "current password is incorrect")
+		return
+	}
+
+	if _, err := h.customerClient.UpdateCustomerPassword(ctx, &customerv1.UpdateCustomerPasswordRequest{
+		CustomerId:   claims.UserID,
+		PasswordHash: hash,
+	}); err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func detailToProfile(detail *customerv1.CustomerDetail) profileResponse {
+	c := detail.GetCustomer()
+	return profileResponse{
+		ID:            c.GetCustomerId(),
+		StoreID:       c.GetStoreId(),
+		FirstName:     c.GetFirstName(),
+		LastName:      c.GetLastName(),
+		Email:         c.GetEmail(),
+		EmailVerified: c.GetEmailVerifiedAt() != nil,
+		PendingEmail:  c.GetPendingEmail(),
+		Active:        c.GetActive(),
+		CreateDate:    timestampToString(c.GetCreateDate()),
+		Address:       detail.GetAddress(),
+		Address2:      detail.GetAddress2(),
+		District:      detail.GetDistrict(),
+		City:          detail.GetCity(),
+		Country:       detail.GetCountry(),
+		PostalCode:    detail.GetPostalCode(),
+		Phone:         detail.GetPhone(),
+	}
+}
+
+// ExportData downloads everything held about the authenticated customer:
+// profile, address, rentals, payments, reviews, wishlist, loyalty points,
+// stored value and subscriptions. format=zip returns a ZIP archive with one
+// JSON file each; the default, format=json, a single JSON file.
+func (h *ProfileHandler) ExportData(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r.Context())
+	if claims == nil {
+		middleware.WriteJSONError(w, http.StatusUnauthorized, "UNAUTHORIZED", "authentication required")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "zip" {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "format must be json or zip")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	export, err := h.customerClient.ExportCustomerData(ctx, &customerv1.ExportCustomerDataRequest{
+		CustomerId: claims.UserID,
+	})
+	if err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	resp := dataExportToResponse(export)
+	if format == "zip" {
+		err = middleware.WriteZIPDownload(w, "my-data.zip", []middleware.DownloadFile{
+			{Name: "profile.json", Data: resp.Profile},
+			{Name: "rentals.json", Data: resp.Rentals},
+			{Name: "payments.json", Data: resp.Payments},
+			{Name: "reviews.json", Data: resp.Reviews},
+			{Name: "wishlist.json", Data: resp.Wishlist},
+			{Name: "loyalty.json", Data: resp.Loyalty},
+			{Name: "stored-value.json", Data: resp.StoredValue},
+			{Name: "subscriptions.json", Data: resp.Subscriptions},
+		})
+	} else {
+		err = middleware.WriteJSONDownload(w, "my-data.json", resp)
+	}
+	if err != nil {
+		middleware.WriteJSONError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to build export")
+	}
+}
+
+func dataExportToResponse(e *customerv1.CustomerDataExport) dataExportResponse {
+	rentals := make([]rentalRecord, len(e.GetRentals()))
+	for i, r := range e.GetRentals() {
+		rentals[i] = rentalRecord{
+			RentalID:   r.GetRentalId(),
+			RentalDate: r.GetRentalDate().AsTime().Format(time.RFC3339),
+			StoreID:    r.GetStoreId(),
+			FilmID:     r.GetFilmId(),
+			FilmTitle:  r.GetFilmTitle(),
+		}
+		if r.GetReturnDate() != nil {
+			rentals[i].ReturnDate = r.GetReturnDate().AsTime().Format(time.RFC3339)
+		}
+	}
+
+	payments := make([]paymentRecord, len(e.GetPayments()))
+	for i, p := range e.GetPayments() {
+		payments[i] = paymentRecord{
+			PaymentID:   p.GetPaymentId(),
+			RentalID:    p.GetRentalId(),
+			Amount:      p.GetAmount(),
+			NetAmount:   p.GetNetAmount(),
+			TaxAmount:   p.GetTaxAmount(),
+			ChargeType:  p.GetChargeType(),
+			PaymentDate: p.GetPaymentDate().AsTime().Format(time.RFC3339),
+		}
+	}
+
+	reviews := make([]reviewRecord, len(e.GetReviews()))
+	for i, rv := range e.GetReviews() {
+		reviews[i] = reviewRecord{
+			ReviewID:  rv.GetReviewId(),
+			FilmID:    rv.GetFilmId(),
+			FilmTitle: rv.GetFilmTitle(),
+			Rating:    rv.GetRating(),
+			Body:      rv.GetBody(),
+			Status:    rv.GetStatus(),
+			CreatedAt: rv.GetCreatedAt().AsTime().Format(time.RFC3339),
+		}
+	}
+
+	wishlist := make([]wishlistRecord, len(e.GetWishlist()))
+	for i, w := range e.GetWishlist() {
+		wishlist[i] = wishlistRecord{
+			FilmID:    w.GetFilmId(),
+			FilmTitle: w.GetFilmTitle(),
+			AddedAt:   w.GetAddedAt().AsTime().Format(time.RFC3339),
+		}
+	}
+
+	loyalty := loyaltyExport{
+		Transactions: make([]loyaltyTransactionRecord, len(e.GetLoyaltyTransactions())),
+	}
+	if a := e.GetLoyalty(); a != nil {
+		loyalty.Account = &loyaltyAccountRecord{
+			Tier:           a.GetTier(),
+			PointsBalance:  a.GetPointsBalance(),
+			LifetimePoints: a.GetLifetimePoints(),
+		}
+	}
+	for i, t := range e.GetLoyaltyTransactions() {
+		loyalty.Transactions[i] = loyaltyTransactionRecord{
+			TransactionID: t.GetTransactionId(),
+			Kind:          t.GetKind(),
+			Points:        t.GetPoints(),
+			BalanceAfter:  t.GetBalanceAfter(),
+			PaymentID:     t.GetPaymentId(),
+			RentalID:      t.GetRentalId(),
+			Note:          t.GetNote(),
+			CreatedAt:     t.GetCreatedAt().AsTime().Format(time.RFC3339),
+		}
+	}
+
+	storedValue := storedValueExport{
+		Accounts: make([]storedValueAccountRecord, len(e.GetStoredValue())),
+		Entries:  make([]storedValueEntryRecord, len(e.GetStoredValueEntries())),
+	}
+	for i, a := range e.GetStoredValue() {
+		storedValue.Accounts[i] = storedValueAccountRecord{
+			AccountID:  a.GetAccountId(),
+			Kind:       a.GetKind(),
+			Code:       a.GetCode(),
+			Balance:    a.GetBalance(),
+			Active:     a.GetActive(),
+			CreateDate: a.GetCreateDate().AsTime().Format(time.RFC3339),
+		}
+	}
+	for i, en := range e.GetStoredValueEntries() {
+		storedValue.Entries[i] = storedValueEntryRecord{
+			EntryID:      en.GetEntryId(),
+			AccountID:    en.GetAccountId(),
+			Kind:         en.GetKind(),
+			Amount:       en.GetAmount(),
+			BalanceAfter: en.GetBalanceAfter(),
+			PaymentID:    en.GetPaymentId(),
+			Note:         en.GetNote(),
+			CreatedAt:    en.GetCreatedAt().AsTime().Format(time.RFC3339),
+		}
+	}
+
+	subscriptions := subscriptionExport{
+		Subscriptions: make([]subscriptionRecord, len(e.GetSubscriptions())),
+		Payments:      make([]subscriptionPaymentRecord, len(e.GetSubscriptionPayments())),
+	}
+	for i, s := range e.GetSubscriptions() {
+		subscriptions.Subscriptions[i] = subscriptionRecord{
+			SubscriptionID:     s.GetSubscriptionId(),
+			PlanName:           s.GetPlanName(),
+			Status:             s.GetStatus(),
+			CurrentPeriodStart: s.GetCurrentPeriodStart().AsTime().Format(time.RFC3339),
+			CurrentPeriodEnd:   s.GetCurrentPeriodEnd().AsTime().Format(time.RFC3339),
+			CreateDate:         s.GetCreateDate().AsTime().Format(time.RFC3339),
+		}
+		if s.GetCanceledAt() != nil {
+			subscriptions.Subscriptions[i].CanceledAt = s.GetCanceledAt().AsTime().Format(time.RFC3339)
+		}
+	}
+	for i, p := range e.GetSubscriptionPayments() {
+		subscriptions.Payments[i] = subscriptionPaymentRecord{
+			SubscriptionPaymentID: p.GetSubscriptionPaymentId(),
+			SubscriptionID:        p.GetSubscriptionId(),
+			Amount:                p.GetAmount(),
+			PeriodStart:           p.GetPeriodStart().AsTime().Format(time.RFC3339),
+			PeriodEnd:             p.GetPeriodEnd().AsTime().Format(time.RFC3339),
+			Status:                p.GetStatus(),
+			CreatedAt:             p.GetCreatedAt().AsTime().Format(time.RFC3339),
+		}
+	}
+
+	return dataExportResponse{
+		ExportedAt:    e.GetExportedAt().AsTime().Format(time.RFC3339),
+		Profile:       detailToProfile(e.GetCustomer()),
+		Rentals:       rentals,
+		Payments:      payments,
+		Reviews:       reviews,
+		Wishlist:      wishlist,
+		Loyalty:       loyalty,
+		StoredValue:   storedValue,
+		Subscriptions: subscriptions,
+	}
 }
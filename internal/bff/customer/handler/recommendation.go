@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	filmv1 "github.com/enkaigaku/dvd-rental/gen/proto/film/v1"
+	"github.com/enkaigaku/dvd-rental/pkg/middleware"
+)
+
+const (
+	defaultRecommendationLimit = 10
+	maxRecommendationLimit     = 50
+)
+
+// RecommendationHandler handles "customers who rented this also rented"
+// endpoints.
+type RecommendationHandler struct {
+	recommendationClient filmv1.RecommendationServiceClient
+}
+
+// NewRecommendationHandler creates a new RecommendationHandler.
+func NewRecommendationHandler(recommendationClient filmv1.RecommendationServiceClient) *RecommendationHandler {
+	return &RecommendationHandler{recommendationClient: recommendationClient}
+}
+
+// --- JSON models ---
+
+type recommendationItem struct {
+	filmListItem
+	Score         float64 `json:"score"`
+	BecauseFilmID int32   `json:"because_film_id,omitempty"`
+	BecauseTitle  string  `json:"because_title,omitempty"`
+}
+
+type recommendationListResponse struct {
+	Films []recommendationItem `json:"films"`
+}
+
+func recommendationsToResponse(recs []*filmv1.Recommendation) recommendationListResponse {
+	items := make([]recommendationItem, len(recs))
+	for i, rec := range recs {
+		items[i] = recommendationItem{
+			filmListItem:  filmToListItem(rec.GetFilm()),
+			Score:         rec.GetScore(),
+			BecauseFilmID: rec.GetBecauseFilmId(),
+			BecauseTitle:  rec.GetBecauseTitle(),
+		}
+	}
+	return recommendationListResponse{Films: items}
+}
+
+// parseRecommendationLimit reads the optional "limit" query parameter.
+func parseRecommendationLimit(r *http.Request) (int32, error) {
+	v := r.URL.Query().Get("limit")
+	if v == "" {
+		return defaultRecommendationLimit, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 32)
+	if err != nil || n <= 0 || n > maxRecommendationLimit {
+		return 0, fmt.Errorf("limit must be between 1 and %d", maxRecommendationLimit)
+	}
+	return int32(n), nil
+}
+
+// ListFilmRelation serves GET /api/v1/films/{id}/{relation}. The pattern
+// is registered with a wildcard because "/films/{id}/similar" would
+// conflict with "/films/category/{id}" and "/films/actor/{id}" in
+// net/http's mux; only the "similar" relation exists.
+func (h *RecommendationHandler) ListFilmRelation(w http.ResponseWriter, r *http.Request) {
+	if r.PathValue("relation") != "similar" {
+		middleware.WriteJSONError(w, http.StatusNotFound, "NOT_FOUND", "not found")
+		return
+	}
+	h.ListSimilarFilms(w, r)
+}
+
+// ListSimilarFilms returns the films customers who rented a film also
+// rented (public).
+func (h *RecommendationHandler) ListSimilarFilms(w http.ResponseWriter, r *http.Request) {
+	filmID, err := parseID(r, "id")
+	if err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+	limit, err := parseRecommendationLimit(r)
+	if err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.recommendationClient.ListSimilarFilms(ctx, &filmv1.ListSimilarFilmsRequest{
+		FilmId: filmID,
+		Limit:  limit,
+	})
+	if err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, recommendationsToResponse(resp.GetRecommendations()))
+}
+
+// ListRecommendations returns films recommended to the authenticated
+// customer from their rental history, leaving out films they have rented.
+func (h *RecommendationHandler) ListRecommendations(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r.Context())
+	if claims == nil {
+		middleware.WriteJSONError(w, http.StatusUnauthorized, "UNAUTHORIZED", "authentication required")
+		return
+	}
+	limit, err := parseRecommendationLimit(r)
+	if err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.recommendationClient.ListRecommendedFilms(ctx, &filmv1.ListRecommendedFilmsRequest{
+		CustomerId: claims.UserID,
+		Limit:      limit,
+	})
+	if err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, recommendationsToResponse(resp.GetRecommendations()))
+}
@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	filmv1 "github.com/enkaigaku/dvd-rental/gen/proto/film/v1"
+	"github.com/enkaigaku/dvd-rental/pkg/middleware"
+)
+
+// ReviewHandler handles film rating and review endpoints.
+type ReviewHandler struct {
+	reviewClient filmv1.ReviewServiceClient
+}
+
+// NewReviewHandler creates a new ReviewHandler.
+func NewReviewHandler(reviewClient filmv1.ReviewServiceClient) *ReviewHandler {
+	return &ReviewHandler{reviewClient: reviewClient}
+}
+
+// --- JSON models ---
+
+type reviewItem struct {
+	ID             int32  `json:"id"`
+	FilmID         int32  `json:"film_id"`
+	FilmTitle      string `json:"film_title"`
+	CustomerName   string `json:"customer_name"`
+	Rating         int32  `json:"rating"`
+	Body           string `json:"body,omitempty"`
+	Status         string `json:"status,omitempty"`
+	ModerationNote string `json:"moderation_note,omitempty"`
+	CreatedAt      string `json:"created_at"`
+}
+
+type reviewListResponse struct {
+	Reviews    []reviewItem `json:"reviews"`
+	TotalCount int32        `json:"total_count"`
+	Page       int32        `json:"page"`
+	PageSize   int32        `json:"page_size"`
+}
+
+type submitReviewRequest struct {
+	FilmID int32  `json:"film_id"`
+	Rating int32  `json:"rating"`
+	Body   string `json:"body"`
+}
+
+// reviewToItem converts a proto review to JSON. The moderation status and
+// note are only shown to the review's author.
+func reviewToItem(r *filmv1.Review, own bool) reviewItem {
+	item := reviewItem{
+		ID:           r.GetReviewId(),
+		FilmID:       r.GetFilmId(),
+		FilmTitle:    r.GetFilmTitle(),
+		CustomerName: r.GetCustomerName(),
+		Rating:       r.GetRating(),
+		Body:         r.GetBody(),
+		CreatedAt:    timestampToString(r.GetCreatedAt()),
+	}
+	if own {
+		item.Status = r.GetStatus()
+		item.ModerationNote = r.GetModerationNote()
+	}
+	return item
+}
+
+// ListFilmReviews returns the published reviews of a film (public).
+func (h *ReviewHandler) ListFilmReviews(w http.ResponseWriter, r *http.Request) {
+	filmID, err := parseID(r, "id")
+	if err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+	pageSize, page := parsePagination(r)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.reviewClient.ListFilmReviews(ctx, &filmv1.ListFilmReviewsRequest{
+		FilmId:   filmID,
+		PageSize: pageSize,
+		Page:     page,
+	})
+	if err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	reviews := make([]reviewItem, len(resp.GetReviews()))
+	for i, rv := range resp.GetReviews() {
+		reviews[i] = reviewToItem(rv, false)
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, reviewListResponse{
+		Reviews:    reviews,
+		TotalCount: resp.GetTotalCount(),
+		Page:       page,
+		PageSize:   pageSize,
+	})
+}
+
+// SubmitReview rates and optionally reviews a film the authenticated
+// customer has rented, replacing their earlier review of it. Reviews with
+// text are published once a moderator approves them.
+func (h *ReviewHandler) SubmitReview(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r.Context())
+	if claims == nil {
+		middleware.WriteJSONError(w, http.StatusUnauthorized, "UNAUTHORIZED", "authentication required")
+		return
+	}
+
+	var req submitReviewRequest
+	if err := readJSON(r, &req); err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+	if req.FilmID == 0 {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "film_id is required")
+		return
+	}
+	if req.Rating < 1 || req.Rating > 5 {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "rating must be between 1 and 5")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	review, err := h.reviewClient.SubmitReview(ctx, &filmv1.SubmitReviewRequest{
+		FilmId:     req.FilmID,
+		CustomerId: claims.UserID,
+		Rating:     req.Rating,
+		Body:       req.Body,
+	})
+	if err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusCreated, reviewToItem(review, true))
+}
+
+// ListMyReviews returns the authenticated customer's reviews with their
+// moderation status.
+func (h *ReviewHandler) ListMyReviews(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r.Context())
+	if claims == nil {
+		middleware.WriteJSONError(w, http.StatusUnauthorized, "UNAUTHORIZED", "authentication required")
+		return
+	}
+	pageSize, page := parsePagination(r)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.reviewClient.ListReviewsByCustomer(ctx, &filmv1.ListReviewsByCustomerRequest{
+		CustomerId: claims.UserID,
+		PageSize:   pageSize,
+		Page:       page,
+	})
+	if err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	reviews := make([]reviewItem, len(resp.GetReviews()))
+	for i, rv := range resp.GetReviews() {
+		reviews[i] = reviewToItem(rv, true)
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, reviewListResponse{
+		Reviews:    reviews,
+		TotalCount: resp.GetTotalCount(),
+		Page:       page,
+		PageSize:   pageSize,
+	})
+}
+
+// DeleteReview deletes one of the authenticated customer's reviews.
+func (h *ReviewHandler) DeleteReview(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r.Context())
+	if claims == nil {
+		middleware.WriteJSONError(w, http.StatusUnauthorized, "UNAUTHORIZED", "authentication required")
+		return
+	}
+
+	reviewID, err := parseID(r, "id")
+	if err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	review, err := h.reviewClient.GetReview(ctx, &filmv1.GetReviewRequest{ReviewId: reviewID})
+	if err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+	if review.GetCustomerId() != claims.UserID {
+		middleware.WriteJSONError(w, http.StatusForbidden, "FORBIDDEN", "you can only delete your own reviews")
+		return
+	}
+
+	if _, err := h.reviewClient.DeleteReview(ctx, &filmv1.DeleteReviewRequest{ReviewId: reviewID}); err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
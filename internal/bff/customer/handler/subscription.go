@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	paymentv1 "github.com/enkaigaku/dvd-rental/gen/proto/payment/v1"
+	"github.com/enkaigaku/dvd-rental/pkg/middleware"
+)
+
+// SubscriptionHandler handles membership plan and subscription endpoints.
+type SubscriptionHandler struct {
+	subscriptionClient paymentv1.SubscriptionServiceClient
+}
+
+// NewSubscriptionHandler creates a new SubscriptionHandler.
+func NewSubscriptionHandler(subscriptionClient paymentv1.SubscriptionServiceClient) *SubscriptionHandler {
+	return &SubscriptionHandler{subscriptionClient: subscriptionClient}
+}
+
+// --- JSON models ---
+
+type subscriptionPlanItem struct {
+	ID                   int32  `json:"id"`
+	Name                 string `json:"name"`
+	Description          string `json:"description"`
+	Price                string `json:"price"`
+	IntervalMonths       int32  `json:"interval_months"`
+	MaxConcurrentRentals int32  `json:"max_concurrent_rentals"`
+	MaxRentalsPerPeriod  int32  `json:"max_rentals_per_period"`
+}
+
+type subscriptionPlanListResponse struct {
+	Plans []subscriptionPlanItem `json:"plans"`
+}
+
+type subscriptionResponse struct {
+	ID                 int32  `json:"id"`
+	PlanID             int32  `json:"plan_id"`
+	PlanName           string `json:"plan_name"`
+	Status             string `json:"status"`
+	CurrentPeriodStart string `json:"current_period_start"`
+	CurrentPeriodEnd   string `json:"current_period_end"`
+	CancelAtPeriodEnd  bool   `json:"cancel_at_period_end"`
+	PauseAtPeriodEnd   bool   `json:"pause_at_period_end"`
+	NextBillingAt      string `json:"next_billing_at,omitempty"`
+}
+
+type startSubscriptionRequest struct {
+	PlanID int32 `json:"plan_id"`
+}
+
+func subscriptionToResponse(s *paymentv1.Subscription) subscriptionResponse {
+	return subscriptionResponse{
+		ID:                 s.GetSubscriptionId(),
+		PlanID:             s.GetPlanId(),
+		PlanName:           s.GetPlanName(),
+		Status:             s.GetStatus(),
+		CurrentPeriodStart: timestampToString(s.GetCurrentPeriodStart()),
+		CurrentPeriodEnd:   timestampToString(s.GetCurrentPeriodEnd()),
+		CancelAtPeriodEnd:  s.GetCancelAtPeriodEnd(),
+		PauseAtPeriodEnd:   s.GetPauseAtPeriodEnd(),
+		NextBillingAt:      timestampToString(s.GetNextBillingAt()),
+	}
+}
+
+// ListPlans returns the plans open to new subscribers (public).
+func (h *SubscriptionHandler) ListPlans(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.subscriptionClient.ListSubscriptionPlans(ctx, &paymentv1.ListSubscriptionPlansRequest{
+		ActiveOnly: true,
+	})
+	if err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	plans := make([]subscriptionPlanItem, len(resp.GetPlans()))
+	for i, p := range resp.GetPlans() {
+		plans[i] = subscriptionPlanItem{
+			ID:                   p.GetPlanId(),
+			Name:                 p.GetName(),
+			Description:          p.GetDescription(),
+			Price:                p.GetPrice(),
+			IntervalMonths:       p.GetIntervalMonths(),
+			MaxConcurrentRentals: p.GetMaxConcurrentRentals(),
+			MaxRentalsPerPeriod:  p.GetMaxRentalsPerPeriod(),
+		}
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, subscriptionPlanListResponse{Plans: plans})
+}
+
+// GetSubscription returns the authenticated customer's subscription.
+func (h *SubscriptionHandler) GetSubscription(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r.Context())
+	if claims == nil {
+		middleware.WriteJSONError(w, http.StatusUnauthorized, "UNAUTHORIZED", "authentication required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	sub, err := h.subscriptionClient.GetCurrentSubscription(ctx, &paymentv1.GetCurrentSubscriptionRequest{
+		CustomerId: claims.UserID,
+	})
+	if err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, subscriptionToResponse(sub))
+}
+
+// StartSubscription subscribes the authenticated customer to a plan.
+func (h *SubscriptionHandler) StartSubscription(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r.Context())
+	if claims == nil {
+		middleware.WriteJSONError(w, http.StatusUnauthorized, "UNAUTHORIZED", "authentication required")
+		return
+	}
+
+	var req startSubscriptionRequest
+	if err := readJSON(r, &req); err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+	if req.PlanID == 0 {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "plan_id is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	sub, err := h.subscriptionClient.StartSubscription(ctx, &paymentv1.StartSubscriptionRequest{
+		CustomerId: claims.UserID,
+		PlanId:     req.PlanID,
+	})
+	if err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusCreated, subscriptionToResponse(sub))
+}
+
+// CancelSubscription cancels the authenticated customer's subscription at
+// the end of the paid period.
+func (h *SubscriptionHandler) CancelSubscription(w http.ResponseWriter, r *http.Request) {
+	h.changeSubscription(w, r, func(ctx context.Context, id int32) (*paymentv1.Subscription, error) {
+		return h.subscriptionClient.CancelSubscription(ctx, &paymentv1.CancelSubscriptionRequest{SubscriptionId: id})
+	})
+}
+
+// PauseSubscription pauses the authenticated customer's subscription at the
+// end of the paid period.
+func (h *SubscriptionHandler) PauseSubscription(w http.ResponseWriter, r *http.Request) {
+	h.changeSubscription(w, r, func(ctx context.Context, id int32) (*paymentv1.Subscription, error) {
+		return h.subscriptionClient.PauseSubscription(ctx, &paymentv1.PauseSubscriptionRequest{SubscriptionId: id})
+	})
+}
+
+// ResumeSubscription undoes a scheduled pause or cancellation, or restarts a
+// paused subscription.
+func (h *SubscriptionHandler) ResumeSubscription(w http.ResponseWriter, r *http.Request) {
+	h.changeSubscription(w, r, func(ctx context.Context, id int32) (*paymentv1.Subscription, error) {
+		return h.subscriptionClient.ResumeSubscription(ctx, &paymentv1.ResumeSubscriptionRequest{SubscriptionId: id})
+	})
+}
+
+// RenewSubscription retries the charge for a past due subscription.
+func (h *SubscriptionHandler) RenewSubscription(w http.ResponseWriter, r *http.Request) {
+	h.changeSubscription(w, r, func(ctx context.Context, id int32) (*paymentv1.Subscription, error) {
+		return h.subscriptionClient.RenewSubscription(ctx, &paymentv1.RenewSubscriptionRequest{SubscriptionId: id})
+	})
+}
+
+// changeSubscription applies a state change to the authenticated customer's subscription.
+func (h *SubscriptionHandler) changeSubscription(w http.ResponseWriter, r *http.Request, change func(ctx context.Context, id int32) (*paymentv1.Subscription, error)) {
+	claims := middleware.GetClaims(r.Context())
+	if claims == nil {
+		middleware.WriteJSONError(w, http.StatusUnauthorized, "UNAUTHORIZED", "authentication required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	current, err := h.subscriptionClient.GetCurrentSubscription(ctx, &paymentv1.GetCurrentSubscriptionRequest{
+		CustomerId: claims.UserID,
+	})
+	if err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	sub, err := change(ctx, current.GetSubscriptionId())
+	if err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, subscriptionToResponse(sub))
+}
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	filmv1 "github.com/enkaigaku/dvd-rental/gen/proto/film/v1"
+	"github.com/enkaigaku/dvd-rental/pkg/middleware"
+)
+
+// WishlistHandler handles the authenticated customer's wishlist of films to
+// rent later.
+type WishlistHandler struct {
+	wishlistClient filmv1.WishlistServiceClient
+}
+
+// NewWishlistHandler creates a new WishlistHandler.
+func NewWishlistHandler(wishlistClient filmv1.WishlistServiceClient) *WishlistHandler {
+	return &WishlistHandler{wishlistClient: wishlistClient}
+}
+
+// --- JSON models ---
+
+// wishlistItem is a wishlisted film with its availability at the
+// customer's home store.
+type wishlistItem struct {
+	filmListItem
+	AddedAt         string `json:"added_at"`
+	StoreID         int32  `json:"store_id"`
+	Copies          int32  `json:"copies"`
+	AvailableCopies int32  `json:"available_copies"`
+	Available       bool   `json:"available"`
+}
+
+type wishlistResponse struct {
+	Films      []wishlistItem `json:"films"`
+	TotalCount int32          `json:"total_count"`
+	Page       int32          `json:"page"`
+	PageSize   int32          `json:"page_size"`
+}
+
+type addToWishlistRequest struct {
+	FilmID int32 `json:"film_id"`
+}
+
+// ListWishlist returns the authenticated customer's wishlist, most recently
+// added first, with how many copies of each film are in stock at their
+// store.
+func (h *WishlistHandler) ListWishlist(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r.Context())
+	if claims == nil {
+		middleware.WriteJSONError(w, http.StatusUnauthorized, "UNAUTHORIZED", "authentication required")
+		return
+	}
+	pageSize, page := parsePagination(r)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.wishlistClient.ListWishlist(ctx, &filmv1.ListWishlistRequest{
+		CustomerId: claims.UserID,
+		PageSize:   pageSize,
+		Page:       page,
+	})
+	if err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	films := make([]wishlistItem, len(resp.GetItems()))
+	for i, item := range resp.GetItems() {
+		films[i] = wishlistItem{
+			filmListItem:    filmToListItem(item.GetFilm()),
+			AddedAt:         timestampToString(item.GetAddedAt()),
+			StoreID:         item.GetStoreId(),
+			Copies:          item.GetCopies(),
+			AvailableCopies: item.GetAvailableCopies(),
+			Available:       item.GetAvailableCopies() > 0,
+		}
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, wishlistResponse{
+		Films:      films,
+		TotalCount: resp.GetTotalCount(),
+		Page:       page,
+		PageSize:   pageSize,
+	})
+}
+
+// AddToWishlist saves a film to the authenticated customer's wishlist.
+// Saving a film that is already on it succeeds without changing anything.
+func (h *WishlistHandler) AddToWishlist(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r.Context())
+	if claims == nil {
+		middleware.WriteJSONError(w, http.StatusUnauthorized, "UNAUTHORIZED", "authentication required")
+		return
+	}
+
+	var req addToWishlistRequest
+	if err := readJSON(r, &req); err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+	if req.FilmID == 0 {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "film_id is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	_, err := h.wishlistClient.AddToWishlist(ctx, &filmv1.AddToWishlistRequest{
+		CustomerId: claims.UserID,
+		FilmId:     req.FilmID,
+	})
+	if err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveFromWishlist removes a film from the authenticated customer's
+// wishlist.
+func (h *WishlistHandler) RemoveFromWishlist(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r.Context())
+	if claims == nil {
+		middleware.WriteJSONError(w, http.StatusUnauthorized, "UNAUTHORIZED", "authentication required")
+		return
+	}
+
+	filmID, err := parseID(r, "film_id")
+	if err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	_, err = h.wishlistClient.RemoveFromWishlist(ctx, &filmv1.RemoveFromWishlistRequest{
+		CustomerId: claims.UserID,
+		FilmId:     filmID,
+	})
+	if err != nil {
+		grpcToHTTPError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -14,23 +14,48 @@ func NewRouter(
 	rentalH *handler.RentalHandler,
 	paymentH *handler.PaymentHandler,
 	profileH *handler.ProfileHandler,
+	loyaltyH *handler.LoyaltyHandler,
+	subscriptionH *handler.SubscriptionHandler,
+	reviewH *handler.ReviewHandler,
+	recommendationH *handler.RecommendationHandler,
+	filmAssetH *handler.FilmAssetHandler,
+	cityH *handler.CityHandler,
+	wishlistH *handler.WishlistHandler,
 	authMw *middleware.AuthMiddleware,
 ) http.Handler {
 	mux := http.NewServeMux()
 
 	// --- Public: Auth ---
+	mux.HandleFunc("POST /api/v1/auth/register", authH.Register)
 	mux.HandleFunc("POST /api/v1/auth/login", authH.Login)
 	mux.HandleFunc("POST /api/v1/auth/refresh", authH.Refresh)
 	mux.HandleFunc("POST /api/v1/auth/logout", authH.Logout)
+	mux.HandleFunc("POST /api/v1/auth/password/forgot", authH.ForgotPassword)
+	mux.HandleFunc("POST /api/v1/auth/password/reset", authH.ResetPassword)
+	mux.HandleFunc("POST /api/v1/auth/email/verify", authH.VerifyEmail)
 
 	// --- Public: Films (read-only) ---
 	mux.HandleFunc("GET /api/v1/films/search", filmH.SearchFilms)
+	mux.HandleFunc("GET /api/v1/films/trending", filmH.ListTrendingFilms)
+	mux.HandleFunc("GET /api/v1/films/popular", filmH.ListPopularFilms)
+	mux.HandleFunc("GET /api/v1/films/new-releases", filmH.ListNewReleases)
 	mux.HandleFunc("GET /api/v1/films/category/{id}", filmH.ListFilmsByCategory)
 	mux.HandleFunc("GET /api/v1/films/actor/{id}", filmH.ListFilmsByActor)
 	mux.HandleFunc("GET /api/v1/films/{id}", filmH.GetFilm)
+	mux.HandleFunc("GET /api/v1/films/{id}/{relation}", recommendationH.ListFilmRelation)
 	mux.HandleFunc("GET /api/v1/films", filmH.ListFilms)
 	mux.HandleFunc("GET /api/v1/categories", filmH.ListCategories)
 	mux.HandleFunc("GET /api/v1/actors", filmH.ListActors)
+	mux.HandleFunc("GET /api/v1/actors/{id}", filmH.GetActor)
+	mux.HandleFunc("GET /api/v1/search/suggest", filmH.Suggest)
+	mux.HandleFunc("GET /api/v1/reviews/film/{id}", reviewH.ListFilmReviews)
+	mux.HandleFunc("GET /api/v1/film-assets/{id}/{size}", filmAssetH.GetFilmAsset)
+
+	// --- Public: Cities ---
+	mux.HandleFunc("GET /api/v1/cities", cityH.ListCities)
+
+	// --- Public: Subscription plans ---
+	mux.HandleFunc("GET /api/v1/subscription-plans", subscriptionH.ListPlans)
 
 	// --- Protected: Rentals ---
 	mux.Handle("GET /api/v1/rentals/{id}", authMw.Require(http.HandlerFunc(rentalH.GetRental)))
@@ -40,10 +65,39 @@ func NewRouter(
 
 	// --- Protected: Payments ---
 	mux.Handle("GET /api/v1/payments", authMw.Require(http.HandlerFunc(paymentH.ListPayments)))
+	mux.Handle("POST /api/v1/payments", authMw.Require(http.HandlerFunc(paymentH.CreatePayment)))
+	mux.Handle("GET /api/v1/payments/{id}/receipt", authMw.Require(http.HandlerFunc(paymentH.GetReceipt)))
+	mux.Handle("GET /api/v1/gift-cards/{code}", authMw.Require(http.HandlerFunc(paymentH.GetGiftCard)))
 
 	// --- Protected: Profile ---
 	mux.Handle("GET /api/v1/profile", authMw.Require(http.HandlerFunc(profileH.GetProfile)))
 	mux.Handle("PUT /api/v1/profile", authMw.Require(http.HandlerFunc(profileH.UpdateProfile)))
+	mux.Handle("PUT /api/v1/profile/address", authMw.Require(http.HandlerFunc(profileH.UpdateAddress)))
+	mux.Handle("POST /api/v1/profile/password", authMw.Require(http.HandlerFunc(profileH.ChangePassword)))
+	mux.Handle("POST /api/v1/profile/email/verification", authMw.Require(http.HandlerFunc(profileH.ResendEmailVerification)))
+	mux.Handle("GET /api/v1/profile/export", authMw.Require(http.HandlerFunc(profileH.ExportData)))
+	mux.Handle("GET /api/v1/profile/stored-value", authMw.Require(http.HandlerFunc(paymentH.ListStoredValue)))
+	mux.Handle("GET /api/v1/profile/loyalty", authMw.Require(http.HandlerFunc(loyaltyH.GetLoyalty)))
+	mux.Handle("GET /api/v1/profile/loyalty/transactions", authMw.Require(http.HandlerFunc(loyaltyH.ListLoyaltyTransactions)))
+	mux.Handle("GET /api/v1/profile/subscription", authMw.Require(http.HandlerFunc(subscriptionH.GetSubscription)))
+	mux.Handle("POST /api/v1/profile/subscription", authMw.Require(http.HandlerFunc(subscriptionH.StartSubscription)))
+	mux.Handle("POST /api/v1/profile/subscription/cancel", authMw.Require(http.HandlerFunc(subscriptionH.CancelSubscription)))
+	mux.Handle("POST /api/v1/profile/subscription/pause", authMw.Require(http.HandlerFunc(subscriptionH.PauseSubscription)))
+	mux.Handle("POST /api/v1/profile/subscription/resume", authMw.Require(http.HandlerFunc(subscriptionH.ResumeSubscription)))
+	mux.Handle("POST /api/v1/profile/subscription/renew", authMw.Require(http.HandlerFunc(subscriptionH.RenewSubscription)))
+
+	// --- Protected: Reviews ---
+	mux.Handle("GET /api/v1/reviews", authMw.Require(http.HandlerFunc(reviewH.ListMyReviews)))
+	mux.Handle("POST /api/v1/reviews", authMw.Require(http.HandlerFunc(reviewH.SubmitReview)))
+	mux.Handle("DELETE /api/v1/reviews/{id}", authMw.Require(http.HandlerFunc(reviewH.DeleteReview)))
+
+	// --- Protected: Wishlist ---
+	mux.Handle("GET /api/v1/wishlist", authMw.Require(http.HandlerFunc(wishlistH.ListWishlist)))
+	mux.Handle("POST /api/v1/wishlist", authMw.Require(http.HandlerFunc(wishlistH.AddToWishlist)))
+	mux.Handle("DELETE /api/v1/wishlist/{film_id}", authMw.Require(http.HandlerFunc(wishlistH.RemoveFromWishlist)))
+
+	// --- Protected: Recommendations ---
+	mux.Handle("GET /api/v1/recommendations", authMw.Require(http.HandlerFunc(recommendationH.ListRecommendations)))
 
 	// Apply middleware chain: Recovery → Logging → CORS → router.
 	return middleware.Recovery(
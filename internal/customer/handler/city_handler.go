@@ -27,7 +27,7 @@ func (h *CityHandler) GetCity(ctx context.Context, req *customerv1.GetCityReques
 }
 
 func (h *CityHandler) ListCities(ctx context.Context, req *customerv1.ListCitiesRequest) (*customerv1.ListCitiesResponse, error) {
-	cities, total, err := h.svc.ListCities(ctx, req.GetPageSize(), req.GetPage())
+	cities, total, err := h.svc.ListCities(ctx, req.GetName(), req.GetCountryId(), req.GetPageSize(), req.GetPage())
 	if err != nil {
 		return nil, toGRPCError(err)
 	}
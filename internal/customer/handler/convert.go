@@ -22,13 +22,15 @@ func toGRPCError(err error) error {
 		return status.Error(codes.AlreadyExists, err.Error())
 	case errors.Is(err, service.ErrForeignKey):
 		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, service.ErrFailedPrecondition):
+		return status.Error(codes.FailedPrecondition, err.Error())
 	default:
 		return status.Error(codes.Internal, "internal error")
 	}
 }
 
 func customerToProto(c model.Customer) *customerv1.Customer {
-	return &customerv1.Customer{
+	pb := &customerv1.Customer{
 		CustomerId:   c.CustomerID,
 		StoreId:      c.StoreID,
 		FirstName:    c.FirstName,
@@ -39,7 +41,15 @@ func customerToProto(c model.Customer) *customerv1.Customer {
 		CreateDate:   timestamppb.New(c.CreateDate),
 		LastUpdate:   timestamppb.New(c.LastUpdate),
 		PasswordHash: c.PasswordHash,
+		PendingEmail: c.PendingEmail,
+	}
+	if !c.EmailVerifiedAt.IsZero() {
+		pb.EmailVerifiedAt = timestamppb.New(c.EmailVerifiedAt)
+	}
+	if !c.AnonymizedAt.IsZero() {
+		pb.AnonymizedAt = timestamppb.New(c.AnonymizedAt)
 	}
+	return pb
 }
 
 func customerDetailToProto(d model.CustomerDetail) *customerv1.CustomerDetail {
@@ -55,6 +65,204 @@ func customerDetailToProto(d model.CustomerDetail) *customerv1.CustomerDetail {
 	}
 }
 
+func customerDataExportToProto(e model.CustomerDataExport) *customerv1.CustomerDataExport {
+	rentals := make([]*customerv1.CustomerRentalRecord, len(e.Rentals))
+	for i, r := range e.Rentals {
+		rentals[i] = &customerv1.CustomerRentalRecord{
+			RentalId:   r.RentalID,
+			RentalDate: timestamppb.New(r.RentalDate),
+			StoreId:    r.StoreID,
+			FilmId:     r.FilmID,
+			FilmTitle:  r.FilmTitle,
+		}
+		if !r.ReturnDate.IsZero() {
+			rentals[i].ReturnDate = timestamppb.New(r.ReturnDate)
+		}
+	}
+	payments := make([]*customerv1.CustomerPaymentRecord, len(e.Payments))
+	for i, p := range e.Payments {
+		payments[i] = &customerv1.CustomerPaymentRecord{
+			PaymentId:   p.PaymentID,
+			RentalId:    p.RentalID,
+			Amount:      p.Amount,
+			NetAmount:   p.NetAmount,
+			TaxAmount:   p.TaxAmount,
+			ChargeType:  p.ChargeType,
+			PaymentDate: timestamppb.New(p.PaymentDate),
+		}
+	}
+	reviews := make([]*customerv1.CustomerReviewRecord, len(e.Reviews))
+	for i, r := range e.Reviews {
+		reviews[i] = &customerv1.CustomerReviewRecord{
+			ReviewId:  r.ReviewID,
+			FilmId:    r.FilmID,
+			FilmTitle: r.FilmTitle,
+			Rating:    r.Rating,
+			Body:      r.Body,
+			Status:    r.Status,
+			CreatedAt: timestamppb.New(r.CreatedAt),
+		}
+	}
+	wishlist := make([]*customerv1.CustomerWishlistRecord, len(e.Wishlist))
+	for i, w := range e.Wishlist {
+		wishlist[i] = &customerv1.CustomerWishlistRecord{
+			FilmId:    w.FilmID,
+			FilmTitle: w.FilmTitle,
+			AddedAt:   timestamppb.New(w.AddedAt),
+		}
+	}
+	loyaltyTransactions := make([]*customerv1.CustomerLoyaltyTransactionRecord, len(e.LoyaltyTransactions))
+	for i, t := range e.LoyaltyTransactions {
+		loyaltyTransactions[i] = &customerv1.CustomerLoyaltyTransactionRecord{
+			TransactionId: t.TransactionID,
+			Kind:          t.Kind,
+			Points:        t.Points,
+			BalanceAfter:  t.BalanceAfter,
+			PaymentId:     t.PaymentID,
+			RentalId:      t.RentalID,
+			Note:          t.Note,
+			CreatedAt:     timestamppb.New(t.CreatedAt),
+		}
+	}
+	storedValue := make([]*customerv1.CustomerStoredValueRecord, len(e.StoredValue))
+	for i, a := range e.StoredValue {
+		storedValue[i] = &customerv1.CustomerStoredValueRecord{
+			AccountId:  a.AccountID,
+			Kind:       a.Kind,
+			Code:       a.Code,
+			Balance:    a.Balance,
+			Active:     a.Active,
+			CreateDate: timestamppb.New(a.CreateDate),
+		}
+	}
+	storedValueEntries := make([]*customerv1.CustomerStoredValueEntryRecord, len(e.StoredValueEntries))
+	for i, en := range e.StoredValueEntries {
+		storedValueEntries[i] = &customerv1.CustomerStoredValueEntryRecord{
+			EntryId:      en.EntryID,
+			AccountId:    en.AccountID,
+			Kind:         en.Kind,
+			Amount:       en.Amount,
+			BalanceAfter: en.BalanceAfter,
+			PaymentId:    en.PaymentID,
+			Note:         en.Note,
+			CreatedAt:    timestamppb.New(en.CreatedAt),
+		}
+	}
+	subscriptions := make([]*customerv1.CustomerSubscriptionRecord, len(e.Subscriptions))
+	for i, sub := range e.Subscriptions {
+		subscriptions[i] = &customerv1.CustomerSubscriptionRecord{
+			SubscriptionId:     sub.SubscriptionID,
+			PlanName:           sub.PlanName,
+			Status:             sub.Status,
+			CurrentPeriodStart: timestamppb.New(sub.CurrentPeriodStart),
+			CurrentPeriodEnd:   timestamppb.New(sub.CurrentPeriodEnd),
+			CreateDate:         timestamppb.New(sub.CreateDate),
+		}
+		if !sub.CanceledAt.IsZero() {
+			subscriptions[i].CanceledAt = timestamppb.New(sub.CanceledAt)
+		}
+	}
+	subscriptionPayments := make([]*customerv1.CustomerSubscriptionPaymentRecord, len(e.SubscriptionPayments))
+	for i, p := range e.SubscriptionPayments {
+		subscriptionPayments[i] = &customerv1.CustomerSubscriptionPaymentRecord{
+			SubscriptionPaymentId: p.SubscriptionPaymentID,
+			SubscriptionId:        p.SubscriptionID,
+			Amount:                p.Amount,
+			PeriodStart:           timestamppb.New(p.PeriodStart),
+			PeriodEnd:             timestamppb.New(p.PeriodEnd),
+			Status:                p.Status,
+			CreatedAt:             timestamppb.New(p.CreatedAt),
+		}
+	}
+
+	pb := &customerv1.CustomerDataExport{
+		Customer:             customerDetailToProto(e.Customer),
+		Rentals:              rentals,
+		Payments:             payments,
+		ExportedAt:           timestamppb.New(e.ExportedAt),
+		Reviews:              reviews,
+		Wishlist:             wishlist,
+		LoyaltyTransactions:  loyaltyTransactions,
+		StoredValue:          storedValue,
+		StoredValueEntries:   storedValueEntries,
+		Subscriptions:        subscriptions,
+		SubscriptionPayments: subscriptionPayments,
+	}
+	if e.Loyalty != nil {
+		pb.Loyalty = &customerv1.CustomerLoyaltyRecord{
+			Tier:           e.Loyalty.Tier,
+			PointsBalance:  e.Loyalty.PointsBalance,
+			LifetimePoints: e.Loyalty.LifetimePoints,
+		}
+	}
+	return pb
+}
+
+func customerSummaryToProto(s model.CustomerSummary) *customerv1.CustomerSummary {
+	categories := make([]*customerv1.CategoryRentalCount, len(s.FavouriteCategories))
+	for i, c := range s.FavouriteCategories {
+		categories[i] = &customerv1.CategoryRentalCount{
+			CategoryId: c.CategoryID,
+			Name:       c.Name,
+			Rentals:    c.Rentals,
+		}
+	}
+	pb := &customerv1.CustomerSummary{
+		CustomerId:          s.CustomerID,
+		TotalRentals:        s.TotalRentals,
+		OpenRentals:         s.OpenRentals,
+		OverdueRentals:      s.OverdueRentals,
+		LifetimeSpend:       s.LifetimeSpend,
+		FavouriteCategories: categories,
+		AverageDaysKept:     s.AverageDaysKept,
+	}
+	if !s.FirstRentalDate.IsZero() {
+		pb.FirstRentalDate = timestamppb.New(s.FirstRentalDate)
+		pb.LastRentalDate = timestamppb.New(s.LastRentalDate)
+	}
+	return pb
+}
+
+func customerDuplicateToProto(d model.CustomerDuplicate) *customerv1.CustomerDuplicate {
+	pb := &customerv1.CustomerDuplicate{
+		DuplicateId:   d.DuplicateID,
+		Customer:      customerDetailToProto(d.Customer),
+		OtherCustomer: customerDetailToProto(d.OtherCustomer),
+		Score:         d.Score,
+		NameScore:     d.NameScore,
+		AddressScore:  d.AddressScore,
+		PhoneScore:    d.PhoneScore,
+		Status:        d.Status,
+		DetectedAt:    timestamppb.New(d.DetectedAt),
+		ReviewedBy:    d.ReviewedBy,
+	}
+	if !d.ReviewedAt.IsZero() {
+		pb.ReviewedAt = timestamppb.New(d.ReviewedAt)
+	}
+	return pb
+}
+
+func customerMergeToProto(m model.CustomerMerge) *customerv1.CustomerMerge {
+	return &customerv1.CustomerMerge{
+		MergeId:               m.MergeID,
+		SurvivingCustomerId:   m.SurvivingCustomerID,
+		MergedCustomerId:      m.MergedCustomerID,
+		RentalsMoved:          m.RentalsMoved,
+		PaymentsMoved:         m.PaymentsMoved,
+		MergedBy:              m.MergedBy,
+		MergedAt:              timestamppb.New(m.MergedAt),
+		SubscriptionsMoved:    m.SubscriptionsMoved,
+		SubscriptionsCanceled: m.SubscriptionsCanceled,
+		GiftCardsMoved:        m.GiftCardsMoved,
+		StoreCreditMoved:      m.StoreCreditMoved,
+		LoyaltyPointsMoved:    m.LoyaltyPointsMoved,
+		ReviewsMoved:          m.ReviewsMoved,
+		ReviewsDropped:        m.ReviewsDropped,
+		WishlistItemsMoved:    m.WishlistItemsMoved,
+		PromotionUsesMoved:    m.PromotionUsesMoved,
+	}
+}
+
 func addressToProto(a model.Address) *customerv1.Address {
 	return &customerv1.Address{
 		AddressId:  a.AddressID,
@@ -65,6 +273,7 @@ func addressToProto(a model.Address) *customerv1.Address {
 		PostalCode: a.PostalCode,
 		Phone:      a.Phone,
 		LastUpdate: timestamppb.New(a.LastUpdate),
+		UserCount:  a.UserCount,
 	}
 }
 
@@ -74,6 +283,7 @@ func cityToProto(c model.City) *customerv1.City {
 		City:       c.City,
 		CountryId:  c.CountryID,
 		LastUpdate: timestamppb.New(c.LastUpdate),
+		Country:    c.CountryName,
 	}
 }
 
@@ -84,3 +294,52 @@ func countryToProto(c model.Country) *customerv1.Country {
 		LastUpdate: timestamppb.New(c.LastUpdate),
 	}
 }
+
+func segmentToProto(s model.Segment) *customerv1.Segment {
+	pb := &customerv1.Segment{
+		SegmentId:   s.SegmentID,
+		Name:        s.Name,
+		Description: s.Description,
+		Rule:        segmentRuleToProto(s.Rule),
+		MemberCount: s.MemberCount,
+		CreatedAt:   timestamppb.New(s.CreatedAt),
+		LastUpdate:  timestamppb.New(s.LastUpdate),
+	}
+	if !s.EvaluatedAt.IsZero() {
+		pb.EvaluatedAt = timestamppb.New(s.EvaluatedAt)
+	}
+	return pb
+}
+
+func segmentRuleToProto(r model.SegmentRule) *customerv1.SegmentRule {
+	rules := make([]*customerv1.SegmentRule, len(r.Rules))
+	for i, child := range r.Rules {
+		rules[i] = segmentRuleToProto(child)
+	}
+	return &customerv1.SegmentRule{
+		Op:       r.Op,
+		Rules:    rules,
+		Field:    r.Field,
+		Category: r.Category,
+		Values:   r.Values,
+	}
+}
+
+// segmentRuleFromProto converts a rule tree from its proto form; a nil
+// rule becomes the zero rule, which fails validation.
+func segmentRuleFromProto(pb *customerv1.SegmentRule) model.SegmentRule {
+	if pb == nil {
+		return model.SegmentRule{}
+	}
+	var rules []model.SegmentRule
+	for _, child := range pb.GetRules() {
+		rules = append(rules, segmentRuleFromProto(child))
+	}
+	return model.SegmentRule{
+		Op:       pb.GetOp(),
+		Rules:    rules,
+		Field:    pb.GetField(),
+		Category: pb.GetCategory(),
+		Values:   pb.GetValues(),
+	}
+}
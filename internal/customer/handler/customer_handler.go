@@ -54,6 +54,14 @@ func (h *CustomerHandler) ListCustomersByStore(ctx context.Context, req *custome
 	return toCustomerListResponse(customers, total), nil
 }
 
+func (h *CustomerHandler) SearchCustomers(ctx context.Context, req *customerv1.SearchCustomersRequest) (*customerv1.ListCustomersResponse, error) {
+	customers, total, err := h.svc.SearchCustomers(ctx, req.GetQuery(), req.GetStoreId(), req.GetStatus(), req.GetPageSize(), req.GetPage())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return toCustomerListResponse(customers, total), nil
+}
+
 func (h *CustomerHandler) CreateCustomer(ctx context.Context, req *customerv1.CreateCustomerRequest) (*customerv1.Customer, error) {
 	cust, err := h.svc.CreateCustomer(ctx, repository.CreateCustomerParams{
 		StoreID:   req.GetStoreId(),
@@ -69,6 +77,43 @@ func (h *CustomerHandler) CreateCustomer(ctx context.Context, req *customerv1.Cr
 	return customerToProto(cust), nil
 }
 
+func (h *CustomerHandler) RegisterCustomer(ctx context.Context, req *customerv1.RegisterCustomerRequest) (*customerv1.Customer, error) {
+	cust, err := h.svc.RegisterCustomer(ctx, repository.RegisterCustomerParams{
+		StoreID:      req.GetStoreId(),
+		FirstName:    req.GetFirstName(),
+		LastName:     req.GetLastName(),
+		Email:        req.GetEmail(),
+		PasswordHash: req.GetPasswordHash(),
+		Address: repository.CreateAddressParams{
+			Address:    req.GetAddress(),
+			Address2:   req.GetAddress2(),
+			District:   req.GetDistrict(),
+			CityID:     req.GetCityId(),
+			PostalCode: req.GetPostalCode(),
+			Phone:      req.GetPhone(),
+		},
+	})
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return customerToProto(cust), nil
+}
+
+func (h *CustomerHandler) ChangeCustomerAddress(ctx context.Context, req *customerv1.ChangeCustomerAddressRequest) (*customerv1.Customer, error) {
+	cust, err := h.svc.ChangeCustomerAddress(ctx, req.GetCustomerId(), repository.CreateAddressParams{
+		Address:    req.GetAddress(),
+		Address2:   req.GetAddress2(),
+		District:   req.GetDistrict(),
+		CityID:     req.GetCityId(),
+		PostalCode: req.GetPostalCode(),
+		Phone:      req.GetPhone(),
+	})
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return customerToProto(cust), nil
+}
+
 func (h *CustomerHandler) UpdateCustomer(ctx context.Context, req *customerv1.UpdateCustomerRequest) (*customerv1.Customer, error) {
 	cust, err := h.svc.UpdateCustomer(ctx, repository.UpdateCustomerParams{
 		CustomerID: req.GetCustomerId(),
@@ -85,13 +130,104 @@ func (h *CustomerHandler) UpdateCustomer(ctx context.Context, req *customerv1.Up
 	return customerToProto(cust), nil
 }
 
-func (h *CustomerHandler) DeleteCustomer(ctx context.Context, req *customerv1.DeleteCustomerRequest) (*emptypb.Empty, error) {
-	if err := h.svc.DeleteCustomer(ctx, req.GetCustomerId()); err != nil {
+func (h *CustomerHandler) UpdateCustomerPassword(ctx context.Context, req *customerv1.UpdateCustomerPasswordRequest) (*emptypb.Empty, error) {
+	if err := h.svc.UpdateCustomerPassword(ctx, req.GetCustomerId(), req.GetPasswordHash()); err != nil {
 		return nil, toGRPCError(err)
 	}
 	return &emptypb.Empty{}, nil
 }
 
+func (h *CustomerHandler) RequestCustomerEmailChange(ctx context.Context, req *customerv1.RequestCustomerEmailChangeRequest) (*customerv1.Customer, error) {
+	c, err := h.svc.RequestEmailChange(ctx, req.GetCustomerId(), req.GetEmail())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return customerToProto(c), nil
+}
+
+func (h *CustomerHandler) VerifyCustomerEmail(ctx context.Context, req *customerv1.VerifyCustomerEmailRequest) (*customerv1.Customer, error) {
+	c, err := h.svc.VerifyEmail(ctx, req.GetCustomerId(), req.GetEmail())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return customerToProto(c), nil
+}
+
+func (h *CustomerHandler) AnonymizeCustomer(ctx context.Context, req *customerv1.AnonymizeCustomerRequest) (*customerv1.Customer, error) {
+	c, err := h.svc.AnonymizeCustomer(ctx, req.GetCustomerId())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return customerToProto(c), nil
+}
+
+func (h *CustomerHandler) ExportCustomerData(ctx context.Context, req *customerv1.ExportCustomerDataRequest) (*customerv1.CustomerDataExport, error) {
+	export, err := h.svc.ExportCustomerData(ctx, req.GetCustomerId())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return customerDataExportToProto(export), nil
+}
+
+func (h *CustomerHandler) GetCustomerSummary(ctx context.Context, req *customerv1.GetCustomerSummaryRequest) (*customerv1.CustomerSummary, error) {
+	summary, err := h.svc.GetCustomerSummary(ctx, req.GetCustomerId())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return customerSummaryToProto(summary), nil
+}
+
+func (h *CustomerHandler) ListCustomerSummaries(ctx context.Context, req *customerv1.ListCustomerSummariesRequest) (*customerv1.ListCustomerSummariesResponse, error) {
+	summaries, err := h.svc.ListCustomerSummaries(ctx, req.GetCustomerIds())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	protos := make([]*customerv1.CustomerSummary, len(summaries))
+	for i, summary := range summaries {
+		protos[i] = customerSummaryToProto(summary)
+	}
+	return &customerv1.ListCustomerSummariesResponse{Summaries: protos}, nil
+}
+
+func (h *CustomerHandler) ScanDuplicateCustomers(ctx context.Context, _ *customerv1.ScanDuplicateCustomersRequest) (*customerv1.ScanDuplicateCustomersResponse, error) {
+	pending, err := h.svc.ScanDuplicateCustomers(ctx)
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &customerv1.ScanDuplicateCustomersResponse{PendingCount: int32(pending)}, nil
+}
+
+func (h *CustomerHandler) ListCustomerDuplicates(ctx context.Context, req *customerv1.ListCustomerDuplicatesRequest) (*customerv1.ListCustomerDuplicatesResponse, error) {
+	duplicates, total, err := h.svc.ListCustomerDuplicates(ctx, req.GetStatus(), req.GetPageSize(), req.GetPage())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	protos := make([]*customerv1.CustomerDuplicate, len(duplicates))
+	for i, d := range duplicates {
+		protos[i] = customerDuplicateToProto(d)
+	}
+	return &customerv1.ListCustomerDuplicatesResponse{
+		Duplicates: protos,
+		TotalCount: int32(total),
+	}, nil
+}
+
+func (h *CustomerHandler) DismissCustomerDuplicate(ctx context.Context, req *customerv1.DismissCustomerDuplicateRequest) (*customerv1.CustomerDuplicate, error) {
+	duplicate, err := h.svc.DismissCustomerDuplicate(ctx, req.GetDuplicateId(), req.GetStaffId())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return customerDuplicateToProto(duplicate), nil
+}
+
+func (h *CustomerHandler) MergeCustomers(ctx context.Context, req *customerv1.MergeCustomersRequest) (*customerv1.CustomerMerge, error) {
+	merge, err := h.svc.MergeCustomers(ctx, req.GetSurvivingCustomerId(), req.GetMergedCustomerId(), req.GetStaffId())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return customerMergeToProto(merge), nil
+}
+
 func toCustomerListResponse(customers []model.Customer, total int64) *customerv1.ListCustomersResponse {
 	protos := make([]*customerv1.Customer, len(customers))
 	for i, c := range customers {
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	customerv1 "github.com/enkaigaku/dvd-rental/gen/proto/customer/v1"
+	"github.com/enkaigaku/dvd-rental/internal/customer/model"
+	"github.com/enkaigaku/dvd-rental/internal/customer/repository"
+	"github.com/enkaigaku/dvd-rental/internal/customer/service"
+)
+
+// SegmentHandler implements the SegmentService gRPC server.
+type SegmentHandler struct {
+	customerv1.UnimplementedSegmentServiceServer
+	svc *service.SegmentService
+}
+
+// NewSegmentHandler creates a new SegmentHandler.
+func NewSegmentHandler(svc *service.SegmentService) *SegmentHandler {
+	return &SegmentHandler{svc: svc}
+}
+
+func (h *SegmentHandler) GetSegment(ctx context.Context, req *customerv1.GetSegmentRequest) (*customerv1.Segment, error) {
+	segment, err := h.svc.GetSegment(ctx, req.GetSegmentId())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return segmentToProto(segment), nil
+}
+
+func (h *SegmentHandler) ListSegments(ctx context.Context, req *customerv1.ListSegmentsRequest) (*customerv1.ListSegmentsResponse, error) {
+	segments, total, err := h.svc.ListSegments(ctx, req.GetPageSize(), req.GetPage())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	protos := make([]*customerv1.Segment, len(segments))
+	for i, s := range segments {
+		protos[i] = segmentToProto(s)
+	}
+	return &customerv1.ListSegmentsResponse{
+		Segments:   protos,
+		TotalCount: int32(total),
+	}, nil
+}
+
+func (h *SegmentHandler) CreateSegment(ctx context.Context, req *customerv1.CreateSegmentRequest) (*customerv1.Segment, error) {
+	segment, err := h.svc.CreateSegment(ctx, repository.SegmentParams{
+		Name:        req.GetName(),
+		Description: req.GetDescription(),
+		Rule:        segmentRuleFromProto(req.GetRule()),
+	})
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return segmentToProto(segment), nil
+}
+
+func (h *SegmentHandler) UpdateSegment(ctx context.Context, req *customerv1.UpdateSegmentRequest) (*customerv1.Segment, error) {
+	segment, err := h.svc.UpdateSegment(ctx, req.GetSegmentId(), repository.SegmentParams{
+		Name:        req.GetName(),
+		Description: req.GetDescription(),
+		Rule:        segmentRuleFromProto(req.GetRule()),
+	})
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return segmentToProto(segment), nil
+}
+
+func (h *SegmentHandler) DeleteSegment(ctx context.Context, req *customerv1.DeleteSegmentRequest) (*emptypb.Empty, error) {
+	if err := h.svc.DeleteSegment(ctx, req.GetSegmentId()); err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (h *SegmentHandler) PreviewSegment(ctx context.Context, req *customerv1.PreviewSegmentRequest) (*customerv1.PreviewSegmentResponse, error) {
+	preview, err := h.svc.PreviewSegment(ctx, segmentRuleFromProto(req.GetRule()))
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return toPreviewSegmentResponse(preview), nil
+}
+
+func (h *SegmentHandler) EvaluateSegment(ctx context.Context, req *customerv1.EvaluateSegmentRequest) (*customerv1.Segment, error) {
+	segment, err := h.svc.EvaluateSegment(ctx, req.GetSegmentId())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return segmentToProto(segment), nil
+}
+
+func (h *SegmentHandler) ListSegmentMembers(ctx context.Context, req *customerv1.ListSegmentMembersRequest) (*customerv1.ListCustomersResponse, error) {
+	customers, total, err := h.svc.ListSegmentMembers(ctx, req.GetSegmentId(), req.GetPageSize(), req.GetPage())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return toCustomerListResponse(customers, total), nil
+}
+
+func toPreviewSegmentResponse(p model.SegmentPreview) *customerv1.PreviewSegmentResponse {
+	stores := make([]*customerv1.SegmentStoreCount, len(p.Stores))
+	for i, s := range p.Stores {
+		stores[i] = &customerv1.SegmentStoreCount{
+			StoreId:     s.StoreID,
+			MemberCount: s.MemberCount,
+		}
+	}
+	return &customerv1.PreviewSegmentResponse{
+		MemberCount: p.MemberCount,
+		Stores:      stores,
+	}
+}
@@ -14,6 +14,14 @@ type Customer struct {
 	CreateDate   time.Time
 	LastUpdate   time.Time
 	PasswordHash string // Only populated by GetCustomerByEmail for BFF auth.
+
+	// EmailVerifiedAt is zero until the customer's email has been confirmed.
+	EmailVerifiedAt time.Time
+	// PendingEmail is an email change waiting to be confirmed; Email stays
+	// in use until then.
+	PendingEmail string
+	// AnonymizedAt is set once the customer's personal data has been erased.
+	AnonymizedAt time.Time
 }
 
 // CustomerDetail is an enriched customer with address information.
@@ -28,6 +36,258 @@ type CustomerDetail struct {
 	Phone       string
 }
 
+// CustomerRentalRecord is one rental in a customer's data export.
+type CustomerRentalRecord struct {
+	RentalID   int32
+	RentalDate time.Time
+	ReturnDate time.Time // Zero while the rental is out.
+	StoreID    int32
+	FilmID     int32
+	FilmTitle  string
+}
+
+// CustomerPaymentRecord is one payment in a customer's data export.
+type CustomerPaymentRecord struct {
+	PaymentID   int32
+	RentalID    int32
+	Amount      string
+	NetAmount   string
+	TaxAmount   string
+	ChargeType  string
+	PaymentDate time.Time
+}
+
+// CustomerReviewRecord is one film review in a customer's data export.
+type CustomerReviewRecord struct {
+	ReviewID  int32
+	FilmID    int32
+	FilmTitle string
+	Rating    int32
+	Body      string
+	Status    string
+	CreatedAt time.Time
+}
+
+// CustomerWishlistRecord is one wishlisted film in a customer's data export.
+type CustomerWishlistRecord struct {
+	FilmID    int32
+	FilmTitle string
+	AddedAt   time.Time
+}
+
+// CustomerLoyaltyRecord is a customer's loyalty account in their data export.
+type CustomerLoyaltyRecord struct {
+	Tier           string
+	PointsBalance  int32
+	LifetimePoints int32
+}
+
+// CustomerLoyaltyTransactionRecord is one loyalty points transaction in a
+// customer's data export.
+type CustomerLoyaltyTransactionRecord struct {
+	TransactionID int32
+	Kind          string
+	Points        int32 // negative when spent
+	BalanceAfter  int32
+	PaymentID     int32 // 0 if none
+	RentalID      int32 // 0 if none
+	Note          string
+	CreatedAt     time.Time
+}
+
+// CustomerStoredValueRecord is one gift card or store credit account in a
+// customer's data export.
+type CustomerStoredValueRecord struct {
+	AccountID  int32
+	Kind       string
+	Code       string
+	Balance    string
+	Active     bool
+	CreateDate time.Time
+}
+
+// CustomerStoredValueEntryRecord is one movement of value on a customer's
+// stored value account in their data export.
+type CustomerStoredValueEntryRecord struct {
+	EntryID      int32
+	AccountID    int32
+	Kind         string // transaction kind
+	Amount       string // negative when debited
+	BalanceAfter string
+	PaymentID    int32 // 0 if none
+	Note         string
+	CreatedAt    time.Time
+}
+
+// CustomerSubscriptionRecord is one subscription in a customer's data export.
+type CustomerSubscriptionRecord struct {
+	SubscriptionID     int32
+	PlanName           string
+	Status             string
+	CurrentPeriodStart time.Time
+	CurrentPeriodEnd   time.Time
+	CreateDate         time.Time
+	CanceledAt         time.Time // Zero unless canceled.
+}
+
+// CustomerSubscriptionPaymentRecord is one subscription charge attempt in a
+// customer's data export.
+type CustomerSubscriptionPaymentRecord struct {
+	SubscriptionPaymentID int32
+	SubscriptionID        int32
+	Amount                string
+	PeriodStart           time.Time
+	PeriodEnd             time.Time
+	Status                string
+	CreatedAt             time.Time
+}
+
+// CustomerDataExport is everything held about a customer, for answering
+// a data subject access request.
+type CustomerDataExport struct {
+	Customer             CustomerDetail
+	Rentals              []CustomerRentalRecord
+	Payments             []CustomerPaymentRecord
+	Reviews              []CustomerReviewRecord
+	Wishlist             []CustomerWishlistRecord
+	Loyalty              *CustomerLoyaltyRecord // nil without a loyalty account
+	LoyaltyTransactions  []CustomerLoyaltyTransactionRecord
+	StoredValue          []CustomerStoredValueRecord
+	StoredValueEntries   []CustomerStoredValueEntryRecord
+	Subscriptions        []CustomerSubscriptionRecord
+	SubscriptionPayments []CustomerSubscriptionPaymentRecord
+	ExportedAt           time.Time
+}
+
+// CustomerSummary is a customer's rental activity and lifetime value. The
+// rental dates are zero for customers who have never rented.
+type CustomerSummary struct {
+	CustomerID          int32
+	FirstRentalDate     time.Time
+	LastRentalDate      time.Time
+	TotalRentals        int32
+	OpenRentals         int32
+	OverdueRentals      int32
+	LifetimeSpend       string
+	FavouriteCategories []CategoryRentalCount
+	AverageDaysKept     float64
+}
+
+// CategoryRentalCount is how many times a customer rented films of a
+// category.
+type CategoryRentalCount struct {
+	CategoryID int32
+	Name       string
+	Rentals    int32
+}
+
+// Segment is a named group of customers defined by a rule tree, for
+// targeting campaigns. Its members are the customers the rule matched when
+// it was last evaluated.
+type Segment struct {
+	SegmentID   int32
+	Name        string
+	Description string
+	Rule        SegmentRule
+	MemberCount int32
+	EvaluatedAt time.Time // zero until first evaluated
+	CreatedAt   time.Time
+	LastUpdate  time.Time
+}
+
+// SegmentRule is a node of a segment's rule tree: either a group ("all",
+// "any" or "not") of child Rules, or a condition comparing a customer
+// attribute, Field, with Values. It is stored as JSON.
+type SegmentRule struct {
+	Op       string        `json:"op"`
+	Rules    []SegmentRule `json:"rules,omitempty"`
+	Field    string        `json:"field,omitempty"`
+	Category string        `json:"category,omitempty"`
+	Values   []float64     `json:"values,omitempty"`
+}
+
+// SegmentCandidate is what segment rules can test about a customer.
+// SpendPercentile is the percentage of the customer's store's customers
+// who spent less than them. Categories are ordered most rented first.
+type SegmentCandidate struct {
+	CustomerID      int32
+	StoreID         int32
+	Active          bool
+	LifetimeSpend   float64
+	SpendPercentile float64
+	TotalRentals    int32
+	LastRentalDate  time.Time // zero if never rented
+	Categories      []CategoryRentalCount
+}
+
+// SegmentPreview is how many customers a segment rule matches, in total
+// and per store, without saving the segment.
+type SegmentPreview struct {
+	MemberCount int32
+	Stores      []SegmentStoreCount // ordered by store ID
+}
+
+// SegmentStoreCount is how many customers of a store a segment rule
+// matches.
+type SegmentStoreCount struct {
+	StoreID     int32
+	MemberCount int32
+}
+
+// Customer duplicate statuses. Pairs found by a scan start pending until
+// staff dismiss or merge them.
+const (
+	DuplicateStatusPending   = "pending"
+	DuplicateStatusDismissed = "dismissed"
+	DuplicateStatusMerged    = "merged"
+)
+
+// DuplicateCandidate is a pair of customers who may be the same person,
+// with how similar their names, addresses and phone numbers are, each from
+// 0 to 1, and the weighted Score.
+type DuplicateCandidate struct {
+	CustomerID      int32
+	OtherCustomerID int32
+	Score           float64
+	NameScore       float64
+	AddressScore    float64
+	PhoneScore      float64
+}
+
+// CustomerDuplicate is a pair of customers in the duplicate review queue,
+// lower customer ID first.
+type CustomerDuplicate struct {
+	DuplicateCandidate
+	DuplicateID   int32
+	Customer      CustomerDetail
+	OtherCustomer CustomerDetail
+	Status        string
+	DetectedAt    time.Time
+	ReviewedAt    time.Time // zero while pending
+	ReviewedBy    int32     // staff ID; zero while pending
+}
+
+// CustomerMerge records a duplicate customer being merged into the
+// surviving one, and how much of their history moved over.
+type CustomerMerge struct {
+	MergeID               int32
+	SurvivingCustomerID   int32
+	MergedCustomerID      int32
+	RentalsMoved          int32
+	PaymentsMoved         int32
+	SubscriptionsMoved    int32
+	SubscriptionsCanceled int32 // the surviving customer already had a live one
+	GiftCardsMoved        int32
+	StoreCreditMoved      string // decimal
+	LoyaltyPointsMoved    int32
+	ReviewsMoved          int32
+	ReviewsDropped        int32 // films the surviving customer had also reviewed
+	WishlistItemsMoved    int32
+	PromotionUsesMoved    int32
+	MergedBy              int32
+	MergedAt              time.Time
+}
+
 // Address represents a physical address.
 type Address struct {
 	AddressID  int32
@@ -38,14 +298,16 @@ type Address struct {
 	PostalCode string
 	Phone      string
 	LastUpdate time.Time
+	UserCount  int64 // Customers, stores and staff using it; only populated by GetAddress.
 }
 
 // City represents a city.
 type City struct {
-	CityID     int32
-	City       string
-	CountryID  int32
-	LastUpdate time.Time
+	CityID      int32
+	City        string
+	CountryID   int32
+	CountryName string // Only populated by ListCities.
+	LastUpdate  time.Time
 }
 
 // Country represents a country.
@@ -41,6 +41,7 @@ type AddressRepository interface {
 	CreateAddress(ctx context.Context, params CreateAddressParams) (model.Address, error)
 	UpdateAddress(ctx context.Context, params UpdateAddressParams) (model.Address, error)
 	DeleteAddress(ctx context.Context, addressID int32) error
+	CountAddressUsers(ctx context.Context, addressID int32) (int64, error)
 }
 
 type addressRepository struct {
@@ -139,3 +140,13 @@ func toAddressModel(a customersqlc.Address) model.Address {
 		LastUpdate: timestamptzToTime(a.LastUpdate),
 	}
 }
+
+// CountAddressUsers counts the customers, stores and staff members using
+// an address.
+func (r *addressRepository) CountAddressUsers(ctx context.Context, addressID int32) (int64, error) {
+	count, err := r.q.CountAddressUsers(ctx, addressID)
+	if err != nil {
+		return 0, fmt.Errorf("count address users: %w", err)
+	}
+	return count, nil
+}
@@ -15,8 +15,8 @@ import (
 // CityRepository defines read-only data-access operations for cities.
 type CityRepository interface {
 	GetCity(ctx context.Context, cityID int32) (model.City, error)
-	ListCities(ctx context.Context, limit, offset int32) ([]model.City, error)
-	CountCities(ctx context.Context) (int64, error)
+	ListCities(ctx context.Context, namePattern string, countryID, limit, offset int32) ([]model.City, error)
+	CountCities(ctx context.Context, namePattern string, countryID int32) (int64, error)
 }
 
 type cityRepository struct {
@@ -39,23 +39,36 @@ func (r *cityRepository) GetCity(ctx context.Context, cityID int32) (model.City,
 	return toCityModel(row), nil
 }
 
-func (r *cityRepository) ListCities(ctx context.Context, limit, offset int32) ([]model.City, error) {
+// ListCities returns cities whose name matches the ILIKE pattern
+// namePattern, optionally only those in countryID, with their country name.
+func (r *cityRepository) ListCities(ctx context.Context, namePattern string, countryID, limit, offset int32) ([]model.City, error) {
 	rows, err := r.q.ListCities(ctx, customersqlc.ListCitiesParams{
-		Limit:  limit,
-		Offset: offset,
+		NamePattern: namePattern,
+		CountryID:   countryID,
+		PageLimit:   limit,
+		PageOffset:  offset,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("list cities: %w", err)
 	}
 	cities := make([]model.City, len(rows))
 	for i, row := range rows {
-		cities[i] = toCityModel(row)
+		cities[i] = model.City{
+			CityID:      row.CityID,
+			City:        row.City,
+			CountryID:   row.CountryID,
+			CountryName: row.Country,
+			LastUpdate:  timestamptzToTime(row.LastUpdate),
+		}
 	}
 	return cities, nil
 }
 
-func (r *cityRepository) CountCities(ctx context.Context) (int64, error) {
-	count, err := r.q.CountCities(ctx)
+func (r *cityRepository) CountCities(ctx context.Context, namePattern string, countryID int32) (int64, error) {
+	count, err := r.q.CountCities(ctx, customersqlc.CountCitiesParams{
+		NamePattern: namePattern,
+		CountryID:   countryID,
+	})
 	if err != nil {
 		return 0, fmt.Errorf("count cities: %w", err)
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -26,6 +27,17 @@ type CreateCustomerParams struct {
 	Active    bool
 }
 
+// RegisterCustomerParams holds parameters for a customer signing up: the
+// customer and their new address.
+type RegisterCustomerParams struct {
+	StoreID      int32
+	FirstName    string
+	LastName     string
+	Email        string
+	PasswordHash string
+	Address      CreateAddressParams
+}
+
 // UpdateCustomerParams holds parameters for updating a customer.
 type UpdateCustomerParams struct {
 	CustomerID int32
@@ -37,6 +49,15 @@ type UpdateCustomerParams struct {
 	Active     bool
 }
 
+// CustomerSearch holds the criteria of a customer search.
+type CustomerSearch struct {
+	Query         string // Matched fuzzily against the full name.
+	PrefixPattern string // ILIKE pattern for names and email.
+	PhonePattern  string // LIKE pattern for phone digits; "" skips phone matching.
+	StoreID       int32  // 0 for all stores.
+	Active        *bool  // nil for active and inactive customers.
+}
+
 // CustomerRepository defines data-access operations for customers.
 type CustomerRepository interface {
 	GetCustomer(ctx context.Context, customerID int32) (model.Customer, error)
@@ -45,18 +66,37 @@ type CustomerRepository interface {
 	CountCustomers(ctx context.Context) (int64, error)
 	ListCustomersByStore(ctx context.Context, storeID, limit, offset int32) ([]model.Customer, error)
 	CountCustomersByStore(ctx context.Context, storeID int32) (int64, error)
+	SearchCustomers(ctx context.Context, search CustomerSearch, limit, offset int32) ([]model.Customer, error)
+	CountSearchCustomers(ctx context.Context, search CustomerSearch) (int64, error)
 	CreateCustomer(ctx context.Context, params CreateCustomerParams) (model.Customer, error)
+	RegisterCustomer(ctx context.Context, params RegisterCustomerParams) (model.Customer, error)
 	UpdateCustomer(ctx context.Context, params UpdateCustomerParams) (model.Customer, error)
-	DeleteCustomer(ctx context.Context, customerID int32) error
+	ChangeCustomerAddress(ctx context.Context, customerID int32, params CreateAddressParams) (model.Customer, error)
+	UpdateCustomerPassword(ctx context.Context, customerID int32, passwordHash string) error
+	SetCustomerPendingEmail(ctx context.Context, customerID int32, email string) (model.Customer, error)
+	VerifyCustomerEmail(ctx context.Context, customerID int32, email string) (model.Customer, error)
+	AnonymizeCustomer(ctx context.Context, customerID int32) (model.Customer, error)
+	ListCustomerRentalHistory(ctx context.Context, customerID int32) ([]model.CustomerRentalRecord, error)
+	ListCustomerPaymentHistory(ctx context.Context, customerID int32) ([]model.CustomerPaymentRecord, error)
+	ListCustomerReviewHistory(ctx context.Context, customerID int32) ([]model.CustomerReviewRecord, error)
+	ListCustomerWishlistHistory(ctx context.Context, customerID int32) ([]model.CustomerWishlistRecord, error)
+	GetCustomerLoyaltyRecord(ctx context.Context, customerID int32) (model.CustomerLoyaltyRecord, error)
+	ListCustomerLoyaltyHistory(ctx context.Context, customerID int32) ([]model.CustomerLoyaltyTransactionRecord, error)
+	ListCustomerStoredValueAccounts(ctx context.Context, customerID int32) ([]model.CustomerStoredValueRecord, error)
+	ListCustomerStoredValueHistory(ctx context.Context, customerID int32) ([]model.CustomerStoredValueEntryRecord, error)
+	ListCustomerSubscriptionHistory(ctx context.Context, customerID int32) ([]model.CustomerSubscriptionRecord, error)
+	ListCustomerSubscriptionPaymentHistory(ctx context.Context, customerID int32) ([]model.CustomerSubscriptionPaymentRecord, error)
+	ListCustomerSummaries(ctx context.Context, customerIDs []int32, maxCategories int32) ([]model.CustomerSummary, error)
 }
 
 type customerRepository struct {
-	q *customersqlc.Queries
+	pool *pgxpool.Pool
+	q    *customersqlc.Queries
 }
 
 // NewCustomerRepository creates a new CustomerRepository.
 func NewCustomerRepository(pool *pgxpool.Pool) CustomerRepository {
-	return &customerRepository{q: customersqlc.New(pool)}
+	return &customerRepository{pool: pool, q: customersqlc.New(pool)}
 }
 
 func (r *customerRepository) GetCustomer(ctx context.Context, customerID int32) (model.Customer, error) {
@@ -68,11 +108,12 @@ func (r *customerRepository) GetCustomer(ctx context.Context, customerID int32)
 		return model.Customer{}, fmt.Errorf("get customer: %w", err)
 	}
 	return toCustomerModel(row.CustomerID, row.StoreID, row.FirstName, row.LastName,
-		row.Email, row.AddressID, row.Activebool, row.CreateDate, row.LastUpdate), nil
+		row.Email, row.AddressID, row.Activebool, row.CreateDate, row.LastUpdate,
+		row.EmailVerifiedAt, row.PendingEmail, row.AnonymizedAt), nil
 }
 
 func (r *customerRepository) GetCustomerByEmail(ctx context.Context, email string) (model.Customer, error) {
-	row, err := r.q.GetCustomerByEmail(ctx, stringToText(email))
+	row, err := r.q.GetCustomerByEmail(ctx, email)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return model.Customer{}, ErrNotFound
@@ -80,7 +121,8 @@ func (r *customerRepository) GetCustomerByEmail(ctx context.Context, email strin
 		return model.Customer{}, fmt.Errorf("get customer by email: %w", err)
 	}
 	c := toCustomerModel(row.CustomerID, row.StoreID, row.FirstName, row.LastName,
-		row.Email, row.AddressID, row.Activebool, row.CreateDate, row.LastUpdate)
+		row.Email, row.AddressID, row.Activebool, row.CreateDate, row.LastUpdate,
+		row.EmailVerifiedAt, row.PendingEmail, row.AnonymizedAt)
 	c.PasswordHash = textToString(row.PasswordHash)
 	return c, nil
 }
@@ -96,7 +138,8 @@ func (r *customerRepository) ListCustomers(ctx context.Context, limit, offset in
 	customers := make([]model.Customer, len(rows))
 	for i, row := range rows {
 		customers[i] = toCustomerModel(row.CustomerID, row.StoreID, row.FirstName, row.LastName,
-			row.Email, row.AddressID, row.Activebool, row.CreateDate, row.LastUpdate)
+			row.Email, row.AddressID, row.Activebool, row.CreateDate, row.LastUpdate,
+			row.EmailVerifiedAt, row.PendingEmail, row.AnonymizedAt)
 	}
 	return customers, nil
 }
@@ -121,7 +164,8 @@ func (r *customerRepository) ListCustomersByStore(ctx context.Context, storeID,
 	customers := make([]model.Customer, len(rows))
 	for i, row := range rows {
 		customers[i] = toCustomerModel(row.CustomerID, row.StoreID, row.FirstName, row.LastName,
-			row.Email, row.AddressID, row.Activebool, row.CreateDate, row.LastUpdate)
+			row.Email, row.AddressID, row.Activebool, row.CreateDate, row.LastUpdate,
+			row.EmailVerifiedAt, row.PendingEmail, row.AnonymizedAt)
 	}
 	return customers, nil
 }
@@ -134,6 +178,42 @@ func (r *customerRepository) CountCustomersByStore(ctx context.Context, storeID
 	return count, nil
 }
 
+func (r *customerRepository) SearchCustomers(ctx context.Context, search CustomerSearch, limit, offset int32) ([]model.Customer, error) {
+	rows, err := r.q.SearchCustomers(ctx, customersqlc.SearchCustomersParams{
+		PrefixPattern: search.PrefixPattern,
+		PhonePattern:  search.PhonePattern,
+		Query:         search.Query,
+		StoreID:       search.StoreID,
+		Active:        boolPtrToBool(search.Active),
+		PageLimit:     limit,
+		PageOffset:    offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("search customers: %w", err)
+	}
+	customers := make([]model.Customer, len(rows))
+	for i, row := range rows {
+		customers[i] = toCustomerModel(row.CustomerID, row.StoreID, row.FirstName, row.LastName,
+			row.Email, row.AddressID, row.Activebool, row.CreateDate, row.LastUpdate,
+			row.EmailVerifiedAt, row.PendingEmail, row.AnonymizedAt)
+	}
+	return customers, nil
+}
+
+func (r *customerRepository) CountSearchCustomers(ctx context.Context, search CustomerSearch) (int64, error) {
+	count, err := r.q.CountSearchCustomers(ctx, customersqlc.CountSearchCustomersParams{
+		PrefixPattern: search.PrefixPattern,
+		PhonePattern:  search.PhonePattern,
+		Query:         search.Query,
+		StoreID:       search.StoreID,
+		Active:        boolPtrToBool(search.Active),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("count search customers: %w", err)
+	}
+	return count, nil
+}
+
 func (r *customerRepository) CreateCustomer(ctx context.Context, params CreateCustomerParams) (model.Customer, error) {
 	row, err := r.q.CreateCustomer(ctx, customersqlc.CreateCustomerParams{
 		StoreID:    params.StoreID,
@@ -148,7 +228,117 @@ func (r *customerRepository) CreateCustomer(ctx context.Context, params CreateCu
 		return model.Customer{}, fmt.Errorf("create customer: %w", err)
 	}
 	return toCustomerModel(row.CustomerID, row.StoreID, row.FirstName, row.LastName,
-		row.Email, row.AddressID, row.Activebool, row.CreateDate, row.LastUpdate), nil
+		row.Email, row.AddressID, row.Activebool, row.CreateDate, row.LastUpdate,
+		row.EmailVerifiedAt, row.PendingEmail, row.AnonymizedAt), nil
+}
+
+// RegisterCustomer creates the customer's address and the customer in one
+// transaction.
+func (r *customerRepository) RegisterCustomer(ctx context.Context, params RegisterCustomerParams) (model.Customer, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return model.Customer{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.q.WithTx(tx)
+	addr, err := q.CreateAddress(ctx, customersqlc.CreateAddressParams{
+		Address:    params.Address.Address,
+		Address2:   stringToText(params.Address.Address2),
+		District:   params.Address.District,
+		CityID:     params.Address.CityID,
+		PostalCode: stringToText(params.Address.PostalCode),
+		Phone:      params.Address.Phone,
+	})
+	if err != nil {
+		return model.Customer{}, fmt.Errorf("create address: %w", err)
+	}
+	row, err := q.RegisterCustomer(ctx, customersqlc.RegisterCustomerParams{
+		StoreID:      params.StoreID,
+		FirstName:    params.FirstName,
+		LastName:     params.LastName,
+		Email:        stringToText(params.Email),
+		AddressID:    addr.AddressID,
+		PasswordHash: stringToText(params.PasswordHash),
+	})
+	if err != nil {
+		return model.Customer{}, fmt.Errorf("register customer: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return model.Customer{}, fmt.Errorf("commit tx: %w", err)
+	}
+	return toCustomerModel(row.CustomerID, row.StoreID, row.FirstName, row.LastName,
+		row.Email, row.AddressID, row.Activebool, row.CreateDate, row.LastUpdate,
+		row.EmailVerifiedAt, row.PendingEmail, row.AnonymizedAt), nil
+}
+
+// ChangeCustomerAddress sets a customer's address in one transaction. An
+// address only the customer uses is updated in place; a shared one, such
+// as a household's, is left alone and the customer gets a new address.
+func (r *customerRepository) ChangeCustomerAddress(ctx context.Context, customerID int32, params CreateAddressParams) (model.Customer, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return model.Customer{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.q.WithTx(tx)
+	addressID, err := q.GetCustomerAddressIDForUpdate(ctx, customerID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Customer{}, ErrNotFound
+		}
+		return model.Customer{}, fmt.Errorf("lock customer: %w", err)
+	}
+	if err := q.LockAddress(ctx, addressID); err != nil {
+		return model.Customer{}, fmt.Errorf("lock address: %w", err)
+	}
+	users, err := q.CountAddressUsers(ctx, addressID)
+	if err != nil {
+		return model.Customer{}, fmt.Errorf("count address users: %w", err)
+	}
+
+	if users <= 1 {
+		if _, err := q.UpdateAddress(ctx, customersqlc.UpdateAddressParams{
+			AddressID:  addressID,
+			Address:    params.Address,
+			Address2:   stringToText(params.Address2),
+			District:   params.District,
+			CityID:     params.CityID,
+			PostalCode: stringToText(params.PostalCode),
+			Phone:      params.Phone,
+		}); err != nil {
+			return model.Customer{}, fmt.Errorf("update address: %w", err)
+		}
+	} else {
+		addr, err := q.CreateAddress(ctx, customersqlc.CreateAddressParams{
+			Address:    params.Address,
+			Address2:   stringToText(params.Address2),
+			District:   params.District,
+			CityID:     params.CityID,
+			PostalCode: stringToText(params.PostalCode),
+			Phone:      params.Phone,
+		})
+		if err != nil {
+			return model.Customer{}, fmt.Errorf("create address: %w", err)
+		}
+		addressID = addr.AddressID
+	}
+	row, err := q.SetCustomerAddress(ctx, customersqlc.SetCustomerAddressParams{
+		AddressID:  addressID,
+		CustomerID: customerID,
+	})
+	if err != nil {
+		return model.Customer{}, fmt.Errorf("set customer address: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return model.Customer{}, fmt.Errorf("commit tx: %w", err)
+	}
+	return toCustomerModel(row.CustomerID, row.StoreID, row.FirstName, row.LastName,
+		row.Email, row.AddressID, row.Activebool, row.CreateDate, row.LastUpdate,
+		row.EmailVerifiedAt, row.PendingEmail, row.AnonymizedAt), nil
 }
 
 func (r *customerRepository) UpdateCustomer(ctx context.Context, params UpdateCustomerParams) (model.Customer, error) {
@@ -169,16 +359,376 @@ func (r *customerRepository) UpdateCustomer(ctx context.Context, params UpdateCu
 		return model.Customer{}, fmt.Errorf("update customer: %w", err)
 	}
 	return toCustomerModel(row.CustomerID, row.StoreID, row.FirstName, row.LastName,
-		row.Email, row.AddressID, row.Activebool, row.CreateDate, row.LastUpdate), nil
+		row.Email, row.AddressID, row.Activebool, row.CreateDate, row.LastUpdate,
+		row.EmailVerifiedAt, row.PendingEmail, row.AnonymizedAt), nil
 }
 
-func (r *customerRepository) DeleteCustomer(ctx context.Context, customerID int32) error {
-	if err := r.q.DeleteCustomer(ctx, customerID); err != nil {
-		return fmt.Errorf("delete customer: %w", err)
+func (r *customerRepository) UpdateCustomerPassword(ctx context.Context, customerID int32, passwordHash string) error {
+	_, err := r.q.UpdateCustomerPassword(ctx, customersqlc.UpdateCustomerPasswordParams{
+		CustomerID:   customerID,
+		PasswordHash: stringToText(passwordHash),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("update customer password: %w", err)
 	}
 	return nil
 }
 
+func (r *customerRepository) SetCustomerPendingEmail(ctx context.Context, customerID int32, email string) (model.Customer, error) {
+	row, err := r.q.SetCustomerPendingEmail(ctx, customersqlc.SetCustomerPendingEmailParams{
+		CustomerID:   customerID,
+		PendingEmail: email,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Customer{}, ErrNotFound
+		}
+		return model.Customer{}, fmt.Errorf("set customer pending email: %w", err)
+	}
+	return toCustomerModel(row.CustomerID, row.StoreID, row.FirstName, row.LastName,
+		row.Email, row.AddressID, row.Activebool, row.CreateDate, row.LastUpdate,
+		row.EmailVerifiedAt, row.PendingEmail, row.AnonymizedAt), nil
+}
+
+// VerifyCustomerEmail confirms email for a customer. It returns
+// ErrNotFound if the customer does not exist or email is neither their
+// pending email nor their unverified current one.
+func (r *customerRepository) VerifyCustomerEmail(ctx context.Context, customerID int32, email string) (model.Customer, error) {
+	row, err := r.q.VerifyCustomerEmail(ctx, customersqlc.VerifyCustomerEmailParams{
+		CustomerID: customerID,
+		Email:      email,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Customer{}, ErrNotFound
+		}
+		return model.Customer{}, fmt.Errorf("verify customer email: %w", err)
+	}
+	return toCustomerModel(row.CustomerID, row.StoreID, row.FirstName, row.LastName,
+		row.Email, row.AddressID, row.Activebool, row.CreateDate, row.LastUpdate,
+		row.EmailVerifiedAt, row.PendingEmail, row.AnonymizedAt), nil
+}
+
+// AnonymizeCustomer scrubs a customer's personal data in one transaction.
+// Their address is scrubbed in place if no one else uses it; otherwise the
+// customer is moved to a new, empty address in the same city. Their wishlist
+// and reviews are deleted, their live subscription is canceled, their
+// loyalty points are forfeited, their store credit is deactivated and their
+// gift cards are unlinked from them but stay spendable.
+func (r *customerRepository) AnonymizeCustomer(ctx context.Context, customerID int32) (model.Customer, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return model.Customer{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.q.WithTx(tx)
+	addressID, err := q.GetCustomerAddressIDForUpdate(ctx, customerID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Customer{}, ErrNotFound
+		}
+		return model.Customer{}, fmt.Errorf("get customer: %w", err)
+	}
+
+	users, err := q.CountAddressUsers(ctx, addressID)
+	if err != nil {
+		return model.Customer{}, fmt.Errorf("count address users: %w", err)
+	}
+	if users <= 1 {
+		if err := q.AnonymizeAddress(ctx, addressID); err != nil {
+			return model.Customer{}, fmt.Errorf("anonymize address: %w", err)
+		}
+	} else {
+		addressID, err = q.CreateAnonymizedAddress(ctx, addressID)
+		if err != nil {
+			return model.Customer{}, fmt.Errorf("create anonymized address: %w", err)
+		}
+	}
+
+	row, err := q.AnonymizeCustomer(ctx, customersqlc.AnonymizeCustomerParams{
+		CustomerID: customerID,
+		AddressID:  addressID,
+	})
+	if err != nil {
+		return model.Customer{}, fmt.Errorf("anonymize customer: %w", err)
+	}
+	if err := q.DeleteCustomerWishlist(ctx, customerID); err != nil {
+		return model.Customer{}, fmt.Errorf("delete customer wishlist: %w", err)
+	}
+
+	filmIDs, err := q.DeleteCustomerReviews(ctx, customerID)
+	if err != nil {
+		return model.Customer{}, fmt.Errorf("delete customer reviews: %w", err)
+	}
+	// Lock the films' ratings in a fixed order so concurrent erasures
+	// cannot deadlock.
+	sort.Slice(filmIDs, func(i, j int) bool { return filmIDs[i] < filmIDs[j] })
+	for _, filmID := range filmIDs {
+		if err := q.LockFilmRating(ctx, filmID); err != nil {
+			return model.Customer{}, fmt.Errorf("lock film rating: %w", err)
+		}
+		if err := q.RefreshFilmRating(ctx, filmID); err != nil {
+			return model.Customer{}, fmt.Errorf("refresh film rating: %w", err)
+		}
+	}
+	if err := q.CancelCustomerSubscriptions(ctx, customerID); err != nil {
+		return model.Customer{}, fmt.Errorf("cancel customer subscriptions: %w", err)
+	}
+	if err := q.ForfeitCustomerLoyaltyPoints(ctx, customerID); err != nil {
+		return model.Customer{}, fmt.Errorf("forfeit customer loyalty points: %w", err)
+	}
+	if err := q.DeactivateCustomerStoreCredit(ctx, customerID); err != nil {
+		return model.Customer{}, fmt.Errorf("deactivate customer store credit: %w", err)
+	}
+	if err := q.UnlinkCustomerGiftCards(ctx, customerID); err != nil {
+		return model.Customer{}, fmt.Errorf("unlink customer gift cards: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return model.Customer{}, fmt.Errorf("commit tx: %w", err)
+	}
+	return toCustomerModel(row.CustomerID, row.StoreID, row.FirstName, row.LastName,
+		row.Email, row.AddressID, row.Activebool, row.CreateDate, row.LastUpdate,
+		row.EmailVerifiedAt, row.PendingEmail, row.AnonymizedAt), nil
+}
+
+func (r *customerRepository) ListCustomerRentalHistory(ctx context.Context, customerID int32) ([]model.CustomerRentalRecord, error) {
+	rows, err := r.q.ListCustomerRentalHistory(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("list customer rental history: %w", err)
+	}
+	rentals := make([]model.CustomerRentalRecord, len(rows))
+	for i, row := range rows {
+		rentals[i] = model.CustomerRentalRecord{
+			RentalID:   row.RentalID,
+			RentalDate: timestamptzToTime(row.RentalDate),
+			ReturnDate: timestamptzToTime(row.ReturnDate),
+			StoreID:    row.StoreID,
+			FilmID:     row.FilmID,
+			FilmTitle:  row.Title,
+		}
+	}
+	return rentals, nil
+}
+
+func (r *customerRepository) ListCustomerPaymentHistory(ctx context.Context, customerID int32) ([]model.CustomerPaymentRecord, error) {
+	rows, err := r.q.ListCustomerPaymentHistory(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("list customer payment history: %w", err)
+	}
+	payments := make([]model.CustomerPaymentRecord, len(rows))
+	for i, row := range rows {
+		payments[i] = model.CustomerPaymentRecord{
+			PaymentID:   row.PaymentID,
+			RentalID:    row.RentalID,
+			Amount:      row.Amount,
+			NetAmount:   row.NetAmount,
+			TaxAmount:   row.TaxAmount,
+			ChargeType:  row.ChargeType,
+			PaymentDate: timestamptzToTime(row.PaymentDate),
+		}
+	}
+	return payments, nil
+}
+
+func (r *customerRepository) ListCustomerReviewHistory(ctx context.Context, customerID int32) ([]model.CustomerReviewRecord, error) {
+	rows, err := r.q.ListCustomerReviewHistory(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("list customer review history: %w", err)
+	}
+	reviews := make([]model.CustomerReviewRecord, len(rows))
+	for i, row := range rows {
+		reviews[i] = model.CustomerReviewRecord{
+			ReviewID:  row.ReviewID,
+			FilmID:    row.FilmID,
+			FilmTitle: row.Title,
+			Rating:    int32(row.Rating),
+			Body:      row.Body,
+			Status:    row.Status,
+			CreatedAt: timestamptzToTime(row.CreatedAt),
+		}
+	}
+	return reviews, nil
+}
+
+func (r *customerRepository) ListCustomerWishlistHistory(ctx context.Context, customerID int32) ([]model.CustomerWishlistRecord, error) {
+	rows, err := r.q.ListCustomerWishlistHistory(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("list customer wishlist history: %w", err)
+	}
+	items := make([]model.CustomerWishlistRecord, len(rows))
+	for i, row := range rows {
+		items[i] = model.CustomerWishlistRecord{
+			FilmID:    row.FilmID,
+			FilmTitle: row.Title,
+			AddedAt:   timestamptzToTime(row.AddedAt),
+		}
+	}
+	return items, nil
+}
+
+func (r *customerRepository) GetCustomerLoyaltyRecord(ctx context.Context, customerID int32) (model.CustomerLoyaltyRecord, error) {
+	row, err := r.q.GetCustomerLoyaltyRecord(ctx, customerID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.CustomerLoyaltyRecord{}, ErrNotFound
+		}
+		return model.CustomerLoyaltyRecord{}, fmt.Errorf("get customer loyalty record: %w", err)
+	}
+	return model.CustomerLoyaltyRecord{
+		Tier:           row.Tier,
+		PointsBalance:  row.PointsBalance,
+		LifetimePoints: row.LifetimePoints,
+	}, nil
+}
+
+func (r *customerRepository) ListCustomerLoyaltyHistory(ctx context.Context, customerID int32) ([]model.CustomerLoyaltyTransactionRecord, error) {
+	rows, err := r.q.ListCustomerLoyaltyHistory(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("list customer loyalty history: %w", err)
+	}
+	transactions := make([]model.CustomerLoyaltyTransactionRecord, len(rows))
+	for i, row := range rows {
+		transactions[i] = model.CustomerLoyaltyTransactionRecord{
+			TransactionID: row.LoyaltyTransactionID,
+			Kind:          row.Kind,
+			Points:        row.Points,
+			BalanceAfter:  row.BalanceAfter,
+			PaymentID:     int4ToInt32(row.PaymentID),
+			RentalID:      int4ToInt32(row.RentalID),
+			Note:          row.Note,
+			CreatedAt:     timestamptzToTime(row.CreatedAt),
+		}
+	}
+	return transactions, nil
+}
+
+func (r *customerRepository) ListCustomerStoredValueAccounts(ctx context.Context, customerID int32) ([]model.CustomerStoredValueRecord, error) {
+	rows, err := r.q.ListCustomerStoredValueAccounts(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("list customer stored value accounts: %w", err)
+	}
+	accounts := make([]model.CustomerStoredValueRecord, len(rows))
+	for i, row := range rows {
+		accounts[i] = model.CustomerStoredValueRecord{
+			AccountID:  row.AccountID,
+			Kind:       row.Kind,
+			Code:       row.Code,
+			Balance:    row.Balance,
+			Active:     row.Active,
+			CreateDate: timestamptzToTime(row.CreateDate),
+		}
+	}
+	return accounts, nil
+}
+
+func (r *customerRepository) ListCustomerStoredValueHistory(ctx context.Context, customerID int32) ([]model.CustomerStoredValueEntryRecord, error) {
+	rows, err := r.q.ListCustomerStoredValueHistory(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("list customer stored value history: %w", err)
+	}
+	entries := make([]model.CustomerStoredValueEntryRecord, len(rows))
+	for i, row := range rows {
+		entries[i] = model.CustomerStoredValueEntryRecord{
+			EntryID:      row.EntryID,
+			AccountID:    row.AccountID,
+			Kind:         row.Kind,
+			Amount:       row.Amount,
+			BalanceAfter: row.BalanceAfter,
+			PaymentID:    int4ToInt32(row.PaymentID),
+			Note:         row.Note,
+			CreatedAt:    timestamptzToTime(row.CreatedAt),
+		}
+	}
+	return entries, nil
+}
+
+func (r *customerRepository) ListCustomerSubscriptionHistory(ctx context.Context, customerID int32) ([]model.CustomerSubscriptionRecord, error) {
+	rows, err := r.q.ListCustomerSubscriptionHistory(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("list customer subscription history: %w", err)
+	}
+	subs := make([]model.CustomerSubscriptionRecord, len(rows))
+	for i, row := range rows {
+		subs[i] = model.CustomerSubscriptionRecord{
+			SubscriptionID:     row.SubscriptionID,
+			PlanName:           row.PlanName,
+			Status:             row.Status,
+			CurrentPeriodStart: timestamptzToTime(row.CurrentPeriodStart),
+			CurrentPeriodEnd:   timestamptzToTime(row.CurrentPeriodEnd),
+			CreateDate:         timestamptzToTime(row.CreateDate),
+			CanceledAt:         timestamptzToTime(row.CanceledAt),
+		}
+	}
+	return subs, nil
+}
+
+func (r *customerRepository) ListCustomerSubscriptionPaymentHistory(ctx context.Context, customerID int32) ([]model.CustomerSubscriptionPaymentRecord, error) {
+	rows, err := r.q.ListCustomerSubscriptionPaymentHistory(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("list customer subscription payment history: %w", err)
+	}
+	payments := make([]model.CustomerSubscriptionPaymentRecord, len(rows))
+	for i, row := range rows {
+		payments[i] = model.CustomerSubscriptionPaymentRecord{
+			SubscriptionPaymentID: row.SubscriptionPaymentID,
+			SubscriptionID:        row.SubscriptionID,
+			Amount:                row.Amount,
+			PeriodStart:           timestamptzToTime(row.PeriodStart),
+			PeriodEnd:             timestamptzToTime(row.PeriodEnd),
+			Status:                row.Status,
+			CreatedAt:             timestamptzToTime(row.CreatedAt),
+		}
+	}
+	return payments, nil
+}
+
+// ListCustomerSummaries returns the summaries of the customers in
+// customerIDs that exist, ordered by customer ID, each with up to
+// maxCategories favourite categories.
+func (r *customerRepository) ListCustomerSummaries(ctx context.Context, customerIDs []int32, maxCategories int32) ([]model.CustomerSummary, error) {
+	rows, err := r.q.ListCustomerSummaries(ctx, customerIDs)
+	if err != nil {
+		return nil, fmt.Errorf("list customer summaries: %w", err)
+	}
+	catRows, err := r.q.ListCustomerFavouriteCategories(ctx, customersqlc.ListCustomerFavouriteCategoriesParams{
+		CustomerIds:    customerIDs,
+		MaxPerCustomer: maxCategories,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list customer favourite categories: %w", err)
+	}
+
+	categories := make(map[int32][]model.CategoryRentalCount)
+	for _, row := range catRows {
+		categories[row.CustomerID] = append(categories[row.CustomerID], model.CategoryRentalCount{
+			CategoryID: row.CategoryID,
+			Name:       row.Name,
+			Rentals:    row.Rentals,
+		})
+	}
+
+	summaries := make([]model.CustomerSummary, len(rows))
+	for i, row := range rows {
+		summaries[i] = model.CustomerSummary{
+			CustomerID:          row.CustomerID,
+			FirstRentalDate:     timestamptzToTime(row.FirstRentalDate),
+			LastRentalDate:      timestamptzToTime(row.LastRentalDate),
+			TotalRentals:        row.TotalRentals,
+			OpenRentals:         row.OpenRentals,
+			OverdueRentals:      row.OverdueRentals,
+			LifetimeSpend:       row.LifetimeSpend,
+			FavouriteCategories: categories[row.CustomerID],
+			AverageDaysKept:     row.AverageDaysKept,
+		}
+	}
+	return summaries, nil
+}
+
 func toCustomerModel(
 	customerID, storeID int32,
 	firstName, lastName string,
@@ -187,16 +737,22 @@ func toCustomerModel(
 	activebool bool,
 	createDate pgtype.Date,
 	lastUpdate pgtype.Timestamptz,
+	emailVerifiedAt pgtype.Timestamptz,
+	pendingEmail pgtype.Text,
+	anonymizedAt pgtype.Timestamptz,
 ) model.Customer {
 	return model.Customer{
-		CustomerID: customerID,
-		StoreID:    storeID,
-		FirstName:  firstName,
-		LastName:   lastName,
-		Email:      textToString(email),
-		AddressID:  addressID,
-		Active:     activebool,
-		CreateDate: dateToTime(createDate),
-		LastUpdate: timestamptzToTime(lastUpdate),
+		CustomerID:      customerID,
+		StoreID:         storeID,
+		FirstName:       firstName,
+		LastName:        lastName,
+		Email:           textToString(email),
+		AddressID:       addressID,
+		Active:          activebool,
+		CreateDate:      dateToTime(createDate),
+		LastUpdate:      timestamptzToTime(lastUpdate),
+		EmailVerifiedAt: timestamptzToTime(emailVerifiedAt),
+		PendingEmail:    textToString(pendingEmail),
+		AnonymizedAt:    timestamptzToTime(anonymizedAt),
 	}
 }
@@ -0,0 +1,441 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/enkaigaku/dvd-rental/internal/customer/model"
+	"github.com/enkaigaku/dvd-rental/gen/sqlc/customer"
+)
+
+// ErrNotMergeable is returned by MergeCustomers when either customer has
+// been anonymized or already merged into someone else.
+var ErrNotMergeable = errors.New("anonymized or already merged")
+
+// DuplicateRepository defines the data access interface for finding,
+// reviewing and merging duplicate customers.
+type DuplicateRepository interface {
+	ListDuplicateCandidates(ctx context.Context) ([]model.DuplicateCandidate, error)
+	ReplacePendingDuplicates(ctx context.Context, candidates []model.DuplicateCandidate) error
+	GetCustomerDuplicate(ctx context.Context, duplicateID int32) (model.CustomerDuplicate, error)
+	ListCustomerDuplicatesByStatus(ctx context.Context, status string, limit, offset int32) ([]model.CustomerDuplicate, error)
+	CountCustomerDuplicatesByStatus(ctx context.Context, status string) (int64, error)
+	DismissCustomerDuplicate(ctx context.Context, duplicateID, staffID int32) (bool, error)
+	MergeCustomers(ctx context.Context, survivingCustomerID, mergedCustomerID, staffID int32) (model.CustomerMerge, error)
+}
+
+type duplicateRepository struct {
+	pool *pgxpool.Pool
+	q    *customersqlc.Queries
+}
+
+// NewDuplicateRepository creates a new DuplicateRepository backed by
+// PostgreSQL.
+func NewDuplicateRepository(pool *pgxpool.Pool) DuplicateRepository {
+	return &duplicateRepository{pool: pool, q: customersqlc.New(pool)}
+}
+
+// ListDuplicateCandidates returns every pair of customers similar enough
+// to be worth scoring; Score is left for the caller to weigh.
+func (r *duplicateRepository) ListDuplicateCandidates(ctx context.Context) ([]model.DuplicateCandidate, error) {
+	rows, err := r.q.ListDuplicateCandidates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list duplicate candidates: %w", err)
+	}
+	candidates := make([]model.DuplicateCandidate, len(rows))
+	for i, row := range rows {
+		candidates[i] = model.DuplicateCandidate{
+			CustomerID:      row.CustomerID,
+			OtherCustomerID: row.OtherCustomerID,
+			NameScore:       row.NameScore,
+			AddressScore:    row.AddressScore,
+			PhoneScore:      row.PhoneScore,
+		}
+	}
+	return candidates, nil
+}
+
+// ReplacePendingDuplicates makes candidates the pending part of the review
+// queue, in one transaction: new pairs are queued, pending pairs get their
+// new scores and pending pairs not among candidates are dropped. Dismissed
+// and merged pairs are kept as they are.
+func (r *duplicateRepository) ReplacePendingDuplicates(ctx context.Context, candidates []model.DuplicateCandidate) error {
+	params := customersqlc.UpsertCustomerDuplicatesParams{
+		CustomerIds:      make([]int32, len(candidates)),
+		OtherCustomerIds: make([]int32, len(candidates)),
+		Scores:           make([]float64, len(candidates)),
+		NameScores:       make([]float64, len(candidates)),
+		AddressScores:    make([]float64, len(candidates)),
+		PhoneScores:      make([]float64, len(candidates)),
+	}
+	for i, c := range candidates {
+		params.CustomerIds[i] = c.CustomerID
+		params.OtherCustomerIds[i] = c.OtherCustomerID
+		params.Scores[i] = c.Score
+		params.NameScores[i] = c.NameScore
+		params.AddressScores[i] = c.AddressScore
+		params.PhoneScores[i] = c.PhoneScore
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.q.WithTx(tx)
+	if err := q.DeleteStaleCustomerDuplicates(ctx, customersqlc.DeleteStaleCustomerDuplicatesParams{
+		CustomerIds:      params.CustomerIds,
+		OtherCustomerIds: params.OtherCustomerIds,
+	}); err != nil {
+		return fmt.Errorf("delete stale customer duplicates: %w", err)
+	}
+	if err := q.UpsertCustomerDuplicates(ctx, params); err != nil {
+		return fmt.Errorf("upsert customer duplicates: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+func (r *duplicateRepository) GetCustomerDuplicate(ctx context.Context, duplicateID int32) (model.CustomerDuplicate, error) {
+	row, err := r.q.GetCustomerDuplicate(ctx, duplicateID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.CustomerDuplicate{}, ErrNotFound
+		}
+		return model.CustomerDuplicate{}, fmt.Errorf("get customer duplicate: %w", err)
+	}
+	duplicates, err := r.withCustomerDetails(ctx, []customersqlc.GetCustomerDuplicateRow{row})
+	if err != nil {
+		return model.CustomerDuplicate{}, err
+	}
+	return duplicates[0], nil
+}
+
+func (r *duplicateRepository) ListCustomerDuplicatesByStatus(ctx context.Context, status string, limit, offset int32) ([]model.CustomerDuplicate, error) {
+	rows, err := r.q.ListCustomerDuplicatesByStatus(ctx, customersqlc.ListCustomerDuplicatesByStatusParams{
+		Status:     status,
+		PageOffset: offset,
+		PageLimit:  limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list customer duplicates: %w", err)
+	}
+	dupRows := make([]customersqlc.GetCustomerDuplicateRow, len(rows))
+	for i, row := range rows {
+		dupRows[i] = customersqlc.GetCustomerDuplicateRow(row)
+	}
+	return r.withCustomerDetails(ctx, dupRows)
+}
+
+func (r *duplicateRepository) CountCustomerDuplicatesByStatus(ctx context.Context, status string) (int64, error) {
+	count, err := r.q.CountCustomerDuplicatesByStatus(ctx, status)
+	if err != nil {
+		return 0, fmt.Errorf("count customer duplicates: %w", err)
+	}
+	return count, nil
+}
+
+// DismissCustomerDuplicate marks a pending pair as not duplicates. It
+// reports false if the pair does not exist or is no longer pending.
+func (r *duplicateRepository) DismissCustomerDuplicate(ctx context.Context, duplicateID, staffID int32) (bool, error) {
+	n, err := r.q.DismissCustomerDuplicate(ctx, customersqlc.DismissCustomerDuplicateParams{
+		StaffID:     staffID,
+		DuplicateID: duplicateID,
+	})
+	if err != nil {
+		return false, fmt.Errorf("dismiss customer duplicate: %w", err)
+	}
+	return n > 0, nil
+}
+
+// MergeCustomers moves the merged customer's rentals, payments,
+// subscription, stored value, loyalty points, reviews, wishlist and
+// promotion usage to the surviving customer, deactivates the merged
+// customer, records the merge and resolves their pairs in the review queue,
+// in one transaction. The merged customer's subscription is canceled when
+// the surviving customer already has a live one, and their review of a
+// film the surviving customer has also reviewed is dropped.
+func (r *duplicateRepository) MergeCustomers(ctx context.Context, survivingCustomerID, mergedCustomerID, staffID int32) (model.CustomerMerge, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return model.CustomerMerge{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.q.WithTx(tx)
+	locked, err := q.LockCustomersForMerge(ctx, []int32{survivingCustomerID, mergedCustomerID})
+	if err != nil {
+		return model.CustomerMerge{}, fmt.Errorf("lock customers: %w", err)
+	}
+	if len(locked) != 2 {
+		return model.CustomerMerge{}, ErrNotFound
+	}
+	for _, c := range locked {
+		if c.AnonymizedAt.Valid || c.Merged {
+			return model.CustomerMerge{}, fmt.Errorf("customer %d: %w", c.CustomerID, ErrNotMergeable)
+		}
+	}
+
+	rentals, err := q.MoveCustomerRentals(ctx, customersqlc.MoveCustomerRentalsParams{
+		SurvivingCustomerID: survivingCustomerID,
+		MergedCustomerID:    mergedCustomerID,
+	})
+	if err != nil {
+		return model.CustomerMerge{}, fmt.Errorf("move rentals: %w", err)
+	}
+	payments, err := q.MoveCustomerPayments(ctx, customersqlc.MoveCustomerPaymentsParams{
+		SurvivingCustomerID: survivingCustomerID,
+		MergedCustomerID:    mergedCustomerID,
+	})
+	if err != nil {
+		return model.CustomerMerge{}, fmt.Errorf("move payments: %w", err)
+	}
+	promotionUses, err := q.MoveCustomerPromotionUses(ctx, customersqlc.MoveCustomerPromotionUsesParams{
+		SurvivingCustomerID: survivingCustomerID,
+		MergedCustomerID:    mergedCustomerID,
+	})
+	if err != nil {
+		return model.CustomerMerge{}, fmt.Errorf("move promotion uses: %w", err)
+	}
+
+	// A customer has at most one live subscription, so the merged
+	// customer's is canceled when the surviving customer has their own.
+	subscriptionsCanceled, err := q.CancelMergedCustomerSubscription(ctx, customersqlc.CancelMergedCustomerSubscriptionParams{
+		MergedCustomerID:    mergedCustomerID,
+		SurvivingCustomerID: survivingCustomerID,
+	})
+	if err != nil {
+		return model.CustomerMerge{}, fmt.Errorf("cancel subscription: %w", err)
+	}
+	subscriptions, err := q.MoveCustomerSubscriptions(ctx, customersqlc.MoveCustomerSubscriptionsParams{
+		SurvivingCustomerID: survivingCustomerID,
+		MergedCustomerID:    mergedCustomerID,
+	})
+	if err != nil {
+		return model.CustomerMerge{}, fmt.Errorf("move subscriptions: %w", err)
+	}
+	if err := q.MoveCustomerSubscriptionPayments(ctx, customersqlc.MoveCustomerSubscriptionPaymentsParams{
+		SurvivingCustomerID: survivingCustomerID,
+		MergedCustomerID:    mergedCustomerID,
+	}); err != nil {
+		return model.CustomerMerge{}, fmt.Errorf("move subscription payments: %w", err)
+	}
+
+	giftCards, err := q.MoveCustomerGiftCards(ctx, customersqlc.MoveCustomerGiftCardsParams{
+		SurvivingCustomerID: survivingCustomerID,
+		MergedCustomerID:    mergedCustomerID,
+	})
+	if err != nil {
+		return model.CustomerMerge{}, fmt.Errorf("move gift cards: %w", err)
+	}
+	storeCredit, err := mergeStoreCredit(ctx, q, survivingCustomerID, mergedCustomerID, staffID)
+	if err != nil {
+		return model.CustomerMerge{}, err
+	}
+
+	loyalty, err := q.LockLoyaltyAccountForMerge(ctx, mergedCustomerID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return model.CustomerMerge{}, fmt.Errorf("lock loyalty account: %w", err)
+	}
+	if loyalty.PointsBalance > 0 || loyalty.LifetimePoints > 0 {
+		if err := q.FoldLoyaltyAccount(ctx, customersqlc.FoldLoyaltyAccountParams{
+			MergedCustomerID:    mergedCustomerID,
+			Points:              loyalty.PointsBalance,
+			SurvivingCustomerID: survivingCustomerID,
+			LifetimePoints:      loyalty.LifetimePoints,
+		}); err != nil {
+			return model.CustomerMerge{}, fmt.Errorf("fold loyalty account: %w", err)
+		}
+	}
+
+	reviews, err := q.MoveCustomerReviews(ctx, customersqlc.MoveCustomerReviewsParams{
+		SurvivingCustomerID: survivingCustomerID,
+		MergedCustomerID:    mergedCustomerID,
+	})
+	if err != nil {
+		return model.CustomerMerge{}, fmt.Errorf("move reviews: %w", err)
+	}
+	droppedFilms, err := q.DeleteCustomerReviews(ctx, mergedCustomerID)
+	if err != nil {
+		return model.CustomerMerge{}, fmt.Errorf("delete reviews: %w", err)
+	}
+	// Lock the films' ratings in a fixed order so concurrent merges cannot
+	// deadlock.
+	sort.Slice(droppedFilms, func(i, j int) bool { return droppedFilms[i] < droppedFilms[j] })
+	for _, filmID := range droppedFilms {
+		if err := q.LockFilmRating(ctx, filmID); err != nil {
+			return model.CustomerMerge{}, fmt.Errorf("lock film %d rating: %w", filmID, err)
+		}
+		if err := q.RefreshFilmRating(ctx, filmID); err != nil {
+			return model.CustomerMerge{}, fmt.Errorf("refresh film %d rating: %w", filmID, err)
+		}
+	}
+
+	wishlist, err := q.MoveCustomerWishlist(ctx, customersqlc.MoveCustomerWishlistParams{
+		SurvivingCustomerID: survivingCustomerID,
+		MergedCustomerID:    mergedCustomerID,
+	})
+	if err != nil {
+		return model.CustomerMerge{}, fmt.Errorf("move wishlist: %w", err)
+	}
+	if err := q.DeleteCustomerWishlist(ctx, mergedCustomerID); err != nil {
+		return model.CustomerMerge{}, fmt.Errorf("delete wishlist: %w", err)
+	}
+
+	if err := q.DeactivateCustomer(ctx, mergedCustomerID); err != nil {
+		return model.CustomerMerge{}, fmt.Errorf("deactivate customer: %w", err)
+	}
+	row, err := q.CreateCustomerMerge(ctx, customersqlc.CreateCustomerMergeParams{
+		SurvivingCustomerID:   survivingCustomerID,
+		MergedCustomerID:      mergedCustomerID,
+		RentalsMoved:          int32(rentals),
+		PaymentsMoved:         int32(payments),
+		SubscriptionsMoved:    int32(subscriptions),
+		SubscriptionsCanceled: int32(subscriptionsCanceled),
+		GiftCardsMoved:        int32(giftCards),
+		StoreCreditMoved:      storeCredit,
+		LoyaltyPointsMoved:    loyalty.PointsBalance,
+		ReviewsMoved:          int32(reviews),
+		ReviewsDropped:        int32(len(droppedFilms)),
+		WishlistItemsMoved:    int32(wishlist),
+		PromotionUsesMoved:    int32(promotionUses),
+		MergedBy:              staffID,
+	})
+	if err != nil {
+		return model.CustomerMerge{}, fmt.Errorf("create customer merge: %w", err)
+	}
+	if err := q.ResolveMergedCustomerDuplicates(ctx, customersqlc.ResolveMergedCustomerDuplicatesParams{
+		MergedCustomerID:    mergedCustomerID,
+		StaffID:             staffID,
+		SurvivingCustomerID: survivingCustomerID,
+	}); err != nil {
+		return model.CustomerMerge{}, fmt.Errorf("resolve customer duplicates: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return model.CustomerMerge{}, fmt.Errorf("commit tx: %w", err)
+	}
+	return model.CustomerMerge{
+		MergeID:               row.MergeID,
+		SurvivingCustomerID:   row.SurvivingCustomerID,
+		MergedCustomerID:      row.MergedCustomerID,
+		RentalsMoved:          row.RentalsMoved,
+		PaymentsMoved:         row.PaymentsMoved,
+		SubscriptionsMoved:    row.SubscriptionsMoved,
+		SubscriptionsCanceled: row.SubscriptionsCanceled,
+		GiftCardsMoved:        row.GiftCardsMoved,
+		StoreCreditMoved:      row.StoreCreditMoved,
+		LoyaltyPointsMoved:    row.LoyaltyPointsMoved,
+		ReviewsMoved:          row.ReviewsMoved,
+		ReviewsDropped:        row.ReviewsDropped,
+		WishlistItemsMoved:    row.WishlistItemsMoved,
+		PromotionUsesMoved:    row.PromotionUsesMoved,
+		MergedBy:              row.MergedBy,
+		MergedAt:              timestamptzToTime(row.MergedAt),
+	}, nil
+}
+
+// mergeStoreCredit hands the merged customer's store credit account to the
+// surviving customer, or, when the surviving customer already has one,
+// transfers its balance there and deactivates it. It returns the amount
+// moved.
+func mergeStoreCredit(ctx context.Context, q *customersqlc.Queries, survivingCustomerID, mergedCustomerID, staffID int32) (string, error) {
+	accounts, err := q.LockStoreCreditForMerge(ctx, []int32{survivingCustomerID, mergedCustomerID})
+	if err != nil {
+		return "", fmt.Errorf("lock store credit: %w", err)
+	}
+	var surviving, merged *customersqlc.LockStoreCreditForMergeRow
+	for i := range accounts {
+		if accounts[i].CustomerID == survivingCustomerID {
+			surviving = &accounts[i]
+		} else {
+			merged = &accounts[i]
+		}
+	}
+	if merged == nil {
+		return "0", nil
+	}
+
+	if surviving == nil {
+		if err := q.MoveStoreCreditAccount(ctx, customersqlc.MoveStoreCreditAccountParams{
+			CustomerID: survivingCustomerID,
+			AccountID:  merged.AccountID,
+		}); err != nil {
+			return "", fmt.Errorf("move store credit: %w", err)
+		}
+		return merged.Balance, nil
+	}
+	if err := q.TransferStoreCredit(ctx, customersqlc.TransferStoreCreditParams{
+		FromAccountID: merged.AccountID,
+		StaffID:       staffID,
+		ToAccountID:   surviving.AccountID,
+	}); err != nil {
+		return "", fmt.Errorf("transfer store credit: %w", err)
+	}
+	if err := q.DeactivateStoredValueAccount(ctx, merged.AccountID); err != nil {
+		return "", fmt.Errorf("deactivate store credit: %w", err)
+	}
+	return merged.Balance, nil
+}
+
+// withCustomerDetails converts duplicate rows to models, looking up both
+// customers of every pair in one query.
+func (r *duplicateRepository) withCustomerDetails(ctx context.Context, rows []customersqlc.GetCustomerDuplicateRow) ([]model.CustomerDuplicate, error) {
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	ids := make([]int32, 0, 2*len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.CustomerID, row.OtherCustomerID)
+	}
+	detailRows, err := r.q.ListCustomerDetails(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("list customer details: %w", err)
+	}
+	details := make(map[int32]model.CustomerDetail, len(detailRows))
+	for _, d := range detailRows {
+		details[d.CustomerID] = model.CustomerDetail{
+			Customer: toCustomerModel(d.CustomerID, d.StoreID, d.FirstName, d.LastName,
+				d.Email, d.AddressID, d.Activebool, d.CreateDate, d.LastUpdate,
+				d.EmailVerifiedAt, d.PendingEmail, d.AnonymizedAt),
+			Address:     d.Address,
+			Address2:    textToString(d.Address2),
+			District:    d.District,
+			CityName:    d.City,
+			CountryName: d.Country,
+			PostalCode:  textToString(d.PostalCode),
+			Phone:       d.Phone,
+		}
+	}
+
+	duplicates := make([]model.CustomerDuplicate, len(rows))
+	for i, row := range rows {
+		duplicates[i] = model.CustomerDuplicate{
+			DuplicateCandidate: model.DuplicateCandidate{
+				CustomerID:      row.CustomerID,
+				OtherCustomerID: row.OtherCustomerID,
+				Score:           row.Score,
+				NameScore:       row.NameScore,
+				AddressScore:    row.AddressScore,
+				PhoneScore:      row.PhoneScore,
+			},
+			DuplicateID:   row.DuplicateID,
+			Customer:      details[row.CustomerID],
+			OtherCustomer: details[row.OtherCustomerID],
+			Status:        row.Status,
+			DetectedAt:    timestamptzToTime(row.DetectedAt),
+			ReviewedAt:    timestamptzToTime(row.ReviewedAt),
+			ReviewedBy:    row.ReviewedBy.Int32,
+		}
+	}
+	return duplicates, nil
+}
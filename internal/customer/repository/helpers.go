@@ -38,6 +38,22 @@ func timestamptzToTime(ts pgtype.Timestamptz) time.Time {
 	return ts.Time
 }
 
+// int4ToInt32 converts a pgtype.Int4 to int32. NULL maps to 0.
+func int4ToInt32(i pgtype.Int4) int32 {
+	if !i.Valid {
+		return 0
+	}
+	return i.Int32
+}
+
+// boolPtrToBool converts a *bool to pgtype.Bool. nil maps to NULL.
+func boolPtrToBool(b *bool) pgtype.Bool {
+	if b == nil {
+		return pgtype.Bool{}
+	}
+	return pgtype.Bool{Bool: *b, Valid: true}
+}
+
 // boolToActive converts a boolean to the legacy active integer field.
 // true → 1, false → 0.
 func boolToActive(b bool) pgtype.Int4 {
@@ -0,0 +1,247 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/enkaigaku/dvd-rental/internal/customer/model"
+	"github.com/enkaigaku/dvd-rental/gen/sqlc/customer"
+)
+
+// SegmentParams holds the editable fields of a customer segment.
+type SegmentParams struct {
+	Name        string
+	Description string
+	Rule        model.SegmentRule
+}
+
+// SegmentRepository defines the data access interface for customer
+// segments and their materialized members.
+type SegmentRepository interface {
+	GetSegment(ctx context.Context, segmentID int32) (model.Segment, error)
+	ListSegments(ctx context.Context, limit, offset int32) ([]model.Segment, error)
+	CountSegments(ctx context.Context) (int64, error)
+	CreateSegment(ctx context.Context, params SegmentParams) (model.Segment, error)
+	UpdateSegment(ctx context.Context, segmentID int32, params SegmentParams) (model.Segment, error)
+	DeleteSegment(ctx context.Context, segmentID int32) error
+	ReplaceSegmentMembers(ctx context.Context, segmentID int32, customerIDs []int32) (model.Segment, error)
+	ListSegmentMembers(ctx context.Context, segmentID, limit, offset int32) ([]model.Customer, error)
+	CountSegmentMembers(ctx context.Context, segmentID int32) (int64, error)
+	ListSegmentCandidates(ctx context.Context) ([]model.SegmentCandidate, error)
+}
+
+type segmentRepository struct {
+	pool *pgxpool.Pool
+	q    *customersqlc.Queries
+}
+
+// NewSegmentRepository creates a new SegmentRepository backed by PostgreSQL.
+func NewSegmentRepository(pool *pgxpool.Pool) SegmentRepository {
+	return &segmentRepository{pool: pool, q: customersqlc.New(pool)}
+}
+
+func (r *segmentRepository) GetSegment(ctx context.Context, segmentID int32) (model.Segment, error) {
+	row, err := r.q.GetSegment(ctx, segmentID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Segment{}, ErrNotFound
+		}
+		return model.Segment{}, fmt.Errorf("get segment: %w", err)
+	}
+	return toSegmentModel(row)
+}
+
+func (r *segmentRepository) ListSegments(ctx context.Context, limit, offset int32) ([]model.Segment, error) {
+	rows, err := r.q.ListSegments(ctx, customersqlc.ListSegmentsParams{
+		PageOffset: offset,
+		PageLimit:  limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list segments: %w", err)
+	}
+	segments := make([]model.Segment, len(rows))
+	for i, row := range rows {
+		if segments[i], err = toSegmentModel(row); err != nil {
+			return nil, err
+		}
+	}
+	return segments, nil
+}
+
+func (r *segmentRepository) CountSegments(ctx context.Context) (int64, error) {
+	count, err := r.q.CountSegments(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("count segments: %w", err)
+	}
+	return count, nil
+}
+
+func (r *segmentRepository) CreateSegment(ctx context.Context, params SegmentParams) (model.Segment, error) {
+	rule, err := json.Marshal(params.Rule)
+	if err != nil {
+		return model.Segment{}, fmt.Errorf("marshal segment rule: %w", err)
+	}
+	segmentID, err := r.q.CreateSegment(ctx, customersqlc.CreateSegmentParams{
+		Name:        params.Name,
+		Description: params.Description,
+		Rule:        rule,
+	})
+	if err != nil {
+		return model.Segment{}, fmt.Errorf("create segment: %w", err)
+	}
+	return r.GetSegment(ctx, segmentID)
+}
+
+func (r *segmentRepository) UpdateSegment(ctx context.Context, segmentID int32, params SegmentParams) (model.Segment, error) {
+	rule, err := json.Marshal(params.Rule)
+	if err != nil {
+		return model.Segment{}, fmt.Errorf("marshal segment rule: %w", err)
+	}
+	n, err := r.q.UpdateSegment(ctx, customersqlc.UpdateSegmentParams{
+		Name:        params.Name,
+		Description: params.Description,
+		Rule:        rule,
+		SegmentID:   segmentID,
+	})
+	if err != nil {
+		return model.Segment{}, fmt.Errorf("update segment: %w", err)
+	}
+	if n == 0 {
+		return model.Segment{}, ErrNotFound
+	}
+	return r.GetSegment(ctx, segmentID)
+}
+
+func (r *segmentRepository) DeleteSegment(ctx context.Context, segmentID int32) error {
+	n, err := r.q.DeleteSegment(ctx, segmentID)
+	if err != nil {
+		return fmt.Errorf("delete segment: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ReplaceSegmentMembers sets a segment's members to customerIDs and
+// records when it was evaluated, in one transaction.
+func (r *segmentRepository) ReplaceSegmentMembers(ctx context.Context, segmentID int32, customerIDs []int32) (model.Segment, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return model.Segment{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.q.WithTx(tx)
+	if _, err := q.LockSegment(ctx, segmentID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Segment{}, ErrNotFound
+		}
+		return model.Segment{}, fmt.Errorf("lock segment: %w", err)
+	}
+	if err := q.DeleteSegmentMembers(ctx, segmentID); err != nil {
+		return model.Segment{}, fmt.Errorf("delete segment members: %w", err)
+	}
+	if err := q.InsertSegmentMembers(ctx, customersqlc.InsertSegmentMembersParams{
+		SegmentID:   segmentID,
+		CustomerIds: customerIDs,
+	}); err != nil {
+		return model.Segment{}, fmt.Errorf("insert segment members: %w", err)
+	}
+	if err := q.SetSegmentEvaluated(ctx, customersqlc.SetSegmentEvaluatedParams{
+		MemberCount: int32(len(customerIDs)),
+		SegmentID:   segmentID,
+	}); err != nil {
+		return model.Segment{}, fmt.Errorf("set segment evaluated: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return model.Segment{}, fmt.Errorf("commit tx: %w", err)
+	}
+	return r.GetSegment(ctx, segmentID)
+}
+
+func (r *segmentRepository) ListSegmentMembers(ctx context.Context, segmentID, limit, offset int32) ([]model.Customer, error) {
+	rows, err := r.q.ListSegmentMembers(ctx, customersqlc.ListSegmentMembersParams{
+		SegmentID:  segmentID,
+		PageOffset: offset,
+		PageLimit:  limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list segment members: %w", err)
+	}
+	customers := make([]model.Customer, len(rows))
+	for i, row := range rows {
+		customers[i] = toCustomerModel(row.CustomerID, row.StoreID, row.FirstName, row.LastName,
+			row.Email, row.AddressID, row.Activebool, row.CreateDate, row.LastUpdate,
+			row.EmailVerifiedAt, row.PendingEmail, row.AnonymizedAt)
+	}
+	return customers, nil
+}
+
+func (r *segmentRepository) CountSegmentMembers(ctx context.Context, segmentID int32) (int64, error) {
+	count, err := r.q.CountSegmentMembers(ctx, segmentID)
+	if err != nil {
+		return 0, fmt.Errorf("count segment members: %w", err)
+	}
+	return count, nil
+}
+
+func (r *segmentRepository) ListSegmentCandidates(ctx context.Context) ([]model.SegmentCandidate, error) {
+	rows, err := r.q.ListSegmentCandidates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list segment candidates: %w", err)
+	}
+	catRows, err := r.q.ListCustomerCategoryRentals(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list customer category rentals: %w", err)
+	}
+
+	categories := make(map[int32][]model.CategoryRentalCount)
+	for _, row := range catRows {
+		categories[row.CustomerID] = append(categories[row.CustomerID], model.CategoryRentalCount{
+			CategoryID: row.CategoryID,
+			Name:       row.Name,
+			Rentals:    row.Rentals,
+		})
+	}
+
+	candidates := make([]model.SegmentCandidate, len(rows))
+	for i, row := range rows {
+		candidates[i] = model.SegmentCandidate{
+			CustomerID:      row.CustomerID,
+			StoreID:         row.StoreID,
+			Active:          row.Activebool,
+			LifetimeSpend:   row.LifetimeSpend,
+			SpendPercentile: row.SpendPercentile,
+			TotalRentals:    row.TotalRentals,
+			LastRentalDate:  timestamptzToTime(row.LastRentalDate),
+			Categories:      categories[row.CustomerID],
+		}
+	}
+	return candidates, nil
+}
+
+// --- row to model conversions ---
+
+func toSegmentModel(row customersqlc.CustomerSegment) (model.Segment, error) {
+	var rule model.SegmentRule
+	if err := json.Unmarshal(row.Rule, &rule); err != nil {
+		return model.Segment{}, fmt.Errorf("unmarshal rule of segment %d: %w", row.SegmentID, err)
+	}
+	return model.Segment{
+		SegmentID:   row.SegmentID,
+		Name:        row.Name,
+		Description: row.Description,
+		Rule:        rule,
+		MemberCount: row.MemberCount,
+		EvaluatedAt: timestamptzToTime(row.EvaluatedAt),
+		CreatedAt:   timestamptzToTime(row.CreatedAt),
+		LastUpdate:  timestamptzToTime(row.LastUpdate),
+	}, nil
+}
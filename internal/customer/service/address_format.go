@@ -0,0 +1,79 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// addressFormat describes the postal codes and phone numbers accepted for
+// addresses in a country. Phone lengths count national digits only, not
+// the country calling code.
+type addressFormat struct {
+	postalCode     *regexp.Regexp
+	postalExample  string
+	minPhoneDigits int
+	maxPhoneDigits int
+}
+
+// defaultAddressFormat applies to countries without an entry in
+// addressFormats: any short alphanumeric postal code, and a phone number
+// that fits in E.164.
+var defaultAddressFormat = addressFormat{
+	postalCode:     regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9 -]{1,9}$`),
+	minPhoneDigits: 6,
+	maxPhoneDigits: 15,
+}
+
+// addressFormats holds country-specific formats, keyed by country name as
+// stored in the country table.
+var addressFormats = map[string]addressFormat{
+	"Australia":          {regexp.MustCompile(`^\d{4}$`), "2000", 9, 9},
+	"Brazil":             {regexp.MustCompile(`^\d{5}-?\d{3}$`), "01310-100", 10, 11},
+	"Canada":             {regexp.MustCompile(`^[A-Za-z]\d[A-Za-z] ?\d[A-Za-z]\d$`), "K1A 0B1", 10, 10},
+	"China":              {regexp.MustCompile(`^\d{6}$`), "100000", 10, 11},
+	"France":             {regexp.MustCompile(`^\d{5}$`), "75001", 9, 9},
+	"Germany":            {regexp.MustCompile(`^\d{5}$`), "10115", 6, 11},
+	"India":              {regexp.MustCompile(`^\d{6}$`), "110001", 10, 10},
+	"Italy":              {regexp.MustCompile(`^\d{5}$`), "00118", 6, 11},
+	"Japan":              {regexp.MustCompile(`^\d{3}-?\d{4}$`), "100-0001", 9, 10},
+	"Mexico":             {regexp.MustCompile(`^\d{5}$`), "06000", 10, 10},
+	"Netherlands":        {regexp.MustCompile(`^\d{4} ?[A-Za-z]{2}$`), "1012 AB", 9, 9},
+	"Russian Federation": {regexp.MustCompile(`^\d{6}$`), "101000", 10, 10},
+	"Spain":              {regexp.MustCompile(`^\d{5}$`), "28001", 9, 9},
+	"United Kingdom":     {regexp.MustCompile(`^[A-Za-z]{1,2}\d[A-Za-z\d]? ?\d[A-Za-z]{2}$`), "SW1A 1AA", 10, 10},
+	"United States":      {regexp.MustCompile(`^\d{5}(-\d{4})?$`), "10001", 10, 10},
+}
+
+// phoneSeparators are the characters allowed between phone number digits.
+var phoneSeparators = strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "")
+
+// validatePostalCodeAndPhone checks an address's postal code and phone
+// number against the format of its country. The postal code is optional.
+// A phone number may be written with spaces, dashes, dots, parentheses and
+// a leading +, and may include a country calling code or trunk prefix.
+func validatePostalCodeAndPhone(country, postalCode, phone string) error {
+	format, ok := addressFormats[country]
+	if !ok {
+		format = defaultAddressFormat
+	}
+
+	if postalCode != "" && !format.postalCode.MatchString(postalCode) {
+		if format.postalExample != "" {
+			return fmt.Errorf("invalid postal code for %s, expected a code like %q: %w", country, format.postalExample, ErrInvalidArgument)
+		}
+		return fmt.Errorf("invalid postal code: %w", ErrInvalidArgument)
+	}
+
+	digits := strings.TrimPrefix(phoneSeparators.Replace(phone), "+")
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return fmt.Errorf("phone must contain only digits, spaces, dashes, dots, parentheses and a leading +: %w", ErrInvalidArgument)
+		}
+	}
+	// Allow up to three extra digits for a calling code or trunk prefix.
+	if n := len(digits); n < format.minPhoneDigits || n > format.maxPhoneDigits+3 || n > 15 {
+		return fmt.Errorf("invalid phone number length for %s: %w", country, ErrInvalidArgument)
+	}
+	return nil
+}
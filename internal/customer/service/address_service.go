@@ -23,7 +23,8 @@ func NewAddressService(addressRepo repository.AddressRepository, cityRepo reposi
 	}
 }
 
-// GetAddress returns an address by ID.
+// GetAddress returns an address by ID, with the number of customers,
+// stores and staff members using it.
 func (s *AddressService) GetAddress(ctx context.Context, addressID int32) (model.Address, error) {
 	if addressID <= 0 {
 		return model.Address{}, fmt.Errorf("address_id must be positive: %w", ErrInvalidArgument)
@@ -36,6 +37,11 @@ func (s *AddressService) GetAddress(ctx context.Context, addressID int32) (model
 		}
 		return model.Address{}, err
 	}
+
+	addr.UserCount, err = s.addressRepo.CountAddressUsers(ctx, addressID)
+	if err != nil {
+		return model.Address{}, err
+	}
 	return addr, nil
 }
 
@@ -119,6 +125,9 @@ func (s *AddressService) DeleteAddress(ctx context.Context, addressID int32) err
 	return nil
 }
 
+// validateAddressParams checks an address entered by staff. The postal code
+// and phone number are not checked against the country's format, which
+// much of the existing data predates.
 func (s *AddressService) validateAddressParams(ctx context.Context, address, district, phone string, cityID int32) error {
 	if address == "" {
 		return fmt.Errorf("address must not be empty: %w", ErrInvalidArgument)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/enkaigaku/dvd-rental/internal/customer/model"
 	"github.com/enkaigaku/dvd-rental/internal/customer/repository"
@@ -35,17 +36,24 @@ func (s *CityService) GetCity(ctx context.Context, cityID int32) (model.City, er
 	return city, nil
 }
 
-// ListCities returns a paginated list of cities.
-func (s *CityService) ListCities(ctx context.Context, pageSize, page int32) ([]model.City, int64, error) {
+// ListCities returns a paginated list of cities. A non-empty name keeps
+// only cities whose name starts with it, ignoring case, ordered by name for
+// autocomplete; a positive countryID keeps only cities in that country.
+func (s *CityService) ListCities(ctx context.Context, name string, countryID, pageSize, page int32) ([]model.City, int64, error) {
+	if countryID < 0 {
+		return nil, 0, fmt.Errorf("country_id must not be negative: %w", ErrInvalidArgument)
+	}
+
 	pageSize, page = clampPagination(pageSize, page)
 	offset := (page - 1) * pageSize
+	pattern := likePrefix(strings.TrimSpace(name))
 
-	cities, err := s.cityRepo.ListCities(ctx, pageSize, offset)
+	cities, err := s.cityRepo.ListCities(ctx, pattern, countryID, pageSize, offset)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	total, err := s.cityRepo.CountCities(ctx)
+	total, err := s.cityRepo.CountCities(ctx, pattern, countryID)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/mail"
+	"strings"
+	"time"
 
 	"github.com/enkaigaku/dvd-rental/internal/customer/model"
 	"github.com/enkaigaku/dvd-rental/internal/customer/repository"
@@ -11,10 +14,11 @@ import (
 
 // CustomerService contains business logic for customer operations.
 type CustomerService struct {
-	customerRepo repository.CustomerRepository
-	addressRepo  repository.AddressRepository
-	cityRepo     repository.CityRepository
-	countryRepo  repository.CountryRepository
+	customerRepo  repository.CustomerRepository
+	addressRepo   repository.AddressRepository
+	cityRepo      repository.CityRepository
+	countryRepo   repository.CountryRepository
+	duplicateRepo repository.DuplicateRepository
 }
 
 // NewCustomerService creates a new CustomerService.
@@ -23,12 +27,14 @@ func NewCustomerService(
 	addressRepo repository.AddressRepository,
 	cityRepo repository.CityRepository,
 	countryRepo repository.CountryRepository,
+	duplicateRepo repository.DuplicateRepository,
 ) *CustomerService {
 	return &CustomerService{
-		customerRepo: customerRepo,
-		addressRepo:  addressRepo,
-		cityRepo:     cityRepo,
-		countryRepo:  countryRepo,
+		customerRepo:  customerRepo,
+		addressRepo:   addressRepo,
+		cityRepo:      cityRepo,
+		countryRepo:   countryRepo,
+		duplicateRepo: duplicateRepo,
 	}
 }
 
@@ -128,6 +134,50 @@ func (s *CustomerService) ListCustomersByStore(ctx context.Context, storeID, pag
 	return customers, total, nil
 }
 
+// SearchCustomers finds customers by name, email or phone: prefix matches
+// first, then fuzzy matches on the full name. An empty query matches every
+// customer, ordered by name. storeID narrows the search to one store when
+// positive, and status to "active" or "inactive" customers when set.
+func (s *CustomerService) SearchCustomers(ctx context.Context, query string, storeID int32, status string, pageSize, page int32) ([]model.Customer, int64, error) {
+	query = strings.TrimSpace(query)
+	if storeID < 0 {
+		return nil, 0, fmt.Errorf("store_id must not be negative: %w", ErrInvalidArgument)
+	}
+
+	search := repository.CustomerSearch{
+		Query:         query,
+		PrefixPattern: likePrefix(query),
+		PhonePattern:  phonePrefix(query),
+		StoreID:       storeID,
+	}
+	switch status {
+	case "":
+	case "active":
+		active := true
+		search.Active = &active
+	case "inactive":
+		active := false
+		search.Active = &active
+	default:
+		return nil, 0, fmt.Errorf("status must be active or inactive: %w", ErrInvalidArgument)
+	}
+
+	pageSize, page = clampPagination(pageSize, page)
+	offset := (page - 1) * pageSize
+
+	customers, err := s.customerRepo.SearchCustomers(ctx, search, pageSize, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.customerRepo.CountSearchCustomers(ctx, search)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return customers, total, nil
+}
+
 // CreateCustomer creates a new customer after validation.
 func (s *CustomerService) CreateCustomer(ctx context.Context, params repository.CreateCustomerParams) (model.Customer, error) {
 	if err := s.validateCustomerParams(ctx, params.FirstName, params.LastName, params.StoreID, params.AddressID); err != nil {
@@ -147,6 +197,47 @@ func (s *CustomerService) CreateCustomer(ctx context.Context, params repository.
 	return cust, nil
 }
 
+// RegisterCustomer signs up a new customer: it creates their address and
+// an active customer with the given password hash. The email must not be
+// in use by another customer.
+func (s *CustomerService) RegisterCustomer(ctx context.Context, params repository.RegisterCustomerParams) (model.Customer, error) {
+	if params.FirstName == "" {
+		return model.Customer{}, fmt.Errorf("first_name must not be empty: %w", ErrInvalidArgument)
+	}
+	if params.LastName == "" {
+		return model.Customer{}, fmt.Errorf("last_name must not be empty: %w", ErrInvalidArgument)
+	}
+	if a, err := mail.ParseAddress(params.Email); err != nil || a.Address != params.Email {
+		return model.Customer{}, fmt.Errorf("invalid email: %w", ErrInvalidArgument)
+	}
+	if params.PasswordHash == "" {
+		return model.Customer{}, fmt.Errorf("password_hash must not be empty: %w", ErrInvalidArgument)
+	}
+	if params.StoreID <= 0 {
+		return model.Customer{}, fmt.Errorf("store_id must be positive: %w", ErrInvalidArgument)
+	}
+
+	if err := s.validateCustomerAddress(ctx, params.Address); err != nil {
+		return model.Customer{}, err
+	}
+
+	if err := s.checkEmailAvailable(ctx, 0, params.Email); err != nil {
+		return model.Customer{}, err
+	}
+
+	cust, err := s.customerRepo.RegisterCustomer(ctx, params)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return model.Customer{}, fmt.Errorf("invalid store_id: %w", ErrInvalidArgument)
+		}
+		if isUniqueViolation(err) {
+			return model.Customer{}, fmt.Errorf("email already in use: %w", ErrAlreadyExists)
+		}
+		return model.Customer{}, err
+	}
+	return cust, nil
+}
+
 // UpdateCustomer updates an existing customer after validation.
 func (s *CustomerService) UpdateCustomer(ctx context.Context, params repository.UpdateCustomerParams) (model.Customer, error) {
 	if params.CustomerID <= 0 {
@@ -157,6 +248,17 @@ func (s *CustomerService) UpdateCustomer(ctx context.Context, params repository.
 		return model.Customer{}, err
 	}
 
+	current, err := s.customerRepo.GetCustomer(ctx, params.CustomerID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.Customer{}, fmt.Errorf("customer %d: %w", params.CustomerID, ErrNotFound)
+		}
+		return model.Customer{}, err
+	}
+	if !current.AnonymizedAt.IsZero() {
+		return model.Customer{}, fmt.Errorf("customer %d has been anonymized: %w", params.CustomerID, ErrFailedPrecondition)
+	}
+
 	cust, err := s.customerRepo.UpdateCustomer(ctx, params)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
@@ -173,30 +275,224 @@ func (s *CustomerService) UpdateCustomer(ctx context.Context, params repository.
 	return cust, nil
 }
 
-// DeleteCustomer deletes a customer. Fails if rental or payment records reference it.
-func (s *CustomerService) DeleteCustomer(ctx context.Context, customerID int32) error {
+// ChangeCustomerAddress sets the address a customer entered themselves,
+// checking the postal code and phone number against their country's
+// format. The address is updated in place unless others use it too, in
+// which case the customer gets a new one.
+func (s *CustomerService) ChangeCustomerAddress(ctx context.Context, customerID int32, params repository.CreateAddressParams) (model.Customer, error) {
 	if customerID <= 0 {
-		return fmt.Errorf("customer_id must be positive: %w", ErrInvalidArgument)
+		return model.Customer{}, fmt.Errorf("customer_id must be positive: %w", ErrInvalidArgument)
+	}
+	if err := s.validateCustomerAddress(ctx, params); err != nil {
+		return model.Customer{}, err
 	}
 
-	// Verify the customer exists.
-	_, err := s.customerRepo.GetCustomer(ctx, customerID)
+	current, err := s.customerRepo.GetCustomer(ctx, customerID)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
-			return fmt.Errorf("customer %d: %w", customerID, ErrNotFound)
+			return model.Customer{}, fmt.Errorf("customer %d: %w", customerID, ErrNotFound)
 		}
-		return err
+		return model.Customer{}, err
+	}
+	if !current.AnonymizedAt.IsZero() {
+		return model.Customer{}, fmt.Errorf("customer %d has been anonymized: %w", customerID, ErrFailedPrecondition)
 	}
 
-	if err := s.customerRepo.DeleteCustomer(ctx, customerID); err != nil {
+	cust, err := s.customerRepo.ChangeCustomerAddress(ctx, customerID, params)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.Customer{}, fmt.Errorf("customer %d: %w", customerID, ErrNotFound)
+		}
 		if isForeignKeyViolation(err) {
-			return fmt.Errorf("customer %d is referenced by rental or payment records: %w", customerID, ErrForeignKey)
+			return model.Customer{}, fmt.Errorf("invalid city_id: %w", ErrInvalidArgument)
+		}
+		return model.Customer{}, err
+	}
+	return cust, nil
+}
+
+// UpdateCustomerPassword replaces a customer's password hash.
+func (s *CustomerService) UpdateCustomerPassword(ctx context.Context, customerID int32, passwordHash string) error {
+	if customerID <= 0 {
+		return fmt.Errorf("customer_id must be positive: %w", ErrInvalidArgument)
+	}
+	if passwordHash == "" {
+		return fmt.Errorf("password_hash must not be empty: %w", ErrInvalidArgument)
+	}
+
+	if err := s.customerRepo.UpdateCustomerPassword(ctx, customerID, passwordHash); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("customer %d: %w", customerID, ErrNotFound)
 		}
 		return err
 	}
 	return nil
 }
 
+// RequestEmailChange records email as the customer's pending email until
+// it is confirmed with VerifyEmail; the current email stays in use until
+// then. Requesting the current email cancels a pending change.
+func (s *CustomerService) RequestEmailChange(ctx context.Context, customerID int32, email string) (model.Customer, error) {
+	if customerID <= 0 {
+		return model.Customer{}, fmt.Errorf("customer_id must be positive: %w", ErrInvalidArgument)
+	}
+	if a, err := mail.ParseAddress(email); err != nil || a.Address != email {
+		return model.Customer{}, fmt.Errorf("invalid email: %w", ErrInvalidArgument)
+	}
+	if err := s.checkEmailAvailable(ctx, customerID, email); err != nil {
+		return model.Customer{}, err
+	}
+
+	cust, err := s.customerRepo.SetCustomerPendingEmail(ctx, customerID, email)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.Customer{}, fmt.Errorf("customer %d: %w", customerID, ErrNotFound)
+		}
+		return model.Customer{}, err
+	}
+	return cust, nil
+}
+
+// VerifyEmail confirms that the customer owns email. If it is their pending
+// email it replaces the current one; otherwise it must be their current,
+// unverified email. Verifying an email that is no longer pending, for
+// example after the change was cancelled, fails with ErrFailedPrecondition.
+func (s *CustomerService) VerifyEmail(ctx context.Context, customerID int32, email string) (model.Customer, error) {
+	if customerID <= 0 {
+		return model.Customer{}, fmt.Errorf("customer_id must be positive: %w", ErrInvalidArgument)
+	}
+	if email == "" {
+		return model.Customer{}, fmt.Errorf("email must not be empty: %w", ErrInvalidArgument)
+	}
+
+	if _, err := s.customerRepo.GetCustomer(ctx, customerID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.Customer{}, fmt.Errorf("customer %d: %w", customerID, ErrNotFound)
+		}
+		return model.Customer{}, err
+	}
+	// Another customer may have taken the address since it was requested.
+	if err := s.checkEmailAvailable(ctx, customerID, email); err != nil {
+		return model.Customer{}, err
+	}
+
+	cust, err := s.customerRepo.VerifyCustomerEmail(ctx, customerID, email)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.Customer{}, fmt.Errorf("email %q is not awaiting verification: %w", email, ErrFailedPrecondition)
+		}
+		if isUniqueViolation(err) {
+			return model.Customer{}, fmt.Errorf("email already in use: %w", ErrAlreadyExists)
+		}
+		return model.Customer{}, err
+	}
+	return cust, nil
+}
+
+// AnonymizeCustomer erases a customer's personal data: their name, email,
+// password and address are scrubbed, their reviews and wishlist are deleted
+// and the account is disabled. Their subscription is canceled, loyalty
+// points are forfeited and store credit is frozen. The customer row stays,
+// so their rentals and payments are kept for accounting. Anonymizing an
+// anonymized customer is a no-op.
+func (s *CustomerService) AnonymizeCustomer(ctx context.Context, customerID int32) (model.Customer, error) {
+	if customerID <= 0 {
+		return model.Customer{}, fmt.Errorf("customer_id must be positive: %w", ErrInvalidArgument)
+	}
+
+	cust, err := s.customerRepo.GetCustomer(ctx, customerID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.Customer{}, fmt.Errorf("customer %d: %w", customerID, ErrNotFound)
+		}
+		return model.Customer{}, err
+	}
+	if !cust.AnonymizedAt.IsZero() {
+		return cust, nil
+	}
+
+	cust, err = s.customerRepo.AnonymizeCustomer(ctx, customerID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.Customer{}, fmt.Errorf("customer %d: %w", customerID, ErrNotFound)
+		}
+		return model.Customer{}, err
+	}
+	return cust, nil
+}
+
+// ExportCustomerData assembles everything held about a customer: their
+// profile and address, rentals, payments, reviews, wishlist, loyalty
+// points, gift cards and store credit, and subscriptions.
+func (s *CustomerService) ExportCustomerData(ctx context.Context, customerID int32) (model.CustomerDataExport, error) {
+	detail, err := s.GetCustomer(ctx, customerID)
+	if err != nil {
+		return model.CustomerDataExport{}, err
+	}
+
+	rentals, err := s.customerRepo.ListCustomerRentalHistory(ctx, customerID)
+	if err != nil {
+		return model.CustomerDataExport{}, err
+	}
+	payments, err := s.customerRepo.ListCustomerPaymentHistory(ctx, customerID)
+	if err != nil {
+		return model.CustomerDataExport{}, err
+	}
+	reviews, err := s.customerRepo.ListCustomerReviewHistory(ctx, customerID)
+	if err != nil {
+		return model.CustomerDataExport{}, err
+	}
+	wishlist, err := s.customerRepo.ListCustomerWishlistHistory(ctx, customerID)
+	if err != nil {
+		return model.CustomerDataExport{}, err
+	}
+
+	var loyalty *model.CustomerLoyaltyRecord
+	account, err := s.customerRepo.GetCustomerLoyaltyRecord(ctx, customerID)
+	switch {
+	case err == nil:
+		loyalty = &account
+	case !errors.Is(err, repository.ErrNotFound):
+		return model.CustomerDataExport{}, err
+	}
+	loyaltyTransactions, err := s.customerRepo.ListCustomerLoyaltyHistory(ctx, customerID)
+	if err != nil {
+		return model.CustomerDataExport{}, err
+	}
+
+	storedValue, err := s.customerRepo.ListCustomerStoredValueAccounts(ctx, customerID)
+	if err != nil {
+		return model.CustomerDataExport{}, err
+	}
+	storedValueEntries, err := s.customerRepo.ListCustomerStoredValueHistory(ctx, customerID)
+	if err != nil {
+		return model.CustomerDataExport{}, err
+	}
+	subscriptions, err := s.customerRepo.ListCustomerSubscriptionHistory(ctx, customerID)
+	if err != nil {
+		return model.CustomerDataExport{}, err
+	}
+	subscriptionPayments, err := s.customerRepo.ListCustomerSubscriptionPaymentHistory(ctx, customerID)
+	if err != nil {
+		return model.CustomerDataExport{}, err
+	}
+
+	return model.CustomerDataExport{
+		Customer:             detail,
+		Rentals:              rentals,
+		Payments:             payments,
+		Reviews:              reviews,
+		Wishlist:             wishlist,
+		Loyalty:              loyalty,
+		LoyaltyTransactions:  loyaltyTransactions,
+		StoredValue:          storedValue,
+		StoredValueEntries:   storedValueEntries,
+		Subscriptions:        subscriptions,
+		SubscriptionPayments: subscriptionPayments,
+		ExportedAt:           time.Now(),
+	}, nil
+}
+
 func (s *CustomerService) validateCustomerParams(ctx context.Context, firstName, lastName string, storeID, addressID int32) error {
 	if firstName == "" {
 		return fmt.Errorf("first_name must not be empty: %w", ErrInvalidArgument)
@@ -221,3 +517,50 @@ func (s *CustomerService) validateCustomerParams(ctx context.Context, firstName,
 
 	return nil
 }
+
+// validateCustomerAddress checks an address a customer entered themselves.
+// Unlike addresses entered by staff, its postal code and phone number must
+// match the format of its country.
+func (s *CustomerService) validateCustomerAddress(ctx context.Context, addr repository.CreateAddressParams) error {
+	if addr.Address == "" {
+		return fmt.Errorf("address must not be empty: %w", ErrInvalidArgument)
+	}
+	if addr.District == "" {
+		return fmt.Errorf("district must not be empty: %w", ErrInvalidArgument)
+	}
+	if addr.Phone == "" {
+		return fmt.Errorf("phone must not be empty: %w", ErrInvalidArgument)
+	}
+	if addr.CityID <= 0 {
+		return fmt.Errorf("city_id must be positive: %w", ErrInvalidArgument)
+	}
+	city, err := s.cityRepo.GetCity(ctx, addr.CityID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("city %d not found: %w", addr.CityID, ErrInvalidArgument)
+		}
+		return err
+	}
+	country, err := s.countryRepo.GetCountry(ctx, city.CountryID)
+	if err != nil {
+		return err
+	}
+	return validatePostalCodeAndPhone(country.Country, addr.PostalCode, addr.Phone)
+}
+
+// checkEmailAvailable returns ErrAlreadyExists if a customer other than
+// customerID uses email, ignoring case. The schema enforces this too; the
+// check gives the usual case a clear error before anything is written.
+func (s *CustomerService) checkEmailAvailable(ctx context.Context, customerID int32, email string) error {
+	cust, err := s.customerRepo.GetCustomerByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if cust.CustomerID != customerID {
+		return fmt.Errorf("email already in use: %w", ErrAlreadyExists)
+	}
+	return nil
+}
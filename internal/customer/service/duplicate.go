@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/enkaigaku/dvd-rental/internal/customer/model"
+	"github.com/enkaigaku/dvd-rental/internal/customer/repository"
+)
+
+// How much name, address and phone similarity count towards a duplicate
+// pair's score. Names weigh most: two people at one address with one phone
+// number are usually a household, not a duplicate.
+const (
+	duplicateNameWeight    = 0.5
+	duplicateAddressWeight = 0.3
+	duplicatePhoneWeight   = 0.2
+)
+
+// minDuplicateScore is the score a pair needs to be queued for review.
+const minDuplicateScore = 0.6
+
+// duplicateScore weighs a candidate pair's similarity scores into one.
+func duplicateScore(c model.DuplicateCandidate) float64 {
+	return duplicateNameWeight*c.NameScore +
+		duplicateAddressWeight*c.AddressScore +
+		duplicatePhoneWeight*c.PhoneScore
+}
+
+// ScanDuplicateCustomers scores pairs of customers that may be the same
+// person and refreshes the pending review queue with those scoring at
+// least minDuplicateScore. Pairs staff have dismissed stay dismissed. It
+// returns how many pairs are pending review.
+func (s *CustomerService) ScanDuplicateCustomers(ctx context.Context) (int64, error) {
+	candidates, err := s.duplicateRepo.ListDuplicateCandidates(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var likely []model.DuplicateCandidate
+	for _, c := range candidates {
+		c.Score = duplicateScore(c)
+		if c.Score >= minDuplicateScore {
+			likely = append(likely, c)
+		}
+	}
+
+	if err := s.duplicateRepo.ReplacePendingDuplicates(ctx, likely); err != nil {
+		return 0, err
+	}
+	return s.duplicateRepo.CountCustomerDuplicatesByStatus(ctx, model.DuplicateStatusPending)
+}
+
+// ListCustomerDuplicates returns a page of duplicate pairs in a status,
+// highest score first, and the total count. With the pending status, the
+// default, this is the review queue.
+func (s *CustomerService) ListCustomerDuplicates(ctx context.Context, status string, pageSize, page int32) ([]model.CustomerDuplicate, int64, error) {
+	if status == "" {
+		status = model.DuplicateStatusPending
+	}
+	switch status {
+	case model.DuplicateStatusPending, model.DuplicateStatusDismissed, model.DuplicateStatusMerged:
+	default:
+		return nil, 0, fmt.Errorf("status must be one of %s, %s or %s: %w",
+			model.DuplicateStatusPending, model.DuplicateStatusDismissed, model.DuplicateStatusMerged, ErrInvalidArgument)
+	}
+	pageSize, page = clampPagination(pageSize, page)
+	offset := (page - 1) * pageSize
+
+	duplicates, err := s.duplicateRepo.ListCustomerDuplicatesByStatus(ctx, status, pageSize, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.duplicateRepo.CountCustomerDuplicatesByStatus(ctx, status)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return duplicates, total, nil
+}
+
+// DismissCustomerDuplicate records a staff member's decision that a
+// pending pair are different people.
+func (s *CustomerService) DismissCustomerDuplicate(ctx context.Context, duplicateID, staffID int32) (model.CustomerDuplicate, error) {
+	if duplicateID <= 0 {
+		return model.CustomerDuplicate{}, fmt.Errorf("duplicate_id must be positive: %w", ErrInvalidArgument)
+	}
+	if staffID <= 0 {
+		return model.CustomerDuplicate{}, fmt.Errorf("staff_id must be positive: %w", ErrInvalidArgument)
+	}
+
+	dismissed, err := s.duplicateRepo.DismissCustomerDuplicate(ctx, duplicateID, staffID)
+	if err != nil {
+		return model.CustomerDuplicate{}, err
+	}
+
+	duplicate, err := s.duplicateRepo.GetCustomerDuplicate(ctx, duplicateID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.CustomerDuplicate{}, fmt.Errorf("customer duplicate %d: %w", duplicateID, ErrNotFound)
+		}
+		return model.CustomerDuplicate{}, err
+	}
+	if !dismissed {
+		return model.CustomerDuplicate{}, fmt.Errorf("customer duplicate %d is already %s: %w", duplicateID, duplicate.Status, ErrFailedPrecondition)
+	}
+	return duplicate, nil
+}
+
+// MergeCustomers merges a duplicate customer into the surviving one: the
+// duplicate's rentals, payments, subscription, gift cards, store credit,
+// loyalty points, reviews, wishlist and promotion usage move to the
+// surviving customer in one transaction, the duplicate is deactivated and
+// the merge is recorded. Where both have a live subscription or a review of
+// the same film, the surviving customer's is kept.
+func (s *CustomerService) MergeCustomers(ctx context.Context, survivingCustomerID, mergedCustomerID, staffID int32) (model.CustomerMerge, error) {
+	if survivingCustomerID <= 0 || mergedCustomerID <= 0 {
+		return model.CustomerMerge{}, fmt.Errorf("surviving_customer_id and merged_customer_id must be positive: %w", ErrInvalidArgument)
+	}
+	if survivingCustomerID == mergedCustomerID {
+		return model.CustomerMerge{}, fmt.Errorf("cannot merge customer %d into itself: %w", mergedCustomerID, ErrInvalidArgument)
+	}
+	if staffID <= 0 {
+		return model.CustomerMerge{}, fmt.Errorf("staff_id must be positive: %w", ErrInvalidArgument)
+	}
+
+	merge, err := s.duplicateRepo.MergeCustomers(ctx, survivingCustomerID, mergedCustomerID, staffID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.CustomerMerge{}, fmt.Errorf("customer %d or %d: %w", survivingCustomerID, mergedCustomerID, ErrNotFound)
+		}
+		if errors.Is(err, repository.ErrNotMergeable) {
+			return model.CustomerMerge{}, fmt.Errorf("%v: %w", err, ErrFailedPrecondition)
+		}
+		if isForeignKeyViolation(err) {
+			return model.CustomerMerge{}, fmt.Errorf("invalid staff_id: %w", ErrInvalidArgument)
+		}
+		return model.CustomerMerge{}, err
+	}
+	return merge, nil
+}
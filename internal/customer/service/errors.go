@@ -11,4 +11,7 @@ var (
 	ErrAlreadyExists = errors.New("already exists")
 	// ErrForeignKey indicates the entity is referenced by another entity.
 	ErrForeignKey = errors.New("referenced by another entity")
+	// ErrFailedPrecondition indicates the entity is not in a state that
+	// allows the operation.
+	ErrFailedPrecondition = errors.New("failed precondition")
 )
@@ -0,0 +1,30 @@
+package service
+
+import "strings"
+
+// minPhoneSearchDigits is the fewest digits a query needs to be matched
+// against phone numbers; shorter numbers match too many customers.
+const minPhoneSearchDigits = 3
+
+// likePrefix returns an ILIKE pattern matching strings that start with s.
+func likePrefix(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return r.Replace(s) + "%"
+}
+
+// phonePrefix returns a LIKE pattern matching phone digits that start with
+// the digits of query, or "" if query does not look like a phone number:
+// it may only hold digits, spaces, dashes, dots, parentheses and a leading
+// +, and needs at least minPhoneSearchDigits digits.
+func phonePrefix(query string) string {
+	digits := strings.TrimPrefix(phoneSeparators.Replace(query), "+")
+	if len(digits) < minPhoneSearchDigits {
+		return ""
+	}
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return ""
+		}
+	}
+	return digits + "%"
+}
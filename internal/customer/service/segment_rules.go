@@ -0,0 +1,244 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/enkaigaku/dvd-rental/internal/customer/model"
+)
+
+// Segment rule group operators.
+const (
+	segmentOpAll = "all" // every child rule matches
+	segmentOpAny = "any" // at least one child rule matches
+	segmentOpNot = "not" // the single child rule does not match
+)
+
+// Segment rule fields. Numeric fields take the comparison operators;
+// days_since_last_rental never matches customers who have not rented.
+// category_rentals counts rentals of films in Category; favourite_category
+// tests whether Category is one of the customer's most rented categories,
+// as listed in their summary.
+const (
+	segmentFieldLifetimeSpend       = "lifetime_spend"
+	segmentFieldSpendPercentile     = "spend_percentile"
+	segmentFieldDaysSinceLastRental = "days_since_last_rental"
+	segmentFieldTotalRentals        = "total_rentals"
+	segmentFieldCategoryRentals     = "category_rentals"
+	segmentFieldFavouriteCategory   = "favourite_category"
+	segmentFieldStoreID             = "store_id"
+	segmentFieldActive              = "active"
+)
+
+const (
+	// maxSegmentRuleDepth is how deeply segment rule groups may nest.
+	maxSegmentRuleDepth = 8
+	// maxSegmentRuleNodes is the most groups and conditions a segment
+	// rule tree may have.
+	maxSegmentRuleNodes = 64
+)
+
+// validateSegmentRule checks that a rule tree is well formed: known
+// operators and fields, the values each condition needs, and within the
+// size limits.
+func validateSegmentRule(rule model.SegmentRule) error {
+	nodes := 0
+	return validateSegmentRuleNode(rule, 1, &nodes)
+}
+
+func validateSegmentRuleNode(rule model.SegmentRule, depth int, nodes *int) error {
+	*nodes++
+	if *nodes > maxSegmentRuleNodes {
+		return fmt.Errorf("rule has more than %d conditions and groups: %w", maxSegmentRuleNodes, ErrInvalidArgument)
+	}
+	if depth > maxSegmentRuleDepth {
+		return fmt.Errorf("rule groups nest deeper than %d levels: %w", maxSegmentRuleDepth, ErrInvalidArgument)
+	}
+
+	switch rule.Op {
+	case segmentOpAll, segmentOpAny, segmentOpNot:
+		if rule.Field != "" || rule.Category != "" || len(rule.Values) > 0 {
+			return fmt.Errorf("%q group takes only rules: %w", rule.Op, ErrInvalidArgument)
+		}
+		if len(rule.Rules) == 0 {
+			return fmt.Errorf("%q group needs at least one rule: %w", rule.Op, ErrInvalidArgument)
+		}
+		if rule.Op == segmentOpNot && len(rule.Rules) != 1 {
+			return fmt.Errorf("%q group takes exactly one rule: %w", rule.Op, ErrInvalidArgument)
+		}
+		for _, child := range rule.Rules {
+			if err := validateSegmentRuleNode(child, depth+1, nodes); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(rule.Rules) > 0 {
+		return fmt.Errorf("condition on %q cannot have rules: %w", rule.Field, ErrInvalidArgument)
+	}
+	return validateSegmentCondition(rule)
+}
+
+func validateSegmentCondition(rule model.SegmentRule) error {
+	switch rule.Field {
+	case segmentFieldLifetimeSpend, segmentFieldSpendPercentile, segmentFieldDaysSinceLastRental,
+		segmentFieldTotalRentals, segmentFieldCategoryRentals:
+		if rule.Field == segmentFieldCategoryRentals && strings.TrimSpace(rule.Category) == "" {
+			return fmt.Errorf("%s needs a category: %w", rule.Field, ErrInvalidArgument)
+		}
+		if rule.Field != segmentFieldCategoryRentals && rule.Category != "" {
+			return fmt.Errorf("%s takes no category: %w", rule.Field, ErrInvalidArgument)
+		}
+		return validateComparison(rule, "eq", "ne", "lt", "lte", "gt", "gte", "in")
+
+	case segmentFieldStoreID:
+		if rule.Category != "" {
+			return fmt.Errorf("%s takes no category: %w", rule.Field, ErrInvalidArgument)
+		}
+		return validateComparison(rule, "eq", "ne", "in")
+
+	case segmentFieldActive:
+		if rule.Category != "" {
+			return fmt.Errorf("%s takes no category: %w", rule.Field, ErrInvalidArgument)
+		}
+		if err := validateComparison(rule, "eq", "ne"); err != nil {
+			return err
+		}
+		if v := rule.Values[0]; v != 0 && v != 1 {
+			return fmt.Errorf("%s is compared with 1 (active) or 0 (inactive): %w", rule.Field, ErrInvalidArgument)
+		}
+		return nil
+
+	case segmentFieldFavouriteCategory:
+		if strings.TrimSpace(rule.Category) == "" {
+			return fmt.Errorf("%s needs a category: %w", rule.Field, ErrInvalidArgument)
+		}
+		if rule.Op != "eq" && rule.Op != "ne" {
+			return fmt.Errorf("%s takes the eq or ne operator: %w", rule.Field, ErrInvalidArgument)
+		}
+		if len(rule.Values) > 0 {
+			return fmt.Errorf("%s takes no values: %w", rule.Field, ErrInvalidArgument)
+		}
+		return nil
+
+	case "":
+		return fmt.Errorf("rule needs a group operator (all, any, not) or a field: %w", ErrInvalidArgument)
+	}
+	return fmt.Errorf("unknown rule field %q: %w", rule.Field, ErrInvalidArgument)
+}
+
+// validateComparison checks a condition's operator is one of ops and that
+// it has one value, or at least one for "in".
+func validateComparison(rule model.SegmentRule, ops ...string) error {
+	allowed := false
+	for _, op := range ops {
+		if rule.Op == op {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return fmt.Errorf("%s takes the %s operators: %w", rule.Field, strings.Join(ops, ", "), ErrInvalidArgument)
+	}
+	if rule.Op == "in" {
+		if len(rule.Values) == 0 {
+			return fmt.Errorf("%s in needs at least one value: %w", rule.Field, ErrInvalidArgument)
+		}
+	} else if len(rule.Values) != 1 {
+		return fmt.Errorf("%s %s needs exactly one value: %w", rule.Field, rule.Op, ErrInvalidArgument)
+	}
+	return nil
+}
+
+// matchSegmentRule reports whether a customer matches a validated rule
+// tree, with rental recency measured from now.
+func matchSegmentRule(rule model.SegmentRule, c model.SegmentCandidate, now time.Time) bool {
+	switch rule.Op {
+	case segmentOpAll:
+		for _, child := range rule.Rules {
+			if !matchSegmentRule(child, c, now) {
+				return false
+			}
+		}
+		return true
+	case segmentOpAny:
+		for _, child := range rule.Rules {
+			if matchSegmentRule(child, c, now) {
+				return true
+			}
+		}
+		return false
+	case segmentOpNot:
+		return !matchSegmentRule(rule.Rules[0], c, now)
+	}
+
+	switch rule.Field {
+	case segmentFieldFavouriteCategory:
+		favourite := false
+		for i, cat := range c.Categories {
+			if i == favouriteCategoryCount {
+				break
+			}
+			if strings.EqualFold(cat.Name, strings.TrimSpace(rule.Category)) {
+				favourite = true
+				break
+			}
+		}
+		return favourite == (rule.Op == "eq")
+	case segmentFieldActive:
+		active := 0.0
+		if c.Active {
+			active = 1
+		}
+		return compareSegmentValue(rule.Op, active, rule.Values)
+	case segmentFieldDaysSinceLastRental:
+		if c.LastRentalDate.IsZero() {
+			return false
+		}
+		return compareSegmentValue(rule.Op, now.Sub(c.LastRentalDate).Hours()/24, rule.Values)
+	case segmentFieldLifetimeSpend:
+		return compareSegmentValue(rule.Op, c.LifetimeSpend, rule.Values)
+	case segmentFieldSpendPercentile:
+		return compareSegmentValue(rule.Op, c.SpendPercentile, rule.Values)
+	case segmentFieldTotalRentals:
+		return compareSegmentValue(rule.Op, float64(c.TotalRentals), rule.Values)
+	case segmentFieldStoreID:
+		return compareSegmentValue(rule.Op, float64(c.StoreID), rule.Values)
+	case segmentFieldCategoryRentals:
+		var rentals int32
+		for _, cat := range c.Categories {
+			if strings.EqualFold(cat.Name, strings.TrimSpace(rule.Category)) {
+				rentals = cat.Rentals
+				break
+			}
+		}
+		return compareSegmentValue(rule.Op, float64(rentals), rule.Values)
+	}
+	return false
+}
+
+func compareSegmentValue(op string, x float64, values []float64) bool {
+	switch op {
+	case "eq":
+		return x == values[0]
+	case "ne":
+		return x != values[0]
+	case "lt":
+		return x < values[0]
+	case "lte":
+		return x <= values[0]
+	case "gt":
+		return x > values[0]
+	case "gte":
+		return x >= values[0]
+	case "in":
+		for _, v := range values {
+			if x == v {
+				return true
+			}
+		}
+	}
+	return false
+}
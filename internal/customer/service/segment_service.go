@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/enkaigaku/dvd-rental/internal/customer/model"
+	"github.com/enkaigaku/dvd-rental/internal/customer/repository"
+)
+
+// maxSegmentNameLength is the longest a segment name may be, in characters.
+const maxSegmentNameLength = 100
+
+// SegmentService contains business logic for customer segments.
+type SegmentService struct {
+	segmentRepo repository.SegmentRepository
+}
+
+// NewSegmentService creates a new SegmentService.
+func NewSegmentService(segmentRepo repository.SegmentRepository) *SegmentService {
+	return &SegmentService{segmentRepo: segmentRepo}
+}
+
+// GetSegment returns a segment by ID.
+func (s *SegmentService) GetSegment(ctx context.Context, segmentID int32) (model.Segment, error) {
+	if segmentID <= 0 {
+		return model.Segment{}, fmt.Errorf("segment_id must be positive: %w", ErrInvalidArgument)
+	}
+
+	segment, err := s.segmentRepo.GetSegment(ctx, segmentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.Segment{}, fmt.Errorf("segment %d: %w", segmentID, ErrNotFound)
+		}
+		return model.Segment{}, err
+	}
+	return segment, nil
+}
+
+// ListSegments returns a page of segments ordered by name and the total
+// count.
+func (s *SegmentService) ListSegments(ctx context.Context, pageSize, page int32) ([]model.Segment, int64, error) {
+	pageSize, page = clampPagination(pageSize, page)
+	offset := (page - 1) * pageSize
+
+	segments, err := s.segmentRepo.ListSegments(ctx, pageSize, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.segmentRepo.CountSegments(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return segments, total, nil
+}
+
+// CreateSegment saves a new segment and evaluates it, so it is returned
+// with its members.
+func (s *SegmentService) CreateSegment(ctx context.Context, params repository.SegmentParams) (model.Segment, error) {
+	params, err := normalizeSegmentParams(params)
+	if err != nil {
+		return model.Segment{}, err
+	}
+
+	segment, err := s.segmentRepo.CreateSegment(ctx, params)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return model.Segment{}, fmt.Errorf("segment %q already exists: %w", params.Name, ErrAlreadyExists)
+		}
+		return model.Segment{}, err
+	}
+
+	return s.EvaluateSegment(ctx, segment.SegmentID)
+}
+
+// UpdateSegment replaces a segment's name, description and rule, and
+// re-evaluates its members.
+func (s *SegmentService) UpdateSegment(ctx context.Context, segmentID int32, params repository.SegmentParams) (model.Segment, error) {
+	if segmentID <= 0 {
+		return model.Segment{}, fmt.Errorf("segment_id must be positive: %w", ErrInvalidArgument)
+	}
+	params, err := normalizeSegmentParams(params)
+	if err != nil {
+		return model.Segment{}, err
+	}
+
+	if _, err := s.segmentRepo.UpdateSegment(ctx, segmentID, params); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.Segment{}, fmt.Errorf("segment %d: %w", segmentID, ErrNotFound)
+		}
+		if isUniqueViolation(err) {
+			return model.Segment{}, fmt.Errorf("segment %q already exists: %w", params.Name, ErrAlreadyExists)
+		}
+		return model.Segment{}, err
+	}
+
+	return s.EvaluateSegment(ctx, segmentID)
+}
+
+// DeleteSegment deletes a segment and its members.
+func (s *SegmentService) DeleteSegment(ctx context.Context, segmentID int32) error {
+	if segmentID <= 0 {
+		return fmt.Errorf("segment_id must be positive: %w", ErrInvalidArgument)
+	}
+
+	if err := s.segmentRepo.DeleteSegment(ctx, segmentID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("segment %d: %w", segmentID, ErrNotFound)
+		}
+		return err
+	}
+	return nil
+}
+
+// PreviewSegment counts the customers a rule matches, in total and per
+// store, without saving anything.
+func (s *SegmentService) PreviewSegment(ctx context.Context, rule model.SegmentRule) (model.SegmentPreview, error) {
+	if err := validateSegmentRule(rule); err != nil {
+		return model.SegmentPreview{}, err
+	}
+
+	members, err := s.matchCandidates(ctx, rule)
+	if err != nil {
+		return model.SegmentPreview{}, err
+	}
+
+	byStore := make(map[int32]int32)
+	for _, c := range members {
+		byStore[c.StoreID]++
+	}
+	preview := model.SegmentPreview{MemberCount: int32(len(members))}
+	for storeID, count := range byStore {
+		preview.Stores = append(preview.Stores, model.SegmentStoreCount{StoreID: storeID, MemberCount: count})
+	}
+	sort.Slice(preview.Stores, func(i, j int) bool {
+		return preview.Stores[i].StoreID < preview.Stores[j].StoreID
+	})
+	return preview, nil
+}
+
+// EvaluateSegment runs a segment's rule against current customer data and
+// replaces its members with the customers it matches.
+func (s *SegmentService) EvaluateSegment(ctx context.Context, segmentID int32) (model.Segment, error) {
+	segment, err := s.GetSegment(ctx, segmentID)
+	if err != nil {
+		return model.Segment{}, err
+	}
+
+	members, err := s.matchCandidates(ctx, segment.Rule)
+	if err != nil {
+		return model.Segment{}, err
+	}
+	customerIDs := make([]int32, len(members))
+	for i, c := range members {
+		customerIDs[i] = c.CustomerID
+	}
+
+	segment, err = s.segmentRepo.ReplaceSegmentMembers(ctx, segmentID, customerIDs)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.Segment{}, fmt.Errorf("segment %d: %w", segmentID, ErrNotFound)
+		}
+		return model.Segment{}, err
+	}
+	return segment, nil
+}
+
+// ListSegmentMembers returns a page of a segment's members, as of its
+// last evaluation, ordered by customer ID, and the total count.
+func (s *SegmentService) ListSegmentMembers(ctx context.Context, segmentID, pageSize, page int32) ([]model.Customer, int64, error) {
+	if _, err := s.GetSegment(ctx, segmentID); err != nil {
+		return nil, 0, err
+	}
+	pageSize, page = clampPagination(pageSize, page)
+	offset := (page - 1) * pageSize
+
+	customers, err := s.segmentRepo.ListSegmentMembers(ctx, segmentID, pageSize, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.segmentRepo.CountSegmentMembers(ctx, segmentID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return customers, total, nil
+}
+
+// matchCandidates returns the customers a validated rule matches.
+func (s *SegmentService) matchCandidates(ctx context.Context, rule model.SegmentRule) ([]model.SegmentCandidate, error) {
+	candidates, err := s.segmentRepo.ListSegmentCandidates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var members []model.SegmentCandidate
+	for _, c := range candidates {
+		if matchSegmentRule(rule, c, now) {
+			members = append(members, c)
+		}
+	}
+	return members, nil
+}
+
+func normalizeSegmentParams(params repository.SegmentParams) (repository.SegmentParams, error) {
+	params.Name = strings.TrimSpace(params.Name)
+	params.Description = strings.TrimSpace(params.Description)
+	if params.Name == "" {
+		return params, fmt.Errorf("name must not be empty: %w", ErrInvalidArgument)
+	}
+	if utf8.RuneCountInString(params.Name) > maxSegmentNameLength {
+		return params, fmt.Errorf("name must be at most %d characters: %w", maxSegmentNameLength, ErrInvalidArgument)
+	}
+	if err := validateSegmentRule(params.Rule); err != nil {
+		return params, err
+	}
+	return params, nil
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/enkaigaku/dvd-rental/internal/customer/model"
+)
+
+const (
+	// favouriteCategoryCount is how many favourite categories a customer
+	// summary lists.
+	favouriteCategoryCount = 3
+	// maxSummaryBatch is the most customers ListCustomerSummaries takes.
+	maxSummaryBatch = 500
+)
+
+// GetCustomerSummary returns a customer's rental activity and lifetime
+// value.
+func (s *CustomerService) GetCustomerSummary(ctx context.Context, customerID int32) (model.CustomerSummary, error) {
+	if customerID <= 0 {
+		return model.CustomerSummary{}, fmt.Errorf("customer_id must be positive: %w", ErrInvalidArgument)
+	}
+
+	summaries, err := s.customerRepo.ListCustomerSummaries(ctx, []int32{customerID}, favouriteCategoryCount)
+	if err != nil {
+		return model.CustomerSummary{}, err
+	}
+	if len(summaries) == 0 {
+		return model.CustomerSummary{}, fmt.Errorf("customer %d: %w", customerID, ErrNotFound)
+	}
+	return summaries[0], nil
+}
+
+// ListCustomerSummaries returns the summaries of several customers in the
+// order they are given. Unknown and repeated IDs are skipped.
+func (s *CustomerService) ListCustomerSummaries(ctx context.Context, customerIDs []int32) ([]model.CustomerSummary, error) {
+	if len(customerIDs) > maxSummaryBatch {
+		return nil, fmt.Errorf("at most %d customer_ids are allowed: %w", maxSummaryBatch, ErrInvalidArgument)
+	}
+	for _, id := range customerIDs {
+		if id <= 0 {
+			return nil, fmt.Errorf("customer_ids must be positive: %w", ErrInvalidArgument)
+		}
+	}
+	if len(customerIDs) == 0 {
+		return nil, nil
+	}
+
+	summaries, err := s.customerRepo.ListCustomerSummaries(ctx, customerIDs, favouriteCategoryCount)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int32]model.CustomerSummary, len(summaries))
+	for _, summary := range summaries {
+		byID[summary.CustomerID] = summary
+	}
+	ordered := make([]model.CustomerSummary, 0, len(summaries))
+	for _, id := range customerIDs {
+		if summary, ok := byID[id]; ok {
+			ordered = append(ordered, summary)
+			delete(byID, id)
+		}
+	}
+	return ordered, nil
+}
@@ -2,6 +2,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/kelseyhightower/envconfig"
 )
 
@@ -10,6 +12,21 @@ type Config struct {
 	DatabaseURL string `envconfig:"DATABASE_URL" required:"true"`
 	GRPCPort    string `envconfig:"GRPC_PORT" default:"50052"`
 	LogLevel    string `envconfig:"LOG_LEVEL" default:"info"`
+
+	// AssetDir is the directory film posters and stills are stored in.
+	AssetDir string `envconfig:"ASSET_DIR" default:"data/assets"`
+
+	// RentalServiceAddr is the rental service, asked whether a customer has
+	// rented a film before they may review it.
+	RentalServiceAddr string `envconfig:"GRPC_RENTAL_ADDR" default:"localhost:50054"`
+
+	// SimilarityRefreshInterval is how often film similarities are
+	// recomputed from the rental history; 0 disables the refresh job.
+	SimilarityRefreshInterval time.Duration `envconfig:"SIMILARITY_REFRESH_INTERVAL" default:"24h"`
+
+	// PopularityRefreshInterval is how often the rental counts behind the
+	// trending and popular lists are recomputed; 0 disables the refresh job.
+	PopularityRefreshInterval time.Duration `envconfig:"POPULARITY_REFRESH_INTERVAL" default:"1h"`
 }
 
 // Load reads configuration from environment variables.
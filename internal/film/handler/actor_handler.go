@@ -29,8 +29,24 @@ func (h *ActorHandler) GetActor(ctx context.Context, req *filmv1.GetActorRequest
 	return actorToProto(actor), nil
 }
 
+func (h *ActorHandler) GetActorDetail(ctx context.Context, req *filmv1.GetActorDetailRequest) (*filmv1.ActorDetail, error) {
+	detail, err := h.svc.GetActorDetail(ctx, req.GetActorId(), req.GetCoStarLimit())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return actorDetailToProto(detail), nil
+}
+
 func (h *ActorHandler) ListActors(ctx context.Context, req *filmv1.ListActorsRequest) (*filmv1.ListActorsResponse, error) {
-	actors, total, err := h.svc.ListActors(ctx, req.GetPageSize(), req.GetPage())
+	actors, total, err := h.svc.ListActors(ctx, req.GetIncludeArchived(), req.GetPageSize(), req.GetPage())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return toActorListResponse(actors, total), nil
+}
+
+func (h *ActorHandler) SearchActors(ctx context.Context, req *filmv1.SearchActorsRequest) (*filmv1.ListActorsResponse, error) {
+	actors, total, err := h.svc.SearchActors(ctx, req.GetQuery(), req.GetIncludeArchived(), req.GetPageSize(), req.GetPage())
 	if err != nil {
 		return nil, toGRPCError(err)
 	}
@@ -68,6 +84,14 @@ func (h *ActorHandler) DeleteActor(ctx context.Context, req *filmv1.DeleteActorR
 	return &emptypb.Empty{}, nil
 }
 
+func (h *ActorHandler) RestoreActor(ctx context.Context, req *filmv1.RestoreActorRequest) (*filmv1.Actor, error) {
+	actor, err := h.svc.RestoreActor(ctx, req.GetActorId())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return actorToProto(actor), nil
+}
+
 func toActorListResponse(actors []model.Actor, total int64) *filmv1.ListActorsResponse {
 	pbActors := make([]*filmv1.Actor, len(actors))
 	for i, a := range actors {
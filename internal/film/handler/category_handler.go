@@ -3,6 +3,8 @@ package handler
 import (
 	"context"
 
+	"google.golang.org/protobuf/types/known/emptypb"
+
 	filmv1 "github.com/enkaigaku/dvd-rental/gen/proto/film/v1"
 	"github.com/enkaigaku/dvd-rental/internal/film/model"
 	"github.com/enkaigaku/dvd-rental/internal/film/service"
@@ -35,6 +37,29 @@ func (h *CategoryHandler) ListCategories(ctx context.Context, req *filmv1.ListCa
 	return toCategoryListResponse(categories, total), nil
 }
 
+func (h *CategoryHandler) CreateCategory(ctx context.Context, req *filmv1.CreateCategoryRequest) (*filmv1.Category, error) {
+	category, err := h.svc.CreateCategory(ctx, req.GetName())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return categoryToProto(category), nil
+}
+
+func (h *CategoryHandler) UpdateCategory(ctx context.Context, req *filmv1.UpdateCategoryRequest) (*filmv1.Category, error) {
+	category, err := h.svc.UpdateCategory(ctx, req.GetCategoryId(), req.GetName())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return categoryToProto(category), nil
+}
+
+func (h *CategoryHandler) DeleteCategory(ctx context.Context, req *filmv1.DeleteCategoryRequest) (*emptypb.Empty, error) {
+	if err := h.svc.DeleteCategory(ctx, req.GetCategoryId(), req.GetReassignTo()); err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
 func toCategoryListResponse(categories []model.Category, total int64) *filmv1.ListCategoriesResponse {
 	pbCategories := make([]*filmv1.Category, len(categories))
 	for i, c := range categories {
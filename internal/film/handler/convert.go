@@ -22,13 +22,15 @@ func toGRPCError(err error) error {
 		return status.Error(codes.AlreadyExists, err.Error())
 	case errors.Is(err, service.ErrForeignKey):
 		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, service.ErrPermissionDenied):
+		return status.Error(codes.PermissionDenied, err.Error())
 	default:
 		return status.Error(codes.Internal, "internal error")
 	}
 }
 
 func filmToProto(f model.Film) *filmv1.Film {
-	return &filmv1.Film{
+	pb := &filmv1.Film{
 		FilmId:             f.FilmID,
 		Title:              f.Title,
 		Description:        f.Description,
@@ -42,6 +44,20 @@ func filmToProto(f model.Film) *filmv1.Film {
 		Rating:             f.Rating,
 		SpecialFeatures:    f.SpecialFeatures,
 		LastUpdate:         timestamppb.New(f.LastUpdate),
+		Poster:             filmAssetToProtoOrNil(f.Poster),
+	}
+	if !f.ArchivedAt.IsZero() {
+		pb.ArchivedAt = timestamppb.New(f.ArchivedAt)
+	}
+	return pb
+}
+
+func filmSearchHitToProto(h model.FilmSearchHit) *filmv1.FilmSearchHit {
+	return &filmv1.FilmSearchHit{
+		Film:                 filmToProto(h.Film),
+		TitleHighlight:       h.TitleHighlight,
+		DescriptionHighlight: h.DescriptionHighlight,
+		Fuzzy:                h.Fuzzy,
 	}
 }
 
@@ -56,22 +72,187 @@ func filmDetailToProto(d model.FilmDetail) *filmv1.FilmDetail {
 		categories[i] = categoryToProto(c)
 	}
 
+	stills := make([]*filmv1.FilmAsset, len(d.Stills))
+	for i, a := range d.Stills {
+		stills[i] = filmAssetToProto(a)
+	}
+
 	return &filmv1.FilmDetail{
 		Film:                 filmToProto(d.Film),
 		LanguageName:         d.LanguageName,
 		OriginalLanguageName: d.OriginalLanguageName,
 		Actors:               actors,
 		Categories:           categories,
+		AverageRating:        d.AverageRating,
+		ReviewCount:          d.ReviewCount,
+		Stills:               stills,
+	}
+}
+
+func filmAssetToProto(a model.FilmAsset) *filmv1.FilmAsset {
+	return &filmv1.FilmAsset{
+		AssetId:     a.AssetID,
+		FilmId:      a.FilmID,
+		Kind:        a.Kind,
+		ContentType: a.ContentType,
+		Width:       a.Width,
+		Height:      a.Height,
+		Sizes:       a.Sizes,
+		CreatedAt:   timestamppb.New(a.CreatedAt),
+	}
+}
+
+func filmAssetToProtoOrNil(a *model.FilmAsset) *filmv1.FilmAsset {
+	if a == nil {
+		return nil
+	}
+	return filmAssetToProto(*a)
+}
+
+func reviewToProto(r model.Review) *filmv1.Review {
+	return &filmv1.Review{
+		ReviewId:       r.ReviewID,
+		FilmId:         r.FilmID,
+		FilmTitle:      r.FilmTitle,
+		CustomerId:     r.CustomerID,
+		CustomerName:   r.CustomerName,
+		Rating:         int32(r.Rating),
+		Body:           r.Body,
+		Status:         r.Status,
+		ModerationNote: r.ModerationNote,
+		CreatedAt:      timestamppb.New(r.CreatedAt),
+		LastUpdate:     timestamppb.New(r.LastUpdate),
+	}
+}
+
+func wishlistItemToProto(item model.WishlistItem) *filmv1.WishlistItem {
+	return &filmv1.WishlistItem{
+		Film:            filmToProto(item.Film),
+		AddedAt:         timestamppb.New(item.AddedAt),
+		StoreId:         item.StoreID,
+		Copies:          item.Copies,
+		AvailableCopies: item.AvailableCopies,
+	}
+}
+
+func recommendationsToProto(recs []model.Recommendation) *filmv1.ListRecommendationsResponse {
+	pbRecs := make([]*filmv1.Recommendation, len(recs))
+	for i, r := range recs {
+		pbRecs[i] = &filmv1.Recommendation{
+			Film:          filmToProto(r.Film),
+			Score:         r.Score,
+			BecauseFilmId: r.BecauseFilmID,
+			BecauseTitle:  r.BecauseTitle,
+		}
+	}
+	return &filmv1.ListRecommendationsResponse{Recommendations: pbRecs}
+}
+
+func filmRecordToProto(r model.FilmRecord) *filmv1.FilmRecord {
+	return &filmv1.FilmRecord{
+		FilmId:           r.FilmID,
+		Title:            r.Title,
+		Description:      r.Description,
+		ReleaseYear:      r.ReleaseYear,
+		Language:         r.Language,
+		OriginalLanguage: r.OriginalLanguage,
+		RentalDuration:   int32(r.RentalDuration),
+		RentalRate:       r.RentalRate,
+		Length:           int32(r.Length),
+		ReplacementCost:  r.ReplacementCost,
+		Rating:           r.Rating,
+		SpecialFeatures:  r.SpecialFeatures,
+		Actors:           r.Actors,
+		Categories:       r.Categories,
+	}
+}
+
+func filmRecordFromProto(r *filmv1.FilmRecord) model.FilmRecord {
+	return model.FilmRecord{
+		Film: model.Film{
+			Title:           r.GetTitle(),
+			Description:     r.GetDescription(),
+			ReleaseYear:     r.GetReleaseYear(),
+			RentalDuration:  int16(r.GetRentalDuration()),
+			RentalRate:      r.GetRentalRate(),
+			Length:          int16(r.GetLength()),
+			ReplacementCost: r.GetReplacementCost(),
+			Rating:          r.GetRating(),
+			SpecialFeatures: r.GetSpecialFeatures(),
+		},
+		Language:         r.GetLanguage(),
+		OriginalLanguage: r.GetOriginalLanguage(),
+		Actors:           r.GetActors(),
+		Categories:       r.GetCategories(),
+	}
+}
+
+func filmImportReportToProto(r model.FilmImportReport) *filmv1.ImportFilmsResponse {
+	pbErrors := make([]*filmv1.ImportRowError, len(r.Errors))
+	for i, e := range r.Errors {
+		pbErrors[i] = &filmv1.ImportRowError{
+			Row:     e.Row,
+			Title:   e.Title,
+			Message: e.Message,
+		}
+	}
+	return &filmv1.ImportFilmsResponse{
+		DryRun:        r.DryRun,
+		Rows:          r.Rows,
+		Imported:      r.Imported,
+		Failed:        r.Failed,
+		ActorsCreated: r.ActorsCreated,
+		Errors:        pbErrors,
 	}
 }
 
 func actorToProto(a model.Actor) *filmv1.Actor {
-	return &filmv1.Actor{
+	pb := &filmv1.Actor{
 		ActorId:    a.ActorID,
 		FirstName:  a.FirstName,
 		LastName:   a.LastName,
 		LastUpdate: timestamppb.New(a.LastUpdate),
 	}
+	if !a.ArchivedAt.IsZero() {
+		pb.ArchivedAt = timestamppb.New(a.ArchivedAt)
+	}
+	return pb
+}
+
+func actorDetailToProto(d model.ActorDetail) *filmv1.ActorDetail {
+	filmography := make([]*filmv1.ActorCategoryFilms, len(d.Filmography))
+	for i, c := range d.Filmography {
+		films := make([]*filmv1.ActorFilm, len(c.Films))
+		for j, f := range c.Films {
+			films[j] = &filmv1.ActorFilm{
+				FilmId:      f.FilmID,
+				Title:       f.Title,
+				ReleaseYear: f.ReleaseYear,
+				Rating:      f.Rating,
+			}
+		}
+		filmography[i] = &filmv1.ActorCategoryFilms{
+			CategoryId:   c.CategoryID,
+			CategoryName: c.CategoryName,
+			Films:        films,
+		}
+	}
+
+	coStars := make([]*filmv1.CoStar, len(d.CoStars))
+	for i, c := range d.CoStars {
+		coStars[i] = &filmv1.CoStar{
+			Actor:         actorToProto(c.Actor),
+			FilmsTogether: c.FilmsTogether,
+		}
+	}
+
+	return &filmv1.ActorDetail{
+		Actor:       actorToProto(d.Actor),
+		FilmCount:   d.FilmCount,
+		RentalCount: d.RentalCount,
+		Filmography: filmography,
+		CoStars:     coStars,
+	}
 }
 
 func categoryToProto(c model.Category) *filmv1.Category {
@@ -89,3 +270,23 @@ func languageToProto(l model.Language) *filmv1.Language {
 		LastUpdate: timestamppb.New(l.LastUpdate),
 	}
 }
+
+func filmFacetsToProto(f model.FilmFacets) *filmv1.FilmFacets {
+	return &filmv1.FilmFacets{
+		Ratings:    facetCountsToProto(f.Ratings),
+		Categories: facetCountsToProto(f.Categories),
+		Languages:  facetCountsToProto(f.Languages),
+	}
+}
+
+func facetCountsToProto(counts []model.FacetCount) []*filmv1.FacetCount {
+	pb := make([]*filmv1.FacetCount, len(counts))
+	for i, c := range counts {
+		pb[i] = &filmv1.FacetCount{
+			Id:    c.ID,
+			Value: c.Value,
+			Count: c.Count,
+		}
+	}
+	return pb
+}
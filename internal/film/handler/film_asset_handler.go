@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	filmv1 "github.com/enkaigaku/dvd-rental/gen/proto/film/v1"
+	"github.com/enkaigaku/dvd-rental/internal/film/service"
+)
+
+// assetChunkSize is the size of the pieces images are streamed in.
+const assetChunkSize = 64 << 10
+
+// FilmAssetHandler implements the FilmAssetService gRPC interface.
+type FilmAssetHandler struct {
+	filmv1.UnimplementedFilmAssetServiceServer
+	svc *service.FilmAssetService
+}
+
+// NewFilmAssetHandler creates a new FilmAssetHandler.
+func NewFilmAssetHandler(svc *service.FilmAssetService) *FilmAssetHandler {
+	return &FilmAssetHandler{svc: svc}
+}
+
+func (h *FilmAssetHandler) UploadFilmAsset(stream filmv1.FilmAssetService_UploadFilmAssetServer) error {
+	first, err := stream.Recv()
+	if errors.Is(err, io.EOF) {
+		return status.Error(codes.InvalidArgument, "empty upload")
+	}
+	if err != nil {
+		return err
+	}
+
+	data := first.GetChunk()
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if len(data)+len(req.GetChunk()) > service.MaxFilmAssetBytes {
+			return status.Error(codes.InvalidArgument, fmt.Sprintf("image exceeds %d bytes", service.MaxFilmAssetBytes))
+		}
+		data = append(data, req.GetChunk()...)
+	}
+
+	asset, err := h.svc.UploadFilmAsset(stream.Context(), first.GetFilmId(), first.GetKind(), data)
+	if err != nil {
+		return toGRPCError(err)
+	}
+	return stream.SendAndClose(filmAssetToProto(asset))
+}
+
+func (h *FilmAssetHandler) ListFilmAssets(ctx context.Context, req *filmv1.ListFilmAssetsRequest) (*filmv1.ListFilmAssetsResponse, error) {
+	assets, err := h.svc.ListFilmAssets(ctx, req.GetFilmId())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	pb := make([]*filmv1.FilmAsset, len(assets))
+	for i, a := range assets {
+		pb[i] = filmAssetToProto(a)
+	}
+	return &filmv1.ListFilmAssetsResponse{Assets: pb}, nil
+}
+
+func (h *FilmAssetHandler) GetFilmAssetContent(req *filmv1.GetFilmAssetContentRequest, stream filmv1.FilmAssetService_GetFilmAssetContentServer) error {
+	rc, contentType, err := h.svc.OpenFilmAsset(stream.Context(), req.GetAssetId(), req.GetSize())
+	if err != nil {
+		return toGRPCError(err)
+	}
+	defer rc.Close()
+
+	buf := make([]byte, assetChunkSize)
+	msg := &filmv1.FilmAssetContent{ContentType: contentType}
+	for {
+		n, err := io.ReadFull(rc, buf)
+		if n > 0 || msg.ContentType != "" {
+			msg.Chunk = buf[:n]
+			if err := stream.Send(msg); err != nil {
+				return err
+			}
+			msg = &filmv1.FilmAssetContent{}
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil
+		}
+		if err != nil {
+			return status.Error(codes.Internal, "internal error")
+		}
+	}
+}
+
+func (h *FilmAssetHandler) DeleteFilmAsset(ctx context.Context, req *filmv1.DeleteFilmAssetRequest) (*emptypb.Empty, error) {
+	if err := h.svc.DeleteFilmAsset(ctx, req.GetFilmId(), req.GetAssetId()); err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
@@ -2,6 +2,8 @@ package handler
 
 import (
 	"context"
+	"errors"
+	"io"
 
 	"google.golang.org/protobuf/types/known/emptypb"
 
@@ -23,7 +25,7 @@ func NewFilmHandler(svc *service.FilmService) *FilmHandler {
 }
 
 func (h *FilmHandler) GetFilm(ctx context.Context, req *filmv1.GetFilmRequest) (*filmv1.FilmDetail, error) {
-	detail, err := h.svc.GetFilm(ctx, req.GetFilmId())
+	detail, err := h.svc.GetFilm(ctx, req.GetFilmId(), req.GetIncludeArchived())
 	if err != nil {
 		return nil, toGRPCError(err)
 	}
@@ -31,19 +33,70 @@ func (h *FilmHandler) GetFilm(ctx context.Context, req *filmv1.GetFilmRequest) (
 }
 
 func (h *FilmHandler) ListFilms(ctx context.Context, req *filmv1.ListFilmsRequest) (*filmv1.ListFilmsResponse, error) {
-	films, total, err := h.svc.ListFilms(ctx, req.GetPageSize(), req.GetPage())
+	filter := repository.FilmFilter{
+		Ratings:         req.GetRatings(),
+		CategoryID:      req.GetCategoryId(),
+		ActorID:         req.GetActorId(),
+		LanguageID:      req.GetLanguageId(),
+		MinReleaseYear:  req.GetMinReleaseYear(),
+		MaxReleaseYear:  req.GetMaxReleaseYear(),
+		MinLength:       req.GetMinLength(),
+		MaxLength:       req.GetMaxLength(),
+		MinRentalRate:   req.GetMinRentalRate(),
+		MaxRentalRate:   req.GetMaxRentalRate(),
+		SpecialFeatures: req.GetSpecialFeatures(),
+		IncludeArchived: req.GetIncludeArchived(),
+	}
+	sort := repository.FilmSort{
+		By:         req.GetSortBy(),
+		Descending: req.GetDescending(),
+	}
+
+	films, total, err := h.svc.ListFilms(ctx, filter, sort, req.GetPageSize(), req.GetPage())
 	if err != nil {
 		return nil, toGRPCError(err)
 	}
-	return toFilmListResponse(films, total), nil
+	resp := toFilmListResponse(films, total)
+
+	if req.GetIncludeFacets() {
+		facets, err := h.svc.ListFilmFacets(ctx, filter)
+		if err != nil {
+			return nil, toGRPCError(err)
+		}
+		resp.Facets = filmFacetsToProto(facets)
+	}
+	return resp, nil
 }
 
-func (h *FilmHandler) SearchFilms(ctx context.Context, req *filmv1.SearchFilmsRequest) (*filmv1.ListFilmsResponse, error) {
-	films, total, err := h.svc.SearchFilms(ctx, req.GetQuery(), req.GetPageSize(), req.GetPage())
+func (h *FilmHandler) SearchFilms(ctx context.Context, req *filmv1.SearchFilmsRequest) (*filmv1.SearchFilmsResponse, error) {
+	hits, total, err := h.svc.SearchFilms(ctx, req.GetQuery(), req.GetPageSize(), req.GetPage())
 	if err != nil {
 		return nil, toGRPCError(err)
 	}
-	return toFilmListResponse(films, total), nil
+	resp := &filmv1.SearchFilmsResponse{
+		Hits:       make([]*filmv1.FilmSearchHit, len(hits)),
+		TotalCount: int32(total),
+	}
+	for i, hit := range hits {
+		resp.Hits[i] = filmSearchHitToProto(hit)
+	}
+	return resp, nil
+}
+
+func (h *FilmHandler) SuggestFilms(ctx context.Context, req *filmv1.SuggestFilmsRequest) (*filmv1.SuggestFilmsResponse, error) {
+	suggestions, err := h.svc.SuggestFilms(ctx, req.GetQuery(), req.GetLimit())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	resp := &filmv1.SuggestFilmsResponse{Suggestions: make([]*filmv1.FilmSuggestion, len(suggestions))}
+	for i, s := range suggestions {
+		resp.Suggestions[i] = &filmv1.FilmSuggestion{
+			FilmId:    s.FilmID,
+			Title:     s.Title,
+			Highlight: s.Highlight,
+		}
+	}
+	return resp, nil
 }
 
 func (h *FilmHandler) ListFilmsByCategory(ctx context.Context, req *filmv1.ListFilmsByCategoryRequest) (*filmv1.ListFilmsResponse, error) {
@@ -62,6 +115,30 @@ func (h *FilmHandler) ListFilmsByActor(ctx context.Context, req *filmv1.ListFilm
 	return toFilmListResponse(films, total), nil
 }
 
+func (h *FilmHandler) ListTrendingFilms(ctx context.Context, req *filmv1.ListTrendingFilmsRequest) (*filmv1.ListPopularFilmsResponse, error) {
+	films, total, err := h.svc.ListTrendingFilms(ctx, req.GetStoreId(), req.GetPageSize(), req.GetPage())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return toPopularFilmListResponse(films, total), nil
+}
+
+func (h *FilmHandler) ListPopularFilms(ctx context.Context, req *filmv1.ListPopularFilmsRequest) (*filmv1.ListPopularFilmsResponse, error) {
+	films, total, err := h.svc.ListPopularFilms(ctx, req.GetStoreId(), req.GetWindowDays(), req.GetPageSize(), req.GetPage())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return toPopularFilmListResponse(films, total), nil
+}
+
+func (h *FilmHandler) ListNewReleases(ctx context.Context, req *filmv1.ListNewReleasesRequest) (*filmv1.ListFilmsResponse, error) {
+	films, total, err := h.svc.ListNewReleases(ctx, req.GetStoreId(), req.GetPageSize(), req.GetPage())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return toFilmListResponse(films, total), nil
+}
+
 func (h *FilmHandler) CreateFilm(ctx context.Context, req *filmv1.CreateFilmRequest) (*filmv1.Film, error) {
 	film, err := h.svc.CreateFilm(ctx, repository.CreateFilmParams{
 		Title:              req.GetTitle(),
@@ -110,6 +187,14 @@ func (h *FilmHandler) DeleteFilm(ctx context.Context, req *filmv1.DeleteFilmRequ
 	return &emptypb.Empty{}, nil
 }
 
+func (h *FilmHandler) RestoreFilm(ctx context.Context, req *filmv1.RestoreFilmRequest) (*filmv1.Film, error) {
+	film, err := h.svc.RestoreFilm(ctx, req.GetFilmId())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return filmToProto(film), nil
+}
+
 func (h *FilmHandler) AddActorToFilm(ctx context.Context, req *filmv1.AddActorToFilmRequest) (*emptypb.Empty, error) {
 	if err := h.svc.AddActorToFilm(ctx, req.GetActorId(), req.GetFilmId()); err != nil {
 		return nil, toGRPCError(err)
@@ -138,6 +223,46 @@ func (h *FilmHandler) RemoveCategoryFromFilm(ctx context.Context, req *filmv1.Re
 	return &emptypb.Empty{}, nil
 }
 
+func (h *FilmHandler) ImportFilms(stream filmv1.FilmService_ImportFilmsServer) error {
+	first, err := stream.Recv()
+	if errors.Is(err, io.EOF) {
+		return stream.SendAndClose(&filmv1.ImportFilmsResponse{})
+	}
+	if err != nil {
+		return err
+	}
+
+	// The first message has already been read for its dry_run flag.
+	pending := first
+	next := func() (model.FilmRecord, error) {
+		req := pending
+		pending = nil
+		if req == nil {
+			var err error
+			if req, err = stream.Recv(); err != nil {
+				return model.FilmRecord{}, err
+			}
+		}
+		return filmRecordFromProto(req.GetFilm()), nil
+	}
+
+	report, err := h.svc.ImportFilms(stream.Context(), next, first.GetDryRun())
+	if err != nil {
+		return toGRPCError(err)
+	}
+	return stream.SendAndClose(filmImportReportToProto(report))
+}
+
+func (h *FilmHandler) ExportFilms(_ *filmv1.ExportFilmsRequest, stream filmv1.FilmService_ExportFilmsServer) error {
+	err := h.svc.ExportFilms(stream.Context(), func(rec model.FilmRecord) error {
+		return stream.Send(filmRecordToProto(rec))
+	})
+	if err != nil {
+		return toGRPCError(err)
+	}
+	return nil
+}
+
 // --- helpers ---
 
 func toFilmListResponse(films []model.Film, total int64) *filmv1.ListFilmsResponse {
@@ -150,3 +275,17 @@ func toFilmListResponse(films []model.Film, total int64) *filmv1.ListFilmsRespon
 		TotalCount: int32(total),
 	}
 }
+
+func toPopularFilmListResponse(films []model.PopularFilm, total int64) *filmv1.ListPopularFilmsResponse {
+	pbFilms := make([]*filmv1.PopularFilm, len(films))
+	for i, f := range films {
+		pbFilms[i] = &filmv1.PopularFilm{
+			Film:    filmToProto(f.Film),
+			Rentals: f.Rentals,
+		}
+	}
+	return &filmv1.ListPopularFilmsResponse{
+		Films:      pbFilms,
+		TotalCount: int32(total),
+	}
+}
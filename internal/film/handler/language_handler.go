@@ -3,6 +3,8 @@ package handler
 import (
 	"context"
 
+	"google.golang.org/protobuf/types/known/emptypb"
+
 	filmv1 "github.com/enkaigaku/dvd-rental/gen/proto/film/v1"
 	"github.com/enkaigaku/dvd-rental/internal/film/model"
 	"github.com/enkaigaku/dvd-rental/internal/film/service"
@@ -35,6 +37,29 @@ func (h *LanguageHandler) ListLanguages(ctx context.Context, req *filmv1.ListLan
 	return toLanguageListResponse(languages, total), nil
 }
 
+func (h *LanguageHandler) CreateLanguage(ctx context.Context, req *filmv1.CreateLanguageRequest) (*filmv1.Language, error) {
+	language, err := h.svc.CreateLanguage(ctx, req.GetName())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return languageToProto(language), nil
+}
+
+func (h *LanguageHandler) UpdateLanguage(ctx context.Context, req *filmv1.UpdateLanguageRequest) (*filmv1.Language, error) {
+	language, err := h.svc.UpdateLanguage(ctx, req.GetLanguageId(), req.GetName())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return languageToProto(language), nil
+}
+
+func (h *LanguageHandler) DeleteLanguage(ctx context.Context, req *filmv1.DeleteLanguageRequest) (*emptypb.Empty, error) {
+	if err := h.svc.DeleteLanguage(ctx, req.GetLanguageId(), req.GetReassignTo()); err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
 func toLanguageListResponse(languages []model.Language, total int64) *filmv1.ListLanguagesResponse {
 	pbLanguages := make([]*filmv1.Language, len(languages))
 	for i, l := range languages {
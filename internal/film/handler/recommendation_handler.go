@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	filmv1 "github.com/enkaigaku/dvd-rental/gen/proto/film/v1"
+	"github.com/enkaigaku/dvd-rental/internal/film/service"
+)
+
+// RecommendationHandler implements the RecommendationService gRPC interface.
+type RecommendationHandler struct {
+	filmv1.UnimplementedRecommendationServiceServer
+	svc *service.RecommendationService
+}
+
+// NewRecommendationHandler creates a new RecommendationHandler.
+func NewRecommendationHandler(svc *service.RecommendationService) *RecommendationHandler {
+	return &RecommendationHandler{svc: svc}
+}
+
+func (h *RecommendationHandler) ListSimilarFilms(ctx context.Context, req *filmv1.ListSimilarFilmsRequest) (*filmv1.ListRecommendationsResponse, error) {
+	recs, err := h.svc.ListSimilarFilms(ctx, req.GetFilmId(), req.GetLimit())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return recommendationsToProto(recs), nil
+}
+
+func (h *RecommendationHandler) ListRecommendedFilms(ctx context.Context, req *filmv1.ListRecommendedFilmsRequest) (*filmv1.ListRecommendationsResponse, error) {
+	recs, err := h.svc.ListRecommendedFilms(ctx, req.GetCustomerId(), req.GetLimit())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return recommendationsToProto(recs), nil
+}
+
+func (h *RecommendationHandler) RefreshSimilarities(ctx context.Context, _ *emptypb.Empty) (*filmv1.RefreshSimilaritiesResponse, error) {
+	pairs, err := h.svc.RefreshSimilarities(ctx)
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &filmv1.RefreshSimilaritiesResponse{Pairs: pairs}, nil
+}
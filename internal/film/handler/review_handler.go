@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	filmv1 "github.com/enkaigaku/dvd-rental/gen/proto/film/v1"
+	"github.com/enkaigaku/dvd-rental/internal/film/model"
+	"github.com/enkaigaku/dvd-rental/internal/film/service"
+)
+
+// ReviewHandler implements the ReviewService gRPC interface.
+type ReviewHandler struct {
+	filmv1.UnimplementedReviewServiceServer
+	svc *service.ReviewService
+}
+
+// NewReviewHandler creates a new ReviewHandler.
+func NewReviewHandler(svc *service.ReviewService) *ReviewHandler {
+	return &ReviewHandler{svc: svc}
+}
+
+func (h *ReviewHandler) GetReview(ctx context.Context, req *filmv1.GetReviewRequest) (*filmv1.Review, error) {
+	review, err := h.svc.GetReview(ctx, req.GetReviewId())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return reviewToProto(review), nil
+}
+
+func (h *ReviewHandler) ListFilmReviews(ctx context.Context, req *filmv1.ListFilmReviewsRequest) (*filmv1.ListReviewsResponse, error) {
+	reviews, total, err := h.svc.ListFilmReviews(ctx, req.GetFilmId(), req.GetPageSize(), req.GetPage())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return toReviewListResponse(reviews, total), nil
+}
+
+func (h *ReviewHandler) ListReviewsByCustomer(ctx context.Context, req *filmv1.ListReviewsByCustomerRequest) (*filmv1.ListReviewsResponse, error) {
+	reviews, total, err := h.svc.ListReviewsByCustomer(ctx, req.GetCustomerId(), req.GetPageSize(), req.GetPage())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return toReviewListResponse(reviews, total), nil
+}
+
+func (h *ReviewHandler) ListReviewsByStatus(ctx context.Context, req *filmv1.ListReviewsByStatusRequest) (*filmv1.ListReviewsResponse, error) {
+	reviews, total, err := h.svc.ListReviewsByStatus(ctx, req.GetStatus(), req.GetPageSize(), req.GetPage())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return toReviewListResponse(reviews, total), nil
+}
+
+func (h *ReviewHandler) SubmitReview(ctx context.Context, req *filmv1.SubmitReviewRequest) (*filmv1.Review, error) {
+	review, err := h.svc.SubmitReview(ctx, req.GetFilmId(), req.GetCustomerId(), req.GetRating(), req.GetBody())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return reviewToProto(review), nil
+}
+
+func (h *ReviewHandler) ModerateReview(ctx context.Context, req *filmv1.ModerateReviewRequest) (*filmv1.Review, error) {
+	review, err := h.svc.ModerateReview(ctx, req.GetReviewId(), req.GetStatus(), req.GetNote())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return reviewToProto(review), nil
+}
+
+func (h *ReviewHandler) DeleteReview(ctx context.Context, req *filmv1.DeleteReviewRequest) (*emptypb.Empty, error) {
+	if err := h.svc.DeleteReview(ctx, req.GetReviewId()); err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func toReviewListResponse(reviews []model.Review, total int64) *filmv1.ListReviewsResponse {
+	pbReviews := make([]*filmv1.Review, len(reviews))
+	for i, r := range reviews {
+		pbReviews[i] = reviewToProto(r)
+	}
+	return &filmv1.ListReviewsResponse{
+		Reviews:    pbReviews,
+		TotalCount: int32(total),
+	}
+}
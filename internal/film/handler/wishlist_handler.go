@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	filmv1 "github.com/enkaigaku/dvd-rental/gen/proto/film/v1"
+	"github.com/enkaigaku/dvd-rental/internal/film/service"
+)
+
+// WishlistHandler implements the WishlistService gRPC interface.
+type WishlistHandler struct {
+	filmv1.UnimplementedWishlistServiceServer
+	svc *service.WishlistService
+}
+
+// NewWishlistHandler creates a new WishlistHandler.
+func NewWishlistHandler(svc *service.WishlistService) *WishlistHandler {
+	return &WishlistHandler{svc: svc}
+}
+
+func (h *WishlistHandler) ListWishlist(ctx context.Context, req *filmv1.ListWishlistRequest) (*filmv1.ListWishlistResponse, error) {
+	items, total, err := h.svc.ListWishlist(ctx, req.GetCustomerId(), req.GetPageSize(), req.GetPage())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	pbItems := make([]*filmv1.WishlistItem, len(items))
+	for i, item := range items {
+		pbItems[i] = wishlistItemToProto(item)
+	}
+	return &filmv1.ListWishlistResponse{
+		Items:      pbItems,
+		TotalCount: int32(total),
+	}, nil
+}
+
+func (h *WishlistHandler) AddToWishlist(ctx context.Context, req *filmv1.AddToWishlistRequest) (*emptypb.Empty, error) {
+	if err := h.svc.AddToWishlist(ctx, req.GetCustomerId(), req.GetFilmId()); err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (h *WishlistHandler) RemoveFromWishlist(ctx context.Context, req *filmv1.RemoveFromWishlistRequest) (*emptypb.Empty, error) {
+	if err := h.svc.RemoveFromWishlist(ctx, req.GetCustomerId(), req.GetFilmId()); err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,101 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png" // register the PNG decoder
+)
+
+// MaxImagePixels bounds the images accepted for decoding, so that a small
+// file cannot claim dimensions that take gigabytes to decode.
+const MaxImagePixels = 40_000_000
+
+// jpegQuality is the quality renditions are encoded at.
+const jpegQuality = 85
+
+// ErrUnsupportedImage is returned for data that is not a JPEG or PNG image
+// of acceptable dimensions.
+var ErrUnsupportedImage = errors.New("unsupported image")
+
+var contentTypes = map[string]string{
+	"jpeg": "image/jpeg",
+	"png":  "image/png",
+}
+
+// DecodeImage decodes a JPEG or PNG image and returns it with its content
+// type.
+func DecodeImage(data []byte) (image.Image, string, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+	contentType, ok := contentTypes[format]
+	if !ok {
+		return nil, "", fmt.Errorf("%w: %s images are not accepted", ErrUnsupportedImage, format)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > MaxImagePixels {
+		return nil, "", fmt.Errorf("%w: %dx%d pixels exceeds the %d pixel limit", ErrUnsupportedImage, cfg.Width, cfg.Height, MaxImagePixels)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+	return img, contentType, nil
+}
+
+// ResizeJPEG scales img down to width, keeping its aspect ratio, and
+// encodes the result as JPEG. Transparent areas become white. Each pixel
+// of the result is the average of the pixels it covers, which keeps thin
+// lines and text legible at small sizes.
+func ResizeJPEG(img image.Image, width int) ([]byte, error) {
+	src := flatten(img)
+	sw, sh := src.Rect.Dx(), src.Rect.Dy()
+	if width <= 0 || width > sw {
+		width = sw
+	}
+	height := max(1, (sh*width+sw/2)/sw)
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		y0, y1 := y*sh/height, max((y+1)*sh/height, y*sh/height+1)
+		for x := range width {
+			x0, x1 := x*sw/width, max((x+1)*sw/width, x*sw/width+1)
+
+			var r, g, b, n uint32
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+3]
+					r += uint32(p[0])
+					g += uint32(p[1])
+					b += uint32(p[2])
+					n++
+				}
+			}
+
+			d := dst.Pix[y*dst.Stride+x*4:]
+			d[0], d[1], d[2], d[3] = uint8(r/n), uint8(g/n), uint8(b/n), 0xff
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, fmt.Errorf("encode jpeg: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// flatten draws img over a white background into an RGBA image whose
+// bounds start at the origin.
+func flatten(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Rect, image.White, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Rect, img, b.Min, draw.Over)
+	return dst
+}
@@ -17,6 +17,8 @@ type Film struct {
 	Rating             string
 	SpecialFeatures    []string
 	LastUpdate         time.Time
+	ArchivedAt         time.Time  // zero unless the film is archived
+	Poster             *FilmAsset // nil when the film has no poster
 }
 
 // FilmDetail is an enriched film with related entity data, used for single-film views.
@@ -26,6 +28,158 @@ type FilmDetail struct {
 	OriginalLanguageName string
 	Actors               []Actor
 	Categories           []Category
+	AverageRating        string // "" when the film has no approved reviews
+	ReviewCount          int32
+	Stills               []FilmAsset
+}
+
+// FilmSearchHit is a film found by search. Highlights mark the matched
+// words with <b></b>; fuzzy matches carry the plain title and no
+// description highlight.
+type FilmSearchHit struct {
+	Film
+	TitleHighlight       string
+	DescriptionHighlight string
+	Fuzzy                bool // matched by title similarity, not full text
+}
+
+// FilmSuggestion is a title suggested while a search is being typed.
+type FilmSuggestion struct {
+	FilmID    int32
+	Title     string
+	Highlight string
+}
+
+// FilmRecord is a film as bulk imported and exported: the film's fields
+// with its language, actors and categories by name. Actors are named
+// "FIRST LAST".
+type FilmRecord struct {
+	Film
+	Language         string
+	OriginalLanguage string
+	Actors           []string
+	Categories       []string
+}
+
+// FilmImportReport summarizes a bulk film import. In a dry run nothing is
+// written, and Imported and ActorsCreated count what would have been.
+type FilmImportReport struct {
+	DryRun        bool
+	Rows          int32
+	Imported      int32
+	Failed        int32
+	ActorsCreated int32
+	Errors        []FilmImportError
+}
+
+// FilmImportError is why one row of a bulk film import was rejected. Row
+// counts from 1.
+type FilmImportError struct {
+	Row     int32
+	Title   string
+	Message string
+}
+
+// PopularFilm is a film in the popular or trending lists with its number
+// of rentals over the list's window.
+type PopularFilm struct {
+	Film
+	Rentals int64
+}
+
+// Recommendation is a film recommended because customers who rented one
+// film also rented it. Score is the similarity, or for personalized
+// recommendations the summed similarity to the customer's rentals, of
+// which BecauseFilmID contributes most.
+type Recommendation struct {
+	Film
+	Score         float64
+	BecauseFilmID int32
+	BecauseTitle  string
+}
+
+// FilmFacets holds the number of films matching a browse per rating,
+// category and language.
+type FilmFacets struct {
+	Ratings    []FacetCount
+	Categories []FacetCount
+	Languages  []FacetCount
+}
+
+// FacetCount is the number of films with one facet value. ID is the
+// category or language ID, and 0 for ratings.
+type FacetCount struct {
+	ID    int32
+	Value string
+	Count int64
+}
+
+// Film asset kinds.
+const (
+	FilmAssetKindPoster = "poster"
+	FilmAssetKindStill  = "still"
+)
+
+// Film asset sizes. Every asset has its original; the other sizes are
+// JPEG renditions, stored only when narrower than the original.
+const (
+	FilmAssetSizeThumb    = "thumb"
+	FilmAssetSizeMedium   = "medium"
+	FilmAssetSizeLarge    = "large"
+	FilmAssetSizeOriginal = "original"
+)
+
+// FilmAsset is an image of a film: its poster or a still. Width, Height
+// and ContentType describe the original; Sizes lists the stored sizes,
+// smallest first.
+type FilmAsset struct {
+	AssetID     int32
+	FilmID      int32
+	Kind        string
+	ContentType string
+	Width       int32
+	Height      int32
+	Sizes       []string
+	CreatedAt   time.Time
+}
+
+// Review statuses. Reviews with text start pending until moderated.
+const (
+	ReviewStatusPending  = "pending"
+	ReviewStatusApproved = "approved"
+	ReviewStatusRejected = "rejected"
+)
+
+// Review is a customer's star rating of a film, with optional text.
+type Review struct {
+	ReviewID       int32
+	FilmID         int32
+	FilmTitle      string
+	CustomerID     int32
+	CustomerName   string
+	Rating         int16 // 1-5 stars
+	Body           string
+	Status         string
+	ModerationNote string
+	CreatedAt      time.Time
+	LastUpdate     time.Time
+}
+
+// FilmRating is the aggregate of a film's approved reviews.
+type FilmRating struct {
+	ReviewCount   int32
+	AverageRating string
+}
+
+// WishlistItem is a film a customer has saved to rent later, with the
+// number of copies at the customer's home store and how many of them are
+// in stock.
+type WishlistItem struct {
+	Film
+	AddedAt         time.Time
+	StoreID         int32
+	Copies          int32
+	AvailableCopies int32
 }
 
 // Actor represents a film actor.
@@ -34,6 +188,54 @@ type Actor struct {
 	FirstName  string
 	LastName   string
 	LastUpdate time.Time
+	ArchivedAt time.Time // zero unless the actor is archived
+}
+
+// ActorDetail is an actor's profile: their films grouped by category, the
+// actors they appear with most and how often their films were rented.
+type ActorDetail struct {
+	Actor
+	FilmCount   int32
+	RentalCount int64
+	Filmography []ActorCategoryFilms
+	CoStars     []CoStar
+}
+
+// ActorCategoryFilms is an actor's films in one category. A film in
+// several categories is listed under each; films without a category are
+// grouped last, under category 0.
+type ActorCategoryFilms struct {
+	CategoryID   int32
+	CategoryName string
+	Films        []ActorFilm
+}
+
+// ActorFilm is a film in an actor's filmography.
+type ActorFilm struct {
+	FilmID      int32
+	Title       string
+	ReleaseYear int32
+	Rating      string
+}
+
+// ActorFilmographyEntry is one film of an actor's filmography in one of
+// its categories.
+type ActorFilmographyEntry struct {
+	CategoryID   int32
+	CategoryName string
+	ActorFilm
+}
+
+// CoStar is an actor who appears in films with another, and in how many.
+type CoStar struct {
+	Actor
+	FilmsTogether int32
+}
+
+// ActorStats counts an actor's films and the rentals of them.
+type ActorStats struct {
+	FilmCount   int32
+	RentalCount int64
 }
 
 // Category represents a film category.
@@ -0,0 +1,35 @@
+// Package rentals asks the rental service about customers' rental history,
+// which decides who may review a film.
+package rentals
+
+import (
+	"context"
+	"fmt"
+
+	rentalv1 "github.com/enkaigaku/dvd-rental/gen/proto/rental/v1"
+)
+
+// Checker reports whether a customer has rented a film.
+type Checker interface {
+	HasRented(ctx context.Context, customerID, filmID int32) (bool, error)
+}
+
+type grpcChecker struct {
+	client rentalv1.RentalServiceClient
+}
+
+// NewChecker creates a Checker backed by the rental service.
+func NewChecker(client rentalv1.RentalServiceClient) Checker {
+	return &grpcChecker{client: client}
+}
+
+func (c *grpcChecker) HasRented(ctx context.Context, customerID, filmID int32) (bool, error) {
+	resp, err := c.client.HasCustomerRentedFilm(ctx, &rentalv1.HasCustomerRentedFilmRequest{
+		CustomerId: customerID,
+		FilmId:     filmID,
+	})
+	if err != nil {
+		return false, fmt.Errorf("check rental history: %w", err)
+	}
+	return resp.GetRented(), nil
+}
@@ -15,12 +15,18 @@ import (
 // ActorRepository defines the data access interface for actors.
 type ActorRepository interface {
 	GetActor(ctx context.Context, actorID int32) (model.Actor, error)
-	ListActors(ctx context.Context, limit, offset int32) ([]model.Actor, error)
-	CountActors(ctx context.Context) (int64, error)
+	ListActors(ctx context.Context, includeArchived bool, limit, offset int32) ([]model.Actor, error)
+	CountActors(ctx context.Context, includeArchived bool) (int64, error)
+	SearchActors(ctx context.Context, prefixPattern, query string, includeArchived bool, limit, offset int32) ([]model.Actor, error)
+	CountSearchActors(ctx context.Context, prefixPattern, query string, includeArchived bool) (int64, error)
 	ListActorsByFilm(ctx context.Context, filmID int32) ([]model.Actor, error)
 	CreateActor(ctx context.Context, firstName, lastName string) (model.Actor, error)
 	UpdateActor(ctx context.Context, actorID int32, firstName, lastName string) (model.Actor, error)
-	DeleteActor(ctx context.Context, actorID int32) error
+	ArchiveActor(ctx context.Context, actorID int32) error
+	RestoreActor(ctx context.Context, actorID int32) error
+	ListActorFilmography(ctx context.Context, actorID int32) ([]model.ActorFilmographyEntry, error)
+	ListActorCoStars(ctx context.Context, actorID, limit int32) ([]model.CoStar, error)
+	GetActorStats(ctx context.Context, actorID int32) (model.ActorStats, error)
 }
 
 type actorRepository struct {
@@ -43,22 +49,52 @@ func (r *actorRepository) GetActor(ctx context.Context, actorID int32) (model.Ac
 	return toActorModel(row), nil
 }
 
-func (r *actorRepository) ListActors(ctx context.Context, limit, offset int32) ([]model.Actor, error) {
-	rows, err := r.q.ListActors(ctx, filmsqlc.ListActorsParams{Limit: limit, Offset: offset})
+func (r *actorRepository) ListActors(ctx context.Context, includeArchived bool, limit, offset int32) ([]model.Actor, error) {
+	rows, err := r.q.ListActors(ctx, filmsqlc.ListActorsParams{
+		IncludeArchived: includeArchived,
+		PageLimit:       limit,
+		PageOffset:      offset,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("list actors: %w", err)
 	}
 	return toActorModels(rows), nil
 }
 
-func (r *actorRepository) CountActors(ctx context.Context) (int64, error) {
-	count, err := r.q.CountActors(ctx)
+func (r *actorRepository) CountActors(ctx context.Context, includeArchived bool) (int64, error) {
+	count, err := r.q.CountActors(ctx, includeArchived)
 	if err != nil {
 		return 0, fmt.Errorf("count actors: %w", err)
 	}
 	return count, nil
 }
 
+func (r *actorRepository) SearchActors(ctx context.Context, prefixPattern, query string, includeArchived bool, limit, offset int32) ([]model.Actor, error) {
+	rows, err := r.q.SearchActors(ctx, filmsqlc.SearchActorsParams{
+		PrefixPattern:   prefixPattern,
+		Query:           query,
+		IncludeArchived: includeArchived,
+		PageOffset:      offset,
+		PageLimit:       limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("search actors: %w", err)
+	}
+	return toActorModels(rows), nil
+}
+
+func (r *actorRepository) CountSearchActors(ctx context.Context, prefixPattern, query string, includeArchived bool) (int64, error) {
+	count, err := r.q.CountSearchActors(ctx, filmsqlc.CountSearchActorsParams{
+		PrefixPattern:   prefixPattern,
+		Query:           query,
+		IncludeArchived: includeArchived,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("count search actors: %w", err)
+	}
+	return count, nil
+}
+
 func (r *actorRepository) ListActorsByFilm(ctx context.Context, filmID int32) ([]model.Actor, error) {
 	rows, err := r.q.ListActorsByFilm(ctx, filmID)
 	if err != nil {
@@ -93,21 +129,82 @@ func (r *actorRepository) UpdateActor(ctx context.Context, actorID int32, firstN
 	return toActorModel(row), nil
 }
 
-func (r *actorRepository) DeleteActor(ctx context.Context, actorID int32) error {
-	if err := r.q.DeleteActor(ctx, actorID); err != nil {
-		return fmt.Errorf("delete actor: %w", err)
+func (r *actorRepository) ArchiveActor(ctx context.Context, actorID int32) error {
+	if err := r.q.ArchiveActor(ctx, actorID); err != nil {
+		return fmt.Errorf("archive actor: %w", err)
+	}
+	return nil
+}
+
+func (r *actorRepository) RestoreActor(ctx context.Context, actorID int32) error {
+	if err := r.q.RestoreActor(ctx, actorID); err != nil {
+		return fmt.Errorf("restore actor: %w", err)
 	}
 	return nil
 }
 
 // --- row to model conversions ---
 
+func (r *actorRepository) ListActorFilmography(ctx context.Context, actorID int32) ([]model.ActorFilmographyEntry, error) {
+	rows, err := r.q.ListActorFilmography(ctx, actorID)
+	if err != nil {
+		return nil, fmt.Errorf("list actor filmography: %w", err)
+	}
+	entries := make([]model.ActorFilmographyEntry, len(rows))
+	for i, row := range rows {
+		entries[i] = model.ActorFilmographyEntry{
+			CategoryID:   row.CategoryID,
+			CategoryName: row.CategoryName,
+			ActorFilm: model.ActorFilm{
+				FilmID:      row.FilmID,
+				Title:       row.Title,
+				ReleaseYear: yearToInt32(row.ReleaseYear),
+				Rating:      ratingToString(row.Rating),
+			},
+		}
+	}
+	return entries, nil
+}
+
+func (r *actorRepository) ListActorCoStars(ctx context.Context, actorID, limit int32) ([]model.CoStar, error) {
+	rows, err := r.q.ListActorCoStars(ctx, filmsqlc.ListActorCoStarsParams{
+		ActorID:    actorID,
+		MaxResults: limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list actor co-stars: %w", err)
+	}
+	coStars := make([]model.CoStar, len(rows))
+	for i, row := range rows {
+		coStars[i] = model.CoStar{
+			Actor: model.Actor{
+				ActorID:    row.ActorID,
+				FirstName:  row.FirstName,
+				LastName:   row.LastName,
+				LastUpdate: row.LastUpdate.Time,
+				ArchivedAt: row.ArchivedAt.Time,
+			},
+			FilmsTogether: row.FilmsTogether,
+		}
+	}
+	return coStars, nil
+}
+
+func (r *actorRepository) GetActorStats(ctx context.Context, actorID int32) (model.ActorStats, error) {
+	row, err := r.q.GetActorStats(ctx, actorID)
+	if err != nil {
+		return model.ActorStats{}, fmt.Errorf("get actor stats: %w", err)
+	}
+	return model.ActorStats{FilmCount: row.FilmCount, RentalCount: row.RentalCount}, nil
+}
+
 func toActorModel(r filmsqlc.Actor) model.Actor {
 	return model.Actor{
 		ActorID:    r.ActorID,
 		FirstName:  r.FirstName,
 		LastName:   r.LastName,
 		LastUpdate: r.LastUpdate.Time,
+		ArchivedAt: r.ArchivedAt.Time,
 	}
 }
 
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/enkaigaku/dvd-rental/internal/film/model"
+	"github.com/enkaigaku/dvd-rental/gen/sqlc/film"
+)
+
+// CatalogRepository defines the data access interface for bulk catalog
+// import and export.
+type CatalogRepository interface {
+	ImportFilm(ctx context.Context, params ImportFilmParams, dryRun bool) (ImportFilmResult, error)
+	ExportFilms(ctx context.Context, afterFilmID, limit int32) ([]model.FilmRecord, error)
+}
+
+// ActorName is an actor's name as given in a bulk import.
+type ActorName struct {
+	FirstName string
+	LastName  string
+}
+
+// ImportFilmParams holds one film of a bulk import with its language and
+// categories resolved. Actors are resolved by name, or created.
+type ImportFilmParams struct {
+	CreateFilmParams
+	Actors      []ActorName
+	CategoryIDs []int32
+}
+
+// ImportFilmResult is the outcome of importing one film.
+type ImportFilmResult struct {
+	Film          model.Film
+	ActorsCreated []ActorName // actors that were not yet in the catalog
+}
+
+type catalogRepository struct {
+	pool *pgxpool.Pool
+	q    *filmsqlc.Queries
+}
+
+// NewCatalogRepository creates a new CatalogRepository backed by PostgreSQL.
+func NewCatalogRepository(pool *pgxpool.Pool) CatalogRepository {
+	return &catalogRepository{pool: pool, q: filmsqlc.New(pool)}
+}
+
+// ImportFilm creates a film with its actor and category links in one
+// transaction, creating actors not yet in the catalog. With dryRun the
+// transaction is rolled back, so the film is checked against the database
+// without being written.
+func (r *catalogRepository) ImportFilm(ctx context.Context, params ImportFilmParams, dryRun bool) (ImportFilmResult, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return ImportFilmResult{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.q.WithTx(tx)
+	row, err := q.CreateFilm(ctx, filmsqlc.CreateFilmParams{
+		Title:              params.Title,
+		Description:        stringToText(params.Description),
+		ReleaseYear:        yearFromInt32(params.ReleaseYear),
+		LanguageID:         params.LanguageID,
+		OriginalLanguageID: int32ToInt4(params.OriginalLanguageID),
+		RentalDuration:     params.RentalDuration,
+		RentalRate:         stringToNumeric(params.RentalRate),
+		Length:             int16ToInt2(params.Length),
+		ReplacementCost:    stringToNumeric(params.ReplacementCost),
+		Rating:             stringToRating(params.Rating),
+		SpecialFeatures:    params.SpecialFeatures,
+	})
+	if err != nil {
+		return ImportFilmResult{}, fmt.Errorf("create film: %w", err)
+	}
+	result := ImportFilmResult{Film: filmFromCreateRow(row)}
+
+	for _, name := range params.Actors {
+		actorID, err := q.FindActorByName(ctx, filmsqlc.FindActorByNameParams{
+			FirstName: name.FirstName,
+			LastName:  name.LastName,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			actor, err := q.CreateActor(ctx, filmsqlc.CreateActorParams{
+				FirstName: name.FirstName,
+				LastName:  name.LastName,
+			})
+			if err != nil {
+				return ImportFilmResult{}, fmt.Errorf("create actor: %w", err)
+			}
+			actorID = actor.ActorID
+			result.ActorsCreated = append(result.ActorsCreated, name)
+		} else if err != nil {
+			return ImportFilmResult{}, fmt.Errorf("find actor: %w", err)
+		}
+
+		if err := q.AddActorToFilm(ctx, filmsqlc.AddActorToFilmParams{
+			ActorID: actorID,
+			FilmID:  row.FilmID,
+		}); err != nil {
+			return ImportFilmResult{}, fmt.Errorf("add actor to film: %w", err)
+		}
+	}
+
+	for _, categoryID := range params.CategoryIDs {
+		if err := q.AddCategoryToFilm(ctx, filmsqlc.AddCategoryToFilmParams{
+			FilmID:     row.FilmID,
+			CategoryID: categoryID,
+		}); err != nil {
+			return ImportFilmResult{}, fmt.Errorf("add category to film: %w", err)
+		}
+	}
+
+	if dryRun {
+		return result, nil
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return ImportFilmResult{}, fmt.Errorf("commit tx: %w", err)
+	}
+	return result, nil
+}
+
+// ExportFilms returns up to limit films in film_id order, starting after
+// afterFilmID.
+func (r *catalogRepository) ExportFilms(ctx context.Context, afterFilmID, limit int32) ([]model.FilmRecord, error) {
+	rows, err := r.q.ExportFilms(ctx, filmsqlc.ExportFilmsParams{
+		AfterFilmID: afterFilmID,
+		PageLimit:   limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("export films: %w", err)
+	}
+	records := make([]model.FilmRecord, len(rows))
+	for i, r := range rows {
+		f := convertFilmFields(filmFields{
+			FilmID: r.FilmID, Title: r.Title, Description: r.Description,
+			ReleaseYear: r.ReleaseYear, LanguageID: r.LanguageID,
+			OriginalLanguageID: r.OriginalLanguageID, RentalDuration: r.RentalDuration,
+			RentalRate: r.RentalRate, Length: r.Length, ReplacementCost: r.ReplacementCost,
+			Rating: r.Rating, SpecialFeatures: r.SpecialFeatures, LastUpdate: r.LastUpdate,
+		})
+		records[i] = model.FilmRecord{
+			Film:             filmFromConverted(f),
+			Language:         r.LanguageName,
+			OriginalLanguage: r.OriginalLanguageName,
+			Actors:           r.ActorNames,
+			Categories:       r.CategoryNames,
+		}
+	}
+	return records, nil
+}
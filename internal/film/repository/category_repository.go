@@ -12,21 +12,25 @@ import (
 	"github.com/enkaigaku/dvd-rental/gen/sqlc/film"
 )
 
-// CategoryRepository defines the read-only data access interface for categories.
+// CategoryRepository defines the data access interface for categories.
 type CategoryRepository interface {
 	GetCategory(ctx context.Context, categoryID int32) (model.Category, error)
 	ListCategories(ctx context.Context) ([]model.Category, error)
 	CountCategories(ctx context.Context) (int64, error)
 	ListCategoriesByFilm(ctx context.Context, filmID int32) ([]model.Category, error)
+	CreateCategory(ctx context.Context, name string) (model.Category, error)
+	UpdateCategory(ctx context.Context, categoryID int32, name string) (model.Category, error)
+	DeleteCategory(ctx context.Context, categoryID, reassignTo int32) error
 }
 
 type categoryRepository struct {
-	q *filmsqlc.Queries
+	pool *pgxpool.Pool
+	q    *filmsqlc.Queries
 }
 
 // NewCategoryRepository creates a new CategoryRepository backed by PostgreSQL.
 func NewCategoryRepository(pool *pgxpool.Pool) CategoryRepository {
-	return &categoryRepository{q: filmsqlc.New(pool)}
+	return &categoryRepository{pool: pool, q: filmsqlc.New(pool)}
 }
 
 func (r *categoryRepository) GetCategory(ctx context.Context, categoryID int32) (model.Category, error) {
@@ -64,6 +68,60 @@ func (r *categoryRepository) ListCategoriesByFilm(ctx context.Context, filmID in
 	return toCategoryModels(rows), nil
 }
 
+func (r *categoryRepository) CreateCategory(ctx context.Context, name string) (model.Category, error) {
+	row, err := r.q.CreateCategory(ctx, name)
+	if err != nil {
+		return model.Category{}, fmt.Errorf("create category: %w", err)
+	}
+	return toCategoryModel(row), nil
+}
+
+func (r *categoryRepository) UpdateCategory(ctx context.Context, categoryID int32, name string) (model.Category, error) {
+	row, err := r.q.UpdateCategory(ctx, filmsqlc.UpdateCategoryParams{
+		CategoryID: categoryID,
+		Name:       name,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Category{}, ErrNotFound
+		}
+		return model.Category{}, fmt.Errorf("update category: %w", err)
+	}
+	return toCategoryModel(row), nil
+}
+
+// DeleteCategory deletes a category. When reassignTo is not 0 its films
+// are first moved to that category, in the same transaction; otherwise the
+// delete fails while films are in the category.
+func (r *categoryRepository) DeleteCategory(ctx context.Context, categoryID, reassignTo int32) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.q.WithTx(tx)
+	if reassignTo != 0 {
+		if err := q.ReassignFilmCategories(ctx, filmsqlc.ReassignFilmCategoriesParams{
+			FromCategoryID: categoryID,
+			ToCategoryID:   reassignTo,
+		}); err != nil {
+			return fmt.Errorf("reassign film categories: %w", err)
+		}
+		if err := q.RemoveCategoryFromAllFilms(ctx, categoryID); err != nil {
+			return fmt.Errorf("remove category from films: %w", err)
+		}
+	}
+	if err := q.DeleteCategory(ctx, categoryID); err != nil {
+		return fmt.Errorf("delete category: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
 // --- row to model conversions ---
 
 func toCategoryModel(r filmsqlc.Category) model.Category {
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/enkaigaku/dvd-rental/internal/film/model"
+	"github.com/enkaigaku/dvd-rental/gen/sqlc/film"
+)
+
+// CreateFilmAssetParams holds the fields for recording a new film asset.
+type CreateFilmAssetParams struct {
+	FilmID      int32
+	Kind        string
+	ContentType string
+	Width       int32
+	Height      int32
+	Sizes       []string
+}
+
+// FilmAssetRepository defines the data access interface for the records of
+// film posters and stills. The images themselves are kept in a blob store.
+type FilmAssetRepository interface {
+	CreateFilmAsset(ctx context.Context, params CreateFilmAssetParams) (model.FilmAsset, error)
+	GetFilmAsset(ctx context.Context, assetID int32) (model.FilmAsset, error)
+	ListFilmAssets(ctx context.Context, filmID int32) ([]model.FilmAsset, error)
+	ListFilmPosters(ctx context.Context, filmIDs []int32) ([]model.FilmAsset, error)
+	DeleteFilmAsset(ctx context.Context, filmID, assetID int32) (model.FilmAsset, error)
+	DeleteOtherFilmPosters(ctx context.Context, filmID, keepAssetID int32) ([]model.FilmAsset, error)
+}
+
+type filmAssetRepository struct {
+	q *filmsqlc.Queries
+}
+
+// NewFilmAssetRepository creates a new FilmAssetRepository backed by PostgreSQL.
+func NewFilmAssetRepository(pool *pgxpool.Pool) FilmAssetRepository {
+	return &filmAssetRepository{q: filmsqlc.New(pool)}
+}
+
+func (r *filmAssetRepository) CreateFilmAsset(ctx context.Context, params CreateFilmAssetParams) (model.FilmAsset, error) {
+	row, err := r.q.CreateFilmAsset(ctx, filmsqlc.CreateFilmAssetParams{
+		FilmID:      params.FilmID,
+		Kind:        params.Kind,
+		ContentType: params.ContentType,
+		Width:       params.Width,
+		Height:      params.Height,
+		Sizes:       params.Sizes,
+	})
+	if err != nil {
+		return model.FilmAsset{}, fmt.Errorf("create film asset: %w", err)
+	}
+	return toFilmAssetModel(row), nil
+}
+
+func (r *filmAssetRepository) GetFilmAsset(ctx context.Context, assetID int32) (model.FilmAsset, error) {
+	row, err := r.q.GetFilmAsset(ctx, assetID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.FilmAsset{}, ErrNotFound
+		}
+		return model.FilmAsset{}, fmt.Errorf("get film asset: %w", err)
+	}
+	return toFilmAssetModel(row), nil
+}
+
+func (r *filmAssetRepository) ListFilmAssets(ctx context.Context, filmID int32) ([]model.FilmAsset, error) {
+	rows, err := r.q.ListFilmAssets(ctx, filmID)
+	if err != nil {
+		return nil, fmt.Errorf("list film assets: %w", err)
+	}
+	return toFilmAssetModels(rows), nil
+}
+
+func (r *filmAssetRepository) ListFilmPosters(ctx context.Context, filmIDs []int32) ([]model.FilmAsset, error) {
+	rows, err := r.q.ListFilmPosters(ctx, filmIDs)
+	if err != nil {
+		return nil, fmt.Errorf("list film posters: %w", err)
+	}
+	return toFilmAssetModels(rows), nil
+}
+
+func (r *filmAssetRepository) DeleteFilmAsset(ctx context.Context, filmID, assetID int32) (model.FilmAsset, error) {
+	row, err := r.q.DeleteFilmAsset(ctx, filmsqlc.DeleteFilmAssetParams{
+		AssetID: assetID,
+		FilmID:  filmID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.FilmAsset{}, ErrNotFound
+		}
+		return model.FilmAsset{}, fmt.Errorf("delete film asset: %w", err)
+	}
+	return toFilmAssetModel(row), nil
+}
+
+func (r *filmAssetRepository) DeleteOtherFilmPosters(ctx context.Context, filmID, keepAssetID int32) ([]model.FilmAsset, error) {
+	rows, err := r.q.DeleteOtherFilmPosters(ctx, filmsqlc.DeleteOtherFilmPostersParams{
+		FilmID:      filmID,
+		KeepAssetID: keepAssetID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("delete other film posters: %w", err)
+	}
+	return toFilmAssetModels(rows), nil
+}
+
+func toFilmAssetModel(r filmsqlc.FilmAsset) model.FilmAsset {
+	return model.FilmAsset{
+		AssetID:     r.AssetID,
+		FilmID:      r.FilmID,
+		Kind:        r.Kind,
+		ContentType: r.ContentType,
+		Width:       r.Width,
+		Height:      r.Height,
+		Sizes:       r.Sizes,
+		CreatedAt:   r.CreatedAt.Time,
+	}
+}
+
+func toFilmAssetModels(rows []filmsqlc.FilmAsset) []model.FilmAsset {
+	assets := make([]model.FilmAsset, len(rows))
+	for i, row := range rows {
+		assets[i] = toFilmAssetModel(row)
+	}
+	return assets
+}
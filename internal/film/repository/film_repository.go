@@ -15,23 +15,61 @@ import (
 // FilmRepository defines the data access interface for films.
 type FilmRepository interface {
 	GetFilm(ctx context.Context, filmID int32) (model.Film, error)
-	ListFilms(ctx context.Context, limit, offset int32) ([]model.Film, error)
-	CountFilms(ctx context.Context) (int64, error)
-	SearchFilms(ctx context.Context, query string, limit, offset int32) ([]model.Film, error)
-	CountSearchFilms(ctx context.Context, query string) (int64, error)
+	ListFilms(ctx context.Context, filter FilmFilter, sort FilmSort, limit, offset int32) ([]model.Film, error)
+	CountFilms(ctx context.Context, filter FilmFilter) (int64, error)
+	ListFilmFacets(ctx context.Context, filter FilmFilter) (model.FilmFacets, error)
+	SearchFilms(ctx context.Context, tsQuery string, limit, offset int32) ([]model.FilmSearchHit, error)
+	CountSearchFilms(ctx context.Context, tsQuery string) (int64, error)
+	FuzzySearchFilms(ctx context.Context, query string, limit, offset int32) ([]model.Film, error)
+	CountFuzzySearchFilms(ctx context.Context, query string) (int64, error)
+	SuggestFilms(ctx context.Context, tsQuery, query string, limit int32) ([]model.FilmSuggestion, error)
 	ListFilmsByCategory(ctx context.Context, categoryID, limit, offset int32) ([]model.Film, error)
 	CountFilmsByCategory(ctx context.Context, categoryID int32) (int64, error)
 	ListFilmsByActor(ctx context.Context, actorID, limit, offset int32) ([]model.Film, error)
 	CountFilmsByActor(ctx context.Context, actorID int32) (int64, error)
 	CreateFilm(ctx context.Context, params CreateFilmParams) (model.Film, error)
 	UpdateFilm(ctx context.Context, params UpdateFilmParams) (model.Film, error)
-	DeleteFilm(ctx context.Context, filmID int32) error
+	ArchiveFilm(ctx context.Context, filmID int32) (int64, error)
+	RestoreFilm(ctx context.Context, filmID int32) error
 	AddActorToFilm(ctx context.Context, actorID, filmID int32) error
 	RemoveActorFromFilm(ctx context.Context, actorID, filmID int32) error
 	AddCategoryToFilm(ctx context.Context, filmID, categoryID int32) error
 	RemoveCategoryFromFilm(ctx context.Context, filmID, categoryID int32) error
 }
 
+// FilmFilter holds the filters for browsing films. Zero values leave a
+// filter unset; filters that are set must all match.
+type FilmFilter struct {
+	Ratings         []string // any of
+	CategoryID      int32
+	ActorID         int32
+	LanguageID      int32
+	MinReleaseYear  int32
+	MaxReleaseYear  int32
+	MinLength       int32
+	MaxLength       int32
+	MinRentalRate   string
+	MaxRentalRate   string
+	SpecialFeatures []string // all of
+	IncludeArchived bool
+}
+
+// FilmSort is the order films are browsed in. By is one of the FilmSortBy
+// constants; ties are broken by title.
+type FilmSort struct {
+	By         string
+	Descending bool
+}
+
+// Film sort orders.
+const (
+	FilmSortByTitle       = "title"
+	FilmSortByReleaseYear = "release_year"
+	FilmSortByRentalRate  = "rental_rate"
+	FilmSortByPopularity  = "popularity" // number of rentals
+	FilmSortByRating      = "rating"     // average review rating, unrated films last
+)
+
 // CreateFilmParams holds parameters for creating a new film.
 type CreateFilmParams struct {
 	Title              string
@@ -83,27 +121,111 @@ func (r *filmRepository) GetFilm(ctx context.Context, filmID int32) (model.Film,
 	return filmFromGetRow(row), nil
 }
 
-func (r *filmRepository) ListFilms(ctx context.Context, limit, offset int32) ([]model.Film, error) {
-	rows, err := r.q.ListFilms(ctx, filmsqlc.ListFilmsParams{Limit: limit, Offset: offset})
+func (r *filmRepository) ListFilms(ctx context.Context, filter FilmFilter, sort FilmSort, limit, offset int32) ([]model.Film, error) {
+	args := filterArgs(filter)
+	rows, err := r.q.ListFilms(ctx, filmsqlc.ListFilmsParams{
+		Ratings:         args.Ratings,
+		CategoryID:      args.CategoryID,
+		ActorID:         args.ActorID,
+		LanguageID:      args.LanguageID,
+		MinReleaseYear:  args.MinReleaseYear,
+		MaxReleaseYear:  args.MaxReleaseYear,
+		MinLength:       args.MinLength,
+		MaxLength:       args.MaxLength,
+		MinRentalRate:   args.MinRentalRate,
+		MaxRentalRate:   args.MaxRentalRate,
+		SpecialFeatures: args.SpecialFeatures,
+		IncludeArchived: args.IncludeArchived,
+		SortBy:          sort.By,
+		Descending:      sort.Descending,
+		PageLimit:       limit,
+		PageOffset:      offset,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("list films: %w", err)
 	}
 	return filmsFromListRows(rows), nil
 }
 
-func (r *filmRepository) CountFilms(ctx context.Context) (int64, error) {
-	count, err := r.q.CountFilms(ctx)
+func (r *filmRepository) CountFilms(ctx context.Context, filter FilmFilter) (int64, error) {
+	count, err := r.q.CountFilms(ctx, filterArgs(filter))
 	if err != nil {
 		return 0, fmt.Errorf("count films: %w", err)
 	}
 	return count, nil
 }
 
-func (r *filmRepository) SearchFilms(ctx context.Context, query string, limit, offset int32) ([]model.Film, error) {
+func (r *filmRepository) ListFilmFacets(ctx context.Context, filter FilmFilter) (model.FilmFacets, error) {
+	args := filterArgs(filter)
+	rows, err := r.q.ListFilmFacets(ctx, filmsqlc.ListFilmFacetsParams{
+		Ratings:         args.Ratings,
+		CategoryID:      args.CategoryID,
+		ActorID:         args.ActorID,
+		LanguageID:      args.LanguageID,
+		MinReleaseYear:  args.MinReleaseYear,
+		MaxReleaseYear:  args.MaxReleaseYear,
+		MinLength:       args.MinLength,
+		MaxLength:       args.MaxLength,
+		MinRentalRate:   args.MinRentalRate,
+		MaxRentalRate:   args.MaxRentalRate,
+		SpecialFeatures: args.SpecialFeatures,
+		IncludeArchived: args.IncludeArchived,
+	})
+	if err != nil {
+		return model.FilmFacets{}, fmt.Errorf("list film facets: %w", err)
+	}
+
+	facets := model.FilmFacets{
+		Ratings:    []model.FacetCount{},
+		Categories: []model.FacetCount{},
+		Languages:  []model.FacetCount{},
+	}
+	for _, row := range rows {
+		count := model.FacetCount{ID: row.ValueID, Value: row.Value, Count: row.FilmCount}
+		switch row.Facet {
+		case "rating":
+			facets.Ratings = append(facets.Ratings, count)
+		case "category":
+			facets.Categories = append(facets.Categories, count)
+		case "language":
+			facets.Languages = append(facets.Languages, count)
+		}
+	}
+	return facets, nil
+}
+
+// filterArgs converts a FilmFilter to query arguments. Unset lists are sent
+// as empty arrays rather than NULL so the filters treat them as unset.
+func filterArgs(f FilmFilter) filmsqlc.CountFilmsParams {
+	ratings := f.Ratings
+	if ratings == nil {
+		ratings = []string{}
+	}
+	features := f.SpecialFeatures
+	if features == nil {
+		features = []string{}
+	}
+	return filmsqlc.CountFilmsParams{
+		Ratings:         ratings,
+		CategoryID:      f.CategoryID,
+		ActorID:         f.ActorID,
+		LanguageID:      f.LanguageID,
+		MinReleaseYear:  f.MinReleaseYear,
+		MaxReleaseYear:  f.MaxReleaseYear,
+		MinLength:       f.MinLength,
+		MaxLength:       f.MaxLength,
+		MinRentalRate:   stringToNumeric(f.MinRentalRate),
+		MaxRentalRate:   stringToNumeric(f.MaxRentalRate),
+		SpecialFeatures: features,
+		IncludeArchived: f.IncludeArchived,
+	}
+}
+
+func (r *filmRepository) SearchFilms(ctx context.Context, tsQuery string, limit, offset int32) ([]model.FilmSearchHit, error) {
 	rows, err := r.q.SearchFilms(ctx, filmsqlc.SearchFilmsParams{
-		PlaintoTsquery: query,
-		Limit:          limit,
-		Offset:         offset,
+		Query:      tsQuery,
+		PageLimit:  limit,
+		PageOffset: offset,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("search films: %w", err)
@@ -111,14 +233,54 @@ func (r *filmRepository) SearchFilms(ctx context.Context, query string, limit, o
 	return filmsFromSearchRows(rows), nil
 }
 
-func (r *filmRepository) CountSearchFilms(ctx context.Context, query string) (int64, error) {
-	count, err := r.q.CountSearchFilms(ctx, query)
+func (r *filmRepository) CountSearchFilms(ctx context.Context, tsQuery string) (int64, error) {
+	count, err := r.q.CountSearchFilms(ctx, tsQuery)
 	if err != nil {
 		return 0, fmt.Errorf("count search films: %w", err)
 	}
 	return count, nil
 }
 
+func (r *filmRepository) FuzzySearchFilms(ctx context.Context, query string, limit, offset int32) ([]model.Film, error) {
+	rows, err := r.q.FuzzySearchFilms(ctx, filmsqlc.FuzzySearchFilmsParams{
+		Query:      query,
+		PageLimit:  limit,
+		PageOffset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fuzzy search films: %w", err)
+	}
+	return filmsFromFuzzySearchRows(rows), nil
+}
+
+func (r *filmRepository) CountFuzzySearchFilms(ctx context.Context, query string) (int64, error) {
+	count, err := r.q.CountFuzzySearchFilms(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("count fuzzy search films: %w", err)
+	}
+	return count, nil
+}
+
+func (r *filmRepository) SuggestFilms(ctx context.Context, tsQuery, query string, limit int32) ([]model.FilmSuggestion, error) {
+	rows, err := r.q.SuggestFilms(ctx, filmsqlc.SuggestFilmsParams{
+		PrefixQuery: tsQuery,
+		Query:       query,
+		MaxResults:  limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("suggest films: %w", err)
+	}
+	suggestions := make([]model.FilmSuggestion, len(rows))
+	for i, row := range rows {
+		suggestions[i] = model.FilmSuggestion{
+			FilmID:    row.FilmID,
+			Title:     row.Title,
+			Highlight: row.Highlight,
+		}
+	}
+	return suggestions, nil
+}
+
 func (r *filmRepository) ListFilmsByCategory(ctx context.Context, categoryID, limit, offset int32) ([]model.Film, error) {
 	rows, err := r.q.ListFilmsByCategory(ctx, filmsqlc.ListFilmsByCategoryParams{
 		CategoryID: categoryID,
@@ -203,9 +365,19 @@ func (r *filmRepository) UpdateFilm(ctx context.Context, params UpdateFilmParams
 	return filmFromUpdateRow(row), nil
 }
 
-func (r *filmRepository) DeleteFilm(ctx context.Context, filmID int32) error {
-	if err := r.q.DeleteFilm(ctx, filmID); err != nil {
-		return fmt.Errorf("delete film: %w", err)
+// ArchiveFilm archives a film unless copies of it are out on rental, and
+// returns the number of copies out. A film with copies out is left as it is.
+func (r *filmRepository) ArchiveFilm(ctx context.Context, filmID int32) (int64, error) {
+	out, err := r.q.ArchiveFilm(ctx, filmID)
+	if err != nil {
+		return 0, fmt.Errorf("archive film: %w", err)
+	}
+	return out, nil
+}
+
+func (r *filmRepository) RestoreFilm(ctx context.Context, filmID int32) error {
+	if err := r.q.RestoreFilm(ctx, filmID); err != nil {
+		return fmt.Errorf("restore film: %w", err)
 	}
 	return nil
 }
@@ -268,6 +440,7 @@ func filmFromGetRow(r filmsqlc.GetFilmRow) model.Film {
 		OriginalLanguageID: r.OriginalLanguageID, RentalDuration: r.RentalDuration,
 		RentalRate: r.RentalRate, Length: r.Length, ReplacementCost: r.ReplacementCost,
 		Rating: r.Rating, SpecialFeatures: r.SpecialFeatures, LastUpdate: r.LastUpdate,
+		ArchivedAt: r.ArchivedAt,
 	})
 	return filmFromConverted(f)
 }
@@ -290,6 +463,7 @@ func filmFromUpdateRow(r filmsqlc.UpdateFilmRow) model.Film {
 		OriginalLanguageID: r.OriginalLanguageID, RentalDuration: r.RentalDuration,
 		RentalRate: r.RentalRate, Length: r.Length, ReplacementCost: r.ReplacementCost,
 		Rating: r.Rating, SpecialFeatures: r.SpecialFeatures, LastUpdate: r.LastUpdate,
+		ArchivedAt: r.ArchivedAt,
 	})
 	return filmFromConverted(f)
 }
@@ -303,13 +477,33 @@ func filmsFromListRows(rows []filmsqlc.ListFilmsRow) []model.Film {
 			OriginalLanguageID: r.OriginalLanguageID, RentalDuration: r.RentalDuration,
 			RentalRate: r.RentalRate, Length: r.Length, ReplacementCost: r.ReplacementCost,
 			Rating: r.Rating, SpecialFeatures: r.SpecialFeatures, LastUpdate: r.LastUpdate,
+			ArchivedAt: r.ArchivedAt,
 		})
 		films[i] = filmFromConverted(f)
 	}
 	return films
 }
 
-func filmsFromSearchRows(rows []filmsqlc.SearchFilmsRow) []model.Film {
+func filmsFromSearchRows(rows []filmsqlc.SearchFilmsRow) []model.FilmSearchHit {
+	hits := make([]model.FilmSearchHit, len(rows))
+	for i, r := range rows {
+		f := convertFilmFields(filmFields{
+			FilmID: r.FilmID, Title: r.Title, Description: r.Description,
+			ReleaseYear: r.ReleaseYear, LanguageID: r.LanguageID,
+			OriginalLanguageID: r.OriginalLanguageID, RentalDuration: r.RentalDuration,
+			RentalRate: r.RentalRate, Length: r.Length, ReplacementCost: r.ReplacementCost,
+			Rating: r.Rating, SpecialFeatures: r.SpecialFeatures, LastUpdate: r.LastUpdate,
+		})
+		hits[i] = model.FilmSearchHit{
+			Film:                 filmFromConverted(f),
+			TitleHighlight:       r.TitleHighlight,
+			DescriptionHighlight: r.DescriptionHighlight,
+		}
+	}
+	return hits
+}
+
+func filmsFromFuzzySearchRows(rows []filmsqlc.FuzzySearchFilmsRow) []model.Film {
 	films := make([]model.Film, len(rows))
 	for i, r := range rows {
 		f := convertFilmFields(filmFields{
@@ -369,5 +563,6 @@ func filmFromConverted(f filmConvertedFields) model.Film {
 		Rating:             f.Rating,
 		SpecialFeatures:    f.SpecialFeatures,
 		LastUpdate:         f.LastUpdate,
+		ArchivedAt:         f.ArchivedAt,
 	}
 }
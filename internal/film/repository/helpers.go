@@ -167,6 +167,7 @@ type filmFields struct {
 	Rating             filmsqlc.NullMpaaRating
 	SpecialFeatures    []string
 	LastUpdate         pgtype.Timestamptz
+	ArchivedAt         pgtype.Timestamptz // zero for queries that leave archived films out
 }
 
 func convertFilmFields(f filmFields) filmConvertedFields {
@@ -184,6 +185,7 @@ func convertFilmFields(f filmFields) filmConvertedFields {
 		Rating:             ratingToString(f.Rating),
 		SpecialFeatures:    f.SpecialFeatures,
 		LastUpdate:         f.LastUpdate.Time,
+		ArchivedAt:         f.ArchivedAt.Time,
 	}
 }
 
@@ -201,4 +203,5 @@ type filmConvertedFields struct {
 	Rating             string
 	SpecialFeatures    []string
 	LastUpdate         time.Time
+	ArchivedAt         time.Time
 }
@@ -12,20 +12,24 @@ import (
 	"github.com/enkaigaku/dvd-rental/gen/sqlc/film"
 )
 
-// LanguageRepository defines the read-only data access interface for languages.
+// LanguageRepository defines the data access interface for languages.
 type LanguageRepository interface {
 	GetLanguage(ctx context.Context, languageID int32) (model.Language, error)
 	ListLanguages(ctx context.Context) ([]model.Language, error)
 	CountLanguages(ctx context.Context) (int64, error)
+	CreateLanguage(ctx context.Context, name string) (model.Language, error)
+	UpdateLanguage(ctx context.Context, languageID int32, name string) (model.Language, error)
+	DeleteLanguage(ctx context.Context, languageID, reassignTo int32) error
 }
 
 type languageRepository struct {
-	q *filmsqlc.Queries
+	pool *pgxpool.Pool
+	q    *filmsqlc.Queries
 }
 
 // NewLanguageRepository creates a new LanguageRepository backed by PostgreSQL.
 func NewLanguageRepository(pool *pgxpool.Pool) LanguageRepository {
-	return &languageRepository{q: filmsqlc.New(pool)}
+	return &languageRepository{pool: pool, q: filmsqlc.New(pool)}
 }
 
 func (r *languageRepository) GetLanguage(ctx context.Context, languageID int32) (model.Language, error) {
@@ -55,6 +59,57 @@ func (r *languageRepository) CountLanguages(ctx context.Context) (int64, error)
 	return count, nil
 }
 
+func (r *languageRepository) CreateLanguage(ctx context.Context, name string) (model.Language, error) {
+	row, err := r.q.CreateLanguage(ctx, name)
+	if err != nil {
+		return model.Language{}, fmt.Errorf("create language: %w", err)
+	}
+	return toLanguageModel(row), nil
+}
+
+func (r *languageRepository) UpdateLanguage(ctx context.Context, languageID int32, name string) (model.Language, error) {
+	row, err := r.q.UpdateLanguage(ctx, filmsqlc.UpdateLanguageParams{
+		LanguageID: languageID,
+		Name:       name,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Language{}, ErrNotFound
+		}
+		return model.Language{}, fmt.Errorf("update language: %w", err)
+	}
+	return toLanguageModel(row), nil
+}
+
+// DeleteLanguage deletes a language. When reassignTo is not 0 films in the
+// language, or originally in it, are first switched to that language, in
+// the same transaction; otherwise the delete fails while films use it.
+func (r *languageRepository) DeleteLanguage(ctx context.Context, languageID, reassignTo int32) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.q.WithTx(tx)
+	if reassignTo != 0 {
+		if err := q.ReassignFilmLanguage(ctx, filmsqlc.ReassignFilmLanguageParams{
+			FromLanguageID: languageID,
+			ToLanguageID:   reassignTo,
+		}); err != nil {
+			return fmt.Errorf("reassign film language: %w", err)
+		}
+	}
+	if err := q.DeleteLanguage(ctx, languageID); err != nil {
+		return fmt.Errorf("delete language: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
 // --- row to model conversions ---
 // language.name is character(20), space-padded — must trim.
 
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/enkaigaku/dvd-rental/internal/film/model"
+	"github.com/enkaigaku/dvd-rental/gen/sqlc/film"
+)
+
+// PopularityRepository defines the data access interface for film
+// popularity lists. Store 0 means all stores.
+type PopularityRepository interface {
+	RefreshFilmPopularity(ctx context.Context) error
+	ListPopularFilms(ctx context.Context, storeID, windowDays, limit, offset int32) ([]model.PopularFilm, error)
+	CountPopularFilms(ctx context.Context, storeID, windowDays int32) (int64, error)
+	ListTrendingFilms(ctx context.Context, storeID, limit, offset int32) ([]model.PopularFilm, error)
+	CountTrendingFilms(ctx context.Context, storeID int32) (int64, error)
+	ListNewReleases(ctx context.Context, storeID, limit, offset int32) ([]model.Film, error)
+	CountNewReleases(ctx context.Context, storeID int32) (int64, error)
+}
+
+type popularityRepository struct {
+	q *filmsqlc.Queries
+}
+
+// NewPopularityRepository creates a new PopularityRepository backed by PostgreSQL.
+func NewPopularityRepository(pool *pgxpool.Pool) PopularityRepository {
+	return &popularityRepository{q: filmsqlc.New(pool)}
+}
+
+// RefreshFilmPopularity recomputes the rental windows of the film_popularity
+// view, without blocking reads of the previous figures.
+func (r *popularityRepository) RefreshFilmPopularity(ctx context.Context) error {
+	if err := r.q.RefreshFilmPopularity(ctx); err != nil {
+		return fmt.Errorf("refresh film popularity: %w", err)
+	}
+	return nil
+}
+
+func (r *popularityRepository) ListPopularFilms(ctx context.Context, storeID, windowDays, limit, offset int32) ([]model.PopularFilm, error) {
+	rows, err := r.q.ListPopularFilms(ctx, filmsqlc.ListPopularFilmsParams{
+		StoreID:    storeID,
+		WindowDays: windowDays,
+		PageLimit:  limit,
+		PageOffset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list popular films: %w", err)
+	}
+	films := make([]model.PopularFilm, len(rows))
+	for i, r := range rows {
+		f := convertFilmFields(filmFields{
+			FilmID: r.FilmID, Title: r.Title, Description: r.Description,
+			ReleaseYear: r.ReleaseYear, LanguageID: r.LanguageID,
+			OriginalLanguageID: r.OriginalLanguageID, RentalDuration: r.RentalDuration,
+			RentalRate: r.RentalRate, Length: r.Length, ReplacementCost: r.ReplacementCost,
+			Rating: r.Rating, SpecialFeatures: r.SpecialFeatures, LastUpdate: r.LastUpdate,
+		})
+		films[i] = model.PopularFilm{Film: filmFromConverted(f), Rentals: r.Rentals}
+	}
+	return films, nil
+}
+
+func (r *popularityRepository) CountPopularFilms(ctx context.Context, storeID, windowDays int32) (int64, error) {
+	count, err := r.q.CountPopularFilms(ctx, filmsqlc.CountPopularFilmsParams{
+		StoreID:    storeID,
+		WindowDays: windowDays,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("count popular films: %w", err)
+	}
+	return count, nil
+}
+
+func (r *popularityRepository) ListTrendingFilms(ctx context.Context, storeID, limit, offset int32) ([]model.PopularFilm, error) {
+	rows, err := r.q.ListTrendingFilms(ctx, filmsqlc.ListTrendingFilmsParams{
+		StoreID:    storeID,
+		PageLimit:  limit,
+		PageOffset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list trending films: %w", err)
+	}
+	films := make([]model.PopularFilm, len(rows))
+	for i, r := range rows {
+		f := convertFilmFields(filmFields{
+			FilmID: r.FilmID, Title: r.Title, Description: r.Description,
+			ReleaseYear: r.ReleaseYear, LanguageID: r.LanguageID,
+			OriginalLanguageID: r.OriginalLanguageID, RentalDuration: r.RentalDuration,
+			RentalRate: r.RentalRate, Length: r.Length, ReplacementCost: r.ReplacementCost,
+			Rating: r.Rating, SpecialFeatures: r.SpecialFeatures, LastUpdate: r.LastUpdate,
+		})
+		films[i] = model.PopularFilm{Film: filmFromConverted(f), Rentals: r.Rentals}
+	}
+	return films, nil
+}
+
+func (r *popularityRepository) CountTrendingFilms(ctx context.Context, storeID int32) (int64, error) {
+	count, err := r.q.CountTrendingFilms(ctx, storeID)
+	if err != nil {
+		return 0, fmt.Errorf("count trending films: %w", err)
+	}
+	return count, nil
+}
+
+func (r *popularityRepository) ListNewReleases(ctx context.Context, storeID, limit, offset int32) ([]model.Film, error) {
+	rows, err := r.q.ListNewReleases(ctx, filmsqlc.ListNewReleasesParams{
+		StoreID:    storeID,
+		PageLimit:  limit,
+		PageOffset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list new releases: %w", err)
+	}
+	films := make([]model.Film, len(rows))
+	for i, r := range rows {
+		f := convertFilmFields(filmFields{
+			FilmID: r.FilmID, Title: r.Title, Description: r.Description,
+			ReleaseYear: r.ReleaseYear, LanguageID: r.LanguageID,
+			OriginalLanguageID: r.OriginalLanguageID, RentalDuration: r.RentalDuration,
+			RentalRate: r.RentalRate, Length: r.Length, ReplacementCost: r.ReplacementCost,
+			Rating: r.Rating, SpecialFeatures: r.SpecialFeatures, LastUpdate: r.LastUpdate,
+		})
+		films[i] = filmFromConverted(f)
+	}
+	return films, nil
+}
+
+func (r *popularityRepository) CountNewReleases(ctx context.Context, storeID int32) (int64, error) {
+	count, err := r.q.CountNewReleases(ctx, storeID)
+	if err != nil {
+		return 0, fmt.Errorf("count new releases: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/enkaigaku/dvd-rental/internal/film/model"
+	"github.com/enkaigaku/dvd-rental/gen/sqlc/film"
+)
+
+// RecommendationRepository defines the data access interface for film
+// similarities and the recommendations built on them.
+type RecommendationRepository interface {
+	RefreshFilmSimilarities(ctx context.Context, maxPerFilm, minCoRentals int32) (int64, error)
+	ListSimilarFilms(ctx context.Context, filmID, limit int32) ([]model.Recommendation, error)
+	ListRecommendedFilms(ctx context.Context, customerID, limit int32) ([]model.Recommendation, error)
+}
+
+type recommendationRepository struct {
+	pool *pgxpool.Pool
+	q    *filmsqlc.Queries
+}
+
+// NewRecommendationRepository creates a new RecommendationRepository backed by PostgreSQL.
+func NewRecommendationRepository(pool *pgxpool.Pool) RecommendationRepository {
+	return &recommendationRepository{pool: pool, q: filmsqlc.New(pool)}
+}
+
+// RefreshFilmSimilarities recomputes every film's similar films from the
+// rental history, replacing the previous results in one transaction. It
+// returns the number of pairs stored.
+func (r *recommendationRepository) RefreshFilmSimilarities(ctx context.Context, maxPerFilm, minCoRentals int32) (int64, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.q.WithTx(tx)
+	if err := q.DeleteFilmSimilarities(ctx); err != nil {
+		return 0, fmt.Errorf("delete film similarities: %w", err)
+	}
+	n, err := q.InsertFilmSimilarities(ctx, filmsqlc.InsertFilmSimilaritiesParams{
+		MaxPerFilm:   maxPerFilm,
+		MinCoRentals: minCoRentals,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("insert film similarities: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit tx: %w", err)
+	}
+	return n, nil
+}
+
+func (r *recommendationRepository) ListSimilarFilms(ctx context.Context, filmID, limit int32) ([]model.Recommendation, error) {
+	rows, err := r.q.ListSimilarFilms(ctx, filmsqlc.ListSimilarFilmsParams{
+		FilmID:     filmID,
+		MaxResults: limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list similar films: %w", err)
+	}
+	recs := make([]model.Recommendation, len(rows))
+	for i, r := range rows {
+		f := convertFilmFields(filmFields{
+			FilmID: r.FilmID, Title: r.Title, Description: r.Description,
+			ReleaseYear: r.ReleaseYear, LanguageID: r.LanguageID,
+			OriginalLanguageID: r.OriginalLanguageID, RentalDuration: r.RentalDuration,
+			RentalRate: r.RentalRate, Length: r.Length, ReplacementCost: r.ReplacementCost,
+			Rating: r.Rating, SpecialFeatures: r.SpecialFeatures, LastUpdate: r.LastUpdate,
+		})
+		recs[i] = model.Recommendation{
+			Film:  filmFromConverted(f),
+			Score: r.Score,
+		}
+	}
+	return recs, nil
+}
+
+func (r *recommendationRepository) ListRecommendedFilms(ctx context.Context, customerID, limit int32) ([]model.Recommendation, error) {
+	rows, err := r.q.ListRecommendedFilms(ctx, filmsqlc.ListRecommendedFilmsParams{
+		MaxResults: limit,
+		CustomerID: customerID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list recommended films: %w", err)
+	}
+	recs := make([]model.Recommendation, len(rows))
+	for i, r := range rows {
+		f := convertFilmFields(filmFields{
+			FilmID: r.FilmID, Title: r.Title, Description: r.Description,
+			ReleaseYear: r.ReleaseYear, LanguageID: r.LanguageID,
+			OriginalLanguageID: r.OriginalLanguageID, RentalDuration: r.RentalDuration,
+			RentalRate: r.RentalRate, Length: r.Length, ReplacementCost: r.ReplacementCost,
+			Rating: r.Rating, SpecialFeatures: r.SpecialFeatures, LastUpdate: r.LastUpdate,
+		})
+		recs[i] = model.Recommendation{
+			Film:          filmFromConverted(f),
+			Score:         r.Score,
+			BecauseFilmID: r.BecauseFilmID,
+			BecauseTitle:  r.BecauseTitle,
+		}
+	}
+	return recs, nil
+}
@@ -0,0 +1,250 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/enkaigaku/dvd-rental/internal/film/model"
+	"github.com/enkaigaku/dvd-rental/gen/sqlc/film"
+)
+
+// UpsertReviewParams holds the fields of a customer's review of a film.
+type UpsertReviewParams struct {
+	FilmID     int32
+	CustomerID int32
+	Rating     int16
+	Body       string
+	Status     string
+}
+
+// ReviewRepository defines the data access interface for film reviews and
+// the per-film rating aggregates. Every write refreshes the aggregate of
+// the film it touches in the same transaction.
+type ReviewRepository interface {
+	GetReview(ctx context.Context, reviewID int32) (model.Review, error)
+	ListFilmReviews(ctx context.Context, filmID, limit, offset int32) ([]model.Review, error)
+	CountFilmReviews(ctx context.Context, filmID int32) (int64, error)
+	ListReviewsByCustomer(ctx context.Context, customerID, limit, offset int32) ([]model.Review, error)
+	CountReviewsByCustomer(ctx context.Context, customerID int32) (int64, error)
+	ListReviewsByStatus(ctx context.Context, status string, limit, offset int32) ([]model.Review, error)
+	CountReviewsByStatus(ctx context.Context, status string) (int64, error)
+	UpsertReview(ctx context.Context, params UpsertReviewParams) (model.Review, error)
+	UpdateReviewStatus(ctx context.Context, reviewID int32, status, note string) (model.Review, error)
+	DeleteReview(ctx context.Context, reviewID int32) error
+	GetFilmRating(ctx context.Context, filmID int32) (model.FilmRating, error)
+}
+
+type reviewRepository struct {
+	pool *pgxpool.Pool
+	q    *filmsqlc.Queries
+}
+
+// NewReviewRepository creates a new ReviewRepository backed by PostgreSQL.
+func NewReviewRepository(pool *pgxpool.Pool) ReviewRepository {
+	return &reviewRepository{pool: pool, q: filmsqlc.New(pool)}
+}
+
+func (r *reviewRepository) GetReview(ctx context.Context, reviewID int32) (model.Review, error) {
+	row, err := r.q.GetReview(ctx, reviewID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Review{}, ErrNotFound
+		}
+		return model.Review{}, fmt.Errorf("get review: %w", err)
+	}
+	return toReviewModel(row), nil
+}
+
+func (r *reviewRepository) ListFilmReviews(ctx context.Context, filmID, limit, offset int32) ([]model.Review, error) {
+	rows, err := r.q.ListFilmReviews(ctx, filmsqlc.ListFilmReviewsParams{
+		FilmID:     filmID,
+		PageOffset: offset,
+		PageLimit:  limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list film reviews: %w", err)
+	}
+	reviews := make([]model.Review, len(rows))
+	for i, row := range rows {
+		reviews[i] = toReviewModel(filmsqlc.GetReviewRow(row))
+	}
+	return reviews, nil
+}
+
+func (r *reviewRepository) CountFilmReviews(ctx context.Context, filmID int32) (int64, error) {
+	count, err := r.q.CountFilmReviews(ctx, filmID)
+	if err != nil {
+		return 0, fmt.Errorf("count film reviews: %w", err)
+	}
+	return count, nil
+}
+
+func (r *reviewRepository) ListReviewsByCustomer(ctx context.Context, customerID, limit, offset int32) ([]model.Review, error) {
+	rows, err := r.q.ListReviewsByCustomer(ctx, filmsqlc.ListReviewsByCustomerParams{
+		CustomerID: customerID,
+		PageOffset: offset,
+		PageLimit:  limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list reviews by customer: %w", err)
+	}
+	reviews := make([]model.Review, len(rows))
+	for i, row := range rows {
+		reviews[i] = toReviewModel(filmsqlc.GetReviewRow(row))
+	}
+	return reviews, nil
+}
+
+func (r *reviewRepository) CountReviewsByCustomer(ctx context.Context, customerID int32) (int64, error) {
+	count, err := r.q.CountReviewsByCustomer(ctx, customerID)
+	if err != nil {
+		return 0, fmt.Errorf("count reviews by customer: %w", err)
+	}
+	return count, nil
+}
+
+func (r *reviewRepository) ListReviewsByStatus(ctx context.Context, status string, limit, offset int32) ([]model.Review, error) {
+	rows, err := r.q.ListReviewsByStatus(ctx, filmsqlc.ListReviewsByStatusParams{
+		Status:     status,
+		PageOffset: offset,
+		PageLimit:  limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list reviews by status: %w", err)
+	}
+	reviews := make([]model.Review, len(rows))
+	for i, row := range rows {
+		reviews[i] = toReviewModel(filmsqlc.GetReviewRow(row))
+	}
+	return reviews, nil
+}
+
+func (r *reviewRepository) CountReviewsByStatus(ctx context.Context, status string) (int64, error) {
+	count, err := r.q.CountReviewsByStatus(ctx, status)
+	if err != nil {
+		return 0, fmt.Errorf("count reviews by status: %w", err)
+	}
+	return count, nil
+}
+
+func (r *reviewRepository) UpsertReview(ctx context.Context, params UpsertReviewParams) (model.Review, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return model.Review{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.q.WithTx(tx)
+	reviewID, err := q.UpsertReview(ctx, filmsqlc.UpsertReviewParams(params))
+	if err != nil {
+		return model.Review{}, fmt.Errorf("upsert review: %w", err)
+	}
+	if err := q.LockFilmRating(ctx, params.FilmID); err != nil {
+		return model.Review{}, fmt.Errorf("lock film rating: %w", err)
+	}
+	if err := q.RefreshFilmRating(ctx, params.FilmID); err != nil {
+		return model.Review{}, fmt.Errorf("refresh film rating: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return model.Review{}, fmt.Errorf("commit tx: %w", err)
+	}
+	return r.GetReview(ctx, reviewID)
+}
+
+func (r *reviewRepository) UpdateReviewStatus(ctx context.Context, reviewID int32, status, note string) (model.Review, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return model.Review{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.q.WithTx(tx)
+	filmID, err := q.UpdateReviewStatus(ctx, filmsqlc.UpdateReviewStatusParams{
+		Status:         status,
+		ModerationNote: note,
+		ReviewID:       reviewID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Review{}, ErrNotFound
+		}
+		return model.Review{}, fmt.Errorf("update review status: %w", err)
+	}
+	if err := q.LockFilmRating(ctx, filmID); err != nil {
+		return model.Review{}, fmt.Errorf("lock film rating: %w", err)
+	}
+	if err := q.RefreshFilmRating(ctx, filmID); err != nil {
+		return model.Review{}, fmt.Errorf("refresh film rating: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return model.Review{}, fmt.Errorf("commit tx: %w", err)
+	}
+	return r.GetReview(ctx, reviewID)
+}
+
+func (r *reviewRepository) DeleteReview(ctx context.Context, reviewID int32) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.q.WithTx(tx)
+	filmID, err := q.DeleteReview(ctx, reviewID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("delete review: %w", err)
+	}
+	if err := q.LockFilmRating(ctx, filmID); err != nil {
+		return fmt.Errorf("lock film rating: %w", err)
+	}
+	if err := q.RefreshFilmRating(ctx, filmID); err != nil {
+		return fmt.Errorf("refresh film rating: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+// GetFilmRating returns the zero FilmRating for films without approved reviews.
+func (r *reviewRepository) GetFilmRating(ctx context.Context, filmID int32) (model.FilmRating, error) {
+	row, err := r.q.GetFilmRating(ctx, filmID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.FilmRating{}, nil
+		}
+		return model.FilmRating{}, fmt.Errorf("get film rating: %w", err)
+	}
+	return model.FilmRating{
+		ReviewCount:   row.ReviewCount,
+		AverageRating: numericToString(row.AverageRating),
+	}, nil
+}
+
+// --- row to model conversions ---
+
+func toReviewModel(r filmsqlc.GetReviewRow) model.Review {
+	return model.Review{
+		ReviewID:       r.ReviewID,
+		FilmID:         r.FilmID,
+		FilmTitle:      r.Title,
+		CustomerID:     r.CustomerID,
+		CustomerName:   r.FirstName + " " + r.LastName,
+		Rating:         r.Rating,
+		Body:           r.Body,
+		Status:         r.Status,
+		ModerationNote: r.ModerationNote,
+		CreatedAt:      r.CreatedAt.Time,
+		LastUpdate:     r.LastUpdate.Time,
+	}
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/enkaigaku/dvd-rental/internal/film/model"
+	"github.com/enkaigaku/dvd-rental/gen/sqlc/film"
+)
+
+// WishlistRepository defines the data access interface for customers'
+// wishlists.
+type WishlistRepository interface {
+	ListWishlist(ctx context.Context, customerID, limit, offset int32) ([]model.WishlistItem, error)
+	CountWishlist(ctx context.Context, customerID int32) (int64, error)
+	AddWishlistItem(ctx context.Context, customerID, filmID int32) error
+	RemoveWishlistItem(ctx context.Context, customerID, filmID int32) error
+}
+
+type wishlistRepository struct {
+	q *filmsqlc.Queries
+}
+
+// NewWishlistRepository creates a new WishlistRepository backed by PostgreSQL.
+func NewWishlistRepository(pool *pgxpool.Pool) WishlistRepository {
+	return &wishlistRepository{q: filmsqlc.New(pool)}
+}
+
+func (r *wishlistRepository) ListWishlist(ctx context.Context, customerID, limit, offset int32) ([]model.WishlistItem, error) {
+	rows, err := r.q.ListWishlist(ctx, filmsqlc.ListWishlistParams{
+		CustomerID: customerID,
+		PageOffset: offset,
+		PageLimit:  limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list wishlist: %w", err)
+	}
+	items := make([]model.WishlistItem, len(rows))
+	for i, r := range rows {
+		f := convertFilmFields(filmFields{
+			FilmID: r.FilmID, Title: r.Title, Description: r.Description,
+			ReleaseYear: r.ReleaseYear, LanguageID: r.LanguageID,
+			OriginalLanguageID: r.OriginalLanguageID, RentalDuration: r.RentalDuration,
+			RentalRate: r.RentalRate, Length: r.Length, ReplacementCost: r.ReplacementCost,
+			Rating: r.Rating, SpecialFeatures: r.SpecialFeatures, LastUpdate: r.LastUpdate,
+		})
+		items[i] = model.WishlistItem{
+			Film:            filmFromConverted(f),
+			AddedAt:         r.AddedAt.Time,
+			StoreID:         r.StoreID,
+			Copies:          r.Copies,
+			AvailableCopies: r.AvailableCopies,
+		}
+	}
+	return items, nil
+}
+
+func (r *wishlistRepository) CountWishlist(ctx context.Context, customerID int32) (int64, error) {
+	count, err := r.q.CountWishlist(ctx, customerID)
+	if err != nil {
+		return 0, fmt.Errorf("count wishlist: %w", err)
+	}
+	return count, nil
+}
+
+func (r *wishlistRepository) AddWishlistItem(ctx context.Context, customerID, filmID int32) error {
+	err := r.q.AddWishlistItem(ctx, filmsqlc.AddWishlistItemParams{
+		CustomerID: customerID,
+		FilmID:     filmID,
+	})
+	if err != nil {
+		return fmt.Errorf("add wishlist item: %w", err)
+	}
+	return nil
+}
+
+func (r *wishlistRepository) RemoveWishlistItem(ctx context.Context, customerID, filmID int32) error {
+	n, err := r.q.RemoveWishlistItem(ctx, filmsqlc.RemoveWishlistItemParams{
+		CustomerID: customerID,
+		FilmID:     filmID,
+	})
+	if err != nil {
+		return fmt.Errorf("remove wishlist item: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -4,11 +4,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/enkaigaku/dvd-rental/internal/film/model"
 	"github.com/enkaigaku/dvd-rental/internal/film/repository"
 )
 
+const (
+	defaultCoStars = 10
+	maxCoStars     = 50
+)
+
 // ActorService contains business logic for actor operations.
 type ActorService struct {
 	actorRepo repository.ActorRepository
@@ -35,17 +41,100 @@ func (s *ActorService) GetActor(ctx context.Context, actorID int32) (model.Actor
 	return actor, nil
 }
 
-// ListActors returns a paginated list of actors.
-func (s *ActorService) ListActors(ctx context.Context, pageSize, page int32) ([]model.Actor, int64, error) {
+// GetActorDetail returns an actor's profile: their films grouped by
+// category, their film and rental counts, and up to coStarLimit of the
+// actors they appear with most. Archived actors have no profile, and
+// archived films and co-stars are left out of it.
+func (s *ActorService) GetActorDetail(ctx context.Context, actorID, coStarLimit int32) (model.ActorDetail, error) {
+	actor, err := s.GetActor(ctx, actorID)
+	if err != nil {
+		return model.ActorDetail{}, err
+	}
+	if !actor.ArchivedAt.IsZero() {
+		return model.ActorDetail{}, fmt.Errorf("actor %d: %w", actorID, ErrNotFound)
+	}
+
+	if coStarLimit <= 0 {
+		coStarLimit = defaultCoStars
+	}
+	if coStarLimit > maxCoStars {
+		coStarLimit = maxCoStars
+	}
+
+	stats, err := s.actorRepo.GetActorStats(ctx, actorID)
+	if err != nil {
+		return model.ActorDetail{}, err
+	}
+	entries, err := s.actorRepo.ListActorFilmography(ctx, actorID)
+	if err != nil {
+		return model.ActorDetail{}, err
+	}
+	coStars, err := s.actorRepo.ListActorCoStars(ctx, actorID, coStarLimit)
+	if err != nil {
+		return model.ActorDetail{}, err
+	}
+
+	// Entries come sorted by category, so each category's films are
+	// consecutive.
+	var filmography []model.ActorCategoryFilms
+	for _, e := range entries {
+		if n := len(filmography); n == 0 || filmography[n-1].CategoryID != e.CategoryID {
+			filmography = append(filmography, model.ActorCategoryFilms{
+				CategoryID:   e.CategoryID,
+				CategoryName: e.CategoryName,
+			})
+		}
+		last := &filmography[len(filmography)-1]
+		last.Films = append(last.Films, e.ActorFilm)
+	}
+
+	return model.ActorDetail{
+		Actor:       actor,
+		FilmCount:   stats.FilmCount,
+		RentalCount: stats.RentalCount,
+		Filmography: filmography,
+		CoStars:     coStars,
+	}, nil
+}
+
+// ListActors returns a paginated list of actors, leaving out archived
+// actors unless includeArchived is set.
+func (s *ActorService) ListActors(ctx context.Context, includeArchived bool, pageSize, page int32) ([]model.Actor, int64, error) {
 	pageSize, page = clampPagination(pageSize, page)
 	offset := (page - 1) * pageSize
 
-	actors, err := s.actorRepo.ListActors(ctx, pageSize, offset)
+	actors, err := s.actorRepo.ListActors(ctx, includeArchived, pageSize, offset)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	total, err := s.actorRepo.CountActors(ctx)
+	total, err := s.actorRepo.CountActors(ctx, includeArchived)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return actors, total, nil
+}
+
+// SearchActors returns actors whose first, last or full name starts with
+// query, or is similar to it. Archived actors are left out unless
+// includeArchived is set.
+func (s *ActorService) SearchActors(ctx context.Context, query string, includeArchived bool, pageSize, page int32) ([]model.Actor, int64, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, 0, fmt.Errorf("search query must not be empty: %w", ErrInvalidArgument)
+	}
+
+	pageSize, page = clampPagination(pageSize, page)
+	offset := (page - 1) * pageSize
+	pattern := likePrefix(query)
+
+	actors, err := s.actorRepo.SearchActors(ctx, pattern, query, includeArchived, pageSize, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.actorRepo.CountSearchActors(ctx, pattern, query, includeArchived)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -104,7 +193,9 @@ func (s *ActorService) UpdateActor(ctx context.Context, actorID int32, firstName
 	return actor, nil
 }
 
-// DeleteActor deletes an actor. Fails if film_actor records reference it.
+// DeleteActor archives an actor. Archived actors stay in the casts of
+// their films but are hidden from actor listings and profiles, and cannot
+// be added to films.
 func (s *ActorService) DeleteActor(ctx context.Context, actorID int32) error {
 	if actorID <= 0 {
 		return fmt.Errorf("actor_id must be positive: %w", ErrInvalidArgument)
@@ -118,11 +209,21 @@ func (s *ActorService) DeleteActor(ctx context.Context, actorID int32) error {
 		return err
 	}
 
-	if err := s.actorRepo.DeleteActor(ctx, actorID); err != nil {
-		if isForeignKeyViolation(err) {
-			return fmt.Errorf("actor %d is referenced by film records: %w", actorID, ErrForeignKey)
-		}
-		return err
+	return s.actorRepo.ArchiveActor(ctx, actorID)
+}
+
+// RestoreActor makes an archived actor available again.
+func (s *ActorService) RestoreActor(ctx context.Context, actorID int32) (model.Actor, error) {
+	if actorID <= 0 {
+		return model.Actor{}, fmt.Errorf("actor_id must be positive: %w", ErrInvalidArgument)
+	}
+
+	if _, err := s.GetActor(ctx, actorID); err != nil {
+		return model.Actor{}, err
+	}
+
+	if err := s.actorRepo.RestoreActor(ctx, actorID); err != nil {
+		return model.Actor{}, err
 	}
-	return nil
+	return s.actorRepo.GetActor(ctx, actorID)
 }
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/enkaigaku/dvd-rental/internal/film/model"
+	"github.com/enkaigaku/dvd-rental/internal/film/repository"
+)
+
+// exportBatchSize is how many films ExportFilms reads per query.
+const exportBatchSize = 500
+
+// Defaults for film fields a bulk import leaves empty, matching the
+// film table's column defaults.
+const (
+	defaultImportRentalDuration  = 3
+	defaultImportRentalRate      = "4.99"
+	defaultImportReplacementCost = "19.99"
+)
+
+// ImportFilms creates films from records read from next until it returns
+// io.EOF. Each record is imported on its own: a record that fails
+// validation is reported and skipped without affecting the others. Actors
+// are matched by name and created if missing; languages and categories
+// must already exist. With dryRun every record is checked but nothing is
+// written.
+func (s *FilmService) ImportFilms(ctx context.Context, next func() (model.FilmRecord, error), dryRun bool) (model.FilmImportReport, error) {
+	languages, err := s.languageRepo.ListLanguages(ctx)
+	if err != nil {
+		return model.FilmImportReport{}, err
+	}
+	languageIDs := make(map[string]int32, len(languages))
+	for _, l := range languages {
+		languageIDs[strings.ToLower(l.Name)] = l.LanguageID
+	}
+
+	categories, err := s.categoryRepo.ListCategories(ctx)
+	if err != nil {
+		return model.FilmImportReport{}, err
+	}
+	categoryIDs := make(map[string]int32, len(categories))
+	for _, c := range categories {
+		categoryIDs[strings.ToLower(c.Name)] = c.CategoryID
+	}
+
+	// A dry run rolls back each film, so an actor new to the catalog is
+	// "created" again by every row naming them; count each one once.
+	createdActors := make(map[string]bool)
+	report := model.FilmImportReport{DryRun: dryRun}
+	for {
+		rec, err := next()
+		if errors.Is(err, io.EOF) {
+			return report, nil
+		}
+		if err != nil {
+			return model.FilmImportReport{}, err
+		}
+		report.Rows++
+
+		params, err := s.importParams(ctx, rec, languageIDs, categoryIDs)
+		if err == nil {
+			var result repository.ImportFilmResult
+			result, err = s.catalogRepo.ImportFilm(ctx, params, dryRun)
+			if err == nil {
+				report.Imported++
+				for _, actor := range result.ActorsCreated {
+					if key := actorKey(actor); !createdActors[key] {
+						createdActors[key] = true
+						report.ActorsCreated++
+					}
+				}
+				continue
+			}
+			if isDataError(err) {
+				err = fmt.Errorf("%s: %w", err, ErrInvalidArgument)
+			}
+		}
+		if !errors.Is(err, ErrInvalidArgument) {
+			return model.FilmImportReport{}, fmt.Errorf("row %d: %w", report.Rows, err)
+		}
+
+		report.Failed++
+		report.Errors = append(report.Errors, model.FilmImportError{
+			Row:     report.Rows,
+			Title:   rec.Title,
+			Message: strings.TrimSuffix(err.Error(), ": "+ErrInvalidArgument.Error()),
+		})
+	}
+}
+
+// importParams validates an import record and resolves its language,
+// categories and actor names.
+func (s *FilmService) importParams(ctx context.Context, rec model.FilmRecord, languageIDs, categoryIDs map[string]int32) (repository.ImportFilmParams, error) {
+	params := repository.ImportFilmParams{
+		CreateFilmParams: repository.CreateFilmParams{
+			Title:           strings.TrimSpace(rec.Title),
+			Description:     rec.Description,
+			ReleaseYear:     rec.ReleaseYear,
+			RentalDuration:  rec.RentalDuration,
+			RentalRate:      rec.RentalRate,
+			Length:          rec.Length,
+			ReplacementCost: rec.ReplacementCost,
+			Rating:          rec.Rating,
+			SpecialFeatures: rec.SpecialFeatures,
+		},
+	}
+	if params.RentalDuration == 0 {
+		params.RentalDuration = defaultImportRentalDuration
+	}
+	if params.RentalRate == "" {
+		params.RentalRate = defaultImportRentalRate
+	}
+	if params.ReplacementCost == "" {
+		params.ReplacementCost = defaultImportReplacementCost
+	}
+
+	languageID, ok := languageIDs[strings.ToLower(strings.TrimSpace(rec.Language))]
+	if !ok {
+		return repository.ImportFilmParams{}, fmt.Errorf("unknown language %q: %w", rec.Language, ErrInvalidArgument)
+	}
+	params.LanguageID = languageID
+	if rec.OriginalLanguage != "" {
+		originalID, ok := languageIDs[strings.ToLower(strings.TrimSpace(rec.OriginalLanguage))]
+		if !ok {
+			return repository.ImportFilmParams{}, fmt.Errorf("unknown original language %q: %w", rec.OriginalLanguage, ErrInvalidArgument)
+		}
+		params.OriginalLanguageID = originalID
+	}
+
+	if err := s.validateFilmParams(ctx, params.Title, params.LanguageID, params.OriginalLanguageID, params.Rating, params.RentalRate, params.ReplacementCost); err != nil {
+		return repository.ImportFilmParams{}, err
+	}
+
+	seenCategories := make(map[int32]bool, len(rec.Categories))
+	for _, name := range rec.Categories {
+		categoryID, ok := categoryIDs[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return repository.ImportFilmParams{}, fmt.Errorf("unknown category %q: %w", name, ErrInvalidArgument)
+		}
+		if !seenCategories[categoryID] {
+			seenCategories[categoryID] = true
+			params.CategoryIDs = append(params.CategoryIDs, categoryID)
+		}
+	}
+
+	seenActors := make(map[string]bool, len(rec.Actors))
+	for _, name := range rec.Actors {
+		actor, err := parseActorName(name)
+		if err != nil {
+			return repository.ImportFilmParams{}, err
+		}
+		if key := actorKey(actor); !seenActors[key] {
+			seenActors[key] = true
+			params.Actors = append(params.Actors, actor)
+		}
+	}
+
+	return params, nil
+}
+
+// actorKey identifies an actor by name the way FindActorByName matches
+// them, ignoring case.
+func actorKey(a repository.ActorName) string {
+	return strings.ToUpper(a.FirstName + " " + a.LastName)
+}
+
+// parseActorName splits "FIRST LAST" into the first word and the rest.
+func parseActorName(name string) (repository.ActorName, error) {
+	first, last, ok := strings.Cut(strings.Join(strings.Fields(name), " "), " ")
+	if !ok {
+		return repository.ActorName{}, fmt.Errorf("actor %q must have a first and last name: %w", name, ErrInvalidArgument)
+	}
+	return repository.ActorName{FirstName: first, LastName: last}, nil
+}
+
+// ExportFilms passes every film in the catalog to send, in film_id order,
+// stopping at the first error send returns.
+func (s *FilmService) ExportFilms(ctx context.Context, send func(model.FilmRecord) error) error {
+	var after int32
+	for {
+		records, err := s.catalogRepo.ExportFilms(ctx, after, exportBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, rec := range records {
+			if err := send(rec); err != nil {
+				return err
+			}
+		}
+		if len(records) < exportBatchSize {
+			return nil
+		}
+		after = records[len(records)-1].FilmID
+	}
+}
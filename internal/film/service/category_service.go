@@ -4,12 +4,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/enkaigaku/dvd-rental/internal/film/model"
 	"github.com/enkaigaku/dvd-rental/internal/film/repository"
 )
 
-// CategoryService contains business logic for category operations (read-only).
+// promotionCategoryConstraint is the foreign key from a promotion limited
+// to a category.
+const promotionCategoryConstraint = "promotion_category_id_fkey"
+
+// CategoryService contains business logic for category operations.
 type CategoryService struct {
 	categoryRepo repository.CategoryRepository
 }
@@ -49,3 +54,82 @@ func (s *CategoryService) ListCategories(ctx context.Context) ([]model.Category,
 
 	return categories, total, nil
 }
+
+// CreateCategory creates a new category. Names are unique, ignoring case.
+func (s *CategoryService) CreateCategory(ctx context.Context, name string) (model.Category, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return model.Category{}, fmt.Errorf("name must not be empty: %w", ErrInvalidArgument)
+	}
+
+	category, err := s.categoryRepo.CreateCategory(ctx, name)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return model.Category{}, fmt.Errorf("category %q: %w", name, ErrAlreadyExists)
+		}
+		return model.Category{}, err
+	}
+	return category, nil
+}
+
+// UpdateCategory renames a category.
+func (s *CategoryService) UpdateCategory(ctx context.Context, categoryID int32, name string) (model.Category, error) {
+	if categoryID <= 0 {
+		return model.Category{}, fmt.Errorf("category_id must be positive: %w", ErrInvalidArgument)
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return model.Category{}, fmt.Errorf("name must not be empty: %w", ErrInvalidArgument)
+	}
+
+	category, err := s.categoryRepo.UpdateCategory(ctx, categoryID, name)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.Category{}, fmt.Errorf("category %d: %w", categoryID, ErrNotFound)
+		}
+		if isUniqueViolation(err) {
+			return model.Category{}, fmt.Errorf("category %q: %w", name, ErrAlreadyExists)
+		}
+		return model.Category{}, err
+	}
+	return category, nil
+}
+
+// DeleteCategory deletes a category. While films are in it the delete
+// fails, unless reassignTo names another category to move them to first.
+// It also fails while a promotion is limited to the category.
+func (s *CategoryService) DeleteCategory(ctx context.Context, categoryID, reassignTo int32) error {
+	if categoryID <= 0 {
+		return fmt.Errorf("category_id must be positive: %w", ErrInvalidArgument)
+	}
+	if reassignTo < 0 || reassignTo == categoryID {
+		return fmt.Errorf("reassign_to must be another category: %w", ErrInvalidArgument)
+	}
+
+	// Verify the category exists.
+	if _, err := s.categoryRepo.GetCategory(ctx, categoryID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("category %d: %w", categoryID, ErrNotFound)
+		}
+		return err
+	}
+	if reassignTo != 0 {
+		if _, err := s.categoryRepo.GetCategory(ctx, reassignTo); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return fmt.Errorf("reassign_to category %d not found: %w", reassignTo, ErrInvalidArgument)
+			}
+			return err
+		}
+	}
+
+	if err := s.categoryRepo.DeleteCategory(ctx, categoryID, reassignTo); err != nil {
+		if isForeignKeyViolation(err) {
+			if violatedConstraint(err) == promotionCategoryConstraint {
+				return fmt.Errorf("category %d is used by a promotion; change or delete the promotion first: %w", categoryID, ErrForeignKey)
+			}
+			return fmt.Errorf("category %d has films; reassign them to another category first: %w", categoryID, ErrForeignKey)
+		}
+		return err
+	}
+	return nil
+}
@@ -11,4 +11,6 @@ var (
 	ErrAlreadyExists = errors.New("already exists")
 	// ErrForeignKey indicates the operation violates a foreign key constraint.
 	ErrForeignKey = errors.New("referenced by another entity")
+	// ErrPermissionDenied indicates the caller may not perform the operation.
+	ErrPermissionDenied = errors.New("permission denied")
 )
@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/enkaigaku/dvd-rental/internal/film/media"
+	"github.com/enkaigaku/dvd-rental/internal/film/model"
+	"github.com/enkaigaku/dvd-rental/internal/film/repository"
+)
+
+// MaxFilmAssetBytes is the largest image file accepted for upload.
+const MaxFilmAssetBytes = 10 << 20
+
+// filmAssetRenditions are the sizes each uploaded image is rendered in,
+// smallest first, with their widths in pixels.
+var filmAssetRenditions = []struct {
+	size  string
+	width int
+}{
+	{model.FilmAssetSizeThumb, 185},
+	{model.FilmAssetSizeMedium, 342},
+	{model.FilmAssetSizeLarge, 780},
+}
+
+var validFilmAssetKinds = map[string]bool{
+	model.FilmAssetKindPoster: true,
+	model.FilmAssetKindStill:  true,
+}
+
+var validFilmAssetSizes = map[string]bool{
+	model.FilmAssetSizeThumb:    true,
+	model.FilmAssetSizeMedium:   true,
+	model.FilmAssetSizeLarge:    true,
+	model.FilmAssetSizeOriginal: true,
+}
+
+// FilmAssetService manages film posters and stills: it renders uploaded
+// images in the sizes the customer app shows, keeps the images in a blob
+// store and records them in the database.
+type FilmAssetService struct {
+	assetRepo repository.FilmAssetRepository
+	filmRepo  repository.FilmRepository
+	blobs     media.BlobStore
+}
+
+// NewFilmAssetService creates a new FilmAssetService.
+func NewFilmAssetService(assetRepo repository.FilmAssetRepository, filmRepo repository.FilmRepository, blobs media.BlobStore) *FilmAssetService {
+	return &FilmAssetService{
+		assetRepo: assetRepo,
+		filmRepo:  filmRepo,
+		blobs:     blobs,
+	}
+}
+
+// UploadFilmAsset stores a JPEG or PNG image as a film's poster or one of
+// its stills. The original is kept along with JPEG renditions of the
+// sizes narrower than it. A new poster replaces the film's previous one.
+func (s *FilmAssetService) UploadFilmAsset(ctx context.Context, filmID int32, kind string, data []byte) (model.FilmAsset, error) {
+	if filmID <= 0 {
+		return model.FilmAsset{}, fmt.Errorf("film_id must be positive: %w", ErrInvalidArgument)
+	}
+	if !validFilmAssetKinds[kind] {
+		return model.FilmAsset{}, fmt.Errorf("invalid kind %q, must be poster or still: %w", kind, ErrInvalidArgument)
+	}
+	if len(data) == 0 {
+		return model.FilmAsset{}, fmt.Errorf("image is empty: %w", ErrInvalidArgument)
+	}
+	if len(data) > MaxFilmAssetBytes {
+		return model.FilmAsset{}, fmt.Errorf("image exceeds %d bytes: %w", MaxFilmAssetBytes, ErrInvalidArgument)
+	}
+
+	if _, err := s.filmRepo.GetFilm(ctx, filmID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.FilmAsset{}, fmt.Errorf("film %d: %w", filmID, ErrNotFound)
+		}
+		return model.FilmAsset{}, err
+	}
+
+	img, contentType, err := media.DecodeImage(data)
+	if err != nil {
+		return model.FilmAsset{}, fmt.Errorf("%v: %w", err, ErrInvalidArgument)
+	}
+	width := img.Bounds().Dx()
+
+	blobs := make(map[string][]byte, len(filmAssetRenditions)+1)
+	var sizes []string
+	for _, r := range filmAssetRenditions {
+		if r.width >= width {
+			break
+		}
+		b, err := media.ResizeJPEG(img, r.width)
+		if err != nil {
+			return model.FilmAsset{}, fmt.Errorf("render %s: %w", r.size, err)
+		}
+		blobs[r.size] = b
+		sizes = append(sizes, r.size)
+	}
+	blobs[model.FilmAssetSizeOriginal] = data
+	sizes = append(sizes, model.FilmAssetSizeOriginal)
+
+	asset, err := s.assetRepo.CreateFilmAsset(ctx, repository.CreateFilmAssetParams{
+		FilmID:      filmID,
+		Kind:        kind,
+		ContentType: contentType,
+		Width:       int32(width),
+		Height:      int32(img.Bounds().Dy()),
+		Sizes:       sizes,
+	})
+	if err != nil {
+		return model.FilmAsset{}, err
+	}
+
+	for _, size := range sizes {
+		if err := s.blobs.Put(ctx, filmAssetKey(asset, size), blobs[size]); err != nil {
+			if _, delErr := s.assetRepo.DeleteFilmAsset(ctx, filmID, asset.AssetID); delErr == nil {
+				s.deleteBlobs(ctx, asset)
+			}
+			return model.FilmAsset{}, err
+		}
+	}
+
+	if kind == model.FilmAssetKindPoster {
+		// Films show their newest poster, so a failure here only leaves
+		// the old poster's files behind.
+		replaced, err := s.assetRepo.DeleteOtherFilmPosters(ctx, filmID, asset.AssetID)
+		if err == nil {
+			for _, old := range replaced {
+				s.deleteBlobs(ctx, old)
+			}
+		}
+	}
+
+	return asset, nil
+}
+
+// ListFilmAssets returns a film's poster and stills.
+func (s *FilmAssetService) ListFilmAssets(ctx context.Context, filmID int32) ([]model.FilmAsset, error) {
+	if filmID <= 0 {
+		return nil, fmt.Errorf("film_id must be positive: %w", ErrInvalidArgument)
+	}
+
+	if _, err := s.filmRepo.GetFilm(ctx, filmID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("film %d: %w", filmID, ErrNotFound)
+		}
+		return nil, err
+	}
+
+	return s.assetRepo.ListFilmAssets(ctx, filmID)
+}
+
+// OpenFilmAsset opens one size of a film asset for reading and returns it
+// with its content type. Sizes that were not rendered because the original
+// is no wider are served by the original.
+func (s *FilmAssetService) OpenFilmAsset(ctx context.Context, assetID int32, size string) (io.ReadCloser, string, error) {
+	if assetID <= 0 {
+		return nil, "", fmt.Errorf("asset_id must be positive: %w", ErrInvalidArgument)
+	}
+	if size == "" {
+		size = model.FilmAssetSizeOriginal
+	}
+	if !validFilmAssetSizes[size] {
+		return nil, "", fmt.Errorf("invalid size %q, must be thumb, medium, large or original: %w", size, ErrInvalidArgument)
+	}
+
+	asset, err := s.assetRepo.GetFilmAsset(ctx, assetID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, "", fmt.Errorf("film asset %d: %w", assetID, ErrNotFound)
+		}
+		return nil, "", err
+	}
+
+	contentType := "image/jpeg"
+	if !slices.Contains(asset.Sizes, size) {
+		size = model.FilmAssetSizeOriginal
+	}
+	if size == model.FilmAssetSizeOriginal {
+		contentType = asset.ContentType
+	}
+
+	rc, err := s.blobs.Open(ctx, filmAssetKey(asset, size))
+	if err != nil {
+		if errors.Is(err, media.ErrBlobNotFound) {
+			return nil, "", fmt.Errorf("film asset %d %s: %w", assetID, size, ErrNotFound)
+		}
+		return nil, "", err
+	}
+	return rc, contentType, nil
+}
+
+// DeleteFilmAsset deletes one of a film's assets and its files.
+func (s *FilmAssetService) DeleteFilmAsset(ctx context.Context, filmID, assetID int32) error {
+	if filmID <= 0 {
+		return fmt.Errorf("film_id must be positive: %w", ErrInvalidArgument)
+	}
+	if assetID <= 0 {
+		return fmt.Errorf("asset_id must be positive: %w", ErrInvalidArgument)
+	}
+
+	asset, err := s.assetRepo.DeleteFilmAsset(ctx, filmID, assetID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("film %d has no asset %d: %w", filmID, assetID, ErrNotFound)
+		}
+		return err
+	}
+
+	s.deleteBlobs(ctx, asset)
+	return nil
+}
+
+// deleteBlobs removes the files of an asset whose record is gone. Files
+// left behind when this fails are unreachable and only take up space.
+func (s *FilmAssetService) deleteBlobs(ctx context.Context, asset model.FilmAsset) {
+	for _, size := range asset.Sizes {
+		_ = s.blobs.Delete(ctx, filmAssetKey(asset, size))
+	}
+}
+
+func filmAssetKey(asset model.FilmAsset, size string) string {
+	return fmt.Sprintf("films/%d/%d/%s", asset.FilmID, asset.AssetID, size)
+}
+
+// attachPosters sets the poster of the films among items that have one.
+// Like the other enrichments of film views it is best effort: when the
+// posters cannot be loaded the films are shown without them.
+func attachPosters[T any](ctx context.Context, repo repository.FilmAssetRepository, items []T, film func(*T) *model.Film) {
+	if len(items) == 0 {
+		return
+	}
+
+	ids := make([]int32, len(items))
+	for i := range items {
+		ids[i] = film(&items[i]).FilmID
+	}
+
+	posters, err := repo.ListFilmPosters(ctx, ids)
+	if err != nil {
+		return
+	}
+	byFilm := make(map[int32]*model.FilmAsset, len(posters))
+	for i := range posters {
+		byFilm[posters[i].FilmID] = &posters[i]
+	}
+
+	for i := range items {
+		f := film(&items[i])
+		f.Poster = byFilm[f.FilmID]
+	}
+}
+
+func filmOf(f *model.Film) *model.Film                         { return f }
+func filmOfSearchHit(h *model.FilmSearchHit) *model.Film       { return &h.Film }
+func filmOfPopularFilm(p *model.PopularFilm) *model.Film       { return &p.Film }
+func filmOfRecommendation(r *model.Recommendation) *model.Film { return &r.Film }
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/enkaigaku/dvd-rental/internal/film/model"
 	"github.com/enkaigaku/dvd-rental/internal/film/repository"
@@ -16,10 +17,14 @@ var validRatings = map[string]bool{
 
 // FilmService contains business logic for film operations.
 type FilmService struct {
-	filmRepo     repository.FilmRepository
-	actorRepo    repository.ActorRepository
-	categoryRepo repository.CategoryRepository
-	languageRepo repository.LanguageRepository
+	filmRepo       repository.FilmRepository
+	actorRepo      repository.ActorRepository
+	categoryRepo   repository.CategoryRepository
+	languageRepo   repository.LanguageRepository
+	reviewRepo     repository.ReviewRepository
+	popularityRepo repository.PopularityRepository
+	catalogRepo    repository.CatalogRepository
+	assetRepo      repository.FilmAssetRepository
 }
 
 // NewFilmService creates a new FilmService.
@@ -28,17 +33,27 @@ func NewFilmService(
 	actorRepo repository.ActorRepository,
 	categoryRepo repository.CategoryRepository,
 	languageRepo repository.LanguageRepository,
+	reviewRepo repository.ReviewRepository,
+	popularityRepo repository.PopularityRepository,
+	catalogRepo repository.CatalogRepository,
+	assetRepo repository.FilmAssetRepository,
 ) *FilmService {
 	return &FilmService{
-		filmRepo:     filmRepo,
-		actorRepo:    actorRepo,
-		categoryRepo: categoryRepo,
-		languageRepo: languageRepo,
+		filmRepo:       filmRepo,
+		actorRepo:      actorRepo,
+		categoryRepo:   categoryRepo,
+		languageRepo:   languageRepo,
+		reviewRepo:     reviewRepo,
+		popularityRepo: popularityRepo,
+		catalogRepo:    catalogRepo,
+		assetRepo:      assetRepo,
 	}
 }
 
-// GetFilm returns a film with enriched details (language names, actors, categories).
-func (s *FilmService) GetFilm(ctx context.Context, filmID int32) (model.FilmDetail, error) {
+// GetFilm returns a film with enriched details (language names, actors,
+// categories, average rating, poster and stills). Archived films are not
+// found unless includeArchived is set.
+func (s *FilmService) GetFilm(ctx context.Context, filmID int32, includeArchived bool) (model.FilmDetail, error) {
 	if filmID <= 0 {
 		return model.FilmDetail{}, fmt.Errorf("film_id must be positive: %w", ErrInvalidArgument)
 	}
@@ -50,6 +65,9 @@ func (s *FilmService) GetFilm(ctx context.Context, filmID int32) (model.FilmDeta
 		}
 		return model.FilmDetail{}, err
 	}
+	if !film.ArchivedAt.IsZero() && !includeArchived {
+		return model.FilmDetail{}, fmt.Errorf("film %d: %w", filmID, ErrNotFound)
+	}
 
 	detail := model.FilmDetail{Film: film}
 
@@ -79,47 +97,137 @@ func (s *FilmService) GetFilm(ctx context.Context, filmID int32) (model.FilmDeta
 		detail.Categories = categories
 	}
 
+	// Fetch rating aggregate.
+	rating, err := s.reviewRepo.GetFilmRating(ctx, filmID)
+	if err == nil {
+		detail.AverageRating = rating.AverageRating
+		detail.ReviewCount = rating.ReviewCount
+	}
+
+	// Fetch poster and stills.
+	assets, err := s.assetRepo.ListFilmAssets(ctx, filmID)
+	if err == nil {
+		for _, a := range assets {
+			if a.Kind == model.FilmAssetKindPoster {
+				detail.Poster = &a
+			} else {
+				detail.Stills = append(detail.Stills, a)
+			}
+		}
+	}
+
 	return detail, nil
 }
 
-// ListFilms returns a paginated list of films.
-func (s *FilmService) ListFilms(ctx context.Context, pageSize, page int32) ([]model.Film, int64, error) {
+var validSorts = map[string]bool{
+	repository.FilmSortByTitle:       true,
+	repository.FilmSortByReleaseYear: true,
+	repository.FilmSortByRentalRate:  true,
+	repository.FilmSortByPopularity:  true,
+	repository.FilmSortByRating:      true,
+}
+
+// ListFilms returns a paginated list of the films matching a filter, sorted
+// by title unless another order is given.
+func (s *FilmService) ListFilms(ctx context.Context, filter repository.FilmFilter, sort repository.FilmSort, pageSize, page int32) ([]model.Film, int64, error) {
+	if err := validateFilmFilter(filter); err != nil {
+		return nil, 0, err
+	}
+	if sort.By == "" {
+		sort.By = repository.FilmSortByTitle
+	}
+	if !validSorts[sort.By] {
+		return nil, 0, fmt.Errorf("invalid sort_by %q, must be one of title, release_year, rental_rate, popularity, rating: %w", sort.By, ErrInvalidArgument)
+	}
+
 	pageSize, page = clampPagination(pageSize, page)
 	offset := (page - 1) * pageSize
 
-	films, err := s.filmRepo.ListFilms(ctx, pageSize, offset)
+	films, err := s.filmRepo.ListFilms(ctx, filter, sort, pageSize, offset)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	total, err := s.filmRepo.CountFilms(ctx)
+	total, err := s.filmRepo.CountFilms(ctx, filter)
 	if err != nil {
 		return nil, 0, err
 	}
 
+	attachPosters(ctx, s.assetRepo, films, filmOf)
 	return films, total, nil
 }
 
-// SearchFilms performs full-text search on films.
-func (s *FilmService) SearchFilms(ctx context.Context, query string, pageSize, page int32) ([]model.Film, int64, error) {
-	if query == "" {
-		return nil, 0, fmt.Errorf("search query must not be empty: %w", ErrInvalidArgument)
+// ListFilmFacets returns the number of films matching a filter per rating,
+// category and language. Each facet ignores its own filter, so its counts
+// are what choosing a different value would return.
+func (s *FilmService) ListFilmFacets(ctx context.Context, filter repository.FilmFilter) (model.FilmFacets, error) {
+	if err := validateFilmFilter(filter); err != nil {
+		return model.FilmFacets{}, err
+	}
+	return s.filmRepo.ListFilmFacets(ctx, filter)
+}
+
+// SearchFilms searches film titles and descriptions as the query is typed:
+// every word of the query must match the start of a word in the film. When
+// nothing matches, it falls back to titles similar to the query, so that
+// misspelled searches still find something.
+func (s *FilmService) SearchFilms(ctx context.Context, query string, pageSize, page int32) ([]model.FilmSearchHit, int64, error) {
+	query = strings.TrimSpace(query)
+	tsQuery := prefixTSQuery(query)
+	if tsQuery == "" {
+		return nil, 0, fmt.Errorf("search query must contain a letter or digit: %w", ErrInvalidArgument)
 	}
 
 	pageSize, page = clampPagination(pageSize, page)
 	offset := (page - 1) * pageSize
 
-	films, err := s.filmRepo.SearchFilms(ctx, query, pageSize, offset)
+	total, err := s.filmRepo.CountSearchFilms(ctx, tsQuery)
 	if err != nil {
 		return nil, 0, err
 	}
+	if total > 0 {
+		hits, err := s.filmRepo.SearchFilms(ctx, tsQuery, pageSize, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		attachPosters(ctx, s.assetRepo, hits, filmOfSearchHit)
+		return hits, total, nil
+	}
 
-	total, err := s.filmRepo.CountSearchFilms(ctx, query)
+	total, err = s.filmRepo.CountFuzzySearchFilms(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	films, err := s.filmRepo.FuzzySearchFilms(ctx, query, pageSize, offset)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	return films, total, nil
+	hits := make([]model.FilmSearchHit, len(films))
+	for i, f := range films {
+		hits[i] = model.FilmSearchHit{Film: f, TitleHighlight: f.Title, Fuzzy: true}
+	}
+	attachPosters(ctx, s.assetRepo, hits, filmOfSearchHit)
+	return hits, total, nil
+}
+
+// SuggestFilms returns up to limit film titles for a partially typed query,
+// titles starting with the query first.
+func (s *FilmService) SuggestFilms(ctx context.Context, query string, limit int32) ([]model.FilmSuggestion, error) {
+	query = strings.TrimSpace(query)
+	tsQuery := prefixTSQuery(query)
+	if tsQuery == "" {
+		return nil, fmt.Errorf("search query must contain a letter or digit: %w", ErrInvalidArgument)
+	}
+
+	if limit <= 0 {
+		limit = defaultSuggestions
+	}
+	if limit > maxSuggestions {
+		limit = maxSuggestions
+	}
+
+	return s.filmRepo.SuggestFilms(ctx, tsQuery, query, limit)
 }
 
 // ListFilmsByCategory returns films in a given category.
@@ -141,6 +249,7 @@ func (s *FilmService) ListFilmsByCategory(ctx context.Context, categoryID, pageS
 		return nil, 0, err
 	}
 
+	attachPosters(ctx, s.assetRepo, films, filmOf)
 	return films, total, nil
 }
 
@@ -163,6 +272,7 @@ func (s *FilmService) ListFilmsByActor(ctx context.Context, actorID, pageSize, p
 		return nil, 0, err
 	}
 
+	attachPosters(ctx, s.assetRepo, films, filmOf)
 	return films, total, nil
 }
 
@@ -205,30 +315,55 @@ func (s *FilmService) UpdateFilm(ctx context.Context, params repository.UpdateFi
 	return film, nil
 }
 
-// DeleteFilm deletes a film. Fails if inventory records reference it.
+// DeleteFilm archives a film, hiding it from customers and blocking new
+// inventory for it. Fails while copies of the film are out on rental;
+// archiving an archived film does nothing.
 func (s *FilmService) DeleteFilm(ctx context.Context, filmID int32) error {
 	if filmID <= 0 {
 		return fmt.Errorf("film_id must be positive: %w", ErrInvalidArgument)
 	}
 
 	// Verify the film exists.
-	_, err := s.filmRepo.GetFilm(ctx, filmID)
+	film, err := s.filmRepo.GetFilm(ctx, filmID)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
 			return fmt.Errorf("film %d: %w", filmID, ErrNotFound)
 		}
 		return err
 	}
+	if !film.ArchivedAt.IsZero() {
+		return nil
+	}
 
-	if err := s.filmRepo.DeleteFilm(ctx, filmID); err != nil {
-		if isForeignKeyViolation(err) {
-			return fmt.Errorf("film %d is referenced by inventory records: %w", filmID, ErrForeignKey)
-		}
+	out, err := s.filmRepo.ArchiveFilm(ctx, filmID)
+	if err != nil {
 		return err
 	}
+	if out > 0 {
+		return fmt.Errorf("film %d has %d copies out on rental: %w", filmID, out, ErrForeignKey)
+	}
 	return nil
 }
 
+// RestoreFilm makes an archived film available again.
+func (s *FilmService) RestoreFilm(ctx context.Context, filmID int32) (model.Film, error) {
+	if filmID <= 0 {
+		return model.Film{}, fmt.Errorf("film_id must be positive: %w", ErrInvalidArgument)
+	}
+
+	if _, err := s.filmRepo.GetFilm(ctx, filmID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.Film{}, fmt.Errorf("film %d: %w", filmID, ErrNotFound)
+		}
+		return model.Film{}, err
+	}
+
+	if err := s.filmRepo.RestoreFilm(ctx, filmID); err != nil {
+		return model.Film{}, err
+	}
+	return s.filmRepo.GetFilm(ctx, filmID)
+}
+
 // AddActorToFilm associates an actor with a film.
 func (s *FilmService) AddActorToFilm(ctx context.Context, actorID, filmID int32) error {
 	if actorID <= 0 {
@@ -245,12 +380,16 @@ func (s *FilmService) AddActorToFilm(ctx context.Context, actorID, filmID int32)
 		}
 		return err
 	}
-	if _, err := s.actorRepo.GetActor(ctx, actorID); err != nil {
+	actor, err := s.actorRepo.GetActor(ctx, actorID)
+	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
 			return fmt.Errorf("actor %d: %w", actorID, ErrNotFound)
 		}
 		return err
 	}
+	if !actor.ArchivedAt.IsZero() {
+		return fmt.Errorf("actor %d is archived: %w", actorID, ErrForeignKey)
+	}
 
 	if err := s.filmRepo.AddActorToFilm(ctx, actorID, filmID); err != nil {
 		if isUniqueViolation(err) {
@@ -363,3 +502,41 @@ func (s *FilmService) validateFilmParams(ctx context.Context, title string, lang
 
 	return nil
 }
+
+// validateFilmFilter validates film browsing filters.
+func validateFilmFilter(f repository.FilmFilter) error {
+	for _, rating := range f.Ratings {
+		if !validRatings[rating] {
+			return fmt.Errorf("invalid rating %q, must be one of G, PG, PG-13, R, NC-17: %w", rating, ErrInvalidArgument)
+		}
+	}
+	if f.CategoryID < 0 || f.ActorID < 0 || f.LanguageID < 0 {
+		return fmt.Errorf("category_id, actor_id and language_id must not be negative: %w", ErrInvalidArgument)
+	}
+	if f.MinReleaseYear < 0 || f.MaxReleaseYear < 0 || f.MinLength < 0 || f.MaxLength < 0 {
+		return fmt.Errorf("release year and length bounds must not be negative: %w", ErrInvalidArgument)
+	}
+	if f.MaxReleaseYear != 0 && f.MinReleaseYear > f.MaxReleaseYear {
+		return fmt.Errorf("min_release_year must not be after max_release_year: %w", ErrInvalidArgument)
+	}
+	if f.MaxLength != 0 && f.MinLength > f.MaxLength {
+		return fmt.Errorf("min_length must not be greater than max_length: %w", ErrInvalidArgument)
+	}
+
+	var minRate, maxRate float64
+	var err error
+	if f.MinRentalRate != "" {
+		if minRate, err = strconv.ParseFloat(f.MinRentalRate, 64); err != nil || minRate < 0 {
+			return fmt.Errorf("invalid min_rental_rate %q: %w", f.MinRentalRate, ErrInvalidArgument)
+		}
+	}
+	if f.MaxRentalRate != "" {
+		if maxRate, err = strconv.ParseFloat(f.MaxRentalRate, 64); err != nil || maxRate < 0 {
+			return fmt.Errorf("invalid max_rental_rate %q: %w", f.MaxRentalRate, ErrInvalidArgument)
+		}
+		if minRate > maxRate {
+			return fmt.Errorf("min_rental_rate must not be greater than max_rental_rate: %w", ErrInvalidArgument)
+		}
+	}
+	return nil
+}
@@ -4,12 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/enkaigaku/dvd-rental/internal/film/model"
 	"github.com/enkaigaku/dvd-rental/internal/film/repository"
 )
 
-// LanguageService contains business logic for language operations (read-only).
+// maxLanguageNameLength is the width of the language.name column.
+const maxLanguageNameLength = 20
+
+// LanguageService contains business logic for language operations.
 type LanguageService struct {
 	languageRepo repository.LanguageRepository
 }
@@ -49,3 +53,90 @@ func (s *LanguageService) ListLanguages(ctx context.Context) ([]model.Language,
 
 	return languages, total, nil
 }
+
+// CreateLanguage creates a new language. Names are unique, ignoring case.
+func (s *LanguageService) CreateLanguage(ctx context.Context, name string) (model.Language, error) {
+	name, err := validateLanguageName(name)
+	if err != nil {
+		return model.Language{}, err
+	}
+
+	language, err := s.languageRepo.CreateLanguage(ctx, name)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return model.Language{}, fmt.Errorf("language %q: %w", name, ErrAlreadyExists)
+		}
+		return model.Language{}, err
+	}
+	return language, nil
+}
+
+// UpdateLanguage renames a language.
+func (s *LanguageService) UpdateLanguage(ctx context.Context, languageID int32, name string) (model.Language, error) {
+	if languageID <= 0 {
+		return model.Language{}, fmt.Errorf("language_id must be positive: %w", ErrInvalidArgument)
+	}
+	name, err := validateLanguageName(name)
+	if err != nil {
+		return model.Language{}, err
+	}
+
+	language, err := s.languageRepo.UpdateLanguage(ctx, languageID, name)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.Language{}, fmt.Errorf("language %d: %w", languageID, ErrNotFound)
+		}
+		if isUniqueViolation(err) {
+			return model.Language{}, fmt.Errorf("language %q: %w", name, ErrAlreadyExists)
+		}
+		return model.Language{}, err
+	}
+	return language, nil
+}
+
+// DeleteLanguage deletes a language. While films are in it, or were
+// originally, the delete fails, unless reassignTo names another language
+// to switch them to first.
+func (s *LanguageService) DeleteLanguage(ctx context.Context, languageID, reassignTo int32) error {
+	if languageID <= 0 {
+		return fmt.Errorf("language_id must be positive: %w", ErrInvalidArgument)
+	}
+	if reassignTo < 0 || reassignTo == languageID {
+		return fmt.Errorf("reassign_to must be another language: %w", ErrInvalidArgument)
+	}
+
+	// Verify the language exists.
+	if _, err := s.languageRepo.GetLanguage(ctx, languageID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("language %d: %w", languageID, ErrNotFound)
+		}
+		return err
+	}
+	if reassignTo != 0 {
+		if _, err := s.languageRepo.GetLanguage(ctx, reassignTo); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return fmt.Errorf("reassign_to language %d not found: %w", reassignTo, ErrInvalidArgument)
+			}
+			return err
+		}
+	}
+
+	if err := s.languageRepo.DeleteLanguage(ctx, languageID, reassignTo); err != nil {
+		if isForeignKeyViolation(err) {
+			return fmt.Errorf("language %d is used by films; reassign them to another language first: %w", languageID, ErrForeignKey)
+		}
+		return err
+	}
+	return nil
+}
+
+func validateLanguageName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("name must not be empty: %w", ErrInvalidArgument)
+	}
+	if len([]rune(name)) > maxLanguageNameLength {
+		return "", fmt.Errorf("name must be at most %d characters: %w", maxLanguageNameLength, ErrInvalidArgument)
+	}
+	return name, nil
+}
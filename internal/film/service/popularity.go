@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/enkaigaku/dvd-rental/internal/film/model"
+)
+
+// defaultPopularWindow is the popular list's window in days when none is
+// given; 7, 30 and 90 days are available.
+const defaultPopularWindow = 30
+
+var validPopularWindows = map[int32]bool{7: true, 30: true, 90: true}
+
+// ListTrendingFilms returns the films rented in the last 7 days at a store,
+// or all stores for store 0, those rented most above their usual rate first.
+func (s *FilmService) ListTrendingFilms(ctx context.Context, storeID, pageSize, page int32) ([]model.PopularFilm, int64, error) {
+	if storeID < 0 {
+		return nil, 0, fmt.Errorf("store_id must not be negative: %w", ErrInvalidArgument)
+	}
+
+	pageSize, page = clampPagination(pageSize, page)
+	offset := (page - 1) * pageSize
+
+	films, err := s.popularityRepo.ListTrendingFilms(ctx, storeID, pageSize, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.popularityRepo.CountTrendingFilms(ctx, storeID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	attachPosters(ctx, s.assetRepo, films, filmOfPopularFilm)
+	return films, total, nil
+}
+
+// ListPopularFilms returns the films most rented over the last windowDays
+// at a store, or all stores for store 0.
+func (s *FilmService) ListPopularFilms(ctx context.Context, storeID, windowDays, pageSize, page int32) ([]model.PopularFilm, int64, error) {
+	if storeID < 0 {
+		return nil, 0, fmt.Errorf("store_id must not be negative: %w", ErrInvalidArgument)
+	}
+	if windowDays == 0 {
+		windowDays = defaultPopularWindow
+	}
+	if !validPopularWindows[windowDays] {
+		return nil, 0, fmt.Errorf("window_days must be 7, 30 or 90: %w", ErrInvalidArgument)
+	}
+
+	pageSize, page = clampPagination(pageSize, page)
+	offset := (page - 1) * pageSize
+
+	films, err := s.popularityRepo.ListPopularFilms(ctx, storeID, windowDays, pageSize, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.popularityRepo.CountPopularFilms(ctx, storeID, windowDays)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	attachPosters(ctx, s.assetRepo, films, filmOfPopularFilm)
+	return films, total, nil
+}
+
+// ListNewReleases returns films newest first, limited to the films a store
+// stocks unless storeID is 0.
+func (s *FilmService) ListNewReleases(ctx context.Context, storeID, pageSize, page int32) ([]model.Film, int64, error) {
+	if storeID < 0 {
+		return nil, 0, fmt.Errorf("store_id must not be negative: %w", ErrInvalidArgument)
+	}
+
+	pageSize, page = clampPagination(pageSize, page)
+	offset := (page - 1) * pageSize
+
+	films, err := s.popularityRepo.ListNewReleases(ctx, storeID, pageSize, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.popularityRepo.CountNewReleases(ctx, storeID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	attachPosters(ctx, s.assetRepo, films, filmOf)
+	return films, total, nil
+}
+
+// RefreshPopularity recomputes the rental counts behind the trending and
+// popular lists.
+func (s *FilmService) RefreshPopularity(ctx context.Context) error {
+	return s.popularityRepo.RefreshFilmPopularity(ctx)
+}
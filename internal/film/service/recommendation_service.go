@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/enkaigaku/dvd-rental/internal/film/model"
+	"github.com/enkaigaku/dvd-rental/internal/film/repository"
+)
+
+const (
+	defaultRecommendations = 10
+	maxRecommendations     = 50
+
+	// similarFilmsKept is how many similar films are stored per film.
+	similarFilmsKept = 50
+	// minCoRentals is how many customers must have rented both films of a
+	// pair for it to count as similar, so one customer's taste is not
+	// taken for a pattern.
+	minCoRentals = 2
+)
+
+// RecommendationService recommends films from the rental history: films
+// rented by the same customers are similar, and a customer is recommended
+// the films most similar to those they have rented.
+type RecommendationService struct {
+	repo      repository.RecommendationRepository
+	filmRepo  repository.FilmRepository
+	assetRepo repository.FilmAssetRepository
+}
+
+// NewRecommendationService creates a new RecommendationService.
+func NewRecommendationService(repo repository.RecommendationRepository, filmRepo repository.FilmRepository, assetRepo repository.FilmAssetRepository) *RecommendationService {
+	return &RecommendationService{repo: repo, filmRepo: filmRepo, assetRepo: assetRepo}
+}
+
+// ListSimilarFilms returns the films customers who rented a film also
+// rented, most similar first.
+func (s *RecommendationService) ListSimilarFilms(ctx context.Context, filmID, limit int32) ([]model.Recommendation, error) {
+	if filmID <= 0 {
+		return nil, fmt.Errorf("film_id must be positive: %w", ErrInvalidArgument)
+	}
+
+	if _, err := s.filmRepo.GetFilm(ctx, filmID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("film %d: %w", filmID, ErrNotFound)
+		}
+		return nil, err
+	}
+
+	recs, err := s.repo.ListSimilarFilms(ctx, filmID, clampRecommendations(limit))
+	if err != nil {
+		return nil, err
+	}
+	attachPosters(ctx, s.assetRepo, recs, filmOfRecommendation)
+	return recs, nil
+}
+
+// ListRecommendedFilms returns personalized recommendations for a customer
+// from their rental history, leaving out films they have already rented.
+// Customers without rentals get none.
+func (s *RecommendationService) ListRecommendedFilms(ctx context.Context, customerID, limit int32) ([]model.Recommendation, error) {
+	if customerID <= 0 {
+		return nil, fmt.Errorf("customer_id must be positive: %w", ErrInvalidArgument)
+	}
+	recs, err := s.repo.ListRecommendedFilms(ctx, customerID, clampRecommendations(limit))
+	if err != nil {
+		return nil, err
+	}
+	attachPosters(ctx, s.assetRepo, recs, filmOfRecommendation)
+	return recs, nil
+}
+
+// RefreshSimilarities recomputes film similarities from the rental history
+// and returns the number of similar film pairs stored.
+func (s *RecommendationService) RefreshSimilarities(ctx context.Context) (int64, error) {
+	return s.repo.RefreshFilmSimilarities(ctx, similarFilmsKept, minCoRentals)
+}
+
+func clampRecommendations(limit int32) int32 {
+	if limit <= 0 {
+		return defaultRecommendations
+	}
+	if limit > maxRecommendations {
+		return maxRecommendations
+	}
+	return limit
+}
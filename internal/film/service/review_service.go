@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/enkaigaku/dvd-rental/internal/film/model"
+	"github.com/enkaigaku/dvd-rental/internal/film/rentals"
+	"github.com/enkaigaku/dvd-rental/internal/film/repository"
+)
+
+// maxReviewLength is the longest review text accepted, in characters.
+const maxReviewLength = 4000
+
+// ReviewService contains business logic for film reviews. Only customers
+// who have rented a film may review it, and reviews with text are held for
+// moderation before they are shown or counted in the film's rating.
+type ReviewService struct {
+	reviewRepo repository.ReviewRepository
+	filmRepo   repository.FilmRepository
+	rentals    rentals.Checker
+}
+
+// NewReviewService creates a new ReviewService.
+func NewReviewService(
+	reviewRepo repository.ReviewRepository,
+	filmRepo repository.FilmRepository,
+	rentals rentals.Checker,
+) *ReviewService {
+	return &ReviewService{
+		reviewRepo: reviewRepo,
+		filmRepo:   filmRepo,
+		rentals:    rentals,
+	}
+}
+
+// GetReview returns a single review by ID.
+func (s *ReviewService) GetReview(ctx context.Context, reviewID int32) (model.Review, error) {
+	if reviewID <= 0 {
+		return model.Review{}, fmt.Errorf("review_id must be positive: %w", ErrInvalidArgument)
+	}
+
+	review, err := s.reviewRepo.GetReview(ctx, reviewID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.Review{}, fmt.Errorf("review %d: %w", reviewID, ErrNotFound)
+		}
+		return model.Review{}, err
+	}
+	return review, nil
+}
+
+// ListFilmReviews returns the approved reviews of a film, newest first.
+func (s *ReviewService) ListFilmReviews(ctx context.Context, filmID, pageSize, page int32) ([]model.Review, int64, error) {
+	if filmID <= 0 {
+		return nil, 0, fmt.Errorf("film_id must be positive: %w", ErrInvalidArgument)
+	}
+
+	pageSize, page = clampPagination(pageSize, page)
+	offset := (page - 1) * pageSize
+
+	reviews, err := s.reviewRepo.ListFilmReviews(ctx, filmID, pageSize, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.reviewRepo.CountFilmReviews(ctx, filmID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return reviews, total, nil
+}
+
+// ListReviewsByCustomer returns a customer's reviews in any status, newest first.
+func (s *ReviewService) ListReviewsByCustomer(ctx context.Context, customerID, pageSize, page int32) ([]model.Review, int64, error) {
+	if customerID <= 0 {
+		return nil, 0, fmt.Errorf("customer_id must be positive: %w", ErrInvalidArgument)
+	}
+
+	pageSize, page = clampPagination(pageSize, page)
+	offset := (page - 1) * pageSize
+
+	reviews, err := s.reviewRepo.ListReviewsByCustomer(ctx, customerID, pageSize, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.reviewRepo.CountReviewsByCustomer(ctx, customerID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return reviews, total, nil
+}
+
+// ListReviewsByStatus returns reviews in a status, oldest first. With the
+// pending status this is the moderation queue.
+func (s *ReviewService) ListReviewsByStatus(ctx context.Context, status string, pageSize, page int32) ([]model.Review, int64, error) {
+	if status == "" {
+		status = model.ReviewStatusPending
+	}
+	if err := validateReviewStatus(status); err != nil {
+		return nil, 0, err
+	}
+
+	pageSize, page = clampPagination(pageSize, page)
+	offset := (page - 1) * pageSize
+
+	reviews, err := s.reviewRepo.ListReviewsByStatus(ctx, status, pageSize, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.reviewRepo.CountReviewsByStatus(ctx, status)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return reviews, total, nil
+}
+
+// SubmitReview creates or replaces a customer's review of a film they have
+// rented. A review with text goes back to the moderation queue; a rating
+// alone is approved immediately.
+func (s *ReviewService) SubmitReview(ctx context.Context, filmID, customerID, rating int32, body string) (model.Review, error) {
+	if filmID <= 0 {
+		return model.Review{}, fmt.Errorf("film_id must be positive: %w", ErrInvalidArgument)
+	}
+	if customerID <= 0 {
+		return model.Review{}, fmt.Errorf("customer_id must be positive: %w", ErrInvalidArgument)
+	}
+	if rating < 1 || rating > 5 {
+		return model.Review{}, fmt.Errorf("rating must be between 1 and 5: %w", ErrInvalidArgument)
+	}
+	body = strings.TrimSpace(body)
+	if utf8.RuneCountInString(body) > maxReviewLength {
+		return model.Review{}, fmt.Errorf("review must be at most %d characters: %w", maxReviewLength, ErrInvalidArgument)
+	}
+
+	if _, err := s.filmRepo.GetFilm(ctx, filmID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.Review{}, fmt.Errorf("film %d: %w", filmID, ErrNotFound)
+		}
+		return model.Review{}, err
+	}
+
+	rented, err := s.rentals.HasRented(ctx, customerID, filmID)
+	if err != nil {
+		return model.Review{}, err
+	}
+	if !rented {
+		return model.Review{}, fmt.Errorf("customer %d has not rented film %d: %w", customerID, filmID, ErrPermissionDenied)
+	}
+
+	status := model.ReviewStatusApproved
+	if body != "" {
+		status = model.ReviewStatusPending
+	}
+
+	review, err := s.reviewRepo.UpsertReview(ctx, repository.UpsertReviewParams{
+		FilmID:     filmID,
+		CustomerID: customerID,
+		Rating:     int16(rating),
+		Body:       body,
+		Status:     status,
+	})
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return model.Review{}, fmt.Errorf("customer %d does not exist: %w", customerID, ErrInvalidArgument)
+		}
+		return model.Review{}, err
+	}
+	return review, nil
+}
+
+// ModerateReview approves or rejects a review. note is shown to the
+// customer, e.g. the reason for a rejection.
+func (s *ReviewService) ModerateReview(ctx context.Context, reviewID int32, status, note string) (model.Review, error) {
+	if reviewID <= 0 {
+		return model.Review{}, fmt.Errorf("review_id must be positive: %w", ErrInvalidArgument)
+	}
+	if status != model.ReviewStatusApproved && status != model.ReviewStatusRejected {
+		return model.Review{}, fmt.Errorf("status must be %s or %s: %w",
+			model.ReviewStatusApproved, model.ReviewStatusRejected, ErrInvalidArgument)
+	}
+
+	review, err := s.reviewRepo.UpdateReviewStatus(ctx, reviewID, status, strings.TrimSpace(note))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.Review{}, fmt.Errorf("review %d: %w", reviewID, ErrNotFound)
+		}
+		return model.Review{}, err
+	}
+	return review, nil
+}
+
+// DeleteReview deletes a review.
+func (s *ReviewService) DeleteReview(ctx context.Context, reviewID int32) error {
+	if reviewID <= 0 {
+		return fmt.Errorf("review_id must be positive: %w", ErrInvalidArgument)
+	}
+
+	if err := s.reviewRepo.DeleteReview(ctx, reviewID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("review %d: %w", reviewID, ErrNotFound)
+		}
+		return err
+	}
+	return nil
+}
+
+func validateReviewStatus(status string) error {
+	switch status {
+	case model.ReviewStatusPending, model.ReviewStatusApproved, model.ReviewStatusRejected:
+		return nil
+	}
+	return fmt.Errorf("status must be one of %s, %s or %s: %w",
+		model.ReviewStatusPending, model.ReviewStatusApproved, model.ReviewStatusRejected, ErrInvalidArgument)
+}
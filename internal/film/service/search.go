@@ -0,0 +1,31 @@
+package service
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	defaultSuggestions = 10
+	maxSuggestions     = 20
+)
+
+// prefixTSQuery turns user input into a tsquery matching every word as a
+// prefix, e.g. "acad din" becomes "acad:* & din:*". Punctuation is dropped,
+// so the result is always valid tsquery syntax. It returns "" when the input
+// has no words.
+func prefixTSQuery(query string) string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, w := range words {
+		words[i] = w + ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+// likePrefix returns a LIKE pattern matching strings that start with s.
+func likePrefix(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return r.Replace(s) + "%"
+}
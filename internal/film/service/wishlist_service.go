@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/enkaigaku/dvd-rental/internal/film/model"
+	"github.com/enkaigaku/dvd-rental/internal/film/repository"
+)
+
+// WishlistService contains business logic for customers' wishlists of
+// films to rent later.
+type WishlistService struct {
+	wishlistRepo repository.WishlistRepository
+	filmRepo     repository.FilmRepository
+}
+
+// NewWishlistService creates a new WishlistService.
+func NewWishlistService(wishlistRepo repository.WishlistRepository, filmRepo repository.FilmRepository) *WishlistService {
+	return &WishlistService{
+		wishlistRepo: wishlistRepo,
+		filmRepo:     filmRepo,
+	}
+}
+
+// ListWishlist returns a customer's wishlist, most recently added first,
+// with each film's availability at the customer's home store.
+func (s *WishlistService) ListWishlist(ctx context.Context, customerID, pageSize, page int32) ([]model.WishlistItem, int64, error) {
+	if customerID <= 0 {
+		return nil, 0, fmt.Errorf("customer_id must be positive: %w", ErrInvalidArgument)
+	}
+
+	pageSize, page = clampPagination(pageSize, page)
+	offset := (page - 1) * pageSize
+
+	items, err := s.wishlistRepo.ListWishlist(ctx, customerID, pageSize, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.wishlistRepo.CountWishlist(ctx, customerID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return items, total, nil
+}
+
+// AddToWishlist saves a film to a customer's wishlist. Adding a film that
+// is already on it does nothing. Archived films cannot be added.
+func (s *WishlistService) AddToWishlist(ctx context.Context, customerID, filmID int32) error {
+	if customerID <= 0 {
+		return fmt.Errorf("customer_id must be positive: %w", ErrInvalidArgument)
+	}
+	if filmID <= 0 {
+		return fmt.Errorf("film_id must be positive: %w", ErrInvalidArgument)
+	}
+
+	film, err := s.filmRepo.GetFilm(ctx, filmID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("film %d: %w", filmID, ErrNotFound)
+		}
+		return err
+	}
+	if !film.ArchivedAt.IsZero() {
+		return fmt.Errorf("film %d: %w", filmID, ErrNotFound)
+	}
+
+	if err := s.wishlistRepo.AddWishlistItem(ctx, customerID, filmID); err != nil {
+		if isForeignKeyViolation(err) {
+			return fmt.Errorf("customer %d does not exist: %w", customerID, ErrInvalidArgument)
+		}
+		return err
+	}
+	return nil
+}
+
+// RemoveFromWishlist removes a film from a customer's wishlist.
+func (s *WishlistService) RemoveFromWishlist(ctx context.Context, customerID, filmID int32) error {
+	if customerID <= 0 {
+		return fmt.Errorf("customer_id must be positive: %w", ErrInvalidArgument)
+	}
+	if filmID <= 0 {
+		return fmt.Errorf("film_id must be positive: %w", ErrInvalidArgument)
+	}
+
+	if err := s.wishlistRepo.RemoveWishlistItem(ctx, customerID, filmID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("film %d is not on the wishlist of customer %d: %w", filmID, customerID, ErrNotFound)
+		}
+		return err
+	}
+	return nil
+}
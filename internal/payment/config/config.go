@@ -2,6 +2,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/kelseyhightower/envconfig"
 )
 
@@ -10,6 +12,10 @@ type Config struct {
 	DatabaseURL string `envconfig:"DATABASE_URL" required:"true"`
 	GRPCPort    string `envconfig:"GRPC_PORT" default:"50055"`
 	LogLevel    string `envconfig:"LOG_LEVEL" default:"info"`
+
+	// BillingInterval is how often due subscriptions are billed; 0 disables
+	// the recurring billing job.
+	BillingInterval time.Duration `envconfig:"BILLING_INTERVAL" default:"1h"`
 }
 
 // Load reads configuration from environment variables.
@@ -0,0 +1,49 @@
+// Package gateway abstracts the external payment processor used for charges
+// that are not settled at the store counter, such as subscription fees.
+package gateway
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+)
+
+// ErrDeclined is returned when the processor refuses a charge. Any other
+// error means the outcome is unknown and the charge may be retried with the
+// same idempotency key.
+var ErrDeclined = errors.New("payment declined")
+
+// ChargeRequest describes a charge against a customer's payment method on file.
+type ChargeRequest struct {
+	CustomerID  int32
+	Amount      string // e.g. "14.99"
+	Description string
+	// IdempotencyKey makes retries of the same charge safe: the processor
+	// returns the original result instead of charging twice.
+	IdempotencyKey string
+}
+
+// Charge is a successful charge.
+type Charge struct {
+	Reference string // processor transaction reference
+}
+
+// Gateway charges customers through an external payment processor.
+type Gateway interface {
+	Charge(ctx context.Context, req ChargeRequest) (Charge, error)
+}
+
+// offlineGateway approves every charge without contacting a processor.
+// Charges are settled outside the system, e.g. at the store counter.
+type offlineGateway struct{}
+
+// NewOfflineGateway creates a Gateway that approves every charge.
+func NewOfflineGateway() Gateway {
+	return offlineGateway{}
+}
+
+func (offlineGateway) Charge(_ context.Context, req ChargeRequest) (Charge, error) {
+	sum := sha256.Sum256([]byte(req.IdempotencyKey))
+	return Charge{Reference: "offline-" + hex.EncodeToString(sum[:8])}, nil
+}
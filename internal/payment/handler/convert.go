@@ -22,6 +22,10 @@ func toGRPCError(err error) error {
 		return status.Error(codes.AlreadyExists, err.Error())
 	case errors.Is(err, service.ErrForeignKey):
 		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, service.ErrInsufficientFunds):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, service.ErrPaymentDeclined):
+		return status.Error(codes.FailedPrecondition, err.Error())
 	default:
 		return status.Error(codes.Internal, "internal error")
 	}
@@ -35,6 +39,9 @@ func paymentToProto(p model.Payment) *paymentv1.Payment {
 		RentalId:    p.RentalID,
 		Amount:      p.Amount,
 		PaymentDate: timestamppb.New(p.PaymentDate),
+		ChargeType:  p.ChargeType,
+		NetAmount:   p.NetAmount,
+		TaxAmount:   p.TaxAmount,
 	}
 }
 
@@ -50,3 +57,221 @@ func paymentDetailToProto(d model.PaymentDetail) *paymentv1.PaymentDetail {
 	}
 	return pb
 }
+
+func receiptToProto(r model.Receipt) *paymentv1.Receipt {
+	return &paymentv1.Receipt{
+		Detail:       paymentDetailToProto(r.PaymentDetail),
+		StoreId:      r.StoreID,
+		StoreAddress: r.StoreAddress,
+		StoreCity:    r.StoreCity,
+		StoreCountry: r.StoreCountry,
+		FilmTitle:    r.FilmTitle,
+		Taxes:        taxLinesToProto(r.Taxes),
+	}
+}
+
+func promotionToProto(p model.Promotion) *paymentv1.Promotion {
+	pb := &paymentv1.Promotion{
+		PromotionId:        p.PromotionID,
+		Name:               p.Name,
+		Code:               p.Code,
+		Kind:               p.Kind,
+		Value:              p.Value,
+		BuyQuantity:        p.BuyQuantity,
+		PayQuantity:        p.PayQuantity,
+		CategoryId:         p.CategoryID,
+		StoreId:            p.StoreID,
+		MaxUsesPerCustomer: p.MaxUsesPerCustomer,
+		Active:             p.Active,
+		LastUpdate:         timestamppb.New(p.LastUpdate),
+	}
+	// Zero time means unbounded — leave the bound nil in proto.
+	if !p.StartsAt.IsZero() {
+		pb.StartsAt = timestamppb.New(p.StartsAt)
+	}
+	if !p.EndsAt.IsZero() {
+		pb.EndsAt = timestamppb.New(p.EndsAt)
+	}
+	return pb
+}
+
+func priceQuoteToProto(q model.PriceQuote) *paymentv1.PriceQuote {
+	rules := make([]*paymentv1.AppliedRule, len(q.AppliedRules))
+	for i, r := range q.AppliedRules {
+		rules[i] = &paymentv1.AppliedRule{
+			PromotionId: r.PromotionID,
+			Name:        r.Name,
+			Description: r.Description,
+			Discount:    r.Discount,
+		}
+	}
+	return &paymentv1.PriceQuote{
+		FilmId:         q.FilmID,
+		StoreId:        q.StoreID,
+		CustomerId:     q.CustomerID,
+		BaseAmount:     q.BaseAmount,
+		Discount:       q.Discount,
+		Amount:         q.Amount,
+		AppliedRules:   rules,
+		SubscriptionId: q.SubscriptionID,
+		TaxAmount:      q.TaxAmount,
+		TotalAmount:    q.TotalAmount,
+	}
+}
+
+func storedValueAccountToProto(a model.StoredValueAccount) *paymentv1.StoredValueAccount {
+	return &paymentv1.StoredValueAccount{
+		AccountId:  a.AccountID,
+		Kind:       a.Kind,
+		Code:       a.Code,
+		CustomerId: a.CustomerID,
+		Balance:    a.Balance,
+		Active:     a.Active,
+		CreateDate: timestamppb.New(a.CreateDate),
+		LastUpdate: timestamppb.New(a.LastUpdate),
+	}
+}
+
+func storedValueEntryToProto(e model.StoredValueEntry) *paymentv1.StoredValueEntry {
+	return &paymentv1.StoredValueEntry{
+		EntryId:       e.EntryID,
+		TransactionId: e.TransactionID,
+		AccountId:     e.AccountID,
+		Kind:          e.Kind,
+		Amount:        e.Amount,
+		BalanceAfter:  e.BalanceAfter,
+		PaymentId:     e.PaymentID,
+		Note:          e.Note,
+		CreatedAt:     timestamppb.New(e.CreatedAt),
+	}
+}
+
+func loyaltyAccountToProto(a model.LoyaltyAccount) *paymentv1.LoyaltyAccount {
+	pb := &paymentv1.LoyaltyAccount{
+		CustomerId:       a.CustomerID,
+		Tier:             a.Tier,
+		PointsBalance:    a.PointsBalance,
+		LifetimePoints:   a.LifetimePoints,
+		PointsMultiplier: a.PointsMultiplier,
+		FreeRentalPoints: service.FreeRentalPoints,
+	}
+	if !a.TierCalculatedAt.IsZero() {
+		pb.TierCalculatedAt = timestamppb.New(a.TierCalculatedAt)
+	}
+	return pb
+}
+
+func loyaltyTransactionToProto(t model.LoyaltyTransaction) *paymentv1.LoyaltyTransaction {
+	return &paymentv1.LoyaltyTransaction{
+		TransactionId: t.TransactionID,
+		CustomerId:    t.CustomerID,
+		Kind:          t.Kind,
+		Points:        t.Points,
+		BalanceAfter:  t.BalanceAfter,
+		PaymentId:     t.PaymentID,
+		RentalId:      t.RentalID,
+		Note:          t.Note,
+		CreatedAt:     timestamppb.New(t.CreatedAt),
+	}
+}
+
+func loyaltyTierToProto(t model.LoyaltyTier) *paymentv1.LoyaltyTier {
+	return &paymentv1.LoyaltyTier{
+		Tier:                t.Tier,
+		Rank:                t.Rank,
+		MinMonthlyPurchases: t.MinMonthlyPurchases,
+		MinDollarAmount:     t.MinDollarAmount,
+		PointsMultiplier:    t.PointsMultiplier,
+	}
+}
+
+func rewardsCustomerToProto(c model.RewardsCustomer) *paymentv1.RewardsCustomer {
+	return &paymentv1.RewardsCustomer{
+		CustomerId: c.CustomerID,
+		StoreId:    c.StoreID,
+		FirstName:  c.FirstName,
+		LastName:   c.LastName,
+		Email:      c.Email,
+	}
+}
+
+func subscriptionPlanToProto(p model.SubscriptionPlan) *paymentv1.SubscriptionPlan {
+	return &paymentv1.SubscriptionPlan{
+		PlanId:               p.PlanID,
+		Name:                 p.Name,
+		Description:          p.Description,
+		Price:                p.Price,
+		IntervalMonths:       p.IntervalMonths,
+		MaxConcurrentRentals: p.MaxConcurrentRentals,
+		MaxRentalsPerPeriod:  p.MaxRentalsPerPeriod,
+		Active:               p.Active,
+		LastUpdate:           timestamppb.New(p.LastUpdate),
+	}
+}
+
+func subscriptionToProto(s model.Subscription) *paymentv1.Subscription {
+	pb := &paymentv1.Subscription{
+		SubscriptionId:     s.SubscriptionID,
+		CustomerId:         s.CustomerID,
+		PlanId:             s.PlanID,
+		PlanName:           s.PlanName,
+		Status:             s.Status,
+		CurrentPeriodStart: timestamppb.New(s.CurrentPeriodStart),
+		CurrentPeriodEnd:   timestamppb.New(s.CurrentPeriodEnd),
+		CancelAtPeriodEnd:  s.CancelAtPeriodEnd,
+		PauseAtPeriodEnd:   s.PauseAtPeriodEnd,
+		FailedAttempts:     s.FailedAttempts,
+		CreateDate:         timestamppb.New(s.CreateDate),
+		LastUpdate:         timestamppb.New(s.LastUpdate),
+	}
+	if !s.NextBillingAt.IsZero() {
+		pb.NextBillingAt = timestamppb.New(s.NextBillingAt)
+	}
+	if !s.CanceledAt.IsZero() {
+		pb.CanceledAt = timestamppb.New(s.CanceledAt)
+	}
+	return pb
+}
+
+func subscriptionPaymentToProto(p model.SubscriptionPayment) *paymentv1.SubscriptionPayment {
+	return &paymentv1.SubscriptionPayment{
+		SubscriptionPaymentId: p.SubscriptionPaymentID,
+		SubscriptionId:        p.SubscriptionID,
+		CustomerId:            p.CustomerID,
+		Amount:                p.Amount,
+		PeriodStart:           timestamppb.New(p.PeriodStart),
+		PeriodEnd:             timestamppb.New(p.PeriodEnd),
+		Status:                p.Status,
+		GatewayReference:      p.GatewayReference,
+		FailureReason:         p.FailureReason,
+		CreatedAt:             timestamppb.New(p.CreatedAt),
+	}
+}
+
+func taxRateToProto(t model.TaxRate) *paymentv1.TaxRate {
+	return &paymentv1.TaxRate{
+		TaxRateId:  t.TaxRateID,
+		Name:       t.Name,
+		CountryId:  t.CountryID,
+		Country:    t.Country,
+		CityId:     t.CityID,
+		City:       t.City,
+		ChargeType: t.ChargeType,
+		Rate:       t.Rate,
+		Active:     t.Active,
+		LastUpdate: timestamppb.New(t.LastUpdate),
+	}
+}
+
+func taxLinesToProto(lines []model.TaxLine) []*paymentv1.TaxLine {
+	protos := make([]*paymentv1.TaxLine, len(lines))
+	for i, l := range lines {
+		protos[i] = &paymentv1.TaxLine{
+			TaxRateId: l.TaxRateID,
+			Name:      l.Name,
+			Rate:      l.Rate,
+			Amount:    l.Amount,
+		}
+	}
+	return protos
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"context"
+
+	paymentv1 "github.com/enkaigaku/dvd-rental/gen/proto/payment/v1"
+	"github.com/enkaigaku/dvd-rental/internal/payment/service"
+)
+
+// LoyaltyHandler implements the LoyaltyService gRPC server.
+type LoyaltyHandler struct {
+	paymentv1.UnimplementedLoyaltyServiceServer
+	svc *service.LoyaltyService
+}
+
+// NewLoyaltyHandler creates a new LoyaltyHandler.
+func NewLoyaltyHandler(svc *service.LoyaltyService) *LoyaltyHandler {
+	return &LoyaltyHandler{svc: svc}
+}
+
+func (h *LoyaltyHandler) GetLoyaltyAccount(ctx context.Context, req *paymentv1.GetLoyaltyAccountRequest) (*paymentv1.LoyaltyAccount, error) {
+	account, err := h.svc.GetAccount(ctx, req.GetCustomerId())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return loyaltyAccountToProto(account), nil
+}
+
+func (h *LoyaltyHandler) ListLoyaltyTransactions(ctx context.Context, req *paymentv1.ListLoyaltyTransactionsRequest) (*paymentv1.ListLoyaltyTransactionsResponse, error) {
+	transactions, total, err := h.svc.ListTransactions(ctx, req.GetCustomerId(), req.GetPageSize(), req.GetPage())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+
+	protos := make([]*paymentv1.LoyaltyTransaction, len(transactions))
+	for i, t := range transactions {
+		protos[i] = loyaltyTransactionToProto(t)
+	}
+	return &paymentv1.ListLoyaltyTransactionsResponse{
+		Transactions: protos,
+		TotalCount:   int32(total),
+	}, nil
+}
+
+func (h *LoyaltyHandler) ListLoyaltyTiers(ctx context.Context, req *paymentv1.ListLoyaltyTiersRequest) (*paymentv1.ListLoyaltyTiersResponse, error) {
+	tiers, err := h.svc.ListTiers(ctx)
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+
+	protos := make([]*paymentv1.LoyaltyTier, len(tiers))
+	for i, t := range tiers {
+		protos[i] = loyaltyTierToProto(t)
+	}
+	return &paymentv1.ListLoyaltyTiersResponse{Tiers: protos}, nil
+}
+
+func (h *LoyaltyHandler) RecalculateLoyaltyTiers(ctx context.Context, req *paymentv1.RecalculateLoyaltyTiersRequest) (*paymentv1.RecalculateLoyaltyTiersResponse, error) {
+	counts, err := h.svc.RecalculateTiers(ctx)
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+
+	protos := make([]*paymentv1.LoyaltyTierCount, len(counts))
+	for i, c := range counts {
+		protos[i] = &paymentv1.LoyaltyTierCount{Tier: c.Tier, Customers: c.Customers}
+	}
+	return &paymentv1.RecalculateLoyaltyTiersResponse{Tiers: protos}, nil
+}
+
+func (h *LoyaltyHandler) RunRewardsReport(ctx context.Context, req *paymentv1.RunRewardsReportRequest) (*paymentv1.RunRewardsReportResponse, error) {
+	customers, err := h.svc.RunRewardsReport(ctx, req.GetMinMonthlyPurchases(), req.GetMinDollarAmount())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+
+	protos := make([]*paymentv1.RewardsCustomer, len(customers))
+	for i, c := range customers {
+		protos[i] = rewardsCustomerToProto(c)
+	}
+	return &paymentv1.RunRewardsReportResponse{Customers: protos}, nil
+}
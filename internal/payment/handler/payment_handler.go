@@ -88,6 +88,13 @@ func (h *PaymentHandler) CreatePayment(ctx context.Context, req *paymentv1.Creat
 		StaffID:    req.GetStaffId(),
 		RentalID:   req.GetRentalId(),
 		Amount:     req.GetAmount(),
+		ChargeType: req.GetChargeType(),
+	}, service.PaymentOptions{
+		PromoCode:     req.GetPromoCode(),
+		GiftCardCode:  req.GetGiftCardCode(),
+		RedeemPoints:  req.GetRedeemPoints(),
+		SelfService:   req.GetSelfService(),
+		PriceOverride: req.GetPriceOverride(),
 	})
 	if err != nil {
 		return nil, toGRPCError(err)
@@ -102,6 +109,41 @@ func (h *PaymentHandler) DeletePayment(ctx context.Context, req *paymentv1.Delet
 	return &emptypb.Empty{}, nil
 }
 
+func (h *PaymentHandler) GetPaymentReceipt(ctx context.Context, req *paymentv1.GetPaymentReceiptRequest) (*paymentv1.Receipt, error) {
+	receipt, err := h.svc.GetPaymentReceipt(ctx, req.GetPaymentId())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return receiptToProto(receipt), nil
+}
+
+func (h *PaymentHandler) GetRevenueReport(ctx context.Context, req *paymentv1.GetRevenueReportRequest) (*paymentv1.GetRevenueReportResponse, error) {
+	if req.GetStartDate() == nil {
+		return nil, status.Error(codes.InvalidArgument, "start_date is required")
+	}
+	if req.GetEndDate() == nil {
+		return nil, status.Error(codes.InvalidArgument, "end_date is required")
+	}
+
+	rows, err := h.svc.GetRevenueReport(ctx, req.GetStartDate().AsTime(), req.GetEndDate().AsTime())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+
+	protos := make([]*paymentv1.RevenueRow, len(rows))
+	for i, r := range rows {
+		protos[i] = &paymentv1.RevenueRow{
+			StoreId:      r.StoreID,
+			ChargeType:   r.ChargeType,
+			PaymentCount: r.PaymentCount,
+			NetAmount:    r.NetAmount,
+			TaxAmount:    r.TaxAmount,
+			GrossAmount:  r.GrossAmount,
+		}
+	}
+	return &paymentv1.GetRevenueReportResponse{Rows: protos}, nil
+}
+
 func toPaymentListResponse(payments []model.Payment, total int64) *paymentv1.ListPaymentsResponse {
 	protos := make([]*paymentv1.Payment, len(payments))
 	for i, p := range payments {
@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"context"
+
+	paymentv1 "github.com/enkaigaku/dvd-rental/gen/proto/payment/v1"
+	"github.com/enkaigaku/dvd-rental/internal/payment/service"
+)
+
+// PricingHandler implements the PricingService gRPC server.
+type PricingHandler struct {
+	paymentv1.UnimplementedPricingServiceServer
+	svc *service.PricingService
+}
+
+// NewPricingHandler creates a new PricingHandler.
+func NewPricingHandler(svc *service.PricingService) *PricingHandler {
+	return &PricingHandler{svc: svc}
+}
+
+func (h *PricingHandler) QuoteRentalPrice(ctx context.Context, req *paymentv1.QuoteRentalPriceRequest) (*paymentv1.PriceQuote, error) {
+	quote, err := h.svc.QuoteRentalPrice(ctx, req.GetFilmId(), req.GetStoreId(), req.GetCustomerId(), req.GetPromoCode())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return priceQuoteToProto(quote), nil
+}
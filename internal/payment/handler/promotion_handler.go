@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	paymentv1 "github.com/enkaigaku/dvd-rental/gen/proto/payment/v1"
+	"github.com/enkaigaku/dvd-rental/internal/payment/repository"
+	"github.com/enkaigaku/dvd-rental/internal/payment/service"
+)
+
+// PromotionHandler implements the PromotionService gRPC server.
+type PromotionHandler struct {
+	paymentv1.UnimplementedPromotionServiceServer
+	svc *service.PromotionService
+}
+
+// NewPromotionHandler creates a new PromotionHandler.
+func NewPromotionHandler(svc *service.PromotionService) *PromotionHandler {
+	return &PromotionHandler{svc: svc}
+}
+
+func (h *PromotionHandler) GetPromotion(ctx context.Context, req *paymentv1.GetPromotionRequest) (*paymentv1.Promotion, error) {
+	promotion, err := h.svc.GetPromotion(ctx, req.GetPromotionId())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return promotionToProto(promotion), nil
+}
+
+func (h *PromotionHandler) ListPromotions(ctx context.Context, req *paymentv1.ListPromotionsRequest) (*paymentv1.ListPromotionsResponse, error) {
+	promotions, total, err := h.svc.ListPromotions(ctx, req.GetPageSize(), req.GetPage(), req.GetActiveOnly())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+
+	protos := make([]*paymentv1.Promotion, len(promotions))
+	for i, p := range promotions {
+		protos[i] = promotionToProto(p)
+	}
+	return &paymentv1.ListPromotionsResponse{
+		Promotions: protos,
+		TotalCount: int32(total),
+	}, nil
+}
+
+func (h *PromotionHandler) CreatePromotion(ctx context.Context, req *paymentv1.CreatePromotionRequest) (*paymentv1.Promotion, error) {
+	promotion, err := h.svc.CreatePromotion(ctx, repository.PromotionParams{
+		Name:               req.GetName(),
+		Code:               req.GetCode(),
+		Kind:               req.GetKind(),
+		Value:              req.GetValue(),
+		BuyQuantity:        req.GetBuyQuantity(),
+		PayQuantity:        req.GetPayQuantity(),
+		CategoryID:         req.GetCategoryId(),
+		StoreID:            req.GetStoreId(),
+		StartsAt:           optionalTime(req.GetStartsAt()),
+		EndsAt:             optionalTime(req.GetEndsAt()),
+		MaxUsesPerCustomer: req.GetMaxUsesPerCustomer(),
+		Active:             req.GetActive(),
+	})
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return promotionToProto(promotion), nil
+}
+
+func (h *PromotionHandler) UpdatePromotion(ctx context.Context, req *paymentv1.UpdatePromotionRequest) (*paymentv1.Promotion, error) {
+	promotion, err := h.svc.UpdatePromotion(ctx, req.GetPromotionId(), repository.PromotionParams{
+		Name:               req.GetName(),
+		Code:               req.GetCode(),
+		Kind:               req.GetKind(),
+		Value:              req.GetValue(),
+		BuyQuantity:        req.GetBuyQuantity(),
+		PayQuantity:        req.GetPayQuantity(),
+		CategoryID:         req.GetCategoryId(),
+		StoreID:            req.GetStoreId(),
+		StartsAt:           optionalTime(req.GetStartsAt()),
+		EndsAt:             optionalTime(req.GetEndsAt()),
+		MaxUsesPerCustomer: req.GetMaxUsesPerCustomer(),
+		Active:             req.GetActive(),
+	})
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return promotionToProto(promotion), nil
+}
+
+func (h *PromotionHandler) DeletePromotion(ctx context.Context, req *paymentv1.DeletePromotionRequest) (*emptypb.Empty, error) {
+	if err := h.svc.DeletePromotion(ctx, req.GetPromotionId()); err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// optionalTime converts a nullable timestamp to time.Time (zero when nil).
+func optionalTime(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
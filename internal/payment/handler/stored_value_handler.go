@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"context"
+
+	paymentv1 "github.com/enkaigaku/dvd-rental/gen/proto/payment/v1"
+	"github.com/enkaigaku/dvd-rental/internal/payment/service"
+)
+
+// StoredValueHandler implements the StoredValueService gRPC server.
+type StoredValueHandler struct {
+	paymentv1.UnimplementedStoredValueServiceServer
+	svc *service.StoredValueService
+}
+
+// NewStoredValueHandler creates a new StoredValueHandler.
+func NewStoredValueHandler(svc *service.StoredValueService) *StoredValueHandler {
+	return &StoredValueHandler{svc: svc}
+}
+
+func (h *StoredValueHandler) IssueGiftCard(ctx context.Context, req *paymentv1.IssueGiftCardRequest) (*paymentv1.StoredValueAccount, error) {
+	account, err := h.svc.IssueGiftCard(ctx, req.GetAmount(), req.GetCustomerId(), req.GetStaffId(), req.GetNote())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return storedValueAccountToProto(account), nil
+}
+
+func (h *StoredValueHandler) IssueStoreCredit(ctx context.Context, req *paymentv1.IssueStoreCreditRequest) (*paymentv1.StoredValueAccount, error) {
+	account, err := h.svc.IssueStoreCredit(ctx, req.GetCustomerId(), req.GetAmount(), req.GetStaffId(), req.GetNote())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return storedValueAccountToProto(account), nil
+}
+
+func (h *StoredValueHandler) GetStoredValueAccount(ctx context.Context, req *paymentv1.GetStoredValueAccountRequest) (*paymentv1.StoredValueAccount, error) {
+	account, err := h.svc.GetAccount(ctx, req.GetCode())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return storedValueAccountToProto(account), nil
+}
+
+func (h *StoredValueHandler) ListStoredValueAccountsByCustomer(ctx context.Context, req *paymentv1.ListStoredValueAccountsByCustomerRequest) (*paymentv1.ListStoredValueAccountsResponse, error) {
+	accounts, err := h.svc.ListAccountsByCustomer(ctx, req.GetCustomerId())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+
+	protos := make([]*paymentv1.StoredValueAccount, len(accounts))
+	for i, a := range accounts {
+		protos[i] = storedValueAccountToProto(a)
+	}
+	return &paymentv1.ListStoredValueAccountsResponse{Accounts: protos}, nil
+}
+
+func (h *StoredValueHandler) ListStoredValueEntries(ctx context.Context, req *paymentv1.ListStoredValueEntriesRequest) (*paymentv1.ListStoredValueEntriesResponse, error) {
+	entries, total, err := h.svc.ListEntries(ctx, req.GetCode(), req.GetPageSize(), req.GetPage())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+
+	protos := make([]*paymentv1.StoredValueEntry, len(entries))
+	for i, e := range entries {
+		protos[i] = storedValueEntryToProto(e)
+	}
+	return &paymentv1.ListStoredValueEntriesResponse{
+		Entries:    protos,
+		TotalCount: int32(total),
+	}, nil
+}
+
+func (h *StoredValueHandler) RedeemStoredValue(ctx context.Context, req *paymentv1.RedeemStoredValueRequest) (*paymentv1.StoredValueAccount, error) {
+	account, err := h.svc.Redeem(ctx, req.GetCode(), req.GetAmount(), req.GetStaffId(), req.GetNote())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return storedValueAccountToProto(account), nil
+}
+
+func (h *StoredValueHandler) TransferStoredValue(ctx context.Context, req *paymentv1.TransferStoredValueRequest) (*paymentv1.TransferStoredValueResponse, error) {
+	from, to, err := h.svc.Transfer(ctx, req.GetFromCode(), req.GetToCode(), req.GetAmount(), req.GetStaffId(), req.GetNote())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &paymentv1.TransferStoredValueResponse{
+		From: storedValueAccountToProto(from),
+		To:   storedValueAccountToProto(to),
+	}, nil
+}
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	paymentv1 "github.com/enkaigaku/dvd-rental/gen/proto/payment/v1"
+	"github.com/enkaigaku/dvd-rental/internal/payment/model"
+	"github.com/enkaigaku/dvd-rental/internal/payment/repository"
+	"github.com/enkaigaku/dvd-rental/internal/payment/service"
+)
+
+// SubscriptionHandler implements the SubscriptionService gRPC server.
+type SubscriptionHandler struct {
+	paymentv1.UnimplementedSubscriptionServiceServer
+	svc *service.SubscriptionService
+}
+
+// NewSubscriptionHandler creates a new SubscriptionHandler.
+func NewSubscriptionHandler(svc *service.SubscriptionService) *SubscriptionHandler {
+	return &SubscriptionHandler{svc: svc}
+}
+
+func (h *SubscriptionHandler) ListSubscriptionPlans(ctx context.Context, req *paymentv1.ListSubscriptionPlansRequest) (*paymentv1.ListSubscriptionPlansResponse, error) {
+	plans, err := h.svc.ListPlans(ctx, req.GetActiveOnly())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+
+	protos := make([]*paymentv1.SubscriptionPlan, len(plans))
+	for i, p := range plans {
+		protos[i] = subscriptionPlanToProto(p)
+	}
+	return &paymentv1.ListSubscriptionPlansResponse{Plans: protos}, nil
+}
+
+func (h *SubscriptionHandler) GetSubscriptionPlan(ctx context.Context, req *paymentv1.GetSubscriptionPlanRequest) (*paymentv1.SubscriptionPlan, error) {
+	plan, err := h.svc.GetPlan(ctx, req.GetPlanId())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return subscriptionPlanToProto(plan), nil
+}
+
+func (h *SubscriptionHandler) CreateSubscriptionPlan(ctx context.Context, req *paymentv1.CreateSubscriptionPlanRequest) (*paymentv1.SubscriptionPlan, error) {
+	plan, err := h.svc.CreatePlan(ctx, repository.SubscriptionPlanParams{
+		Name:                 req.GetName(),
+		Description:          req.GetDescription(),
+		Price:                req.GetPrice(),
+		IntervalMonths:       req.GetIntervalMonths(),
+		MaxConcurrentRentals: req.GetMaxConcurrentRentals(),
+		MaxRentalsPerPeriod:  req.GetMaxRentalsPerPeriod(),
+		Active:               req.GetActive(),
+	})
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return subscriptionPlanToProto(plan), nil
+}
+
+func (h *SubscriptionHandler) UpdateSubscriptionPlan(ctx context.Context, req *paymentv1.UpdateSubscriptionPlanRequest) (*paymentv1.SubscriptionPlan, error) {
+	plan, err := h.svc.UpdatePlan(ctx, req.GetPlanId(), repository.SubscriptionPlanParams{
+		Name:                 req.GetName(),
+		Description:          req.GetDescription(),
+		Price:                req.GetPrice(),
+		IntervalMonths:       req.GetIntervalMonths(),
+		MaxConcurrentRentals: req.GetMaxConcurrentRentals(),
+		MaxRentalsPerPeriod:  req.GetMaxRentalsPerPeriod(),
+		Active:               req.GetActive(),
+	})
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return subscriptionPlanToProto(plan), nil
+}
+
+func (h *SubscriptionHandler) StartSubscription(ctx context.Context, req *paymentv1.StartSubscriptionRequest) (*paymentv1.Subscription, error) {
+	return subscriptionResult(h.svc.Start(ctx, req.GetCustomerId(), req.GetPlanId()))
+}
+
+func (h *SubscriptionHandler) GetSubscription(ctx context.Context, req *paymentv1.GetSubscriptionRequest) (*paymentv1.Subscription, error) {
+	return subscriptionResult(h.svc.GetSubscription(ctx, req.GetSubscriptionId()))
+}
+
+func (h *SubscriptionHandler) GetCurrentSubscription(ctx context.Context, req *paymentv1.GetCurrentSubscriptionRequest) (*paymentv1.Subscription, error) {
+	return subscriptionResult(h.svc.GetCurrentSubscription(ctx, req.GetCustomerId()))
+}
+
+func (h *SubscriptionHandler) ListSubscriptions(ctx context.Context, req *paymentv1.ListSubscriptionsRequest) (*paymentv1.ListSubscriptionsResponse, error) {
+	subs, total, err := h.svc.ListSubscriptions(ctx, req.GetCustomerId(), req.GetStatus(), req.GetPageSize(), req.GetPage())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+
+	protos := make([]*paymentv1.Subscription, len(subs))
+	for i, s := range subs {
+		protos[i] = subscriptionToProto(s)
+	}
+	return &paymentv1.ListSubscriptionsResponse{
+		Subscriptions: protos,
+		TotalCount:    int32(total),
+	}, nil
+}
+
+func (h *SubscriptionHandler) CancelSubscription(ctx context.Context, req *paymentv1.CancelSubscriptionRequest) (*paymentv1.Subscription, error) {
+	return subscriptionResult(h.svc.Cancel(ctx, req.GetSubscriptionId(), req.GetImmediately()))
+}
+
+func (h *SubscriptionHandler) PauseSubscription(ctx context.Context, req *paymentv1.PauseSubscriptionRequest) (*paymentv1.Subscription, error) {
+	return subscriptionResult(h.svc.Pause(ctx, req.GetSubscriptionId()))
+}
+
+func (h *SubscriptionHandler) ResumeSubscription(ctx context.Context, req *paymentv1.ResumeSubscriptionRequest) (*paymentv1.Subscription, error) {
+	return subscriptionResult(h.svc.Resume(ctx, req.GetSubscriptionId()))
+}
+
+func (h *SubscriptionHandler) RenewSubscription(ctx context.Context, req *paymentv1.RenewSubscriptionRequest) (*paymentv1.Subscription, error) {
+	return subscriptionResult(h.svc.Renew(ctx, req.GetSubscriptionId()))
+}
+
+func (h *SubscriptionHandler) ListSubscriptionPayments(ctx context.Context, req *paymentv1.ListSubscriptionPaymentsRequest) (*paymentv1.ListSubscriptionPaymentsResponse, error) {
+	payments, total, err := h.svc.ListPayments(ctx, req.GetSubscriptionId(), req.GetPageSize(), req.GetPage())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+
+	protos := make([]*paymentv1.SubscriptionPayment, len(payments))
+	for i, p := range payments {
+		protos[i] = subscriptionPaymentToProto(p)
+	}
+	return &paymentv1.ListSubscriptionPaymentsResponse{
+		Payments:   protos,
+		TotalCount: int32(total),
+	}, nil
+}
+
+func (h *SubscriptionHandler) RunSubscriptionBilling(ctx context.Context, req *paymentv1.RunSubscriptionBillingRequest) (*paymentv1.RunSubscriptionBillingResponse, error) {
+	run, err := h.svc.RunBilling(ctx, time.Now())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &paymentv1.RunSubscriptionBillingResponse{
+		Renewed:  run.Renewed,
+		Failed:   run.Failed,
+		Paused:   run.Paused,
+		Canceled: run.Canceled,
+	}, nil
+}
+
+func subscriptionResult(sub model.Subscription, err error) (*paymentv1.Subscription, error) {
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return subscriptionToProto(sub), nil
+}
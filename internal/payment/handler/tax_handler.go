@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	paymentv1 "github.com/enkaigaku/dvd-rental/gen/proto/payment/v1"
+	"github.com/enkaigaku/dvd-rental/internal/payment/repository"
+	"github.com/enkaigaku/dvd-rental/internal/payment/service"
+)
+
+// TaxHandler implements the TaxService gRPC server.
+type TaxHandler struct {
+	paymentv1.UnimplementedTaxServiceServer
+	svc *service.TaxService
+}
+
+// NewTaxHandler creates a new TaxHandler.
+func NewTaxHandler(svc *service.TaxService) *TaxHandler {
+	return &TaxHandler{svc: svc}
+}
+
+func (h *TaxHandler) ListTaxRates(ctx context.Context, req *paymentv1.ListTaxRatesRequest) (*paymentv1.ListTaxRatesResponse, error) {
+	rates, err := h.svc.ListTaxRates(ctx, req.GetCountryId())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+
+	protos := make([]*paymentv1.TaxRate, len(rates))
+	for i, t := range rates {
+		protos[i] = taxRateToProto(t)
+	}
+	return &paymentv1.ListTaxRatesResponse{TaxRates: protos}, nil
+}
+
+func (h *TaxHandler) GetTaxRate(ctx context.Context, req *paymentv1.GetTaxRateRequest) (*paymentv1.TaxRate, error) {
+	rate, err := h.svc.GetTaxRate(ctx, req.GetTaxRateId())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return taxRateToProto(rate), nil
+}
+
+func (h *TaxHandler) CreateTaxRate(ctx context.Context, req *paymentv1.CreateTaxRateRequest) (*paymentv1.TaxRate, error) {
+	rate, err := h.svc.CreateTaxRate(ctx, repository.TaxRateParams{
+		Name:       req.GetName(),
+		CountryID:  req.GetCountryId(),
+		CityID:     req.GetCityId(),
+		ChargeType: req.GetChargeType(),
+		Rate:       req.GetRate(),
+		Active:     req.GetActive(),
+	})
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return taxRateToProto(rate), nil
+}
+
+func (h *TaxHandler) UpdateTaxRate(ctx context.Context, req *paymentv1.UpdateTaxRateRequest) (*paymentv1.TaxRate, error) {
+	rate, err := h.svc.UpdateTaxRate(ctx, req.GetTaxRateId(), repository.TaxRateParams{
+		Name:       req.GetName(),
+		CountryID:  req.GetCountryId(),
+		CityID:     req.GetCityId(),
+		ChargeType: req.GetChargeType(),
+		Rate:       req.GetRate(),
+		Active:     req.GetActive(),
+	})
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return taxRateToProto(rate), nil
+}
+
+func (h *TaxHandler) DeleteTaxRate(ctx context.Context, req *paymentv1.DeleteTaxRateRequest) (*emptypb.Empty, error) {
+	if err := h.svc.DeleteTaxRate(ctx, req.GetTaxRateId()); err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
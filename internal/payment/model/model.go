@@ -8,10 +8,20 @@ type Payment struct {
 	CustomerID  int32
 	StaffID     int32
 	RentalID    int32
-	Amount      string // numeric(5,2) stored as string
+	Amount      string // numeric(5,2) stored as string; NetAmount + TaxAmount
 	PaymentDate time.Time
+	ChargeType  string
+	NetAmount   string
+	TaxAmount   string
 }
 
+// Charge types a payment can be for.
+const (
+	ChargeTypeRental      = "rental"
+	ChargeTypeLateFee     = "late_fee"
+	ChargeTypeReplacement = "replacement"
+)
+
 // PaymentDetail is an enriched payment with cross-table data.
 type PaymentDetail struct {
 	Payment
@@ -19,3 +29,270 @@ type PaymentDetail struct {
 	StaffName    string
 	RentalDate   time.Time // zero value means rental not found
 }
+
+// Promotion kinds.
+const (
+	PromotionKindPercentage = "percentage"
+	PromotionKindFixed      = "fixed"
+	PromotionKindBundle     = "bundle"
+)
+
+// Promotion is a discount code or pricing rule evaluated by the pricing engine.
+type Promotion struct {
+	PromotionID        int32
+	Name               string
+	Code               string // empty means the promotion applies automatically
+	Kind               string // percentage, fixed or bundle
+	Value              string // percent off or amount off, numeric(5,2) as string
+	BuyQuantity        int32  // bundle: rent BuyQuantity...
+	PayQuantity        int32  // ...pay PayQuantity
+	CategoryID         int32  // 0 means any category
+	StoreID            int32  // 0 means any store
+	StartsAt           time.Time
+	EndsAt             time.Time // zero value means no bound
+	MaxUsesPerCustomer int32     // 0 means unlimited
+	Active             bool
+	LastUpdate         time.Time
+}
+
+// AppliedRule explains a single promotion applied to a price quote. A
+// subscription waiver is reported as a rule with a zero PromotionID.
+type AppliedRule struct {
+	PromotionID int32
+	Name        string
+	Description string
+	Discount    string
+}
+
+// PriceQuote is the result of pricing a rental.
+type PriceQuote struct {
+	FilmID       int32
+	StoreID      int32
+	CustomerID   int32
+	BaseAmount   string
+	Discount     string
+	Amount       string
+	AppliedRules []AppliedRule
+	// SubscriptionID is the subscription that waived the rental rate, or 0.
+	SubscriptionID int32
+	// TaxAmount is the sales tax on Amount; TotalAmount is what the customer pays.
+	TaxAmount   string
+	TotalAmount string
+}
+
+// Stored value account kinds.
+const (
+	AccountKindGiftCard    = "gift_card"
+	AccountKindStoreCredit = "store_credit"
+	AccountKindSystem      = "system"
+)
+
+// Stored value transaction kinds.
+const (
+	TransactionKindIssue    = "issue"
+	TransactionKindRedeem   = "redeem"
+	TransactionKindTransfer = "transfer"
+)
+
+// StoredValueAccount is a gift card, a customer's store credit or a system account.
+type StoredValueAccount struct {
+	AccountID  int32
+	Kind       string
+	Code       string
+	CustomerID int32  // 0 for unregistered gift cards and system accounts
+	Balance    string // always 0 for system accounts, which keep no running balance
+	Active     bool
+	CreateDate time.Time
+	LastUpdate time.Time
+}
+
+// StoredValueEntry is one side of a balanced stored value transaction.
+type StoredValueEntry struct {
+	EntryID       int32
+	TransactionID int32
+	AccountID     int32
+	Kind          string // transaction kind
+	Amount        string // positive = credit, negative = debit
+	BalanceAfter  string
+	PaymentID     int32 // 0 when not tied to a payment
+	Note          string
+	CreatedAt     time.Time
+}
+
+// Loyalty transaction kinds.
+const (
+	LoyaltyKindEarn   = "earn"
+	LoyaltyKindRedeem = "redeem"
+	LoyaltyKindAdjust = "adjust"
+)
+
+// LoyaltyTier is a loyalty level. Customers qualify for a tier when
+// rewards_report lists them for the tier's thresholds.
+type LoyaltyTier struct {
+	Tier                string
+	Rank                int32
+	MinMonthlyPurchases int32
+	MinDollarAmount     string
+	PointsMultiplier    string
+}
+
+// LoyaltyAccount holds a customer's points and tier.
+type LoyaltyAccount struct {
+	CustomerID       int32
+	Tier             string
+	PointsBalance    int32
+	LifetimePoints   int32
+	PointsMultiplier string
+	TierCalculatedAt time.Time // zero until tiers are first calculated
+}
+
+// LoyaltyTransaction is a change to a customer's points balance.
+type LoyaltyTransaction struct {
+	TransactionID int32
+	CustomerID    int32
+	Kind          string
+	Points        int32 // negative when points are spent
+	BalanceAfter  int32
+	PaymentID     int32 // 0 when not tied to a payment
+	RentalID      int32 // 0 when not tied to a rental
+	Note          string
+	CreatedAt     time.Time
+}
+
+// LoyaltyTierCount is the number of customers in a tier.
+type LoyaltyTierCount struct {
+	Tier      string
+	Customers int64
+}
+
+// RewardsCustomer is a customer listed by rewards_report.
+type RewardsCustomer struct {
+	CustomerID int32
+	StoreID    int32
+	FirstName  string
+	LastName   string
+	Email      string
+}
+
+// Subscription statuses. A subscription is pending until its first period
+// has been charged.
+const (
+	SubscriptionStatusPending  = "pending"
+	SubscriptionStatusActive   = "active"
+	SubscriptionStatusPastDue  = "past_due"
+	SubscriptionStatusPaused   = "paused"
+	SubscriptionStatusCanceled = "canceled"
+)
+
+// Subscription payment statuses.
+const (
+	SubscriptionPaymentSucceeded = "succeeded"
+	SubscriptionPaymentFailed    = "failed"
+)
+
+// SubscriptionPlan is a membership plan billed every IntervalMonths.
+type SubscriptionPlan struct {
+	PlanID               int32
+	Name                 string
+	Description          string
+	Price                string
+	IntervalMonths       int32
+	MaxConcurrentRentals int32 // 0 = unlimited
+	MaxRentalsPerPeriod  int32 // 0 = unlimited
+	Active               bool
+	LastUpdate           time.Time
+}
+
+// Subscription is a customer's membership.
+type Subscription struct {
+	SubscriptionID     int32
+	CustomerID         int32
+	PlanID             int32
+	PlanName           string
+	Status             string
+	CurrentPeriodStart time.Time
+	CurrentPeriodEnd   time.Time
+	CancelAtPeriodEnd  bool
+	PauseAtPeriodEnd   bool
+	FailedAttempts     int32
+	NextBillingAt      time.Time // zero when the subscription is not billed
+	CanceledAt         time.Time // zero unless canceled
+	CreateDate         time.Time
+	LastUpdate         time.Time
+}
+
+// SubscriptionPayment is an attempt to charge a subscription period.
+type SubscriptionPayment struct {
+	SubscriptionPaymentID int32
+	SubscriptionID        int32
+	CustomerID            int32
+	Amount                string
+	PeriodStart           time.Time
+	PeriodEnd             time.Time
+	Status                string
+	GatewayReference      string
+	FailureReason         string
+	CreatedAt             time.Time
+}
+
+// SubscriptionCoverage is the paid subscription period in force at a point in time.
+type SubscriptionCoverage struct {
+	SubscriptionID       int32
+	PlanID               int32
+	PlanName             string
+	MaxConcurrentRentals int32
+	MaxRentalsPerPeriod  int32
+	PeriodStart          time.Time
+	PeriodEnd            time.Time
+}
+
+// BillingRun summarises one run of the recurring billing job.
+type BillingRun struct {
+	Renewed  int32
+	Failed   int32
+	Paused   int32
+	Canceled int32
+}
+
+// TaxRate is a sales tax rate for a country, or for one city of it.
+type TaxRate struct {
+	TaxRateID  int32
+	Name       string
+	CountryID  int32
+	Country    string
+	CityID     int32  // 0 means the whole country
+	City       string // empty when CityID is 0
+	ChargeType string // empty means every charge type
+	Rate       string // percent, e.g. "8.25"
+	Active     bool
+	LastUpdate time.Time
+}
+
+// TaxLine is the tax charged at one rate.
+type TaxLine struct {
+	TaxRateID int32
+	Name      string
+	Rate      string
+	Amount    string
+}
+
+// Receipt is a payment with the details printed on a customer receipt.
+type Receipt struct {
+	PaymentDetail
+	StoreID      int32
+	StoreAddress string
+	StoreCity    string
+	StoreCountry string
+	FilmTitle    string
+	Taxes        []TaxLine
+}
+
+// RevenueRow is the revenue of one store for one charge type.
+type RevenueRow struct {
+	StoreID      int32
+	ChargeType   string
+	PaymentCount int64
+	NetAmount    string
+	TaxAmount    string
+	GrossAmount  string
+}
@@ -50,3 +50,31 @@ func max32(a, b int32) int32 {
 	}
 	return b
 }
+
+func textToString(t pgtype.Text) string {
+	if t.Valid {
+		return t.String
+	}
+	return ""
+}
+
+func stringToText(s string) pgtype.Text {
+	if s == "" {
+		return pgtype.Text{Valid: false}
+	}
+	return pgtype.Text{String: s, Valid: true}
+}
+
+func int4ToInt32(n pgtype.Int4) int32 {
+	if n.Valid {
+		return n.Int32
+	}
+	return 0
+}
+
+func int32ToInt4(v int32) pgtype.Int4 {
+	if v == 0 {
+		return pgtype.Int4{Valid: false}
+	}
+	return pgtype.Int4{Int32: v, Valid: true}
+}
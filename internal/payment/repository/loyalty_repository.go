@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/enkaigaku/dvd-rental/internal/payment/model"
+	"github.com/enkaigaku/dvd-rental/gen/sqlc/payment"
+)
+
+// ErrInsufficientPoints is returned when a redemption exceeds a customer's
+// points balance.
+var ErrInsufficientPoints = errors.New("insufficient loyalty points")
+
+// LoyaltyPosting is a change to a customer's points made together with a payment.
+type LoyaltyPosting struct {
+	Kind     string
+	Points   int32 // always positive; redeem postings are debited
+	RentalID int32
+	Note     string
+}
+
+// LoyaltyRepository defines data-access operations for the loyalty program.
+type LoyaltyRepository interface {
+	GetAccount(ctx context.Context, customerID int32) (model.LoyaltyAccount, error)
+	ListTiers(ctx context.Context) ([]model.LoyaltyTier, error)
+	RecalculateTiers(ctx context.Context, tiers []model.LoyaltyTier) ([]model.LoyaltyTierCount, error)
+	RunRewardsReport(ctx context.Context, minMonthlyPurchases int32, minDollarAmount string) ([]model.RewardsCustomer, error)
+	ListTransactions(ctx context.Context, customerID, limit, offset int32) ([]model.LoyaltyTransaction, error)
+	CountTransactions(ctx context.Context, customerID int32) (int64, error)
+}
+
+type loyaltyRepository struct {
+	pool *pgxpool.Pool
+	q    *paymentsqlc.Queries
+}
+
+// NewLoyaltyRepository creates a new LoyaltyRepository.
+func NewLoyaltyRepository(pool *pgxpool.Pool) LoyaltyRepository {
+	return &loyaltyRepository{pool: pool, q: paymentsqlc.New(pool)}
+}
+
+func (r *loyaltyRepository) GetAccount(ctx context.Context, customerID int32) (model.LoyaltyAccount, error) {
+	row, err := r.q.GetLoyaltyAccount(ctx, customerID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.LoyaltyAccount{}, ErrNotFound
+		}
+		return model.LoyaltyAccount{}, fmt.Errorf("get loyalty account: %w", err)
+	}
+	return model.LoyaltyAccount{
+		CustomerID:       row.CustomerID,
+		Tier:             row.Tier,
+		PointsBalance:    row.PointsBalance,
+		LifetimePoints:   row.LifetimePoints,
+		PointsMultiplier: numericToString(row.PointsMultiplier),
+		TierCalculatedAt: timestamptzToTime(row.TierCalculatedAt),
+	}, nil
+}
+
+func (r *loyaltyRepository) ListTiers(ctx context.Context) ([]model.LoyaltyTier, error) {
+	rows, err := r.q.ListLoyaltyTiers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list loyalty tiers: %w", err)
+	}
+	tiers := make([]model.LoyaltyTier, len(rows))
+	for i, row := range rows {
+		tiers[i] = model.LoyaltyTier{
+			Tier:                row.Tier,
+			Rank:                row.Rank,
+			MinMonthlyPurchases: row.MinMonthlyPurchases,
+			MinDollarAmount:     numericToString(row.MinDollarAmount),
+			PointsMultiplier:    numericToString(row.PointsMultiplier),
+		}
+	}
+	return tiers, nil
+}
+
+// RecalculateTiers opens accounts for customers who have none, moves every
+// account to tiers[0] and then promotes the customers rewards_report lists
+// for each following tier, so later tiers take precedence.
+func (r *loyaltyRepository) RecalculateTiers(ctx context.Context, tiers []model.LoyaltyTier) ([]model.LoyaltyTierCount, error) {
+	if len(tiers) == 0 {
+		return nil, nil
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.q.WithTx(tx)
+	if _, err := q.EnsureLoyaltyAccounts(ctx); err != nil {
+		return nil, fmt.Errorf("ensure loyalty accounts: %w", err)
+	}
+	if err := q.ResetLoyaltyTiers(ctx, tiers[0].Tier); err != nil {
+		return nil, fmt.Errorf("reset loyalty tiers: %w", err)
+	}
+	for _, tier := range tiers[1:] {
+		if _, err := q.PromoteLoyaltyTier(ctx, paymentsqlc.PromoteLoyaltyTierParams{
+			Tier:                tier.Tier,
+			MinMonthlyPurchases: tier.MinMonthlyPurchases,
+			MinDollarAmount:     stringToNumeric(tier.MinDollarAmount),
+		}); err != nil {
+			return nil, fmt.Errorf("promote loyalty tier %s: %w", tier.Tier, err)
+		}
+	}
+
+	rows, err := q.CountLoyaltyAccountsByTier(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("count loyalty accounts by tier: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+
+	counts := make([]model.LoyaltyTierCount, len(rows))
+	for i, row := range rows {
+		counts[i] = model.LoyaltyTierCount{Tier: row.Tier, Customers: row.Customers}
+	}
+	return counts, nil
+}
+
+func (r *loyaltyRepository) RunRewardsReport(ctx context.Context, minMonthlyPurchases int32, minDollarAmount string) ([]model.RewardsCustomer, error) {
+	rows, err := r.q.RunRewardsReport(ctx, paymentsqlc.RunRewardsReportParams{
+		MinMonthlyPurchases: minMonthlyPurchases,
+		MinDollarAmount:     stringToNumeric(minDollarAmount),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("run rewards report: %w", err)
+	}
+	customers := make([]model.RewardsCustomer, len(rows))
+	for i, row := range rows {
+		customers[i] = model.RewardsCustomer{
+			CustomerID: row.CustomerID,
+			StoreID:    row.StoreID,
+			FirstName:  row.FirstName,
+			LastName:   row.LastName,
+			Email:      textToString(row.Email),
+		}
+	}
+	return customers, nil
+}
+
+func (r *loyaltyRepository) ListTransactions(ctx context.Context, customerID, limit, offset int32) ([]model.LoyaltyTransaction, error) {
+	rows, err := r.q.ListLoyaltyTransactions(ctx, paymentsqlc.ListLoyaltyTransactionsParams{
+		CustomerID: customerID, Limit: limit, Offset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list loyalty transactions: %w", err)
+	}
+	transactions := make([]model.LoyaltyTransaction, len(rows))
+	for i, row := range rows {
+		transactions[i] = model.LoyaltyTransaction{
+			TransactionID: row.LoyaltyTransactionID,
+			CustomerID:    row.CustomerID,
+			Kind:          row.Kind,
+			Points:        row.Points,
+			BalanceAfter:  row.BalanceAfter,
+			PaymentID:     int4ToInt32(row.PaymentID),
+			RentalID:      int4ToInt32(row.RentalID),
+			Note:          textToString(row.Note),
+			CreatedAt:     timestamptzToTime(row.CreatedAt),
+		}
+	}
+	return transactions, nil
+}
+
+func (r *loyaltyRepository) CountTransactions(ctx context.Context, customerID int32) (int64, error) {
+	count, err := r.q.CountLoyaltyTransactions(ctx, customerID)
+	if err != nil {
+		return 0, fmt.Errorf("count loyalty transactions: %w", err)
+	}
+	return count, nil
+}
+
+// postLoyalty applies a points posting for a payment using q, which must be
+// bound to a database transaction. Redemptions fail with ErrInsufficientPoints
+// when the customer does not have enough points.
+func postLoyalty(ctx context.Context, q *paymentsqlc.Queries, customerID, paymentID int32, posting LoyaltyPosting) error {
+	var (
+		balance int32
+		err     error
+	)
+	points := posting.Points
+	if posting.Kind == model.LoyaltyKindRedeem {
+		balance, err = q.DebitLoyaltyPoints(ctx, paymentsqlc.DebitLoyaltyPointsParams{
+			CustomerID: customerID,
+			Points:     posting.Points,
+		})
+		points = -posting.Points
+	} else {
+		balance, err = q.CreditLoyaltyPoints(ctx, paymentsqlc.CreditLoyaltyPointsParams{
+			CustomerID: customerID,
+			Points:     posting.Points,
+		})
+	}
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrInsufficientPoints
+		}
+		return fmt.Errorf("update loyalty points: %w", err)
+	}
+
+	if err := q.CreateLoyaltyTransaction(ctx, paymentsqlc.CreateLoyaltyTransactionParams{
+		CustomerID:   customerID,
+		Kind:         posting.Kind,
+		Points:       points,
+		BalanceAfter: balance,
+		PaymentID:    int32ToInt4(paymentID),
+		RentalID:     int32ToInt4(posting.RentalID),
+		Note:         stringToText(posting.Note),
+	}); err != nil {
+		return fmt.Errorf("create loyalty transaction: %w", err)
+	}
+	return nil
+}
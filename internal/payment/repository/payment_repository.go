@@ -21,7 +21,35 @@ type CreatePaymentParams struct {
 	CustomerID int32
 	StaffID    int32
 	RentalID   int32
-	Amount     string
+	ChargeType string
+	// Amount is the total paid: NetAmount plus TaxAmount.
+	Amount    string
+	NetAmount string
+	TaxAmount string
+	// Taxes holds the tax charged at each rate making up TaxAmount. They are
+	// stored in the same transaction as the payment.
+	Taxes []model.TaxLine
+	// AppliedRules records the promotions the pricing engine applied to
+	// Amount. They are stored in the same transaction as the payment.
+	AppliedRules []model.AppliedRule
+	// QuotedAmount, when set, is the pricing engine's net quote that staff
+	// replaced with a manual NetAmount. The override is recorded in the same
+	// transaction as the payment.
+	QuotedAmount string
+	// Redemption, when set, pays Amount from stored value. It is posted in
+	// the same transaction with PaymentID set to the new payment.
+	Redemption *Posting
+	// Loyalty holds the points earned or redeemed with the payment, applied
+	// in order in the same transaction.
+	Loyalty []LoyaltyPosting
+}
+
+// StoreLocation is the store a rental was made at.
+type StoreLocation struct {
+	StoreID int32
+	Address string
+	City    string
+	Country string
 }
 
 // PaymentRepository defines data-access operations for payments.
@@ -42,15 +70,20 @@ type PaymentRepository interface {
 	GetCustomerName(ctx context.Context, customerID int32) (string, error)
 	GetStaffName(ctx context.Context, staffID int32) (string, error)
 	GetRentalDate(ctx context.Context, rentalID int32) (time.Time, error)
+	GetRentalStoreManager(ctx context.Context, rentalID int32) (int32, error)
+	GetRentalStoreLocation(ctx context.Context, rentalID int32) (StoreLocation, error)
+	GetRentalFilmTitle(ctx context.Context, rentalID int32) (string, error)
+	GetRevenueReport(ctx context.Context, startDate, endDate time.Time) ([]model.RevenueRow, error)
 }
 
 type paymentRepository struct {
-	q *paymentsqlc.Queries
+	pool *pgxpool.Pool
+	q    *paymentsqlc.Queries
 }
 
 // NewPaymentRepository creates a new PaymentRepository.
 func NewPaymentRepository(pool *pgxpool.Pool) PaymentRepository {
-	return &paymentRepository{q: paymentsqlc.New(pool)}
+	return &paymentRepository{pool: pool, q: paymentsqlc.New(pool)}
 }
 
 func (r *paymentRepository) GetPayment(ctx context.Context, paymentID int32) (model.Payment, error) {
@@ -159,15 +192,102 @@ func (r *paymentRepository) CountPaymentsByDateRange(ctx context.Context, startD
 }
 
 func (r *paymentRepository) CreatePayment(ctx context.Context, params CreatePaymentParams) (model.Payment, error) {
-	row, err := r.q.CreatePayment(ctx, paymentsqlc.CreatePaymentParams{
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return model.Payment{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.q.WithTx(tx)
+	row, err := q.CreatePayment(ctx, paymentsqlc.CreatePaymentParams{
 		CustomerID: params.CustomerID,
 		StaffID:    params.StaffID,
 		RentalID:   params.RentalID,
 		Amount:     stringToNumeric(params.Amount),
+		ChargeType: params.ChargeType,
+		NetAmount:  stringToNumeric(params.NetAmount),
+		TaxAmount:  stringToNumeric(params.TaxAmount),
 	})
 	if err != nil {
 		return model.Payment{}, fmt.Errorf("create payment: %w", err)
 	}
+
+	for _, tax := range params.Taxes {
+		if err := q.CreatePaymentTax(ctx, paymentsqlc.CreatePaymentTaxParams{
+			PaymentID: row.PaymentID,
+			TaxRateID: tax.TaxRateID,
+			Name:      tax.Name,
+			Rate:      stringToNumeric(tax.Rate),
+			Amount:    stringToNumeric(tax.Amount),
+		}); err != nil {
+			return model.Payment{}, fmt.Errorf("create payment tax: %w", err)
+		}
+	}
+
+	if params.QuotedAmount != "" {
+		if err := q.CreatePaymentPriceOverride(ctx, paymentsqlc.CreatePaymentPriceOverrideParams{
+			PaymentID:    row.PaymentID,
+			CustomerID:   params.CustomerID,
+			StaffID:      params.StaffID,
+			QuotedAmount: stringToNumeric(params.QuotedAmount),
+			Amount:       stringToNumeric(params.NetAmount),
+		}); err != nil {
+			return model.Payment{}, fmt.Errorf("create payment price override: %w", err)
+		}
+	}
+
+	for _, rule := range params.AppliedRules {
+		// Subscription waivers and manual prices are not promotions and leave
+		// no usage record.
+		if rule.PromotionID == 0 {
+			continue
+		}
+		// The quote checked the limit before the transaction; check it again
+		// under the lock, as another payment may have used the promotion
+		// since.
+		if err := q.LockPromotionCustomer(ctx, paymentsqlc.LockPromotionCustomerParams{
+			PromotionID: rule.PromotionID,
+			CustomerID:  params.CustomerID,
+		}); err != nil {
+			return model.Payment{}, fmt.Errorf("lock promotion: %w", err)
+		}
+		reached, err := q.PromotionLimitReached(ctx, paymentsqlc.PromotionLimitReachedParams{
+			CustomerID:  params.CustomerID,
+			PromotionID: rule.PromotionID,
+		})
+		if err != nil {
+			return model.Payment{}, fmt.Errorf("check promotion limit: %w", err)
+		}
+		if reached {
+			return model.Payment{}, fmt.Errorf("promotion %q: %w", rule.Name, ErrPromotionUsedUp)
+		}
+		if err := q.CreatePaymentPromotion(ctx, paymentsqlc.CreatePaymentPromotionParams{
+			PaymentID:   row.PaymentID,
+			PromotionID: rule.PromotionID,
+			CustomerID:  params.CustomerID,
+			Discount:    stringToNumeric(rule.Discount),
+		}); err != nil {
+			return model.Payment{}, fmt.Errorf("create payment promotion: %w", err)
+		}
+	}
+
+	if params.Redemption != nil {
+		redemption := *params.Redemption
+		redemption.PaymentID = row.PaymentID
+		if _, err := postTransaction(ctx, q, redemption); err != nil {
+			return model.Payment{}, err
+		}
+	}
+
+	for _, posting := range params.Loyalty {
+		if err := postLoyalty(ctx, q, params.CustomerID, row.PaymentID, posting); err != nil {
+			return model.Payment{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return model.Payment{}, fmt.Errorf("commit tx: %w", err)
+	}
 	return toPaymentModel(row), nil
 }
 
@@ -217,6 +337,66 @@ func (r *paymentRepository) GetRentalDate(ctx context.Context, rentalID int32) (
 	return timestamptzToTime(ts), nil
 }
 
+func (r *paymentRepository) GetRentalStoreManager(ctx context.Context, rentalID int32) (int32, error) {
+	staffID, err := r.q.GetRentalStoreManager(ctx, rentalID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrNotFound
+		}
+		return 0, fmt.Errorf("get rental store manager: %w", err)
+	}
+	return staffID, nil
+}
+
+func (r *paymentRepository) GetRentalStoreLocation(ctx context.Context, rentalID int32) (StoreLocation, error) {
+	row, err := r.q.GetRentalStoreLocation(ctx, rentalID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return StoreLocation{}, ErrNotFound
+		}
+		return StoreLocation{}, fmt.Errorf("get rental store location: %w", err)
+	}
+	return StoreLocation{
+		StoreID: row.StoreID,
+		Address: row.Address,
+		City:    row.City,
+		Country: row.Country,
+	}, nil
+}
+
+func (r *paymentRepository) GetRentalFilmTitle(ctx context.Context, rentalID int32) (string, error) {
+	title, err := r.q.GetRentalFilmTitle(ctx, rentalID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("get rental film title: %w", err)
+	}
+	return title, nil
+}
+
+func (r *paymentRepository) GetRevenueReport(ctx context.Context, startDate, endDate time.Time) ([]model.RevenueRow, error) {
+	rows, err := r.q.GetRevenueReport(ctx, paymentsqlc.GetRevenueReportParams{
+		StartDate: timeToTimestamptz(startDate),
+		EndDate:   timeToTimestamptz(endDate),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get revenue report: %w", err)
+	}
+	report := make([]model.RevenueRow, len(rows))
+	for i, row := range rows {
+		report[i] = model.RevenueRow{
+			StoreID:      row.StoreID,
+			ChargeType:   row.ChargeType,
+			PaymentCount: row.PaymentCount,
+			NetAmount:    numericToString(row.NetAmount),
+			TaxAmount:    numericToString(row.TaxAmount),
+			GrossAmount:  numericToString(row.GrossAmount),
+		}
+	}
+	return report, nil
+}
+
 func toPaymentModel(p paymentsqlc.Payment) model.Payment {
 	return model.Payment{
 		PaymentID:   p.PaymentID,
@@ -225,6 +405,9 @@ func toPaymentModel(p paymentsqlc.Payment) model.Payment {
 		RentalID:    p.RentalID,
 		Amount:      numericToString(p.Amount),
 		PaymentDate: timestamptzToTime(p.PaymentDate),
+		ChargeType:  p.ChargeType,
+		NetAmount:   numericToString(p.NetAmount),
+		TaxAmount:   numericToString(p.TaxAmount),
 	}
 }
 
@@ -0,0 +1,276 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/enkaigaku/dvd-rental/internal/payment/model"
+	"github.com/enkaigaku/dvd-rental/gen/sqlc/payment"
+)
+
+// ErrPromotionUsedUp is returned when a payment would take a customer past a
+// promotion's per-customer usage limit.
+var ErrPromotionUsedUp = errors.New("promotion usage limit reached")
+
+// PromotionParams holds parameters for creating or updating a promotion.
+type PromotionParams struct {
+	Name               string
+	Code               string
+	Kind               string
+	Value              string
+	BuyQuantity        int32
+	PayQuantity        int32
+	CategoryID         int32
+	StoreID            int32
+	StartsAt           time.Time
+	EndsAt             time.Time
+	MaxUsesPerCustomer int32
+	Active             bool
+}
+
+// FilmPricing holds the pricing inputs of a film.
+type FilmPricing struct {
+	FilmID     int32
+	RentalRate string
+	CategoryID int32
+}
+
+// RentalPricing holds the pricing inputs of an existing rental.
+type RentalPricing struct {
+	RentalID   int32
+	CustomerID int32
+	FilmID     int32
+	StoreID    int32
+	RentalDate time.Time
+}
+
+// PromotionRepository defines data-access operations for promotions and pricing inputs.
+type PromotionRepository interface {
+	GetPromotion(ctx context.Context, promotionID int32) (model.Promotion, error)
+	GetPromotionByCode(ctx context.Context, code string) (model.Promotion, error)
+	ListPromotions(ctx context.Context, limit, offset int32) ([]model.Promotion, error)
+	CountPromotions(ctx context.Context) (int64, error)
+	ListActivePromotions(ctx context.Context, limit, offset int32) ([]model.Promotion, error)
+	CountActivePromotions(ctx context.Context) (int64, error)
+	ListAutomaticPromotions(ctx context.Context, at time.Time) ([]model.Promotion, error)
+	CreatePromotion(ctx context.Context, params PromotionParams) (model.Promotion, error)
+	UpdatePromotion(ctx context.Context, promotionID int32, params PromotionParams) (model.Promotion, error)
+	DeletePromotion(ctx context.Context, promotionID int32) error
+	GetFilmPricing(ctx context.Context, filmID int32) (FilmPricing, error)
+	GetRentalPricing(ctx context.Context, rentalID int32) (RentalPricing, error)
+	CountCustomerRentalsInWindow(ctx context.Context, customerID, storeID, categoryID int32, start, end time.Time) (int64, error)
+	CountPromotionUsesByCustomer(ctx context.Context, promotionID, customerID int32) (int64, error)
+}
+
+type promotionRepository struct {
+	q *paymentsqlc.Queries
+}
+
+// NewPromotionRepository creates a new PromotionRepository.
+func NewPromotionRepository(pool *pgxpool.Pool) PromotionRepository {
+	return &promotionRepository{q: paymentsqlc.New(pool)}
+}
+
+func (r *promotionRepository) GetPromotion(ctx context.Context, promotionID int32) (model.Promotion, error) {
+	row, err := r.q.GetPromotion(ctx, promotionID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Promotion{}, ErrNotFound
+		}
+		return model.Promotion{}, fmt.Errorf("get promotion: %w", err)
+	}
+	return toPromotionModel(row), nil
+}
+
+func (r *promotionRepository) GetPromotionByCode(ctx context.Context, code string) (model.Promotion, error) {
+	row, err := r.q.GetPromotionByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Promotion{}, ErrNotFound
+		}
+		return model.Promotion{}, fmt.Errorf("get promotion by code: %w", err)
+	}
+	return toPromotionModel(row), nil
+}
+
+func (r *promotionRepository) ListPromotions(ctx context.Context, limit, offset int32) ([]model.Promotion, error) {
+	rows, err := r.q.ListPromotions(ctx, paymentsqlc.ListPromotionsParams{Limit: limit, Offset: offset})
+	if err != nil {
+		return nil, fmt.Errorf("list promotions: %w", err)
+	}
+	return toPromotionModels(rows), nil
+}
+
+func (r *promotionRepository) CountPromotions(ctx context.Context) (int64, error) {
+	count, err := r.q.CountPromotions(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("count promotions: %w", err)
+	}
+	return count, nil
+}
+
+func (r *promotionRepository) ListActivePromotions(ctx context.Context, limit, offset int32) ([]model.Promotion, error) {
+	rows, err := r.q.ListActivePromotions(ctx, paymentsqlc.ListActivePromotionsParams{Limit: limit, Offset: offset})
+	if err != nil {
+		return nil, fmt.Errorf("list active promotions: %w", err)
+	}
+	return toPromotionModels(rows), nil
+}
+
+func (r *promotionRepository) CountActivePromotions(ctx context.Context) (int64, error) {
+	count, err := r.q.CountActivePromotions(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("count active promotions: %w", err)
+	}
+	return count, nil
+}
+
+func (r *promotionRepository) ListAutomaticPromotions(ctx context.Context, at time.Time) ([]model.Promotion, error) {
+	rows, err := r.q.ListAutomaticPromotions(ctx, timeToTimestamptz(at))
+	if err != nil {
+		return nil, fmt.Errorf("list automatic promotions: %w", err)
+	}
+	return toPromotionModels(rows), nil
+}
+
+func (r *promotionRepository) CreatePromotion(ctx context.Context, params PromotionParams) (model.Promotion, error) {
+	row, err := r.q.CreatePromotion(ctx, paymentsqlc.CreatePromotionParams{
+		Name:               params.Name,
+		Code:               stringToText(params.Code),
+		Kind:               params.Kind,
+		Value:              stringToNumeric(params.Value),
+		BuyQuantity:        params.BuyQuantity,
+		PayQuantity:        params.PayQuantity,
+		CategoryID:         int32ToInt4(params.CategoryID),
+		StoreID:            int32ToInt4(params.StoreID),
+		StartsAt:           timeToTimestamptz(params.StartsAt),
+		EndsAt:             timeToTimestamptz(params.EndsAt),
+		MaxUsesPerCustomer: params.MaxUsesPerCustomer,
+		Active:             params.Active,
+	})
+	if err != nil {
+		return model.Promotion{}, fmt.Errorf("create promotion: %w", err)
+	}
+	return toPromotionModel(row), nil
+}
+
+func (r *promotionRepository) UpdatePromotion(ctx context.Context, promotionID int32, params PromotionParams) (model.Promotion, error) {
+	row, err := r.q.UpdatePromotion(ctx, paymentsqlc.UpdatePromotionParams{
+		PromotionID:        promotionID,
+		Name:               params.Name,
+		Code:               stringToText(params.Code),
+		Kind:               params.Kind,
+		Value:              stringToNumeric(params.Value),
+		BuyQuantity:        params.BuyQuantity,
+		PayQuantity:        params.PayQuantity,
+		CategoryID:         int32ToInt4(params.CategoryID),
+		StoreID:            int32ToInt4(params.StoreID),
+		StartsAt:           timeToTimestamptz(params.StartsAt),
+		EndsAt:             timeToTimestamptz(params.EndsAt),
+		MaxUsesPerCustomer: params.MaxUsesPerCustomer,
+		Active:             params.Active,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Promotion{}, ErrNotFound
+		}
+		return model.Promotion{}, fmt.Errorf("update promotion: %w", err)
+	}
+	return toPromotionModel(row), nil
+}
+
+func (r *promotionRepository) DeletePromotion(ctx context.Context, promotionID int32) error {
+	if err := r.q.DeletePromotion(ctx, promotionID); err != nil {
+		return fmt.Errorf("delete promotion: %w", err)
+	}
+	return nil
+}
+
+func (r *promotionRepository) GetFilmPricing(ctx context.Context, filmID int32) (FilmPricing, error) {
+	row, err := r.q.GetFilmPricing(ctx, filmID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return FilmPricing{}, ErrNotFound
+		}
+		return FilmPricing{}, fmt.Errorf("get film pricing: %w", err)
+	}
+	return FilmPricing{
+		FilmID:     row.FilmID,
+		RentalRate: numericToString(row.RentalRate),
+		CategoryID: row.CategoryID,
+	}, nil
+}
+
+func (r *promotionRepository) GetRentalPricing(ctx context.Context, rentalID int32) (RentalPricing, error) {
+	row, err := r.q.GetRentalPricing(ctx, rentalID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return RentalPricing{}, ErrNotFound
+		}
+		return RentalPricing{}, fmt.Errorf("get rental pricing: %w", err)
+	}
+	return RentalPricing{
+		RentalID:   row.RentalID,
+		CustomerID: row.CustomerID,
+		FilmID:     row.FilmID,
+		StoreID:    row.StoreID,
+		RentalDate: timestamptzToTime(row.RentalDate),
+	}, nil
+}
+
+func (r *promotionRepository) CountCustomerRentalsInWindow(ctx context.Context, customerID, storeID, categoryID int32, start, end time.Time) (int64, error) {
+	count, err := r.q.CountCustomerRentalsInWindow(ctx, paymentsqlc.CountCustomerRentalsInWindowParams{
+		CustomerID:  customerID,
+		WindowStart: timeToTimestamptz(start),
+		WindowEnd:   timeToTimestamptz(end),
+		StoreID:     storeID,
+		CategoryID:  categoryID,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("count customer rentals in window: %w", err)
+	}
+	return count, nil
+}
+
+func (r *promotionRepository) CountPromotionUsesByCustomer(ctx context.Context, promotionID, customerID int32) (int64, error) {
+	count, err := r.q.CountPromotionUsesByCustomer(ctx, paymentsqlc.CountPromotionUsesByCustomerParams{
+		PromotionID: promotionID,
+		CustomerID:  customerID,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("count promotion uses by customer: %w", err)
+	}
+	return count, nil
+}
+
+func toPromotionModel(p paymentsqlc.Promotion) model.Promotion {
+	return model.Promotion{
+		PromotionID:        p.PromotionID,
+		Name:               p.Name,
+		Code:               textToString(p.Code),
+		Kind:               p.Kind,
+		Value:              numericToString(p.Value),
+		BuyQuantity:        p.BuyQuantity,
+		PayQuantity:        p.PayQuantity,
+		CategoryID:         int4ToInt32(p.CategoryID),
+		StoreID:            int4ToInt32(p.StoreID),
+		StartsAt:           timestamptzToTime(p.StartsAt),
+		EndsAt:             timestamptzToTime(p.EndsAt),
+		MaxUsesPerCustomer: p.MaxUsesPerCustomer,
+		Active:             p.Active,
+		LastUpdate:         timestamptzToTime(p.LastUpdate),
+	}
+}
+
+func toPromotionModels(rows []paymentsqlc.Promotion) []model.Promotion {
+	promotions := make([]model.Promotion, len(rows))
+	for i, row := range rows {
+		promotions[i] = toPromotionModel(row)
+	}
+	return promotions
+}
@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/enkaigaku/dvd-rental/internal/payment/model"
+	"github.com/enkaigaku/dvd-rental/gen/sqlc/payment"
+)
+
+// ErrInsufficientFunds is returned when a debit would overdraw an account
+// or the account is inactive.
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// PostingLeg is a single debit or credit of a stored value transaction.
+type PostingLeg struct {
+	AccountID int32
+	Amount    string // always positive
+	Debit     bool
+}
+
+// Posting is a balanced stored value transaction: debits equal credits.
+type Posting struct {
+	Kind      string
+	PaymentID int32
+	StaffID   int32
+	Note      string
+	Legs      []PostingLeg
+}
+
+// StoredValueRepository defines data-access operations for the stored value ledger.
+type StoredValueRepository interface {
+	GetAccount(ctx context.Context, accountID int32) (model.StoredValueAccount, error)
+	GetAccountByCode(ctx context.Context, code string) (model.StoredValueAccount, error)
+	GetStoreCreditAccount(ctx context.Context, customerID int32) (model.StoredValueAccount, error)
+	ListAccountsByCustomer(ctx context.Context, customerID int32) ([]model.StoredValueAccount, error)
+	CreateAccount(ctx context.Context, kind, code string, customerID int32) (model.StoredValueAccount, error)
+	Post(ctx context.Context, posting Posting) (int32, error)
+	ListEntries(ctx context.Context, accountID, limit, offset int32) ([]model.StoredValueEntry, error)
+	CountEntries(ctx context.Context, accountID int32) (int64, error)
+}
+
+type storedValueRepository struct {
+	pool *pgxpool.Pool
+	q    *paymentsqlc.Queries
+}
+
+// NewStoredValueRepository creates a new StoredValueRepository.
+func NewStoredValueRepository(pool *pgxpool.Pool) StoredValueRepository {
+	return &storedValueRepository{pool: pool, q: paymentsqlc.New(pool)}
+}
+
+func (r *storedValueRepository) GetAccount(ctx context.Context, accountID int32) (model.StoredValueAccount, error) {
+	row, err := r.q.GetStoredValueAccount(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.StoredValueAccount{}, ErrNotFound
+		}
+		return model.StoredValueAccount{}, fmt.Errorf("get stored value account: %w", err)
+	}
+	return toStoredValueAccountModel(row), nil
+}
+
+func (r *storedValueRepository) GetAccountByCode(ctx context.Context, code string) (model.StoredValueAccount, error) {
+	row, err := r.q.GetStoredValueAccountByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.StoredValueAccount{}, ErrNotFound
+		}
+		return model.StoredValueAccount{}, fmt.Errorf("get stored value account by code: %w", err)
+	}
+	return toStoredValueAccountModel(row), nil
+}
+
+func (r *storedValueRepository) GetStoreCreditAccount(ctx context.Context, customerID int32) (model.StoredValueAccount, error) {
+	row, err := r.q.GetStoreCreditAccount(ctx, int32ToInt4(customerID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.StoredValueAccount{}, ErrNotFound
+		}
+		return model.StoredValueAccount{}, fmt.Errorf("get store credit account: %w", err)
+	}
+	return toStoredValueAccountModel(row), nil
+}
+
+func (r *storedValueRepository) ListAccountsByCustomer(ctx context.Context, customerID int32) ([]model.StoredValueAccount, error) {
+	rows, err := r.q.ListStoredValueAccountsByCustomer(ctx, int32ToInt4(customerID))
+	if err != nil {
+		return nil, fmt.Errorf("list stored value accounts by customer: %w", err)
+	}
+	accounts := make([]model.StoredValueAccount, len(rows))
+	for i, row := range rows {
+		accounts[i] = toStoredValueAccountModel(row)
+	}
+	return accounts, nil
+}
+
+func (r *storedValueRepository) CreateAccount(ctx context.Context, kind, code string, customerID int32) (model.StoredValueAccount, error) {
+	row, err := r.q.CreateStoredValueAccount(ctx, paymentsqlc.CreateStoredValueAccountParams{
+		Kind:       kind,
+		Code:       code,
+		CustomerID: int32ToInt4(customerID),
+	})
+	if err != nil {
+		return model.StoredValueAccount{}, fmt.Errorf("create stored value account: %w", err)
+	}
+	return toStoredValueAccountModel(row), nil
+}
+
+func (r *storedValueRepository) Post(ctx context.Context, posting Posting) (int32, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	transactionID, err := postTransaction(ctx, r.q.WithTx(tx), posting)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit tx: %w", err)
+	}
+	return transactionID, nil
+}
+
+func (r *storedValueRepository) ListEntries(ctx context.Context, accountID, limit, offset int32) ([]model.StoredValueEntry, error) {
+	rows, err := r.q.ListStoredValueEntriesByAccount(ctx, paymentsqlc.ListStoredValueEntriesByAccountParams{
+		AccountID: accountID, Limit: limit, Offset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list stored value entries: %w", err)
+	}
+	entries := make([]model.StoredValueEntry, len(rows))
+	for i, row := range rows {
+		entries[i] = model.StoredValueEntry{
+			EntryID:       row.EntryID,
+			TransactionID: row.TransactionID,
+			AccountID:     row.AccountID,
+			Kind:          row.Kind,
+			Amount:        numericToString(row.Amount),
+			BalanceAfter:  numericToString(row.BalanceAfter),
+			PaymentID:     int4ToInt32(row.PaymentID),
+			Note:          textToString(row.Note),
+			CreatedAt:     timestamptzToTime(row.CreatedAt),
+		}
+	}
+	return entries, nil
+}
+
+func (r *storedValueRepository) CountEntries(ctx context.Context, accountID int32) (int64, error) {
+	count, err := r.q.CountStoredValueEntriesByAccount(ctx, accountID)
+	if err != nil {
+		return 0, fmt.Errorf("count stored value entries: %w", err)
+	}
+	return count, nil
+}
+
+// postTransaction writes a stored value transaction and its entries using q,
+// which must be bound to a database transaction. Accounts are updated in
+// account_id order so concurrent postings cannot deadlock. System accounts
+// keep no running balance, so postings do not contend on their rows; their
+// balance is the sum of their entries.
+func postTransaction(ctx context.Context, q *paymentsqlc.Queries, posting Posting) (int32, error) {
+	txn, err := q.CreateStoredValueTransaction(ctx, paymentsqlc.CreateStoredValueTransactionParams{
+		Kind:      posting.Kind,
+		PaymentID: int32ToInt4(posting.PaymentID),
+		StaffID:   int32ToInt4(posting.StaffID),
+		Note:      stringToText(posting.Note),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("create stored value transaction: %w", err)
+	}
+
+	legs := make([]PostingLeg, len(posting.Legs))
+	copy(legs, posting.Legs)
+	sort.Slice(legs, func(i, j int) bool { return legs[i].AccountID < legs[j].AccountID })
+
+	for _, leg := range legs {
+		account, err := q.GetStoredValueAccount(ctx, leg.AccountID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return 0, ErrInsufficientFunds
+			}
+			return 0, fmt.Errorf("get stored value account %d: %w", leg.AccountID, err)
+		}
+
+		var balance pgtype.Numeric
+		amount := leg.Amount
+		if leg.Debit {
+			amount = "-" + leg.Amount
+		}
+		switch {
+		case account.Kind == model.AccountKindSystem:
+			if !account.Active {
+				return 0, ErrInsufficientFunds
+			}
+		case leg.Debit:
+			balance, err = q.DebitStoredValueAccount(ctx, paymentsqlc.DebitStoredValueAccountParams{
+				AccountID: leg.AccountID,
+				Amount:    stringToNumeric(leg.Amount),
+			})
+		default:
+			balance, err = q.CreditStoredValueAccount(ctx, paymentsqlc.CreditStoredValueAccountParams{
+				AccountID: leg.AccountID,
+				Amount:    stringToNumeric(leg.Amount),
+			})
+		}
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return 0, ErrInsufficientFunds
+			}
+			return 0, fmt.Errorf("update stored value account %d: %w", leg.AccountID, err)
+		}
+
+		if err := q.CreateStoredValueEntry(ctx, paymentsqlc.CreateStoredValueEntryParams{
+			TransactionID: txn.TransactionID,
+			AccountID:     leg.AccountID,
+			Amount:        stringToNumeric(amount),
+			BalanceAfter:  balance,
+		}); err != nil {
+			return 0, fmt.Errorf("create stored value entry: %w", err)
+		}
+	}
+	return txn.TransactionID, nil
+}
+
+func toStoredValueAccountModel(a paymentsqlc.StoredValueAccount) model.StoredValueAccount {
+	return model.StoredValueAccount{
+		AccountID:  a.AccountID,
+		Kind:       a.Kind,
+		Code:       a.Code,
+		CustomerID: int4ToInt32(a.CustomerID),
+		Balance:    numericToString(a.Balance),
+		Active:     a.Active,
+		CreateDate: timestamptzToTime(a.CreateDate),
+		LastUpdate: timestamptzToTime(a.LastUpdate),
+	}
+}
@@ -0,0 +1,412 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/enkaigaku/dvd-rental/internal/payment/model"
+	"github.com/enkaigaku/dvd-rental/gen/sqlc/payment"
+)
+
+// SubscriptionPlanParams holds the fields for creating or updating a plan.
+type SubscriptionPlanParams struct {
+	Name                 string
+	Description          string
+	Price                string
+	IntervalMonths       int32
+	MaxConcurrentRentals int32
+	MaxRentalsPerPeriod  int32
+	Active               bool
+}
+
+// SubscriptionPaymentParams records an attempt to charge a subscription period.
+type SubscriptionPaymentParams struct {
+	Amount           string
+	PeriodStart      time.Time
+	PeriodEnd        time.Time
+	Status           string
+	GatewayReference string
+	FailureReason    string
+	// The plan's limits when the period was charged; they apply for the
+	// whole period.
+	MaxConcurrentRentals int32
+	MaxRentalsPerPeriod  int32
+}
+
+// SubscriptionUpdate is called with a subscription locked for update. It
+// changes sub in place and may return a payment attempt to record with the
+// change. Returning an error discards both.
+type SubscriptionUpdate func(sub *model.Subscription) (*SubscriptionPaymentParams, error)
+
+// SubscriptionRepository defines data-access operations for subscriptions.
+type SubscriptionRepository interface {
+	GetPlan(ctx context.Context, planID int32) (model.SubscriptionPlan, error)
+	ListPlans(ctx context.Context, activeOnly bool) ([]model.SubscriptionPlan, error)
+	CreatePlan(ctx context.Context, params SubscriptionPlanParams) (model.SubscriptionPlan, error)
+	UpdatePlan(ctx context.Context, planID int32, params SubscriptionPlanParams) (model.SubscriptionPlan, error)
+
+	GetSubscription(ctx context.Context, subscriptionID int32) (model.Subscription, error)
+	GetLiveSubscriptionByCustomer(ctx context.Context, customerID int32) (model.Subscription, error)
+	ListSubscriptions(ctx context.Context, customerID int32, status string, limit, offset int32) ([]model.Subscription, error)
+	CountSubscriptions(ctx context.Context, customerID int32, status string) (int64, error)
+	CreateSubscription(ctx context.Context, sub model.Subscription) (model.Subscription, error)
+	UpdateSubscription(ctx context.Context, subscriptionID int32, update SubscriptionUpdate) (model.Subscription, error)
+	BillNextDue(ctx context.Context, now time.Time, update SubscriptionUpdate) (model.Subscription, bool, error)
+
+	ListPayments(ctx context.Context, subscriptionID, limit, offset int32) ([]model.SubscriptionPayment, error)
+	CountPayments(ctx context.Context, subscriptionID int32) (int64, error)
+
+	GetCoverage(ctx context.Context, customerID int32, at time.Time) (model.SubscriptionCoverage, error)
+	CountOutstandingRentals(ctx context.Context, customerID, excludeRentalID int32, at time.Time) (int64, error)
+}
+
+type subscriptionRepository struct {
+	pool *pgxpool.Pool
+	q    *paymentsqlc.Queries
+}
+
+// NewSubscriptionRepository creates a new SubscriptionRepository.
+func NewSubscriptionRepository(pool *pgxpool.Pool) SubscriptionRepository {
+	return &subscriptionRepository{pool: pool, q: paymentsqlc.New(pool)}
+}
+
+func (r *subscriptionRepository) GetPlan(ctx context.Context, planID int32) (model.SubscriptionPlan, error) {
+	row, err := r.q.GetSubscriptionPlan(ctx, planID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.SubscriptionPlan{}, ErrNotFound
+		}
+		return model.SubscriptionPlan{}, fmt.Errorf("get subscription plan: %w", err)
+	}
+	return toSubscriptionPlanModel(row), nil
+}
+
+func (r *subscriptionRepository) ListPlans(ctx context.Context, activeOnly bool) ([]model.SubscriptionPlan, error) {
+	rows, err := r.q.ListSubscriptionPlans(ctx, activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("list subscription plans: %w", err)
+	}
+	plans := make([]model.SubscriptionPlan, len(rows))
+	for i, row := range rows {
+		plans[i] = toSubscriptionPlanModel(row)
+	}
+	return plans, nil
+}
+
+func (r *subscriptionRepository) CreatePlan(ctx context.Context, params SubscriptionPlanParams) (model.SubscriptionPlan, error) {
+	row, err := r.q.CreateSubscriptionPlan(ctx, paymentsqlc.CreateSubscriptionPlanParams{
+		Name:                 params.Name,
+		Description:          stringToText(params.Description),
+		Price:                stringToNumeric(params.Price),
+		IntervalMonths:       params.IntervalMonths,
+		MaxConcurrentRentals: params.MaxConcurrentRentals,
+		MaxRentalsPerPeriod:  params.MaxRentalsPerPeriod,
+		Active:               params.Active,
+	})
+	if err != nil {
+		return model.SubscriptionPlan{}, fmt.Errorf("create subscription plan: %w", err)
+	}
+	return toSubscriptionPlanModel(row), nil
+}
+
+func (r *subscriptionRepository) UpdatePlan(ctx context.Context, planID int32, params SubscriptionPlanParams) (model.SubscriptionPlan, error) {
+	row, err := r.q.UpdateSubscriptionPlan(ctx, paymentsqlc.UpdateSubscriptionPlanParams{
+		PlanID:               planID,
+		Name:                 params.Name,
+		Description:          stringToText(params.Description),
+		Price:                stringToNumeric(params.Price),
+		IntervalMonths:       params.IntervalMonths,
+		MaxConcurrentRentals: params.MaxConcurrentRentals,
+		MaxRentalsPerPeriod:  params.MaxRentalsPerPeriod,
+		Active:               params.Active,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.SubscriptionPlan{}, ErrNotFound
+		}
+		return model.SubscriptionPlan{}, fmt.Errorf("update subscription plan: %w", err)
+	}
+	return toSubscriptionPlanModel(row), nil
+}
+
+func (r *subscriptionRepository) GetSubscription(ctx context.Context, subscriptionID int32) (model.Subscription, error) {
+	row, err := r.q.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Subscription{}, ErrNotFound
+		}
+		return model.Subscription{}, fmt.Errorf("get subscription: %w", err)
+	}
+	return toSubscriptionModel(row), nil
+}
+
+func (r *subscriptionRepository) GetLiveSubscriptionByCustomer(ctx context.Context, customerID int32) (model.Subscription, error) {
+	row, err := r.q.GetLiveSubscriptionByCustomer(ctx, customerID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Subscription{}, ErrNotFound
+		}
+		return model.Subscription{}, fmt.Errorf("get live subscription by customer: %w", err)
+	}
+	return toSubscriptionModel(paymentsqlc.GetSubscriptionRow(row)), nil
+}
+
+func (r *subscriptionRepository) ListSubscriptions(ctx context.Context, customerID int32, status string, limit, offset int32) ([]model.Subscription, error) {
+	rows, err := r.q.ListSubscriptions(ctx, paymentsqlc.ListSubscriptionsParams{
+		CustomerID: customerID,
+		Status:     status,
+		Limit:      limit,
+		Offset:     offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list subscriptions: %w", err)
+	}
+	subs := make([]model.Subscription, len(rows))
+	for i, row := range rows {
+		subs[i] = toSubscriptionModel(paymentsqlc.GetSubscriptionRow(row))
+	}
+	return subs, nil
+}
+
+func (r *subscriptionRepository) CountSubscriptions(ctx context.Context, customerID int32, status string) (int64, error) {
+	count, err := r.q.CountSubscriptions(ctx, paymentsqlc.CountSubscriptionsParams{
+		CustomerID: customerID,
+		Status:     status,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("count subscriptions: %w", err)
+	}
+	return count, nil
+}
+
+// CreateSubscription inserts sub. Its first period is charged and recorded
+// afterwards with UpdateSubscription.
+func (r *subscriptionRepository) CreateSubscription(ctx context.Context, sub model.Subscription) (model.Subscription, error) {
+	subscriptionID, err := r.q.CreateSubscription(ctx, paymentsqlc.CreateSubscriptionParams{
+		CustomerID:         sub.CustomerID,
+		PlanID:             sub.PlanID,
+		Status:             sub.Status,
+		CurrentPeriodStart: timeToTimestamptz(sub.CurrentPeriodStart),
+		CurrentPeriodEnd:   timeToTimestamptz(sub.CurrentPeriodEnd),
+		NextBillingAt:      timeToTimestamptz(sub.NextBillingAt),
+	})
+	if err != nil {
+		return model.Subscription{}, fmt.Errorf("create subscription: %w", err)
+	}
+	return r.GetSubscription(ctx, subscriptionID)
+}
+
+// UpdateSubscription locks a subscription, applies update and saves the
+// result. The lock is held until update returns, so update must not call
+// the payment gateway.
+func (r *subscriptionRepository) UpdateSubscription(ctx context.Context, subscriptionID int32, update SubscriptionUpdate) (model.Subscription, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return model.Subscription{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.q.WithTx(tx)
+	row, err := q.LockSubscription(ctx, subscriptionID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Subscription{}, ErrNotFound
+		}
+		return model.Subscription{}, fmt.Errorf("lock subscription: %w", err)
+	}
+
+	sub, err := applySubscriptionUpdate(ctx, q, toSubscriptionModel(paymentsqlc.GetSubscriptionRow(row)), update)
+	if err != nil {
+		return model.Subscription{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return model.Subscription{}, fmt.Errorf("commit tx: %w", err)
+	}
+	return sub, nil
+}
+
+// BillNextDue locks the subscription due for billing soonest, skipping any
+// locked by a concurrent run, and applies update to it. The lock is held
+// until update returns, so update must not call the payment gateway; it
+// claims the subscription by moving NextBillingAt instead. It reports false
+// when nothing is due.
+func (r *subscriptionRepository) BillNextDue(ctx context.Context, now time.Time, update SubscriptionUpdate) (model.Subscription, bool, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return model.Subscription{}, false, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.q.WithTx(tx)
+	row, err := q.LockNextDueSubscription(ctx, timeToTimestamptz(now))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Subscription{}, false, nil
+		}
+		return model.Subscription{}, false, fmt.Errorf("lock next due subscription: %w", err)
+	}
+
+	sub, err := applySubscriptionUpdate(ctx, q, toSubscriptionModel(paymentsqlc.GetSubscriptionRow(row)), update)
+	if err != nil {
+		return model.Subscription{}, false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return model.Subscription{}, false, fmt.Errorf("commit tx: %w", err)
+	}
+	return sub, true, nil
+}
+
+func (r *subscriptionRepository) ListPayments(ctx context.Context, subscriptionID, limit, offset int32) ([]model.SubscriptionPayment, error) {
+	rows, err := r.q.ListSubscriptionPayments(ctx, paymentsqlc.ListSubscriptionPaymentsParams{
+		SubscriptionID: subscriptionID, Limit: limit, Offset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list subscription payments: %w", err)
+	}
+	payments := make([]model.SubscriptionPayment, len(rows))
+	for i, row := range rows {
+		payments[i] = model.SubscriptionPayment{
+			SubscriptionPaymentID: row.SubscriptionPaymentID,
+			SubscriptionID:        row.SubscriptionID,
+			CustomerID:            row.CustomerID,
+			Amount:                numericToString(row.Amount),
+			PeriodStart:           timestamptzToTime(row.PeriodStart),
+			PeriodEnd:             timestamptzToTime(row.PeriodEnd),
+			Status:                row.Status,
+			GatewayReference:      textToString(row.GatewayReference),
+			FailureReason:         textToString(row.FailureReason),
+			CreatedAt:             timestamptzToTime(row.CreatedAt),
+		}
+	}
+	return payments, nil
+}
+
+func (r *subscriptionRepository) CountPayments(ctx context.Context, subscriptionID int32) (int64, error) {
+	count, err := r.q.CountSubscriptionPayments(ctx, subscriptionID)
+	if err != nil {
+		return 0, fmt.Errorf("count subscription payments: %w", err)
+	}
+	return count, nil
+}
+
+func (r *subscriptionRepository) GetCoverage(ctx context.Context, customerID int32, at time.Time) (model.SubscriptionCoverage, error) {
+	row, err := r.q.GetSubscriptionCoverage(ctx, paymentsqlc.GetSubscriptionCoverageParams{
+		CustomerID: customerID,
+		At:         timeToTimestamptz(at),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.SubscriptionCoverage{}, ErrNotFound
+		}
+		return model.SubscriptionCoverage{}, fmt.Errorf("get subscription coverage: %w", err)
+	}
+	return model.SubscriptionCoverage{
+		SubscriptionID:       row.SubscriptionID,
+		PlanID:               row.PlanID,
+		PlanName:             row.PlanName,
+		MaxConcurrentRentals: row.MaxConcurrentRentals,
+		MaxRentalsPerPeriod:  row.MaxRentalsPerPeriod,
+		PeriodStart:          timestamptzToTime(row.PeriodStart),
+		PeriodEnd:            timestamptzToTime(row.PeriodEnd),
+	}, nil
+}
+
+func (r *subscriptionRepository) CountOutstandingRentals(ctx context.Context, customerID, excludeRentalID int32, at time.Time) (int64, error) {
+	count, err := r.q.CountOutstandingRentals(ctx, paymentsqlc.CountOutstandingRentalsParams{
+		CustomerID:      customerID,
+		ExcludeRentalID: excludeRentalID,
+		At:              timeToTimestamptz(at),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("count outstanding rentals: %w", err)
+	}
+	return count, nil
+}
+
+// applySubscriptionUpdate runs update on sub and writes the new state and
+// any payment attempt using q, which must be bound to a database transaction.
+func applySubscriptionUpdate(ctx context.Context, q *paymentsqlc.Queries, sub model.Subscription, update SubscriptionUpdate) (model.Subscription, error) {
+	payment, err := update(&sub)
+	if err != nil {
+		return model.Subscription{}, err
+	}
+
+	if err := q.UpdateSubscriptionState(ctx, paymentsqlc.UpdateSubscriptionStateParams{
+		SubscriptionID:     sub.SubscriptionID,
+		Status:             sub.Status,
+		CurrentPeriodStart: timeToTimestamptz(sub.CurrentPeriodStart),
+		CurrentPeriodEnd:   timeToTimestamptz(sub.CurrentPeriodEnd),
+		CancelAtPeriodEnd:  sub.CancelAtPeriodEnd,
+		PauseAtPeriodEnd:   sub.PauseAtPeriodEnd,
+		FailedAttempts:     sub.FailedAttempts,
+		NextBillingAt:      timeToTimestamptz(sub.NextBillingAt),
+		CanceledAt:         timeToTimestamptz(sub.CanceledAt),
+	}); err != nil {
+		return model.Subscription{}, fmt.Errorf("update subscription: %w", err)
+	}
+
+	if payment != nil {
+		if err := createSubscriptionPayment(ctx, q, sub, *payment); err != nil {
+			return model.Subscription{}, err
+		}
+	}
+	return sub, nil
+}
+
+func createSubscriptionPayment(ctx context.Context, q *paymentsqlc.Queries, sub model.Subscription, payment SubscriptionPaymentParams) error {
+	if err := q.CreateSubscriptionPayment(ctx, paymentsqlc.CreateSubscriptionPaymentParams{
+		SubscriptionID:       sub.SubscriptionID,
+		CustomerID:           sub.CustomerID,
+		Amount:               stringToNumeric(payment.Amount),
+		PeriodStart:          timeToTimestamptz(payment.PeriodStart),
+		PeriodEnd:            timeToTimestamptz(payment.PeriodEnd),
+		Status:               payment.Status,
+		GatewayReference:     stringToText(payment.GatewayReference),
+		FailureReason:        stringToText(payment.FailureReason),
+		MaxConcurrentRentals: payment.MaxConcurrentRentals,
+		MaxRentalsPerPeriod:  payment.MaxRentalsPerPeriod,
+	}); err != nil {
+		return fmt.Errorf("create subscription payment: %w", err)
+	}
+	return nil
+}
+
+func toSubscriptionPlanModel(p paymentsqlc.SubscriptionPlan) model.SubscriptionPlan {
+	return model.SubscriptionPlan{
+		PlanID:               p.PlanID,
+		Name:                 p.Name,
+		Description:          textToString(p.Description),
+		Price:                numericToString(p.Price),
+		IntervalMonths:       p.IntervalMonths,
+		MaxConcurrentRentals: p.MaxConcurrentRentals,
+		MaxRentalsPerPeriod:  p.MaxRentalsPerPeriod,
+		Active:               p.Active,
+		LastUpdate:           timestamptzToTime(p.LastUpdate),
+	}
+}
+
+func toSubscriptionModel(s paymentsqlc.GetSubscriptionRow) model.Subscription {
+	return model.Subscription{
+		SubscriptionID:     s.SubscriptionID,
+		CustomerID:         s.CustomerID,
+		PlanID:             s.PlanID,
+		PlanName:           s.PlanName,
+		Status:             s.Status,
+		CurrentPeriodStart: timestamptzToTime(s.CurrentPeriodStart),
+		CurrentPeriodEnd:   timestamptzToTime(s.CurrentPeriodEnd),
+		CancelAtPeriodEnd:  s.CancelAtPeriodEnd,
+		PauseAtPeriodEnd:   s.PauseAtPeriodEnd,
+		FailedAttempts:     s.FailedAttempts,
+		NextBillingAt:      timestamptzToTime(s.NextBillingAt),
+		CanceledAt:         timestamptzToTime(s.CanceledAt),
+		CreateDate:         timestamptzToTime(s.CreateDate),
+		LastUpdate:         timestamptzToTime(s.LastUpdate),
+	}
+}
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/enkaigaku/dvd-rental/internal/payment/model"
+	"github.com/enkaigaku/dvd-rental/gen/sqlc/payment"
+)
+
+// TaxRateParams holds the fields for creating or updating a tax rate.
+type TaxRateParams struct {
+	Name       string
+	CountryID  int32
+	CityID     int32
+	ChargeType string
+	Rate       string
+	Active     bool
+}
+
+// StoreTaxRate is a rate that applies to charges at a store.
+type StoreTaxRate struct {
+	TaxRateID int32
+	Name      string
+	Rate      string
+}
+
+// TaxRepository defines data-access operations for sales tax rates.
+type TaxRepository interface {
+	GetTaxRate(ctx context.Context, taxRateID int32) (model.TaxRate, error)
+	ListTaxRates(ctx context.Context, countryID int32) ([]model.TaxRate, error)
+	CreateTaxRate(ctx context.Context, params TaxRateParams) (model.TaxRate, error)
+	UpdateTaxRate(ctx context.Context, taxRateID int32, params TaxRateParams) (model.TaxRate, error)
+	DeleteTaxRate(ctx context.Context, taxRateID int32) error
+	GetCityCountry(ctx context.Context, cityID int32) (int32, error)
+	ListStoreTaxRates(ctx context.Context, storeID int32, chargeType string) ([]StoreTaxRate, error)
+	ListPaymentTaxes(ctx context.Context, paymentID int32) ([]model.TaxLine, error)
+}
+
+type taxRepository struct {
+	q *paymentsqlc.Queries
+}
+
+// NewTaxRepository creates a new TaxRepository.
+func NewTaxRepository(pool *pgxpool.Pool) TaxRepository {
+	return &taxRepository{q: paymentsqlc.New(pool)}
+}
+
+func (r *taxRepository) GetTaxRate(ctx context.Context, taxRateID int32) (model.TaxRate, error) {
+	row, err := r.q.GetTaxRate(ctx, taxRateID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.TaxRate{}, ErrNotFound
+		}
+		return model.TaxRate{}, fmt.Errorf("get tax rate: %w", err)
+	}
+	return toTaxRateModel(row), nil
+}
+
+func (r *taxRepository) ListTaxRates(ctx context.Context, countryID int32) ([]model.TaxRate, error) {
+	rows, err := r.q.ListTaxRates(ctx, countryID)
+	if err != nil {
+		return nil, fmt.Errorf("list tax rates: %w", err)
+	}
+	rates := make([]model.TaxRate, len(rows))
+	for i, row := range rows {
+		rates[i] = toTaxRateModel(paymentsqlc.GetTaxRateRow(row))
+	}
+	return rates, nil
+}
+
+func (r *taxRepository) CreateTaxRate(ctx context.Context, params TaxRateParams) (model.TaxRate, error) {
+	taxRateID, err := r.q.CreateTaxRate(ctx, paymentsqlc.CreateTaxRateParams{
+		Name:       params.Name,
+		CountryID:  params.CountryID,
+		CityID:     int32ToInt4(params.CityID),
+		ChargeType: stringToText(params.ChargeType),
+		Rate:       stringToNumeric(params.Rate),
+		Active:     params.Active,
+	})
+	if err != nil {
+		return model.TaxRate{}, fmt.Errorf("create tax rate: %w", err)
+	}
+	return r.GetTaxRate(ctx, taxRateID)
+}
+
+func (r *taxRepository) UpdateTaxRate(ctx context.Context, taxRateID int32, params TaxRateParams) (model.TaxRate, error) {
+	n, err := r.q.UpdateTaxRate(ctx, paymentsqlc.UpdateTaxRateParams{
+		TaxRateID:  taxRateID,
+		Name:       params.Name,
+		CountryID:  params.CountryID,
+		CityID:     int32ToInt4(params.CityID),
+		ChargeType: stringToText(params.ChargeType),
+		Rate:       stringToNumeric(params.Rate),
+		Active:     params.Active,
+	})
+	if err != nil {
+		return model.TaxRate{}, fmt.Errorf("update tax rate: %w", err)
+	}
+	if n == 0 {
+		return model.TaxRate{}, ErrNotFound
+	}
+	return r.GetTaxRate(ctx, taxRateID)
+}
+
+func (r *taxRepository) DeleteTaxRate(ctx context.Context, taxRateID int32) error {
+	n, err := r.q.DeleteTaxRate(ctx, taxRateID)
+	if err != nil {
+		return fmt.Errorf("delete tax rate: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *taxRepository) GetCityCountry(ctx context.Context, cityID int32) (int32, error) {
+	countryID, err := r.q.GetCityCountry(ctx, cityID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrNotFound
+		}
+		return 0, fmt.Errorf("get city country: %w", err)
+	}
+	return countryID, nil
+}
+
+func (r *taxRepository) ListStoreTaxRates(ctx context.Context, storeID int32, chargeType string) ([]StoreTaxRate, error) {
+	rows, err := r.q.ListStoreTaxRates(ctx, paymentsqlc.ListStoreTaxRatesParams{
+		StoreID:    storeID,
+		ChargeType: chargeType,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list store tax rates: %w", err)
+	}
+	rates := make([]StoreTaxRate, len(rows))
+	for i, row := range rows {
+		rates[i] = StoreTaxRate{
+			TaxRateID: row.TaxRateID,
+			Name:      row.Name,
+			Rate:      numericToString(row.Rate),
+		}
+	}
+	return rates, nil
+}
+
+func (r *taxRepository) ListPaymentTaxes(ctx context.Context, paymentID int32) ([]model.TaxLine, error) {
+	rows, err := r.q.ListPaymentTaxes(ctx, paymentID)
+	if err != nil {
+		return nil, fmt.Errorf("list payment taxes: %w", err)
+	}
+	lines := make([]model.TaxLine, len(rows))
+	for i, row := range rows {
+		lines[i] = model.TaxLine{
+			TaxRateID: row.TaxRateID,
+			Name:      row.Name,
+			Rate:      numericToString(row.Rate),
+			Amount:    numericToString(row.Amount),
+		}
+	}
+	return lines, nil
+}
+
+func toTaxRateModel(t paymentsqlc.GetTaxRateRow) model.TaxRate {
+	return model.TaxRate{
+		TaxRateID:  t.TaxRateID,
+		Name:       t.Name,
+		CountryID:  t.CountryID,
+		Country:    t.Country,
+		CityID:     int4ToInt32(t.CityID),
+		City:       textToString(t.City),
+		ChargeType: textToString(t.ChargeType),
+		Rate:       numericToString(t.Rate),
+		Active:     t.Active,
+		LastUpdate: timestamptzToTime(t.LastUpdate),
+	}
+}
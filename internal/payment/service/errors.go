@@ -11,4 +11,8 @@ var (
 	ErrAlreadyExists = errors.New("already exists")
 	// ErrForeignKey indicates the entity is referenced by another entity.
 	ErrForeignKey = errors.New("referenced by another entity")
+	// ErrInsufficientFunds indicates a stored value account cannot cover a debit.
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	// ErrPaymentDeclined indicates the payment gateway refused a charge.
+	ErrPaymentDeclined = errors.New("payment declined")
 )
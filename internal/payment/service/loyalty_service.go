@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/enkaigaku/dvd-rental/internal/payment/model"
+	"github.com/enkaigaku/dvd-rental/internal/payment/repository"
+)
+
+const (
+	// pointsPerDollar is the base earn rate, before the tier multiplier.
+	pointsPerDollar int64 = 10
+
+	// FreeRentalPoints is the number of points a free rental costs.
+	FreeRentalPoints int32 = 250
+)
+
+// LoyaltyService contains business logic for the loyalty points program.
+type LoyaltyService struct {
+	repo repository.LoyaltyRepository
+}
+
+// NewLoyaltyService creates a new LoyaltyService.
+func NewLoyaltyService(repo repository.LoyaltyRepository) *LoyaltyService {
+	return &LoyaltyService{repo: repo}
+}
+
+// GetAccount returns a customer's points and tier. Customers who have not
+// earned any points yet are reported at the lowest tier with no points.
+func (s *LoyaltyService) GetAccount(ctx context.Context, customerID int32) (model.LoyaltyAccount, error) {
+	if customerID <= 0 {
+		return model.LoyaltyAccount{}, fmt.Errorf("customer_id must be positive: %w", ErrInvalidArgument)
+	}
+
+	account, err := s.repo.GetAccount(ctx, customerID)
+	if err == nil {
+		return account, nil
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return model.LoyaltyAccount{}, err
+	}
+
+	tiers, err := s.repo.ListTiers(ctx)
+	if err != nil {
+		return model.LoyaltyAccount{}, err
+	}
+	if len(tiers) == 0 {
+		return model.LoyaltyAccount{}, fmt.Errorf("no loyalty tiers are configured")
+	}
+	return model.LoyaltyAccount{
+		CustomerID:       customerID,
+		Tier:             tiers[0].Tier,
+		PointsMultiplier: tiers[0].PointsMultiplier,
+	}, nil
+}
+
+// ListTiers returns the loyalty tiers, lowest first.
+func (s *LoyaltyService) ListTiers(ctx context.Context) ([]model.LoyaltyTier, error) {
+	return s.repo.ListTiers(ctx)
+}
+
+// RecalculateTiers assigns every customer the highest tier whose thresholds
+// rewards_report finds them meeting, and returns the resulting tier sizes.
+func (s *LoyaltyService) RecalculateTiers(ctx context.Context) ([]model.LoyaltyTierCount, error) {
+	tiers, err := s.repo.ListTiers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.RecalculateTiers(ctx, tiers)
+}
+
+// RunRewardsReport lists the customers who, in the month three months ago,
+// made more than minMonthlyPurchases payments totalling more than minDollarAmount.
+func (s *LoyaltyService) RunRewardsReport(ctx context.Context, minMonthlyPurchases int32, minDollarAmount string) ([]model.RewardsCustomer, error) {
+	if minMonthlyPurchases <= 0 {
+		return nil, fmt.Errorf("min_monthly_purchases must be positive: %w", ErrInvalidArgument)
+	}
+	cents, err := parseCents(minDollarAmount)
+	if err != nil || cents <= 0 {
+		return nil, fmt.Errorf("min_dollar_amount must be a positive amount: %w", ErrInvalidArgument)
+	}
+	if cents > 999_99 {
+		return nil, fmt.Errorf("min_dollar_amount must be at most 999.99: %w", ErrInvalidArgument)
+	}
+	return s.repo.RunRewardsReport(ctx, minMonthlyPurchases, formatCents(cents))
+}
+
+// ListTransactions returns a customer's points history, newest first.
+func (s *LoyaltyService) ListTransactions(ctx context.Context, customerID, pageSize, page int32) ([]model.LoyaltyTransaction, int64, error) {
+	if customerID <= 0 {
+		return nil, 0, fmt.Errorf("customer_id must be positive: %w", ErrInvalidArgument)
+	}
+
+	pageSize, page = clampPagination(pageSize, page)
+	offset := (page - 1) * pageSize
+
+	transactions, err := s.repo.ListTransactions(ctx, customerID, pageSize, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.repo.CountTransactions(ctx, customerID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return transactions, total, nil
+}
+
+// earnPosting returns the points a customer earns for paying amount at their
+// current tier, or nil when the payment earns nothing.
+func (s *LoyaltyService) earnPosting(ctx context.Context, customerID int32, amount string) (*repository.LoyaltyPosting, error) {
+	cents, err := parseCents(amount)
+	if err != nil || cents <= 0 {
+		return nil, nil
+	}
+
+	account, err := s.GetAccount(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	multiplier, err := parseCents(account.PointsMultiplier)
+	if err != nil {
+		return nil, fmt.Errorf("tier %s multiplier: %w", account.Tier, err)
+	}
+
+	points := int32(cents * pointsPerDollar * multiplier / 100_00)
+	if points == 0 {
+		return nil, nil
+	}
+	return &repository.LoyaltyPosting{Kind: model.LoyaltyKindEarn, Points: points}, nil
+}
+
+// redeemFreeRental returns the posting that spends points on a free rental.
+func redeemFreeRental(rentalID int32) repository.LoyaltyPosting {
+	return repository.LoyaltyPosting{
+		Kind:     model.LoyaltyKindRedeem,
+		Points:   FreeRentalPoints,
+		RentalID: rentalID,
+		Note:     "Free rental",
+	}
+}
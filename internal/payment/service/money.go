@@ -0,0 +1,50 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseCents parses a non-negative decimal amount such as "4.99" into
+// hundredths. At most two fractional digits are accepted.
+func parseCents(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" && frac == "" {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	if len(frac) > 2 {
+		return 0, fmt.Errorf("invalid amount %q: at most 2 decimal places", s)
+	}
+	for len(frac) < 2 {
+		frac += "0"
+	}
+	if whole == "" {
+		whole = "0"
+	}
+	w, err := strconv.ParseUint(whole, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	f, err := strconv.ParseUint(frac, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	return int64(w)*100 + int64(f), nil
+}
+
+// formatCents formats hundredths as a two-decimal amount string.
+func formatCents(c int64) string {
+	sign := ""
+	if c < 0 {
+		sign = "-"
+		c = -c
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, c/100, c%100)
+}
+
+// percentOf returns pct (in hundredths of a percent) of amount, rounded half up.
+func percentOf(amount, pct int64) int64 {
+	return (amount*pct + 5000) / 10000
+}
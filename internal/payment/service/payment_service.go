@@ -10,14 +10,49 @@ import (
 	"github.com/enkaigaku/dvd-rental/internal/payment/repository"
 )
 
+// maxPaymentCents is the largest amount a payment column can hold.
+const maxPaymentCents int64 = 999_99
+
 // PaymentService contains business logic for payment operations.
 type PaymentService struct {
-	repo repository.PaymentRepository
+	repo        repository.PaymentRepository
+	pricing     *PricingService
+	storedValue *StoredValueService
+	loyalty     *LoyaltyService
+	taxes       *TaxService
 }
 
 // NewPaymentService creates a new PaymentService.
-func NewPaymentService(repo repository.PaymentRepository) *PaymentService {
-	return &PaymentService{repo: repo}
+func NewPaymentService(
+	repo repository.PaymentRepository,
+	pricing *PricingService,
+	storedValue *StoredValueService,
+	loyalty *LoyaltyService,
+	taxes *TaxService,
+) *PaymentService {
+	return &PaymentService{
+		repo:        repo,
+		pricing:     pricing,
+		storedValue: storedValue,
+		loyalty:     loyalty,
+		taxes:       taxes,
+	}
+}
+
+// PaymentOptions holds optional inputs to CreatePayment.
+type PaymentOptions struct {
+	// PromoCode is applied by the pricing engine; not valid with PriceOverride.
+	PromoCode string
+	// GiftCardCode pays the amount from a gift card or store credit account.
+	GiftCardCode string
+	// RedeemPoints makes the rental free in exchange for loyalty points.
+	RedeemPoints bool
+	// SelfService marks a payment made by the customer, with no staff
+	// member involved; it is recorded against the rental store's manager.
+	SelfService bool
+	// PriceOverride lets staff charge an explicit amount for a rental in
+	// place of the pricing engine's quote.
+	PriceOverride bool
 }
 
 // GetPayment returns a payment with enriched details (customer name, staff name, rental date).
@@ -57,6 +92,38 @@ func (s *PaymentService) GetPayment(ctx context.Context, paymentID int32) (model
 	return detail, nil
 }
 
+// GetPaymentReceipt returns a payment with the details printed on a receipt:
+// the store it was taken at, the film rented and the tax charged.
+func (s *PaymentService) GetPaymentReceipt(ctx context.Context, paymentID int32) (model.Receipt, error) {
+	detail, err := s.GetPayment(ctx, paymentID)
+	if err != nil {
+		return model.Receipt{}, err
+	}
+
+	receipt := model.Receipt{PaymentDetail: detail}
+
+	location, err := s.repo.GetRentalStoreLocation(ctx, detail.RentalID)
+	if err == nil {
+		receipt.StoreID = location.StoreID
+		receipt.StoreAddress = location.Address
+		receipt.StoreCity = location.City
+		receipt.StoreCountry = location.Country
+	}
+
+	title, err := s.repo.GetRentalFilmTitle(ctx, detail.RentalID)
+	if err == nil {
+		receipt.FilmTitle = title
+	}
+
+	taxes, err := s.taxes.paymentTaxes(ctx, paymentID)
+	if err != nil {
+		return model.Receipt{}, err
+	}
+	receipt.Taxes = taxes
+
+	return receipt, nil
+}
+
 // ListPayments returns a paginated list of payments.
 func (s *PaymentService) ListPayments(ctx context.Context, pageSize, page int32) ([]model.Payment, int64, error) {
 	pageSize, page = clampPagination(pageSize, page)
@@ -169,19 +236,171 @@ func (s *PaymentService) ListPaymentsByDateRange(ctx context.Context, startDate,
 	return payments, total, nil
 }
 
-// CreatePayment creates a new payment after validation.
-func (s *PaymentService) CreatePayment(ctx context.Context, params repository.CreatePaymentParams) (model.Payment, error) {
+// GetRevenueReport returns net, tax and gross revenue per store and charge
+// type for payments within a date range [startDate, endDate). Subscription
+// charges are included under the "subscription" charge type.
+func (s *PaymentService) GetRevenueReport(ctx context.Context, startDate, endDate time.Time) ([]model.RevenueRow, error) {
+	if startDate.IsZero() {
+		return nil, fmt.Errorf("start_date must not be empty: %w", ErrInvalidArgument)
+	}
+	if endDate.IsZero() {
+		return nil, fmt.Errorf("end_date must not be empty: %w", ErrInvalidArgument)
+	}
+	if !startDate.Before(endDate) {
+		return nil, fmt.Errorf("start_date must be before end_date: %w", ErrInvalidArgument)
+	}
+
+	return s.repo.GetRevenueReport(ctx, startDate, endDate)
+}
+
+// CreatePayment creates a new payment after validation. The amount is the
+// net charge; sales tax for the rental store's jurisdiction is added on top
+// and the payment's amount is the total. A rental fee is computed by the
+// pricing engine (applying the optional promo code) and the applied
+// promotions are recorded with the payment; staff may charge a different
+// amount only with PriceOverride, which records the replaced quote. Late
+// fees and replacement charges need an explicit amount.
+// A gift card code pays the total from stored value in the same transaction.
+// Loyalty points are earned on the net amount, or spent instead of paying
+// when RedeemPoints is set. A self-service payment is recorded against the
+// rental store's manager; any other needs a staff_id.
+func (s *PaymentService) CreatePayment(ctx context.Context, params repository.CreatePaymentParams, opts PaymentOptions) (model.Payment, error) {
 	if params.CustomerID <= 0 {
 		return model.Payment{}, fmt.Errorf("customer_id must be positive: %w", ErrInvalidArgument)
 	}
-	if params.StaffID <= 0 {
+	if opts.SelfService && params.StaffID != 0 {
+		return model.Payment{}, fmt.Errorf("staff_id must be empty for a self-service payment: %w", ErrInvalidArgument)
+	}
+	if !opts.SelfService && params.StaffID <= 0 {
 		return model.Payment{}, fmt.Errorf("staff_id must be positive: %w", ErrInvalidArgument)
 	}
 	if params.RentalID <= 0 {
 		return model.Payment{}, fmt.Errorf("rental_id must be positive: %w", ErrInvalidArgument)
 	}
-	if params.Amount == "" {
-		return model.Payment{}, fmt.Errorf("amount must not be empty: %w", ErrInvalidArgument)
+	if params.ChargeType == "" {
+		params.ChargeType = model.ChargeTypeRental
+	}
+	if err := validateChargeType(params.ChargeType, false); err != nil {
+		return model.Payment{}, err
+	}
+	if params.ChargeType != model.ChargeTypeRental {
+		if params.Amount == "" {
+			return model.Payment{}, fmt.Errorf("amount is required for a %s charge: %w", params.ChargeType, ErrInvalidArgument)
+		}
+		if opts.PromoCode != "" || opts.RedeemPoints {
+			return model.Payment{}, fmt.Errorf("promo_code and redeem_points only apply to rental charges: %w", ErrInvalidArgument)
+		}
+	}
+	if params.ChargeType == model.ChargeTypeRental && params.Amount != "" && !opts.PriceOverride {
+		return model.Payment{}, fmt.Errorf("amount for a rental charge is set by the pricing engine unless price_override is set: %w", ErrInvalidArgument)
+	}
+	if opts.PriceOverride {
+		if params.ChargeType != model.ChargeTypeRental {
+			return model.Payment{}, fmt.Errorf("price_override only applies to rental charges: %w", ErrInvalidArgument)
+		}
+		if params.Amount == "" {
+			return model.Payment{}, fmt.Errorf("amount is required with price_override: %w", ErrInvalidArgument)
+		}
+		if opts.SelfService {
+			return model.Payment{}, fmt.Errorf("price_override is not allowed for a self-service payment: %w", ErrInvalidArgument)
+		}
+	}
+	if params.Amount != "" && opts.PromoCode != "" {
+		return model.Payment{}, fmt.Errorf("amount and promo_code are mutually exclusive: %w", ErrInvalidArgument)
+	}
+	if opts.RedeemPoints && (params.Amount != "" || opts.PromoCode != "" || opts.GiftCardCode != "") {
+		return model.Payment{}, fmt.Errorf("redeem_points cannot be combined with amount, promo_code or gift_card_code: %w", ErrInvalidArgument)
+	}
+
+	if params.ChargeType == model.ChargeTypeRental {
+		manual := params.Amount
+		quote, err := s.pricing.QuoteRental(ctx, params.RentalID, opts.PromoCode)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return model.Payment{}, fmt.Errorf("invalid rental_id: %w", ErrInvalidArgument)
+			}
+			return model.Payment{}, err
+		}
+		if quote.CustomerID != params.CustomerID {
+			return model.Payment{}, fmt.Errorf("rental %d does not belong to customer %d: %w", params.RentalID, params.CustomerID, ErrInvalidArgument)
+		}
+		params.Amount = quote.Amount
+		params.AppliedRules = quote.AppliedRules
+		if opts.PriceOverride {
+			amount, err := parseCents(manual)
+			if err != nil {
+				return model.Payment{}, fmt.Errorf("amount must be a non-negative amount: %w", ErrInvalidArgument)
+			}
+			quoted, _ := parseCents(quote.Amount)
+			params.Amount = formatCents(amount)
+			params.QuotedAmount = quote.Amount
+			params.AppliedRules = []model.AppliedRule{{
+				Name:        "Manual price",
+				Description: fmt.Sprintf("set by staff %d in place of the quoted %s", params.StaffID, quote.Amount),
+				Discount:    formatCents(quoted - amount),
+			}}
+		}
+		if opts.RedeemPoints && quote.SubscriptionID != 0 {
+			return model.Payment{}, fmt.Errorf("rental %d is covered by subscription %d: %w", params.RentalID, quote.SubscriptionID, ErrInvalidArgument)
+		}
+		if opts.RedeemPoints {
+			params.Amount = formatCents(0)
+			params.AppliedRules = nil
+			params.Loyalty = append(params.Loyalty, redeemFreeRental(params.RentalID))
+		}
+	}
+
+	net, err := parseCents(params.Amount)
+	if err != nil {
+		return model.Payment{}, fmt.Errorf("amount must be a non-negative amount: %w", ErrInvalidArgument)
+	}
+
+	location, err := s.repo.GetRentalStoreLocation(ctx, params.RentalID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.Payment{}, fmt.Errorf("invalid rental_id: %w", ErrInvalidArgument)
+		}
+		return model.Payment{}, err
+	}
+	tax, err := s.taxes.assess(ctx, location.StoreID, params.ChargeType, net)
+	if err != nil {
+		return model.Payment{}, err
+	}
+	if net+tax.total > maxPaymentCents {
+		return model.Payment{}, fmt.Errorf("amount including tax must be at most %s: %w", formatCents(maxPaymentCents), ErrInvalidArgument)
+	}
+	params.NetAmount = formatCents(net)
+	params.TaxAmount = formatCents(tax.total)
+	params.Amount = formatCents(net + tax.total)
+	params.Taxes = tax.lines
+
+	if opts.SelfService {
+		managerID, err := s.repo.GetRentalStoreManager(ctx, params.RentalID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return model.Payment{}, fmt.Errorf("invalid rental_id: %w", ErrInvalidArgument)
+			}
+			return model.Payment{}, err
+		}
+		params.StaffID = managerID
+	}
+
+	// A fully discounted rental has nothing to redeem.
+	if cents, _ := parseCents(params.Amount); opts.GiftCardCode != "" && cents > 0 {
+		posting, err := s.storedValue.redemptionPosting(ctx, opts.GiftCardCode, params.Amount, params.CustomerID)
+		if err != nil {
+			return model.Payment{}, err
+		}
+		posting.StaffID = params.StaffID
+		params.Redemption = posting
+	}
+
+	earned, err := s.loyalty.earnPosting(ctx, params.CustomerID, params.NetAmount)
+	if err != nil {
+		return model.Payment{}, err
+	}
+	if earned != nil {
+		params.Loyalty = append(params.Loyalty, *earned)
 	}
 
 	payment, err := s.repo.CreatePayment(ctx, params)
@@ -189,6 +408,15 @@ func (s *PaymentService) CreatePayment(ctx context.Context, params repository.Cr
 		if isForeignKeyViolation(err) {
 			return model.Payment{}, fmt.Errorf("invalid customer_id, staff_id, or rental_id: %w", ErrInvalidArgument)
 		}
+		if errors.Is(err, repository.ErrInsufficientFunds) {
+			return model.Payment{}, fmt.Errorf("gift card %s has insufficient balance: %w", normalizeCode(opts.GiftCardCode), ErrInsufficientFunds)
+		}
+		if errors.Is(err, repository.ErrInsufficientPoints) {
+			return model.Payment{}, fmt.Errorf("a free rental needs %d loyalty points: %w", FreeRentalPoints, ErrInsufficientFunds)
+		}
+		if errors.Is(err, repository.ErrPromotionUsedUp) {
+			return model.Payment{}, fmt.Errorf("%v: %w", err, ErrInvalidArgument)
+		}
 		return model.Payment{}, err
 	}
 	return payment, nil
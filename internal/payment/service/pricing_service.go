@@ -0,0 +1,351 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/enkaigaku/dvd-rental/internal/payment/model"
+	"github.com/enkaigaku/dvd-rental/internal/payment/repository"
+)
+
+// PricingService is the pricing engine. It computes the charge for a rental
+// from the film's rental rate and every promotion that applies to it. The
+// rental rate is waived for subscribers within their plan's limits. Sales
+// tax for the store's jurisdiction is quoted on top of the discounted charge.
+type PricingService struct {
+	repo          repository.PromotionRepository
+	subscriptions repository.SubscriptionRepository
+	taxes         *TaxService
+}
+
+// NewPricingService creates a new PricingService.
+func NewPricingService(repo repository.PromotionRepository, subscriptions repository.SubscriptionRepository, taxes *TaxService) *PricingService {
+	return &PricingService{repo: repo, subscriptions: subscriptions, taxes: taxes}
+}
+
+// pricingInput describes the rental being priced.
+type pricingInput struct {
+	rentalID   int32 // 0 for a prospective rental
+	filmID     int32
+	storeID    int32
+	customerID int32
+	promoCode  string
+	at         time.Time
+}
+
+// QuoteRentalPrice prices a prospective rental of a film at a store by a customer.
+func (s *PricingService) QuoteRentalPrice(ctx context.Context, filmID, storeID, customerID int32, promoCode string) (model.PriceQuote, error) {
+	if filmID <= 0 {
+		return model.PriceQuote{}, fmt.Errorf("film_id must be positive: %w", ErrInvalidArgument)
+	}
+	if storeID <= 0 {
+		return model.PriceQuote{}, fmt.Errorf("store_id must be positive: %w", ErrInvalidArgument)
+	}
+	if customerID <= 0 {
+		return model.PriceQuote{}, fmt.Errorf("customer_id must be positive: %w", ErrInvalidArgument)
+	}
+
+	return s.price(ctx, pricingInput{
+		filmID:     filmID,
+		storeID:    storeID,
+		customerID: customerID,
+		promoCode:  promoCode,
+		at:         time.Now(),
+	})
+}
+
+// QuoteRental prices an existing rental as of its rental date.
+func (s *PricingService) QuoteRental(ctx context.Context, rentalID int32, promoCode string) (model.PriceQuote, error) {
+	if rentalID <= 0 {
+		return model.PriceQuote{}, fmt.Errorf("rental_id must be positive: %w", ErrInvalidArgument)
+	}
+
+	rental, err := s.repo.GetRentalPricing(ctx, rentalID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.PriceQuote{}, fmt.Errorf("rental %d: %w", rentalID, ErrNotFound)
+		}
+		return model.PriceQuote{}, err
+	}
+
+	return s.price(ctx, pricingInput{
+		rentalID:   rentalID,
+		filmID:     rental.FilmID,
+		storeID:    rental.StoreID,
+		customerID: rental.CustomerID,
+		promoCode:  promoCode,
+		at:         rental.RentalDate,
+	})
+}
+
+func (s *PricingService) price(ctx context.Context, in pricingInput) (model.PriceQuote, error) {
+	film, err := s.repo.GetFilmPricing(ctx, in.filmID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.PriceQuote{}, fmt.Errorf("film %d: %w", in.filmID, ErrNotFound)
+		}
+		return model.PriceQuote{}, err
+	}
+
+	base, err := parseCents(film.RentalRate)
+	if err != nil {
+		return model.PriceQuote{}, fmt.Errorf("film %d rental rate: %w", in.filmID, err)
+	}
+
+	quote := model.PriceQuote{
+		FilmID:       in.filmID,
+		StoreID:      in.storeID,
+		CustomerID:   in.customerID,
+		BaseAmount:   formatCents(base),
+		AppliedRules: []model.AppliedRule{},
+	}
+
+	// A covering subscription makes the rental free, so promotions and promo
+	// codes are not needed.
+	coverage, covered, err := s.subscriptionCoverage(ctx, in)
+	if err != nil {
+		return model.PriceQuote{}, err
+	}
+	if covered {
+		quote.Discount = formatCents(base)
+		quote.Amount = formatCents(0)
+		quote.SubscriptionID = coverage.SubscriptionID
+		if base > 0 {
+			quote.AppliedRules = append(quote.AppliedRules, model.AppliedRule{
+				Name:        coverage.PlanName,
+				Description: fmt.Sprintf("covered by subscription %d", coverage.SubscriptionID),
+				Discount:    formatCents(base),
+			})
+		}
+		return s.withTax(ctx, quote, 0)
+	}
+
+	promotions, err := s.repo.ListAutomaticPromotions(ctx, in.at)
+	if err != nil {
+		return model.PriceQuote{}, err
+	}
+
+	// Automatic promotions that do not apply are silently skipped.
+	var candidates []model.Promotion
+	for _, p := range promotions {
+		if !promotionInScope(p, film.CategoryID, in.storeID) {
+			continue
+		}
+		ok, err := s.withinUsageLimit(ctx, p, in.customerID)
+		if err != nil {
+			return model.PriceQuote{}, err
+		}
+		if ok {
+			candidates = append(candidates, p)
+		}
+	}
+
+	// A presented promo code must be applicable, otherwise the quote fails.
+	if code := strings.TrimSpace(in.promoCode); code != "" {
+		p, err := s.lookupPromoCode(ctx, code, film.CategoryID, in)
+		if err != nil {
+			return model.PriceQuote{}, err
+		}
+		candidates = append(candidates, p)
+	}
+
+	// Bundles first (they make the rental free), then percentages, then fixed amounts.
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return kindOrder(candidates[i].Kind) < kindOrder(candidates[j].Kind)
+	})
+
+	remaining := base
+	for _, p := range candidates {
+		if remaining == 0 {
+			break
+		}
+
+		var (
+			discount    int64
+			description string
+		)
+		switch p.Kind {
+		case model.PromotionKindBundle:
+			position, err := s.bundlePosition(ctx, p, in)
+			if err != nil {
+				return model.PriceQuote{}, err
+			}
+			cycle := (position-1)%int64(p.BuyQuantity) + 1
+			if cycle <= int64(p.PayQuantity) {
+				continue
+			}
+			discount = remaining
+			description = fmt.Sprintf("rent %d pay %d: rental %d of %d is free",
+				p.BuyQuantity, p.PayQuantity, cycle, p.BuyQuantity)
+		case model.PromotionKindPercentage:
+			pct, err := parseCents(p.Value)
+			if err != nil {
+				return model.PriceQuote{}, fmt.Errorf("promotion %d value: %w", p.PromotionID, err)
+			}
+			discount = percentOf(remaining, pct)
+			description = fmt.Sprintf("%s%% off", p.Value)
+		case model.PromotionKindFixed:
+			off, err := parseCents(p.Value)
+			if err != nil {
+				return model.PriceQuote{}, fmt.Errorf("promotion %d value: %w", p.PromotionID, err)
+			}
+			discount = min(off, remaining)
+			description = fmt.Sprintf("%s off", p.Value)
+		}
+		if discount <= 0 {
+			continue
+		}
+
+		if p.Code != "" {
+			description += fmt.Sprintf(" (code %s)", p.Code)
+		}
+		if p.CategoryID != 0 {
+			description += fmt.Sprintf(" on category %d", p.CategoryID)
+		}
+		if p.StoreID != 0 {
+			description += fmt.Sprintf(" at store %d", p.StoreID)
+		}
+
+		remaining -= discount
+		quote.AppliedRules = append(quote.AppliedRules, model.AppliedRule{
+			PromotionID: p.PromotionID,
+			Name:        p.Name,
+			Description: description,
+			Discount:    formatCents(discount),
+		})
+	}
+
+	quote.Discount = formatCents(base - remaining)
+	quote.Amount = formatCents(remaining)
+	return s.withTax(ctx, quote, remaining)
+}
+
+// withTax adds the sales tax on net cents at the quote's store.
+func (s *PricingService) withTax(ctx context.Context, quote model.PriceQuote, net int64) (model.PriceQuote, error) {
+	tax, err := s.taxes.assess(ctx, quote.StoreID, model.ChargeTypeRental, net)
+	if err != nil {
+		return model.PriceQuote{}, err
+	}
+	quote.TaxAmount = formatCents(tax.total)
+	quote.TotalAmount = formatCents(net + tax.total)
+	return quote, nil
+}
+
+// subscriptionCoverage returns the customer's subscription period in force at
+// the time of the rental, and reports whether the rental is within the plan's
+// limits on concurrent rentals and rentals per period.
+func (s *PricingService) subscriptionCoverage(ctx context.Context, in pricingInput) (model.SubscriptionCoverage, bool, error) {
+	coverage, err := s.subscriptions.GetCoverage(ctx, in.customerID, in.at)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.SubscriptionCoverage{}, false, nil
+		}
+		return model.SubscriptionCoverage{}, false, err
+	}
+
+	if coverage.MaxConcurrentRentals > 0 {
+		out, err := s.subscriptions.CountOutstandingRentals(ctx, in.customerID, in.rentalID, in.at)
+		if err != nil {
+			return model.SubscriptionCoverage{}, false, err
+		}
+		if out >= int64(coverage.MaxConcurrentRentals) {
+			return coverage, false, nil
+		}
+	}
+
+	if coverage.MaxRentalsPerPeriod > 0 {
+		prior, err := s.repo.CountCustomerRentalsInWindow(ctx, in.customerID, 0, 0, coverage.PeriodStart, in.at)
+		if err != nil {
+			return model.SubscriptionCoverage{}, false, err
+		}
+		if prior >= int64(coverage.MaxRentalsPerPeriod) {
+			return coverage, false, nil
+		}
+	}
+
+	return coverage, true, nil
+}
+
+// lookupPromoCode resolves a promo code and checks that it applies to the rental.
+func (s *PricingService) lookupPromoCode(ctx context.Context, code string, categoryID int32, in pricingInput) (model.Promotion, error) {
+	p, err := s.repo.GetPromotionByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.Promotion{}, fmt.Errorf("promo code %q is not valid: %w", code, ErrInvalidArgument)
+		}
+		return model.Promotion{}, err
+	}
+
+	switch {
+	case !p.Active:
+		return model.Promotion{}, fmt.Errorf("promo code %q is not active: %w", code, ErrInvalidArgument)
+	case !p.StartsAt.IsZero() && in.at.Before(p.StartsAt):
+		return model.Promotion{}, fmt.Errorf("promo code %q is not valid yet: %w", code, ErrInvalidArgument)
+	case !p.EndsAt.IsZero() && !in.at.Before(p.EndsAt):
+		return model.Promotion{}, fmt.Errorf("promo code %q has expired: %w", code, ErrInvalidArgument)
+	case !promotionInScope(p, categoryID, in.storeID):
+		return model.Promotion{}, fmt.Errorf("promo code %q does not apply to this rental: %w", code, ErrInvalidArgument)
+	}
+
+	ok, err := s.withinUsageLimit(ctx, p, in.customerID)
+	if err != nil {
+		return model.Promotion{}, err
+	}
+	if !ok {
+		return model.Promotion{}, fmt.Errorf("promo code %q has already been used: %w", code, ErrInvalidArgument)
+	}
+	return p, nil
+}
+
+// withinUsageLimit reports whether the customer may still use the promotion.
+// The payment repository checks the limit again, under a lock, when the
+// use is recorded.
+func (s *PricingService) withinUsageLimit(ctx context.Context, p model.Promotion, customerID int32) (bool, error) {
+	if p.MaxUsesPerCustomer == 0 {
+		return true, nil
+	}
+	uses, err := s.repo.CountPromotionUsesByCustomer(ctx, p.PromotionID, customerID)
+	if err != nil {
+		return false, err
+	}
+	return uses < int64(p.MaxUsesPerCustomer), nil
+}
+
+// bundlePosition returns the 1-based position of this rental among the
+// customer's qualifying rentals in the promotion window.
+func (s *PricingService) bundlePosition(ctx context.Context, p model.Promotion, in pricingInput) (int64, error) {
+	start := p.StartsAt
+	if start.IsZero() {
+		start = time.Unix(0, 0)
+	}
+	prior, err := s.repo.CountCustomerRentalsInWindow(ctx, in.customerID, p.StoreID, p.CategoryID, start, in.at)
+	if err != nil {
+		return 0, err
+	}
+	return prior + 1, nil
+}
+
+func promotionInScope(p model.Promotion, categoryID, storeID int32) bool {
+	if p.CategoryID != 0 && p.CategoryID != categoryID {
+		return false
+	}
+	if p.StoreID != 0 && p.StoreID != storeID {
+		return false
+	}
+	return true
+}
+
+func kindOrder(kind string) int {
+	switch kind {
+	case model.PromotionKindBundle:
+		return 0
+	case model.PromotionKindPercentage:
+		return 1
+	default:
+		return 2
+	}
+}
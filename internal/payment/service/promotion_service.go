@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/enkaigaku/dvd-rental/internal/payment/model"
+	"github.com/enkaigaku/dvd-rental/internal/payment/repository"
+)
+
+// PromotionService contains business logic for managing promotions.
+type PromotionService struct {
+	repo repository.PromotionRepository
+}
+
+// NewPromotionService creates a new PromotionService.
+func NewPromotionService(repo repository.PromotionRepository) *PromotionService {
+	return &PromotionService{repo: repo}
+}
+
+// GetPromotion returns a promotion by ID.
+func (s *PromotionService) GetPromotion(ctx context.Context, promotionID int32) (model.Promotion, error) {
+	if promotionID <= 0 {
+		return model.Promotion{}, fmt.Errorf("promotion_id must be positive: %w", ErrInvalidArgument)
+	}
+
+	promotion, err := s.repo.GetPromotion(ctx, promotionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.Promotion{}, fmt.Errorf("promotion %d: %w", promotionID, ErrNotFound)
+		}
+		return model.Promotion{}, err
+	}
+	return promotion, nil
+}
+
+// ListPromotions returns a paginated list of promotions, optionally only active ones.
+func (s *PromotionService) ListPromotions(ctx context.Context, pageSize, page int32, activeOnly bool) ([]model.Promotion, int64, error) {
+	pageSize, page = clampPagination(pageSize, page)
+	offset := (page - 1) * pageSize
+
+	var (
+		promotions []model.Promotion
+		total      int64
+		err        error
+	)
+
+	if activeOnly {
+		promotions, err = s.repo.ListActivePromotions(ctx, pageSize, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		total, err = s.repo.CountActivePromotions(ctx)
+	} else {
+		promotions, err = s.repo.ListPromotions(ctx, pageSize, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		total, err = s.repo.CountPromotions(ctx)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	return promotions, total, nil
+}
+
+// CreatePromotion creates a new promotion after validation.
+func (s *PromotionService) CreatePromotion(ctx context.Context, params repository.PromotionParams) (model.Promotion, error) {
+	params = normalizePromotion(params)
+	if err := validatePromotion(params); err != nil {
+		return model.Promotion{}, err
+	}
+
+	promotion, err := s.repo.CreatePromotion(ctx, params)
+	if err != nil {
+		return model.Promotion{}, mapPromotionWriteError(err, params)
+	}
+	return promotion, nil
+}
+
+// UpdatePromotion updates an existing promotion after validation.
+func (s *PromotionService) UpdatePromotion(ctx context.Context, promotionID int32, params repository.PromotionParams) (model.Promotion, error) {
+	if promotionID <= 0 {
+		return model.Promotion{}, fmt.Errorf("promotion_id must be positive: %w", ErrInvalidArgument)
+	}
+	params = normalizePromotion(params)
+	if err := validatePromotion(params); err != nil {
+		return model.Promotion{}, err
+	}
+
+	promotion, err := s.repo.UpdatePromotion(ctx, promotionID, params)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.Promotion{}, fmt.Errorf("promotion %d: %w", promotionID, ErrNotFound)
+		}
+		return model.Promotion{}, mapPromotionWriteError(err, params)
+	}
+	return promotion, nil
+}
+
+// DeletePromotion deletes a promotion. Promotions that have already been
+// applied to payments cannot be deleted and should be deactivated instead.
+func (s *PromotionService) DeletePromotion(ctx context.Context, promotionID int32) error {
+	if promotionID <= 0 {
+		return fmt.Errorf("promotion_id must be positive: %w", ErrInvalidArgument)
+	}
+
+	// Verify the promotion exists.
+	_, err := s.repo.GetPromotion(ctx, promotionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("promotion %d: %w", promotionID, ErrNotFound)
+		}
+		return err
+	}
+
+	if err := s.repo.DeletePromotion(ctx, promotionID); err != nil {
+		if isForeignKeyViolation(err) {
+			return fmt.Errorf("promotion %d has been applied to payments, deactivate it instead: %w", promotionID, ErrForeignKey)
+		}
+		return err
+	}
+	return nil
+}
+
+// normalizePromotion trims the code and zeroes fields the kind does not use.
+func normalizePromotion(params repository.PromotionParams) repository.PromotionParams {
+	params.Name = strings.TrimSpace(params.Name)
+	params.Code = strings.TrimSpace(params.Code)
+	if params.Kind == model.PromotionKindBundle {
+		params.Value = "0"
+	} else {
+		params.BuyQuantity, params.PayQuantity = 0, 0
+	}
+	return params
+}
+
+func validatePromotion(params repository.PromotionParams) error {
+	if params.Name == "" {
+		return fmt.Errorf("name must not be empty: %w", ErrInvalidArgument)
+	}
+
+	switch params.Kind {
+	case model.PromotionKindPercentage, model.PromotionKindFixed:
+		value, err := parseCents(params.Value)
+		if err != nil || value <= 0 {
+			return fmt.Errorf("value must be a positive amount: %w", ErrInvalidArgument)
+		}
+		if params.Kind == model.PromotionKindPercentage && value > 10000 {
+			return fmt.Errorf("percentage value must not exceed 100: %w", ErrInvalidArgument)
+		}
+		if value > maxPaymentCents {
+			return fmt.Errorf("value must be at most %s: %w", formatCents(maxPaymentCents), ErrInvalidArgument)
+		}
+	case model.PromotionKindBundle:
+		if params.PayQuantity <= 0 || params.BuyQuantity <= params.PayQuantity {
+			return fmt.Errorf("bundle requires buy_quantity > pay_quantity > 0: %w", ErrInvalidArgument)
+		}
+	default:
+		return fmt.Errorf("kind must be one of percentage, fixed, bundle: %w", ErrInvalidArgument)
+	}
+
+	if params.CategoryID < 0 {
+		return fmt.Errorf("category_id must not be negative: %w", ErrInvalidArgument)
+	}
+	if params.StoreID < 0 {
+		return fmt.Errorf("store_id must not be negative: %w", ErrInvalidArgument)
+	}
+	if params.MaxUsesPerCustomer < 0 {
+		return fmt.Errorf("max_uses_per_customer must not be negative: %w", ErrInvalidArgument)
+	}
+	if !params.StartsAt.IsZero() && !params.EndsAt.IsZero() && !params.StartsAt.Before(params.EndsAt) {
+		return fmt.Errorf("starts_at must be before ends_at: %w", ErrInvalidArgument)
+	}
+	return nil
+}
+
+func mapPromotionWriteError(err error, params repository.PromotionParams) error {
+	if isUniqueViolation(err) {
+		return fmt.Errorf("promo code %q already exists: %w", params.Code, ErrAlreadyExists)
+	}
+	if isForeignKeyViolation(err) {
+		return fmt.Errorf("invalid category_id or store_id: %w", ErrInvalidArgument)
+	}
+	return err
+}
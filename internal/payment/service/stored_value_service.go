@@ -0,0 +1,334 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/enkaigaku/dvd-rental/internal/payment/model"
+	"github.com/enkaigaku/dvd-rental/internal/payment/repository"
+)
+
+const (
+	// maxIssueCents caps a single issuance of stored value.
+	maxIssueCents int64 = 1000_00
+
+	issuanceAccountCode   = "SYSTEM-ISSUANCE"
+	redemptionAccountCode = "SYSTEM-REDEMPTION"
+
+	// codeAlphabet omits characters that are easily confused (0/O, 1/I).
+	codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+)
+
+// StoredValueService contains business logic for gift cards and store credit.
+// All balance changes are posted as balanced (double-entry) transactions.
+type StoredValueService struct {
+	repo repository.StoredValueRepository
+}
+
+// NewStoredValueService creates a new StoredValueService.
+func NewStoredValueService(repo repository.StoredValueRepository) *StoredValueService {
+	return &StoredValueService{repo: repo}
+}
+
+// IssueGiftCard creates a gift card with a new code and loads it with amount.
+// customerID is optional and records who the card was sold to.
+func (s *StoredValueService) IssueGiftCard(ctx context.Context, amount string, customerID, staffID int32, note string) (model.StoredValueAccount, error) {
+	if err := validateIssueAmount(amount); err != nil {
+		return model.StoredValueAccount{}, err
+	}
+	if customerID < 0 {
+		return model.StoredValueAccount{}, fmt.Errorf("customer_id must not be negative: %w", ErrInvalidArgument)
+	}
+
+	account, err := s.createAccount(ctx, model.AccountKindGiftCard, "GC", customerID)
+	if err != nil {
+		return model.StoredValueAccount{}, err
+	}
+	if err := s.issue(ctx, account, amount, staffID, note); err != nil {
+		return model.StoredValueAccount{}, err
+	}
+	return s.repo.GetAccount(ctx, account.AccountID)
+}
+
+// IssueStoreCredit credits a customer's store credit account (e.g. for a
+// refund), opening the account on first use.
+func (s *StoredValueService) IssueStoreCredit(ctx context.Context, customerID int32, amount string, staffID int32, note string) (model.StoredValueAccount, error) {
+	if customerID <= 0 {
+		return model.StoredValueAccount{}, fmt.Errorf("customer_id must be positive: %w", ErrInvalidArgument)
+	}
+	if err := validateIssueAmount(amount); err != nil {
+		return model.StoredValueAccount{}, err
+	}
+
+	account, err := s.repo.GetStoreCreditAccount(ctx, customerID)
+	if errors.Is(err, repository.ErrNotFound) {
+		account, err = s.createAccount(ctx, model.AccountKindStoreCredit, "SC", customerID)
+		if errors.Is(err, ErrAlreadyExists) {
+			// Opened concurrently by another request.
+			account, err = s.repo.GetStoreCreditAccount(ctx, customerID)
+		}
+	}
+	if err != nil {
+		return model.StoredValueAccount{}, err
+	}
+
+	if err := s.issue(ctx, account, amount, staffID, note); err != nil {
+		return model.StoredValueAccount{}, err
+	}
+	return s.repo.GetAccount(ctx, account.AccountID)
+}
+
+// GetAccount returns a gift card or store credit account by its code.
+func (s *StoredValueService) GetAccount(ctx context.Context, code string) (model.StoredValueAccount, error) {
+	code = normalizeCode(code)
+	if code == "" {
+		return model.StoredValueAccount{}, fmt.Errorf("code must not be empty: %w", ErrInvalidArgument)
+	}
+
+	account, err := s.repo.GetAccountByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.StoredValueAccount{}, fmt.Errorf("stored value account %q: %w", code, ErrNotFound)
+		}
+		return model.StoredValueAccount{}, err
+	}
+	if account.Kind == model.AccountKindSystem {
+		return model.StoredValueAccount{}, fmt.Errorf("stored value account %q: %w", code, ErrNotFound)
+	}
+	return account, nil
+}
+
+// GetStoreCreditAccount returns a customer's store credit account.
+func (s *StoredValueService) GetStoreCreditAccount(ctx context.Context, customerID int32) (model.StoredValueAccount, error) {
+	if customerID <= 0 {
+		return model.StoredValueAccount{}, fmt.Errorf("customer_id must be positive: %w", ErrInvalidArgument)
+	}
+
+	account, err := s.repo.GetStoreCreditAccount(ctx, customerID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.StoredValueAccount{}, fmt.Errorf("store credit for customer %d: %w", customerID, ErrNotFound)
+		}
+		return model.StoredValueAccount{}, err
+	}
+	return account, nil
+}
+
+// ListAccountsByCustomer returns the store credit and gift card accounts of a customer.
+func (s *StoredValueService) ListAccountsByCustomer(ctx context.Context, customerID int32) ([]model.StoredValueAccount, error) {
+	if customerID <= 0 {
+		return nil, fmt.Errorf("customer_id must be positive: %w", ErrInvalidArgument)
+	}
+	return s.repo.ListAccountsByCustomer(ctx, customerID)
+}
+
+// ListEntries returns the ledger entries of an account, newest first.
+func (s *StoredValueService) ListEntries(ctx context.Context, code string, pageSize, page int32) ([]model.StoredValueEntry, int64, error) {
+	account, err := s.GetAccount(ctx, code)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	pageSize, page = clampPagination(pageSize, page)
+	offset := (page - 1) * pageSize
+
+	entries, err := s.repo.ListEntries(ctx, account.AccountID, pageSize, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.repo.CountEntries(ctx, account.AccountID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
+
+// Redeem spends amount from a gift card or store credit account outside of
+// a rental payment.
+func (s *StoredValueService) Redeem(ctx context.Context, code, amount string, staffID int32, note string) (model.StoredValueAccount, error) {
+	posting, err := s.redemptionPosting(ctx, code, amount, 0)
+	if err != nil {
+		return model.StoredValueAccount{}, err
+	}
+	posting.StaffID = staffID
+	posting.Note = note
+
+	if _, err := s.repo.Post(ctx, *posting); err != nil {
+		return model.StoredValueAccount{}, mapPostingError(err, code)
+	}
+	return s.GetAccount(ctx, code)
+}
+
+// Transfer moves amount from one gift card or store credit account to another.
+func (s *StoredValueService) Transfer(ctx context.Context, fromCode, toCode, amount string, staffID int32, note string) (model.StoredValueAccount, model.StoredValueAccount, error) {
+	cents, err := parseCents(amount)
+	if err != nil || cents <= 0 {
+		return model.StoredValueAccount{}, model.StoredValueAccount{}, fmt.Errorf("amount must be a positive amount: %w", ErrInvalidArgument)
+	}
+
+	from, err := s.GetAccount(ctx, fromCode)
+	if err != nil {
+		return model.StoredValueAccount{}, model.StoredValueAccount{}, err
+	}
+	to, err := s.GetAccount(ctx, toCode)
+	if err != nil {
+		return model.StoredValueAccount{}, model.StoredValueAccount{}, err
+	}
+	if from.AccountID == to.AccountID {
+		return model.StoredValueAccount{}, model.StoredValueAccount{}, fmt.Errorf("cannot transfer to the same account: %w", ErrInvalidArgument)
+	}
+
+	value := formatCents(cents)
+	if _, err := s.repo.Post(ctx, repository.Posting{
+		Kind:    model.TransactionKindTransfer,
+		StaffID: staffID,
+		Note:    note,
+		Legs: []repository.PostingLeg{
+			{AccountID: from.AccountID, Amount: value, Debit: true},
+			{AccountID: to.AccountID, Amount: value},
+		},
+	}); err != nil {
+		return model.StoredValueAccount{}, model.StoredValueAccount{}, mapPostingError(err, from.Code)
+	}
+
+	from, err = s.repo.GetAccount(ctx, from.AccountID)
+	if err != nil {
+		return model.StoredValueAccount{}, model.StoredValueAccount{}, err
+	}
+	to, err = s.repo.GetAccount(ctx, to.AccountID)
+	if err != nil {
+		return model.StoredValueAccount{}, model.StoredValueAccount{}, err
+	}
+	return from, to, nil
+}
+
+// redemptionPosting builds the posting that spends amount from the account
+// with the given code into the redemption account. When customerID is set,
+// store credit belonging to another customer is rejected.
+func (s *StoredValueService) redemptionPosting(ctx context.Context, code, amount string, customerID int32) (*repository.Posting, error) {
+	cents, err := parseCents(amount)
+	if err != nil || cents <= 0 {
+		return nil, fmt.Errorf("amount must be a positive amount: %w", ErrInvalidArgument)
+	}
+
+	account, err := s.GetAccount(ctx, code)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, fmt.Errorf("gift card %q is not valid: %w", normalizeCode(code), ErrInvalidArgument)
+		}
+		return nil, err
+	}
+	if customerID > 0 && account.Kind == model.AccountKindStoreCredit && account.CustomerID != customerID {
+		return nil, fmt.Errorf("store credit %s belongs to another customer: %w", account.Code, ErrInvalidArgument)
+	}
+	redemption, err := s.repo.GetAccountByCode(ctx, redemptionAccountCode)
+	if err != nil {
+		return nil, fmt.Errorf("redemption account: %w", err)
+	}
+
+	value := formatCents(cents)
+	return &repository.Posting{
+		Kind: model.TransactionKindRedeem,
+		Legs: []repository.PostingLeg{
+			{AccountID: account.AccountID, Amount: value, Debit: true},
+			{AccountID: redemption.AccountID, Amount: value},
+		},
+	}, nil
+}
+
+// issue posts amount from the issuance account into account.
+func (s *StoredValueService) issue(ctx context.Context, account model.StoredValueAccount, amount string, staffID int32, note string) error {
+	issuance, err := s.repo.GetAccountByCode(ctx, issuanceAccountCode)
+	if err != nil {
+		return fmt.Errorf("issuance account: %w", err)
+	}
+
+	cents, _ := parseCents(amount)
+	value := formatCents(cents)
+	if _, err := s.repo.Post(ctx, repository.Posting{
+		Kind:    model.TransactionKindIssue,
+		StaffID: staffID,
+		Note:    note,
+		Legs: []repository.PostingLeg{
+			{AccountID: issuance.AccountID, Amount: value, Debit: true},
+			{AccountID: account.AccountID, Amount: value},
+		},
+	}); err != nil {
+		return mapPostingError(err, account.Code)
+	}
+	return nil
+}
+
+// createAccount opens an account with a freshly generated code.
+func (s *StoredValueService) createAccount(ctx context.Context, kind, prefix string, customerID int32) (model.StoredValueAccount, error) {
+	for attempt := 0; attempt < 3; attempt++ {
+		code, err := newStoredValueCode(prefix)
+		if err != nil {
+			return model.StoredValueAccount{}, err
+		}
+
+		account, err := s.repo.CreateAccount(ctx, kind, code, customerID)
+		if err == nil {
+			return account, nil
+		}
+		if isForeignKeyViolation(err) {
+			return model.StoredValueAccount{}, fmt.Errorf("invalid customer_id: %w", ErrInvalidArgument)
+		}
+		if !isUniqueViolation(err) {
+			return model.StoredValueAccount{}, err
+		}
+		if kind == model.AccountKindStoreCredit {
+			// Unique violation on the per-customer index, not the code.
+			return model.StoredValueAccount{}, fmt.Errorf("store credit for customer %d: %w", customerID, ErrAlreadyExists)
+		}
+	}
+	return model.StoredValueAccount{}, fmt.Errorf("could not generate a unique %s code", kind)
+}
+
+func validateIssueAmount(amount string) error {
+	cents, err := parseCents(amount)
+	if err != nil || cents <= 0 {
+		return fmt.Errorf("amount must be a positive amount: %w", ErrInvalidArgument)
+	}
+	if cents > maxIssueCents {
+		return fmt.Errorf("amount must not exceed %s: %w", formatCents(maxIssueCents), ErrInvalidArgument)
+	}
+	return nil
+}
+
+func mapPostingError(err error, code string) error {
+	if errors.Is(err, repository.ErrInsufficientFunds) {
+		return fmt.Errorf("%s has insufficient balance or is inactive: %w", code, ErrInsufficientFunds)
+	}
+	if isForeignKeyViolation(err) {
+		return fmt.Errorf("invalid staff_id: %w", ErrInvalidArgument)
+	}
+	return err
+}
+
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// newStoredValueCode returns a random code such as GC-7KQ2-M9XD-4HPA.
+func newStoredValueCode(prefix string) (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate code: %w", err)
+	}
+
+	var b strings.Builder
+	b.WriteString(prefix)
+	for i, c := range buf {
+		if i%4 == 0 {
+			b.WriteByte('-')
+		}
+		b.WriteByte(codeAlphabet[int(c)%len(codeAlphabet)])
+	}
+	return b.String(), nil
+}
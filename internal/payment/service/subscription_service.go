@@ -0,0 +1,597 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/enkaigaku/dvd-rental/internal/payment/gateway"
+	"github.com/enkaigaku/dvd-rental/internal/payment/model"
+	"github.com/enkaigaku/dvd-rental/internal/payment/repository"
+)
+
+const (
+	// maxBillingAttempts is the number of declined renewals after which a
+	// subscription is canceled.
+	maxBillingAttempts int32 = 3
+
+	// billingRetryDelay is the wait before retrying a declined renewal.
+	billingRetryDelay = 24 * time.Hour
+
+	// billingClaimTimeout is how long a billing run has to charge and record
+	// a renewal it has claimed before another run may retry it.
+	billingClaimTimeout = 15 * time.Minute
+
+	// maxPlanCents is the largest price a plan column can hold.
+	maxPlanCents int64 = 999_99
+)
+
+// SubscriptionService contains business logic for membership subscriptions.
+// Periods are billed in advance through the payment gateway; a period only
+// covers rentals once its charge has succeeded.
+type SubscriptionService struct {
+	repo repository.SubscriptionRepository
+	gw   gateway.Gateway
+}
+
+// NewSubscriptionService creates a new SubscriptionService.
+func NewSubscriptionService(repo repository.SubscriptionRepository, gw gateway.Gateway) *SubscriptionService {
+	return &SubscriptionService{repo: repo, gw: gw}
+}
+
+// GetPlan returns a subscription plan by ID.
+func (s *SubscriptionService) GetPlan(ctx context.Context, planID int32) (model.SubscriptionPlan, error) {
+	if planID <= 0 {
+		return model.SubscriptionPlan{}, fmt.Errorf("plan_id must be positive: %w", ErrInvalidArgument)
+	}
+
+	plan, err := s.repo.GetPlan(ctx, planID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.SubscriptionPlan{}, fmt.Errorf("plan %d: %w", planID, ErrNotFound)
+		}
+		return model.SubscriptionPlan{}, err
+	}
+	return plan, nil
+}
+
+// ListPlans returns the subscription plans, cheapest first.
+func (s *SubscriptionService) ListPlans(ctx context.Context, activeOnly bool) ([]model.SubscriptionPlan, error) {
+	return s.repo.ListPlans(ctx, activeOnly)
+}
+
+// CreatePlan creates a new subscription plan after validation.
+func (s *SubscriptionService) CreatePlan(ctx context.Context, params repository.SubscriptionPlanParams) (model.SubscriptionPlan, error) {
+	params, err := validatePlan(params)
+	if err != nil {
+		return model.SubscriptionPlan{}, err
+	}
+
+	plan, err := s.repo.CreatePlan(ctx, params)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return model.SubscriptionPlan{}, fmt.Errorf("plan %q: %w", params.Name, ErrAlreadyExists)
+		}
+		return model.SubscriptionPlan{}, err
+	}
+	return plan, nil
+}
+
+// UpdatePlan updates an existing subscription plan. Changes apply to
+// subscribers from their next billing period: each charged period keeps the
+// price and rental limits it was charged under.
+func (s *SubscriptionService) UpdatePlan(ctx context.Context, planID int32, params repository.SubscriptionPlanParams) (model.SubscriptionPlan, error) {
+	if planID <= 0 {
+		return model.SubscriptionPlan{}, fmt.Errorf("plan_id must be positive: %w", ErrInvalidArgument)
+	}
+	params, err := validatePlan(params)
+	if err != nil {
+		return model.SubscriptionPlan{}, err
+	}
+
+	plan, err := s.repo.UpdatePlan(ctx, planID, params)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.SubscriptionPlan{}, fmt.Errorf("plan %d: %w", planID, ErrNotFound)
+		}
+		if isUniqueViolation(err) {
+			return model.SubscriptionPlan{}, fmt.Errorf("plan %q: %w", params.Name, ErrAlreadyExists)
+		}
+		return model.SubscriptionPlan{}, err
+	}
+	return plan, nil
+}
+
+// GetSubscription returns a subscription by ID.
+func (s *SubscriptionService) GetSubscription(ctx context.Context, subscriptionID int32) (model.Subscription, error) {
+	if subscriptionID <= 0 {
+		return model.Subscription{}, fmt.Errorf("subscription_id must be positive: %w", ErrInvalidArgument)
+	}
+
+	sub, err := s.repo.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.Subscription{}, fmt.Errorf("subscription %d: %w", subscriptionID, ErrNotFound)
+		}
+		return model.Subscription{}, err
+	}
+	return sub, nil
+}
+
+// GetCurrentSubscription returns a customer's subscription that has not been canceled.
+func (s *SubscriptionService) GetCurrentSubscription(ctx context.Context, customerID int32) (model.Subscription, error) {
+	if customerID <= 0 {
+		return model.Subscription{}, fmt.Errorf("customer_id must be positive: %w", ErrInvalidArgument)
+	}
+
+	sub, err := s.repo.GetLiveSubscriptionByCustomer(ctx, customerID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.Subscription{}, fmt.Errorf("subscription for customer %d: %w", customerID, ErrNotFound)
+		}
+		return model.Subscription{}, err
+	}
+	return sub, nil
+}
+
+// ListSubscriptions returns a paginated list of subscriptions, optionally
+// filtered by customer (0 means any) and status ("" means any).
+func (s *SubscriptionService) ListSubscriptions(ctx context.Context, customerID int32, status string, pageSize, page int32) ([]model.Subscription, int64, error) {
+	if customerID < 0 {
+		return nil, 0, fmt.Errorf("customer_id must not be negative: %w", ErrInvalidArgument)
+	}
+	switch status {
+	case "", model.SubscriptionStatusPending, model.SubscriptionStatusActive, model.SubscriptionStatusPastDue,
+		model.SubscriptionStatusPaused, model.SubscriptionStatusCanceled:
+	default:
+		return nil, 0, fmt.Errorf("unknown status %q: %w", status, ErrInvalidArgument)
+	}
+
+	pageSize, page = clampPagination(pageSize, page)
+	offset := (page - 1) * pageSize
+
+	subs, err := s.repo.ListSubscriptions(ctx, customerID, status, pageSize, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.repo.CountSubscriptions(ctx, customerID, status)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return subs, total, nil
+}
+
+// ListPayments returns the charge attempts for a subscription, newest first.
+func (s *SubscriptionService) ListPayments(ctx context.Context, subscriptionID, pageSize, page int32) ([]model.SubscriptionPayment, int64, error) {
+	if subscriptionID <= 0 {
+		return nil, 0, fmt.Errorf("subscription_id must be positive: %w", ErrInvalidArgument)
+	}
+
+	pageSize, page = clampPagination(pageSize, page)
+	offset := (page - 1) * pageSize
+
+	payments, err := s.repo.ListPayments(ctx, subscriptionID, pageSize, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.repo.CountPayments(ctx, subscriptionID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return payments, total, nil
+}
+
+// Start subscribes a customer to a plan, charging the first period up front.
+// The subscription is recorded as pending before it is charged, so a customer
+// who already has one is never charged, and a declined charge cancels it.
+// When the outcome of the charge is unknown the subscription stays pending;
+// starting the same plan again retries the charge with the same idempotency
+// key.
+func (s *SubscriptionService) Start(ctx context.Context, customerID, planID int32) (model.Subscription, error) {
+	if customerID <= 0 {
+		return model.Subscription{}, fmt.Errorf("customer_id must be positive: %w", ErrInvalidArgument)
+	}
+
+	plan, err := s.GetPlan(ctx, planID)
+	if err != nil {
+		return model.Subscription{}, err
+	}
+	if !plan.Active {
+		return model.Subscription{}, fmt.Errorf("plan %d is not available: %w", planID, ErrInvalidArgument)
+	}
+
+	sub, err := s.repo.GetLiveSubscriptionByCustomer(ctx, customerID)
+	switch {
+	case err == nil:
+		if sub.Status != model.SubscriptionStatusPending || sub.PlanID != plan.PlanID {
+			return model.Subscription{}, fmt.Errorf("customer %d already has a subscription: %w", customerID, ErrAlreadyExists)
+		}
+	case errors.Is(err, repository.ErrNotFound):
+		now := time.Now()
+		sub, err = s.repo.CreateSubscription(ctx, model.Subscription{
+			CustomerID:         customerID,
+			PlanID:             plan.PlanID,
+			Status:             model.SubscriptionStatusPending,
+			CurrentPeriodStart: now,
+			CurrentPeriodEnd:   now,
+		})
+		if err != nil {
+			if isUniqueViolation(err) {
+				return model.Subscription{}, fmt.Errorf("customer %d already has a subscription: %w", customerID, ErrAlreadyExists)
+			}
+			if isForeignKeyViolation(err) {
+				return model.Subscription{}, fmt.Errorf("invalid customer_id: %w", ErrInvalidArgument)
+			}
+			return model.Subscription{}, err
+		}
+	default:
+		return model.Subscription{}, err
+	}
+
+	key := fmt.Sprintf("subscription-start-%d", sub.SubscriptionID)
+	payment, err := s.charge(ctx, customerID, plan, time.Now(), key)
+	if err != nil {
+		return model.Subscription{}, err
+	}
+
+	sub, err = s.update(ctx, sub.SubscriptionID, func(sub *model.Subscription) (*repository.SubscriptionPaymentParams, error) {
+		switch sub.Status {
+		case model.SubscriptionStatusPending:
+		case model.SubscriptionStatusCanceled:
+			// Canceled while the charge was in flight; keep the record.
+			return &payment, nil
+		default:
+			// Started by a concurrent retry, which recorded the same charge.
+			return nil, nil
+		}
+		applyPayment(sub, payment)
+		if payment.Status != model.SubscriptionPaymentSucceeded {
+			cancelNow(sub, time.Now())
+		}
+		return &payment, nil
+	})
+	if err != nil {
+		return model.Subscription{}, err
+	}
+	if payment.Status != model.SubscriptionPaymentSucceeded {
+		return model.Subscription{}, fmt.Errorf("%s: %w", payment.FailureReason, ErrPaymentDeclined)
+	}
+	return sub, nil
+}
+
+// Cancel cancels a subscription. Unless immediately is set, an active
+// subscription stays active until the end of the paid period. Subscriptions
+// that are past due or paused are always canceled immediately.
+func (s *SubscriptionService) Cancel(ctx context.Context, subscriptionID int32, immediately bool) (model.Subscription, error) {
+	return s.update(ctx, subscriptionID, func(sub *model.Subscription) (*repository.SubscriptionPaymentParams, error) {
+		if sub.Status == model.SubscriptionStatusCanceled {
+			return nil, fmt.Errorf("subscription %d is already canceled: %w", sub.SubscriptionID, ErrInvalidArgument)
+		}
+		if immediately || sub.Status != model.SubscriptionStatusActive {
+			cancelNow(sub, time.Now())
+			return nil, nil
+		}
+		sub.CancelAtPeriodEnd = true
+		sub.PauseAtPeriodEnd = false
+		return nil, nil
+	})
+}
+
+// Pause stops billing an active subscription at the end of the paid period.
+func (s *SubscriptionService) Pause(ctx context.Context, subscriptionID int32) (model.Subscription, error) {
+	return s.update(ctx, subscriptionID, func(sub *model.Subscription) (*repository.SubscriptionPaymentParams, error) {
+		if sub.Status != model.SubscriptionStatusActive {
+			return nil, fmt.Errorf("only active subscriptions can be paused, subscription %d is %s: %w", sub.SubscriptionID, sub.Status, ErrInvalidArgument)
+		}
+		sub.PauseAtPeriodEnd = true
+		sub.CancelAtPeriodEnd = false
+		return nil, nil
+	})
+}
+
+// Resume undoes a scheduled pause or cancellation of an active subscription,
+// or restarts a paused subscription by charging a new period from now.
+func (s *SubscriptionService) Resume(ctx context.Context, subscriptionID int32) (model.Subscription, error) {
+	return s.chargeNow(ctx, subscriptionID, func(sub *model.Subscription) (bool, error) {
+		switch sub.Status {
+		case model.SubscriptionStatusActive:
+			sub.PauseAtPeriodEnd = false
+			sub.CancelAtPeriodEnd = false
+			return false, nil
+		case model.SubscriptionStatusPaused:
+			return true, nil
+		default:
+			return false, fmt.Errorf("subscription %d is %s and cannot be resumed: %w", sub.SubscriptionID, sub.Status, ErrInvalidArgument)
+		}
+	})
+}
+
+// Renew retries billing a past due subscription now instead of waiting for
+// the billing job. A declined charge counts as a failed attempt.
+func (s *SubscriptionService) Renew(ctx context.Context, subscriptionID int32) (model.Subscription, error) {
+	return s.chargeNow(ctx, subscriptionID, func(sub *model.Subscription) (bool, error) {
+		if sub.Status != model.SubscriptionStatusPastDue {
+			return false, fmt.Errorf("only past due subscriptions can be renewed, subscription %d is %s: %w", sub.SubscriptionID, sub.Status, ErrInvalidArgument)
+		}
+		return true, nil
+	})
+}
+
+// RunBilling bills every subscription that is due at now. Scheduled pauses
+// and cancellations take effect, renewals are charged, and declined charges
+// are retried after billingRetryDelay until maxBillingAttempts is reached.
+// Subscriptions being billed by a concurrent run are skipped. A renewal is
+// claimed under the row lock and charged after the lock is released.
+func (s *SubscriptionService) RunBilling(ctx context.Context, now time.Time) (model.BillingRun, error) {
+	var run model.BillingRun
+	for {
+		if err := ctx.Err(); err != nil {
+			return run, err
+		}
+
+		var (
+			outcome *int32
+			due     model.Subscription
+		)
+		_, ok, err := s.repo.BillNextDue(ctx, now, func(sub *model.Subscription) (*repository.SubscriptionPaymentParams, error) {
+			switch {
+			case sub.CancelAtPeriodEnd:
+				cancelNow(sub, now)
+				outcome = &run.Canceled
+				return nil, nil
+			case sub.PauseAtPeriodEnd:
+				sub.Status = model.SubscriptionStatusPaused
+				sub.PauseAtPeriodEnd = false
+				sub.NextBillingAt = time.Time{}
+				outcome = &run.Paused
+				return nil, nil
+			}
+
+			due = *sub
+			sub.NextBillingAt = now.Add(billingClaimTimeout)
+			return nil, nil
+		})
+		if err != nil {
+			return run, err
+		}
+		if !ok {
+			return run, nil
+		}
+		if outcome == nil {
+			outcome, err = s.renew(ctx, due, now, &run)
+			if err != nil {
+				return run, err
+			}
+		}
+		if outcome != nil {
+			*outcome++
+		}
+	}
+}
+
+// renew charges the next period of a subscription claimed by RunBilling and
+// records the outcome, returning the run counter it falls under, if any. If
+// the charge or the record fails, the claim expires and a later run retries
+// with the same idempotency key.
+func (s *SubscriptionService) renew(ctx context.Context, due model.Subscription, now time.Time, run *model.BillingRun) (*int32, error) {
+	plan, err := s.repo.GetPlan(ctx, due.PlanID)
+	if err != nil {
+		return nil, fmt.Errorf("get plan %d: %w", due.PlanID, err)
+	}
+
+	// Renew back to back, unless a whole period has been missed.
+	start := due.CurrentPeriodEnd
+	if !start.AddDate(0, int(plan.IntervalMonths), 0).After(now) {
+		start = now
+	}
+	payment, err := s.charge(ctx, due.CustomerID, plan, start, renewalKey(due))
+	if err != nil {
+		return nil, err
+	}
+
+	var outcome *int32
+	_, err = s.repo.UpdateSubscription(ctx, due.SubscriptionID, func(sub *model.Subscription) (*repository.SubscriptionPaymentParams, error) {
+		if renewalKey(*sub) != renewalKey(due) {
+			// Renewed meanwhile, which recorded the same charge.
+			return nil, nil
+		}
+		if sub.Status == model.SubscriptionStatusCanceled {
+			// Canceled while the charge was in flight; keep the record.
+			return &payment, nil
+		}
+
+		applyPayment(sub, payment)
+		switch {
+		case payment.Status == model.SubscriptionPaymentSucceeded:
+			outcome = &run.Renewed
+		case sub.FailedAttempts >= maxBillingAttempts:
+			cancelNow(sub, now)
+			outcome = &run.Canceled
+		default:
+			sub.Status = model.SubscriptionStatusPastDue
+			sub.NextBillingAt = now.Add(billingRetryDelay)
+			outcome = &run.Failed
+		}
+		return &payment, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return outcome, nil
+}
+
+func (s *SubscriptionService) update(ctx context.Context, subscriptionID int32, update repository.SubscriptionUpdate) (model.Subscription, error) {
+	if subscriptionID <= 0 {
+		return model.Subscription{}, fmt.Errorf("subscription_id must be positive: %w", ErrInvalidArgument)
+	}
+
+	sub, err := s.repo.UpdateSubscription(ctx, subscriptionID, update)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.Subscription{}, fmt.Errorf("subscription %d: %w", subscriptionID, ErrNotFound)
+		}
+		return model.Subscription{}, err
+	}
+	return sub, nil
+}
+
+// chargeNow locks a subscription and, when check reports a charge is needed,
+// bills a new period starting now. Like renew, the charge is claimed under
+// the row lock and made after the lock is released, then recorded in a
+// second update. The attempt is recorded either way, and a declined charge
+// is reported as ErrPaymentDeclined.
+func (s *SubscriptionService) chargeNow(ctx context.Context, subscriptionID int32, check func(sub *model.Subscription) (bool, error)) (model.Subscription, error) {
+	var (
+		due     model.Subscription
+		claimed bool
+	)
+	sub, err := s.update(ctx, subscriptionID, func(sub *model.Subscription) (*repository.SubscriptionPaymentParams, error) {
+		needed, err := check(sub)
+		if err != nil || !needed {
+			return nil, err
+		}
+		due = *sub
+		claimed = true
+		// Keep the billing job off a past due subscription while it is
+		// charged; if the charge is never recorded the claim expires.
+		if !sub.NextBillingAt.IsZero() {
+			sub.NextBillingAt = time.Now().Add(billingClaimTimeout)
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return model.Subscription{}, err
+	}
+	if !claimed {
+		return sub, nil
+	}
+
+	plan, err := s.repo.GetPlan(ctx, due.PlanID)
+	if err != nil {
+		return model.Subscription{}, fmt.Errorf("get plan %d: %w", due.PlanID, err)
+	}
+	payment, err := s.charge(ctx, due.CustomerID, plan, time.Now(), renewalKey(due))
+	if err != nil {
+		return model.Subscription{}, err
+	}
+
+	sub, err = s.update(ctx, subscriptionID, func(sub *model.Subscription) (*repository.SubscriptionPaymentParams, error) {
+		if renewalKey(*sub) != renewalKey(due) {
+			// Renewed meanwhile, which recorded the same charge.
+			return nil, nil
+		}
+		if sub.Status == model.SubscriptionStatusCanceled {
+			// Canceled while the charge was in flight; keep the record.
+			return &payment, nil
+		}
+		applyPayment(sub, payment)
+		if payment.Status != model.SubscriptionPaymentSucceeded {
+			sub.NextBillingAt = due.NextBillingAt
+		}
+		return &payment, nil
+	})
+	if err != nil {
+		return model.Subscription{}, err
+	}
+	if payment.Status != model.SubscriptionPaymentSucceeded {
+		return model.Subscription{}, fmt.Errorf("%s: %w", payment.FailureReason, ErrPaymentDeclined)
+	}
+	return sub, nil
+}
+
+// charge bills the plan's price for the period starting at start. A decline
+// is returned as a failed payment; other errors leave the outcome unknown,
+// to be retried with the same key.
+func (s *SubscriptionService) charge(ctx context.Context, customerID int32, plan model.SubscriptionPlan, start time.Time, key string) (repository.SubscriptionPaymentParams, error) {
+	end := start.AddDate(0, int(plan.IntervalMonths), 0)
+	payment := repository.SubscriptionPaymentParams{
+		Amount:               plan.Price,
+		PeriodStart:          start,
+		PeriodEnd:            end,
+		MaxConcurrentRentals: plan.MaxConcurrentRentals,
+		MaxRentalsPerPeriod:  plan.MaxRentalsPerPeriod,
+	}
+
+	charge, err := s.gw.Charge(ctx, gateway.ChargeRequest{
+		CustomerID:     customerID,
+		Amount:         plan.Price,
+		Description:    fmt.Sprintf("%s subscription %s to %s", plan.Name, start.Format(time.DateOnly), end.Format(time.DateOnly)),
+		IdempotencyKey: key,
+	})
+	if err != nil {
+		if !errors.Is(err, gateway.ErrDeclined) {
+			return repository.SubscriptionPaymentParams{}, fmt.Errorf("charge subscription: %w", err)
+		}
+		payment.Status = model.SubscriptionPaymentFailed
+		payment.FailureReason = err.Error()
+		return payment, nil
+	}
+
+	payment.Status = model.SubscriptionPaymentSucceeded
+	payment.GatewayReference = charge.Reference
+	return payment, nil
+}
+
+// applyPayment updates sub for a charge attempt. On success sub becomes
+// active for the charged period; a decline counts as a failed attempt and
+// leaves the period unchanged.
+func applyPayment(sub *model.Subscription, payment repository.SubscriptionPaymentParams) {
+	if payment.Status != model.SubscriptionPaymentSucceeded {
+		sub.FailedAttempts++
+		return
+	}
+	sub.Status = model.SubscriptionStatusActive
+	sub.CurrentPeriodStart = payment.PeriodStart
+	sub.CurrentPeriodEnd = payment.PeriodEnd
+	sub.FailedAttempts = 0
+	sub.NextBillingAt = payment.PeriodEnd
+}
+
+// renewalKey is the idempotency key for charging the period after sub's
+// current one. Each attempt gets its own key, while a billing run and a
+// manual renewal of the same attempt share one and cannot both charge.
+func renewalKey(sub model.Subscription) string {
+	return fmt.Sprintf("subscription-%d-%d-%d", sub.SubscriptionID, sub.CurrentPeriodEnd.Unix(), sub.FailedAttempts)
+}
+
+func cancelNow(sub *model.Subscription, now time.Time) {
+	sub.Status = model.SubscriptionStatusCanceled
+	sub.CanceledAt = now
+	sub.NextBillingAt = time.Time{}
+	sub.CancelAtPeriodEnd = false
+	sub.PauseAtPeriodEnd = false
+}
+
+func validatePlan(params repository.SubscriptionPlanParams) (repository.SubscriptionPlanParams, error) {
+	params.Name = strings.TrimSpace(params.Name)
+	params.Description = strings.TrimSpace(params.Description)
+	if params.Name == "" {
+		return params, fmt.Errorf("name must not be empty: %w", ErrInvalidArgument)
+	}
+
+	cents, err := parseCents(params.Price)
+	if err != nil || cents <= 0 {
+		return params, fmt.Errorf("price must be a positive amount: %w", ErrInvalidArgument)
+	}
+	if cents > maxPlanCents {
+		return params, fmt.Errorf("price must be at most %s: %w", formatCents(maxPlanCents), ErrInvalidArgument)
+	}
+	params.Price = formatCents(cents)
+
+	if params.IntervalMonths < 1 || params.IntervalMonths > 12 {
+		return params, fmt.Errorf("interval_months must be between 1 and 12: %w", ErrInvalidArgument)
+	}
+	if params.MaxConcurrentRentals < 0 {
+		return params, fmt.Errorf("max_concurrent_rentals must not be negative: %w", ErrInvalidArgument)
+	}
+	if params.MaxRentalsPerPeriod < 0 {
+		return params, fmt.Errorf("max_rentals_per_period must not be negative: %w", ErrInvalidArgument)
+	}
+	return params, nil
+}
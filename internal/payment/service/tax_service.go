@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/enkaigaku/dvd-rental/internal/payment/model"
+	"github.com/enkaigaku/dvd-rental/internal/payment/repository"
+)
+
+// TaxService is the sales tax engine. Charges are taxed at the sum of the
+// active rates configured for the country and city of the store the charge
+// is made at.
+type TaxService struct {
+	repo repository.TaxRepository
+}
+
+// NewTaxService creates a new TaxService.
+func NewTaxService(repo repository.TaxRepository) *TaxService {
+	return &TaxService{repo: repo}
+}
+
+// taxAssessment is the tax due on a net amount.
+type taxAssessment struct {
+	lines []model.TaxLine
+	total int64 // cents
+}
+
+// GetTaxRate returns a tax rate by ID.
+func (s *TaxService) GetTaxRate(ctx context.Context, taxRateID int32) (model.TaxRate, error) {
+	if taxRateID <= 0 {
+		return model.TaxRate{}, fmt.Errorf("tax_rate_id must be positive: %w", ErrInvalidArgument)
+	}
+
+	rate, err := s.repo.GetTaxRate(ctx, taxRateID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.TaxRate{}, fmt.Errorf("tax rate %d: %w", taxRateID, ErrNotFound)
+		}
+		return model.TaxRate{}, err
+	}
+	return rate, nil
+}
+
+// ListTaxRates returns the tax rates, optionally only those of one country.
+func (s *TaxService) ListTaxRates(ctx context.Context, countryID int32) ([]model.TaxRate, error) {
+	if countryID < 0 {
+		return nil, fmt.Errorf("country_id must not be negative: %w", ErrInvalidArgument)
+	}
+	return s.repo.ListTaxRates(ctx, countryID)
+}
+
+// CreateTaxRate creates a new tax rate after validation.
+func (s *TaxService) CreateTaxRate(ctx context.Context, params repository.TaxRateParams) (model.TaxRate, error) {
+	params, err := s.validateTaxRate(ctx, params)
+	if err != nil {
+		return model.TaxRate{}, err
+	}
+
+	rate, err := s.repo.CreateTaxRate(ctx, params)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return model.TaxRate{}, fmt.Errorf("invalid country_id or city_id: %w", ErrInvalidArgument)
+		}
+		return model.TaxRate{}, err
+	}
+	return rate, nil
+}
+
+// UpdateTaxRate replaces a tax rate. Payments already taken keep the tax
+// they were charged.
+func (s *TaxService) UpdateTaxRate(ctx context.Context, taxRateID int32, params repository.TaxRateParams) (model.TaxRate, error) {
+	if taxRateID <= 0 {
+		return model.TaxRate{}, fmt.Errorf("tax_rate_id must be positive: %w", ErrInvalidArgument)
+	}
+	params, err := s.validateTaxRate(ctx, params)
+	if err != nil {
+		return model.TaxRate{}, err
+	}
+
+	rate, err := s.repo.UpdateTaxRate(ctx, taxRateID, params)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.TaxRate{}, fmt.Errorf("tax rate %d: %w", taxRateID, ErrNotFound)
+		}
+		if isForeignKeyViolation(err) {
+			return model.TaxRate{}, fmt.Errorf("invalid country_id or city_id: %w", ErrInvalidArgument)
+		}
+		return model.TaxRate{}, err
+	}
+	return rate, nil
+}
+
+// DeleteTaxRate deletes a tax rate that has never been charged. Rates that
+// have been charged can only be deactivated.
+func (s *TaxService) DeleteTaxRate(ctx context.Context, taxRateID int32) error {
+	if taxRateID <= 0 {
+		return fmt.Errorf("tax_rate_id must be positive: %w", ErrInvalidArgument)
+	}
+
+	if err := s.repo.DeleteTaxRate(ctx, taxRateID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("tax rate %d: %w", taxRateID, ErrNotFound)
+		}
+		if isForeignKeyViolation(err) {
+			return fmt.Errorf("tax rate %d has been charged, deactivate it instead: %w", taxRateID, ErrForeignKey)
+		}
+		return err
+	}
+	return nil
+}
+
+// assess computes the tax on net cents for a charge of chargeType at a store.
+// Each rate is rounded separately, half up.
+func (s *TaxService) assess(ctx context.Context, storeID int32, chargeType string, net int64) (taxAssessment, error) {
+	rates, err := s.repo.ListStoreTaxRates(ctx, storeID, chargeType)
+	if err != nil {
+		return taxAssessment{}, err
+	}
+
+	assessment := taxAssessment{lines: []model.TaxLine{}}
+	for _, r := range rates {
+		pct, err := parseCents(r.Rate)
+		if err != nil {
+			return taxAssessment{}, fmt.Errorf("tax rate %d: %w", r.TaxRateID, err)
+		}
+		amount := percentOf(net, pct)
+		if amount == 0 {
+			continue
+		}
+		assessment.total += amount
+		assessment.lines = append(assessment.lines, model.TaxLine{
+			TaxRateID: r.TaxRateID,
+			Name:      r.Name,
+			Rate:      r.Rate,
+			Amount:    formatCents(amount),
+		})
+	}
+	return assessment, nil
+}
+
+// paymentTaxes returns the tax lines charged on a payment.
+func (s *TaxService) paymentTaxes(ctx context.Context, paymentID int32) ([]model.TaxLine, error) {
+	return s.repo.ListPaymentTaxes(ctx, paymentID)
+}
+
+func (s *TaxService) validateTaxRate(ctx context.Context, params repository.TaxRateParams) (repository.TaxRateParams, error) {
+	params.Name = strings.TrimSpace(params.Name)
+	if params.Name == "" {
+		return params, fmt.Errorf("name must not be empty: %w", ErrInvalidArgument)
+	}
+	if params.CountryID <= 0 {
+		return params, fmt.Errorf("country_id must be positive: %w", ErrInvalidArgument)
+	}
+	if params.CityID < 0 {
+		return params, fmt.Errorf("city_id must not be negative: %w", ErrInvalidArgument)
+	}
+	if err := validateChargeType(params.ChargeType, true); err != nil {
+		return params, err
+	}
+
+	pct, err := parseCents(params.Rate)
+	if err != nil || pct > 100_00 {
+		return params, fmt.Errorf("rate must be a percentage between 0 and 100: %w", ErrInvalidArgument)
+	}
+	params.Rate = formatCents(pct)
+
+	if params.CityID != 0 {
+		countryID, err := s.repo.GetCityCountry(ctx, params.CityID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return params, fmt.Errorf("city %d does not exist: %w", params.CityID, ErrInvalidArgument)
+			}
+			return params, err
+		}
+		if countryID != params.CountryID {
+			return params, fmt.Errorf("city %d is not in country %d: %w", params.CityID, params.CountryID, ErrInvalidArgument)
+		}
+	}
+	return params, nil
+}
+
+// validateChargeType checks chargeType is a known charge type, or empty
+// when allowEmpty is set.
+func validateChargeType(chargeType string, allowEmpty bool) error {
+	switch chargeType {
+	case model.ChargeTypeRental, model.ChargeTypeLateFee, model.ChargeTypeReplacement:
+		return nil
+	case "":
+		if allowEmpty {
+			return nil
+		}
+	}
+	return fmt.Errorf("charge_type must be one of %s, %s or %s: %w",
+		model.ChargeTypeRental, model.ChargeTypeLateFee, model.ChargeTypeReplacement, ErrInvalidArgument)
+}
@@ -89,6 +89,14 @@ func (h *RentalHandler) DeleteRental(ctx context.Context, req *rentalv1.DeleteRe
 	return &emptypb.Empty{}, nil
 }
 
+func (h *RentalHandler) HasCustomerRentedFilm(ctx context.Context, req *rentalv1.HasCustomerRentedFilmRequest) (*rentalv1.HasCustomerRentedFilmResponse, error) {
+	rented, err := h.svc.HasCustomerRentedFilm(ctx, req.GetCustomerId(), req.GetFilmId())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &rentalv1.HasCustomerRentedFilmResponse{Rented: rented}, nil
+}
+
 func toRentalListResponse(rentals []model.Rental, total int64) *rentalv1.ListRentalsResponse {
 	protos := make([]*rentalv1.Rental, len(rentals))
 	for i, r := range rentals {
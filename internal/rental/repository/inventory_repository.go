@@ -29,6 +29,7 @@ type InventoryRepository interface {
 	CountInventoryByStore(ctx context.Context, storeID int32) (int64, error)
 	ListAvailableInventory(ctx context.Context, filmID, storeID, limit, offset int32) ([]model.Inventory, error)
 	CountAvailableInventory(ctx context.Context, filmID, storeID int32) (int64, error)
+	IsFilmArchived(ctx context.Context, filmID int32) (bool, error)
 	CreateInventory(ctx context.Context, params CreateInventoryParams) (model.Inventory, error)
 	DeleteInventory(ctx context.Context, inventoryID int32) error
 }
@@ -125,6 +126,19 @@ func (r *inventoryRepository) CountAvailableInventory(ctx context.Context, filmI
 	return count, nil
 }
 
+// IsFilmArchived reports whether a film is archived. A film that does not
+// exist is not archived.
+func (r *inventoryRepository) IsFilmArchived(ctx context.Context, filmID int32) (bool, error) {
+	archived, err := r.q.IsFilmArchived(ctx, filmID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("is film archived: %w", err)
+	}
+	return archived, nil
+}
+
 func (r *inventoryRepository) CreateInventory(ctx context.Context, params CreateInventoryParams) (model.Inventory, error) {
 	row, err := r.q.CreateInventory(ctx, rentalsqlc.CreateInventoryParams{
 		FilmID:  params.FilmID,
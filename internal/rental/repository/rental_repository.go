@@ -39,6 +39,7 @@ type RentalRepository interface {
 	GetCustomerName(ctx context.Context, customerID int32) (string, error)
 	GetFilmTitleByInventory(ctx context.Context, inventoryID int32) (string, int32, error)
 	IsInventoryAvailable(ctx context.Context, inventoryID int32) (bool, error)
+	HasCustomerRentedFilm(ctx context.Context, customerID, filmID int32) (bool, error)
 }
 
 type rentalRepository struct {
@@ -193,6 +194,17 @@ func (r *rentalRepository) IsInventoryAvailable(ctx context.Context, inventoryID
 	return available, nil
 }
 
+func (r *rentalRepository) HasCustomerRentedFilm(ctx context.Context, customerID, filmID int32) (bool, error) {
+	rented, err := r.q.HasCustomerRentedFilm(ctx, rentalsqlc.HasCustomerRentedFilmParams{
+		CustomerID: customerID,
+		FilmID:     filmID,
+	})
+	if err != nil {
+		return false, fmt.Errorf("has customer rented film: %w", err)
+	}
+	return rented, nil
+}
+
 func toRentalModel(r rentalsqlc.Rental) model.Rental {
 	return model.Rental{
 		RentalID:    r.RentalID,
@@ -147,7 +147,8 @@ func (s *InventoryService) ListAvailableInventory(ctx context.Context, filmID, s
 	return items, total, nil
 }
 
-// CreateInventory creates a new inventory item.
+// CreateInventory creates a new inventory item. Archived films get no new
+// inventory.
 func (s *InventoryService) CreateInventory(ctx context.Context, params repository.CreateInventoryParams) (model.Inventory, error) {
 	if params.FilmID <= 0 {
 		return model.Inventory{}, fmt.Errorf("film_id must be positive: %w", ErrInvalidArgument)
@@ -156,6 +157,14 @@ func (s *InventoryService) CreateInventory(ctx context.Context, params repositor
 		return model.Inventory{}, fmt.Errorf("store_id must be positive: %w", ErrInvalidArgument)
 	}
 
+	archived, err := s.inventoryRepo.IsFilmArchived(ctx, params.FilmID)
+	if err != nil {
+		return model.Inventory{}, err
+	}
+	if archived {
+		return model.Inventory{}, fmt.Errorf("film %d is archived: %w", params.FilmID, ErrForeignKey)
+	}
+
 	inv, err := s.inventoryRepo.CreateInventory(ctx, params)
 	if err != nil {
 		if isForeignKeyViolation(err) {
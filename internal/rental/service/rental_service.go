@@ -98,6 +98,18 @@ func (s *RentalService) ListRentalsByCustomer(ctx context.Context, customerID, p
 	return rentals, total, nil
 }
 
+// HasCustomerRentedFilm reports whether a customer has ever rented any copy
+// of a film.
+func (s *RentalService) HasCustomerRentedFilm(ctx context.Context, customerID, filmID int32) (bool, error) {
+	if customerID <= 0 {
+		return false, fmt.Errorf("customer_id must be positive: %w", ErrInvalidArgument)
+	}
+	if filmID <= 0 {
+		return false, fmt.Errorf("film_id must be positive: %w", ErrInvalidArgument)
+	}
+	return s.rentalRepo.HasCustomerRentedFilm(ctx, customerID, filmID)
+}
+
 // ListRentalsByInventory returns rentals for a given inventory item.
 func (s *RentalService) ListRentalsByInventory(ctx context.Context, inventoryID, pageSize, page int32) ([]model.Rental, int64, error) {
 	if inventoryID <= 0 {
@@ -0,0 +1,44 @@
+-- Promotions, discount codes and pricing rules for rental payments
+-- The payment service pricing engine evaluates these when quoting a rental
+-- and when a rental payment is created.
+
+-- kind:
+--   percentage  value is percent off the rental rate (e.g. 10.00 = 10%)
+--   fixed       value is an amount off the rental rate (e.g. 1.00)
+--   bundle      "rent buy_quantity, pay pay_quantity" within the promotion window
+-- A promotion with a NULL code is applied automatically when eligible
+-- (e.g. category-wide sales); otherwise the customer must present the code.
+CREATE TABLE IF NOT EXISTS promotion (
+    promotion_id          SERIAL PRIMARY KEY,
+    name                  TEXT NOT NULL,
+    code                  TEXT,
+    kind                  TEXT NOT NULL CHECK (kind IN ('percentage', 'fixed', 'bundle')),
+    value                 NUMERIC(5,2) NOT NULL DEFAULT 0,
+    buy_quantity          INTEGER NOT NULL DEFAULT 0,
+    pay_quantity          INTEGER NOT NULL DEFAULT 0,
+    category_id           INTEGER REFERENCES category(category_id),
+    store_id              INTEGER REFERENCES store(store_id),
+    starts_at             TIMESTAMP WITH TIME ZONE,
+    ends_at               TIMESTAMP WITH TIME ZONE,
+    max_uses_per_customer INTEGER NOT NULL DEFAULT 0,
+    active                BOOLEAN NOT NULL DEFAULT true,
+    last_update           TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+-- Codes are matched case-insensitively
+CREATE UNIQUE INDEX IF NOT EXISTS idx_unq_promotion_code ON promotion (upper(code));
+
+-- Record of which promotions were applied to which payment.
+-- payment is partitioned by payment_date, so payment_id cannot be a foreign key.
+CREATE TABLE IF NOT EXISTS payment_promotion (
+    payment_id   INTEGER NOT NULL,
+    promotion_id INTEGER NOT NULL REFERENCES promotion(promotion_id),
+    customer_id  INTEGER NOT NULL REFERENCES customer(customer_id),
+    discount     NUMERIC(5,2) NOT NULL,
+    applied_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (payment_id, promotion_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_promotion_customer ON payment_promotion (promotion_id, customer_id);
+
+CREATE TRIGGER last_updated BEFORE UPDATE ON promotion FOR EACH ROW EXECUTE FUNCTION last_updated();
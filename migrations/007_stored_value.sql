@@ -0,0 +1,52 @@
+-- Gift cards and store credit (stored value) for the payment service
+-- Every movement of value is a transaction with balanced entries
+-- (double entry): entries of one transaction always sum to zero.
+-- Money enters the ledger from the SYSTEM-ISSUANCE account and leaves it
+-- into SYSTEM-REDEMPTION when spent on a payment.
+
+CREATE TABLE IF NOT EXISTS stored_value_account (
+    account_id   SERIAL PRIMARY KEY,
+    kind         TEXT NOT NULL CHECK (kind IN ('gift_card', 'store_credit', 'system')),
+    code         TEXT NOT NULL UNIQUE,
+    customer_id  INTEGER REFERENCES customer(customer_id),
+    balance      NUMERIC(7,2) NOT NULL DEFAULT 0,
+    active       BOOLEAN NOT NULL DEFAULT true,
+    create_date  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    last_update  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    -- Only system accounts may go negative; this is the last line of defence
+    -- behind the conditional debit in the payment service.
+    CONSTRAINT stored_value_balance_non_negative CHECK (kind = 'system' OR balance >= 0)
+);
+
+-- One store credit account per customer
+CREATE UNIQUE INDEX IF NOT EXISTS idx_unq_store_credit_customer
+    ON stored_value_account (customer_id) WHERE kind = 'store_credit';
+
+CREATE INDEX IF NOT EXISTS idx_stored_value_account_customer ON stored_value_account (customer_id);
+
+CREATE TABLE IF NOT EXISTS stored_value_transaction (
+    transaction_id SERIAL PRIMARY KEY,
+    kind           TEXT NOT NULL CHECK (kind IN ('issue', 'redeem', 'transfer')),
+    payment_id     INTEGER,
+    staff_id       INTEGER REFERENCES staff(staff_id),
+    note           TEXT,
+    created_at     TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS stored_value_entry (
+    entry_id       SERIAL PRIMARY KEY,
+    transaction_id INTEGER NOT NULL REFERENCES stored_value_transaction(transaction_id),
+    account_id     INTEGER NOT NULL REFERENCES stored_value_account(account_id),
+    amount         NUMERIC(7,2) NOT NULL CHECK (amount <> 0),
+    balance_after  NUMERIC(7,2) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_stored_value_entry_account ON stored_value_entry (account_id, entry_id);
+
+CREATE TRIGGER last_updated BEFORE UPDATE ON stored_value_account FOR EACH ROW EXECUTE FUNCTION last_updated();
+
+-- System accounts
+INSERT INTO stored_value_account (kind, code) VALUES
+    ('system', 'SYSTEM-ISSUANCE'),
+    ('system', 'SYSTEM-REDEMPTION')
+ON CONFLICT (code) DO NOTHING;
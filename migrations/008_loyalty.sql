@@ -0,0 +1,75 @@
+-- Loyalty points program for the payment service
+-- Customers earn points on every payment and redeem them for free rentals.
+-- Tiers are recalculated from monthly activity with Pagila's rewards_report()
+-- and raise the rate at which points are earned.
+
+CREATE TABLE IF NOT EXISTS loyalty_tier (
+    tier                  TEXT PRIMARY KEY,
+    rank                  INTEGER NOT NULL UNIQUE,
+    -- rewards_report() thresholds; the lowest ranked tier is the default and
+    -- is not looked up in the report.
+    min_monthly_purchases INTEGER NOT NULL DEFAULT 0,
+    min_dollar_amount     NUMERIC(5,2) NOT NULL DEFAULT 0,
+    points_multiplier     NUMERIC(3,2) NOT NULL DEFAULT 1 CHECK (points_multiplier > 0),
+    last_update           TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE TRIGGER last_updated BEFORE UPDATE ON loyalty_tier FOR EACH ROW EXECUTE FUNCTION last_updated();
+
+INSERT INTO loyalty_tier (tier, rank, min_monthly_purchases, min_dollar_amount, points_multiplier) VALUES
+    ('bronze', 1, 0, 0.00, 1.00),
+    ('silver', 2, 4, 10.00, 1.25),
+    ('gold',   3, 7, 20.00, 1.50)
+ON CONFLICT (tier) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS loyalty_account (
+    customer_id        INTEGER PRIMARY KEY REFERENCES customer(customer_id),
+    tier               TEXT NOT NULL DEFAULT 'bronze' REFERENCES loyalty_tier(tier),
+    points_balance     INTEGER NOT NULL DEFAULT 0 CHECK (points_balance >= 0),
+    lifetime_points    INTEGER NOT NULL DEFAULT 0,
+    tier_calculated_at TIMESTAMP WITH TIME ZONE,
+    create_date        TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    last_update        TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_loyalty_account_tier ON loyalty_account (tier);
+
+CREATE TRIGGER last_updated BEFORE UPDATE ON loyalty_account FOR EACH ROW EXECUTE FUNCTION last_updated();
+
+CREATE TABLE IF NOT EXISTS loyalty_transaction (
+    loyalty_transaction_id SERIAL PRIMARY KEY,
+    customer_id            INTEGER NOT NULL REFERENCES customer(customer_id),
+    kind                   TEXT NOT NULL CHECK (kind IN ('earn', 'redeem', 'adjust')),
+    points                 INTEGER NOT NULL CHECK (points <> 0), -- negative when spent
+    balance_after          INTEGER NOT NULL,
+    payment_id             INTEGER,
+    rental_id              INTEGER REFERENCES rental(rental_id),
+    note                   TEXT,
+    created_at             TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_loyalty_transaction_customer
+    ON loyalty_transaction (customer_id, loyalty_transaction_id);
+
+-- Opening balances: 10 points per dollar of payment history.
+INSERT INTO loyalty_account (customer_id, points_balance, lifetime_points)
+SELECT c.customer_id,
+       COALESCE(floor(sum(p.amount) * 10), 0)::int,
+       COALESCE(floor(sum(p.amount) * 10), 0)::int
+FROM customer c
+LEFT JOIN payment p ON p.customer_id = c.customer_id
+GROUP BY c.customer_id
+ON CONFLICT (customer_id) DO NOTHING;
+
+INSERT INTO loyalty_transaction (customer_id, kind, points, balance_after, note)
+SELECT a.customer_id, 'adjust', a.points_balance, a.points_balance, 'Opening balance from payment history'
+FROM loyalty_account a
+WHERE a.points_balance > 0
+  AND NOT EXISTS (SELECT 1 FROM loyalty_transaction t WHERE t.customer_id = a.customer_id);
+
+-- Initial tiers from rewards_report(), lowest tier first so higher tiers win.
+UPDATE loyalty_account SET tier = 'silver', tier_calculated_at = now()
+WHERE customer_id IN (SELECT customer_id FROM rewards_report(4, 10.00));
+
+UPDATE loyalty_account SET tier = 'gold', tier_calculated_at = now()
+WHERE customer_id IN (SELECT customer_id FROM rewards_report(7, 20.00));
@@ -0,0 +1,68 @@
+-- Membership subscriptions for the payment service
+-- Subscribed customers rent without paying rental_rate, within their plan's
+-- limits. Plans are billed in advance through the payment gateway by the
+-- recurring billing job; every charge attempt is kept in subscription_payment.
+
+CREATE TABLE IF NOT EXISTS subscription_plan (
+    plan_id                SERIAL PRIMARY KEY,
+    name                   TEXT NOT NULL UNIQUE,
+    description            TEXT,
+    price                  NUMERIC(5,2) NOT NULL CHECK (price > 0),
+    interval_months        INTEGER NOT NULL DEFAULT 1 CHECK (interval_months > 0),
+    max_concurrent_rentals INTEGER NOT NULL DEFAULT 0 CHECK (max_concurrent_rentals >= 0), -- 0 = unlimited
+    max_rentals_per_period INTEGER NOT NULL DEFAULT 0 CHECK (max_rentals_per_period >= 0), -- 0 = unlimited
+    active                 BOOLEAN NOT NULL DEFAULT true,
+    last_update            TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE TRIGGER last_updated BEFORE UPDATE ON subscription_plan FOR EACH ROW EXECUTE FUNCTION last_updated();
+
+INSERT INTO subscription_plan (name, description, price, interval_months, max_concurrent_rentals, max_rentals_per_period) VALUES
+    ('Unlimited 2', 'Unlimited rentals, 2 at a time', 14.99, 1, 2, 0),
+    ('Unlimited 4', 'Unlimited rentals, 4 at a time', 24.99, 1, 4, 0),
+    ('Casual', '8 rentals a month, 1 at a time', 7.99, 1, 1, 8)
+ON CONFLICT (name) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS subscription (
+    subscription_id       SERIAL PRIMARY KEY,
+    customer_id           INTEGER NOT NULL REFERENCES customer(customer_id),
+    plan_id               INTEGER NOT NULL REFERENCES subscription_plan(plan_id),
+    status                TEXT NOT NULL CHECK (status IN ('active', 'past_due', 'paused', 'canceled')),
+    current_period_start  TIMESTAMP WITH TIME ZONE NOT NULL,
+    current_period_end    TIMESTAMP WITH TIME ZONE NOT NULL,
+    cancel_at_period_end  BOOLEAN NOT NULL DEFAULT false,
+    pause_at_period_end   BOOLEAN NOT NULL DEFAULT false,
+    failed_attempts       INTEGER NOT NULL DEFAULT 0,
+    next_billing_at       TIMESTAMP WITH TIME ZONE, -- NULL when not billed (paused, canceled)
+    canceled_at           TIMESTAMP WITH TIME ZONE,
+    create_date           TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    last_update           TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+-- At most one live subscription per customer
+CREATE UNIQUE INDEX IF NOT EXISTS idx_unq_subscription_customer
+    ON subscription (customer_id) WHERE status <> 'canceled';
+
+CREATE INDEX IF NOT EXISTS idx_subscription_next_billing
+    ON subscription (next_billing_at) WHERE next_billing_at IS NOT NULL;
+
+CREATE TRIGGER last_updated BEFORE UPDATE ON subscription FOR EACH ROW EXECUTE FUNCTION last_updated();
+
+CREATE TABLE IF NOT EXISTS subscription_payment (
+    subscription_payment_id SERIAL PRIMARY KEY,
+    subscription_id         INTEGER NOT NULL REFERENCES subscription(subscription_id),
+    customer_id             INTEGER NOT NULL REFERENCES customer(customer_id),
+    amount                  NUMERIC(5,2) NOT NULL,
+    period_start            TIMESTAMP WITH TIME ZONE NOT NULL,
+    period_end              TIMESTAMP WITH TIME ZONE NOT NULL,
+    status                  TEXT NOT NULL CHECK (status IN ('succeeded', 'failed')),
+    gateway_reference       TEXT,
+    failure_reason          TEXT,
+    created_at              TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_subscription_payment_subscription
+    ON subscription_payment (subscription_id, subscription_payment_id);
+
+CREATE INDEX IF NOT EXISTS idx_subscription_payment_customer_period
+    ON subscription_payment (customer_id, period_start) WHERE status = 'succeeded';
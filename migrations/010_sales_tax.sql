@@ -0,0 +1,52 @@
+-- Sales tax for the payment service
+-- Tax is charged on top of the net amount of a rental fee, late fee or
+-- replacement charge, at the rates configured for the jurisdiction of the
+-- store the rental was made at (store.address_id -> city -> country).
+
+-- A rate applies to a whole country, or to one city when city_id is set,
+-- and to every charge type unless charge_type is set. All rates that apply
+-- to a charge are added together (e.g. a national and a city sales tax).
+-- rate is a percentage (e.g. 8.25 = 8.25%).
+CREATE TABLE IF NOT EXISTS tax_rate (
+    tax_rate_id SERIAL PRIMARY KEY,
+    name        TEXT NOT NULL,
+    country_id  INTEGER NOT NULL REFERENCES country(country_id),
+    city_id     INTEGER REFERENCES city(city_id),
+    charge_type TEXT CHECK (charge_type IN ('rental', 'late_fee', 'replacement')),
+    rate        NUMERIC(5,2) NOT NULL CHECK (rate >= 0 AND rate <= 100),
+    active      BOOLEAN NOT NULL DEFAULT true,
+    last_update TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_tax_rate_country ON tax_rate (country_id);
+
+CREATE TRIGGER last_updated BEFORE UPDATE ON tax_rate FOR EACH ROW EXECUTE FUNCTION last_updated();
+
+-- Goods and services tax where the sample stores are located
+-- (Lethbridge, Canada and Woodridge, Australia).
+INSERT INTO tax_rate (name, country_id, rate)
+SELECT v.name, c.country_id, v.rate
+FROM (VALUES ('GST', 'Canada', 5.00), ('GST', 'Australia', 10.00)) AS v (name, country, rate)
+JOIN country c ON c.country = v.country
+WHERE NOT EXISTS (SELECT 1 FROM tax_rate t WHERE t.country_id = c.country_id AND t.name = v.name);
+
+-- amount remains the total paid by the customer (net + tax), so existing
+-- reports keep working. Payments taken before sales tax are untaxed.
+ALTER TABLE payment ADD COLUMN IF NOT EXISTS charge_type TEXT NOT NULL DEFAULT 'rental'
+    CHECK (charge_type IN ('rental', 'late_fee', 'replacement'));
+ALTER TABLE payment ADD COLUMN IF NOT EXISTS net_amount NUMERIC(5,2);
+ALTER TABLE payment ADD COLUMN IF NOT EXISTS tax_amount NUMERIC(5,2) NOT NULL DEFAULT 0;
+
+UPDATE payment SET net_amount = amount WHERE net_amount IS NULL;
+ALTER TABLE payment ALTER COLUMN net_amount SET NOT NULL;
+
+-- Tax charged on each payment, one row per rate applied.
+-- payment is partitioned by payment_date, so payment_id cannot be a foreign key.
+CREATE TABLE IF NOT EXISTS payment_tax (
+    payment_id  INTEGER NOT NULL,
+    tax_rate_id INTEGER NOT NULL REFERENCES tax_rate(tax_rate_id),
+    name        TEXT NOT NULL,
+    rate        NUMERIC(5,2) NOT NULL,
+    amount      NUMERIC(5,2) NOT NULL,
+    PRIMARY KEY (payment_id, tax_rate_id)
+);
@@ -0,0 +1,9 @@
+-- Typo-tolerant and prefix search for films and actors
+-- Trigram indexes back the fuzzy fallback (pg_trgm word similarity) used
+-- when full-text search finds nothing, and actor name search.
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_film_title_trgm ON film USING gin (title gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_actor_name_trgm ON actor USING gin ((first_name || ' ' || last_name) gin_trgm_ops);
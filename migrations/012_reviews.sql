@@ -0,0 +1,35 @@
+-- Customer ratings and reviews for films
+-- A customer can review each film they have rented once; posting again
+-- replaces the review. Reviews with text wait in the moderation queue
+-- (status 'pending'); star-only ratings have nothing to moderate and are
+-- approved immediately. Only approved reviews are shown and counted.
+CREATE TABLE IF NOT EXISTS film_review (
+    review_id       SERIAL PRIMARY KEY,
+    film_id         INTEGER NOT NULL REFERENCES film(film_id) ON DELETE CASCADE,
+    customer_id     INTEGER NOT NULL REFERENCES customer(customer_id) ON DELETE CASCADE,
+    rating          SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    body            TEXT NOT NULL DEFAULT '',
+    status          TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    moderation_note TEXT NOT NULL DEFAULT '',
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    last_update     TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    UNIQUE (film_id, customer_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_film_review_status ON film_review (status, created_at);
+CREATE INDEX IF NOT EXISTS idx_film_review_customer ON film_review (customer_id);
+
+CREATE TRIGGER last_updated BEFORE UPDATE ON film_review FOR EACH ROW EXECUTE FUNCTION last_updated();
+
+-- Per-film aggregate of approved reviews, refreshed whenever a review of
+-- the film changes. Films without approved reviews have no row.
+CREATE TABLE IF NOT EXISTS film_rating (
+    film_id        INTEGER PRIMARY KEY REFERENCES film(film_id) ON DELETE CASCADE,
+    review_count   INTEGER NOT NULL,
+    average_rating NUMERIC(3,2) NOT NULL,
+    last_update    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_film_rating_average ON film_rating (average_rating);
+
+CREATE TRIGGER last_updated BEFORE UPDATE ON film_rating FOR EACH ROW EXECUTE FUNCTION last_updated();
@@ -0,0 +1,14 @@
+-- "Customers who rented this also rented" recommendations
+-- Item-to-item similarity between films, from how many customers rented
+-- both (cosine similarity over the sets of customers who rented each film).
+-- The film service recomputes the table on a schedule; only the most
+-- similar films of each film are kept.
+CREATE TABLE IF NOT EXISTS film_similarity (
+    film_id         INTEGER NOT NULL REFERENCES film(film_id) ON DELETE CASCADE,
+    similar_film_id INTEGER NOT NULL REFERENCES film(film_id) ON DELETE CASCADE,
+    co_rentals      INTEGER NOT NULL, -- customers who rented both films
+    score           DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (film_id, similar_film_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_film_similarity_score ON film_similarity (film_id, score DESC);
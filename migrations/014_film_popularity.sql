@@ -0,0 +1,18 @@
+-- Film popularity for the trending and popular lists
+-- Rentals of each film over rolling 7, 30 and 90 day windows, per store
+-- and over all stores (store_id 0). The windows end when the view is
+-- refreshed; the film service refreshes it on a schedule. Films not rented
+-- in the last 90 days have no row.
+CREATE MATERIALIZED VIEW IF NOT EXISTS film_popularity AS
+SELECT i.film_id,
+       coalesce(i.store_id, 0) AS store_id,
+       count(*) FILTER (WHERE r.rental_date >= now() - interval '7 days') AS rentals_7d,
+       count(*) FILTER (WHERE r.rental_date >= now() - interval '30 days') AS rentals_30d,
+       count(*) AS rentals_90d
+FROM rental r
+JOIN inventory i ON i.inventory_id = r.inventory_id
+WHERE r.rental_date >= now() - interval '90 days'
+GROUP BY GROUPING SETS ((i.film_id, i.store_id), (i.film_id));
+
+-- Required by REFRESH MATERIALIZED VIEW CONCURRENTLY.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_film_popularity_store_film ON film_popularity (store_id, film_id);
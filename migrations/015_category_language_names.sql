@@ -0,0 +1,6 @@
+-- Unique category and language names
+-- Categories and languages are managed through the API, which looks them
+-- up by name (e.g. in bulk film imports), so names must not repeat. Case
+-- and, for the space-padded language.name, trailing spaces are ignored.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_category_name ON category (lower(name));
+CREATE UNIQUE INDEX IF NOT EXISTS idx_language_name ON language (lower(trim(name)));
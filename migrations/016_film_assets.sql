@@ -0,0 +1,18 @@
+-- Film posters and stills
+-- The image files live in the film service's blob store under
+-- films/<film_id>/<asset_id>/<size>; this table records which exist. Each
+-- upload is stored as the original plus JPEG renditions of the sizes
+-- narrower than it. A film has at most one poster: uploading another
+-- replaces it. Blobs of a deleted film's assets are left in the store.
+CREATE TABLE IF NOT EXISTS film_asset (
+    asset_id     SERIAL PRIMARY KEY,
+    film_id      INTEGER NOT NULL REFERENCES film(film_id) ON DELETE CASCADE,
+    kind         TEXT NOT NULL CHECK (kind IN ('poster', 'still')),
+    content_type TEXT NOT NULL, -- of the original
+    width        INTEGER NOT NULL,
+    height       INTEGER NOT NULL,
+    sizes        TEXT[] NOT NULL, -- stored sizes, smallest first, ending with 'original'
+    created_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_film_asset_film ON film_asset (film_id, kind);
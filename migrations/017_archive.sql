@@ -0,0 +1,8 @@
+-- Archived films and actors
+-- Deleting a film or an actor archives it instead, keeping its rental and
+-- casting history: archived films and actors are hidden from the customer
+-- catalog but can still be seen and restored by staff. Archived films get
+-- no new inventory, and a film cannot be archived while copies of it are
+-- out on rental.
+ALTER TABLE film ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE actor ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP WITH TIME ZONE;
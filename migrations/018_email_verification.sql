@@ -0,0 +1,12 @@
+-- Email verification
+-- A customer's email is their login, so changes to it are confirmed from
+-- the new address before they take effect: the requested address waits in
+-- pending_email and the current one keeps working until then.
+-- email_verified_at is set once the customer's address has been confirmed.
+ALTER TABLE customer ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE customer ADD COLUMN IF NOT EXISTS pending_email TEXT;
+
+-- Existing customers' emails are taken as verified.
+UPDATE customer
+SET email_verified_at = create_date
+WHERE email_verified_at IS NULL;
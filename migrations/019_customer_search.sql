@@ -0,0 +1,8 @@
+-- Customer search
+-- Trigram indexes back staff searching customers by name, email and phone:
+-- prefix matches on each, plus a fuzzy match on the full name.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_customer_name_trgm ON customer USING gin ((first_name || ' ' || last_name) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_customer_email_trgm ON customer USING gin (email gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_address_phone_digits_trgm ON address USING gin ((regexp_replace(phone, '[^0-9]', '', 'g')) gin_trgm_ops);
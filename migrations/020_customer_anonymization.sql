@@ -0,0 +1,5 @@
+-- Customer anonymization
+-- Customers are no longer deleted. Erasing a customer scrubs their name,
+-- email, password and address but keeps the customer row, so their rentals
+-- and payments stay in the books. anonymized_at records when it happened.
+ALTER TABLE customer ADD COLUMN IF NOT EXISTS anonymized_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,11 @@
+-- Customer wishlists
+-- Films a customer has saved to rent later. Saving a film twice keeps the
+-- original added_at.
+CREATE TABLE IF NOT EXISTS wishlist_item (
+    customer_id INTEGER NOT NULL REFERENCES customer(customer_id) ON DELETE CASCADE,
+    film_id     INTEGER NOT NULL REFERENCES film(film_id) ON DELETE CASCADE,
+    added_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (customer_id, film_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_wishlist_item_customer_added ON wishlist_item (customer_id, added_at DESC);
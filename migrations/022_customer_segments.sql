@@ -0,0 +1,27 @@
+-- Customer segments for targeted campaigns
+-- A segment is a rule tree over customers' spend, rental recency,
+-- categories rented, store and active flag, stored as JSON. Evaluating a
+-- segment replaces its materialized member list with the customers the
+-- rules match at that moment.
+CREATE TABLE IF NOT EXISTS customer_segment (
+    segment_id   SERIAL PRIMARY KEY,
+    name         TEXT NOT NULL,
+    description  TEXT NOT NULL DEFAULT '',
+    rule         JSONB NOT NULL,
+    member_count INTEGER NOT NULL DEFAULT 0,
+    evaluated_at TIMESTAMP WITH TIME ZONE,
+    created_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    last_update  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_unq_customer_segment_name ON customer_segment (lower(name));
+
+CREATE TRIGGER last_updated BEFORE UPDATE ON customer_segment FOR EACH ROW EXECUTE FUNCTION last_updated();
+
+CREATE TABLE IF NOT EXISTS customer_segment_member (
+    segment_id  INTEGER NOT NULL REFERENCES customer_segment(segment_id) ON DELETE CASCADE,
+    customer_id INTEGER NOT NULL REFERENCES customer(customer_id) ON DELETE CASCADE,
+    PRIMARY KEY (segment_id, customer_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_segment_member_customer ON customer_segment_member (customer_id);
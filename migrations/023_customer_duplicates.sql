@@ -0,0 +1,43 @@
+-- Duplicate customer detection and merging
+-- Scanning for duplicates scores pairs of customers on how similar their
+-- names, addresses and phone numbers are, and queues likely duplicates for
+-- staff to review (status 'pending'). Staff either dismiss a pair as two
+-- different people, which keeps it out of later scans, or merge it: the
+-- duplicate's rentals and payments move to the surviving customer, the
+-- duplicate is deactivated and the merge is recorded in customer_merge.
+CREATE TABLE IF NOT EXISTS customer_duplicate (
+    duplicate_id      SERIAL PRIMARY KEY,
+    customer_id       INTEGER NOT NULL REFERENCES customer(customer_id) ON DELETE CASCADE,
+    other_customer_id INTEGER NOT NULL REFERENCES customer(customer_id) ON DELETE CASCADE,
+    score             DOUBLE PRECISION NOT NULL,
+    name_score        DOUBLE PRECISION NOT NULL,
+    address_score     DOUBLE PRECISION NOT NULL,
+    phone_score       DOUBLE PRECISION NOT NULL,
+    status            TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'dismissed', 'merged')),
+    detected_at       TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    reviewed_at       TIMESTAMP WITH TIME ZONE,
+    reviewed_by       INTEGER REFERENCES staff(staff_id),
+    last_update       TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    UNIQUE (customer_id, other_customer_id),
+    CHECK (customer_id < other_customer_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_duplicate_status ON customer_duplicate (status, score DESC);
+CREATE INDEX IF NOT EXISTS idx_customer_duplicate_other ON customer_duplicate (other_customer_id);
+
+CREATE TRIGGER last_updated BEFORE UPDATE ON customer_duplicate FOR EACH ROW EXECUTE FUNCTION last_updated();
+
+-- A customer can be merged away only once; merged customers are left out
+-- of duplicate scans and cannot take part in further merges.
+CREATE TABLE IF NOT EXISTS customer_merge (
+    merge_id              SERIAL PRIMARY KEY,
+    surviving_customer_id INTEGER NOT NULL REFERENCES customer(customer_id),
+    merged_customer_id    INTEGER NOT NULL UNIQUE REFERENCES customer(customer_id),
+    rentals_moved         INTEGER NOT NULL,
+    payments_moved        INTEGER NOT NULL,
+    merged_by             INTEGER NOT NULL REFERENCES staff(staff_id),
+    merged_at             TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    CHECK (surviving_customer_id <> merged_customer_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_merge_surviving ON customer_merge (surviving_customer_id);
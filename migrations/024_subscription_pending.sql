@@ -0,0 +1,12 @@
+-- Pending subscriptions
+-- Starting a subscription records it as 'pending' before the first period is
+-- charged, so the one-live-subscription-per-customer index rejects a second
+-- start before the customer is charged. The subscription becomes 'active'
+-- when the charge succeeds and 'canceled' when it is declined.
+ALTER TABLE subscription DROP CONSTRAINT IF EXISTS subscription_status_check;
+ALTER TABLE subscription ADD CONSTRAINT subscription_status_check
+    CHECK (status IN ('pending', 'active', 'past_due', 'paused', 'canceled'));
+
+-- Subscription charges are reported by when they were taken
+CREATE INDEX IF NOT EXISTS idx_subscription_payment_created
+    ON subscription_payment (created_at) WHERE status = 'succeeded';
@@ -0,0 +1,18 @@
+-- Stored value balances
+-- Amounts and balances are widened to NUMERIC(12,2). System accounts no
+-- longer keep a running balance: every issue and redemption would otherwise
+-- lock and update the same SYSTEM-ISSUANCE or SYSTEM-REDEMPTION row,
+-- serialising all postings, and the issuance balance falls without limit.
+-- A system account's balance is the sum of its entries, which carry no
+-- balance_after.
+ALTER TABLE stored_value_account ALTER COLUMN balance TYPE NUMERIC(12,2);
+ALTER TABLE stored_value_entry ALTER COLUMN amount TYPE NUMERIC(12,2);
+ALTER TABLE stored_value_entry ALTER COLUMN balance_after TYPE NUMERIC(12,2);
+ALTER TABLE stored_value_entry ALTER COLUMN balance_after DROP NOT NULL;
+
+UPDATE stored_value_entry e
+SET balance_after = NULL
+FROM stored_value_account a
+WHERE a.account_id = e.account_id AND a.kind = 'system';
+
+UPDATE stored_value_account SET balance = 0 WHERE kind = 'system';
@@ -0,0 +1,15 @@
+-- Customer merges carry over everything
+-- Merging a duplicate now also moves their subscription (or cancels it when
+-- the surviving customer already has one), gift cards, store credit,
+-- loyalty points, reviews, wishlist and promotion usage. customer_merge
+-- records how much of each was moved. Where the surviving customer already
+-- has a review of the same film, the duplicate's review is dropped.
+ALTER TABLE customer_merge ADD COLUMN IF NOT EXISTS subscriptions_moved INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE customer_merge ADD COLUMN IF NOT EXISTS subscriptions_canceled INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE customer_merge ADD COLUMN IF NOT EXISTS gift_cards_moved INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE customer_merge ADD COLUMN IF NOT EXISTS store_credit_moved NUMERIC(12,2) NOT NULL DEFAULT 0;
+ALTER TABLE customer_merge ADD COLUMN IF NOT EXISTS loyalty_points_moved INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE customer_merge ADD COLUMN IF NOT EXISTS reviews_moved INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE customer_merge ADD COLUMN IF NOT EXISTS reviews_dropped INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE customer_merge ADD COLUMN IF NOT EXISTS wishlist_items_moved INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE customer_merge ADD COLUMN IF NOT EXISTS promotion_uses_moved INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,8 @@
+-- Unique customer emails
+-- Customers sign in with their email, which is matched ignoring case, so
+-- no two customers may share one in any capitalisation. The unique index
+-- also serves the case-insensitive lookup and replaces the plain index on
+-- email. Anonymized customers have no email and are not constrained.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_unq_customer_email_lower ON customer (lower(email));
+
+DROP INDEX IF EXISTS idx_customer_email;
@@ -0,0 +1,13 @@
+-- Subscription limits per billing period
+-- Each charged period keeps the rental limits of the plan it was charged
+-- under, so a plan change applies to subscribers from their next period
+-- instead of mid-period. Existing periods take their plan's current limits.
+ALTER TABLE subscription_payment ADD COLUMN IF NOT EXISTS max_concurrent_rentals INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE subscription_payment ADD COLUMN IF NOT EXISTS max_rentals_per_period INTEGER NOT NULL DEFAULT 0;
+
+UPDATE subscription_payment sp
+SET max_concurrent_rentals = p.max_concurrent_rentals,
+    max_rentals_per_period = p.max_rentals_per_period
+FROM subscription s
+JOIN subscription_plan p ON p.plan_id = s.plan_id
+WHERE s.subscription_id = sp.subscription_id;
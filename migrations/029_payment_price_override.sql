@@ -0,0 +1,14 @@
+-- Manual rental prices
+-- Staff may set a rental payment's amount by hand instead of the pricing
+-- engine's quote. Each override is recorded with the quote it replaced.
+-- payment is partitioned by payment_date, so payment_id cannot be a foreign key.
+CREATE TABLE IF NOT EXISTS payment_price_override (
+    payment_id    INTEGER PRIMARY KEY,
+    customer_id   INTEGER NOT NULL REFERENCES customer(customer_id),
+    staff_id      INTEGER NOT NULL REFERENCES staff(staff_id),
+    quoted_amount NUMERIC(5,2) NOT NULL,
+    amount        NUMERIC(5,2) NOT NULL,
+    created_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_price_override_staff ON payment_price_override (staff_id);
@@ -15,7 +15,8 @@ type RefreshTokenData struct {
 	Role   Role  `json:"role"`
 }
 
-// RefreshTokenStore manages refresh tokens in Redis.
+// RefreshTokenStore manages refresh tokens in Redis. Each user's token IDs
+// are also kept in a set so they can all be revoked at once.
 type RefreshTokenStore struct {
 	client *redis.Client
 	ttl    time.Duration
@@ -32,13 +33,20 @@ func NewRefreshTokenStore(client *redis.Client, ttl time.Duration) *RefreshToken
 // Store saves a refresh token with its metadata.
 func (s *RefreshTokenStore) Store(ctx context.Context, jti string, data RefreshTokenData) error {
 	key := "refresh:" + jti
+	userKey := userTokensKey(data.UserID, data.Role)
 
 	b, err := json.Marshal(data)
 	if err != nil {
 		return fmt.Errorf("marshal token data: %w", err)
 	}
 
-	if err := s.client.Set(ctx, key, b, s.ttl).Err(); err != nil {
+	// The user's set outlives every token in it; IDs of tokens that have
+	// expired or been deleted are left in it and ignored.
+	pipe := s.client.TxPipeline()
+	pipe.Set(ctx, key, b, s.ttl)
+	pipe.SAdd(ctx, userKey, jti)
+	pipe.Expire(ctx, userKey, s.ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
 		return fmt.Errorf("store refresh token: %w", err)
 	}
 
@@ -76,6 +84,28 @@ func (s *RefreshTokenStore) Delete(ctx context.Context, jti string) error {
 	return nil
 }
 
+// DeleteAllForUser revokes every refresh token of a user, signing them out
+// everywhere once their access tokens expire.
+func (s *RefreshTokenStore) DeleteAllForUser(ctx context.Context, userID int32, role Role) error {
+	userKey := userTokensKey(userID, role)
+
+	jtis, err := s.client.SMembers(ctx, userKey).Result()
+	if err != nil {
+		return fmt.Errorf("list refresh tokens: %w", err)
+	}
+
+	keys := make([]string, 0, len(jtis)+1)
+	for _, jti := range jtis {
+		keys = append(keys, "refresh:"+jti)
+	}
+	keys = append(keys, userKey)
+	if err := s.client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("delete refresh tokens: %w", err)
+	}
+
+	return nil
+}
+
 // Exists checks whether a refresh token exists in the store.
 func (s *RefreshTokenStore) Exists(ctx context.Context, jti string) (bool, error) {
 	key := "refresh:" + jti
@@ -87,3 +117,7 @@ func (s *RefreshTokenStore) Exists(ctx context.Context, jti string) (bool, error
 
 	return count > 0, nil
 }
+
+func userTokensKey(userID int32, role Role) string {
+	return fmt.Sprintf("refresh_user:%s:%d", role, userID)
+}
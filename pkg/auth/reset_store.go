@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrResetTokenInvalid is returned for a password reset token that is
+// unknown, expired or already used.
+var ErrResetTokenInvalid = errors.New("invalid or expired reset token")
+
+// PasswordResetData identifies the user a password reset token is for.
+type PasswordResetData struct {
+	UserID int32 `json:"user_id"`
+	Role   Role  `json:"role"`
+}
+
+// PasswordResetStore manages single-use password reset tokens in Redis.
+// Only a SHA-256 hash of each token is stored, so the tokens cannot be
+// read back out of Redis. Each user's token hashes are also kept in a set
+// so every outstanding link can be invalidated once a reset succeeds.
+type PasswordResetStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewPasswordResetStore creates a password reset token store whose tokens
+// expire after ttl.
+func NewPasswordResetStore(client *redis.Client, ttl time.Duration) *PasswordResetStore {
+	return &PasswordResetStore{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+// TTL returns how long reset tokens are valid for.
+func (s *PasswordResetStore) TTL() time.Duration {
+	return s.ttl
+}
+
+// Create issues a reset token for a user.
+func (s *PasswordResetStore) Create(ctx context.Context, data PasswordResetData) (string, error) {
+	token, err := newOpaqueToken()
+	if err != nil {
+		return "", fmt.Errorf("generate reset token: %w", err)
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return "", fmt.Errorf("marshal reset data: %w", err)
+	}
+
+	// As with refresh tokens, hashes of expired or used tokens are left in
+	// the user's set and ignored.
+	userKey := resetUserKey(data.UserID, data.Role)
+	pipe := s.client.TxPipeline()
+	pipe.Set(ctx, resetKey(token), b, s.ttl)
+	pipe.SAdd(ctx, userKey, hashToken(token))
+	pipe.Expire(ctx, userKey, s.ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", fmt.Errorf("store reset token: %w", err)
+	}
+
+	return token, nil
+}
+
+// Consume redeems a reset token, returning the user it was issued for. A
+// token can only be consumed once.
+func (s *PasswordResetStore) Consume(ctx context.Context, token string) (*PasswordResetData, error) {
+	val, err := s.client.GetDel(ctx, resetKey(token)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, ErrResetTokenInvalid
+		}
+		return nil, fmt.Errorf("consume reset token: %w", err)
+	}
+
+	var data PasswordResetData
+	if err := json.Unmarshal([]byte(val), &data); err != nil {
+		return nil, fmt.Errorf("unmarshal reset data: %w", err)
+	}
+
+	return &data, nil
+}
+
+// DeleteAllForUser invalidates every outstanding reset token of a user,
+// so older reset links stop working once their password has been reset.
+func (s *PasswordResetStore) DeleteAllForUser(ctx context.Context, userID int32, role Role) error {
+	userKey := resetUserKey(userID, role)
+
+	hashes, err := s.client.SMembers(ctx, userKey).Result()
+	if err != nil {
+		return fmt.Errorf("list reset tokens: %w", err)
+	}
+
+	keys := make([]string, 0, len(hashes)+1)
+	for _, hash := range hashes {
+		keys = append(keys, "password_reset:"+hash)
+	}
+	keys = append(keys, userKey)
+	if err := s.client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("delete reset tokens: %w", err)
+	}
+
+	return nil
+}
+
+func resetKey(token string) string {
+	return "password_reset:" + hashToken(token)
+}
+
+func resetUserKey(userID int32, role Role) string {
+	return fmt.Sprintf("password_reset_user:%s:%d", role, userID)
+}
+
+// newOpaqueToken returns a random, URL-safe token for links sent by email.
+func newOpaqueToken() (string, error) {
+	var raw [32]byte
+	if _, err := rand.Read(raw[:]); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw[:]), nil
+}
+
+// hashToken returns the hex SHA-256 of a token, which is what gets stored
+// in Redis in its place.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrVerificationTokenInvalid is returned for an email verification token
+// that is unknown, expired, already used or replaced by a newer one.
+var ErrVerificationTokenInvalid = errors.New("invalid or expired verification token")
+
+// EmailVerificationData identifies the user and the email address an email
+// verification token confirms.
+type EmailVerificationData struct {
+	UserID int32  `json:"user_id"`
+	Role   Role   `json:"role"`
+	Email  string `json:"email"`
+}
+
+// EmailVerificationStore manages single-use email verification tokens in
+// Redis. A user has at most one outstanding token: issuing a new one, for
+// example when resending the email, invalidates the previous one. Like
+// password reset tokens, only a SHA-256 hash of each token is stored.
+type EmailVerificationStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewEmailVerificationStore creates an email verification token store whose
+// tokens expire after ttl.
+func NewEmailVerificationStore(client *redis.Client, ttl time.Duration) *EmailVerificationStore {
+	return &EmailVerificationStore{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+// TTL returns how long verification tokens are valid for.
+func (s *EmailVerificationStore) TTL() time.Duration {
+	return s.ttl
+}
+
+// Create issues a verification token for a user's email, replacing any
+// token previously issued to them.
+func (s *EmailVerificationStore) Create(ctx context.Context, data EmailVerificationData) (string, error) {
+	token, err := newOpaqueToken()
+	if err != nil {
+		return "", fmt.Errorf("generate verification token: %w", err)
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return "", fmt.Errorf("marshal verification data: %w", err)
+	}
+
+	userKey := verificationUserKey(data.UserID, data.Role)
+	previous, err := s.client.Get(ctx, userKey).Result()
+	if err != nil && err != redis.Nil {
+		return "", fmt.Errorf("get previous verification token: %w", err)
+	}
+
+	pipe := s.client.TxPipeline()
+	if previous != "" {
+		pipe.Del(ctx, "email_verification:"+previous)
+	}
+	pipe.Set(ctx, verificationKey(token), b, s.ttl)
+	pipe.Set(ctx, userKey, hashToken(token), s.ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", fmt.Errorf("store verification token: %w", err)
+	}
+
+	return token, nil
+}
+
+// Consume redeems a verification token, returning the user and email it was
+// issued for. A token can only be consumed once.
+func (s *EmailVerificationStore) Consume(ctx context.Context, token string) (*EmailVerificationData, error) {
+	val, err := s.client.GetDel(ctx, verificationKey(token)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, ErrVerificationTokenInvalid
+		}
+		return nil, fmt.Errorf("consume verification token: %w", err)
+	}
+
+	var data EmailVerificationData
+	if err := json.Unmarshal([]byte(val), &data); err != nil {
+		return nil, fmt.Errorf("unmarshal verification data: %w", err)
+	}
+
+	// The consumed token was the user's latest one, since issuing a new
+	// token deletes the old.
+	if err := s.client.Del(ctx, verificationUserKey(data.UserID, data.Role)).Err(); err != nil {
+		return nil, fmt.Errorf("delete verification token: %w", err)
+	}
+
+	return &data, nil
+}
+
+func verificationKey(token string) string {
+	return "email_verification:" + hashToken(token)
+}
+
+func verificationUserKey(userID int32, role Role) string {
+	return fmt.Sprintf("email_verification_user:%s:%d", role, userID)
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// DownloadFile is a file in a ZIP download; Data is written as indented
+// JSON.
+type DownloadFile struct {
+	Name string
+	Data interface{}
+}
+
+// WriteJSONDownload writes data as an indented JSON file the browser saves
+// as filename.
+func WriteJSONDownload(w http.ResponseWriter, filename string, data interface{}) error {
+	b, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal %s: %w", filename, err)
+	}
+	writeDownload(w, "application/json", filename, b)
+	return nil
+}
+
+// WriteZIPDownload writes files as a ZIP archive the browser saves as
+// filename. Nothing is written to w if building the archive fails.
+func WriteZIPDownload(w http.ResponseWriter, filename string, files []DownloadFile) error {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		b, err := json.MarshalIndent(f.Data, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal %s: %w", f.Name, err)
+		}
+		fw, err := zw.Create(f.Name)
+		if err != nil {
+			return fmt.Errorf("add %s: %w", f.Name, err)
+		}
+		if _, err := fw.Write(b); err != nil {
+			return fmt.Errorf("write %s: %w", f.Name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("close zip: %w", err)
+	}
+	writeDownload(w, "application/zip", filename, buf.Bytes())
+	return nil
+}
+
+func writeDownload(w http.ResponseWriter, contentType, filename string, b []byte) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", "attachment; filename="+strconv.Quote(filename))
+	w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
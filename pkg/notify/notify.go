@@ -0,0 +1,66 @@
+// Package notify delivers messages such as password reset emails to users.
+package notify
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Message is an email to a single recipient.
+type Message struct {
+	To      string
+	Subject string
+	Body    string // plain text
+}
+
+// Notifier sends messages to users.
+type Notifier interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+type fileNotifier struct {
+	dir  string
+	from string
+}
+
+// NewFileNotifier creates a Notifier that stands in for an SMTP server: it
+// writes each message as an RFC 5322 .eml file into dir, creating dir if
+// needed, instead of sending it.
+func NewFileNotifier(dir, from string) (Notifier, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create mail dir: %w", err)
+	}
+	return &fileNotifier{dir: dir, from: from}, nil
+}
+
+func (n *fileNotifier) Send(ctx context.Context, msg Message) error {
+	if strings.ContainsAny(msg.To+msg.Subject, "\r\n") {
+		return fmt.Errorf("send %q: header contains a line break", msg.Subject)
+	}
+
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return fmt.Errorf("message id: %w", err)
+	}
+	now := time.Now()
+	name := now.UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(id[:]) + ".eml"
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", n.from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	if err := os.WriteFile(filepath.Join(n.dir, name), []byte(b.String()), 0o644); err != nil {
+		return fmt.Errorf("write message: %w", err)
+	}
+	return nil
+}
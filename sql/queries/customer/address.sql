@@ -28,5 +28,34 @@ SET address = $2,
 WHERE address_id = $1
 RETURNING address_id, address, address2, district, city_id, postal_code, phone, last_update;
 
+-- name: LockAddress :exec
+-- Locks an address so that no customer, store or staff member starts using
+-- it until the transaction ends.
+SELECT address_id FROM address WHERE address_id = $1 FOR UPDATE;
+
+-- name: AnonymizeAddress :exec
+-- Scrubs an address in place, keeping only its city.
+UPDATE address
+SET address = 'Anonymized',
+    address2 = NULL,
+    district = 'Anonymized',
+    postal_code = NULL,
+    phone = ''
+WHERE address_id = $1;
+
+-- name: CreateAnonymizedAddress :one
+-- Creates an address with no personal data in the city of another.
+INSERT INTO address (address, district, city_id, phone)
+SELECT 'Anonymized', 'Anonymized', a.city_id, ''
+FROM address a
+WHERE a.address_id = $1
+RETURNING address_id;
+
 -- name: DeleteAddress :exec
 DELETE FROM address WHERE address_id = $1;
+
+-- name: CountAddressUsers :one
+-- Counts the customers, stores and staff members using an address.
+SELECT ((SELECT count(*) FROM customer c WHERE c.address_id = $1)
+      + (SELECT count(*) FROM store s WHERE s.address_id = $1)
+      + (SELECT count(*) FROM staff st WHERE st.address_id = $1))::bigint AS user_count;
@@ -4,10 +4,17 @@ FROM city
 WHERE city_id = $1;
 
 -- name: ListCities :many
-SELECT city_id, city, country_id, last_update
-FROM city
-ORDER BY city_id
-LIMIT $1 OFFSET $2;
+-- Filtering by a name prefix orders cities by name, for autocomplete.
+SELECT ci.city_id, ci.city, ci.country_id, ci.last_update, co.country
+FROM city ci
+JOIN country co ON co.country_id = ci.country_id
+WHERE ci.city ILIKE @name_pattern::text
+  AND (@country_id::int = 0 OR ci.country_id = @country_id::int)
+ORDER BY CASE WHEN @name_pattern::text = '%' THEN NULL ELSE ci.city END,
+         ci.city_id
+LIMIT @page_limit OFFSET @page_offset;
 
 -- name: CountCities :one
-SELECT count(*) FROM city;
+SELECT count(*) FROM city
+WHERE city ILIKE @name_pattern::text
+  AND (@country_id::int = 0 OR country_id = @country_id::int);
@@ -1,19 +1,22 @@
 -- name: GetCustomer :one
 SELECT customer_id, store_id, first_name, last_name, email,
-       address_id, activebool, create_date, last_update, active
+       address_id, activebool, create_date, last_update, active,
+       email_verified_at, pending_email, anonymized_at
 FROM customer
 WHERE customer_id = $1;
 
 -- name: GetCustomerByEmail :one
+-- Matches the email ignoring case.
 SELECT customer_id, store_id, first_name, last_name, email,
        address_id, activebool, create_date, last_update, active,
-       password_hash
+       email_verified_at, pending_email, anonymized_at, password_hash
 FROM customer
-WHERE email = $1;
+WHERE lower(email) = lower($1);
 
 -- name: ListCustomers :many
 SELECT customer_id, store_id, first_name, last_name, email,
-       address_id, activebool, create_date, last_update, active
+       address_id, activebool, create_date, last_update, active,
+       email_verified_at, pending_email, anonymized_at
 FROM customer
 ORDER BY customer_id
 LIMIT $1 OFFSET $2;
@@ -23,7 +26,8 @@ SELECT count(*) FROM customer;
 
 -- name: ListCustomersByStore :many
 SELECT customer_id, store_id, first_name, last_name, email,
-       address_id, activebool, create_date, last_update, active
+       address_id, activebool, create_date, last_update, active,
+       email_verified_at, pending_email, anonymized_at
 FROM customer
 WHERE store_id = $1
 ORDER BY customer_id
@@ -32,13 +36,67 @@ LIMIT $2 OFFSET $3;
 -- name: CountCustomersByStore :one
 SELECT count(*) FROM customer WHERE store_id = $1;
 
+-- name: SearchCustomers :many
+-- Customers whose first name, last name, full name or email starts with the
+-- query, or whose phone starts with its digits, then customers whose name
+-- contains a word similar to it.
+SELECT c.customer_id, c.store_id, c.first_name, c.last_name, c.email,
+       c.address_id, c.activebool, c.create_date, c.last_update, c.active,
+       c.email_verified_at, c.pending_email, c.anonymized_at
+FROM customer c
+JOIN address a ON a.address_id = c.address_id
+WHERE (c.first_name ILIKE @prefix_pattern::text
+       OR c.last_name ILIKE @prefix_pattern::text
+       OR (c.first_name || ' ' || c.last_name) ILIKE @prefix_pattern::text
+       OR c.email ILIKE @prefix_pattern::text
+       OR (@phone_pattern::text <> ''
+           AND regexp_replace(a.phone, '[^0-9]', '', 'g') LIKE @phone_pattern::text)
+       OR @query::text <% (c.first_name || ' ' || c.last_name))
+  AND (@store_id::int = 0 OR c.store_id = @store_id::int)
+  AND (sqlc.narg('active')::bool IS NULL OR c.activebool = sqlc.narg('active')::bool)
+ORDER BY (c.first_name ILIKE @prefix_pattern::text
+          OR c.last_name ILIKE @prefix_pattern::text
+          OR (c.first_name || ' ' || c.last_name) ILIKE @prefix_pattern::text
+          OR c.email ILIKE @prefix_pattern::text
+          OR (@phone_pattern::text <> ''
+              AND regexp_replace(a.phone, '[^0-9]', '', 'g') LIKE @phone_pattern::text)) DESC,
+         word_similarity(@query::text, c.first_name || ' ' || c.last_name) DESC,
+         c.last_name, c.first_name, c.customer_id
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: CountSearchCustomers :one
+SELECT count(*)
+FROM customer c
+JOIN address a ON a.address_id = c.address_id
+WHERE (c.first_name ILIKE @prefix_pattern::text
+       OR c.last_name ILIKE @prefix_pattern::text
+       OR (c.first_name || ' ' || c.last_name) ILIKE @prefix_pattern::text
+       OR c.email ILIKE @prefix_pattern::text
+       OR (@phone_pattern::text <> ''
+           AND regexp_replace(a.phone, '[^0-9]', '', 'g') LIKE @phone_pattern::text)
+       OR @query::text <% (c.first_name || ' ' || c.last_name))
+  AND (@store_id::int = 0 OR c.store_id = @store_id::int)
+  AND (sqlc.narg('active')::bool IS NULL OR c.activebool = sqlc.narg('active')::bool);
+
 -- name: CreateCustomer :one
 INSERT INTO customer (store_id, first_name, last_name, email, address_id, activebool, active)
 VALUES ($1, $2, $3, $4, $5, $6, $7)
 RETURNING customer_id, store_id, first_name, last_name, email,
-          address_id, activebool, create_date, last_update, active;
+          address_id, activebool, create_date, last_update, active,
+          email_verified_at, pending_email, anonymized_at;
+
+-- name: RegisterCustomer :one
+-- Self-registered customers are active and sign in with their own password.
+INSERT INTO customer (store_id, first_name, last_name, email, address_id,
+                      activebool, active, password_hash)
+VALUES ($1, $2, $3, $4, $5, true, 1, $6)
+RETURNING customer_id, store_id, first_name, last_name, email,
+          address_id, activebool, create_date, last_update, active,
+          email_verified_at, pending_email, anonymized_at;
 
 -- name: UpdateCustomer :one
+-- Changing the email directly, other than its case, leaves the new address
+-- unverified.
 UPDATE customer
 SET store_id = $2,
     first_name = $3,
@@ -46,10 +104,143 @@ SET store_id = $2,
     email = $5,
     address_id = $6,
     activebool = $7,
-    active = $8
+    active = $8,
+    email_verified_at = CASE WHEN lower(email) = lower($5) THEN email_verified_at END,
+    pending_email = NULLIF(pending_email, $5)
 WHERE customer_id = $1
 RETURNING customer_id, store_id, first_name, last_name, email,
-          address_id, activebool, create_date, last_update, active;
+          address_id, activebool, create_date, last_update, active,
+          email_verified_at, pending_email, anonymized_at;
+
+-- name: UpdateCustomerPassword :one
+UPDATE customer
+SET password_hash = $2
+WHERE customer_id = $1
+RETURNING customer_id;
+
+-- name: SetCustomerPendingEmail :one
+-- Requesting the current email again cancels a pending change.
+UPDATE customer
+SET pending_email = NULLIF(@pending_email::text, email)
+WHERE customer_id = @customer_id
+RETURNING customer_id, store_id, first_name, last_name, email,
+          address_id, activebool, create_date, last_update, active,
+          email_verified_at, pending_email, anonymized_at;
+
+-- name: VerifyCustomerEmail :one
+-- Confirms either the pending email, which then replaces the current one,
+-- or an unverified current email.
+UPDATE customer
+SET email = @email::text,
+    pending_email = NULLIF(pending_email, @email::text),
+    email_verified_at = now()
+WHERE customer_id = @customer_id
+  AND (pending_email = @email::text
+       OR (email = @email::text AND email_verified_at IS NULL))
+RETURNING customer_id, store_id, first_name, last_name, email,
+          address_id, activebool, create_date, last_update, active,
+          email_verified_at, pending_email, anonymized_at;
+
+-- name: SetCustomerAddress :one
+UPDATE customer
+SET address_id = @address_id
+WHERE customer_id = @customer_id
+RETURNING customer_id, store_id, first_name, last_name, email,
+          address_id, activebool, create_date, last_update, active,
+          email_verified_at, pending_email, anonymized_at;
+
+-- name: GetCustomerAddressIDForUpdate :one
+SELECT address_id
+FROM customer
+WHERE customer_id = $1
+FOR UPDATE;
+
+-- name: AnonymizeCustomer :one
+-- Scrubs a customer's personal data and disables the account; address_id
+-- must point at an address holding no personal data.
+UPDATE customer
+SET first_name = 'Deleted',
+    last_name = 'Customer',
+    email = NULL,
+    pending_email = NULL,
+    email_verified_at = NULL,
+    password_hash = NULL,
+    address_id = @address_id,
+    activebool = false,
+    active = 0,
+    anonymized_at = now()
+WHERE customer_id = @customer_id
+RETURNING customer_id, store_id, first_name, last_name, email,
+          address_id, activebool, create_date, last_update, active,
+          email_verified_at, pending_email, anonymized_at;
+
+-- name: DeleteCustomerWishlist :exec
+DELETE FROM wishlist_item WHERE customer_id = $1;
+
+-- name: CancelCustomerSubscriptions :exec
+-- Cancels a customer's live subscription immediately, so it is not billed again.
+UPDATE subscription
+SET status = 'canceled',
+    canceled_at = now(),
+    next_billing_at = NULL,
+    cancel_at_period_end = false,
+    pause_at_period_end = false
+WHERE customer_id = $1 AND status <> 'canceled';
+
+-- name: DeactivateCustomerStoreCredit :exec
+-- The balance stays on the books but can no longer be spent.
+UPDATE stored_value_account
+SET active = false
+WHERE customer_id = @customer_id::int AND kind = 'store_credit';
+
+-- name: UnlinkCustomerGiftCards :exec
+-- Gift cards stay spendable by whoever holds the code.
+UPDATE stored_value_account
+SET customer_id = NULL
+WHERE customer_id = @customer_id::int AND kind = 'gift_card';
+
+-- name: ForfeitCustomerLoyaltyPoints :exec
+-- Zeroes a customer's points balance and records the forfeited points.
+WITH account AS (
+    SELECT a.customer_id, a.points_balance
+    FROM loyalty_account a
+    WHERE a.customer_id = @customer_id AND a.points_balance > 0
+    FOR UPDATE
+), zeroed AS (
+    UPDATE loyalty_account la
+    SET points_balance = 0
+    FROM account
+    WHERE la.customer_id = account.customer_id
+)
+INSERT INTO loyalty_transaction (customer_id, kind, points, balance_after, note)
+SELECT account.customer_id, 'adjust', -account.points_balance, 0, 'Forfeited on anonymization'
+FROM account;
+
+-- name: DeleteCustomerReviews :many
+-- Returns the films whose ratings need refreshing.
+DELETE FROM film_review
+WHERE customer_id = $1
+RETURNING film_id;
+
+-- name: LockFilmRating :exec
+-- Serialises rating recomputes for one film until the transaction ends; the
+-- film service takes the same lock.
+SELECT pg_advisory_xact_lock(@film_id::int);
 
--- name: DeleteCustomer :exec
-DELETE FROM customer WHERE customer_id = $1;
+-- name: RefreshFilmRating :exec
+-- Recomputes a film's aggregate from its approved reviews. Call
+-- LockFilmRating first in the same transaction.
+WITH agg AS (
+    SELECT count(*)::int AS review_count, coalesce(round(avg(rating), 2), 0)::numeric(3,2) AS average_rating
+    FROM film_review
+    WHERE film_id = @film_id AND status = 'approved'
+), upserted AS (
+    INSERT INTO film_rating (film_id, review_count, average_rating)
+    SELECT @film_id, review_count, average_rating FROM agg WHERE review_count > 0
+    ON CONFLICT (film_id) DO UPDATE
+    SET review_count = EXCLUDED.review_count,
+        average_rating = EXCLUDED.average_rating
+    RETURNING film_id
+)
+DELETE FROM film_rating
+WHERE film_rating.film_id = @film_id AND (SELECT review_count FROM agg) = 0;
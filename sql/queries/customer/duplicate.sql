@@ -0,0 +1,262 @@
+-- name: ListDuplicateCandidates :many
+-- Pairs of customers, lower ID first, with similar names, the same phone
+-- number (ignoring any prefix before its last seven digits) or the same
+-- street address in the same city. Scores run from 0 to 1; an address in
+-- another city scores half. Anonymized and merged customers are left out.
+WITH c AS (
+    SELECT cu.customer_id,
+           lower(cu.first_name || ' ' || cu.last_name) AS full_name,
+           lower(trim(a.address || ' ' || coalesce(a.address2, ''))) AS street,
+           a.city_id,
+           regexp_replace(a.phone, '[^0-9]', '', 'g') AS phone
+    FROM customer cu
+    JOIN address a ON a.address_id = cu.address_id
+    WHERE cu.anonymized_at IS NULL
+      AND NOT EXISTS (SELECT 1 FROM customer_merge m WHERE m.merged_customer_id = cu.customer_id)
+)
+SELECT x.customer_id,
+       y.customer_id AS other_customer_id,
+       similarity(x.full_name, y.full_name)::float8 AS name_score,
+       (CASE WHEN x.city_id = y.city_id THEN similarity(x.street, y.street)
+             ELSE similarity(x.street, y.street) / 2 END)::float8 AS address_score,
+       (CASE WHEN length(x.phone) < 7 OR length(y.phone) < 7 THEN 0
+             WHEN x.phone = y.phone THEN 1
+             WHEN right(x.phone, 7) = right(y.phone, 7) THEN 0.8
+             ELSE 0 END)::float8 AS phone_score
+FROM c x
+JOIN c y ON x.customer_id < y.customer_id
+WHERE x.full_name % y.full_name
+   OR (length(x.phone) >= 7 AND right(x.phone, 7) = right(y.phone, 7))
+   OR (x.city_id = y.city_id AND x.street = y.street)
+ORDER BY x.customer_id, y.customer_id;
+
+-- name: UpsertCustomerDuplicates :exec
+-- Queues newly found pairs and refreshes the scores of pending ones; pairs
+-- already dismissed or merged are left as they are.
+INSERT INTO customer_duplicate (customer_id, other_customer_id, score, name_score, address_score, phone_score)
+SELECT unnest(@customer_ids::int[]), unnest(@other_customer_ids::int[]),
+       unnest(@scores::float8[]), unnest(@name_scores::float8[]),
+       unnest(@address_scores::float8[]), unnest(@phone_scores::float8[])
+ON CONFLICT (customer_id, other_customer_id) DO UPDATE
+SET score = EXCLUDED.score,
+    name_score = EXCLUDED.name_score,
+    address_score = EXCLUDED.address_score,
+    phone_score = EXCLUDED.phone_score,
+    detected_at = now()
+WHERE customer_duplicate.status = 'pending';
+
+-- name: DeleteStaleCustomerDuplicates :exec
+-- Drops pending pairs that the latest scan no longer found.
+DELETE FROM customer_duplicate
+WHERE status = 'pending'
+  AND (customer_id, other_customer_id) NOT IN (
+      SELECT unnest(@customer_ids::int[]), unnest(@other_customer_ids::int[])
+  );
+
+-- name: GetCustomerDuplicate :one
+SELECT duplicate_id, customer_id, other_customer_id, score, name_score, address_score,
+       phone_score, status, detected_at, reviewed_at, reviewed_by
+FROM customer_duplicate
+WHERE duplicate_id = $1;
+
+-- name: ListCustomerDuplicatesByStatus :many
+SELECT duplicate_id, customer_id, other_customer_id, score, name_score, address_score,
+       phone_score, status, detected_at, reviewed_at, reviewed_by
+FROM customer_duplicate
+WHERE status = @status
+ORDER BY score DESC, duplicate_id
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: CountCustomerDuplicatesByStatus :one
+SELECT count(*) FROM customer_duplicate WHERE status = $1;
+
+-- name: DismissCustomerDuplicate :execrows
+UPDATE customer_duplicate
+SET status = 'dismissed', reviewed_at = now(), reviewed_by = @staff_id::int
+WHERE duplicate_id = @duplicate_id AND status = 'pending';
+
+-- name: ListCustomerDetails :many
+-- Customers with their address, city and country, for comparing the two
+-- sides of duplicate pairs.
+SELECT c.customer_id, c.store_id, c.first_name, c.last_name, c.email,
+       c.address_id, c.activebool, c.create_date, c.last_update, c.active,
+       c.email_verified_at, c.pending_email, c.anonymized_at,
+       a.address, a.address2, a.district, a.postal_code, a.phone,
+       ci.city, co.country
+FROM customer c
+JOIN address a ON a.address_id = c.address_id
+JOIN city ci ON ci.city_id = a.city_id
+JOIN country co ON co.country_id = ci.country_id
+WHERE c.customer_id = ANY(@customer_ids::int[]);
+
+-- name: LockCustomersForMerge :many
+-- Locks the customers of a merge, reporting whether each has already been
+-- merged into someone else.
+SELECT c.customer_id, c.anonymized_at,
+       EXISTS (SELECT 1 FROM customer_merge m WHERE m.merged_customer_id = c.customer_id) AS merged
+FROM customer c
+WHERE c.customer_id = ANY(@customer_ids::int[])
+ORDER BY c.customer_id
+FOR UPDATE OF c;
+
+-- name: MoveCustomerRentals :execrows
+UPDATE rental SET customer_id = @surviving_customer_id WHERE customer_id = @merged_customer_id;
+
+-- name: MoveCustomerPayments :execrows
+UPDATE payment SET customer_id = @surviving_customer_id WHERE customer_id = @merged_customer_id;
+
+-- name: CancelMergedCustomerSubscription :execrows
+-- Cancels the merged customer's live subscription if the surviving customer
+-- has one of their own.
+UPDATE subscription
+SET status = 'canceled',
+    canceled_at = now(),
+    next_billing_at = NULL,
+    cancel_at_period_end = false,
+    pause_at_period_end = false
+WHERE customer_id = @merged_customer_id::int
+  AND status <> 'canceled'
+  AND EXISTS (
+      SELECT 1 FROM subscription s
+      WHERE s.customer_id = @surviving_customer_id::int AND s.status <> 'canceled'
+  );
+
+-- name: MoveCustomerSubscriptions :execrows
+UPDATE subscription SET customer_id = @surviving_customer_id WHERE customer_id = @merged_customer_id;
+
+-- name: MoveCustomerSubscriptionPayments :exec
+UPDATE subscription_payment SET customer_id = @surviving_customer_id WHERE customer_id = @merged_customer_id;
+
+-- name: MoveCustomerGiftCards :execrows
+UPDATE stored_value_account
+SET customer_id = @surviving_customer_id::int
+WHERE customer_id = @merged_customer_id::int AND kind = 'gift_card';
+
+-- name: LockStoreCreditForMerge :many
+-- Locks the store credit accounts of the customers of a merge.
+SELECT account_id, customer_id::int AS customer_id, balance::text AS balance
+FROM stored_value_account
+WHERE kind = 'store_credit' AND customer_id = ANY(@customer_ids::int[])
+ORDER BY account_id
+FOR UPDATE;
+
+-- name: MoveStoreCreditAccount :exec
+UPDATE stored_value_account
+SET customer_id = @customer_id::int
+WHERE account_id = @account_id;
+
+-- name: TransferStoreCredit :exec
+-- Moves the whole balance of one store credit account to another as a
+-- balanced transfer transaction.
+WITH source AS (
+    SELECT a.account_id, a.balance
+    FROM stored_value_account a
+    WHERE a.account_id = @from_account_id::int AND a.balance > 0
+), txn AS (
+    INSERT INTO stored_value_transaction (kind, staff_id, note)
+    SELECT 'transfer', @staff_id::int, 'Customer merge' FROM source
+    RETURNING transaction_id
+), debited AS (
+    UPDATE stored_value_account a
+    SET balance = 0
+    FROM source
+    WHERE a.account_id = source.account_id
+), credited AS (
+    UPDATE stored_value_account a
+    SET balance = a.balance + source.balance
+    FROM source
+    WHERE a.account_id = @to_account_id::int
+    RETURNING a.account_id, a.balance
+)
+INSERT INTO stored_value_entry (transaction_id, account_id, amount, balance_after)
+SELECT txn.transaction_id, source.account_id, -source.balance, 0
+FROM txn, source
+UNION ALL
+SELECT txn.transaction_id, credited.account_id, source.balance, credited.balance
+FROM txn, source, credited;
+
+-- name: DeactivateStoredValueAccount :exec
+UPDATE stored_value_account SET active = false WHERE account_id = $1;
+
+-- name: LockLoyaltyAccountForMerge :one
+SELECT points_balance, lifetime_points
+FROM loyalty_account
+WHERE customer_id = $1
+FOR UPDATE;
+
+-- name: FoldLoyaltyAccount :exec
+-- Adds the merged customer's points to the surviving customer's account,
+-- creating it if needed, and empties the merged customer's account. Both
+-- sides are recorded as adjustments.
+WITH target AS (
+    INSERT INTO loyalty_account (customer_id, points_balance, lifetime_points)
+    VALUES (@surviving_customer_id::int, @points::int, @lifetime_points::int)
+    ON CONFLICT (customer_id) DO UPDATE
+    SET points_balance = loyalty_account.points_balance + EXCLUDED.points_balance,
+        lifetime_points = loyalty_account.lifetime_points + EXCLUDED.lifetime_points
+    RETURNING points_balance
+), emptied AS (
+    UPDATE loyalty_account
+    SET points_balance = 0, lifetime_points = 0
+    WHERE customer_id = @merged_customer_id::int
+)
+INSERT INTO loyalty_transaction (customer_id, kind, points, balance_after, note)
+SELECT @merged_customer_id::int, 'adjust', 0 - @points::int, 0, 'Merged into another customer'
+WHERE @points::int > 0
+UNION ALL
+SELECT @surviving_customer_id::int, 'adjust', @points::int, target.points_balance, 'Merged from another customer'
+FROM target
+WHERE @points::int > 0;
+
+-- name: MoveCustomerReviews :execrows
+-- Moves the reviews of films the surviving customer has not reviewed.
+UPDATE film_review
+SET customer_id = @surviving_customer_id::int
+WHERE customer_id = @merged_customer_id::int
+  AND film_id NOT IN (
+      SELECT r.film_id FROM film_review r WHERE r.customer_id = @surviving_customer_id::int
+  );
+
+-- name: MoveCustomerWishlist :execrows
+-- Films already on the surviving customer's wishlist keep their entry.
+INSERT INTO wishlist_item (customer_id, film_id, added_at)
+SELECT @surviving_customer_id::int, w.film_id, w.added_at
+FROM wishlist_item w
+WHERE w.customer_id = @merged_customer_id::int
+ON CONFLICT (customer_id, film_id) DO NOTHING;
+
+-- name: MoveCustomerPromotionUses :execrows
+UPDATE payment_promotion SET customer_id = @surviving_customer_id WHERE customer_id = @merged_customer_id;
+
+-- name: DeactivateCustomer :exec
+UPDATE customer SET activebool = false, active = 0 WHERE customer_id = $1;
+
+-- name: CreateCustomerMerge :one
+INSERT INTO customer_merge (surviving_customer_id, merged_customer_id, rentals_moved, payments_moved,
+                            subscriptions_moved, subscriptions_canceled, gift_cards_moved,
+                            store_credit_moved, loyalty_points_moved, reviews_moved, reviews_dropped,
+                            wishlist_items_moved, promotion_uses_moved, merged_by)
+VALUES (@surviving_customer_id, @merged_customer_id, @rentals_moved, @payments_moved,
+        @subscriptions_moved, @subscriptions_canceled, @gift_cards_moved,
+        CAST(sqlc.arg(store_credit_moved)::text AS NUMERIC), @loyalty_points_moved, @reviews_moved, @reviews_dropped,
+        @wishlist_items_moved, @promotion_uses_moved, @merged_by)
+RETURNING merge_id, surviving_customer_id, merged_customer_id, rentals_moved, payments_moved,
+          subscriptions_moved, subscriptions_canceled, gift_cards_moved,
+          store_credit_moved::text AS store_credit_moved, loyalty_points_moved, reviews_moved,
+          reviews_dropped, wishlist_items_moved, promotion_uses_moved, merged_by, merged_at;
+
+-- name: ResolveMergedCustomerDuplicates :exec
+-- Marks the merged pair as merged and drops the merged customer's other
+-- pending pairs.
+WITH merged AS (
+    UPDATE customer_duplicate
+    SET status = 'merged', reviewed_at = now(), reviewed_by = @staff_id::int
+    WHERE status = 'pending'
+      AND customer_id = least(@surviving_customer_id::int, @merged_customer_id::int)
+      AND other_customer_id = greatest(@surviving_customer_id::int, @merged_customer_id::int)
+    RETURNING duplicate_id
+)
+DELETE FROM customer_duplicate
+WHERE status = 'pending'
+  AND (customer_id = @merged_customer_id::int OR other_customer_id = @merged_customer_id::int)
+  AND duplicate_id NOT IN (SELECT duplicate_id FROM merged);
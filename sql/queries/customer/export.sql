@@ -0,0 +1,80 @@
+-- name: ListCustomerRentalHistory :many
+-- Every rental of a customer with the film rented, oldest first.
+SELECT r.rental_id, r.rental_date, r.return_date, i.store_id, f.film_id, f.title
+FROM rental r
+JOIN inventory i ON i.inventory_id = r.inventory_id
+JOIN film f ON f.film_id = i.film_id
+WHERE r.customer_id = $1
+ORDER BY r.rental_date, r.rental_id;
+
+-- name: ListCustomerPaymentHistory :many
+-- Every payment of a customer, oldest first.
+SELECT payment_id, rental_id, amount::text AS amount, net_amount::text AS net_amount,
+       tax_amount::text AS tax_amount, charge_type, payment_date
+FROM payment
+WHERE customer_id = $1
+ORDER BY payment_date, payment_id;
+
+-- name: ListCustomerReviewHistory :many
+-- Every film review a customer has written, oldest first.
+SELECT fr.review_id, fr.film_id, f.title, fr.rating, fr.body, fr.status, fr.created_at
+FROM film_review fr
+JOIN film f ON f.film_id = fr.film_id
+WHERE fr.customer_id = $1
+ORDER BY fr.created_at, fr.review_id;
+
+-- name: ListCustomerWishlistHistory :many
+-- Every film on a customer's wishlist, oldest first.
+SELECT w.film_id, f.title, w.added_at
+FROM wishlist_item w
+JOIN film f ON f.film_id = w.film_id
+WHERE w.customer_id = $1
+ORDER BY w.added_at, w.film_id;
+
+-- name: GetCustomerLoyaltyRecord :one
+SELECT tier, points_balance, lifetime_points
+FROM loyalty_account
+WHERE customer_id = $1;
+
+-- name: ListCustomerLoyaltyHistory :many
+-- Every loyalty points transaction of a customer, oldest first.
+SELECT loyalty_transaction_id, kind, points, balance_after, payment_id, rental_id,
+       coalesce(note, '')::text AS note, created_at
+FROM loyalty_transaction
+WHERE customer_id = $1
+ORDER BY loyalty_transaction_id;
+
+-- name: ListCustomerStoredValueAccounts :many
+-- A customer's gift cards and store credit.
+SELECT account_id, kind, code, balance::text AS balance, active, create_date
+FROM stored_value_account
+WHERE customer_id = @customer_id::int
+ORDER BY account_id;
+
+-- name: ListCustomerStoredValueHistory :many
+-- Every entry on a customer's gift cards and store credit, oldest first.
+SELECT e.entry_id, e.account_id, t.kind, e.amount::text AS amount,
+       e.balance_after::text AS balance_after, t.payment_id,
+       coalesce(t.note, '')::text AS note, t.created_at
+FROM stored_value_entry e
+JOIN stored_value_account a ON a.account_id = e.account_id
+JOIN stored_value_transaction t ON t.transaction_id = e.transaction_id
+WHERE a.customer_id = @customer_id::int
+ORDER BY e.entry_id;
+
+-- name: ListCustomerSubscriptionHistory :many
+-- Every subscription of a customer, oldest first.
+SELECT s.subscription_id, p.name AS plan_name, s.status, s.current_period_start,
+       s.current_period_end, s.create_date, s.canceled_at
+FROM subscription s
+JOIN subscription_plan p ON p.plan_id = s.plan_id
+WHERE s.customer_id = $1
+ORDER BY s.subscription_id;
+
+-- name: ListCustomerSubscriptionPaymentHistory :many
+-- Every subscription charge attempt of a customer, oldest first.
+SELECT subscription_payment_id, subscription_id, amount::text AS amount, period_start,
+       period_end, status, created_at
+FROM subscription_payment
+WHERE customer_id = $1
+ORDER BY subscription_payment_id;
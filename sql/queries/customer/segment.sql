@@ -0,0 +1,90 @@
+-- name: GetSegment :one
+SELECT segment_id, name, description, rule, member_count, evaluated_at, created_at, last_update
+FROM customer_segment
+WHERE segment_id = $1;
+
+-- name: ListSegments :many
+SELECT segment_id, name, description, rule, member_count, evaluated_at, created_at, last_update
+FROM customer_segment
+ORDER BY name, segment_id
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: CountSegments :one
+SELECT count(*) FROM customer_segment;
+
+-- name: CreateSegment :one
+INSERT INTO customer_segment (name, description, rule)
+VALUES (@name, @description, @rule)
+RETURNING segment_id;
+
+-- name: UpdateSegment :execrows
+UPDATE customer_segment
+SET name = @name, description = @description, rule = @rule
+WHERE segment_id = @segment_id;
+
+-- name: DeleteSegment :execrows
+DELETE FROM customer_segment WHERE segment_id = $1;
+
+-- name: LockSegment :one
+SELECT segment_id FROM customer_segment WHERE segment_id = $1 FOR UPDATE;
+
+-- name: DeleteSegmentMembers :exec
+DELETE FROM customer_segment_member WHERE segment_id = $1;
+
+-- name: InsertSegmentMembers :exec
+INSERT INTO customer_segment_member (segment_id, customer_id)
+SELECT @segment_id, unnest(@customer_ids::int[]);
+
+-- name: SetSegmentEvaluated :exec
+UPDATE customer_segment
+SET member_count = @member_count, evaluated_at = now()
+WHERE segment_id = @segment_id;
+
+-- name: ListSegmentMembers :many
+SELECT c.customer_id, c.store_id, c.first_name, c.last_name, c.email,
+       c.address_id, c.activebool, c.create_date, c.last_update, c.active,
+       c.email_verified_at, c.pending_email, c.anonymized_at
+FROM customer_segment_member m
+JOIN customer c ON c.customer_id = m.customer_id
+WHERE m.segment_id = @segment_id
+ORDER BY c.customer_id
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: CountSegmentMembers :one
+SELECT count(*) FROM customer_segment_member WHERE segment_id = $1;
+
+-- ListSegmentCandidates returns what segment rules can test about every
+-- customer who has not been anonymized. spend_percentile is the percentage
+-- of the customer's store's customers who spent less than them.
+
+-- name: ListSegmentCandidates :many
+WITH spend AS (
+    SELECT customer_id, sum(amount) AS lifetime_spend
+    FROM payment
+    GROUP BY customer_id
+), rentals AS (
+    SELECT customer_id, count(*) AS total_rentals, max(rental_date) AS last_rental_date
+    FROM rental
+    GROUP BY customer_id
+)
+SELECT c.customer_id, c.store_id, c.activebool,
+       coalesce(s.lifetime_spend, 0)::float8 AS lifetime_spend,
+       (percent_rank() OVER (PARTITION BY c.store_id ORDER BY coalesce(s.lifetime_spend, 0)) * 100)::float8 AS spend_percentile,
+       coalesce(r.total_rentals, 0)::int AS total_rentals,
+       r.last_rental_date::timestamptz AS last_rental_date
+FROM customer c
+LEFT JOIN spend s ON s.customer_id = c.customer_id
+LEFT JOIN rentals r ON r.customer_id = c.customer_id
+WHERE c.anonymized_at IS NULL
+ORDER BY c.customer_id;
+
+-- name: ListCustomerCategoryRentals :many
+SELECT r.customer_id, c.category_id, c.name, count(*)::int AS rentals
+FROM rental r
+JOIN inventory i ON i.inventory_id = r.inventory_id
+JOIN film_category fc ON fc.film_id = i.film_id
+JOIN category c ON c.category_id = fc.category_id
+JOIN customer cu ON cu.customer_id = r.customer_id
+WHERE cu.anonymized_at IS NULL
+GROUP BY r.customer_id, c.category_id, c.name
+ORDER BY r.customer_id, count(*) DESC, c.name;
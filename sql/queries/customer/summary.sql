@@ -0,0 +1,60 @@
+-- Rental and spending summaries of customers, for the ones in customer_ids
+-- that exist. A rental is overdue once it has been out longer than the
+-- film's rental duration; average days kept only counts returned rentals.
+
+-- name: ListCustomerSummaries :many
+WITH rentals AS (
+    SELECT r.customer_id,
+           min(r.rental_date)::timestamptz AS first_rental_date,
+           max(r.rental_date)::timestamptz AS last_rental_date,
+           count(*)::int AS total_rentals,
+           (count(*) FILTER (WHERE r.return_date IS NULL))::int AS open_rentals,
+           (count(*) FILTER (
+               WHERE r.return_date IS NULL
+                 AND r.rental_date + f.rental_duration * interval '1 day' < now()
+           ))::int AS overdue_rentals,
+           coalesce(avg(extract(epoch FROM r.return_date - r.rental_date) / 86400)
+                    FILTER (WHERE r.return_date IS NOT NULL), 0)::float8 AS average_days_kept
+    FROM rental r
+    JOIN inventory i ON i.inventory_id = r.inventory_id
+    JOIN film f ON f.film_id = i.film_id
+    WHERE r.customer_id = ANY(@customer_ids::int[])
+    GROUP BY r.customer_id
+), spend AS (
+    SELECT customer_id, sum(amount) AS lifetime_spend
+    FROM payment
+    WHERE customer_id = ANY(@customer_ids::int[])
+    GROUP BY customer_id
+)
+SELECT c.customer_id,
+       r.first_rental_date,
+       r.last_rental_date,
+       coalesce(r.total_rentals, 0)::int AS total_rentals,
+       coalesce(r.open_rentals, 0)::int AS open_rentals,
+       coalesce(r.overdue_rentals, 0)::int AS overdue_rentals,
+       coalesce(r.average_days_kept, 0)::float8 AS average_days_kept,
+       coalesce(s.lifetime_spend, 0)::text AS lifetime_spend
+FROM customer c
+LEFT JOIN rentals r ON r.customer_id = c.customer_id
+LEFT JOIN spend s ON s.customer_id = c.customer_id
+WHERE c.customer_id = ANY(@customer_ids::int[])
+ORDER BY c.customer_id;
+
+-- ListCustomerFavouriteCategories returns each customer's most rented
+-- categories, at most max_per_customer of them, most rented first.
+
+-- name: ListCustomerFavouriteCategories :many
+WITH ranked AS (
+    SELECT r.customer_id, c.category_id, c.name, count(*) AS rentals,
+           row_number() OVER (PARTITION BY r.customer_id ORDER BY count(*) DESC, c.name) AS rank
+    FROM rental r
+    JOIN inventory i ON i.inventory_id = r.inventory_id
+    JOIN film_category fc ON fc.film_id = i.film_id
+    JOIN category c ON c.category_id = fc.category_id
+    WHERE r.customer_id = ANY(@customer_ids::int[])
+    GROUP BY r.customer_id, c.category_id, c.name
+)
+SELECT customer_id, category_id, name, rentals::int AS rentals
+FROM ranked
+WHERE rank <= @max_per_customer::int
+ORDER BY customer_id, rank;
@@ -1,20 +1,48 @@
 -- name: GetActor :one
-SELECT actor_id, first_name, last_name, last_update
+SELECT actor_id, first_name, last_name, last_update, archived_at
 FROM actor
 WHERE actor_id = $1;
 
 -- name: ListActors :many
-SELECT actor_id, first_name, last_name, last_update
+SELECT actor_id, first_name, last_name, last_update, archived_at
 FROM actor
+WHERE @include_archived::bool OR archived_at IS NULL
 ORDER BY last_name, first_name
-LIMIT $1 OFFSET $2;
+LIMIT @page_limit OFFSET @page_offset;
 
 -- name: CountActors :one
-SELECT count(*) FROM actor;
+SELECT count(*) FROM actor
+WHERE @include_archived::bool OR archived_at IS NULL;
+
+-- name: SearchActors :many
+-- Actors whose first name, last name or full name starts with the query,
+-- then actors whose name contains a word similar to it.
+SELECT actor_id, first_name, last_name, last_update, archived_at
+FROM actor
+WHERE (first_name ILIKE @prefix_pattern::text
+       OR last_name ILIKE @prefix_pattern::text
+       OR (first_name || ' ' || last_name) ILIKE @prefix_pattern::text
+       OR @query::text <% (first_name || ' ' || last_name))
+  AND (@include_archived::bool OR archived_at IS NULL)
+ORDER BY (first_name ILIKE @prefix_pattern::text
+          OR last_name ILIKE @prefix_pattern::text
+          OR (first_name || ' ' || last_name) ILIKE @prefix_pattern::text) DESC,
+         word_similarity(@query::text, first_name || ' ' || last_name) DESC,
+         last_name, first_name
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: CountSearchActors :one
+SELECT count(*)
+FROM actor
+WHERE (first_name ILIKE @prefix_pattern::text
+       OR last_name ILIKE @prefix_pattern::text
+       OR (first_name || ' ' || last_name) ILIKE @prefix_pattern::text
+       OR @query::text <% (first_name || ' ' || last_name))
+  AND (@include_archived::bool OR archived_at IS NULL);
 
 -- name: ListActorsByFilm :many
 -- Returns all actors for a given film (no pagination needed, typically small set).
-SELECT a.actor_id, a.first_name, a.last_name, a.last_update
+SELECT a.actor_id, a.first_name, a.last_name, a.last_update, a.archived_at
 FROM actor a
 JOIN film_actor fa ON a.actor_id = fa.actor_id
 WHERE fa.film_id = $1
@@ -23,13 +51,65 @@ ORDER BY a.last_name, a.first_name;
 -- name: CreateActor :one
 INSERT INTO actor (first_name, last_name)
 VALUES ($1, $2)
-RETURNING actor_id, first_name, last_name, last_update;
+RETURNING actor_id, first_name, last_name, last_update, archived_at;
 
 -- name: UpdateActor :one
 UPDATE actor
 SET first_name = $2, last_name = $3
 WHERE actor_id = $1
-RETURNING actor_id, first_name, last_name, last_update;
+RETURNING actor_id, first_name, last_name, last_update, archived_at;
+
+-- Deleting an actor archives them: archived actors stay in the casts of
+-- their films but are otherwise hidden from customers.
+
+-- name: ArchiveActor :exec
+UPDATE actor SET archived_at = now()
+WHERE actor_id = $1 AND archived_at IS NULL;
+
+-- name: RestoreActor :exec
+UPDATE actor SET archived_at = NULL
+WHERE actor_id = $1;
+
+-- ListActorFilmography returns an actor's films once for each category
+-- they are in, like the actor_info view; films without a category come
+-- last, with category 0. Archived films are left out.
+
+-- name: ListActorFilmography :many
+SELECT coalesce(c.category_id, 0)::int AS category_id,
+       coalesce(c.name, '')::text AS category_name,
+       f.film_id, f.title, f.release_year, f.rating
+FROM film_actor fa
+JOIN film f ON f.film_id = fa.film_id
+LEFT JOIN film_category fc ON fc.film_id = f.film_id
+LEFT JOIN category c ON c.category_id = fc.category_id
+WHERE fa.actor_id = @actor_id AND f.archived_at IS NULL
+ORDER BY c.name NULLS LAST, f.title;
+
+-- ListActorCoStars returns the actors who appear in most films with an
+-- actor, leaving out archived actors and films.
+
+-- name: ListActorCoStars :many
+SELECT a.actor_id, a.first_name, a.last_name, a.last_update, a.archived_at,
+       count(*)::int AS films_together
+FROM film_actor fa
+JOIN film f ON f.film_id = fa.film_id
+JOIN film_actor co ON co.film_id = fa.film_id AND co.actor_id <> fa.actor_id
+JOIN actor a ON a.actor_id = co.actor_id
+WHERE fa.actor_id = @actor_id AND f.archived_at IS NULL AND a.archived_at IS NULL
+GROUP BY a.actor_id
+ORDER BY films_together DESC, a.last_name, a.first_name
+LIMIT @max_results;
+
+-- GetActorStats counts an actor's films that are not archived and the
+-- rentals of all their films.
 
--- name: DeleteActor :exec
-DELETE FROM actor WHERE actor_id = $1;
+-- name: GetActorStats :one
+SELECT (SELECT count(*)
+        FROM film_actor fa
+        JOIN film f ON f.film_id = fa.film_id
+        WHERE fa.actor_id = @actor_id AND f.archived_at IS NULL)::int AS film_count,
+       (SELECT count(*)
+        FROM rental r
+        JOIN inventory i ON i.inventory_id = r.inventory_id
+        JOIN film_actor fa ON fa.film_id = i.film_id
+        WHERE fa.actor_id = @actor_id) AS rental_count;
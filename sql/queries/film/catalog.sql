@@ -0,0 +1,31 @@
+-- name: FindActorByName :one
+-- Matches names case-insensitively, as the catalog stores them in capitals.
+SELECT actor_id
+FROM actor
+WHERE upper(first_name) = upper(@first_name::text)
+  AND upper(last_name) = upper(@last_name::text)
+ORDER BY actor_id
+LIMIT 1;
+
+-- name: ExportFilms :many
+-- A page of the catalog in film_id order, after @after_film_id, with the
+-- language, actors and categories of each film by name.
+SELECT f.film_id, f.title, f.description, f.release_year, f.language_id,
+       f.original_language_id, f.rental_duration, f.rental_rate, f.length,
+       f.replacement_cost, f.rating, f.special_features, f.last_update,
+       trim(l.name)::text AS language_name,
+       coalesce(trim(ol.name), '')::text AS original_language_name,
+       coalesce((SELECT array_agg(a.first_name || ' ' || a.last_name ORDER BY a.last_name, a.first_name)
+                 FROM film_actor fa
+                 JOIN actor a ON a.actor_id = fa.actor_id
+                 WHERE fa.film_id = f.film_id), '{}')::text[] AS actor_names,
+       coalesce((SELECT array_agg(c.name ORDER BY c.name)
+                 FROM film_category fc
+                 JOIN category c ON c.category_id = fc.category_id
+                 WHERE fc.film_id = f.film_id), '{}')::text[] AS category_names
+FROM film f
+JOIN language l ON l.language_id = f.language_id
+LEFT JOIN language ol ON ol.language_id = f.original_language_id
+WHERE f.film_id > @after_film_id::int
+ORDER BY f.film_id
+LIMIT @page_limit;
@@ -18,3 +18,31 @@ FROM category c
 JOIN film_category fc ON c.category_id = fc.category_id
 WHERE fc.film_id = $1
 ORDER BY c.name;
+
+-- name: CreateCategory :one
+INSERT INTO category (name)
+VALUES ($1)
+RETURNING category_id, name, last_update;
+
+-- name: UpdateCategory :one
+UPDATE category
+SET name = $2
+WHERE category_id = $1
+RETURNING category_id, name, last_update;
+
+-- name: DeleteCategory :exec
+DELETE FROM category WHERE category_id = $1;
+
+-- name: ReassignFilmCategories :exec
+-- Moves films from one category to another. Films already in the target
+-- category keep that link and are left in the old one too, to be removed
+-- with RemoveCategoryFromAllFilms.
+UPDATE film_category fc
+SET category_id = @to_category_id
+WHERE fc.category_id = @from_category_id
+  AND NOT EXISTS (
+      SELECT 1 FROM film_category x
+      WHERE x.film_id = fc.film_id AND x.category_id = @to_category_id);
+
+-- name: RemoveCategoryFromAllFilms :exec
+DELETE FROM film_category WHERE category_id = $1;
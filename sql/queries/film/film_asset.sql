@@ -0,0 +1,33 @@
+-- name: CreateFilmAsset :one
+INSERT INTO film_asset (film_id, kind, content_type, width, height, sizes)
+VALUES (@film_id, @kind, @content_type, @width, @height, @sizes::text[])
+RETURNING *;
+
+-- name: GetFilmAsset :one
+SELECT * FROM film_asset
+WHERE asset_id = @asset_id;
+
+-- name: ListFilmAssets :many
+SELECT * FROM film_asset
+WHERE film_id = @film_id
+ORDER BY kind, asset_id;
+
+-- ListFilmPosters returns the poster of each of the films that has one.
+
+-- name: ListFilmPosters :many
+SELECT DISTINCT ON (film_id) *
+FROM film_asset
+WHERE film_id = ANY(@film_ids::int[]) AND kind = 'poster'
+ORDER BY film_id, asset_id DESC;
+
+-- name: DeleteFilmAsset :one
+DELETE FROM film_asset
+WHERE asset_id = @asset_id AND film_id = @film_id
+RETURNING *;
+
+-- DeleteOtherFilmPosters removes the posters a newly uploaded one replaces.
+
+-- name: DeleteOtherFilmPosters :many
+DELETE FROM film_asset
+WHERE film_id = @film_id AND kind = 'poster' AND asset_id <> @keep_asset_id
+RETURNING *;
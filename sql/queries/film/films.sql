@@ -1,34 +1,198 @@
 -- name: GetFilm :one
 SELECT film_id, title, description, release_year, language_id,
        original_language_id, rental_duration, rental_rate, length,
-       replacement_cost, rating, special_features, last_update
+       replacement_cost, rating, special_features, last_update, archived_at
 FROM film
 WHERE film_id = $1;
 
+-- Browsing filters: an empty list, zero or NULL leaves a filter unset.
+-- Films must have every requested special feature. Archived films are left
+-- out unless include_archived is set. Sorting by rating puts films without
+-- reviews last in either direction.
+
 -- name: ListFilms :many
+WITH popularity AS (
+    SELECT i.film_id, count(*) AS rentals
+    FROM rental r
+    JOIN inventory i ON i.inventory_id = r.inventory_id
+    WHERE @sort_by::text = 'popularity'
+    GROUP BY i.film_id
+)
+SELECT f.film_id, f.title, f.description, f.release_year, f.language_id,
+       f.original_language_id, f.rental_duration, f.rental_rate, f.length,
+       f.replacement_cost, f.rating, f.special_features, f.last_update, f.archived_at
+FROM film f
+LEFT JOIN popularity p ON p.film_id = f.film_id
+LEFT JOIN film_rating fr ON fr.film_id = f.film_id
+WHERE (cardinality(@ratings::text[]) = 0 OR f.rating::text = ANY(@ratings::text[]))
+  AND (@category_id::int = 0 OR EXISTS (
+         SELECT 1 FROM film_category fc2
+         WHERE fc2.film_id = f.film_id AND fc2.category_id = @category_id::int))
+  AND (@actor_id::int = 0 OR EXISTS (
+         SELECT 1 FROM film_actor fa
+         WHERE fa.film_id = f.film_id AND fa.actor_id = @actor_id::int))
+  AND (@language_id::int = 0 OR f.language_id = @language_id::int)
+  AND (@min_release_year::int = 0 OR f.release_year >= @min_release_year::int)
+  AND (@max_release_year::int = 0 OR f.release_year <= @max_release_year::int)
+  AND (@min_length::int = 0 OR f.length >= @min_length::int)
+  AND (@max_length::int = 0 OR f.length <= @max_length::int)
+  AND (sqlc.narg('min_rental_rate')::numeric IS NULL OR f.rental_rate >= sqlc.narg('min_rental_rate')::numeric)
+  AND (sqlc.narg('max_rental_rate')::numeric IS NULL OR f.rental_rate <= sqlc.narg('max_rental_rate')::numeric)
+  AND (cardinality(@special_features::text[]) = 0 OR f.special_features @> @special_features::text[])
+  AND (@include_archived::bool OR f.archived_at IS NULL)
+ORDER BY
+  CASE WHEN @sort_by::text = 'release_year' AND NOT @descending::bool THEN f.release_year END,
+  CASE WHEN @sort_by::text = 'release_year' AND @descending::bool THEN f.release_year END DESC,
+  CASE WHEN @sort_by::text = 'rental_rate' AND NOT @descending::bool THEN f.rental_rate END,
+  CASE WHEN @sort_by::text = 'rental_rate' AND @descending::bool THEN f.rental_rate END DESC,
+  CASE WHEN @sort_by::text = 'popularity' AND NOT @descending::bool THEN coalesce(p.rentals, 0) END,
+  CASE WHEN @sort_by::text = 'popularity' AND @descending::bool THEN coalesce(p.rentals, 0) END DESC,
+  CASE WHEN @sort_by::text = 'rating' AND NOT @descending::bool THEN fr.average_rating END NULLS LAST,
+  CASE WHEN @sort_by::text = 'rating' AND @descending::bool THEN fr.average_rating END DESC NULLS LAST,
+  CASE WHEN @sort_by::text = 'title' AND @descending::bool THEN f.title END DESC,
+  f.title, f.film_id
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: CountFilms :one
+SELECT count(*)
+FROM film f
+WHERE (cardinality(@ratings::text[]) = 0 OR f.rating::text = ANY(@ratings::text[]))
+  AND (@category_id::int = 0 OR EXISTS (
+         SELECT 1 FROM film_category fc2
+         WHERE fc2.film_id = f.film_id AND fc2.category_id = @category_id::int))
+  AND (@actor_id::int = 0 OR EXISTS (
+         SELECT 1 FROM film_actor fa
+         WHERE fa.film_id = f.film_id AND fa.actor_id = @actor_id::int))
+  AND (@language_id::int = 0 OR f.language_id = @language_id::int)
+  AND (@min_release_year::int = 0 OR f.release_year >= @min_release_year::int)
+  AND (@max_release_year::int = 0 OR f.release_year <= @max_release_year::int)
+  AND (@min_length::int = 0 OR f.length >= @min_length::int)
+  AND (@max_length::int = 0 OR f.length <= @max_length::int)
+  AND (sqlc.narg('min_rental_rate')::numeric IS NULL OR f.rental_rate >= sqlc.narg('min_rental_rate')::numeric)
+  AND (sqlc.narg('max_rental_rate')::numeric IS NULL OR f.rental_rate <= sqlc.narg('max_rental_rate')::numeric)
+  AND (cardinality(@special_features::text[]) = 0 OR f.special_features @> @special_features::text[])
+  AND (@include_archived::bool OR f.archived_at IS NULL);
+
+-- ListFilmFacets counts the films matching the filters per rating, category
+-- and language. Each facet ignores its own filter so that the counts show
+-- what selecting another value of it would match.
+
+-- name: ListFilmFacets :many
+SELECT 'rating'::text AS facet, 0::int AS value_id, f.rating::text AS value, count(*) AS film_count
+FROM film f
+WHERE (@category_id::int = 0 OR EXISTS (
+         SELECT 1 FROM film_category fc2
+         WHERE fc2.film_id = f.film_id AND fc2.category_id = @category_id::int))
+  AND (@actor_id::int = 0 OR EXISTS (
+         SELECT 1 FROM film_actor fa
+         WHERE fa.film_id = f.film_id AND fa.actor_id = @actor_id::int))
+  AND (@language_id::int = 0 OR f.language_id = @language_id::int)
+  AND (@min_release_year::int = 0 OR f.release_year >= @min_release_year::int)
+  AND (@max_release_year::int = 0 OR f.release_year <= @max_release_year::int)
+  AND (@min_length::int = 0 OR f.length >= @min_length::int)
+  AND (@max_length::int = 0 OR f.length <= @max_length::int)
+  AND (sqlc.narg('min_rental_rate')::numeric IS NULL OR f.rental_rate >= sqlc.narg('min_rental_rate')::numeric)
+  AND (sqlc.narg('max_rental_rate')::numeric IS NULL OR f.rental_rate <= sqlc.narg('max_rental_rate')::numeric)
+  AND (cardinality(@special_features::text[]) = 0 OR f.special_features @> @special_features::text[])
+  AND (@include_archived::bool OR f.archived_at IS NULL)
+  AND f.rating IS NOT NULL
+GROUP BY f.rating
+UNION ALL
+SELECT 'category'::text, c.category_id, c.name::text, count(*)
+FROM film f
+JOIN film_category fc ON fc.film_id = f.film_id
+JOIN category c ON c.category_id = fc.category_id
+WHERE (cardinality(@ratings::text[]) = 0 OR f.rating::text = ANY(@ratings::text[]))
+  AND (@actor_id::int = 0 OR EXISTS (
+         SELECT 1 FROM film_actor fa
+         WHERE fa.film_id = f.film_id AND fa.actor_id = @actor_id::int))
+  AND (@language_id::int = 0 OR f.language_id = @language_id::int)
+  AND (@min_release_year::int = 0 OR f.release_year >= @min_release_year::int)
+  AND (@max_release_year::int = 0 OR f.release_year <= @max_release_year::int)
+  AND (@min_length::int = 0 OR f.length >= @min_length::int)
+  AND (@max_length::int = 0 OR f.length <= @max_length::int)
+  AND (sqlc.narg('min_rental_rate')::numeric IS NULL OR f.rental_rate >= sqlc.narg('min_rental_rate')::numeric)
+  AND (sqlc.narg('max_rental_rate')::numeric IS NULL OR f.rental_rate <= sqlc.narg('max_rental_rate')::numeric)
+  AND (cardinality(@special_features::text[]) = 0 OR f.special_features @> @special_features::text[])
+  AND (@include_archived::bool OR f.archived_at IS NULL)
+GROUP BY c.category_id, c.name
+UNION ALL
+SELECT 'language'::text, l.language_id, rtrim(l.name)::text, count(*)
+FROM film f
+JOIN language l ON l.language_id = f.language_id
+WHERE (cardinality(@ratings::text[]) = 0 OR f.rating::text = ANY(@ratings::text[]))
+  AND (@category_id::int = 0 OR EXISTS (
+         SELECT 1 FROM film_category fc2
+         WHERE fc2.film_id = f.film_id AND fc2.category_id = @category_id::int))
+  AND (@actor_id::int = 0 OR EXISTS (
+         SELECT 1 FROM film_actor fa
+         WHERE fa.film_id = f.film_id AND fa.actor_id = @actor_id::int))
+  AND (@min_release_year::int = 0 OR f.release_year >= @min_release_year::int)
+  AND (@max_release_year::int = 0 OR f.release_year <= @max_release_year::int)
+  AND (@min_length::int = 0 OR f.length >= @min_length::int)
+  AND (@max_length::int = 0 OR f.length <= @max_length::int)
+  AND (sqlc.narg('min_rental_rate')::numeric IS NULL OR f.rental_rate >= sqlc.narg('min_rental_rate')::numeric)
+  AND (sqlc.narg('max_rental_rate')::numeric IS NULL OR f.rental_rate <= sqlc.narg('max_rental_rate')::numeric)
+  AND (cardinality(@special_features::text[]) = 0 OR f.special_features @> @special_features::text[])
+  AND (@include_archived::bool OR f.archived_at IS NULL)
+GROUP BY l.language_id, l.name
+ORDER BY facet, value;
+
+-- Search queries take a to_tsquery expression with every term as a prefix
+-- (e.g. 'academ:* & dino:*'), so partially typed words match.
+
+-- name: SearchFilms :many
 SELECT film_id, title, description, release_year, language_id,
        original_language_id, rental_duration, rental_rate, length,
-       replacement_cost, rating, special_features, last_update
+       replacement_cost, rating, special_features, last_update,
+       ts_headline('english', title, to_tsquery('english', @query::text),
+                   'HighlightAll=true, StartSel=<b>, StopSel=</b>')::text AS title_highlight,
+       ts_headline('english', coalesce(description, ''), to_tsquery('english', @query::text),
+                   'MaxWords=20, MinWords=8, StartSel=<b>, StopSel=</b>')::text AS description_highlight
 FROM film
-ORDER BY title
-LIMIT $1 OFFSET $2;
+WHERE fulltext @@ to_tsquery('english', @query::text)
+  AND archived_at IS NULL
+ORDER BY ts_rank(fulltext, to_tsquery('english', @query::text)) DESC, title
+LIMIT @page_limit OFFSET @page_offset;
 
--- name: CountFilms :one
-SELECT count(*) FROM film;
+-- name: CountSearchFilms :one
+SELECT count(*)
+FROM film
+WHERE fulltext @@ to_tsquery('english', @query::text)
+  AND archived_at IS NULL;
 
--- name: SearchFilms :many
+-- Fuzzy search is the fallback when full-text search finds nothing: titles
+-- containing a word similar to the query (pg_trgm word similarity).
+
+-- name: FuzzySearchFilms :many
 SELECT film_id, title, description, release_year, language_id,
        original_language_id, rental_duration, rental_rate, length,
        replacement_cost, rating, special_features, last_update
 FROM film
-WHERE fulltext @@ plainto_tsquery('english', $1)
-ORDER BY ts_rank(fulltext, plainto_tsquery('english', $1)) DESC
-LIMIT $2 OFFSET $3;
+WHERE @query::text <% title
+  AND archived_at IS NULL
+ORDER BY word_similarity(@query::text, title) DESC, title
+LIMIT @page_limit OFFSET @page_offset;
 
--- name: CountSearchFilms :one
+-- name: CountFuzzySearchFilms :one
 SELECT count(*)
 FROM film
-WHERE fulltext @@ plainto_tsquery('english', $1);
+WHERE @query::text <% title
+  AND archived_at IS NULL;
+
+-- name: SuggestFilms :many
+-- Title suggestions for search-as-you-type: prefix matches first, then
+-- fuzzy matches.
+SELECT film_id, title,
+       ts_headline('simple', title, to_tsquery('simple', @prefix_query::text),
+                   'HighlightAll=true, StartSel=<b>, StopSel=</b>')::text AS highlight
+FROM film
+WHERE (to_tsvector('simple', title) @@ to_tsquery('simple', @prefix_query::text)
+       OR @query::text <% title)
+  AND archived_at IS NULL
+ORDER BY to_tsvector('simple', title) @@ to_tsquery('simple', @prefix_query::text) DESC,
+         word_similarity(@query::text, title) DESC, title
+LIMIT @max_results;
 
 -- name: ListFilmsByCategory :many
 SELECT f.film_id, f.title, f.description, f.release_year, f.language_id,
@@ -36,7 +200,7 @@ SELECT f.film_id, f.title, f.description, f.release_year, f.language_id,
        f.replacement_cost, f.rating, f.special_features, f.last_update
 FROM film f
 JOIN film_category fc ON f.film_id = fc.film_id
-WHERE fc.category_id = $1
+WHERE fc.category_id = $1 AND f.archived_at IS NULL
 ORDER BY f.title
 LIMIT $2 OFFSET $3;
 
@@ -44,7 +208,7 @@ LIMIT $2 OFFSET $3;
 SELECT count(*)
 FROM film f
 JOIN film_category fc ON f.film_id = fc.film_id
-WHERE fc.category_id = $1;
+WHERE fc.category_id = $1 AND f.archived_at IS NULL;
 
 -- name: ListFilmsByActor :many
 SELECT f.film_id, f.title, f.description, f.release_year, f.language_id,
@@ -52,7 +216,7 @@ SELECT f.film_id, f.title, f.description, f.release_year, f.language_id,
        f.replacement_cost, f.rating, f.special_features, f.last_update
 FROM film f
 JOIN film_actor fa ON f.film_id = fa.film_id
-WHERE fa.actor_id = $1
+WHERE fa.actor_id = $1 AND f.archived_at IS NULL
 ORDER BY f.title
 LIMIT $2 OFFSET $3;
 
@@ -60,7 +224,7 @@ LIMIT $2 OFFSET $3;
 SELECT count(*)
 FROM film f
 JOIN film_actor fa ON f.film_id = fa.film_id
-WHERE fa.actor_id = $1;
+WHERE fa.actor_id = $1 AND f.archived_at IS NULL;
 
 -- name: CreateFilm :one
 INSERT INTO film (title, description, release_year, language_id,
@@ -79,7 +243,27 @@ SET title = $2, description = $3, release_year = $4, language_id = $5,
 WHERE film_id = $1
 RETURNING film_id, title, description, release_year, language_id,
           original_language_id, rental_duration, rental_rate, length,
-          replacement_cost, rating, special_features, last_update;
+          replacement_cost, rating, special_features, last_update, archived_at;
+
+-- Deleting a film archives it. Archived films keep their inventory and
+-- rental history but are hidden from customers and get no new inventory.
 
--- name: DeleteFilm :exec
-DELETE FROM film WHERE film_id = $1;
+-- name: ArchiveFilm :one
+-- Archives the film unless copies of it are out on rental, checking and
+-- archiving in one statement. Returns the number of copies out.
+WITH open_rentals AS (
+    SELECT count(*) AS rentals_out
+    FROM rental r
+    JOIN inventory i ON i.inventory_id = r.inventory_id
+    WHERE i.film_id = @film_id AND r.return_date IS NULL
+), archived AS (
+    UPDATE film SET archived_at = now()
+    WHERE film_id = @film_id
+      AND archived_at IS NULL
+      AND (SELECT rentals_out FROM open_rentals) = 0
+)
+SELECT rentals_out FROM open_rentals;
+
+-- name: RestoreFilm :exec
+UPDATE film SET archived_at = NULL
+WHERE film_id = $1;
@@ -10,3 +10,24 @@ ORDER BY name;
 
 -- name: CountLanguages :one
 SELECT count(*) FROM language;
+
+-- name: CreateLanguage :one
+INSERT INTO language (name)
+VALUES ($1)
+RETURNING language_id, name, last_update;
+
+-- name: UpdateLanguage :one
+UPDATE language
+SET name = $2
+WHERE language_id = $1
+RETURNING language_id, name, last_update;
+
+-- name: DeleteLanguage :exec
+DELETE FROM language WHERE language_id = $1;
+
+-- name: ReassignFilmLanguage :exec
+-- Replaces a language with another as both films' language and original language.
+UPDATE film
+SET language_id = CASE WHEN language_id = @from_language_id THEN @to_language_id ELSE language_id END,
+    original_language_id = CASE WHEN original_language_id = @from_language_id THEN @to_language_id ELSE original_language_id END
+WHERE language_id = @from_language_id OR original_language_id = @from_language_id;
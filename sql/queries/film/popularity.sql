@@ -0,0 +1,88 @@
+-- name: RefreshFilmPopularity :exec
+REFRESH MATERIALIZED VIEW CONCURRENTLY film_popularity;
+
+-- ListPopularFilms ranks films by their rentals over the last @window_days
+-- (7, 30 or 90) at a store, or all stores for store 0. Archived films are
+-- left out of all the lists below.
+
+-- name: ListPopularFilms :many
+WITH ranked AS (
+    SELECT p.film_id,
+           CASE @window_days::int
+               WHEN 7 THEN p.rentals_7d
+               WHEN 30 THEN p.rentals_30d
+               ELSE p.rentals_90d
+           END AS rentals
+    FROM film_popularity p
+    WHERE p.store_id = @store_id::int
+)
+SELECT f.film_id, f.title, f.description, f.release_year, f.language_id,
+       f.original_language_id, f.rental_duration, f.rental_rate, f.length,
+       f.replacement_cost, f.rating, f.special_features, f.last_update,
+       rk.rentals::bigint AS rentals
+FROM ranked rk
+JOIN film f ON f.film_id = rk.film_id
+WHERE rk.rentals > 0 AND f.archived_at IS NULL
+ORDER BY rk.rentals DESC, f.title, f.film_id
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: CountPopularFilms :one
+SELECT count(*)
+FROM film_popularity p
+JOIN film f ON f.film_id = p.film_id
+WHERE p.store_id = @store_id::int
+  AND f.archived_at IS NULL
+  AND CASE @window_days::int
+          WHEN 7 THEN p.rentals_7d
+          WHEN 30 THEN p.rentals_30d
+          ELSE p.rentals_90d
+      END > 0;
+
+-- ListTrendingFilms ranks films rented in the last 7 days by how far their
+-- rentals exceed their usual weekly rate over the last 90 days, so films
+-- picking up beat films that are always popular.
+
+-- name: ListTrendingFilms :many
+SELECT f.film_id, f.title, f.description, f.release_year, f.language_id,
+       f.original_language_id, f.rental_duration, f.rental_rate, f.length,
+       f.replacement_cost, f.rating, f.special_features, f.last_update,
+       p.rentals_7d AS rentals
+FROM film_popularity p
+JOIN film f ON f.film_id = p.film_id
+WHERE p.store_id = @store_id::int
+  AND p.rentals_7d > 0
+  AND f.archived_at IS NULL
+ORDER BY p.rentals_7d - p.rentals_90d * 7 / 90.0 DESC, p.rentals_7d DESC, f.title, f.film_id
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: CountTrendingFilms :one
+SELECT count(*)
+FROM film_popularity p
+JOIN film f ON f.film_id = p.film_id
+WHERE p.store_id = @store_id::int
+  AND p.rentals_7d > 0
+  AND f.archived_at IS NULL;
+
+-- ListNewReleases lists films newest release year first, newest additions
+-- to the catalog first within a year. For a store other than 0 only films
+-- it stocks are listed.
+
+-- name: ListNewReleases :many
+SELECT f.film_id, f.title, f.description, f.release_year, f.language_id,
+       f.original_language_id, f.rental_duration, f.rental_rate, f.length,
+       f.replacement_cost, f.rating, f.special_features, f.last_update
+FROM film f
+WHERE f.archived_at IS NULL
+  AND (@store_id::int = 0 OR EXISTS (
+        SELECT 1 FROM inventory i
+        WHERE i.film_id = f.film_id AND i.store_id = @store_id::int))
+ORDER BY f.release_year DESC NULLS LAST, f.film_id DESC
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: CountNewReleases :one
+SELECT count(*)
+FROM film f
+WHERE f.archived_at IS NULL
+  AND (@store_id::int = 0 OR EXISTS (
+        SELECT 1 FROM inventory i
+        WHERE i.film_id = f.film_id AND i.store_id = @store_id::int));
@@ -0,0 +1,79 @@
+-- name: DeleteFilmSimilarities :exec
+DELETE FROM film_similarity;
+
+-- InsertFilmSimilarities scores every pair of films rented by at least
+-- @min_co_rentals of the same customers and keeps the @max_per_film best
+-- of each film. score = co-renters / sqrt(renters of a * renters of b).
+
+-- name: InsertFilmSimilarities :execrows
+WITH customer_film AS (
+    SELECT DISTINCT r.customer_id, i.film_id
+    FROM rental r
+    JOIN inventory i ON i.inventory_id = r.inventory_id
+), film_customers AS (
+    SELECT film_id, count(*) AS customers
+    FROM customer_film
+    GROUP BY film_id
+), pairs AS (
+    SELECT a.film_id, b.film_id AS similar_film_id, count(*) AS co_rentals
+    FROM customer_film a
+    JOIN customer_film b ON b.customer_id = a.customer_id AND b.film_id <> a.film_id
+    GROUP BY a.film_id, b.film_id
+    HAVING count(*) >= @min_co_rentals::int
+), scored AS (
+    SELECT p.film_id, p.similar_film_id, p.co_rentals,
+           p.co_rentals / sqrt(fa.customers::float8 * fb.customers::float8) AS score
+    FROM pairs p
+    JOIN film_customers fa ON fa.film_id = p.film_id
+    JOIN film_customers fb ON fb.film_id = p.similar_film_id
+), ranked AS (
+    SELECT film_id, similar_film_id, co_rentals, score,
+           row_number() OVER (PARTITION BY film_id ORDER BY score DESC, similar_film_id) AS rank
+    FROM scored
+)
+INSERT INTO film_similarity (film_id, similar_film_id, co_rentals, score)
+SELECT film_id, similar_film_id, co_rentals, score
+FROM ranked
+WHERE rank <= @max_per_film::int;
+
+-- name: ListSimilarFilms :many
+SELECT f.film_id, f.title, f.description, f.release_year, f.language_id,
+       f.original_language_id, f.rental_duration, f.rental_rate, f.length,
+       f.replacement_cost, f.rating, f.special_features, f.last_update,
+       s.score
+FROM film_similarity s
+JOIN film f ON f.film_id = s.similar_film_id
+WHERE s.film_id = @film_id AND f.archived_at IS NULL
+ORDER BY s.score DESC, f.film_id
+LIMIT @max_results;
+
+-- ListRecommendedFilms ranks the films similar to any film the customer has
+-- rented by their summed similarity, leaving out films they have rented
+-- and archived films.
+-- because_film_id is the rented film that contributes most to each score.
+
+-- name: ListRecommendedFilms :many
+WITH history AS (
+    SELECT DISTINCT i.film_id
+    FROM rental r
+    JOIN inventory i ON i.inventory_id = r.inventory_id
+    WHERE r.customer_id = @customer_id
+), candidates AS (
+    SELECT s.similar_film_id AS film_id,
+           sum(s.score)::float8 AS score,
+           ((array_agg(s.film_id ORDER BY s.score DESC, s.film_id))[1])::int AS because_film_id
+    FROM film_similarity s
+    JOIN history h ON h.film_id = s.film_id
+    WHERE s.similar_film_id NOT IN (SELECT film_id FROM history)
+    GROUP BY s.similar_film_id
+)
+SELECT f.film_id, f.title, f.description, f.release_year, f.language_id,
+       f.original_language_id, f.rental_duration, f.rental_rate, f.length,
+       f.replacement_cost, f.rating, f.special_features, f.last_update,
+       c.score, c.because_film_id, bf.title AS because_title
+FROM candidates c
+JOIN film f ON f.film_id = c.film_id
+JOIN film bf ON bf.film_id = c.because_film_id
+WHERE f.archived_at IS NULL
+ORDER BY c.score DESC, f.film_id
+LIMIT @max_results;
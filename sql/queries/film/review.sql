@@ -0,0 +1,105 @@
+-- name: GetReview :one
+SELECT r.review_id, r.film_id, f.title, r.customer_id,
+       c.first_name, c.last_name, r.rating, r.body, r.status,
+       r.moderation_note, r.created_at, r.last_update
+FROM film_review r
+JOIN film f ON f.film_id = r.film_id
+JOIN customer c ON c.customer_id = r.customer_id
+WHERE r.review_id = $1;
+
+-- name: ListFilmReviews :many
+SELECT r.review_id, r.film_id, f.title, r.customer_id,
+       c.first_name, c.last_name, r.rating, r.body, r.status,
+       r.moderation_note, r.created_at, r.last_update
+FROM film_review r
+JOIN film f ON f.film_id = r.film_id
+JOIN customer c ON c.customer_id = r.customer_id
+WHERE r.film_id = @film_id AND r.status = 'approved'
+ORDER BY r.created_at DESC, r.review_id DESC
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: CountFilmReviews :one
+SELECT count(*) FROM film_review
+WHERE film_id = @film_id AND status = 'approved';
+
+-- name: ListReviewsByCustomer :many
+SELECT r.review_id, r.film_id, f.title, r.customer_id,
+       c.first_name, c.last_name, r.rating, r.body, r.status,
+       r.moderation_note, r.created_at, r.last_update
+FROM film_review r
+JOIN film f ON f.film_id = r.film_id
+JOIN customer c ON c.customer_id = r.customer_id
+WHERE r.customer_id = @customer_id
+ORDER BY r.created_at DESC, r.review_id DESC
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: CountReviewsByCustomer :one
+SELECT count(*) FROM film_review WHERE customer_id = @customer_id;
+
+-- The moderation queue is worked oldest first.
+
+-- name: ListReviewsByStatus :many
+SELECT r.review_id, r.film_id, f.title, r.customer_id,
+       c.first_name, c.last_name, r.rating, r.body, r.status,
+       r.moderation_note, r.created_at, r.last_update
+FROM film_review r
+JOIN film f ON f.film_id = r.film_id
+JOIN customer c ON c.customer_id = r.customer_id
+WHERE r.status = @status
+ORDER BY r.created_at, r.review_id
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: CountReviewsByStatus :one
+SELECT count(*) FROM film_review WHERE status = @status;
+
+-- name: UpsertReview :one
+INSERT INTO film_review (film_id, customer_id, rating, body, status)
+VALUES (@film_id, @customer_id, @rating, @body, @status)
+ON CONFLICT (film_id, customer_id) DO UPDATE
+SET rating = EXCLUDED.rating,
+    body = EXCLUDED.body,
+    status = EXCLUDED.status,
+    moderation_note = '',
+    created_at = now()
+RETURNING review_id;
+
+-- name: UpdateReviewStatus :one
+UPDATE film_review
+SET status = @status, moderation_note = @moderation_note
+WHERE review_id = @review_id
+RETURNING film_id;
+
+-- name: DeleteReview :one
+DELETE FROM film_review
+WHERE review_id = $1
+RETURNING film_id;
+
+-- name: LockFilmRating :exec
+-- Serialises rating recomputes for one film until the transaction ends, so
+-- concurrent review changes cannot each write an aggregate that misses the
+-- other's review.
+SELECT pg_advisory_xact_lock(@film_id::int);
+
+-- RefreshFilmRating recomputes a film's aggregate from its approved reviews.
+-- Call LockFilmRating first in the same transaction.
+
+-- name: RefreshFilmRating :exec
+WITH agg AS (
+    SELECT count(*)::int AS review_count, coalesce(round(avg(rating), 2), 0)::numeric(3,2) AS average_rating
+    FROM film_review
+    WHERE film_id = @film_id AND status = 'approved'
+), upserted AS (
+    INSERT INTO film_rating (film_id, review_count, average_rating)
+    SELECT @film_id, review_count, average_rating FROM agg WHERE review_count > 0
+    ON CONFLICT (film_id) DO UPDATE
+    SET review_count = EXCLUDED.review_count,
+        average_rating = EXCLUDED.average_rating
+    RETURNING film_id
+)
+DELETE FROM film_rating
+WHERE film_rating.film_id = @film_id AND (SELECT review_count FROM agg) = 0;
+
+-- name: GetFilmRating :one
+SELECT review_count, average_rating
+FROM film_rating
+WHERE film_id = $1;
@@ -0,0 +1,38 @@
+-- Wishlisted films are listed newest first with the number of copies the
+-- customer's home store holds and how many of them are not rented out.
+-- Archived films stay on the list but are not shown until restored.
+
+-- name: ListWishlist :many
+SELECT f.film_id, f.title, f.description, f.release_year, f.language_id,
+       f.original_language_id, f.rental_duration, f.rental_rate, f.length,
+       f.replacement_cost, f.rating, f.special_features, f.last_update,
+       w.added_at, c.store_id,
+       (SELECT count(*) FROM inventory i
+        WHERE i.film_id = f.film_id AND i.store_id = c.store_id)::int AS copies,
+       (SELECT count(*) FROM inventory i
+        WHERE i.film_id = f.film_id AND i.store_id = c.store_id
+          AND NOT EXISTS (
+              SELECT 1 FROM rental r
+              WHERE r.inventory_id = i.inventory_id AND r.return_date IS NULL
+          ))::int AS available_copies
+FROM wishlist_item w
+JOIN film f ON f.film_id = w.film_id
+JOIN customer c ON c.customer_id = w.customer_id
+WHERE w.customer_id = @customer_id AND f.archived_at IS NULL
+ORDER BY w.added_at DESC, f.film_id DESC
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: CountWishlist :one
+SELECT count(*)
+FROM wishlist_item w
+JOIN film f ON f.film_id = w.film_id
+WHERE w.customer_id = @customer_id AND f.archived_at IS NULL;
+
+-- name: AddWishlistItem :exec
+INSERT INTO wishlist_item (customer_id, film_id)
+VALUES (@customer_id, @film_id)
+ON CONFLICT (customer_id, film_id) DO NOTHING;
+
+-- name: RemoveWishlistItem :execrows
+DELETE FROM wishlist_item
+WHERE customer_id = @customer_id AND film_id = @film_id;
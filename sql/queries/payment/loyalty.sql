@@ -0,0 +1,67 @@
+-- name: GetLoyaltyAccount :one
+SELECT a.customer_id, a.tier, a.points_balance, a.lifetime_points, a.tier_calculated_at,
+       t.points_multiplier
+FROM loyalty_account a
+JOIN loyalty_tier t ON t.tier = a.tier
+WHERE a.customer_id = $1;
+
+-- name: ListLoyaltyTiers :many
+SELECT tier, rank, min_monthly_purchases, min_dollar_amount, points_multiplier
+FROM loyalty_tier
+ORDER BY rank;
+
+-- name: EnsureLoyaltyAccounts :execrows
+INSERT INTO loyalty_account (customer_id)
+SELECT customer_id FROM customer
+ON CONFLICT (customer_id) DO NOTHING;
+
+-- name: ResetLoyaltyTiers :exec
+UPDATE loyalty_account
+SET tier = $1, tier_calculated_at = now();
+
+-- name: PromoteLoyaltyTier :execrows
+UPDATE loyalty_account
+SET tier = sqlc.arg(tier), tier_calculated_at = now()
+WHERE customer_id IN (
+    SELECT r.customer_id
+    FROM rewards_report(sqlc.arg(min_monthly_purchases)::int, sqlc.arg(min_dollar_amount)::numeric) r
+);
+
+-- name: CountLoyaltyAccountsByTier :many
+SELECT tier, count(*) AS customers
+FROM loyalty_account
+GROUP BY tier;
+
+-- name: RunRewardsReport :many
+SELECT r.customer_id, r.store_id, r.first_name, r.last_name, r.email
+FROM rewards_report(sqlc.arg(min_monthly_purchases)::int, sqlc.arg(min_dollar_amount)::numeric) r
+ORDER BY r.customer_id;
+
+-- name: CreditLoyaltyPoints :one
+INSERT INTO loyalty_account (customer_id, points_balance, lifetime_points)
+VALUES (sqlc.arg(customer_id), sqlc.arg(points)::int, sqlc.arg(points)::int)
+ON CONFLICT (customer_id) DO UPDATE
+SET points_balance = loyalty_account.points_balance + EXCLUDED.points_balance,
+    lifetime_points = loyalty_account.lifetime_points + EXCLUDED.lifetime_points
+RETURNING points_balance;
+
+-- name: DebitLoyaltyPoints :one
+-- Returns no row when the customer does not have enough points.
+UPDATE loyalty_account
+SET points_balance = points_balance - sqlc.arg(points)::int
+WHERE customer_id = sqlc.arg(customer_id) AND points_balance >= sqlc.arg(points)::int
+RETURNING points_balance;
+
+-- name: CreateLoyaltyTransaction :exec
+INSERT INTO loyalty_transaction (customer_id, kind, points, balance_after, payment_id, rental_id, note)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: ListLoyaltyTransactions :many
+SELECT loyalty_transaction_id, customer_id, kind, points, balance_after, payment_id, rental_id, note, created_at
+FROM loyalty_transaction
+WHERE customer_id = $1
+ORDER BY loyalty_transaction_id DESC
+LIMIT $2 OFFSET $3;
+
+-- name: CountLoyaltyTransactions :one
+SELECT count(*) FROM loyalty_transaction WHERE customer_id = $1;
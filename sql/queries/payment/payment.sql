@@ -1,10 +1,12 @@
 -- name: GetPayment :one
-SELECT payment_id, customer_id, staff_id, rental_id, amount, payment_date
+SELECT payment_id, customer_id, staff_id, rental_id, amount, payment_date,
+       charge_type, net_amount, tax_amount
 FROM payment
 WHERE payment_id = $1;
 
 -- name: ListPayments :many
-SELECT payment_id, customer_id, staff_id, rental_id, amount, payment_date
+SELECT payment_id, customer_id, staff_id, rental_id, amount, payment_date,
+       charge_type, net_amount, tax_amount
 FROM payment
 ORDER BY payment_date DESC, payment_id DESC
 LIMIT $1 OFFSET $2;
@@ -13,7 +15,8 @@ LIMIT $1 OFFSET $2;
 SELECT count(*) FROM payment;
 
 -- name: ListPaymentsByCustomer :many
-SELECT payment_id, customer_id, staff_id, rental_id, amount, payment_date
+SELECT payment_id, customer_id, staff_id, rental_id, amount, payment_date,
+       charge_type, net_amount, tax_amount
 FROM payment
 WHERE customer_id = $1
 ORDER BY payment_date DESC, payment_id DESC
@@ -23,7 +26,8 @@ LIMIT $2 OFFSET $3;
 SELECT count(*) FROM payment WHERE customer_id = $1;
 
 -- name: ListPaymentsByStaff :many
-SELECT payment_id, customer_id, staff_id, rental_id, amount, payment_date
+SELECT payment_id, customer_id, staff_id, rental_id, amount, payment_date,
+       charge_type, net_amount, tax_amount
 FROM payment
 WHERE staff_id = $1
 ORDER BY payment_date DESC, payment_id DESC
@@ -33,7 +37,8 @@ LIMIT $2 OFFSET $3;
 SELECT count(*) FROM payment WHERE staff_id = $1;
 
 -- name: ListPaymentsByRental :many
-SELECT payment_id, customer_id, staff_id, rental_id, amount, payment_date
+SELECT payment_id, customer_id, staff_id, rental_id, amount, payment_date,
+       charge_type, net_amount, tax_amount
 FROM payment
 WHERE rental_id = $1
 ORDER BY payment_date DESC, payment_id DESC
@@ -43,7 +48,8 @@ LIMIT $2 OFFSET $3;
 SELECT count(*) FROM payment WHERE rental_id = $1;
 
 -- name: ListPaymentsByDateRange :many
-SELECT payment_id, customer_id, staff_id, rental_id, amount, payment_date
+SELECT payment_id, customer_id, staff_id, rental_id, amount, payment_date,
+       charge_type, net_amount, tax_amount
 FROM payment
 WHERE payment_date >= $1 AND payment_date < $2
 ORDER BY payment_date DESC, payment_id DESC
@@ -54,13 +60,64 @@ SELECT count(*) FROM payment
 WHERE payment_date >= $1 AND payment_date < $2;
 
 -- name: CreatePayment :one
-INSERT INTO payment (customer_id, staff_id, rental_id, amount, payment_date)
-VALUES ($1, $2, $3, $4, now())
-RETURNING payment_id, customer_id, staff_id, rental_id, amount, payment_date;
+INSERT INTO payment (customer_id, staff_id, rental_id, amount, payment_date,
+                     charge_type, net_amount, tax_amount)
+VALUES ($1, $2, $3, $4, now(), $5, $6, $7)
+RETURNING payment_id, customer_id, staff_id, rental_id, amount, payment_date,
+          charge_type, net_amount, tax_amount;
+
+-- name: CreatePaymentPriceOverride :exec
+INSERT INTO payment_price_override (payment_id, customer_id, staff_id, quoted_amount, amount)
+VALUES ($1, $2, $3, $4, $5);
 
 -- name: DeletePayment :exec
 DELETE FROM payment WHERE payment_id = $1;
 
+-- name: GetRentalStoreLocation :one
+-- Store a rental was made at, with its address for receipts.
+SELECT s.store_id, a.address, c.city, cy.country
+FROM rental r
+JOIN inventory i ON i.inventory_id = r.inventory_id
+JOIN store s ON s.store_id = i.store_id
+JOIN address a ON a.address_id = s.address_id
+JOIN city c ON c.city_id = a.city_id
+JOIN country cy ON cy.country_id = c.country_id
+WHERE r.rental_id = $1;
+
+-- name: GetRentalFilmTitle :one
+SELECT f.title
+FROM rental r
+JOIN inventory i ON i.inventory_id = r.inventory_id
+JOIN film f ON f.film_id = i.film_id
+WHERE r.rental_id = $1;
+
+-- name: GetRevenueReport :many
+-- Revenue per store and charge type for payments in [start_date, end_date).
+-- Succeeded subscription charges are reported under the customer's home
+-- store with charge type 'subscription'; they carry no sales tax.
+SELECT rev.store_id::int AS store_id, rev.charge_type::text AS charge_type,
+       count(*) AS payment_count,
+       sum(rev.net_amount)::numeric AS net_amount,
+       sum(rev.tax_amount)::numeric AS tax_amount,
+       sum(rev.amount)::numeric AS gross_amount
+FROM (
+    SELECT i.store_id, p.charge_type, p.net_amount, p.tax_amount, p.amount
+    FROM payment p
+    JOIN rental r ON r.rental_id = p.rental_id
+    JOIN inventory i ON i.inventory_id = r.inventory_id
+    WHERE p.payment_date >= sqlc.arg(start_date)::timestamptz
+      AND p.payment_date < sqlc.arg(end_date)::timestamptz
+    UNION ALL
+    SELECT c.store_id, 'subscription', sp.amount, 0, sp.amount
+    FROM subscription_payment sp
+    JOIN customer c ON c.customer_id = sp.customer_id
+    WHERE sp.status = 'succeeded'
+      AND sp.created_at >= sqlc.arg(start_date)::timestamptz
+      AND sp.created_at < sqlc.arg(end_date)::timestamptz
+) rev
+GROUP BY rev.store_id, rev.charge_type
+ORDER BY rev.store_id, rev.charge_type;
+
 -- name: GetCustomerName :one
 SELECT first_name || ' ' || last_name AS full_name
 FROM customer
@@ -75,3 +132,11 @@ WHERE staff_id = $1;
 SELECT rental_date
 FROM rental
 WHERE rental_id = $1;
+
+-- name: GetRentalStoreManager :one
+-- Manager of the store a rental was made at; records self-service payments.
+SELECT s.manager_staff_id
+FROM rental r
+JOIN inventory i ON i.inventory_id = r.inventory_id
+JOIN store s ON s.store_id = i.store_id
+WHERE r.rental_id = $1;
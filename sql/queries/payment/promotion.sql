@@ -0,0 +1,124 @@
+-- name: GetPromotion :one
+SELECT promotion_id, name, code, kind, value, buy_quantity, pay_quantity,
+       category_id, store_id, starts_at, ends_at, max_uses_per_customer,
+       active, last_update
+FROM promotion
+WHERE promotion_id = $1;
+
+-- name: GetPromotionByCode :one
+SELECT promotion_id, name, code, kind, value, buy_quantity, pay_quantity,
+       category_id, store_id, starts_at, ends_at, max_uses_per_customer,
+       active, last_update
+FROM promotion
+WHERE upper(code) = upper(sqlc.arg(code)::text);
+
+-- name: ListPromotions :many
+SELECT promotion_id, name, code, kind, value, buy_quantity, pay_quantity,
+       category_id, store_id, starts_at, ends_at, max_uses_per_customer,
+       active, last_update
+FROM promotion
+ORDER BY promotion_id
+LIMIT $1 OFFSET $2;
+
+-- name: CountPromotions :one
+SELECT count(*) FROM promotion;
+
+-- name: ListActivePromotions :many
+SELECT promotion_id, name, code, kind, value, buy_quantity, pay_quantity,
+       category_id, store_id, starts_at, ends_at, max_uses_per_customer,
+       active, last_update
+FROM promotion
+WHERE active = true
+ORDER BY promotion_id
+LIMIT $1 OFFSET $2;
+
+-- name: CountActivePromotions :one
+SELECT count(*) FROM promotion WHERE active = true;
+
+-- name: ListAutomaticPromotions :many
+-- Active promotions without a code whose window contains the given time.
+SELECT promotion_id, name, code, kind, value, buy_quantity, pay_quantity,
+       category_id, store_id, starts_at, ends_at, max_uses_per_customer,
+       active, last_update
+FROM promotion
+WHERE active = true
+  AND code IS NULL
+  AND (starts_at IS NULL OR starts_at <= sqlc.arg(at)::timestamptz)
+  AND (ends_at IS NULL OR ends_at > sqlc.arg(at)::timestamptz)
+ORDER BY promotion_id;
+
+-- name: CreatePromotion :one
+INSERT INTO promotion (name, code, kind, value, buy_quantity, pay_quantity,
+                       category_id, store_id, starts_at, ends_at,
+                       max_uses_per_customer, active)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+RETURNING promotion_id, name, code, kind, value, buy_quantity, pay_quantity,
+          category_id, store_id, starts_at, ends_at, max_uses_per_customer,
+          active, last_update;
+
+-- name: UpdatePromotion :one
+UPDATE promotion
+SET name = $2, code = $3, kind = $4, value = $5, buy_quantity = $6,
+    pay_quantity = $7, category_id = $8, store_id = $9, starts_at = $10,
+    ends_at = $11, max_uses_per_customer = $12, active = $13
+WHERE promotion_id = $1
+RETURNING promotion_id, name, code, kind, value, buy_quantity, pay_quantity,
+          category_id, store_id, starts_at, ends_at, max_uses_per_customer,
+          active, last_update;
+
+-- name: DeletePromotion :exec
+DELETE FROM promotion WHERE promotion_id = $1;
+
+-- name: GetFilmPricing :one
+-- Base rental rate and category of a film (pagila assigns one category per film).
+SELECT f.film_id, f.rental_rate,
+       COALESCE((SELECT fc.category_id FROM film_category fc
+                 WHERE fc.film_id = f.film_id
+                 ORDER BY fc.category_id LIMIT 1), 0)::int AS category_id
+FROM film f
+WHERE f.film_id = $1;
+
+-- name: GetRentalPricing :one
+-- Film, store and customer of a rental, as needed by the pricing engine.
+SELECT r.rental_id, r.customer_id, r.rental_date, i.film_id, i.store_id
+FROM rental r
+JOIN inventory i ON i.inventory_id = r.inventory_id
+WHERE r.rental_id = $1;
+
+-- name: CountCustomerRentalsInWindow :one
+-- Counts a customer's rentals in [start, end) that match an optional
+-- category and store scope (0 means any). Used for bundle promotions.
+SELECT count(*)
+FROM rental r
+JOIN inventory i ON i.inventory_id = r.inventory_id
+WHERE r.customer_id = sqlc.arg(customer_id)
+  AND r.rental_date >= sqlc.arg(window_start)::timestamptz
+  AND r.rental_date < sqlc.arg(window_end)::timestamptz
+  AND (sqlc.arg(store_id)::int = 0 OR i.store_id = sqlc.arg(store_id)::int)
+  AND (sqlc.arg(category_id)::int = 0 OR EXISTS (
+      SELECT 1 FROM film_category fc
+      WHERE fc.film_id = i.film_id AND fc.category_id = sqlc.arg(category_id)::int
+  ));
+
+-- name: CountPromotionUsesByCustomer :one
+SELECT count(*) FROM payment_promotion
+WHERE promotion_id = $1 AND customer_id = $2;
+
+-- name: LockPromotionCustomer :exec
+-- Serialises payments applying a promotion for one customer until the
+-- transaction ends, so its per-customer limit can be checked and used in
+-- the same transaction.
+SELECT pg_advisory_xact_lock(@promotion_id::int, @customer_id::int);
+
+-- name: PromotionLimitReached :one
+-- Reports whether the customer has used the promotion as often as it allows.
+SELECT (p.max_uses_per_customer > 0
+        AND (SELECT count(*) FROM payment_promotion pp
+             WHERE pp.promotion_id = p.promotion_id AND pp.customer_id = @customer_id::int)
+            >= p.max_uses_per_customer)::bool AS limit_reached
+FROM promotion p
+WHERE p.promotion_id = @promotion_id::int;
+
+-- name: CreatePaymentPromotion :exec
+INSERT INTO payment_promotion (payment_id, promotion_id, customer_id, discount)
+VALUES ($1, $2, $3, $4);
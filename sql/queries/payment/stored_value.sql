@@ -0,0 +1,64 @@
+-- name: GetStoredValueAccount :one
+SELECT account_id, kind, code, customer_id, balance, active, create_date, last_update
+FROM stored_value_account
+WHERE account_id = $1;
+
+-- name: GetStoredValueAccountByCode :one
+SELECT account_id, kind, code, customer_id, balance, active, create_date, last_update
+FROM stored_value_account
+WHERE code = $1;
+
+-- name: GetStoreCreditAccount :one
+SELECT account_id, kind, code, customer_id, balance, active, create_date, last_update
+FROM stored_value_account
+WHERE customer_id = $1 AND kind = 'store_credit';
+
+-- name: ListStoredValueAccountsByCustomer :many
+SELECT account_id, kind, code, customer_id, balance, active, create_date, last_update
+FROM stored_value_account
+WHERE customer_id = $1
+ORDER BY account_id;
+
+-- name: CreateStoredValueAccount :one
+INSERT INTO stored_value_account (kind, code, customer_id)
+VALUES ($1, $2, $3)
+RETURNING account_id, kind, code, customer_id, balance, active, create_date, last_update;
+
+-- name: CreditStoredValueAccount :one
+-- System accounts keep no running balance and are never updated.
+UPDATE stored_value_account
+SET balance = balance + sqlc.arg(amount)::numeric
+WHERE account_id = sqlc.arg(account_id) AND kind <> 'system' AND active = true
+RETURNING balance;
+
+-- name: DebitStoredValueAccount :one
+-- Returns no row when the account is inactive or the balance is insufficient.
+-- The row lock taken by UPDATE serialises concurrent debits of one account.
+UPDATE stored_value_account
+SET balance = balance - sqlc.arg(amount)::numeric
+WHERE account_id = sqlc.arg(account_id)
+  AND kind <> 'system'
+  AND active = true
+  AND balance >= sqlc.arg(amount)::numeric
+RETURNING balance;
+
+-- name: CreateStoredValueTransaction :one
+INSERT INTO stored_value_transaction (kind, payment_id, staff_id, note)
+VALUES ($1, $2, $3, $4)
+RETURNING transaction_id, kind, payment_id, staff_id, note, created_at;
+
+-- name: CreateStoredValueEntry :exec
+INSERT INTO stored_value_entry (transaction_id, account_id, amount, balance_after)
+VALUES ($1, $2, $3, $4);
+
+-- name: ListStoredValueEntriesByAccount :many
+SELECT e.entry_id, e.transaction_id, e.account_id, e.amount, e.balance_after,
+       t.kind, t.payment_id, t.note, t.created_at
+FROM stored_value_entry e
+JOIN stored_value_transaction t ON t.transaction_id = e.transaction_id
+WHERE e.account_id = $1
+ORDER BY e.entry_id DESC
+LIMIT $2 OFFSET $3;
+
+-- name: CountStoredValueEntriesByAccount :one
+SELECT count(*) FROM stored_value_entry WHERE account_id = $1;
@@ -0,0 +1,150 @@
+-- name: GetSubscriptionPlan :one
+SELECT plan_id, name, description, price, interval_months, max_concurrent_rentals,
+       max_rentals_per_period, active, last_update
+FROM subscription_plan
+WHERE plan_id = $1;
+
+-- name: ListSubscriptionPlans :many
+SELECT plan_id, name, description, price, interval_months, max_concurrent_rentals,
+       max_rentals_per_period, active, last_update
+FROM subscription_plan
+WHERE NOT sqlc.arg(active_only)::bool OR active
+ORDER BY price, plan_id;
+
+-- name: CreateSubscriptionPlan :one
+INSERT INTO subscription_plan (name, description, price, interval_months,
+                               max_concurrent_rentals, max_rentals_per_period, active)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING plan_id, name, description, price, interval_months, max_concurrent_rentals,
+          max_rentals_per_period, active, last_update;
+
+-- name: UpdateSubscriptionPlan :one
+UPDATE subscription_plan
+SET name = $2, description = $3, price = $4, interval_months = $5,
+    max_concurrent_rentals = $6, max_rentals_per_period = $7, active = $8
+WHERE plan_id = $1
+RETURNING plan_id, name, description, price, interval_months, max_concurrent_rentals,
+          max_rentals_per_period, active, last_update;
+
+-- name: GetSubscription :one
+SELECT s.subscription_id, s.customer_id, s.plan_id, p.name AS plan_name, s.status,
+       s.current_period_start, s.current_period_end, s.cancel_at_period_end,
+       s.pause_at_period_end, s.failed_attempts, s.next_billing_at, s.canceled_at,
+       s.create_date, s.last_update
+FROM subscription s
+JOIN subscription_plan p ON p.plan_id = s.plan_id
+WHERE s.subscription_id = $1;
+
+-- name: LockSubscription :one
+SELECT s.subscription_id, s.customer_id, s.plan_id, p.name AS plan_name, s.status,
+       s.current_period_start, s.current_period_end, s.cancel_at_period_end,
+       s.pause_at_period_end, s.failed_attempts, s.next_billing_at, s.canceled_at,
+       s.create_date, s.last_update
+FROM subscription s
+JOIN subscription_plan p ON p.plan_id = s.plan_id
+WHERE s.subscription_id = $1
+FOR UPDATE OF s;
+
+-- name: LockNextDueSubscription :one
+-- Subscriptions locked by another billing run are skipped, and those of
+-- anonymized customers are never billed.
+SELECT s.subscription_id, s.customer_id, s.plan_id, p.name AS plan_name, s.status,
+       s.current_period_start, s.current_period_end, s.cancel_at_period_end,
+       s.pause_at_period_end, s.failed_attempts, s.next_billing_at, s.canceled_at,
+       s.create_date, s.last_update
+FROM subscription s
+JOIN subscription_plan p ON p.plan_id = s.plan_id
+JOIN customer c ON c.customer_id = s.customer_id
+WHERE s.next_billing_at <= sqlc.arg(now)::timestamptz
+  AND s.status IN ('active', 'past_due')
+  AND c.anonymized_at IS NULL
+ORDER BY s.next_billing_at
+LIMIT 1
+FOR UPDATE OF s SKIP LOCKED;
+
+-- name: GetLiveSubscriptionByCustomer :one
+SELECT s.subscription_id, s.customer_id, s.plan_id, p.name AS plan_name, s.status,
+       s.current_period_start, s.current_period_end, s.cancel_at_period_end,
+       s.pause_at_period_end, s.failed_attempts, s.next_billing_at, s.canceled_at,
+       s.create_date, s.last_update
+FROM subscription s
+JOIN subscription_plan p ON p.plan_id = s.plan_id
+WHERE s.customer_id = $1 AND s.status <> 'canceled';
+
+-- name: ListSubscriptions :many
+SELECT s.subscription_id, s.customer_id, s.plan_id, p.name AS plan_name, s.status,
+       s.current_period_start, s.current_period_end, s.cancel_at_period_end,
+       s.pause_at_period_end, s.failed_attempts, s.next_billing_at, s.canceled_at,
+       s.create_date, s.last_update
+FROM subscription s
+JOIN subscription_plan p ON p.plan_id = s.plan_id
+WHERE (sqlc.arg(customer_id)::int = 0 OR s.customer_id = sqlc.arg(customer_id)::int)
+  AND (sqlc.arg(status)::text = '' OR s.status = sqlc.arg(status)::text)
+ORDER BY s.subscription_id DESC
+LIMIT $1 OFFSET $2;
+
+-- name: CountSubscriptions :one
+SELECT count(*)
+FROM subscription s
+WHERE (sqlc.arg(customer_id)::int = 0 OR s.customer_id = sqlc.arg(customer_id)::int)
+  AND (sqlc.arg(status)::text = '' OR s.status = sqlc.arg(status)::text);
+
+-- name: CreateSubscription :one
+INSERT INTO subscription (customer_id, plan_id, status, current_period_start,
+                          current_period_end, next_billing_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING subscription_id;
+
+-- name: UpdateSubscriptionState :exec
+UPDATE subscription
+SET status = $2,
+    current_period_start = $3,
+    current_period_end = $4,
+    cancel_at_period_end = $5,
+    pause_at_period_end = $6,
+    failed_attempts = $7,
+    next_billing_at = $8,
+    canceled_at = $9
+WHERE subscription_id = $1;
+
+-- name: CreateSubscriptionPayment :exec
+INSERT INTO subscription_payment (subscription_id, customer_id, amount, period_start,
+                                  period_end, status, gateway_reference, failure_reason,
+                                  max_concurrent_rentals, max_rentals_per_period)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+
+-- name: ListSubscriptionPayments :many
+SELECT subscription_payment_id, subscription_id, customer_id, amount, period_start,
+       period_end, status, gateway_reference, failure_reason, created_at
+FROM subscription_payment
+WHERE subscription_id = $1
+ORDER BY subscription_payment_id DESC
+LIMIT $2 OFFSET $3;
+
+-- name: CountSubscriptionPayments :one
+SELECT count(*) FROM subscription_payment WHERE subscription_id = $1;
+
+-- name: GetSubscriptionCoverage :one
+-- Returns the paid subscription period covering a point in time, if any,
+-- with the limits of the plan as it was when the period was charged.
+SELECT s.subscription_id, p.plan_id, p.name AS plan_name, sp.max_concurrent_rentals,
+       sp.max_rentals_per_period, sp.period_start, sp.period_end
+FROM subscription_payment sp
+JOIN subscription s ON s.subscription_id = sp.subscription_id
+JOIN subscription_plan p ON p.plan_id = s.plan_id
+WHERE sp.customer_id = sqlc.arg(customer_id)
+  AND sp.status = 'succeeded'
+  AND sp.period_start <= sqlc.arg(at)::timestamptz
+  AND sp.period_end > sqlc.arg(at)::timestamptz
+  AND (s.canceled_at IS NULL OR s.canceled_at > sqlc.arg(at)::timestamptz)
+ORDER BY sp.period_end DESC
+LIMIT 1;
+
+-- name: CountOutstandingRentals :one
+-- Counts a customer's other rentals that were out at a point in time.
+SELECT count(*)
+FROM rental
+WHERE customer_id = sqlc.arg(customer_id)
+  AND rental_id <> sqlc.arg(exclude_rental_id)::int
+  AND rental_date <= sqlc.arg(at)::timestamptz
+  AND (return_date IS NULL OR return_date > sqlc.arg(at)::timestamptz);
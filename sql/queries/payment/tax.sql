@@ -0,0 +1,55 @@
+-- name: GetTaxRate :one
+SELECT t.tax_rate_id, t.name, t.country_id, cy.country, t.city_id, c.city,
+       t.charge_type, t.rate, t.active, t.last_update
+FROM tax_rate t
+JOIN country cy ON cy.country_id = t.country_id
+LEFT JOIN city c ON c.city_id = t.city_id
+WHERE t.tax_rate_id = $1;
+
+-- name: ListTaxRates :many
+SELECT t.tax_rate_id, t.name, t.country_id, cy.country, t.city_id, c.city,
+       t.charge_type, t.rate, t.active, t.last_update
+FROM tax_rate t
+JOIN country cy ON cy.country_id = t.country_id
+LEFT JOIN city c ON c.city_id = t.city_id
+WHERE sqlc.arg(country_id)::int = 0 OR t.country_id = sqlc.arg(country_id)::int
+ORDER BY cy.country, c.city NULLS FIRST, t.tax_rate_id;
+
+-- name: CreateTaxRate :one
+INSERT INTO tax_rate (name, country_id, city_id, charge_type, rate, active)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING tax_rate_id;
+
+-- name: UpdateTaxRate :execrows
+UPDATE tax_rate
+SET name = $2, country_id = $3, city_id = $4, charge_type = $5, rate = $6, active = $7
+WHERE tax_rate_id = $1;
+
+-- name: DeleteTaxRate :execrows
+DELETE FROM tax_rate WHERE tax_rate_id = $1;
+
+-- name: GetCityCountry :one
+SELECT country_id FROM city WHERE city_id = $1;
+
+-- name: ListStoreTaxRates :many
+-- Active rates for the jurisdiction of a store that apply to a charge type.
+SELECT t.tax_rate_id, t.name, t.rate
+FROM store s
+JOIN address a ON a.address_id = s.address_id
+JOIN city c ON c.city_id = a.city_id
+JOIN tax_rate t ON t.country_id = c.country_id
+WHERE s.store_id = sqlc.arg(store_id)
+  AND t.active
+  AND (t.city_id IS NULL OR t.city_id = c.city_id)
+  AND (t.charge_type IS NULL OR t.charge_type = sqlc.arg(charge_type)::text)
+ORDER BY t.city_id NULLS FIRST, t.tax_rate_id;
+
+-- name: CreatePaymentTax :exec
+INSERT INTO payment_tax (payment_id, tax_rate_id, name, rate, amount)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: ListPaymentTaxes :many
+SELECT tax_rate_id, name, rate, amount
+FROM payment_tax
+WHERE payment_id = $1
+ORDER BY tax_rate_id;
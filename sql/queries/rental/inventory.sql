@@ -54,6 +54,12 @@ WHERE i.film_id = $1
       WHERE r.inventory_id = i.inventory_id AND r.return_date IS NULL
   );
 
+-- name: IsFilmArchived :one
+-- Archived films get no new inventory.
+SELECT (archived_at IS NOT NULL)::bool AS archived
+FROM film
+WHERE film_id = $1;
+
 -- name: CreateInventory :one
 INSERT INTO inventory (film_id, store_id)
 VALUES ($1, $2)
@@ -72,3 +72,10 @@ SELECT NOT EXISTS (
     SELECT 1 FROM rental
     WHERE inventory_id = $1 AND return_date IS NULL
 ) AS available;
+
+-- name: HasCustomerRentedFilm :one
+SELECT EXISTS (
+    SELECT 1 FROM rental r
+    JOIN inventory i ON i.inventory_id = r.inventory_id
+    WHERE r.customer_id = @customer_id AND i.film_id = @film_id
+) AS rented;